@@ -24,6 +24,7 @@ import (
 	"pentagi/pkg/providers/provider"
 	"pentagi/pkg/providers/qwen"
 	"pentagi/pkg/providers/tester"
+	"pentagi/pkg/providers/tester/stub"
 	"pentagi/pkg/providers/tester/testdata"
 	"pentagi/pkg/version"
 
@@ -42,6 +43,7 @@ func main() {
 	testGroups := flag.String("groups", "all", "Comma-separated test groups to run")
 	workers := flag.Int("workers", 4, "Number of workers to use")
 	verbose := flag.Bool("verbose", false, "Enable verbose output")
+	useStub := flag.Bool("stub", false, "Run the curated local profile tests against the bundled stub server")
 	flag.Parse()
 
 	logrus.Infof("Starting PentAGI Provider Configuration Tester %s", version.GetBinaryVersion())
@@ -63,6 +65,39 @@ func main() {
 		cfg.LLMServerProvider = *providerName
 	}
 
+	var localRegistry *testdata.TestRegistry
+	if *useStub {
+		if *providerType != "custom" {
+			log.Fatalf("The stub server can be used only with the custom provider type")
+		}
+
+		if *testsPath != "" {
+			localRegistry, err = loadCustomTests(*testsPath)
+		} else {
+			localRegistry, err = testdata.LoadLocalRegistry()
+		}
+		if err != nil {
+			log.Fatalf("Error loading stub tests: %v", err)
+		}
+
+		srv := stub.New(localRegistry.GetAllTests())
+		defer srv.Close()
+
+		cfg.LLMServerURL = srv.URL()
+		cfg.LLMServerKey = "stub"
+		cfg.LLMServerModel = stub.DefaultModel
+		cfg.LLMServerProvider = ""
+		cfg.LLMServerConfig = ""
+		if cfg.LLMServerProfile == "" {
+			cfg.LLMServerProfile = custom.LocalProfile
+		}
+		if *testGroups == "all" {
+			*testGroups = "basic,json"
+		}
+
+		logrus.Infof("Stub server is listening on %s (profile %q)", srv.URL(), cfg.LLMServerProfile)
+	}
+
 	prv, err := createProvider(*providerType, cfg)
 	if err != nil {
 		log.Fatalf("Error creating provider: %v", err)
@@ -98,6 +133,8 @@ func main() {
 			log.Fatalf("Error loading custom tests: %v", err)
 		}
 		testOptions = append(testOptions, tester.WithCustomRegistry(registry))
+	} else if localRegistry != nil {
+		testOptions = append(testOptions, tester.WithCustomRegistry(localRegistry))
 	}
 
 	testOptions = append(
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"pentagi/pkg/database/knowledge"
+	"pentagi/pkg/graph/model"
+	"pentagi/pkg/terminal"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// ArchiveOptions represents the options for knowledge archive export and import
+type ArchiveOptions struct {
+	Path           string
+	UserID         int64
+	WithEmbeddings bool
+	DocType        string
+	Conflict       knowledge.ConflictPolicy
+}
+
+// parseArchiveArgs parses command line arguments specific for export and import-archive
+func parseArchiveArgs(args []string, export bool) (*ArchiveOptions, error) {
+	opts := &ArchiveOptions{Conflict: knowledge.ConflictSkip}
+	if !export {
+		opts.UserID = 1
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		paramName := strings.TrimPrefix(arg, "-")
+
+		if i+1 >= len(args) || strings.HasPrefix(args[i+1], "-") {
+			return nil, fmt.Errorf("missing value for parameter: %s", paramName)
+		}
+
+		paramValue := args[i+1]
+		i++
+
+		switch {
+		case paramName == "path":
+			opts.Path = paramValue
+		case paramName == "user_id":
+			userID, err := strconv.ParseInt(paramValue, 10, 64)
+			if err != nil || userID < 0 || (!export && userID == 0) {
+				return nil, fmt.Errorf("invalid user_id value: %s", paramValue)
+			}
+			opts.UserID = userID
+		case paramName == "embeddings" && export:
+			withEmbeddings, err := strconv.ParseBool(paramValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid embeddings value: %s", paramValue)
+			}
+			opts.WithEmbeddings = withEmbeddings
+		case paramName == "doc_type" && export:
+			docType := model.KnowledgeDocType(paramValue)
+			if !docType.IsValid() {
+				return nil, fmt.Errorf("invalid doc_type value: %s", paramValue)
+			}
+			opts.DocType = paramValue
+		case paramName == "conflict" && !export:
+			opts.Conflict = knowledge.ConflictPolicy(paramValue)
+			if !opts.Conflict.Valid() {
+				return nil, fmt.Errorf("invalid conflict value: %s", paramValue)
+			}
+		default:
+			return nil, fmt.Errorf("unknown parameter: %s", paramName)
+		}
+	}
+
+	if opts.Path == "" {
+		return nil, fmt.Errorf("path parameter is required")
+	}
+
+	return opts, nil
+}
+
+// exportArchive writes knowledge documents of one or all users to a knowledge archive
+func (t *Tester) exportArchive(args []string) error {
+	if len(args) == 0 {
+		printExportUsage()
+		return nil
+	}
+
+	opts, err := parseArchiveArgs(args, true)
+	if err != nil {
+		terminal.Error("Error parsing export arguments: %v", err)
+		printExportUsage()
+		return nil
+	}
+
+	req := knowledge.ExportRequest{
+		WithEmbeddings: opts.WithEmbeddings,
+		Instance:       t.instance(),
+	}
+	if opts.DocType != "" {
+		req.Filter = &model.KnowledgeFilter{DocTypes: []model.KnowledgeDocType{model.KnowledgeDocType(opts.DocType)}}
+	}
+
+	file, err := os.Create(opts.Path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", opts.Path, err)
+	}
+	defer file.Close()
+
+	db := stdlib.OpenDBFromPool(t.conn)
+	defer db.Close()
+	store := t.newKnowledgeStore(db)
+
+	var manifest *knowledge.ArchiveManifest
+	if opts.UserID == 0 {
+		manifest, err = store.ExportDocuments(t.ctx, file, req)
+	} else {
+		manifest, err = store.ExportUserDocuments(t.ctx, opts.UserID, file, req)
+	}
+	if err != nil {
+		os.Remove(opts.Path)
+		return fmt.Errorf("export failed: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.Path, err)
+	}
+
+	printArchiveManifest(manifest)
+	terminal.Success("Exported %d documents to %s", manifest.Documents, opts.Path)
+	return nil
+}
+
+// importArchive imports a knowledge archive exported by another instance
+func (t *Tester) importArchive(args []string) error {
+	if len(args) == 0 {
+		printImportArchiveUsage()
+		return nil
+	}
+
+	opts, err := parseArchiveArgs(args, false)
+	if err != nil {
+		terminal.Error("Error parsing import-archive arguments: %v", err)
+		printImportArchiveUsage()
+		return nil
+	}
+
+	info, err := os.Stat(opts.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.Path, err)
+	}
+	if limit := t.cfg.KnowledgeImportMaxBytes; limit > 0 && info.Size() > limit {
+		return fmt.Errorf("archive %s exceeds KNOWLEDGE_IMPORT_MAX_BYTES (%s)", opts.Path, formatSize(limit))
+	}
+	data, err := os.ReadFile(opts.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.Path, err)
+	}
+
+	manifest, err := knowledge.ReadArchiveManifest(data, t.cfg.KnowledgeImportMaxBytes)
+	if err != nil {
+		return err
+	}
+	printArchiveManifest(manifest)
+
+	db := stdlib.OpenDBFromPool(t.conn)
+	defer db.Close()
+	store := t.newKnowledgeStore(db)
+
+	terminal.Info("Importing %d documents from %s as user %d (conflict policy: %s)...",
+		manifest.Documents, opts.Path, opts.UserID, opts.Conflict)
+
+	job, err := store.RunArchiveImport(t.ctx, opts.UserID, knowledge.ArchiveImportRequest{
+		Source:   filepath.Base(opts.Path),
+		Data:     data,
+		Conflict: opts.Conflict,
+		Instance: t.instance(),
+		MaxBytes: t.cfg.KnowledgeImportMaxBytes,
+	}, func(job *model.KnowledgeImportJob) {
+		if job.Status == model.KnowledgeImportStatusRunning {
+			terminal.Info("Processed %d/%d documents: %d created, %d updated, %d skipped, %d failed",
+				job.ProcessedChunks, job.TotalChunks, job.CreatedDocs, job.UpdatedDocs, job.DuplicateDocs, job.FailedDocs)
+		}
+	})
+	if job != nil {
+		printImportJob(job)
+	}
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	terminal.Success("Archive import finished")
+	return nil
+}
+
+// instance identifies this installation in knowledge archives
+func (t *Tester) instance() knowledge.Instance {
+	return knowledge.Instance{
+		InstallationID: t.cfg.InstallationID,
+		TenantID:       t.cfg.TenantID,
+	}
+}
+
+// printArchiveManifest prints the header of a knowledge archive
+func printArchiveManifest(manifest *knowledge.ArchiveManifest) {
+	terminal.PrintHeader(fmt.Sprintf("Knowledge archive v%d", manifest.Version))
+	terminal.PrintKeyValue("Instance", manifest.InstanceID)
+	if manifest.TenantID != "" {
+		terminal.PrintKeyValue("Tenant", manifest.TenantID)
+	}
+	terminal.PrintKeyValueFormat("Exported at", "%s", manifest.ExportedAt.Format("2006-01-02 15:04:05 MST"))
+	terminal.PrintKeyValueFormat("Documents", "%d", manifest.Documents)
+	if manifest.Embedding != nil {
+		terminal.PrintKeyValueFormat("Embeddings", "%s (%d dimensions)", manifest.Embedding, manifest.Embedding.Dimensions)
+	} else {
+		terminal.PrintKeyValue("Embeddings", "not included")
+	}
+}
+
+// printExportUsage prints the usage information for the export command
+func printExportUsage() {
+	terminal.PrintHeader("Export Command Usage:")
+	terminal.Info("Exports knowledge documents to a versioned archive that another instance can import")
+	terminal.Info("\nSyntax:")
+	terminal.Info("  ./etester export [OPTIONS]")
+	terminal.Info("\nOptions:")
+	terminal.PrintKeyValue("  -path STRING", "Archive file to write (required)")
+	terminal.PrintKeyValue("  -user_id NUMBER", "Export documents of this user only (default: 0, all users)")
+	terminal.PrintKeyValue("  -embeddings BOOL", "Include embeddings in the archive (default: false)")
+	terminal.PrintKeyValue("  -doc_type STRING", "Export only documents of this type (answer, guide, code)")
+	terminal.Info("\nExamples:")
+	terminal.Info("  ./etester export -path kb.zip")
+	terminal.Info("  ./etester export -path guides.zip -doc_type guide -embeddings true")
+	terminal.Info("")
+}
+
+// printImportArchiveUsage prints the usage information for the import-archive command
+func printImportArchiveUsage() {
+	terminal.PrintHeader("Import-Archive Command Usage:")
+	terminal.Info("Imports a knowledge archive, re-embedding documents when the embedding model differs")
+	terminal.Info("\nSyntax:")
+	terminal.Info("  ./etester import-archive [OPTIONS]")
+	terminal.Info("\nOptions:")
+	terminal.PrintKeyValue("  -path STRING", "Knowledge archive to import (required)")
+	terminal.PrintKeyValue("  -user_id NUMBER", "Owner of the imported documents (default: 1)")
+	terminal.PrintKeyValue("  -conflict STRING", "Conflict policy (skip, overwrite, duplicate; default: skip)")
+	terminal.Info("\nExamples:")
+	terminal.Info("  ./etester import-archive -path kb.zip")
+	terminal.Info("  ./etester import-archive -path kb.zip -user_id 2 -conflict overwrite")
+	terminal.Info("")
+}
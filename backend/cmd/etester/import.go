@@ -0,0 +1,267 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/database/knowledge"
+	"pentagi/pkg/database/knowledge/ingest"
+	"pentagi/pkg/graph/model"
+	"pentagi/pkg/graph/subscriptions"
+	"pentagi/pkg/terminal"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// ImportOptions represents the options for bulk knowledge import
+type ImportOptions struct {
+	Path    string
+	UserID  int64
+	Options ingest.Options
+}
+
+// parseImportArgs parses command line arguments specific for import
+func parseImportArgs(args []string, defaults ingest.Options) (*ImportOptions, error) {
+	opts := &ImportOptions{UserID: 1, Options: defaults}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		paramName := strings.TrimPrefix(arg, "-")
+
+		if i+1 >= len(args) || strings.HasPrefix(args[i+1], "-") {
+			return nil, fmt.Errorf("missing value for parameter: %s", paramName)
+		}
+
+		paramValue := args[i+1]
+		i++
+
+		switch paramName {
+		case "path":
+			opts.Path = paramValue
+		case "user_id":
+			userID, err := strconv.ParseInt(paramValue, 10, 64)
+			if err != nil || userID <= 0 {
+				return nil, fmt.Errorf("invalid user_id value: %s", paramValue)
+			}
+			opts.UserID = userID
+		case "splitter":
+			opts.Options.Splitter = ingest.Splitter(paramValue)
+		case "chunk_size":
+			size, err := strconv.Atoi(paramValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid chunk_size value: %v", err)
+			}
+			opts.Options.ChunkSize = size
+		case "chunk_overlap":
+			overlap, err := strconv.Atoi(paramValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid chunk_overlap value: %v", err)
+			}
+			opts.Options.ChunkOverlap = overlap
+		case "doc_type":
+			opts.Options.DocType = paramValue
+		case "guide_type":
+			opts.Options.GuideType = paramValue
+		case "answer_type":
+			opts.Options.AnswerType = paramValue
+		case "code_lang":
+			opts.Options.CodeLang = paramValue
+		default:
+			return nil, fmt.Errorf("unknown parameter: %s", paramName)
+		}
+	}
+
+	if opts.Path == "" {
+		return nil, fmt.Errorf("path parameter is required")
+	}
+	if _, err := opts.Options.Normalize(); err != nil {
+		return nil, err
+	}
+
+	return opts, nil
+}
+
+// importDocuments imports a file, a zip archive or a directory tree into the
+// knowledge store, reporting progress after each batch
+func (t *Tester) importDocuments(args []string) error {
+	if len(args) == 0 {
+		printImportUsage()
+		return nil
+	}
+
+	opts, err := parseImportArgs(args, ingest.Options{
+		ChunkSize:    t.cfg.KnowledgeImportChunkSize,
+		ChunkOverlap: t.cfg.KnowledgeImportChunkOverlap,
+	})
+	if err != nil {
+		terminal.Error("Error parsing import arguments: %v", err)
+		printImportUsage()
+		return nil
+	}
+
+	files, err := readImportFiles(opts.Path, t.cfg.KnowledgeImportMaxBytes)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", opts.Path, err)
+	}
+	files, skipped, err := ingest.ExpandArchives(files, t.cfg.KnowledgeImportMaxBytes)
+	if err != nil {
+		return fmt.Errorf("failed to expand archives: %w", err)
+	}
+
+	db := stdlib.OpenDBFromPool(t.conn)
+	defer db.Close()
+	store := t.newKnowledgeStore(db)
+
+	terminal.Info("Importing %d files from %s as user %d...", len(files), opts.Path, opts.UserID)
+
+	job, err := store.RunImport(t.ctx, opts.UserID, knowledge.ImportRequest{
+		Source:       opts.Path,
+		Files:        files,
+		SkippedFiles: skipped,
+		Options:      opts.Options,
+	}, func(job *model.KnowledgeImportJob) {
+		if job.Status == model.KnowledgeImportStatusRunning {
+			terminal.Info("Processed %d/%d chunks: %d created, %d duplicates, %d failed",
+				job.ProcessedChunks, job.TotalChunks, job.CreatedDocs, job.DuplicateDocs, job.FailedDocs)
+		}
+	})
+	if job != nil {
+		printImportJob(job)
+	}
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	terminal.Success("Import finished")
+	return nil
+}
+
+// newKnowledgeStore creates a knowledge store over the tester connection
+func (t *Tester) newKnowledgeStore(db *sql.DB) knowledge.KnowledgeStore {
+	// nobody listens to events here, the controller just drops them
+	publishers := subscriptions.NewSubscriptionsController(nil, nil, nil)
+	return knowledge.NewKnowledgeStore(
+		database.New(db),
+		nil,
+		t.embedder,
+		publishers.NewKnowledgePublisher,
+		t.cfg.EmbeddingMaxTextBytes,
+		database.HybridSearchConfig{},
+		knowledge.ReembedConfig{},
+	)
+}
+
+// readImportFiles reads a single file or walks a directory skipping hidden
+// entries such as .git; file paths keep the directory name as a prefix
+func readImportFiles(root string, maxBytes int64) ([]ingest.File, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if maxBytes > 0 && info.Size() > maxBytes {
+			return nil, ingest.ErrTooLarge
+		}
+		data, err := os.ReadFile(root)
+		if err != nil {
+			return nil, err
+		}
+		return []ingest.File{{Path: filepath.Base(root), Data: data}}, nil
+	}
+
+	var (
+		files []ingest.File
+		total int64
+	)
+	base := filepath.Base(filepath.Clean(root))
+	err = filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != root && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		if maxBytes > 0 && total > maxBytes {
+			return ingest.ErrTooLarge
+		}
+
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		files = append(files, ingest.File{Path: path.Join(base, filepath.ToSlash(rel)), Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// printImportJob prints the final counters of an import job
+func printImportJob(job *model.KnowledgeImportJob) {
+	terminal.PrintHeader(fmt.Sprintf("Import job #%d (%s)", job.ID, job.Status))
+	terminal.PrintKeyValueFormat("Files", "%d (%d skipped)", job.TotalFiles, job.SkippedFiles)
+	terminal.PrintKeyValueFormat("Chunks", "%d/%d processed", job.ProcessedChunks, job.TotalChunks)
+	terminal.PrintKeyValueFormat("Created documents", "%d", job.CreatedDocs)
+	if job.UpdatedDocs > 0 {
+		terminal.PrintKeyValueFormat("Updated documents", "%d", job.UpdatedDocs)
+	}
+	terminal.PrintKeyValueFormat("Duplicates", "%d", job.DuplicateDocs)
+	terminal.PrintKeyValueFormat("Failed documents", "%d", job.FailedDocs)
+	if job.Error != nil {
+		terminal.PrintKeyValue("Last error", *job.Error)
+	}
+}
+
+// printImportUsage prints the usage information for the import command
+func printImportUsage() {
+	terminal.PrintHeader("Import Command Usage:")
+	terminal.Info("Imports Markdown, HTML, plain text, JSONL, source files and zip archives into the knowledge base")
+	terminal.Info("\nSyntax:")
+	terminal.Info("  ./etester import [OPTIONS]")
+	terminal.Info("\nOptions:")
+	terminal.PrintKeyValue("  -path STRING", "File, zip archive or directory to import (required)")
+	terminal.PrintKeyValue("  -user_id NUMBER", "Owner of the imported documents (default: 1)")
+	terminal.PrintKeyValue("  -splitter STRING", "Chunking strategy (auto, markdown, recursive, none)")
+	terminal.PrintKeyValue("  -chunk_size NUMBER", "Maximum chunk size in characters (default: KNOWLEDGE_IMPORT_CHUNK_SIZE)")
+	terminal.PrintKeyValue("  -chunk_overlap NUMBER", "Overlap between chunks (default: KNOWLEDGE_IMPORT_CHUNK_OVERLAP)")
+	terminal.PrintKeyValue("  -doc_type STRING", "Document type for all chunks (answer, guide, code)")
+	terminal.PrintKeyValue("  -guide_type STRING", "Guide type (install, configure, use, pentest, development, other)")
+	terminal.PrintKeyValue("  -answer_type STRING", "Answer type (guide, vulnerability, code, tool, other)")
+	terminal.PrintKeyValue("  -code_lang STRING", "Code language for code chunks")
+	terminal.Info("\nExamples:")
+	terminal.Info("  ./etester import -path ./writeups")
+	terminal.Info("  ./etester import -path tools.zip -doc_type guide -guide_type use")
+	terminal.Info("  ./etester import -path answers.jsonl -user_id 2 -splitter none")
+	terminal.Info("")
+}
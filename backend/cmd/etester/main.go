@@ -146,11 +146,17 @@ func showHelp() {
 	terminal.PrintKeyValue("  flush   ", "Delete all documents from the embedding database")
 	terminal.PrintKeyValue("  reindex ", "Recalculate embeddings for all documents")
 	terminal.PrintKeyValue("  search  ", "Search for documents in the embedding database")
+	terminal.PrintKeyValue("  import  ", "Import documents, archives and directories into the knowledge base")
+	terminal.PrintKeyValue("  export  ", "Export knowledge documents to a portable knowledge archive")
+	terminal.PrintKeyValue("  import-archive", "Import a knowledge archive exported by another instance")
 	terminal.Info("\nExamples:")
 	terminal.Info("  ./etester test -verbose         Test with verbose output")
 	terminal.Info("  ./etester info                  Show database statistics")
 	terminal.Info("  ./etester flush                 Delete all documents")
 	terminal.Info("  ./etester reindex               Reindex all documents")
 	terminal.Info("  ./etester search -query \"How to install PostgreSQL\"  Search for documents")
+	terminal.Info("  ./etester import -path ./writeups  Import a directory of documents")
+	terminal.Info("  ./etester export -path kb.zip   Export all documents without embeddings")
+	terminal.Info("  ./etester import-archive -path kb.zip  Import a knowledge archive")
 	terminal.Info("")
 }
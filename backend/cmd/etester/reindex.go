@@ -117,6 +117,28 @@ func (t *Tester) reindex() error {
 		terminal.Info("Progress: %.2f%% (%d/%d documents processed)", progressPercent, processedDocs, totalDocs)
 	}
 
+	// record the model so the server does not start a re-embedding migration
+	_, err = t.conn.Exec(t.ctx, `
+		INSERT INTO embedding_collections (name, provider, model, dimensions)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE
+		SET provider = EXCLUDED.provider, model = EXCLUDED.model, dimensions = EXCLUDED.dimensions`,
+		"langchain", t.embedder.Provider(), t.embedder.Model(), t.embeddingDimensions())
+	if err != nil {
+		terminal.Warning("Failed to record the embedding model: %v", err)
+	}
+
 	terminal.Success("\nReindexing completed successfully! %d documents were updated.", processedDocs)
 	return nil
 }
+
+// embeddingDimensions returns the size of stored vectors or 0 if unknown
+func (t *Tester) embeddingDimensions() int {
+	var dims int
+	err := t.conn.QueryRow(t.ctx,
+		fmt.Sprintf("SELECT COALESCE(MAX(vector_dims(embedding)), 0) FROM %s", t.embeddingTableName)).Scan(&dims)
+	if err != nil {
+		return 0
+	}
+	return dims
+}
@@ -56,6 +56,12 @@ func (t *Tester) executeCommand(args []string) error {
 		return t.reindex()
 	case "search":
 		return t.search(args)
+	case "import":
+		return t.importDocuments(args)
+	case "export":
+		return t.exportArchive(args)
+	case "import-archive":
+		return t.importArchive(args)
 	default:
 		return fmt.Errorf("unknown command: %s", t.command)
 	}
@@ -17,9 +17,11 @@ import (
 	"pentagi/pkg/database"
 	"pentagi/pkg/docker"
 	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
 	"pentagi/pkg/providers"
 	"pentagi/pkg/providers/provider"
 	"pentagi/pkg/terminal"
+	"pentagi/pkg/vault"
 	"pentagi/pkg/version"
 
 	"github.com/joho/godotenv"
@@ -59,7 +61,7 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	lfclient, err := obs.NewLangfuseClient(ctx, cfg)
+	lfclient, err := obs.NewLangfuseClient(ctx, cfg, langfuse.WithRedactor(vault.Redact))
 	if err != nil && !errors.Is(err, obs.ErrNotConfigured) {
 		log.Fatalf("Unable to create langfuse client: %v\n", err)
 	}
@@ -553,6 +553,37 @@ func MockResponse(funcName string, args json.RawMessage) (string, error) {
 
 		resultObj = "code sample stored successfully"
 
+	case tools.StoreCredentialToolName:
+		var credArgs tools.StoreCredentialAction
+		if err := json.Unmarshal(args, &credArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling store credential arguments: %w", err)
+		}
+
+		terminal.PrintMock("Store credential:")
+		terminal.PrintKeyValue("Type", credArgs.Type.String())
+		terminal.PrintKeyValue("Username", credArgs.Username)
+		terminal.PrintKeyValueFormat("Secret length", "%d chars", len(credArgs.Secret))
+		terminal.PrintKeyValue("Source host", credArgs.SourceHost)
+
+		resultObj = fmt.Sprintf("credential stored as {{cred:1}} (%s, username %s, for %s); "+
+			"use the placeholder instead of the secret from now on", credArgs.Type, credArgs.Username, credArgs.SourceHost)
+
+	case tools.ListCredentialsToolName:
+		var listArgs tools.ListCredentialsAction
+		if err := json.Unmarshal(args, &listArgs); err != nil {
+			return "", fmt.Errorf("error unmarshaling list credentials arguments: %w", err)
+		}
+
+		terminal.PrintMock("List credentials:")
+		terminal.PrintKeyValueFormat("Reveal", "%v", listArgs.Reveal.Bool())
+
+		secret := ""
+		if listArgs.Reveal.Bool() {
+			secret = ", secret: P@ssw0rd123"
+		}
+		resultObj = "Stored credentials of this flow:\n" +
+			"- {{cred:1}}: password, username admin, for 192.168.1.100" + secret + "\n"
+
 	case tools.GraphitiSearchToolName:
 		var searchArgs tools.GraphitiSearchAction
 		if err := json.Unmarshal(args, &searchArgs); err != nil {
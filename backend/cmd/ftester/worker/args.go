@@ -342,6 +342,8 @@ func getStructTypeForFunction(funcName string) (reflect.Type, error) {
 		tools.SearchCodeToolName:        &tools.SearchCodeAction{},
 		tools.StoreCodeToolName:         &tools.StoreCodeAction{},
 		tools.GraphitiSearchToolName:    &tools.GraphitiSearchAction{},
+		tools.StoreCredentialToolName:   &tools.StoreCredentialAction{},
+		tools.ListCredentialsToolName:   &tools.ListCredentialsAction{},
 		tools.SearchToolName:            &tools.ComplexSearch{},
 		tools.MaintenanceToolName:       &tools.MaintenanceAction{},
 		tools.CoderToolName:             &tools.CoderAction{},
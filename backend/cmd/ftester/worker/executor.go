@@ -16,6 +16,7 @@ import (
 	"pentagi/pkg/terminal"
 	"pentagi/pkg/tools"
 	"pentagi/pkg/tools/searchers"
+	"pentagi/pkg/vault"
 
 	"github.com/sirupsen/logrus"
 	"github.com/vxcontrol/cloud/anonymizer"
@@ -186,6 +187,7 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 			te.cfg.TenantPrefix(),
 			te.dockerClient,
 			te.proxies.GetTermLogProvider(),
+			vault.New(te.db, te.cfg),
 			time.Duration(te.cfg.TerminalToolTimeout)*time.Second,
 		), nil
 
@@ -200,6 +202,7 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 			te.cfg.TenantPrefix(),
 			te.dockerClient,
 			te.proxies.GetTermLogProvider(),
+			vault.New(te.db, te.cfg),
 			time.Duration(te.cfg.TerminalToolTimeout)*time.Second,
 		), nil
 
@@ -264,10 +267,15 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 		fetcher := tools.NewBrowserPageFetcher(te.flowID, te.taskID, te.subtaskID, te.cfg, te.proxies.GetScreenshotProvider())
 		return &searcherTool{searcher: tools.NewInternalEngine(te.cfg, te.GetSummarizer(), fetcher)}, nil
 
+	case tools.StoreCredentialToolName, tools.ListCredentialsToolName:
+		return tools.NewCredentialsTool(te.flowID, te.taskID, te.subtaskID, vault.New(te.db, te.cfg)), nil
+
 	case tools.SearchInMemoryToolName:
 		return tools.NewMemoryTool(
 			te.flowID,
 			te.store,
+			te.db,
+			database.NewHybridSearchConfig(te.cfg),
 			te.proxies.GetVectorStoreLogProvider(),
 		), nil
 
@@ -282,6 +290,7 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 			te.embedder,
 			te.db,
 			te.cfg.EmbeddingMaxTextBytes,
+			database.NewHybridSearchConfig(te.cfg),
 			te.proxies.GetVectorStoreLogProvider(),
 			te.proxies.GetKnowledgeProvider(),
 		), nil
@@ -297,6 +306,7 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 			te.embedder,
 			te.db,
 			te.cfg.EmbeddingMaxTextBytes,
+			database.NewHybridSearchConfig(te.cfg),
 			te.proxies.GetVectorStoreLogProvider(),
 			te.proxies.GetKnowledgeProvider(),
 		), nil
@@ -312,6 +322,7 @@ func (te *toolExecutor) GetTool(ctx context.Context, funcName string) (tools.Too
 			te.embedder,
 			te.db,
 			te.cfg.EmbeddingMaxTextBytes,
+			database.NewHybridSearchConfig(te.cfg),
 			te.proxies.GetVectorStoreLogProvider(),
 			te.proxies.GetKnowledgeProvider(),
 		), nil
@@ -545,6 +545,7 @@ func (c *controller) ResetLLMProviderConfig(providerID string) map[string]*LLMPr
 			"LLM_SERVER_URL", "LLM_SERVER_KEY", "LLM_SERVER_MODEL",
 			"LLM_SERVER_CONFIG_PATH", "LLM_SERVER_LEGACY_REASONING",
 			"LLM_SERVER_PRESERVE_REASONING", "LLM_SERVER_PROVIDER",
+			"LLM_SERVER_PROFILE", "LLM_SERVER_TOOL_GRAMMAR",
 			"PENTAGI_LLM_SERVER_CONFIG_PATH", // local path to the LLM config file
 		}
 	}
@@ -1316,6 +1317,9 @@ func (c *controller) GetEmbedderConfig() *EmbedderConfig {
 	case "ollama":
 		// for Ollama, no API key required, but URL must be provided
 		config.Configured = config.URL.Value != ""
+	case "local":
+		// local embeddings work without a sidecar URL or API key
+		config.Configured = true
 	case "huggingface", "googleai":
 		// These require API key
 		config.Configured = config.APIKey.Value != ""
@@ -2354,6 +2358,8 @@ func (c *controller) getVariableDescription(varName string) string {
 		"LLM_SERVER_LEGACY_REASONING":       locale.EnvDesc_LLM_SERVER_LEGACY_REASONING,
 		"LLM_SERVER_PRESERVE_REASONING":     locale.EnvDesc_LLM_SERVER_PRESERVE_REASONING,
 		"LLM_SERVER_PROVIDER":               locale.EnvDesc_LLM_SERVER_PROVIDER,
+		"LLM_SERVER_PROFILE":                locale.EnvDesc_LLM_SERVER_PROFILE,
+		"LLM_SERVER_TOOL_GRAMMAR":           locale.EnvDesc_LLM_SERVER_TOOL_GRAMMAR,
 
 		"LANGFUSE_LISTEN_IP":   locale.EnvDesc_LANGFUSE_LISTEN_IP,
 		"LANGFUSE_LISTEN_PORT": locale.EnvDesc_LANGFUSE_LISTEN_PORT,
@@ -2404,6 +2410,20 @@ func (c *controller) getVariableDescription(varName string) string {
 		"EMBEDDING_STRIP_NEW_LINES": locale.EnvDesc_EMBEDDING_STRIP_NEW_LINES,
 		"EMBEDDING_MAX_TEXT_BYTES":  locale.EnvDesc_EMBEDDING_MAX_TEXT_BYTES,
 
+		"EMBEDDING_REEMBED_AUTO":       locale.EnvDesc_EMBEDDING_REEMBED_AUTO,
+		"EMBEDDING_REEMBED_BATCH_SIZE": locale.EnvDesc_EMBEDDING_REEMBED_BATCH_SIZE,
+		"EMBEDDING_REEMBED_DELAY_MS":   locale.EnvDesc_EMBEDDING_REEMBED_DELAY_MS,
+
+		"KNOWLEDGE_HYBRID_SEARCH":        locale.EnvDesc_KNOWLEDGE_HYBRID_SEARCH,
+		"KNOWLEDGE_HYBRID_RRF_K":         locale.EnvDesc_KNOWLEDGE_HYBRID_RRF_K,
+		"KNOWLEDGE_HYBRID_TEXT_WEIGHT":   locale.EnvDesc_KNOWLEDGE_HYBRID_TEXT_WEIGHT,
+		"KNOWLEDGE_HYBRID_TEXT_WEIGHTS":  locale.EnvDesc_KNOWLEDGE_HYBRID_TEXT_WEIGHTS,
+		"KNOWLEDGE_FEEDBACK_WEIGHT":      locale.EnvDesc_KNOWLEDGE_FEEDBACK_WEIGHT,
+		"KNOWLEDGE_POPULARITY_WEIGHT":    locale.EnvDesc_KNOWLEDGE_POPULARITY_WEIGHT,
+		"KNOWLEDGE_IMPORT_MAX_BYTES":     locale.EnvDesc_KNOWLEDGE_IMPORT_MAX_BYTES,
+		"KNOWLEDGE_IMPORT_CHUNK_SIZE":    locale.EnvDesc_KNOWLEDGE_IMPORT_CHUNK_SIZE,
+		"KNOWLEDGE_IMPORT_CHUNK_OVERLAP": locale.EnvDesc_KNOWLEDGE_IMPORT_CHUNK_OVERLAP,
+
 		"ASK_USER": locale.EnvDesc_ASK_USER,
 
 		"ASSISTANT_USE_AGENTS": locale.EnvDesc_ASSISTANT_USE_AGENTS,
@@ -2468,6 +2488,8 @@ func (c *controller) getVariableDescription(varName string) string {
 		"PUBLIC_URL":                        locale.EnvDesc_PUBLIC_URL,
 		"CORS_ORIGINS":                      locale.EnvDesc_CORS_ORIGINS,
 		"COOKIE_SIGNING_SALT":               locale.EnvDesc_COOKIE_SIGNING_SALT,
+		"CREDENTIAL_VAULT_KEY":              locale.EnvDesc_CREDENTIAL_VAULT_KEY,
+		"LLM_ANONYMIZATION_ENABLED":         locale.EnvDesc_LLM_ANONYMIZATION_ENABLED,
 		"PROXY_URL":                         locale.EnvDesc_PROXY_URL,
 		"EXTERNAL_SSL_CA_PATH":              locale.EnvDesc_EXTERNAL_SSL_CA_PATH,
 		"EXTERNAL_SSL_INSECURE":             locale.EnvDesc_EXTERNAL_SSL_INSECURE,
@@ -2495,6 +2517,7 @@ func (c *controller) getVariableDescription(varName string) string {
 
 		"LANGFUSE_EE_LICENSE_KEY": locale.EnvDesc_LANGFUSE_EE_LICENSE_KEY,
 
+		"KNOWLEDGE_GRAPH_BACKEND":               locale.EnvDesc_KNOWLEDGE_GRAPH_BACKEND,
 		"GRAPHITI_ENABLED":                      locale.EnvDesc_GRAPHITI_ENABLED,
 		"GRAPHITI_URL":                          locale.EnvDesc_GRAPHITI_URL,
 		"GRAPHITI_TIMEOUT":                      locale.EnvDesc_GRAPHITI_TIMEOUT,
@@ -2588,7 +2611,8 @@ var maskedVariables = map[string]bool{
 	"LANGFUSE_AUTH_CUSTOM_CLIENT_SECRET": true,
 
 	// server settings
-	"COOKIE_SIGNING_SALT": true,
+	"COOKIE_SIGNING_SALT":  true,
+	"CREDENTIAL_VAULT_KEY": true,
 }
 
 // isVariableMasked returns true if the variable should be masked in display
@@ -2640,6 +2664,8 @@ var criticalVariables = map[string]bool{
 	"LLM_SERVER_LEGACY_REASONING":       true,
 	"LLM_SERVER_PRESERVE_REASONING":     true,
 	"LLM_SERVER_PROVIDER":               true,
+	"LLM_SERVER_PROFILE":                true,
+	"LLM_SERVER_TOOL_GRAMMAR":           true,
 
 	// tools changes
 	"DUCKDUCKGO_ENABLED":      true,
@@ -2677,6 +2703,22 @@ var criticalVariables = map[string]bool{
 	"EMBEDDING_STRIP_NEW_LINES": true,
 	"EMBEDDING_MAX_TEXT_BYTES":  true,
 
+	// Embedding model migration changes
+	"EMBEDDING_REEMBED_AUTO":       true,
+	"EMBEDDING_REEMBED_BATCH_SIZE": true,
+	"EMBEDDING_REEMBED_DELAY_MS":   true,
+
+	// Knowledge retrieval changes
+	"KNOWLEDGE_HYBRID_SEARCH":        true,
+	"KNOWLEDGE_HYBRID_RRF_K":         true,
+	"KNOWLEDGE_HYBRID_TEXT_WEIGHT":   true,
+	"KNOWLEDGE_HYBRID_TEXT_WEIGHTS":  true,
+	"KNOWLEDGE_FEEDBACK_WEIGHT":      true,
+	"KNOWLEDGE_POPULARITY_WEIGHT":    true,
+	"KNOWLEDGE_IMPORT_MAX_BYTES":     true,
+	"KNOWLEDGE_IMPORT_CHUNK_SIZE":    true,
+	"KNOWLEDGE_IMPORT_CHUNK_OVERLAP": true,
+
 	// Docker configuration changes
 	"DOCKER_INSIDE":                    true,
 	"DOCKER_NET_ADMIN":                 true,
@@ -2697,6 +2739,7 @@ var criticalVariables = map[string]bool{
 	"OTEL_HOST": true,
 
 	// graphiti changes
+	"KNOWLEDGE_GRAPH_BACKEND":               true,
 	"GRAPHITI_ENABLED":                      true,
 	"GRAPHITI_URL":                          true,
 	"GRAPHITI_TIMEOUT":                      true,
@@ -2737,6 +2780,8 @@ var criticalVariables = map[string]bool{
 	"PUBLIC_URL":                       true,
 	"CORS_ORIGINS":                     true,
 	"COOKIE_SIGNING_SALT":              true,
+	"CREDENTIAL_VAULT_KEY":             true,
+	"LLM_ANONYMIZATION_ENABLED":        true,
 	"PROXY_URL":                        true,
 	"EXTERNAL_SSL_CA_PATH":             true,
 	"EXTERNAL_SSL_INSECURE":            true,
@@ -1853,6 +1853,8 @@ Change providers carefully - different embedders produce incompatible vectors re
 	EmbedderProviderGoogleAIDesc    = "Google AI embedding models (embedding-001)"
 	EmbedderProviderVoyageAI        = "VoyageAI"
 	EmbedderProviderVoyageAIDesc    = "VoyageAI embedding API"
+	EmbedderProviderLocal           = "Local (offline)"
+	EmbedderProviderLocalDesc       = "Local sidecar or in-process hash embeddings, no remote calls"
 	EmbedderProviderDisabled        = "Disabled"
 	EmbedderProviderDisabledDesc    = "Disable embeddings functionality completely"
 
@@ -1864,6 +1866,7 @@ Change providers carefully - different embedders produce incompatible vectors re
 	EmbedderURLPlaceholderHuggingFace = "https://api-inference.huggingface.co"
 	EmbedderURLPlaceholderGoogleAI    = "Not supported - uses default endpoint"
 	EmbedderURLPlaceholderVoyageAI    = "Not supported - uses default endpoint"
+	EmbedderURLPlaceholderLocal       = "http://embedder:8080/embed (empty for in-process)"
 
 	EmbedderAPIKeyPlaceholderOllama      = "Not required for local models"
 	EmbedderAPIKeyPlaceholderMistral     = "Mistral API key"
@@ -1871,6 +1874,7 @@ Change providers carefully - different embedders produce incompatible vectors re
 	EmbedderAPIKeyPlaceholderHuggingFace = "HuggingFace API key"
 	EmbedderAPIKeyPlaceholderGoogleAI    = "Google AI API key"
 	EmbedderAPIKeyPlaceholderVoyageAI    = "VoyageAI API key"
+	EmbedderAPIKeyPlaceholderLocal       = "Optional sidecar bearer token"
 	EmbedderAPIKeyPlaceholderDefault     = "API key for the provider"
 
 	EmbedderModelPlaceholderOpenAI      = "text-embedding-3-small"
@@ -1880,6 +1884,7 @@ Change providers carefully - different embedders produce incompatible vectors re
 	EmbedderModelPlaceholderHuggingFace = "sentence-transformers/all-MiniLM-L6-v2"
 	EmbedderModelPlaceholderGoogleAI    = "gemini-embedding-001"
 	EmbedderModelPlaceholderVoyageAI    = "voyage-2"
+	EmbedderModelPlaceholderLocal       = "hash-768 or sidecar model name"
 	EmbedderModelPlaceholderDefault     = "Model name"
 
 	// Provider IDs for internal use
@@ -1891,6 +1896,7 @@ Change providers carefully - different embedders produce incompatible vectors re
 	EmbedderProviderIDHuggingFace = "huggingface"
 	EmbedderProviderIDGoogleAI    = "googleai"
 	EmbedderProviderIDVoyageAI    = "voyageai"
+	EmbedderProviderIDLocal       = "local"
 	EmbedderProviderIDDisabled    = "none"
 
 	EmbedderHelpGeneral = `Embeddings convert text into vectors for semantic search and knowledge storage. This enables PentAGI to understand meaning rather than just keywords, making search results more relevant and intelligent.
@@ -1991,6 +1997,16 @@ Recommended models:
 • voyage-large-2 (highest quality, 1536 dimensions)
 • voyage-code-2 (code embeddings, 1536 dimensions)`
 
+	EmbedderHelpLocal = `Offline embeddings for air-gapped engagements.
+
+With a URL, texts are posted to a local sidecar (ONNX or gguf runtime behind a thin adapter):
+• request: {"model": "...", "input": ["text", ...]}
+• response: {"embeddings": [[0.1, ...], ...]}
+
+Without a URL, vectors are computed in-process by a deterministic hash model:
+• hash-768 (default), or hash-<dimensions> up to 4096
+• captures lexical similarity only, best suited for tests`
+
 	EmbedderHelpDisabled = `Disables all embedding functionality.
 
 This will:
@@ -2359,6 +2375,8 @@ const (
 	EnvDesc_LLM_SERVER_LEGACY_REASONING       = "Custom LLM Legacy Reasoning"
 	EnvDesc_LLM_SERVER_PRESERVE_REASONING     = "Custom LLM Preserve Reasoning Content"
 	EnvDesc_LLM_SERVER_PROVIDER               = "Custom LLM Provider Name"
+	EnvDesc_LLM_SERVER_PROFILE                = "Custom LLM Server Profile"
+	EnvDesc_LLM_SERVER_TOOL_GRAMMAR           = "Custom LLM GBNF Tool Grammar"
 
 	EnvDesc_LANGFUSE_LISTEN_IP   = "Langfuse Listen IP"
 	EnvDesc_LANGFUSE_LISTEN_PORT = "Langfuse Listen Port"
@@ -2409,6 +2427,20 @@ const (
 	EnvDesc_EMBEDDING_STRIP_NEW_LINES = "Embedding Strip New Lines"
 	EnvDesc_EMBEDDING_MAX_TEXT_BYTES  = "Embedding Max Text Bytes"
 
+	EnvDesc_EMBEDDING_REEMBED_AUTO       = "Re-embed Knowledge on Model Change"
+	EnvDesc_EMBEDDING_REEMBED_BATCH_SIZE = "Re-embedding Batch Size"
+	EnvDesc_EMBEDDING_REEMBED_DELAY_MS   = "Re-embedding Batch Delay (ms)"
+
+	EnvDesc_KNOWLEDGE_HYBRID_SEARCH        = "Knowledge Hybrid Search"
+	EnvDesc_KNOWLEDGE_HYBRID_RRF_K         = "Knowledge Hybrid RRF K"
+	EnvDesc_KNOWLEDGE_HYBRID_TEXT_WEIGHT   = "Knowledge Full-Text Weight"
+	EnvDesc_KNOWLEDGE_HYBRID_TEXT_WEIGHTS  = "Knowledge Full-Text Weights by Type"
+	EnvDesc_KNOWLEDGE_FEEDBACK_WEIGHT      = "Knowledge Feedback Weight"
+	EnvDesc_KNOWLEDGE_POPULARITY_WEIGHT    = "Knowledge Popularity Weight"
+	EnvDesc_KNOWLEDGE_IMPORT_MAX_BYTES     = "Knowledge Import Max Upload Size"
+	EnvDesc_KNOWLEDGE_IMPORT_CHUNK_SIZE    = "Knowledge Import Chunk Size"
+	EnvDesc_KNOWLEDGE_IMPORT_CHUNK_OVERLAP = "Knowledge Import Chunk Overlap"
+
 	EnvDesc_ASK_USER = "Human-in-the-loop"
 
 	EnvDesc_ASSISTANT_USE_AGENTS = "Enable multi-agent mode for assistant"
@@ -2463,6 +2495,8 @@ const (
 	EnvDesc_PUBLIC_URL                        = "PentAGI Public URL"
 	EnvDesc_CORS_ORIGINS                      = "PentAGI CORS Origins"
 	EnvDesc_COOKIE_SIGNING_SALT               = "PentAGI Cookie Signing Salt"
+	EnvDesc_CREDENTIAL_VAULT_KEY              = "PentAGI Credential Vault Key"
+	EnvDesc_LLM_ANONYMIZATION_ENABLED         = "PentAGI LLM Traffic Anonymization"
 	EnvDesc_DATABASE_EXTENSIONS_SCHEMA        = "PostgreSQL Extensions Schema"
 	EnvDesc_DATABASE_SEARCH_PATH_VIA_OPTIONS  = "PostgreSQL Search Path via Options"
 	EnvDesc_PROXY_URL                         = "HTTP/HTTPS Proxy URL"
@@ -2503,6 +2537,7 @@ const (
 	EnvDesc_LANGFUSE_EE_LICENSE_KEY   = "Langfuse Enterprise License Key"
 	EnvDesc_PENTAGI_POSTGRES_PASSWORD = "PentAGI PostgreSQL Password"
 
+	EnvDesc_KNOWLEDGE_GRAPH_BACKEND               = "Knowledge Graph Backend"
 	EnvDesc_GRAPHITI_ENABLED                      = "Enable Graphiti Integration"
 	EnvDesc_GRAPHITI_URL                          = "Graphiti Server URL"
 	EnvDesc_GRAPHITI_TIMEOUT                      = "Graphiti Request Timeout"
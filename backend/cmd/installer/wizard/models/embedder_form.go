@@ -153,6 +153,18 @@ func initEmbeddingProviders() map[string]*EmbeddingProviderInfo {
 			SupportsModel:     true,
 			HelpText:          locale.EmbedderHelpVoyageAI,
 		},
+		locale.EmbedderProviderIDLocal: {
+			ID:                locale.EmbedderProviderIDLocal,
+			Name:              locale.EmbedderProviderLocal,
+			Description:       locale.EmbedderProviderLocalDesc,
+			URLPlaceholder:    locale.EmbedderURLPlaceholderLocal,
+			APIKeyPlaceholder: locale.EmbedderAPIKeyPlaceholderLocal,
+			ModelPlaceholder:  locale.EmbedderModelPlaceholderLocal,
+			RequiresAPIKey:    false,
+			SupportsURL:       true,
+			SupportsModel:     true,
+			HelpText:          locale.EmbedderHelpLocal,
+		},
 		locale.EmbedderProviderIDDisabled: {
 			ID:                locale.EmbedderProviderIDDisabled,
 			Name:              locale.EmbedderProviderDisabled,
@@ -179,6 +191,7 @@ func (m *EmbedderFormModel) initializeProviderList(styles styles.Styles) {
 		{Value: locale.EmbedderProviderIDHuggingFace, Display: locale.EmbedderProviderHuggingFace},
 		{Value: locale.EmbedderProviderIDGoogleAI, Display: locale.EmbedderProviderGoogleAI},
 		{Value: locale.EmbedderProviderIDVoyageAI, Display: locale.EmbedderProviderVoyageAI},
+		{Value: locale.EmbedderProviderIDLocal, Display: locale.EmbedderProviderLocal},
 		{Value: locale.EmbedderProviderIDDisabled, Display: locale.EmbedderProviderDisabled},
 	}
 
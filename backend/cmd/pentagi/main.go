@@ -19,10 +19,13 @@ import (
 	"pentagi/pkg/docker"
 	"pentagi/pkg/graph/subscriptions"
 	obs "pentagi/pkg/observability"
+	"pentagi/pkg/observability/langfuse"
 	"pentagi/pkg/observability/profiling"
 	"pentagi/pkg/providers"
 	router "pentagi/pkg/server"
+	"pentagi/pkg/vault"
 	"pentagi/pkg/version"
+	"pentagi/pkg/webhooks"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq"
@@ -72,7 +75,7 @@ func main() {
 
 	// Telemetry is optional — degrade to a no-op observer on init failure so an
 	// unreachable collector can't take the app down.
-	lfclient, err := obs.NewLangfuseClient(ctx, cfg)
+	lfclient, err := obs.NewLangfuseClient(ctx, cfg, langfuse.WithRedactor(vault.Redact))
 	if err != nil && !errors.Is(err, obs.ErrNotConfigured) {
 		logrus.WithError(err).Warn("langfuse telemetry disabled: client init failed")
 		lfclient = nil
@@ -178,14 +181,40 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("LLM provider controller initialization failed")
 	}
-	subscriptions := subscriptions.NewSubscriptionsController()
-	controller := controller.NewFlowController(queries, cfg, client, providers, subscriptions)
+	dispatcher, err := webhooks.NewDispatcher(queries, cfg)
+	if err != nil {
+		logrus.WithError(err).Fatal("Webhooks dispatcher initialization failed")
+	}
+	dispatcher.Start(ctx)
+
+	// several replicas share the subscription events through the transport,
+	// a single one keeps them in memory
+	transport, err := subscriptions.NewTransport(cfg, queries)
+	if err != nil {
+		logrus.WithError(err).Fatal("Subscriptions transport initialization failed")
+	}
+	if transport != nil {
+		defer transport.Close()
+	}
+
+	// every flow is driven by the replica holding its lease, the elected leader
+	// hands the flows of dead replicas over to the live ones
+	leases := controller.NewFlowLeases(queries, cfg.InstanceID, time.Duration(cfg.FlowLeaseTTL)*time.Second)
+	leases.Start(ctx)
+	logrus.WithFields(logrus.Fields{
+		"instance_id": cfg.InstanceID,
+		"transport":   cfg.SubscriptionsTransport,
+	}).Info("Backend instance registered")
+
+	subscriptions := subscriptions.NewSubscriptionsController(queries, dispatcher, transport)
+	controller := controller.NewFlowController(queries, cfg, client, providers, subscriptions, leases)
 
 	if err := controller.LoadFlows(ctx); err != nil {
 		logrus.WithError(err).Fatal("Active flows restoration failed")
 	}
+	controller.SuperviseFlows(ctx)
 
-	r := router.NewRouter(queries, orm, cfg, providers, controller, subscriptions, client)
+	r := router.NewRouter(queries, orm, cfg, providers, controller, subscriptions, client, dispatcher)
 
 	// Launch HTTP/HTTPS server in background goroutine
 	serverErrChan := make(chan error, 1)
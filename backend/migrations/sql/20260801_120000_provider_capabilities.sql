@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE provider_capabilities (
+  id           BIGINT           PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  user_id      BIGINT           NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  type         PROVIDER_TYPE    NOT NULL,
+  model        TEXT             NOT NULL,
+  agent_types  TEXT[]           NOT NULL DEFAULT '{}',
+  tool_calling DOUBLE PRECISION NULL,
+  json_mode    BOOLEAN          NULL,
+  reasoning    BOOLEAN          NOT NULL DEFAULT FALSE,
+  streaming    BOOLEAN          NULL,
+  max_context  BIGINT           NOT NULL DEFAULT 0,
+  tests_total  BIGINT           NOT NULL DEFAULT 0,
+  tests_passed BIGINT           NOT NULL DEFAULT 0,
+  created_at   TIMESTAMPTZ      DEFAULT CURRENT_TIMESTAMP,
+  updated_at   TIMESTAMPTZ      DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT provider_capabilities_user_type_model_unique UNIQUE (user_id, type, model),
+  CONSTRAINT provider_capabilities_model_not_empty        CHECK (length(trim(model)) > 0),
+  CONSTRAINT provider_capabilities_tool_calling_range     CHECK (tool_calling IS NULL OR (tool_calling >= 0 AND tool_calling <= 1))
+);
+
+CREATE INDEX provider_capabilities_user_id_idx   ON provider_capabilities(user_id);
+CREATE INDEX provider_capabilities_user_type_idx ON provider_capabilities(user_id, type);
+
+CREATE OR REPLACE TRIGGER update_provider_capabilities_modified
+  BEFORE UPDATE ON provider_capabilities
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS provider_capabilities;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Lexical indices backing hybrid knowledge retrieval (SearchKnowledgeDocumentsText).
+-- Expressions must match the query exactly for the planner to use them.
+CREATE INDEX IF NOT EXISTS langchain_pg_embedding_document_tsv_idx
+  ON langchain_pg_embedding USING GIN (to_tsvector('english', COALESCE(document, '')));
+
+CREATE INDEX IF NOT EXISTS langchain_pg_embedding_document_trgm_idx
+  ON langchain_pg_embedding USING GIN ((COALESCE(document, '')::text) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS langchain_pg_embedding_document_tsv_idx;
+DROP INDEX IF EXISTS langchain_pg_embedding_document_trgm_idx;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE KNOWLEDGE_IMPORT_STATUS AS ENUM ('pending', 'running', 'finished', 'failed');
+
+-- Background bulk imports of documents into the knowledge store.
+CREATE TABLE knowledge_import_jobs (
+  id               BIGINT                  PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  user_id          BIGINT                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status           KNOWLEDGE_IMPORT_STATUS NOT NULL DEFAULT 'pending',
+  source           TEXT                    NOT NULL,
+  options          JSON                    NOT NULL DEFAULT '{}',
+  total_files      BIGINT                  NOT NULL DEFAULT 0,
+  skipped_files    BIGINT                  NOT NULL DEFAULT 0,
+  total_chunks     BIGINT                  NOT NULL DEFAULT 0,
+  processed_chunks BIGINT                  NOT NULL DEFAULT 0,
+  created_docs     BIGINT                  NOT NULL DEFAULT 0,
+  duplicate_docs   BIGINT                  NOT NULL DEFAULT 0,
+  failed_docs      BIGINT                  NOT NULL DEFAULT 0,
+  error            TEXT                    NULL,
+  created_at       TIMESTAMPTZ             DEFAULT CURRENT_TIMESTAMP,
+  updated_at       TIMESTAMPTZ             DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT knowledge_import_jobs_source_not_empty CHECK (length(trim(source)) > 0)
+);
+
+CREATE INDEX knowledge_import_jobs_user_id_idx ON knowledge_import_jobs(user_id);
+CREATE INDEX knowledge_import_jobs_status_idx  ON knowledge_import_jobs(status);
+
+CREATE OR REPLACE TRIGGER update_knowledge_import_jobs_modified
+  BEFORE UPDATE ON knowledge_import_jobs
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+-- Content hash lookups used to skip duplicate chunks during imports.
+CREATE INDEX IF NOT EXISTS langchain_pg_embedding_document_md5_idx
+  ON langchain_pg_embedding (md5(COALESCE(document, '')));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS langchain_pg_embedding_document_md5_idx;
+DROP TABLE IF EXISTS knowledge_import_jobs;
+DROP TYPE IF EXISTS KNOWLEDGE_IMPORT_STATUS;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Archive imports may overwrite existing documents on conflict.
+ALTER TABLE knowledge_import_jobs ADD COLUMN updated_docs BIGINT NOT NULL DEFAULT 0;
+
+-- Provenance lookups used to detect documents imported from another instance.
+CREATE INDEX IF NOT EXISTS langchain_pg_embedding_origin_id_idx
+  ON langchain_pg_embedding ((cmetadata ->> 'origin_id'))
+  WHERE (cmetadata ->> 'origin_id') IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS langchain_pg_embedding_origin_id_idx;
+ALTER TABLE knowledge_import_jobs DROP COLUMN IF EXISTS updated_docs;
+-- +goose StatementEnd
@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Embedding model that produced the vectors of a langchain_pg_collection
+-- entry. Kept apart from the collection cmetadata because langchaingo
+-- overwrites that column every time a vector store is opened.
+CREATE TABLE IF NOT EXISTS embedding_collections (
+  name       VARCHAR     PRIMARY KEY,
+  provider   TEXT        NOT NULL,
+  model      TEXT        NOT NULL,
+  dimensions INTEGER     NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE OR REPLACE TRIGGER update_embedding_collections_modified
+  BEFORE UPDATE ON embedding_collections
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+CREATE TYPE EMBEDDING_MIGRATION_STATUS AS ENUM ('running', 'completed', 'failed');
+
+-- Background re-embedding of a collection after the embedding model changed.
+CREATE TABLE embedding_migrations (
+  id                BIGINT                     PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  collection        VARCHAR                    NOT NULL,
+  status            EMBEDDING_MIGRATION_STATUS NOT NULL DEFAULT 'running',
+  source_provider   TEXT                       NOT NULL,
+  source_model      TEXT                       NOT NULL,
+  source_dimensions INTEGER                    NOT NULL DEFAULT 0,
+  target_provider   TEXT                       NOT NULL,
+  target_model      TEXT                       NOT NULL,
+  target_dimensions INTEGER                    NOT NULL DEFAULT 0,
+  total_docs        BIGINT                     NOT NULL DEFAULT 0,
+  processed_docs    BIGINT                     NOT NULL DEFAULT 0,
+  failed_docs       BIGINT                     NOT NULL DEFAULT 0,
+  error             TEXT                       NULL,
+  created_at        TIMESTAMPTZ                DEFAULT CURRENT_TIMESTAMP,
+  updated_at        TIMESTAMPTZ                DEFAULT CURRENT_TIMESTAMP,
+  finished_at       TIMESTAMPTZ                NULL
+);
+
+CREATE INDEX embedding_migrations_collection_idx ON embedding_migrations(collection);
+CREATE UNIQUE INDEX embedding_migrations_running_idx
+  ON embedding_migrations(collection) WHERE status = 'running';
+
+CREATE OR REPLACE TRIGGER update_embedding_migrations_modified
+  BEFORE UPDATE ON embedding_migrations
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+-- Vectors of the target model are built here while searches keep using the
+-- old ones in embedding; a completed migration moves them over in one UPDATE.
+ALTER TABLE langchain_pg_embedding ADD COLUMN IF NOT EXISTS embedding_next vector;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE langchain_pg_embedding DROP COLUMN IF EXISTS embedding_next;
+DROP TABLE IF EXISTS embedding_migrations;
+DROP TYPE IF EXISTS EMBEDDING_MIGRATION_STATUS;
+DROP TABLE IF EXISTS embedding_collections;
+-- +goose StatementEnd
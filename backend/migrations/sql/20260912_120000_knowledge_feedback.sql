@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Retrieval counts and usefulness reported by agents after using search
+-- results. Kept apart from cmetadata so frequent counter updates never race
+-- with edits of the document metadata.
+CREATE TABLE knowledge_document_stats (
+  document_id       UUID        PRIMARY KEY REFERENCES langchain_pg_embedding(uuid) ON DELETE CASCADE,
+  retrievals        BIGINT      NOT NULL DEFAULT 0,
+  useful            BIGINT      NOT NULL DEFAULT 0,
+  not_useful        BIGINT      NOT NULL DEFAULT 0,
+  last_retrieved_at TIMESTAMPTZ NULL,
+  created_at        TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  updated_at        TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE OR REPLACE TRIGGER update_knowledge_document_stats_modified
+  BEFORE UPDATE ON knowledge_document_stats
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+-- Operator votes, one per user and document.
+CREATE TABLE knowledge_document_votes (
+  document_id UUID        NOT NULL REFERENCES langchain_pg_embedding(uuid) ON DELETE CASCADE,
+  user_id     BIGINT      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  vote        SMALLINT    NOT NULL CHECK (vote IN (-1, 1)),
+  created_at  TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  updated_at  TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (document_id, user_id)
+);
+
+CREATE INDEX knowledge_document_votes_user_id_idx ON knowledge_document_votes(user_id);
+
+CREATE OR REPLACE TRIGGER update_knowledge_document_votes_modified
+  BEFORE UPDATE ON knowledge_document_votes
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS knowledge_document_votes;
+DROP TABLE IF EXISTS knowledge_document_stats;
+-- +goose StatementEnd
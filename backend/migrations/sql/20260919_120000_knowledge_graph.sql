@@ -0,0 +1,88 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Built-in knowledge graph used instead of Graphiti when
+-- KNOWLEDGE_GRAPH_BACKEND=postgres. Episodes keep the raw agent responses
+-- and tool executions, entities and relations are extracted from them.
+CREATE TABLE graph_episodes (
+  id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  group_id           TEXT        NOT NULL,
+  name               TEXT        NOT NULL,
+  author             TEXT        NOT NULL DEFAULT '',
+  source_description TEXT        NOT NULL DEFAULT '',
+  content            TEXT        NOT NULL,
+  success            BOOLEAN     NULL,
+  valid_at           TIMESTAMPTZ NOT NULL,
+  created_at         TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX graph_episodes_group_id_valid_at_idx ON graph_episodes(group_id, valid_at DESC);
+CREATE INDEX graph_episodes_content_fts_idx ON graph_episodes USING GIN (to_tsvector('english', content));
+
+-- Engagement entities such as hosts, services, credentials, accounts and
+-- vulnerabilities, unique by label and name within a group.
+CREATE TABLE graph_entities (
+  id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  group_id      TEXT        NOT NULL,
+  label         TEXT        NOT NULL,
+  name          TEXT        NOT NULL,
+  summary       TEXT        NOT NULL DEFAULT '',
+  mentions      BIGINT      NOT NULL DEFAULT 1,
+  first_seen_at TIMESTAMPTZ NOT NULL,
+  last_seen_at  TIMESTAMPTZ NOT NULL,
+  created_at    TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  updated_at    TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT graph_entities_group_label_name_key UNIQUE (group_id, label, name)
+);
+
+CREATE INDEX graph_entities_group_id_last_seen_at_idx ON graph_entities(group_id, last_seen_at DESC);
+CREATE INDEX graph_entities_fts_idx ON graph_entities
+  USING GIN (to_tsvector('english', label || ' ' || name || ' ' || summary));
+
+CREATE OR REPLACE TRIGGER update_graph_entities_modified
+  BEFORE UPDATE ON graph_entities
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+-- Directed facts between entities; the episode is the latest one that stated the fact.
+CREATE TABLE graph_relations (
+  id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  group_id     TEXT        NOT NULL,
+  source_id    UUID        NOT NULL REFERENCES graph_entities(id) ON DELETE CASCADE,
+  target_id    UUID        NOT NULL REFERENCES graph_entities(id) ON DELETE CASCADE,
+  name         TEXT        NOT NULL,
+  fact         TEXT        NOT NULL,
+  mentions     BIGINT      NOT NULL DEFAULT 1,
+  episode_id   UUID        NULL REFERENCES graph_episodes(id) ON DELETE SET NULL,
+  valid_at     TIMESTAMPTZ NOT NULL,
+  last_seen_at TIMESTAMPTZ NOT NULL,
+  created_at   TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  updated_at   TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT graph_relations_source_target_name_key UNIQUE (source_id, target_id, name)
+);
+
+CREATE INDEX graph_relations_target_id_idx ON graph_relations(target_id);
+CREATE INDEX graph_relations_group_id_last_seen_at_idx ON graph_relations(group_id, last_seen_at DESC);
+CREATE INDEX graph_relations_fact_fts_idx ON graph_relations USING GIN (to_tsvector('english', fact));
+
+CREATE OR REPLACE TRIGGER update_graph_relations_modified
+  BEFORE UPDATE ON graph_relations
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+-- Source episodes of every entity.
+CREATE TABLE graph_entity_episodes (
+  entity_id  UUID NOT NULL REFERENCES graph_entities(id) ON DELETE CASCADE,
+  episode_id UUID NOT NULL REFERENCES graph_episodes(id) ON DELETE CASCADE,
+  PRIMARY KEY (entity_id, episode_id)
+);
+
+CREATE INDEX graph_entity_episodes_episode_id_idx ON graph_entity_episodes(episode_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS graph_entity_episodes;
+DROP TABLE IF EXISTS graph_relations;
+DROP TABLE IF EXISTS graph_entities;
+DROP TABLE IF EXISTS graph_episodes;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE CREDENTIAL_TYPE AS ENUM ('password','hash','token','key','other');
+
+-- Credentials discovered or provided during a flow. The secret is sealed
+-- with AES-256-GCM under a key derived for the flow, never stored in clear.
+CREATE TABLE flow_credentials (
+  id          BIGINT          PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  flow_id     BIGINT          NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+  type        CREDENTIAL_TYPE NOT NULL DEFAULT 'password',
+  username    TEXT            NOT NULL DEFAULT '',
+  secret      BYTEA           NOT NULL,
+  source_host TEXT            NOT NULL DEFAULT '',
+  toolcall_id BIGINT          NULL REFERENCES toolcalls(id) ON DELETE SET NULL,
+  created_at  TIMESTAMPTZ     DEFAULT CURRENT_TIMESTAMP,
+  updated_at  TIMESTAMPTZ     DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT flow_credentials_flow_type_username_host_key UNIQUE (flow_id, type, username, source_host)
+);
+
+CREATE INDEX flow_credentials_toolcall_id_idx ON flow_credentials(toolcall_id);
+
+CREATE OR REPLACE TRIGGER update_flow_credentials_modified
+  BEFORE UPDATE ON flow_credentials
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flow_credentials;
+DROP TYPE IF EXISTS CREDENTIAL_TYPE;
+-- +goose StatementEnd
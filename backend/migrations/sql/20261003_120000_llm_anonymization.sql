@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Patterns a user adds to the built-in anonymization patterns. A row named
+-- like a built-in pattern replaces it, or turns it off when not enabled.
+CREATE TABLE anonymization_patterns (
+  id         BIGINT      PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  user_id    BIGINT      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name       TEXT        NOT NULL,
+  regex      TEXT        NOT NULL DEFAULT '',
+  enabled    BOOLEAN     NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT anonymization_patterns_user_name_key UNIQUE (user_id, name)
+);
+
+CREATE OR REPLACE TRIGGER update_anonymization_patterns_modified
+  BEFORE UPDATE ON anonymization_patterns
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+-- Values replaced with placeholders in the LLM traffic of a flow. The value is
+-- sealed with the key of the flow; the digest is keyed too and only serves to
+-- find the placeholder of a value which was already seen.
+CREATE TABLE flow_anonymization_entries (
+  id           BIGINT      PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  flow_id      BIGINT      NOT NULL REFERENCES flows(id) ON DELETE CASCADE,
+  kind         TEXT        NOT NULL,
+  value        BYTEA       NOT NULL,
+  value_digest BYTEA       NOT NULL,
+  created_at   TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT flow_anonymization_entries_flow_digest_key UNIQUE (flow_id, value_digest)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS flow_anonymization_entries;
+DROP TABLE IF EXISTS anonymization_patterns;
+-- +goose StatementEnd
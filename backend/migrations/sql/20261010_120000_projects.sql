@@ -0,0 +1,88 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO privileges (role_id, name) VALUES
+  (1, 'projects.admin'),
+  (1, 'projects.create'),
+  (1, 'projects.view'),
+  (1, 'projects.edit'),
+  (1, 'projects.delete'),
+  (2, 'projects.create'),
+  (2, 'projects.view'),
+  (2, 'projects.edit'),
+  (2, 'projects.delete')
+  ON CONFLICT DO NOTHING;
+
+-- Owners manage the members and delete what the project shares, editors also
+-- steer shared flows and change shared items, viewers only watch them.
+CREATE TYPE PROJECT_ROLE AS ENUM ('owner','editor','viewer');
+
+CREATE TABLE projects (
+  id          BIGINT      PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  name        TEXT        NOT NULL,
+  description TEXT        NOT NULL DEFAULT '',
+  user_id     BIGINT      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at  TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  updated_at  TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT projects_name_not_empty CHECK (length(trim(name)) > 0)
+);
+
+CREATE INDEX projects_user_id_idx ON projects(user_id);
+
+CREATE OR REPLACE TRIGGER update_projects_modified
+  BEFORE UPDATE ON projects
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+CREATE TABLE project_members (
+  project_id BIGINT       NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  user_id    BIGINT       NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role       PROJECT_ROLE NOT NULL DEFAULT 'viewer',
+  created_at TIMESTAMPTZ  DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ  DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX project_members_user_id_idx ON project_members(user_id);
+
+CREATE OR REPLACE TRIGGER update_project_members_modified
+  BEFORE UPDATE ON project_members
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+-- Items stay owned by their user; a project only shares them with its members
+-- and deleting the project stops sharing them.
+ALTER TABLE flows ADD COLUMN project_id BIGINT NULL REFERENCES projects(id) ON DELETE SET NULL;
+ALTER TABLE flow_templates ADD COLUMN project_id BIGINT NULL REFERENCES projects(id) ON DELETE SET NULL;
+ALTER TABLE user_resources ADD COLUMN project_id BIGINT NULL REFERENCES projects(id) ON DELETE SET NULL;
+ALTER TABLE providers ADD COLUMN project_id BIGINT NULL REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX flows_project_id_idx ON flows(project_id) WHERE project_id IS NOT NULL;
+CREATE INDEX flow_templates_project_id_idx ON flow_templates(project_id) WHERE project_id IS NOT NULL;
+CREATE INDEX user_resources_project_id_idx ON user_resources(project_id) WHERE project_id IS NOT NULL;
+CREATE INDEX providers_project_id_idx ON providers(project_id) WHERE project_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS providers_project_id_idx;
+DROP INDEX IF EXISTS user_resources_project_id_idx;
+DROP INDEX IF EXISTS flow_templates_project_id_idx;
+DROP INDEX IF EXISTS flows_project_id_idx;
+
+ALTER TABLE providers DROP COLUMN IF EXISTS project_id;
+ALTER TABLE user_resources DROP COLUMN IF EXISTS project_id;
+ALTER TABLE flow_templates DROP COLUMN IF EXISTS project_id;
+ALTER TABLE flows DROP COLUMN IF EXISTS project_id;
+
+DROP TABLE IF EXISTS project_members;
+DROP TABLE IF EXISTS projects;
+DROP TYPE IF EXISTS PROJECT_ROLE;
+
+DELETE FROM privileges WHERE name IN (
+  'projects.admin',
+  'projects.create',
+  'projects.view',
+  'projects.edit',
+  'projects.delete'
+);
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO privileges (role_id, name) VALUES
+  (1, 'roles.edit')
+  ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM privileges WHERE name = 'roles.edit';
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A token without privileges inherits those of its role, otherwise it gets the
+-- listed ones its role still holds. Empty flow, template and address lists
+-- don't restrict the token.
+ALTER TABLE api_tokens
+  ADD COLUMN privileges   TEXT[]      NULL,
+  ADD COLUMN flow_ids     BIGINT[]    NULL,
+  ADD COLUMN template_ids BIGINT[]    NULL,
+  ADD COLUMN allowed_ips  TEXT[]      NULL,
+  ADD COLUMN last_used_at TIMESTAMPTZ NULL,
+  ADD COLUMN last_used_ip TEXT        NULL;
+
+-- Recording the last use of a token is no modification of it
+DROP TRIGGER IF EXISTS update_api_tokens_modified ON api_tokens;
+CREATE TRIGGER update_api_tokens_modified
+  BEFORE UPDATE ON api_tokens
+  FOR EACH ROW
+  WHEN ((OLD.last_used_at, OLD.last_used_ip) IS NOT DISTINCT FROM (NEW.last_used_at, NEW.last_used_ip))
+  EXECUTE PROCEDURE update_modified_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_api_tokens_modified ON api_tokens;
+CREATE TRIGGER update_api_tokens_modified
+  BEFORE UPDATE ON api_tokens
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+ALTER TABLE api_tokens
+  DROP COLUMN IF EXISTS privileges,
+  DROP COLUMN IF EXISTS flow_ids,
+  DROP COLUMN IF EXISTS template_ids,
+  DROP COLUMN IF EXISTS allowed_ips,
+  DROP COLUMN IF EXISTS last_used_at,
+  DROP COLUMN IF EXISTS last_used_ip;
+-- +goose StatementEnd
@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO privileges (role_id, name) VALUES
+  (1, 'audit.view')
+  ON CONFLICT DO NOTHING;
+
+-- Every event stores the hash of the previous one, so removing or changing a
+-- row breaks the chain; the triggers below reject it in the first place.
+CREATE TABLE audit_events (
+  id          BIGINT      PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  user_id     BIGINT      NULL,
+  user_type   TEXT        NOT NULL DEFAULT '',
+  token_id    TEXT        NULL,
+  action      TEXT        NOT NULL,
+  method      TEXT        NOT NULL DEFAULT '',
+  target_type TEXT        NOT NULL DEFAULT '',
+  target_id   TEXT        NOT NULL DEFAULT '',
+  diff        JSON        NULL,
+  status      TEXT        NOT NULL,
+  error       TEXT        NOT NULL DEFAULT '',
+  ip          TEXT        NOT NULL DEFAULT '',
+  user_agent  TEXT        NOT NULL DEFAULT '',
+  prev_hash   TEXT        NOT NULL DEFAULT '',
+  hash        TEXT        NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT audit_events_status_valid CHECK (status IN ('success', 'failure'))
+);
+
+CREATE INDEX audit_events_created_at_idx ON audit_events(created_at);
+CREATE INDEX audit_events_user_id_idx ON audit_events(user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX audit_events_token_id_idx ON audit_events(token_id) WHERE token_id IS NOT NULL;
+CREATE INDEX audit_events_action_idx ON audit_events(action);
+CREATE INDEX audit_events_target_idx ON audit_events(target_type, target_id);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_events is append-only, % is not allowed', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER audit_events_no_update
+  BEFORE UPDATE OR DELETE ON audit_events
+  FOR EACH ROW EXECUTE PROCEDURE audit_events_append_only();
+
+CREATE OR REPLACE TRIGGER audit_events_no_truncate
+  BEFORE TRUNCATE ON audit_events
+  FOR EACH STATEMENT EXECUTE PROCEDURE audit_events_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_events;
+DROP FUNCTION audit_events_append_only();
+
+DELETE FROM privileges WHERE name = 'audit.view';
+-- +goose StatementEnd
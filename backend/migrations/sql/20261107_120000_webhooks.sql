@@ -0,0 +1,80 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO privileges (role_id, name) VALUES
+  (1, 'webhooks.admin'),
+  (1, 'webhooks.create'),
+  (1, 'webhooks.view'),
+  (1, 'webhooks.edit'),
+  (1, 'webhooks.delete'),
+  (2, 'webhooks.create'),
+  (2, 'webhooks.view'),
+  (2, 'webhooks.edit'),
+  (2, 'webhooks.delete')
+  ON CONFLICT DO NOTHING;
+
+CREATE TYPE WEBHOOK_DELIVERY_STATUS AS ENUM ('pending','success','failed');
+
+-- Empty events and flow_ids match every event of every flow of the owner.
+CREATE TABLE webhooks (
+  id         BIGINT      PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  user_id    BIGINT      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name       TEXT        NOT NULL,
+  url        TEXT        NOT NULL,
+  secret     TEXT        NOT NULL DEFAULT '',
+  events     TEXT[]      NOT NULL DEFAULT '{}',
+  flow_ids   BIGINT[]    NOT NULL DEFAULT '{}',
+  enabled    BOOLEAN     NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT webhooks_name_not_empty CHECK (length(trim(name)) > 0),
+  CONSTRAINT webhooks_url_http CHECK (url ~* '^https?://')
+);
+
+CREATE INDEX webhooks_user_id_idx ON webhooks(user_id);
+
+CREATE OR REPLACE TRIGGER update_webhooks_modified
+  BEFORE UPDATE ON webhooks
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+
+-- Every attempt to send an event is kept as a delivery; a pending delivery is
+-- sent again at next_attempt_at until it succeeds or runs out of attempts.
+CREATE TABLE webhook_deliveries (
+  id              BIGINT                  PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  webhook_id      BIGINT                  NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+  event           TEXT                    NOT NULL,
+  flow_id         BIGINT                  NULL,
+  payload         JSON                    NOT NULL,
+  status          WEBHOOK_DELIVERY_STATUS NOT NULL DEFAULT 'pending',
+  attempts        INTEGER                 NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ             NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  response_status INTEGER                 NULL,
+  response_body   TEXT                    NOT NULL DEFAULT '',
+  error           TEXT                    NOT NULL DEFAULT '',
+  delivered_at    TIMESTAMPTZ             NULL,
+  created_at      TIMESTAMPTZ             DEFAULT CURRENT_TIMESTAMP,
+  updated_at      TIMESTAMPTZ             DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries(webhook_id, id DESC);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+CREATE OR REPLACE TRIGGER update_webhook_deliveries_modified
+  BEFORE UPDATE ON webhook_deliveries
+  FOR EACH ROW EXECUTE PROCEDURE update_modified_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TYPE IF EXISTS WEBHOOK_DELIVERY_STATUS;
+
+DELETE FROM privileges WHERE name IN (
+  'webhooks.admin',
+  'webhooks.create',
+  'webhooks.view',
+  'webhooks.edit',
+  'webhooks.delete'
+);
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A flow is driven by the backend replica holding its lease; the holder renews
+-- the lease with heartbeats and another replica may take it once it expires.
+CREATE TABLE flow_leases (
+  flow_id      BIGINT      PRIMARY KEY REFERENCES flows(id) ON DELETE CASCADE,
+  owner        TEXT        NOT NULL,
+  acquired_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at   TIMESTAMPTZ NOT NULL,
+
+  CONSTRAINT flow_leases_owner_not_empty CHECK (length(owner) > 0)
+);
+
+CREATE INDEX flow_leases_owner_idx ON flow_leases(owner);
+CREATE INDEX flow_leases_expires_at_idx ON flow_leases(expires_at);
+
+-- Subscription events larger than a NOTIFY payload are passed between the
+-- replicas through this table; rows are only needed for a few seconds.
+CREATE UNLOGGED TABLE subscription_payloads (
+  id         BIGINT      PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  payload    TEXT        NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX subscription_payloads_created_at_idx ON subscription_payloads(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS subscription_payloads;
+DROP TABLE IF EXISTS flow_leases;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO privileges (role_id, name) VALUES
+  (1, 'instances.view')
+  ON CONFLICT DO NOTHING;
+
+-- Every backend replica registers itself and keeps the row alive with the
+-- same heartbeats that renew its flow leases.
+CREATE TABLE backend_instances (
+  name         TEXT        PRIMARY KEY,
+  started_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at   TIMESTAMPTZ NOT NULL,
+
+  CONSTRAINT backend_instances_name_not_empty CHECK (length(name) > 0)
+);
+
+CREATE INDEX backend_instances_expires_at_idx ON backend_instances(expires_at);
+
+-- The single row names the replica which hands the flows of dead replicas over
+-- to the live ones; it's taken like a flow lease once it expires.
+CREATE TABLE backend_leader (
+  id          SMALLINT    PRIMARY KEY DEFAULT 1,
+  owner       TEXT        NOT NULL,
+  acquired_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at  TIMESTAMPTZ NOT NULL,
+
+  CONSTRAINT backend_leader_single_row CHECK (id = 1)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS backend_leader;
+DROP TABLE IF EXISTS backend_instances;
+
+DELETE FROM privileges WHERE name = 'instances.view';
+-- +goose StatementEnd
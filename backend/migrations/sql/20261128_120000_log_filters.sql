@@ -0,0 +1,69 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Full-text document of a row for the log filters and the global search. NULL
+-- parts are skipped and very long logs are cut, a tsvector can't exceed 1 MB.
+CREATE FUNCTION search_document(VARIADIC parts TEXT[]) RETURNS TSVECTOR
+  LANGUAGE sql IMMUTABLE PARALLEL SAFE
+  AS $$ SELECT to_tsvector('english', left(array_to_string(parts, ' '), 262144)) $$;
+
+CREATE INDEX termlogs_flow_id_id_idx ON termlogs(flow_id, id);
+CREATE INDEX termlogs_flow_id_created_at_idx ON termlogs(flow_id, created_at);
+CREATE INDEX termlogs_search_idx ON termlogs USING GIN (search_document(text));
+
+CREATE INDEX msglogs_flow_id_id_idx ON msglogs(flow_id, id);
+CREATE INDEX msglogs_flow_id_created_at_idx ON msglogs(flow_id, created_at);
+CREATE INDEX msglogs_search_idx ON msglogs USING GIN (search_document(message, thinking, result));
+
+CREATE INDEX agentlogs_flow_id_id_idx ON agentlogs(flow_id, id);
+CREATE INDEX agentlogs_flow_id_created_at_idx ON agentlogs(flow_id, created_at);
+CREATE INDEX agentlogs_search_idx ON agentlogs USING GIN (search_document(task, result));
+
+CREATE INDEX searchlogs_flow_id_id_idx ON searchlogs(flow_id, id);
+CREATE INDEX searchlogs_flow_id_created_at_idx ON searchlogs(flow_id, created_at);
+CREATE INDEX searchlogs_search_idx ON searchlogs USING GIN (search_document(query, result));
+
+CREATE INDEX vecstorelogs_flow_id_id_idx ON vecstorelogs(flow_id, id);
+CREATE INDEX vecstorelogs_flow_id_created_at_idx ON vecstorelogs(flow_id, created_at);
+CREATE INDEX vecstorelogs_search_idx ON vecstorelogs USING GIN (search_document(query, result));
+
+CREATE INDEX toolcalls_flow_id_id_idx ON toolcalls(flow_id, id);
+CREATE INDEX toolcalls_flow_id_created_at_idx ON toolcalls(flow_id, created_at);
+CREATE INDEX toolcalls_search_idx ON toolcalls USING GIN (search_document(name, args::text, result));
+
+CREATE INDEX assistantlogs_flow_id_assistant_id_id_idx ON assistantlogs(flow_id, assistant_id, id);
+CREATE INDEX assistantlogs_flow_id_created_at_idx ON assistantlogs(flow_id, created_at);
+CREATE INDEX assistantlogs_search_idx ON assistantlogs USING GIN (search_document(message, thinking, result));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS termlogs_flow_id_id_idx;
+DROP INDEX IF EXISTS termlogs_flow_id_created_at_idx;
+DROP INDEX IF EXISTS termlogs_search_idx;
+
+DROP INDEX IF EXISTS msglogs_flow_id_id_idx;
+DROP INDEX IF EXISTS msglogs_flow_id_created_at_idx;
+DROP INDEX IF EXISTS msglogs_search_idx;
+
+DROP INDEX IF EXISTS agentlogs_flow_id_id_idx;
+DROP INDEX IF EXISTS agentlogs_flow_id_created_at_idx;
+DROP INDEX IF EXISTS agentlogs_search_idx;
+
+DROP INDEX IF EXISTS searchlogs_flow_id_id_idx;
+DROP INDEX IF EXISTS searchlogs_flow_id_created_at_idx;
+DROP INDEX IF EXISTS searchlogs_search_idx;
+
+DROP INDEX IF EXISTS vecstorelogs_flow_id_id_idx;
+DROP INDEX IF EXISTS vecstorelogs_flow_id_created_at_idx;
+DROP INDEX IF EXISTS vecstorelogs_search_idx;
+
+DROP INDEX IF EXISTS toolcalls_flow_id_id_idx;
+DROP INDEX IF EXISTS toolcalls_flow_id_created_at_idx;
+DROP INDEX IF EXISTS toolcalls_search_idx;
+
+DROP INDEX IF EXISTS assistantlogs_flow_id_assistant_id_id_idx;
+DROP INDEX IF EXISTS assistantlogs_flow_id_created_at_idx;
+DROP INDEX IF EXISTS assistantlogs_search_idx;
+
+DROP FUNCTION IF EXISTS search_document(TEXT[]);
+-- +goose StatementEnd
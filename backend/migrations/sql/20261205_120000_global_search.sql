@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Full-text indexes of the global search, the log tables are indexed by the log filters
+CREATE INDEX flows_search_idx ON flows USING GIN (search_document(title)) WHERE deleted_at IS NULL;
+CREATE INDEX tasks_search_idx ON tasks USING GIN (search_document(title, input, result));
+CREATE INDEX subtasks_search_idx ON subtasks USING GIN (search_document(title, description, result));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS flows_search_idx;
+DROP INDEX IF EXISTS tasks_search_idx;
+DROP INDEX IF EXISTS subtasks_search_idx;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Capabilities belong to one saved provider, not to every provider of its type:
+-- two endpoints of the same type may serve the same model name very differently.
+-- Rows stored so far can't be attributed to a provider and are dropped.
+DELETE FROM provider_capabilities;
+
+ALTER TABLE provider_capabilities
+  ADD COLUMN provider_id BIGINT NOT NULL REFERENCES providers(id) ON DELETE CASCADE;
+
+ALTER TABLE provider_capabilities DROP CONSTRAINT provider_capabilities_user_type_model_unique;
+ALTER TABLE provider_capabilities
+  ADD CONSTRAINT provider_capabilities_provider_model_unique UNIQUE (provider_id, model);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM provider_capabilities;
+
+ALTER TABLE provider_capabilities DROP CONSTRAINT provider_capabilities_provider_model_unique;
+ALTER TABLE provider_capabilities DROP COLUMN provider_id;
+ALTER TABLE provider_capabilities
+  ADD CONSTRAINT provider_capabilities_user_type_model_unique UNIQUE (user_id, type, model);
+-- +goose StatementEnd
@@ -0,0 +1,59 @@
+package attackgraph
+
+import (
+	"fmt"
+	"strings"
+)
+
+var dotNodeStyles = map[NodeKind]string{
+	NodeKindStart:         `shape=doublecircle, style=filled, fillcolor="#d9d9d9"`,
+	NodeKindAsset:         `shape=box, style="rounded,filled", fillcolor="#cfe2f3"`,
+	NodeKindVulnerability: `shape=octagon, style=filled, fillcolor="#f4cccc"`,
+	NodeKindCredential:    `shape=note, style=filled, fillcolor="#fff2cc"`,
+	NodeKindFoothold:      `shape=house, style=filled, fillcolor="#d9ead3"`,
+}
+
+var dotEdgeStyles = map[EdgeKind]string{
+	EdgeKindAction:   `style=solid`,
+	EdgeKindRelation: `style=dashed, color="#666666", fontcolor="#666666"`,
+}
+
+// DOT renders the graph in the Graphviz language, left to right from the
+// start node, e.g. for `dot -Tsvg`.
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(fmt.Sprintf("flow_%d_attack_graph", g.FlowID)))
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+	sb.WriteString("  edge [fontname=\"Helvetica\", fontsize=9];\n")
+
+	for _, n := range g.Nodes {
+		label := n.Name
+		if n.Label != "" {
+			label = n.Label + "\n" + n.Name
+		}
+		if n.Summary != "" {
+			label += "\n" + n.Summary
+		}
+		fmt.Fprintf(&sb, "  %s [label=%s, %s];\n", dotQuote(n.ID), dotQuote(label), dotNodeStyles[n.Kind])
+	}
+
+	for _, e := range g.Edges {
+		label := e.Name
+		if e.Kind == EdgeKindAction && e.Description != "" {
+			label += ": " + e.Description
+		}
+		fmt.Fprintf(&sb, "  %s -> %s [label=%s, %s];\n",
+			dotQuote(e.Source), dotQuote(e.Target), dotQuote(label), dotEdgeStyles[e.Kind])
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotQuote makes s a quoted DOT identifier; newlines become line breaks
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
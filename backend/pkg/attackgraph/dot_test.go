@@ -0,0 +1,30 @@
+package attackgraph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphDOT(t *testing.T) {
+	t.Parallel()
+
+	g := Build(1, StepsFromToolcalls(testToolcalls()))
+	dot := g.DOT()
+
+	assert.True(t, strings.HasPrefix(dot, "digraph \"flow_1_attack_graph\" {\n"))
+	assert.True(t, strings.HasSuffix(dot, "}\n"))
+	assert.Contains(t, dot, `"start" [label="flow 1", shape=doublecircle`)
+	assert.Contains(t, dot, `"host:10.0.0.5" [label="Host\n10.0.0.5\nweb.corp.local", shape=box`)
+	assert.Contains(t, dot, `"start" -> "host:10.0.0.5" [label="terminal: nmap -sV 10.0.0.5", style=solid];`)
+	assert.Contains(t, dot, `"host:10.0.0.5" -> "service:10.0.0.5:22/tcp" [label="RUNS_SERVICE", style=dashed`)
+	assert.Equal(t, len(g.Nodes)+len(g.Edges)+5, strings.Count(dot, "\n"))
+}
+
+func TestDOTQuote(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `"plain"`, dotQuote("plain"))
+	assert.Equal(t, `"say \"hi\"\nC:\\tmp"`, dotQuote("say \"hi\"\r\nC:\\tmp"))
+}
@@ -0,0 +1,254 @@
+// Package attackgraph turns what a flow recorded into an attack path: the
+// assets, vulnerabilities, credentials and footholds the agents found, and
+// the actions which found them, in the order they happened.
+package attackgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"pentagi/pkg/graphiti"
+)
+
+type NodeKind string
+
+const (
+	NodeKindStart         NodeKind = "start"
+	NodeKindAsset         NodeKind = "asset"
+	NodeKindVulnerability NodeKind = "vulnerability"
+	NodeKindCredential    NodeKind = "credential"
+	NodeKindFoothold      NodeKind = "foothold"
+)
+
+type EdgeKind string
+
+const (
+	// EdgeKindAction leads from where an action was aimed to what it found
+	EdgeKindAction EdgeKind = "action"
+	// EdgeKindRelation is a fact linking two findings, e.g. a host runs a service
+	EdgeKindRelation EdgeKind = "relation"
+)
+
+const maxDescriptionLength = 200
+
+// Graph is the attack path of a flow. The start node stands for the
+// engagement itself: the first findings hang off it.
+type Graph struct {
+	FlowID int64  `json:"flow_id"`
+	Nodes  []Node `json:"nodes"`
+	Edges  []Edge `json:"edges"`
+}
+
+type Node struct {
+	ID   string   `json:"id"`
+	Kind NodeKind `json:"kind"`
+	// Label is the knowledge graph entity label, e.g. Host or Service
+	Label       string    `json:"label"`
+	Name        string    `json:"name"`
+	Summary     string    `json:"summary,omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+}
+
+// Edge is either an action, named after the tool or agent which performed
+// it, or a relation, named after the knowledge graph relation. Both keep
+// the step which produced them.
+type Edge struct {
+	ID          string    `json:"id"`
+	Kind        EdgeKind  `json:"kind"`
+	Source      string    `json:"source"`
+	Target      string    `json:"target"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	ToolcallID  *int64    `json:"toolcall_id,omitempty"`
+	TaskID      *int64    `json:"task_id,omitempty"`
+	SubtaskID   *int64    `json:"subtask_id,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Step is one recorded action of a flow which may have found something
+type Step struct {
+	ToolcallID *int64
+	TaskID     *int64
+	SubtaskID  *int64
+	// Actor is the tool or the agent which acted
+	Actor       string
+	Description string
+	// Input names what the action was aimed at, Output is what it returned
+	Input     string
+	Output    string
+	Timestamp time.Time
+}
+
+// Build replays the steps in chronological order. Only the first mention of
+// an entity produces a node: an action edge links it to the host the step
+// was aimed at, or to the start node, unless a relation found by the same
+// step already explains where it comes from.
+func Build(flowID int64, steps []Step) *Graph {
+	b := &builder{
+		graph: &Graph{FlowID: flowID, Nodes: []Node{}, Edges: []Edge{}},
+		nodes: make(map[string]int),
+		pairs: make(map[[2]string]struct{}),
+	}
+
+	steps = slices.Clone(steps)
+	slices.SortStableFunc(steps, func(a, b Step) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	start := b.addNode(Node{
+		ID:   startNodeID,
+		Kind: NodeKindStart,
+		Name: fmt.Sprintf("flow %d", flowID),
+	})
+	if len(steps) > 0 {
+		b.graph.Nodes[0].FirstSeenAt = steps[0].Timestamp
+	}
+
+	for _, step := range steps {
+		b.addStep(step, start)
+	}
+
+	return b.graph
+}
+
+// JSON returns the graph as an indented document for export
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+const startNodeID = "start"
+
+type builder struct {
+	graph *Graph
+	nodes map[string]int
+	pairs map[[2]string]struct{}
+}
+
+func (b *builder) addStep(step Step, start string) {
+	// findings hang off the host the step was aimed at; a host reached for
+	// the first time hangs off the start node itself
+	origin := start
+	targets, _ := graphiti.ExtractEntities(step.Input)
+	for _, e := range targets {
+		if e.Label == graphiti.LabelHost {
+			origin = nodeID(e.Label, e.Name)
+			break
+		}
+	}
+
+	entities, relations := graphiti.ExtractEntities(step.Input + "\n" + step.Output)
+
+	// a relation explains its target when the source was mentioned first,
+	// like the services listed under a host; an account found on a host
+	// does not explain the host
+	position := make(map[string]int, len(entities))
+	for i, e := range entities {
+		position[nodeID(e.Label, e.Name)] = i
+	}
+	explained := make(map[string]struct{})
+	for _, r := range relations {
+		source, target := nodeID(r.SourceLabel, r.SourceName), nodeID(r.TargetLabel, r.TargetName)
+		if position[source] < position[target] {
+			explained[target] = struct{}{}
+		}
+	}
+
+	var found []string
+	for _, e := range entities {
+		id := nodeID(e.Label, e.Name)
+		if i, ok := b.nodes[id]; ok {
+			if b.graph.Nodes[i].Summary == "" {
+				b.graph.Nodes[i].Summary = e.Summary
+			}
+			continue
+		}
+		b.addNode(Node{
+			ID:          id,
+			Kind:        nodeKind(e.Label),
+			Label:       e.Label,
+			Name:        e.Name,
+			Summary:     e.Summary,
+			FirstSeenAt: step.Timestamp,
+		})
+		found = append(found, id)
+	}
+
+	for _, id := range found {
+		if _, ok := explained[id]; ok {
+			continue
+		}
+		source := origin
+		if id == origin {
+			source = start
+		}
+		b.addEdge(step, EdgeKindAction, source, id, step.Actor, step.Description)
+	}
+	for _, r := range relations {
+		b.addEdge(step, EdgeKindRelation,
+			nodeID(r.SourceLabel, r.SourceName), nodeID(r.TargetLabel, r.TargetName), r.Name, r.Fact)
+	}
+}
+
+func (b *builder) addNode(node Node) string {
+	b.nodes[node.ID] = len(b.graph.Nodes)
+	b.graph.Nodes = append(b.graph.Nodes, node)
+	return node.ID
+}
+
+// addEdge keeps at most one edge between two nodes: the attack path only
+// needs to show how a node was reached, the first time it was.
+func (b *builder) addEdge(step Step, kind EdgeKind, source, target, name, description string) {
+	if source == target {
+		return
+	}
+	pair := [2]string{min(source, target), max(source, target)}
+	if _, ok := b.pairs[pair]; ok {
+		return
+	}
+	b.pairs[pair] = struct{}{}
+
+	b.graph.Edges = append(b.graph.Edges, Edge{
+		ID:          fmt.Sprintf("e%d", len(b.graph.Edges)+1),
+		Kind:        kind,
+		Source:      source,
+		Target:      target,
+		Name:        name,
+		Description: truncate(description),
+		ToolcallID:  step.ToolcallID,
+		TaskID:      step.TaskID,
+		SubtaskID:   step.SubtaskID,
+		Timestamp:   step.Timestamp,
+	})
+}
+
+func nodeID(label, name string) string {
+	return strings.ToLower(label) + ":" + name
+}
+
+func nodeKind(label string) NodeKind {
+	switch label {
+	case graphiti.LabelVulnerability:
+		return NodeKindVulnerability
+	case graphiti.LabelCredential:
+		return NodeKindCredential
+	case graphiti.LabelAccount:
+		return NodeKindFoothold
+	default:
+		return NodeKindAsset
+	}
+}
+
+func truncate(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= maxDescriptionLength {
+		return s
+	}
+	cut := maxDescriptionLength
+	for cut > 0 && s[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return s[:cut] + "..."
+}
@@ -0,0 +1,192 @@
+package attackgraph
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/graphiti"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStart = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func toolcall(id int64, name string, status database.ToolcallStatus, args any, result string) database.Toolcall {
+	raw, _ := json.Marshal(args)
+	return database.Toolcall{
+		ID:        id,
+		Name:      name,
+		Status:    status,
+		Args:      raw,
+		Result:    result,
+		FlowID:    1,
+		TaskID:    sql.NullInt64{Int64: 10, Valid: true},
+		SubtaskID: sql.NullInt64{Int64: 100 + id, Valid: true},
+		CreatedAt: sql.NullTime{Time: testStart.Add(time.Duration(id) * time.Minute), Valid: true},
+	}
+}
+
+func testToolcalls() []database.Toolcall {
+	return []database.Toolcall{
+		// recorded out of order on purpose, Build sorts by time
+		toolcall(3, "terminal", database.ToolcallStatusFinished,
+			map[string]string{"input": "ssh admin@10.0.0.5 id"},
+			"uid=0(root) gid=0(root) groups=0(root)"),
+		toolcall(1, "terminal", database.ToolcallStatusFinished,
+			map[string]string{"input": "nmap -sV 10.0.0.5", "message": "scan the target"},
+			"Nmap scan report for web.corp.local (10.0.0.5)\n"+
+				"22/tcp open  ssh     OpenSSH 8.2p1\n"+
+				"80/tcp open  http    Apache httpd 2.4.49\n"+
+				"| vulners: CVE-2021-41773 9.8"),
+		toolcall(2, "terminal", database.ToolcallStatusFinished,
+			map[string]string{"input": "hydra -l admin -P pass.txt ssh://10.0.0.5"},
+			"[22][ssh] host: 10.0.0.5   login: admin   password: S3cret!"),
+		// retrieval and unfinished calls never add findings
+		toolcall(4, "search_in_memory", database.ToolcallStatusFinished,
+			map[string]string{"question": "hosts"}, "192.168.7.7 from another flow"),
+		toolcall(5, "terminal", database.ToolcallStatusFailed,
+			map[string]string{"input": "nmap 172.16.0.9"}, "Nmap scan report for 172.16.0.9"),
+	}
+}
+
+func nodeByID(t *testing.T, g *Graph, id string) Node {
+	t.Helper()
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	t.Fatalf("node %q not found", id)
+	return Node{}
+}
+
+func edgeBetween(t *testing.T, g *Graph, source, target string) Edge {
+	t.Helper()
+	for _, e := range g.Edges {
+		if e.Source == source && e.Target == target {
+			return e
+		}
+	}
+	t.Fatalf("edge %s -> %s not found", source, target)
+	return Edge{}
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	g := Build(1, StepsFromToolcalls(testToolcalls()))
+
+	assert.Equal(t, int64(1), g.FlowID)
+	require.Len(t, g.Nodes, 8)
+	assert.Equal(t, NodeKindStart, g.Nodes[0].Kind)
+	assert.Equal(t, testStart.Add(time.Minute), g.Nodes[0].FirstSeenAt)
+
+	host := nodeByID(t, g, "host:10.0.0.5")
+	assert.Equal(t, NodeKindAsset, host.Kind)
+	assert.Equal(t, "web.corp.local", host.Summary)
+	assert.Equal(t, NodeKindAsset, nodeByID(t, g, "service:10.0.0.5:80/tcp").Kind)
+	assert.Equal(t, NodeKindVulnerability, nodeByID(t, g, "vulnerability:CVE-2021-41773").Kind)
+	assert.Equal(t, NodeKindCredential, nodeByID(t, g, "credential:admin@10.0.0.5").Kind)
+	assert.Equal(t, NodeKindFoothold, nodeByID(t, g, "account:admin@10.0.0.5").Kind)
+	root := nodeByID(t, g, "account:root@10.0.0.5")
+	assert.Equal(t, NodeKindFoothold, root.Kind)
+	assert.Equal(t, testStart.Add(3*time.Minute), root.FirstSeenAt)
+
+	scan := edgeBetween(t, g, startNodeID, "host:10.0.0.5")
+	assert.Equal(t, EdgeKindAction, scan.Kind)
+	assert.Equal(t, "terminal", scan.Name)
+	assert.Equal(t, "nmap -sV 10.0.0.5", scan.Description)
+	require.NotNil(t, scan.ToolcallID)
+	assert.Equal(t, int64(1), *scan.ToolcallID)
+	require.NotNil(t, scan.SubtaskID)
+	assert.Equal(t, int64(101), *scan.SubtaskID)
+
+	assert.Equal(t, graphiti.EdgeRunsService, edgeBetween(t, g, "host:10.0.0.5", "service:10.0.0.5:80/tcp").Name)
+	assert.Equal(t, EdgeKindRelation, edgeBetween(t, g, "service:10.0.0.5:80/tcp", "vulnerability:CVE-2021-41773").Kind)
+
+	// later steps aimed at a known host start from it
+	brute := edgeBetween(t, g, "host:10.0.0.5", "credential:admin@10.0.0.5")
+	assert.Equal(t, EdgeKindAction, brute.Kind)
+	assert.Equal(t, int64(2), *brute.ToolcallID)
+	assert.Equal(t, graphiti.EdgeYieldedAccess,
+		edgeBetween(t, g, "credential:admin@10.0.0.5", "account:admin@10.0.0.5").Name)
+	assert.Equal(t, EdgeKindAction, edgeBetween(t, g, "host:10.0.0.5", "account:root@10.0.0.5").Kind)
+
+	for _, n := range g.Nodes {
+		assert.NotContains(t, n.Name+n.Summary, "S3cret", "secrets must never be shown")
+		assert.NotContains(t, n.Name, "192.168.7.7", "retrieved knowledge is not a finding")
+		assert.NotContains(t, n.Name, "172.16.0.9", "failed calls are not findings")
+	}
+
+	seen := make(map[[2]string]bool)
+	for _, e := range g.Edges {
+		pair := [2]string{min(e.Source, e.Target), max(e.Source, e.Target)}
+		assert.False(t, seen[pair], "one edge per pair of nodes")
+		seen[pair] = true
+	}
+}
+
+func TestBuild_Empty(t *testing.T) {
+	t.Parallel()
+
+	g := Build(7, nil)
+	require.Len(t, g.Nodes, 1)
+	assert.Empty(t, g.Edges)
+
+	data, err := g.JSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"edges": []`)
+}
+
+func TestStepsFromToolcalls(t *testing.T) {
+	t.Parallel()
+
+	steps := StepsFromToolcalls([]database.Toolcall{
+		toolcall(1, "file", database.ToolcallStatusFinished,
+			map[string]string{"action": "read_file", "path": "/etc/hosts"}, "10.0.0.8 db"),
+		toolcall(2, "hack_result", database.ToolcallStatusFinished,
+			map[string]string{"result": "got a shell on 10.0.0.8", "message": "foothold on db"}, "stored"),
+	})
+
+	require.Len(t, steps, 2)
+	assert.Equal(t, "/etc/hosts", steps[0].Input)
+	assert.Equal(t, "10.0.0.8 db", steps[0].Output)
+	assert.Equal(t, "got a shell on 10.0.0.8", steps[1].Input)
+	assert.Empty(t, steps[1].Output)
+	assert.Equal(t, "foothold on db", steps[1].Description)
+}
+
+func TestStepsFromEpisodes(t *testing.T) {
+	t.Parallel()
+
+	steps := StepsFromEpisodes([]graphiti.Episode{
+		{
+			Name:              "agent_response",
+			Content:           "Agent: pentester\nResponse: CVE-2021-41773 confirmed on 10.0.0.5",
+			SourceDescription: "PentAGI pentester agent execution in flow 1, task 10, subtask 102",
+			ValidAt:           testStart,
+		},
+		{
+			Name:    "tool_execution_terminal",
+			Content: "Tool: terminal\nStatus: success\nResult: 10.0.0.6",
+		},
+	})
+
+	require.Len(t, steps, 1)
+	assert.Equal(t, "pentester", steps[0].Actor)
+	require.NotNil(t, steps[0].TaskID)
+	assert.Equal(t, int64(10), *steps[0].TaskID)
+	require.NotNil(t, steps[0].SubtaskID)
+	assert.Equal(t, int64(102), *steps[0].SubtaskID)
+	assert.Nil(t, steps[0].ToolcallID)
+
+	g := Build(1, steps)
+	assert.Equal(t, EdgeKindRelation,
+		edgeBetween(t, g, "host:10.0.0.5", "vulnerability:CVE-2021-41773").Kind,
+		"the report is aimed at the host it names")
+	assert.Equal(t, "pentester", edgeBetween(t, g, startNodeID, "host:10.0.0.5").Name)
+}
@@ -0,0 +1,151 @@
+package attackgraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/graphiti"
+	"pentagi/pkg/tools"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxEpisodes bounds the agent responses read back from the knowledge graph
+const maxEpisodes = 1000
+
+// agentResponseEpisode is the episode name the performer stores agent
+// responses under; tool executions are read from the flow toolcalls instead.
+const agentResponseEpisode = "agent_response"
+
+// actionTools are the tools whose records describe the engagement itself.
+// Search and memory tools are left out: they echo knowledge of other flows.
+var actionTools = map[string]struct{}{
+	tools.TerminalToolName:   {},
+	tools.FileToolName:       {},
+	tools.HackResultToolName: {},
+}
+
+var episodeSourceRegex = regexp.MustCompile(`^PentAGI (\S+) agent execution in flow \d+(?:, task (\d+))?(?:, subtask (\d+))?`)
+
+// Loader reads the records of a flow and builds its attack graph
+type Loader struct {
+	db       database.Querier
+	cfg      *config.Config
+	graphiti *graphiti.Client
+}
+
+// NewLoader creates a Loader; graphitiClient may be nil or disabled, the
+// graph is then built from the flow toolcalls alone.
+func NewLoader(db database.Querier, cfg *config.Config, graphitiClient *graphiti.Client) *Loader {
+	return &Loader{
+		db:       db,
+		cfg:      cfg,
+		graphiti: graphitiClient,
+	}
+}
+
+// Load builds the attack graph of a flow from its finished toolcalls and,
+// when the knowledge graph is enabled, the agent responses it recorded.
+// An unavailable knowledge graph only leaves its steps out.
+func (l *Loader) Load(ctx context.Context, flowID int64) (*Graph, error) {
+	toolcalls, err := l.db.GetFlowToolcalls(ctx, flowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow toolcalls: %w", err)
+	}
+
+	steps := StepsFromToolcalls(toolcalls)
+
+	if l.graphiti.IsEnabled() {
+		episodes, err := l.graphiti.GetEpisodes(ctx, l.cfg.GroupID(flowID), maxEpisodes)
+		if err != nil {
+			logrus.WithError(err).WithField("flow_id", flowID).
+				Warn("failed to get knowledge graph episodes for the attack graph")
+		} else {
+			steps = append(steps, StepsFromEpisodes(episodes)...)
+		}
+	}
+
+	return Build(flowID, steps), nil
+}
+
+// StepsFromToolcalls keeps the finished calls of action tools
+func StepsFromToolcalls(toolcalls []database.Toolcall) []Step {
+	steps := make([]Step, 0, len(toolcalls))
+	for _, tc := range toolcalls {
+		if _, ok := actionTools[tc.Name]; !ok || tc.Status != database.ToolcallStatusFinished {
+			continue
+		}
+
+		step := Step{
+			ToolcallID: &tc.ID,
+			TaskID:     database.NullInt64ToInt64(tc.TaskID),
+			SubtaskID:  database.NullInt64ToInt64(tc.SubtaskID),
+			Actor:      tc.Name,
+			Input:      string(tc.Args),
+			Output:     tc.Result,
+			Timestamp:  tc.CreatedAt.Time,
+		}
+
+		var args struct {
+			Input   string `json:"input"`
+			Path    string `json:"path"`
+			Result  string `json:"result"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(tc.Args, &args); err == nil {
+			switch tc.Name {
+			case tools.TerminalToolName:
+				step.Input, step.Description = args.Input, args.Input
+			case tools.FileToolName:
+				step.Input, step.Description = args.Path, args.Path
+			case tools.HackResultToolName:
+				// the report names the targets it is about
+				step.Input, step.Output, step.Description = args.Result, "", args.Message
+			}
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps
+}
+
+// StepsFromEpisodes keeps the agent responses; they are attributed to the
+// agent, task and subtask named in the episode source description.
+func StepsFromEpisodes(episodes []graphiti.Episode) []Step {
+	steps := make([]Step, 0, len(episodes))
+	for _, e := range episodes {
+		if e.Name != agentResponseEpisode {
+			continue
+		}
+
+		step := Step{
+			Actor:       "agent",
+			Description: e.SourceDescription,
+			Input:       e.Content,
+			Timestamp:   e.ValidAt,
+		}
+		if m := episodeSourceRegex.FindStringSubmatch(e.SourceDescription); m != nil {
+			step.Actor = m[1]
+			step.TaskID = parseID(m[2])
+			step.SubtaskID = parseID(m[3])
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps
+}
+
+func parseID(s string) *int64 {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,75 @@
+package cast
+
+import (
+	"math"
+
+	"github.com/vxcontrol/langchaingo/llms"
+)
+
+// Token estimation constants. Providers do not expose their tokenizers, so
+// counts are derived from the byte size of the text with a per-tokenizer
+// density plus fixed costs for the parts that are not plain text.
+const (
+	// DefaultBytesPerToken is used when the caller has no tokenizer density
+	DefaultBytesPerToken = 3.0
+
+	// messageOverheadTokens covers role markers and message separators
+	messageOverheadTokens = 4
+
+	// toolCallOverheadTokens covers the call envelope (id, type, name framing)
+	toolCallOverheadTokens = 8
+
+	// imageTokens approximates a single image attachment; providers bill
+	// images by resolution rather than by encoded size
+	imageTokens = 1600
+)
+
+// EstimateTokens returns an approximate token count of the whole chain as a
+// model with the given tokenizer density would see it. Non-positive
+// bytesPerToken falls back to DefaultBytesPerToken.
+func EstimateTokens(chain []llms.MessageContent, bytesPerToken float64) int {
+	tokens := 0
+	for idx := range chain {
+		tokens += EstimateMessageTokens(&chain[idx], bytesPerToken)
+	}
+	return tokens
+}
+
+// EstimateMessageTokens returns an approximate token count of a single message,
+// including reasoning content that is replayed to the model.
+func EstimateMessageTokens(msg *llms.MessageContent, bytesPerToken float64) int {
+	if msg == nil {
+		return 0
+	}
+	if bytesPerToken <= 0 {
+		bytesPerToken = DefaultBytesPerToken
+	}
+
+	textBytes, tokens := 0, messageOverheadTokens
+	for _, part := range msg.Parts {
+		switch p := part.(type) {
+		case llms.TextContent:
+			textBytes += len(p.Text)
+			if p.Reasoning != nil {
+				textBytes += len(p.Reasoning.Content)
+			}
+		case llms.ImageURLContent:
+			tokens += imageTokens
+		case llms.BinaryContent:
+			tokens += imageTokens
+		case llms.ToolCall:
+			tokens += toolCallOverheadTokens
+			if p.FunctionCall != nil {
+				textBytes += len(p.FunctionCall.Name) + len(p.FunctionCall.Arguments)
+			}
+			if p.Reasoning != nil {
+				textBytes += len(p.Reasoning.Content)
+			}
+		case llms.ToolCallResponse:
+			tokens += toolCallOverheadTokens
+			textBytes += len(p.Name) + len(p.Content)
+		}
+	}
+
+	return tokens + int(math.Ceil(float64(textBytes)/bytesPerToken))
+}
@@ -0,0 +1,67 @@
+package cast
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/reasoning"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	chain := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, strings.Repeat("a", 300)),
+		llms.TextParts(llms.ChatMessageTypeHuman, strings.Repeat("b", 30)),
+		{
+			Role: llms.ChatMessageTypeAI,
+			Parts: []llms.ContentPart{
+				llms.TextContent{
+					Text:      strings.Repeat("c", 60),
+					Reasoning: &reasoning.ContentReasoning{Content: strings.Repeat("d", 30)},
+				},
+				llms.ToolCall{
+					ID:           "call_1",
+					Type:         "function",
+					FunctionCall: &llms.FunctionCall{Name: "terminal", Arguments: `{"cmd":"ls"}`},
+				},
+			},
+		},
+		{
+			Role: llms.ChatMessageTypeTool,
+			Parts: []llms.ContentPart{
+				llms.ToolCallResponse{ToolCallID: "call_1", Name: "terminal", Content: strings.Repeat("e", 90)},
+			},
+		},
+	}
+
+	assert.Equal(t, 0, EstimateTokens(nil, 3))
+	assert.Equal(t, 4+100, EstimateMessageTokens(&chain[0], 3))
+	assert.Equal(t, 4+10, EstimateMessageTokens(&chain[1], 3))
+	// text, reasoning and tool call arguments are all replayed to the model
+	assert.Equal(t, 4+8+37, EstimateMessageTokens(&chain[2], 3)) // ceil((60+30+8+12)/3)
+	assert.Equal(t, 4+8+33, EstimateMessageTokens(&chain[3], 3)) // ceil((8+90)/3)
+
+	total := 0
+	for idx := range chain {
+		total += EstimateMessageTokens(&chain[idx], 3)
+	}
+	assert.Equal(t, total, EstimateTokens(chain, 3))
+
+	// a denser tokenizer yields fewer tokens, a missing one falls back to the default
+	assert.Less(t, EstimateTokens(chain, 4), EstimateTokens(chain, 3))
+	assert.Equal(t, EstimateTokens(chain, DefaultBytesPerToken), EstimateTokens(chain, 0))
+}
+
+func TestEstimateTokens_Images(t *testing.T) {
+	msg := llms.MessageContent{
+		Role: llms.ChatMessageTypeHuman,
+		Parts: []llms.ContentPart{
+			llms.BinaryContent{MIMEType: "image/png", Data: make([]byte, 512*1024)},
+			llms.ImageURLContent{URL: "data:image/png;base64," + strings.Repeat("A", 1024)},
+		},
+	}
+
+	// images are billed by resolution, not by encoded size
+	assert.Equal(t, 4+2*imageTokens, EstimateMessageTokens(&msg, 3))
+}
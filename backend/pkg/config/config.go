@@ -72,6 +72,48 @@ type Config struct {
 	// === Authentication & Session Security ===
 	CookieSigningSalt string `env:"COOKIE_SIGNING_SALT"`
 
+	// === Credential Vault ===
+	// CredentialVaultKey is the secret of the master key sealing the credentials
+	// stored by agents; when empty the key is derived from COOKIE_SIGNING_SALT
+	// (and TENANT_ID). Rotating it needs the stored credentials re-encrypted.
+	CredentialVaultKey string `env:"CREDENTIAL_VAULT_KEY"`
+
+	// === LLM Traffic Anonymization ===
+	// LLMAnonymizationEnabled replaces sensitive values in everything sent to
+	// the LLM providers of a flow with per-flow placeholders
+	LLMAnonymizationEnabled bool `env:"LLM_ANONYMIZATION_ENABLED" envDefault:"false"`
+
+	// === Audit Log ===
+	// AuditEnabled records user and admin actions of the REST and GraphQL APIs
+	// into the hash-chained audit_events table
+	AuditEnabled bool `env:"AUDIT_ENABLED" envDefault:"true"`
+	// AuditSyslogURL forwards every recorded event to a syslog collector, e.g. udp://siem:514
+	AuditSyslogURL    string `env:"AUDIT_SYSLOG_URL"`
+	AuditMaxDiffBytes int    `env:"AUDIT_MAX_DIFF_BYTES" envDefault:"65536"`
+
+	// === Webhooks ===
+	// WebhookMaxAttempts is how many times a flow event is sent before its delivery fails,
+	// the pause between the attempts doubles from 10 seconds up to an hour
+	WebhookMaxAttempts int `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	// WebhookTimeout is the timeout of a single delivery attempt in seconds
+	WebhookTimeout int `env:"WEBHOOK_TIMEOUT" envDefault:"10"`
+	// WebhookAllowedHosts lists the internal hosts, IPs and CIDRs webhooks may be
+	// sent to; loopback, private and link-local targets are refused otherwise
+	WebhookAllowedHosts []string `env:"WEBHOOK_ALLOWED_HOSTS"`
+	// WebhookAllowPrivate lets webhooks target any internal address, for on-prem receivers
+	WebhookAllowPrivate bool `env:"WEBHOOK_ALLOW_PRIVATE" envDefault:"false"`
+
+	// === Horizontal Scaling ===
+	// InstanceID names this backend replica as the owner of the flows it drives,
+	// the host name is used when it's empty
+	InstanceID string `env:"INSTANCE_ID"`
+	// SubscriptionsTransport passes the GraphQL subscription events between the
+	// replicas: memory for a single backend, postgres (LISTEN/NOTIFY) or redis
+	SubscriptionsTransport string `env:"SUBSCRIPTIONS_TRANSPORT" envDefault:"memory"`
+	SubscriptionsRedisURL  string `env:"SUBSCRIPTIONS_REDIS_URL"`
+	// FlowLeaseTTL is how many seconds a replica owns its flows without a heartbeat
+	FlowLeaseTTL int `env:"FLOW_LEASE_TTL" envDefault:"30"`
+
 	// === Web Scraper Service Endpoints ===
 	ScraperPublicURL  string `env:"SCRAPER_PUBLIC_URL"`
 	ScraperPrivateURL string `env:"SCRAPER_PRIVATE_URL"`
@@ -93,6 +135,24 @@ type Config struct {
 	EmbeddingProvider      string `env:"EMBEDDING_PROVIDER" envDefault:"openai"`
 	EmbeddingMaxTextBytes  int    `env:"EMBEDDING_MAX_TEXT_BYTES" envDefault:"8192"`
 
+	// === Hybrid Knowledge Retrieval ===
+	KnowledgeHybridSearch      bool    `env:"KNOWLEDGE_HYBRID_SEARCH" envDefault:"true"`
+	KnowledgeHybridRRFK        int     `env:"KNOWLEDGE_HYBRID_RRF_K" envDefault:"60"`
+	KnowledgeHybridTextWeight  float64 `env:"KNOWLEDGE_HYBRID_TEXT_WEIGHT" envDefault:"0.3"`
+	KnowledgeHybridTextWeights string  `env:"KNOWLEDGE_HYBRID_TEXT_WEIGHTS" envDefault:"answer:0.4,code:0.5"`
+	KnowledgeFeedbackWeight    float64 `env:"KNOWLEDGE_FEEDBACK_WEIGHT" envDefault:"0.5"`
+	KnowledgePopularityWeight  float64 `env:"KNOWLEDGE_POPULARITY_WEIGHT" envDefault:"0.1"`
+
+	// === Embedding Model Migration ===
+	EmbeddingReembedAuto      bool `env:"EMBEDDING_REEMBED_AUTO" envDefault:"true"`
+	EmbeddingReembedBatchSize int  `env:"EMBEDDING_REEMBED_BATCH_SIZE" envDefault:"64"`
+	EmbeddingReembedDelayMs   int  `env:"EMBEDDING_REEMBED_DELAY_MS" envDefault:"1000"`
+
+	// === Knowledge Bulk Import ===
+	KnowledgeImportMaxBytes     int64 `env:"KNOWLEDGE_IMPORT_MAX_BYTES" envDefault:"104857600"`
+	KnowledgeImportChunkSize    int   `env:"KNOWLEDGE_IMPORT_CHUNK_SIZE" envDefault:"2000"`
+	KnowledgeImportChunkOverlap int   `env:"KNOWLEDGE_IMPORT_CHUNK_OVERLAP" envDefault:"200"`
+
 	// === Chain Summarization Engine ===
 	SummarizerPreserveLast   bool `env:"SUMMARIZER_PRESERVE_LAST" envDefault:"true"`
 	SummarizerUseQA          bool `env:"SUMMARIZER_USE_QA" envDefault:"true"`
@@ -102,6 +162,7 @@ type Config struct {
 	SummarizerMaxQASections  int  `env:"SUMMARIZER_MAX_QA_SECTIONS" envDefault:"10"`
 	SummarizerMaxQABytes     int  `env:"SUMMARIZER_MAX_QA_BYTES" envDefault:"65536"`
 	SummarizerKeepQASections int  `env:"SUMMARIZER_KEEP_QA_SECTIONS" envDefault:"1"`
+	SummarizerContextPercent int  `env:"SUMMARIZER_CONTEXT_PERCENT" envDefault:"75"`
 
 	// === LLM Provider: Custom/Self-Hosted ===
 	LLMServerURL               string `env:"LLM_SERVER_URL"`
@@ -111,6 +172,8 @@ type Config struct {
 	LLMServerConfig            string `env:"LLM_SERVER_CONFIG_PATH"`
 	LLMServerLegacyReasoning   bool   `env:"LLM_SERVER_LEGACY_REASONING" envDefault:"false"`
 	LLMServerPreserveReasoning bool   `env:"LLM_SERVER_PRESERVE_REASONING" envDefault:"false"`
+	LLMServerProfile           string `env:"LLM_SERVER_PROFILE"`
+	LLMServerToolGrammar       bool   `env:"LLM_SERVER_TOOL_GRAMMAR" envDefault:"false"`
 
 	// === LLM Provider: Ollama (Local/Remote) ===
 	OllamaServerURL               string `env:"OLLAMA_SERVER_URL"`
@@ -183,6 +246,29 @@ type Config struct {
 	OAuthGithubClientID     string `env:"OAUTH_GITHUB_CLIENT_ID"`
 	OAuthGithubClientSecret string `env:"OAUTH_GITHUB_CLIENT_SECRET"`
 
+	// === OAuth Provider: generic OpenID Connect (Keycloak, Okta, ...) ===
+	OAuthOIDCName         string   `env:"OAUTH_OIDC_NAME" envDefault:"oidc"`
+	OAuthOIDCIssuerURL    string   `env:"OAUTH_OIDC_ISSUER_URL"`
+	OAuthOIDCClientID     string   `env:"OAUTH_OIDC_CLIENT_ID"`
+	OAuthOIDCClientSecret string   `env:"OAUTH_OIDC_CLIENT_SECRET"`
+	OAuthOIDCScopes       []string `env:"OAUTH_OIDC_SCOPES" envDefault:"openid,email,profile"`
+	OAuthOIDCEmailClaim   string   `env:"OAUTH_OIDC_EMAIL_CLAIM" envDefault:"email"`
+	OAuthOIDCNameClaim    string   `env:"OAUTH_OIDC_NAME_CLAIM" envDefault:"name"`
+	OAuthOIDCGroupsClaim  string   `env:"OAUTH_OIDC_GROUPS_CLAIM" envDefault:"groups"`
+	OAuthOIDCTrustEmail   bool     `env:"OAUTH_OIDC_TRUST_EMAIL" envDefault:"false"`
+	OAuthOIDCRoleMapping  string   `env:"OAUTH_OIDC_ROLE_MAPPING"`
+
+	// === SAML 2.0 Service Provider ===
+	SAMLIDPMetadataURL  string `env:"SAML_IDP_METADATA_URL"`
+	SAMLIDPMetadataFile string `env:"SAML_IDP_METADATA_FILE"`
+	SAMLSPEntityID      string `env:"SAML_SP_ENTITY_ID"`
+	SAMLSPCertFile      string `env:"SAML_SP_CERT_FILE"`
+	SAMLSPKeyFile       string `env:"SAML_SP_KEY_FILE"`
+	SAMLEmailAttribute  string `env:"SAML_EMAIL_ATTRIBUTE"`
+	SAMLNameAttribute   string `env:"SAML_NAME_ATTRIBUTE"`
+	SAMLGroupsAttribute string `env:"SAML_GROUPS_ATTRIBUTE"`
+	SAMLRoleMapping     string `env:"SAML_ROLE_MAPPING"`
+
 	// === OAuth Callback Configuration ===
 	PublicURL string `env:"PUBLIC_URL" envDefault:""`
 
@@ -227,6 +313,7 @@ type Config struct {
 	AssistantSummarizerMaxQASections  int  `env:"ASSISTANT_SUMMARIZER_MAX_QA_SECTIONS" envDefault:"7"`
 	AssistantSummarizerMaxQABytes     int  `env:"ASSISTANT_SUMMARIZER_MAX_QA_BYTES" envDefault:"76800"`
 	AssistantSummarizerKeepQASections int  `env:"ASSISTANT_SUMMARIZER_KEEP_QA_SECTIONS" envDefault:"3"`
+	AssistantSummarizerContextPercent int  `env:"ASSISTANT_SUMMARIZER_CONTEXT_PERCENT" envDefault:"75"`
 
 	// === Network Proxy Settings ===
 	ProxyURL string `env:"PROXY_URL"`
@@ -251,10 +338,13 @@ type Config struct {
 	LangfusePublicKey string `env:"LANGFUSE_PUBLIC_KEY"`
 	LangfuseSecretKey string `env:"LANGFUSE_SECRET_KEY"`
 
-	// === Knowledge Graph: Graphiti + Neo4j ===
-	GraphitiEnabled bool   `env:"GRAPHITI_ENABLED" envDefault:"false"`
-	GraphitiTimeout int    `env:"GRAPHITI_TIMEOUT" envDefault:"30"`
-	GraphitiURL     string `env:"GRAPHITI_URL"`
+	// === Knowledge Graph: Graphiti + Neo4j or built-in Postgres ===
+	// KnowledgeGraphBackend is "graphiti" (enabled by GRAPHITI_ENABLED and
+	// GRAPHITI_URL) or "postgres" (built-in, always enabled)
+	KnowledgeGraphBackend string `env:"KNOWLEDGE_GRAPH_BACKEND" envDefault:"graphiti"`
+	GraphitiEnabled       bool   `env:"GRAPHITI_ENABLED" envDefault:"false"`
+	GraphitiTimeout       int    `env:"GRAPHITI_TIMEOUT" envDefault:"30"`
+	GraphitiURL           string `env:"GRAPHITI_URL"`
 
 	// === Agent Execution Monitoring ===
 	ExecutionMonitorEnabled        bool `env:"EXECUTION_MONITOR_ENABLED" envDefault:"false"`
@@ -311,6 +401,7 @@ func NewConfig() (*Config, error) {
 
 	ensureInstallationID(&config)
 	ensureLicenseKey(&config)
+	ensureInstanceID(&config)
 
 	return &config, nil
 }
@@ -333,6 +424,18 @@ func ensureInstallationID(config *Config) {
 	_ = os.WriteFile(installationIDPath, []byte(config.InstallationID), 0644)
 }
 
+func ensureInstanceID(config *Config) {
+	if config.InstanceID != "" {
+		return
+	}
+
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		config.InstanceID = hostname
+	} else {
+		config.InstanceID = uuid.New().String()
+	}
+}
+
 func ensureLicenseKey(config *Config) {
 	if config.LicenseKey == "" {
 		return
@@ -441,6 +544,7 @@ func (c *Config) GetSecretPatterns() []patterns.Pattern {
 		{c.DatabaseURL, "Database URL"},
 		{c.LicenseKey, "License Key"},
 		{c.CookieSigningSalt, "Cookie Salt"},
+		{c.CredentialVaultKey, "Credential Vault Key"},
 		{c.OpenAIKey, "OpenAI Key"},
 		{c.AnthropicAPIKey, "Anthropic Key"},
 		{c.EmbeddingKey, "Embedding Key"},
@@ -462,6 +566,7 @@ func (c *Config) GetSecretPatterns() []patterns.Pattern {
 		{c.OAuthGoogleClientSecret, "Google Client Secret"},
 		{c.OAuthGithubClientID, "Github Client ID"},
 		{c.OAuthGithubClientSecret, "Github Client Secret"},
+		{c.OAuthOIDCClientSecret, "OIDC Client Secret"},
 		{c.TraversaalAPIKey, "Traversaal Key"},
 		{c.TavilyAPIKey, "Tavily Key"},
 		{c.FirecrawlAPIKey, "Firecrawl Key"},
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"pentagi/pkg/providers"
 	"pentagi/pkg/providers/provider"
 	"pentagi/pkg/tools"
+	"pentagi/pkg/vault"
 
 	"github.com/sirupsen/logrus"
 )
@@ -46,6 +48,9 @@ type FlowController interface {
 		resources []database.UserResource,
 	) (AssistantWorker, error)
 	LoadFlows(ctx context.Context) error
+	// SuperviseFlows loads the flows handed over to this replica and drops the
+	// ones taken over by others until the context is done
+	SuperviseFlows(ctx context.Context)
 	ListFlows(ctx context.Context) []FlowWorker
 	GetFlow(ctx context.Context, flowID int64) (FlowWorker, error)
 	StopFlow(ctx context.Context, flowID int64) error
@@ -81,14 +86,18 @@ type flowController struct {
 	vslc   VectorStoreLogController
 	tclc   ToolCallLogController
 	sc     ScreenshotController
+	leases FlowLeases
 }
 
+// NewFlowController creates the controller of the flows driven by this backend,
+// leases may be nil when the backend runs as a single replica
 func NewFlowController(
 	db database.Querier,
 	cfg *config.Config,
 	docker docker.DockerClient,
 	provs providers.ProviderController,
 	subs subscriptions.SubscriptionsController,
+	leases FlowLeases,
 ) FlowController {
 	return &flowController{
 		db:     db,
@@ -106,6 +115,7 @@ func NewFlowController(
 		vslc:   NewVectorStoreLogController(db),
 		tclc:   NewToolCallLogController(db),
 		sc:     NewScreenshotController(db),
+		leases: leases,
 	}
 }
 
@@ -116,28 +126,19 @@ func (fc *flowController) LoadFlows(ctx context.Context) error {
 	}
 
 	for _, flow := range flows {
-		fw, err := LoadFlowWorker(ctx, flow, flowWorkerCtx{
-			db:     fc.db,
-			cfg:    fc.cfg,
-			docker: fc.docker,
-			provs:  fc.provs,
-			subs:   fc.subs,
-			flowProviderControllers: flowProviderControllers{
-				mlc:  fc.mlc,
-				aslc: fc.aslc,
-				alc:  fc.alc,
-				slc:  fc.slc,
-				tlc:  fc.tlc,
-				vslc: fc.vslc,
-				tclc: fc.tclc,
-				sc:   fc.sc,
-			},
-		})
-		if err != nil {
-			if errors.Is(err, ErrNothingToLoad) {
-				continue
-			}
+		switch flow.Status {
+		case database.FlowStatusRunning, database.FlowStatusWaiting:
+		default:
+			continue
+		}
 
+		fw, err := fc.loadLeasedFlow(ctx, flow)
+		if errors.Is(err, ErrFlowLeased) {
+			logrus.WithContext(ctx).WithError(err).Infof("flow %d is not loaded", flow.ID)
+			continue
+		} else if errors.Is(err, ErrNothingToLoad) {
+			continue
+		} else if err != nil {
 			logrus.WithContext(ctx).WithError(err).Errorf("failed to load flow %d", flow.ID)
 			continue
 		}
@@ -148,6 +149,122 @@ func (fc *flowController) LoadFlows(ctx context.Context) error {
 	return nil
 }
 
+func (fc *flowController) SuperviseFlows(ctx context.Context) {
+	if fc.leases == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(fc.leases.Interval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fc.superviseFlows(ctx)
+			}
+		}
+	}()
+}
+
+// superviseFlows runs under the lock, so a flow which is being created or
+// loaded by a request can't be mistaken for a lost or a handed over one
+func (fc *flowController) superviseFlows(ctx context.Context) {
+	fc.mx.Lock()
+	defer fc.mx.Unlock()
+
+	logger := logrus.WithContext(ctx).WithField("instance", fc.leases.Owner())
+
+	owned, err := fc.leases.Owned(ctx)
+	if err != nil {
+		logger.WithError(err).Error("failed to get owned flows")
+		return
+	}
+
+	handed := make(map[int64]struct{}, len(owned))
+	for _, flowID := range owned {
+		if _, ok := fc.flows[flowID]; !ok {
+			handed[flowID] = struct{}{}
+		}
+	}
+
+	for flowID, fw := range fc.flows {
+		if slices.Contains(owned, flowID) {
+			continue
+		}
+
+		// the lease expired while the heartbeats failed or was never taken,
+		// the flow is kept unless another replica took it in the meantime
+		err := fc.leases.Acquire(ctx, flowID)
+		if errors.Is(err, ErrFlowLeased) {
+			logger.WithError(err).Warnf("flow %d is taken over, stopping it", flowID)
+			delete(fc.flows, flowID)
+			vault.Forget(flowID)
+			go func() {
+				if err := fw.Stop(ctx); err != nil {
+					logger.WithError(err).Errorf("failed to stop flow %d", flowID)
+				}
+			}()
+		} else if err != nil {
+			logger.WithError(err).Errorf("failed to renew flow %d lease", flowID)
+		}
+	}
+
+	for flowID := range handed {
+		flow, err := fc.db.GetFlow(ctx, flowID)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to get handed over flow %d", flowID)
+			fc.releaseLease(ctx, flowID)
+			continue
+		}
+
+		fw, err := fc.loadLeasedFlow(ctx, flow)
+		if errors.Is(err, ErrNothingToLoad) {
+			continue
+		} else if err != nil {
+			logger.WithError(err).Errorf("failed to load handed over flow %d", flowID)
+			continue
+		}
+
+		fc.flows[flowID] = fw
+		logger.Infof("flow %d is taken over", flowID)
+	}
+}
+
+// loadLeasedFlow loads the flow worker after taking the flow lease, the lease
+// is released again when the flow can't be loaded
+func (fc *flowController) loadLeasedFlow(ctx context.Context, flow database.Flow) (FlowWorker, error) {
+	if err := fc.acquireLease(ctx, flow.ID); err != nil {
+		return nil, err
+	}
+
+	fw, err := LoadFlowWorker(ctx, flow, flowWorkerCtx{
+		db:     fc.db,
+		cfg:    fc.cfg,
+		docker: fc.docker,
+		provs:  fc.provs,
+		subs:   fc.subs,
+		flowProviderControllers: flowProviderControllers{
+			mlc:  fc.mlc,
+			aslc: fc.aslc,
+			alc:  fc.alc,
+			slc:  fc.slc,
+			tlc:  fc.tlc,
+			vslc: fc.vslc,
+			tclc: fc.tclc,
+			sc:   fc.sc,
+		},
+	})
+	if err != nil {
+		fc.releaseLease(ctx, flow.ID)
+		return nil, err
+	}
+
+	return fw, nil
+}
+
 func (fc *flowController) CreateFlow(
 	ctx context.Context,
 	userID int64,
@@ -191,6 +308,11 @@ func (fc *flowController) CreateFlow(
 
 	fc.flows[fw.GetFlowID()] = fw
 
+	// nobody else knows the new flow yet, so the lease can't be taken
+	if err := fc.acquireLease(ctx, fw.GetFlowID()); err != nil {
+		logrus.WithContext(ctx).WithError(err).Errorf("failed to lease new flow %d", fw.GetFlowID())
+	}
+
 	return fw, nil
 }
 
@@ -250,10 +372,18 @@ func (fc *flowController) CreateAssistant(
 		flowID = fw.GetFlowID()
 		fw.SetStatus(ctx, database.FlowStatusWaiting)
 
+		if err := fc.acquireLease(ctx, flowID); err != nil {
+			logrus.WithContext(ctx).WithError(err).Errorf("failed to lease new flow %d", flowID)
+		}
+
 		return nil
 	}
 
 	loadFlow := func() error {
+		if err := fc.acquireLease(ctx, flowID); err != nil {
+			return err
+		}
+
 		flow, err := fc.db.UpdateFlowStatus(ctx, database.UpdateFlowStatusParams{
 			ID:     flowID,
 			Status: database.FlowStatusWaiting,
@@ -264,6 +394,7 @@ func (fc *flowController) CreateAssistant(
 
 		fw, err = LoadFlowWorker(ctx, flow, flowWorkerCtx)
 		if err != nil {
+			fc.releaseLease(ctx, flowID)
 			return fmt.Errorf("failed to load flow %d: %w", flowID, err)
 		}
 
@@ -349,7 +480,7 @@ func (fc *flowController) GetFlow(ctx context.Context, flowID int64) (FlowWorker
 
 	flow, ok := fc.flows[flowID]
 	if !ok {
-		return nil, ErrFlowNotFound
+		return nil, fc.flowNotFound(ctx, flowID)
 	}
 
 	return flow, nil
@@ -361,7 +492,7 @@ func (fc *flowController) StopFlow(ctx context.Context, flowID int64) error {
 
 	flow, ok := fc.flows[flowID]
 	if !ok {
-		return ErrFlowNotFound
+		return fc.flowNotFound(ctx, flowID)
 	}
 
 	err := flow.Stop(ctx)
@@ -378,7 +509,7 @@ func (fc *flowController) FinishFlow(ctx context.Context, flowID int64) error {
 
 	flow, ok := fc.flows[flowID]
 	if !ok {
-		return ErrFlowNotFound
+		return fc.flowNotFound(ctx, flowID)
 	}
 
 	err := flow.Finish(ctx)
@@ -387,10 +518,43 @@ func (fc *flowController) FinishFlow(ctx context.Context, flowID int64) error {
 	}
 
 	delete(fc.flows, flowID)
+	vault.Forget(flowID)
+	fc.releaseLease(ctx, flowID)
 
 	return nil
 }
 
+func (fc *flowController) acquireLease(ctx context.Context, flowID int64) error {
+	if fc.leases == nil {
+		return nil
+	}
+
+	return fc.leases.Acquire(ctx, flowID)
+}
+
+func (fc *flowController) releaseLease(ctx context.Context, flowID int64) {
+	if fc.leases == nil {
+		return
+	}
+
+	fc.leases.Release(ctx, flowID)
+}
+
+// flowNotFound tells a flow which isn't loaded anywhere from a flow which is
+// driven by another replica, the latter must not be changed behind its back
+func (fc *flowController) flowNotFound(ctx context.Context, flowID int64) error {
+	if fc.leases == nil {
+		return ErrFlowNotFound
+	}
+
+	owner, ok, err := fc.leases.Holder(ctx, flowID)
+	if err != nil || !ok || owner == fc.leases.Owner() {
+		return ErrFlowNotFound
+	}
+
+	return fmt.Errorf("flow %d is run by instance '%s': %w", flowID, owner, ErrFlowLeased)
+}
+
 func (fc *flowController) RenameFlow(ctx context.Context, flowID int64, title string) error {
 	fc.mx.Lock()
 	defer fc.mx.Unlock()
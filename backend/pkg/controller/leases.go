@@ -0,0 +1,309 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"pentagi/pkg/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defFlowLeaseTTL = 30 * time.Second
+	minFlowLeaseTTL = 3 * time.Second
+
+	// instances which stopped heartbeats are kept this long for the admins
+	expiredInstanceRetention = 24 * time.Hour
+)
+
+// ErrFlowLeased is returned for a flow which is driven by another backend replica
+var ErrFlowLeased = fmt.Errorf("flow is run by another instance")
+
+// FlowLeases makes sure that every flow is driven by a single backend replica
+// when several replicas share the database. The leases held by this replica
+// are kept alive by heartbeats until they're released. One of the replicas is
+// elected as the leader and hands the running flows of dead replicas over to
+// the live ones, which pick them up with Owned.
+type FlowLeases interface {
+	Owner() string
+	// Acquire takes the lease of the flow, it fails with ErrFlowLeased while
+	// another replica holds an unexpired lease
+	Acquire(ctx context.Context, flowID int64) error
+	Release(ctx context.Context, flowID int64)
+	// Holder returns the owner of the unexpired lease of the flow if there is one
+	Holder(ctx context.Context, flowID int64) (string, bool, error)
+	// Owned returns the flows leased by this replica including the ones which
+	// the leader handed over to it
+	Owned(ctx context.Context) ([]int64, error)
+	// Interval is the period of the heartbeats
+	Interval() time.Duration
+	// Start renews the leases of this replica until the context is done and
+	// releases them afterwards
+	Start(ctx context.Context)
+}
+
+type flowLeases struct {
+	db     database.Querier
+	owner  string
+	ttl    time.Duration
+	logger *logrus.Entry
+
+	mx   sync.Mutex
+	held map[int64]struct{}
+}
+
+func NewFlowLeases(db database.Querier, owner string, ttl time.Duration) FlowLeases {
+	if ttl < minFlowLeaseTTL {
+		ttl = defFlowLeaseTTL
+	}
+
+	return &flowLeases{
+		db:     db,
+		owner:  owner,
+		ttl:    ttl,
+		logger: logrus.WithFields(logrus.Fields{"component": "flow-leases", "owner": owner}),
+		held:   make(map[int64]struct{}),
+	}
+}
+
+func (l *flowLeases) Owner() string {
+	return l.owner
+}
+
+func (l *flowLeases) Acquire(ctx context.Context, flowID int64) error {
+	_, err := l.db.AcquireFlowLease(ctx, database.AcquireFlowLeaseParams{
+		FlowID:     flowID,
+		Owner:      l.owner,
+		TtlSeconds: l.ttlSeconds(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		lease, err := l.db.GetFlowLease(ctx, flowID)
+		if err != nil {
+			return fmt.Errorf("flow %d: %w", flowID, ErrFlowLeased)
+		}
+		return fmt.Errorf("flow %d is run by instance '%s': %w", flowID, lease.Owner, ErrFlowLeased)
+	} else if err != nil {
+		return fmt.Errorf("failed to acquire flow %d lease: %w", flowID, err)
+	}
+
+	l.mx.Lock()
+	l.held[flowID] = struct{}{}
+	l.mx.Unlock()
+
+	return nil
+}
+
+func (l *flowLeases) Release(ctx context.Context, flowID int64) {
+	l.mx.Lock()
+	delete(l.held, flowID)
+	l.mx.Unlock()
+
+	err := l.db.ReleaseFlowLease(ctx, database.ReleaseFlowLeaseParams{
+		FlowID: flowID,
+		Owner:  l.owner,
+	})
+	if err != nil {
+		l.logger.WithError(err).WithField("flow_id", flowID).Warn("failed to release flow lease")
+	}
+}
+
+func (l *flowLeases) Holder(ctx context.Context, flowID int64) (string, bool, error) {
+	lease, err := l.db.GetFlowLease(ctx, flowID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("failed to get flow %d lease: %w", flowID, err)
+	}
+
+	if !lease.ExpiresAt.After(time.Now()) {
+		return "", false, nil
+	}
+
+	return lease.Owner, true, nil
+}
+
+func (l *flowLeases) Owned(ctx context.Context) ([]int64, error) {
+	leases, err := l.db.GetOwnerFlowLeases(ctx, l.owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow leases of '%s': %w", l.owner, err)
+	}
+
+	flowIDs := make([]int64, 0, len(leases))
+	for _, lease := range leases {
+		flowIDs = append(flowIDs, lease.FlowID)
+	}
+
+	return flowIDs, nil
+}
+
+// Interval fits three heartbeats into the lease, so a single slow one doesn't lose it
+func (l *flowLeases) Interval() time.Duration {
+	return l.ttl / 3
+}
+
+func (l *flowLeases) Start(ctx context.Context) {
+	_, err := l.db.RegisterBackendInstance(ctx, database.RegisterBackendInstanceParams{
+		Name:       l.owner,
+		TtlSeconds: l.ttlSeconds(),
+	})
+	if err != nil {
+		l.logger.WithError(err).Error("failed to register backend instance")
+	}
+
+	go func() {
+		ticker := time.NewTicker(l.Interval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				l.releaseAll()
+				return
+			case <-ticker.C:
+				l.heartbeat(ctx)
+				if l.lead(ctx) {
+					l.handOver(ctx)
+				}
+			}
+		}
+	}()
+}
+
+func (l *flowLeases) ttlSeconds() int32 {
+	return int32(l.ttl.Seconds())
+}
+
+func (l *flowLeases) heartbeat(ctx context.Context) {
+	_, err := l.db.RenewBackendInstance(ctx, database.RenewBackendInstanceParams{
+		Name:       l.owner,
+		TtlSeconds: l.ttlSeconds(),
+	})
+	if err != nil {
+		l.logger.WithError(err).Error("failed to renew backend instance")
+	}
+
+	l.renew(ctx)
+}
+
+// lead takes or keeps the leadership, it's lost to another replica only after
+// this one missed the heartbeats for the whole ttl
+func (l *flowLeases) lead(ctx context.Context) bool {
+	_, err := l.db.AcquireBackendLeader(ctx, database.AcquireBackendLeaderParams{
+		Owner:      l.owner,
+		TtlSeconds: l.ttlSeconds(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false
+	} else if err != nil {
+		l.logger.WithError(err).Error("failed to acquire backend leadership")
+		return false
+	}
+
+	return true
+}
+
+// handOver leases the running flows without a live lease to the live replicas
+// driving the fewest flows, they load the flows at their next heartbeat
+func (l *flowLeases) handOver(ctx context.Context) {
+	instances, err := l.db.GetBackendInstances(ctx)
+	if err != nil {
+		l.logger.WithError(err).Error("failed to get backend instances")
+		return
+	}
+
+	live := make([]database.GetBackendInstancesRow, 0, len(instances))
+	for _, instance := range instances {
+		if instance.Alive {
+			live = append(live, instance)
+		}
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	flows, err := l.db.GetOrphanedFlows(ctx, l.ttlSeconds())
+	if err != nil {
+		l.logger.WithError(err).Error("failed to get orphaned flows")
+		return
+	}
+
+	for _, flow := range flows {
+		target := &live[0]
+		for idx := range live {
+			if live[idx].FlowsCount < target.FlowsCount {
+				target = &live[idx]
+			}
+		}
+
+		_, err := l.db.AcquireFlowLease(ctx, database.AcquireFlowLeaseParams{
+			FlowID:     flow.ID,
+			Owner:      target.Name,
+			TtlSeconds: l.ttlSeconds(),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue // a replica took the flow in the meantime
+		} else if err != nil {
+			l.logger.WithError(err).WithField("flow_id", flow.ID).Error("failed to hand over flow")
+			continue
+		}
+
+		target.FlowsCount++
+		l.logger.WithFields(logrus.Fields{
+			"flow_id":  flow.ID,
+			"instance": target.Name,
+		}).Info("flow is handed over")
+	}
+
+	err = l.db.DeleteExpiredBackendInstances(ctx, time.Now().Add(-expiredInstanceRetention))
+	if err != nil {
+		l.logger.WithError(err).Warn("failed to delete expired backend instances")
+	}
+}
+
+func (l *flowLeases) renew(ctx context.Context) {
+	leases, err := l.db.RenewFlowLeases(ctx, database.RenewFlowLeasesParams{
+		TtlSeconds: l.ttlSeconds(),
+		Owner:      l.owner,
+	})
+	if err != nil {
+		l.logger.WithError(err).Error("failed to renew flow leases")
+		return
+	}
+
+	renewed := make(map[int64]struct{}, len(leases))
+	for _, lease := range leases {
+		renewed[lease.FlowID] = struct{}{}
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	for flowID := range l.held {
+		if _, ok := renewed[flowID]; !ok {
+			// the lease expired while the heartbeats failed and another replica took it
+			l.logger.WithField("flow_id", flowID).Error("flow lease is lost")
+			delete(l.held, flowID)
+		}
+	}
+}
+
+func (l *flowLeases) releaseAll() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the leader hands the released flows over to the other replicas right away
+	if err := l.db.ReleaseOwnerFlowLeases(ctx, l.owner); err != nil {
+		l.logger.WithError(err).Warn("failed to release flow leases")
+	}
+	if err := l.db.ReleaseBackendLeader(ctx, l.owner); err != nil {
+		l.logger.WithError(err).Warn("failed to release backend leadership")
+	}
+	if err := l.db.DeleteBackendInstance(ctx, l.owner); err != nil {
+		l.logger.WithError(err).Warn("failed to unregister backend instance")
+	}
+}
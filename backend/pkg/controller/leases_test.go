@@ -0,0 +1,335 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"pentagi/pkg/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// leaseFakeQuerier keeps the leases in memory with the semantics of the
+// AcquireFlowLease upsert: a lease is taken over by its owner or once it expired
+type leaseFakeQuerier struct {
+	database.Querier
+
+	mx        sync.Mutex
+	leases    map[int64]database.FlowLease
+	leader    database.BackendLeader
+	instances []database.GetBackendInstancesRow
+	running   []database.Flow
+}
+
+func newLeaseFakeQuerier() *leaseFakeQuerier {
+	return &leaseFakeQuerier{leases: make(map[int64]database.FlowLease)}
+}
+
+func (q *leaseFakeQuerier) AcquireFlowLease(
+	_ context.Context, arg database.AcquireFlowLeaseParams,
+) (database.FlowLease, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	now := time.Now()
+	lease, ok := q.leases[arg.FlowID]
+	if ok && lease.Owner != arg.Owner && lease.ExpiresAt.After(now) {
+		return database.FlowLease{}, sql.ErrNoRows
+	}
+
+	lease = database.FlowLease{
+		FlowID:      arg.FlowID,
+		Owner:       arg.Owner,
+		AcquiredAt:  now,
+		HeartbeatAt: now,
+		ExpiresAt:   now.Add(time.Duration(arg.TtlSeconds) * time.Second),
+	}
+	q.leases[arg.FlowID] = lease
+	return lease, nil
+}
+
+func (q *leaseFakeQuerier) GetFlowLease(_ context.Context, flowID int64) (database.FlowLease, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	lease, ok := q.leases[flowID]
+	if !ok {
+		return database.FlowLease{}, sql.ErrNoRows
+	}
+	return lease, nil
+}
+
+func (q *leaseFakeQuerier) ReleaseFlowLease(_ context.Context, arg database.ReleaseFlowLeaseParams) error {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	if lease, ok := q.leases[arg.FlowID]; ok && lease.Owner == arg.Owner {
+		delete(q.leases, arg.FlowID)
+	}
+	return nil
+}
+
+func (q *leaseFakeQuerier) RenewFlowLeases(
+	_ context.Context, arg database.RenewFlowLeasesParams,
+) ([]database.FlowLease, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	var renewed []database.FlowLease
+	for flowID, lease := range q.leases {
+		if lease.Owner != arg.Owner {
+			continue
+		}
+		lease.HeartbeatAt = time.Now()
+		lease.ExpiresAt = lease.HeartbeatAt.Add(time.Duration(arg.TtlSeconds) * time.Second)
+		q.leases[flowID] = lease
+		renewed = append(renewed, lease)
+	}
+	return renewed, nil
+}
+
+func (q *leaseFakeQuerier) GetOwnerFlowLeases(_ context.Context, owner string) ([]database.FlowLease, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	var leases []database.FlowLease
+	for _, lease := range q.leases {
+		if lease.Owner == owner && lease.ExpiresAt.After(time.Now()) {
+			leases = append(leases, lease)
+		}
+	}
+	return leases, nil
+}
+
+func (q *leaseFakeQuerier) AcquireBackendLeader(
+	_ context.Context, arg database.AcquireBackendLeaderParams,
+) (database.BackendLeader, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	if q.leader.Owner != "" && q.leader.Owner != arg.Owner && q.leader.ExpiresAt.After(time.Now()) {
+		return database.BackendLeader{}, sql.ErrNoRows
+	}
+
+	q.leader = database.BackendLeader{
+		ID:        1,
+		Owner:     arg.Owner,
+		ExpiresAt: time.Now().Add(time.Duration(arg.TtlSeconds) * time.Second),
+	}
+	return q.leader, nil
+}
+
+func (q *leaseFakeQuerier) GetBackendInstances(_ context.Context) ([]database.GetBackendInstancesRow, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	instances := make([]database.GetBackendInstancesRow, 0, len(q.instances))
+	for _, instance := range q.instances {
+		instance.FlowsCount = 0
+		for _, lease := range q.leases {
+			if lease.Owner == instance.Name && lease.ExpiresAt.After(time.Now()) {
+				instance.FlowsCount++
+			}
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+func (q *leaseFakeQuerier) GetOrphanedFlows(_ context.Context, _ int32) ([]database.Flow, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	var flows []database.Flow
+	for _, flow := range q.running {
+		if lease, ok := q.leases[flow.ID]; !ok || !lease.ExpiresAt.After(time.Now()) {
+			flows = append(flows, flow)
+		}
+	}
+	return flows, nil
+}
+
+func (q *leaseFakeQuerier) DeleteExpiredBackendInstances(_ context.Context, _ time.Time) error {
+	return nil
+}
+
+func (q *leaseFakeQuerier) expire(flowID int64) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	lease := q.leases[flowID]
+	lease.ExpiresAt = time.Now().Add(-time.Second)
+	q.leases[flowID] = lease
+}
+
+func TestFlowLeasesAreExclusive(t *testing.T) {
+	ctx := t.Context()
+	db := newLeaseFakeQuerier()
+	first := NewFlowLeases(db, "backend-1", 30*time.Second)
+	second := NewFlowLeases(db, "backend-2", 30*time.Second)
+
+	require.NoError(t, first.Acquire(ctx, 10))
+	require.NoError(t, first.Acquire(ctx, 10), "the owner may acquire its lease again")
+
+	err := second.Acquire(ctx, 10)
+	require.ErrorIs(t, err, ErrFlowLeased)
+	assert.Contains(t, err.Error(), "backend-1")
+
+	owner, ok, err := second.Holder(ctx, 10)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "backend-1", owner)
+
+	first.Release(ctx, 10)
+	require.NoError(t, second.Acquire(ctx, 10), "a released lease is free")
+}
+
+func TestFlowLeasesExpire(t *testing.T) {
+	ctx := t.Context()
+	db := newLeaseFakeQuerier()
+	first := NewFlowLeases(db, "backend-1", 30*time.Second).(*flowLeases)
+	second := NewFlowLeases(db, "backend-2", 30*time.Second)
+
+	require.NoError(t, first.Acquire(ctx, 10))
+	db.expire(10)
+
+	_, ok, err := second.Holder(ctx, 10)
+	require.NoError(t, err)
+	assert.False(t, ok, "an expired lease has no holder")
+	require.NoError(t, second.Acquire(ctx, 10), "an expired lease is taken over")
+
+	first.renew(ctx)
+	assert.NotContains(t, first.held, int64(10), "the heartbeat notices the lost lease")
+}
+
+func TestFlowNotFoundTellsLeasedFlows(t *testing.T) {
+	ctx := t.Context()
+	db := newLeaseFakeQuerier()
+	other := NewFlowLeases(db, "backend-2", 30*time.Second)
+	require.NoError(t, other.Acquire(ctx, 10))
+
+	fc := &flowController{
+		mx:     &sync.Mutex{},
+		flows:  make(map[int64]FlowWorker),
+		leases: NewFlowLeases(db, "backend-1", 30*time.Second),
+	}
+
+	_, err := fc.GetFlow(ctx, 10)
+	assert.ErrorIs(t, err, ErrFlowLeased)
+	assert.NotErrorIs(t, err, ErrFlowNotFound)
+
+	_, err = fc.GetFlow(ctx, 11)
+	assert.ErrorIs(t, err, ErrFlowNotFound)
+
+	fc.leases = nil
+	_, err = fc.GetFlow(ctx, 10)
+	assert.ErrorIs(t, err, ErrFlowNotFound, "without leases the flow is just not loaded")
+}
+
+func TestFlowLeasesElectOneLeader(t *testing.T) {
+	ctx := t.Context()
+	db := newLeaseFakeQuerier()
+	first := NewFlowLeases(db, "backend-1", 30*time.Second).(*flowLeases)
+	second := NewFlowLeases(db, "backend-2", 30*time.Second).(*flowLeases)
+
+	assert.True(t, first.lead(ctx))
+	assert.False(t, second.lead(ctx), "the leadership is held by the first replica")
+	assert.True(t, first.lead(ctx), "the leader keeps the leadership")
+
+	db.leader.ExpiresAt = time.Now().Add(-time.Second)
+	assert.True(t, second.lead(ctx), "an expired leadership is taken over")
+	assert.False(t, first.lead(ctx))
+}
+
+func TestFlowLeasesHandOverOrphanedFlows(t *testing.T) {
+	ctx := t.Context()
+	db := newLeaseFakeQuerier()
+	leader := NewFlowLeases(db, "backend-1", 30*time.Second).(*flowLeases)
+	dead := NewFlowLeases(db, "backend-3", 30*time.Second)
+
+	db.instances = []database.GetBackendInstancesRow{
+		{Name: "backend-1", Alive: true},
+		{Name: "backend-2", Alive: true},
+		{Name: "backend-3", Alive: false},
+	}
+	for id := int64(1); id <= 5; id++ {
+		db.running = append(db.running, database.Flow{ID: id, Status: database.FlowStatusRunning})
+	}
+
+	require.NoError(t, leader.Acquire(ctx, 1))
+	require.NoError(t, leader.Acquire(ctx, 2))
+	require.NoError(t, dead.Acquire(ctx, 3))
+	require.NoError(t, dead.Acquire(ctx, 4))
+	db.expire(3)
+
+	leader.handOver(ctx)
+
+	owners := map[int64]string{}
+	for flowID, lease := range db.leases {
+		owners[flowID] = lease.Owner
+	}
+	assert.Equal(t, map[int64]string{
+		1: "backend-1",
+		2: "backend-1",
+		3: "backend-2",
+		4: "backend-3", // the lease is still alive, the replica may just be slow
+		5: "backend-2",
+	}, owners, "orphaned flows go to the least loaded live replica")
+
+	owned, err := NewFlowLeases(db, "backend-2", 30*time.Second).Owned(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int64{3, 5}, owned)
+}
+
+type stoppedFlowWorker struct {
+	FlowWorker
+
+	stopped chan struct{}
+}
+
+func (fw *stoppedFlowWorker) Stop(_ context.Context) error {
+	close(fw.stopped)
+	return nil
+}
+
+func TestSuperviseFlowsDropsTakenOverFlows(t *testing.T) {
+	ctx := t.Context()
+	db := newLeaseFakeQuerier()
+	leases := NewFlowLeases(db, "backend-1", 30*time.Second)
+	other := NewFlowLeases(db, "backend-2", 30*time.Second)
+
+	kept := &stoppedFlowWorker{stopped: make(chan struct{})}
+	unleased := &stoppedFlowWorker{stopped: make(chan struct{})}
+	taken := &stoppedFlowWorker{stopped: make(chan struct{})}
+
+	require.NoError(t, leases.Acquire(ctx, 10))
+	require.NoError(t, leases.Acquire(ctx, 12))
+	db.expire(12)
+	require.NoError(t, other.Acquire(ctx, 12))
+
+	fc := &flowController{
+		mx:     &sync.Mutex{},
+		flows:  map[int64]FlowWorker{10: kept, 11: unleased, 12: taken},
+		leases: leases,
+	}
+	fc.superviseFlows(ctx)
+
+	assert.Contains(t, fc.flows, int64(10))
+	assert.Contains(t, fc.flows, int64(11), "a flow without a lease is leased again")
+	assert.NotContains(t, fc.flows, int64(12), "a flow taken over by another replica is dropped")
+
+	owner, ok, err := leases.Holder(ctx, 11)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "backend-1", owner)
+
+	select {
+	case <-taken.stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the dropped flow is not stopped")
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"pentagi/pkg/database"
 	"pentagi/pkg/graph/subscriptions"
+	"pentagi/pkg/vault"
 )
 
 const defaultMaxMessageLength = 2048
@@ -176,7 +177,7 @@ func (mlw *flowMsgLogWorker) UpdateMsgResult(
 	defer mlw.mx.Unlock()
 
 	msgLog, err := mlw.db.UpdateMsgLogResult(ctx, database.UpdateMsgLogResultParams{
-		Result:       database.SanitizeUTF8(result),
+		Result:       database.SanitizeUTF8(vault.RedactFlow(mlw.flowID, result)),
 		ResultFormat: resultFormat,
 		ID:           msgID,
 	})
@@ -197,8 +198,8 @@ func (mlw *flowMsgLogWorker) putMsg(
 ) (int64, error) {
 	msgLog, err := mlw.db.CreateMsgLog(ctx, database.CreateMsgLogParams{
 		Type:      msgType,
-		Message:   database.SanitizeUTF8(msg),
-		Thinking:  database.StringToNullString(database.SanitizeUTF8(thinking)),
+		Message:   database.SanitizeUTF8(vault.RedactFlow(mlw.flowID, msg)),
+		Thinking:  database.StringToNullString(database.SanitizeUTF8(vault.RedactFlow(mlw.flowID, thinking))),
 		FlowID:    mlw.flowID,
 		TaskID:    database.Int64ToNullInt64(taskID),
 		SubtaskID: database.Int64ToNullInt64(subtaskID),
@@ -219,6 +220,8 @@ func (mlw *flowMsgLogWorker) putMsgResult(
 	thinking, msg, result string,
 	resultFormat database.MsglogResultFormat,
 ) (int64, error) {
+	// redacted before truncation, a cut secret would no longer match
+	msg = vault.RedactFlow(mlw.flowID, msg)
 	if len(msg) > defaultMaxMessageLength {
 		msg = msg[:defaultMaxMessageLength] + "..."
 	}
@@ -226,8 +229,8 @@ func (mlw *flowMsgLogWorker) putMsgResult(
 	msgLog, err := mlw.db.CreateResultMsgLog(ctx, database.CreateResultMsgLogParams{
 		Type:         msgType,
 		Message:      database.SanitizeUTF8(msg),
-		Thinking:     database.StringToNullString(database.SanitizeUTF8(thinking)),
-		Result:       database.SanitizeUTF8(result),
+		Thinking:     database.StringToNullString(database.SanitizeUTF8(vault.RedactFlow(mlw.flowID, thinking))),
+		Result:       database.SanitizeUTF8(vault.RedactFlow(mlw.flowID, result)),
 		ResultFormat: resultFormat,
 		FlowID:       mlw.flowID,
 		TaskID:       database.Int64ToNullInt64(taskID),
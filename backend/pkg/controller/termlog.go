@@ -7,6 +7,7 @@ import (
 
 	"pentagi/pkg/database"
 	"pentagi/pkg/graph/subscriptions"
+	"pentagi/pkg/vault"
 )
 
 type FlowTermLogWorker interface {
@@ -66,7 +67,7 @@ func (tlw *flowTermLogWorker) PutMsg(
 
 	termLog, err := tlw.db.CreateTermLog(ctx, database.CreateTermLogParams{
 		Type:        msgType,
-		Text:        database.SanitizeUTF8(msg),
+		Text:        database.SanitizeUTF8(vault.RedactFlow(tlw.flowID, msg)),
 		ContainerID: containerID,
 		FlowID:      tlw.flowID,
 		TaskID:      database.Int64ToNullInt64(taskID),
@@ -44,6 +44,17 @@ const (
 	// keepMinLastQASections defines minimum number of QA sections to keep in the chain (1)
 	keepMinLastQASections = 1
 
+	// contextTriggerPercentage defines the share of the model context window a chain
+	// may fill before it is summarized (75%)
+	contextTriggerPercentage = 75
+
+	// contextTargetPercentage defines the share of the trigger budget the byte limits
+	// are scaled to, so a summarized chain has room to grow before the next pass (50%)
+	contextTargetPercentage = 50
+
+	// minScaledByteSize defines the floor for byte limits scaled to a small context window (1 KB)
+	minScaledByteSize = 1024
+
 	// Default marker prefix for summarized content
 	SummarizedContentPrefix = "**summarized content:**\n"
 )
@@ -58,6 +69,15 @@ type SummarizerConfig struct {
 	MaxQASections  int
 	MaxQABytes     int
 	KeepQASections int
+	// ContextPercent is the share of the model context window that triggers
+	// summarization; it only applies once a ContextWindow is known
+	ContextPercent int
+}
+
+// ContextWindow describes the model a chain is sent to
+type ContextWindow struct {
+	Tokens        int     // context size in tokens, zero when unknown
+	BytesPerToken float64 // tokenizer density used to estimate chain tokens
 }
 
 // Summarizer is a wrapper around the summarizer configuration
@@ -68,10 +88,14 @@ type Summarizer interface {
 		chain []llms.MessageContent,
 		tcIDTemplate string,
 	) ([]llms.MessageContent, error)
+	// WithContextWindow returns a summarizer bound to the given model window.
+	// A zero window keeps the configured byte limits untouched.
+	WithContextWindow(window ContextWindow) Summarizer
 }
 
 type summarizer struct {
 	config SummarizerConfig
+	window ContextWindow
 }
 
 // NewSummarizer creates a new summarizer with the given configuration
@@ -99,9 +123,62 @@ func NewSummarizer(config SummarizerConfig) Summarizer {
 		config.KeepQASections = keepMinLastQASections
 	}
 
+	if config.ContextPercent <= 0 || config.ContextPercent > 100 {
+		config.ContextPercent = contextTriggerPercentage
+	}
+
 	return &summarizer{config: config}
 }
 
+// WithContextWindow returns a copy of the summarizer bound to the model window
+func (s *summarizer) WithContextWindow(window ContextWindow) Summarizer {
+	if window.BytesPerToken <= 0 {
+		window.BytesPerToken = cast.DefaultBytesPerToken
+	}
+
+	return &summarizer{config: s.config, window: window}
+}
+
+// contextConfig returns the configuration to summarize the chain with, or false when
+// the chain still fits into the trigger share of the model window and must be kept as is.
+// Byte limits are scaled so the summarized chain takes contextTargetPercentage of the
+// trigger budget, keeping the proportions between limits the operator configured.
+func (s *summarizer) contextConfig(chain []llms.MessageContent) (SummarizerConfig, bool) {
+	cfg := s.config
+	if s.window.Tokens <= 0 {
+		return cfg, true
+	}
+
+	triggerTokens := s.window.Tokens * cfg.ContextPercent / 100
+	if cast.EstimateTokens(chain, s.window.BytesPerToken) <= triggerTokens {
+		return cfg, false
+	}
+
+	var referenceBytes int
+	switch {
+	case cfg.UseQA:
+		referenceBytes = cfg.MaxQABytes
+	case cfg.PreserveLast:
+		referenceBytes = cfg.LastSecBytes * cfg.KeepQASections
+	default:
+		return cfg, true
+	}
+
+	// The configured limits stay the upper bound: a large window only delays
+	// summarization, it never lets a summarized chain grow past them
+	targetBytes := float64(triggerTokens) * s.window.BytesPerToken * contextTargetPercentage / 100
+	factor := min(targetBytes/float64(referenceBytes), 1)
+	scale := func(size int) int {
+		return max(int(float64(size)*factor), minScaledByteSize)
+	}
+
+	cfg.LastSecBytes = scale(cfg.LastSecBytes)
+	cfg.MaxBPBytes = scale(cfg.MaxBPBytes)
+	cfg.MaxQABytes = scale(cfg.MaxQABytes)
+
+	return cfg, true
+}
+
 // SummarizeChain takes a message chain and summarizes old messages to prevent context from growing too large
 // Uses ChainAST with size tracking for efficient summarization decisions
 func (s *summarizer) SummarizeChain(
@@ -114,6 +191,12 @@ func (s *summarizer) SummarizeChain(
 		return chain, nil
 	}
 
+	// Keep chains which fit into the model window untouched
+	cfg, ok := s.contextConfig(chain)
+	if !ok {
+		return chain, nil
+	}
+
 	// Parse chain into ChainAST with automatic size calculation
 	ast, err := cast.NewChainAST(chain, true)
 	if err != nil {
@@ -122,7 +205,6 @@ func (s *summarizer) SummarizeChain(
 
 	// Apply different summarization strategies sequentially
 	// Each function modifies the ast directly
-	cfg := s.config
 
 	// 0. All sections except last N should have exactly one Completion body pair
 	err = summarizeSections(ctx, ast, handler, cfg.KeepQASections, tcIDTemplate)
@@ -0,0 +1,100 @@
+package csum
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/cast"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vxcontrol/langchaingo/llms"
+)
+
+// newContextTestChain builds a chain of QA sections with the given text size per answer
+func newContextTestChain(sections, answerBytes int) []llms.MessageContent {
+	chain := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, "system prompt"),
+	}
+	for i := 0; i < sections; i++ {
+		chain = append(chain,
+			llms.TextParts(llms.ChatMessageTypeHuman, "question"),
+			llms.TextParts(llms.ChatMessageTypeAI, strings.Repeat("x", answerBytes)),
+		)
+	}
+	return chain
+}
+
+func newContextTestSummarizer() Summarizer {
+	return NewSummarizer(SummarizerConfig{
+		PreserveLast:   true,
+		UseQA:          true,
+		LastSecBytes:   50 * 1024,
+		MaxBPBytes:     16 * 1024,
+		MaxQASections:  10,
+		MaxQABytes:     64 * 1024,
+		KeepQASections: 1,
+	})
+}
+
+func TestSummarizeChain_ContextWindowDefersSummarization(t *testing.T) {
+	// 12 sections of 8 KB exceed both MaxQASections and MaxQABytes
+	chain := newContextTestChain(12, 8*1024)
+
+	mock := newMockSummarizer("summary", nil, nil)
+	result, err := newContextTestSummarizer().SummarizeChain(context.Background(), mock.SummarizerHandler(), chain, cast.ToolCallIDTemplate)
+	require.NoError(t, err)
+	assert.True(t, mock.called, "byte limits must summarize the chain without a known window")
+	assert.Less(t, len(result), len(chain))
+
+	// the same chain is ~33k tokens, well below 75% of a 200k window
+	mock = newMockSummarizer("summary", nil, nil)
+	large := newContextTestSummarizer().WithContextWindow(ContextWindow{Tokens: 200_000, BytesPerToken: 3})
+	result, err = large.SummarizeChain(context.Background(), mock.SummarizerHandler(), chain, cast.ToolCallIDTemplate)
+	require.NoError(t, err)
+	assert.False(t, mock.called, "chain fitting into the window must not be summarized")
+	assert.Equal(t, chain, result)
+}
+
+func TestSummarizeChain_ContextWindowFitsSmallModel(t *testing.T) {
+	// 4 sections of 8 KB stay within the global byte limits, but ~11k tokens
+	// overflow 75% of an 8k window
+	chain := newContextTestChain(4, 8*1024)
+	window := ContextWindow{Tokens: 8192, BytesPerToken: 3}
+	require.Greater(t, cast.EstimateTokens(chain, window.BytesPerToken), window.Tokens)
+
+	mock := newMockSummarizer("summary", nil, nil)
+	small := newContextTestSummarizer().WithContextWindow(window)
+	result, err := small.SummarizeChain(context.Background(), mock.SummarizerHandler(), chain, cast.ToolCallIDTemplate)
+	require.NoError(t, err)
+	assert.True(t, mock.called, "chain overflowing a small window must be summarized")
+	assert.LessOrEqual(t, cast.EstimateTokens(result, window.BytesPerToken), window.Tokens*contextTriggerPercentage/100)
+}
+
+func TestSummarizer_ContextConfig(t *testing.T) {
+	s := newContextTestSummarizer().(*summarizer)
+	chain := newContextTestChain(4, 8*1024)
+
+	cfg, ok := s.contextConfig(chain)
+	assert.True(t, ok)
+	assert.Equal(t, s.config, cfg, "no window keeps the configured limits")
+
+	small := s.WithContextWindow(ContextWindow{Tokens: 8192}).(*summarizer)
+	assert.Equal(t, cast.DefaultBytesPerToken, small.window.BytesPerToken)
+
+	cfg, ok = small.contextConfig(chain)
+	require.True(t, ok)
+	// trigger 6144 tokens * 3 bytes * 50% = 9216 bytes for the whole QA footprint
+	assert.Equal(t, 9216, cfg.MaxQABytes)
+	assert.Equal(t, 7200, cfg.LastSecBytes)
+	assert.Equal(t, 2304, cfg.MaxBPBytes)
+	assert.Equal(t, s.config.MaxQASections, cfg.MaxQASections)
+
+	// a chain overflowing a large window is summarized with the configured limits
+	chain = newContextTestChain(64, 64*1024)
+	large := s.WithContextWindow(ContextWindow{Tokens: 1_000_000, BytesPerToken: 3}).(*summarizer)
+	cfg, ok = large.contextConfig(chain)
+	require.True(t, ok)
+	assert.Equal(t, s.config, cfg, "a large window must not loosen the configured limits")
+}
@@ -8,6 +8,8 @@ package database
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const createAgentLog = `-- name: CreateAgentLog :one
@@ -133,6 +135,85 @@ func (q *Queries) GetFlowAgentLogs(ctx context.Context, flowID int64) ([]Agentlo
 	return items, nil
 }
 
+const getFlowAgentLogsPage = `-- name: GetFlowAgentLogsPage :many
+SELECT
+  al.id, al.initiator, al.executor, al.task, al.result, al.flow_id, al.task_id, al.subtask_id, al.created_at
+FROM agentlogs al
+INNER JOIN flows f ON al.flow_id = f.id
+WHERE al.flow_id = $1 AND f.deleted_at IS NULL
+  AND ($2::bigint IS NULL OR al.id > $2::bigint)
+  AND ($3::bigint IS NULL OR al.id < $3::bigint)
+  AND ($4::timestamptz IS NULL OR al.created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR al.created_at < $5::timestamptz)
+  AND ($6::bigint IS NULL OR al.task_id = $6::bigint)
+  AND ($7::bigint IS NULL OR al.subtask_id = $7::bigint)
+  AND (cardinality($8::text[]) = 0 OR al.executor::text = ANY($8::text[]))
+  AND ($9::text = '' OR search_document(al.task, al.result) @@ websearch_to_tsquery('english', $9::text))
+ORDER BY
+  CASE WHEN $10::bool THEN al.id END DESC,
+  al.id ASC
+LIMIT $11::int
+`
+
+type GetFlowAgentLogsPageParams struct {
+	FlowID    int64         `json:"flow_id"`
+	AfterID   sql.NullInt64 `json:"after_id"`
+	BeforeID  sql.NullInt64 `json:"before_id"`
+	Since     sql.NullTime  `json:"since"`
+	Until     sql.NullTime  `json:"until"`
+	TaskID    sql.NullInt64 `json:"task_id"`
+	SubtaskID sql.NullInt64 `json:"subtask_id"`
+	Types     []string      `json:"types"`
+	Search    string        `json:"search"`
+	Reverse   bool          `json:"reverse"`
+	PageSize  sql.NullInt32 `json:"page_size"`
+}
+
+func (q *Queries) GetFlowAgentLogsPage(ctx context.Context, arg GetFlowAgentLogsPageParams) ([]Agentlog, error) {
+	rows, err := q.db.QueryContext(ctx, getFlowAgentLogsPage,
+		arg.FlowID,
+		arg.AfterID,
+		arg.BeforeID,
+		arg.Since,
+		arg.Until,
+		arg.TaskID,
+		arg.SubtaskID,
+		pq.Array(arg.Types),
+		arg.Search,
+		arg.Reverse,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Agentlog
+	for rows.Next() {
+		var i Agentlog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Initiator,
+			&i.Executor,
+			&i.Task,
+			&i.Result,
+			&i.FlowID,
+			&i.TaskID,
+			&i.SubtaskID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSubtaskAgentLogs = `-- name: GetSubtaskAgentLogs :many
 SELECT
   al.id, al.initiator, al.executor, al.task, al.result, al.flow_id, al.task_id, al.subtask_id, al.created_at
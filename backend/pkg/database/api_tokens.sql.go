@@ -8,6 +8,8 @@ package database
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const createAPIToken = `-- name: CreateAPIToken :one
@@ -17,20 +19,28 @@ INSERT INTO api_tokens (
   role_id,
   name,
   ttl,
-  status
+  status,
+  privileges,
+  flow_ids,
+  template_ids,
+  allowed_ips
 ) VALUES (
-  $1, $2, $3, $4, $5, $6
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
-RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at
+RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at, privileges, flow_ids, template_ids, allowed_ips, last_used_at, last_used_ip
 `
 
 type CreateAPITokenParams struct {
-	TokenID string         `json:"token_id"`
-	UserID  int64          `json:"user_id"`
-	RoleID  int64          `json:"role_id"`
-	Name    sql.NullString `json:"name"`
-	Ttl     int64          `json:"ttl"`
-	Status  TokenStatus    `json:"status"`
+	TokenID     string         `json:"token_id"`
+	UserID      int64          `json:"user_id"`
+	RoleID      int64          `json:"role_id"`
+	Name        sql.NullString `json:"name"`
+	Ttl         int64          `json:"ttl"`
+	Status      TokenStatus    `json:"status"`
+	Privileges  []string       `json:"privileges"`
+	FlowIds     []int64        `json:"flow_ids"`
+	TemplateIds []int64        `json:"template_ids"`
+	AllowedIps  []string       `json:"allowed_ips"`
 }
 
 func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error) {
@@ -41,6 +51,10 @@ func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams)
 		arg.Name,
 		arg.Ttl,
 		arg.Status,
+		pq.Array(arg.Privileges),
+		pq.Array(arg.FlowIds),
+		pq.Array(arg.TemplateIds),
+		pq.Array(arg.AllowedIps),
 	)
 	var i ApiToken
 	err := row.Scan(
@@ -54,6 +68,12 @@ func (q *Queries) CreateAPIToken(ctx context.Context, arg CreateAPITokenParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		pq.Array(&i.Privileges),
+		pq.Array(&i.FlowIds),
+		pq.Array(&i.TemplateIds),
+		pq.Array(&i.AllowedIps),
+		&i.LastUsedAt,
+		&i.LastUsedIp,
 	)
 	return i, err
 }
@@ -62,7 +82,7 @@ const deleteAPIToken = `-- name: DeleteAPIToken :one
 UPDATE api_tokens
 SET deleted_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at
+RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at, privileges, flow_ids, template_ids, allowed_ips, last_used_at, last_used_ip
 `
 
 func (q *Queries) DeleteAPIToken(ctx context.Context, id int64) (ApiToken, error) {
@@ -79,6 +99,12 @@ func (q *Queries) DeleteAPIToken(ctx context.Context, id int64) (ApiToken, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		pq.Array(&i.Privileges),
+		pq.Array(&i.FlowIds),
+		pq.Array(&i.TemplateIds),
+		pq.Array(&i.AllowedIps),
+		&i.LastUsedAt,
+		&i.LastUsedIp,
 	)
 	return i, err
 }
@@ -87,7 +113,7 @@ const deleteUserAPIToken = `-- name: DeleteUserAPIToken :one
 UPDATE api_tokens
 SET deleted_at = CURRENT_TIMESTAMP
 WHERE id = $1 AND user_id = $2
-RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at
+RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at, privileges, flow_ids, template_ids, allowed_ips, last_used_at, last_used_ip
 `
 
 type DeleteUserAPITokenParams struct {
@@ -109,6 +135,12 @@ func (q *Queries) DeleteUserAPIToken(ctx context.Context, arg DeleteUserAPIToken
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		pq.Array(&i.Privileges),
+		pq.Array(&i.FlowIds),
+		pq.Array(&i.TemplateIds),
+		pq.Array(&i.AllowedIps),
+		&i.LastUsedAt,
+		&i.LastUsedIp,
 	)
 	return i, err
 }
@@ -117,7 +149,7 @@ const deleteUserAPITokenByTokenID = `-- name: DeleteUserAPITokenByTokenID :one
 UPDATE api_tokens
 SET deleted_at = CURRENT_TIMESTAMP
 WHERE token_id = $1 AND user_id = $2
-RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at
+RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at, privileges, flow_ids, template_ids, allowed_ips, last_used_at, last_used_ip
 `
 
 type DeleteUserAPITokenByTokenIDParams struct {
@@ -139,13 +171,19 @@ func (q *Queries) DeleteUserAPITokenByTokenID(ctx context.Context, arg DeleteUse
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		pq.Array(&i.Privileges),
+		pq.Array(&i.FlowIds),
+		pq.Array(&i.TemplateIds),
+		pq.Array(&i.AllowedIps),
+		&i.LastUsedAt,
+		&i.LastUsedIp,
 	)
 	return i, err
 }
 
 const getAPIToken = `-- name: GetAPIToken :one
 SELECT
-  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at
+  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at, t.privileges, t.flow_ids, t.template_ids, t.allowed_ips, t.last_used_at, t.last_used_ip
 FROM api_tokens t
 WHERE t.id = $1 AND t.deleted_at IS NULL
 `
@@ -164,13 +202,19 @@ func (q *Queries) GetAPIToken(ctx context.Context, id int64) (ApiToken, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		pq.Array(&i.Privileges),
+		pq.Array(&i.FlowIds),
+		pq.Array(&i.TemplateIds),
+		pq.Array(&i.AllowedIps),
+		&i.LastUsedAt,
+		&i.LastUsedIp,
 	)
 	return i, err
 }
 
 const getAPITokenByTokenID = `-- name: GetAPITokenByTokenID :one
 SELECT
-  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at
+  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at, t.privileges, t.flow_ids, t.template_ids, t.allowed_ips, t.last_used_at, t.last_used_ip
 FROM api_tokens t
 WHERE t.token_id = $1 AND t.deleted_at IS NULL
 `
@@ -189,13 +233,19 @@ func (q *Queries) GetAPITokenByTokenID(ctx context.Context, tokenID string) (Api
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		pq.Array(&i.Privileges),
+		pq.Array(&i.FlowIds),
+		pq.Array(&i.TemplateIds),
+		pq.Array(&i.AllowedIps),
+		&i.LastUsedAt,
+		&i.LastUsedIp,
 	)
 	return i, err
 }
 
 const getAPITokens = `-- name: GetAPITokens :many
 SELECT
-  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at
+  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at, t.privileges, t.flow_ids, t.template_ids, t.allowed_ips, t.last_used_at, t.last_used_ip
 FROM api_tokens t
 WHERE t.deleted_at IS NULL
 ORDER BY t.created_at DESC
@@ -221,6 +271,18 @@ func (q *Queries) GetAPITokens(ctx context.Context) ([]ApiToken, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			pq.Array(&i.Privileges),
+			pq.Array(&i.FlowIds),
+			pq.Array(&i.TemplateIds),
+			pq.Array(&i.AllowedIps),
+			&i.LastUsedAt,
+			&i.LastUsedIp,
+			pq.Array(&i.Privileges),
+			pq.Array(&i.FlowIds),
+			pq.Array(&i.TemplateIds),
+			pq.Array(&i.AllowedIps),
+			&i.LastUsedAt,
+			&i.LastUsedIp,
 		); err != nil {
 			return nil, err
 		}
@@ -237,7 +299,7 @@ func (q *Queries) GetAPITokens(ctx context.Context) ([]ApiToken, error) {
 
 const getUserAPIToken = `-- name: GetUserAPIToken :one
 SELECT
-  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at
+  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at, t.privileges, t.flow_ids, t.template_ids, t.allowed_ips, t.last_used_at, t.last_used_ip
 FROM api_tokens t
 INNER JOIN users u ON t.user_id = u.id
 WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
@@ -262,13 +324,19 @@ func (q *Queries) GetUserAPIToken(ctx context.Context, arg GetUserAPITokenParams
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		pq.Array(&i.Privileges),
+		pq.Array(&i.FlowIds),
+		pq.Array(&i.TemplateIds),
+		pq.Array(&i.AllowedIps),
+		&i.LastUsedAt,
+		&i.LastUsedIp,
 	)
 	return i, err
 }
 
 const getUserAPITokenByTokenID = `-- name: GetUserAPITokenByTokenID :one
 SELECT
-  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at
+  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at, t.privileges, t.flow_ids, t.template_ids, t.allowed_ips, t.last_used_at, t.last_used_ip
 FROM api_tokens t
 INNER JOIN users u ON t.user_id = u.id
 WHERE t.token_id = $1 AND t.user_id = $2 AND t.deleted_at IS NULL
@@ -293,13 +361,19 @@ func (q *Queries) GetUserAPITokenByTokenID(ctx context.Context, arg GetUserAPITo
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		pq.Array(&i.Privileges),
+		pq.Array(&i.FlowIds),
+		pq.Array(&i.TemplateIds),
+		pq.Array(&i.AllowedIps),
+		&i.LastUsedAt,
+		&i.LastUsedIp,
 	)
 	return i, err
 }
 
 const getUserAPITokens = `-- name: GetUserAPITokens :many
 SELECT
-  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at
+  t.id, t.token_id, t.user_id, t.role_id, t.name, t.ttl, t.status, t.created_at, t.updated_at, t.deleted_at, t.privileges, t.flow_ids, t.template_ids, t.allowed_ips, t.last_used_at, t.last_used_ip
 FROM api_tokens t
 INNER JOIN users u ON t.user_id = u.id
 WHERE t.user_id = $1 AND t.deleted_at IS NULL
@@ -326,6 +400,18 @@ func (q *Queries) GetUserAPITokens(ctx context.Context, userID int64) ([]ApiToke
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			pq.Array(&i.Privileges),
+			pq.Array(&i.FlowIds),
+			pq.Array(&i.TemplateIds),
+			pq.Array(&i.AllowedIps),
+			&i.LastUsedAt,
+			&i.LastUsedIp,
+			pq.Array(&i.Privileges),
+			pq.Array(&i.FlowIds),
+			pq.Array(&i.TemplateIds),
+			pq.Array(&i.AllowedIps),
+			&i.LastUsedAt,
+			&i.LastUsedIp,
 		); err != nil {
 			return nil, err
 		}
@@ -344,7 +430,7 @@ const updateAPIToken = `-- name: UpdateAPIToken :one
 UPDATE api_tokens
 SET name = $2, status = $3
 WHERE id = $1
-RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at
+RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at, privileges, flow_ids, template_ids, allowed_ips, last_used_at, last_used_ip
 `
 
 type UpdateAPITokenParams struct {
@@ -367,6 +453,12 @@ func (q *Queries) UpdateAPIToken(ctx context.Context, arg UpdateAPITokenParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		pq.Array(&i.Privileges),
+		pq.Array(&i.FlowIds),
+		pq.Array(&i.TemplateIds),
+		pq.Array(&i.AllowedIps),
+		&i.LastUsedAt,
+		&i.LastUsedIp,
 	)
 	return i, err
 }
@@ -375,7 +467,7 @@ const updateUserAPIToken = `-- name: UpdateUserAPIToken :one
 UPDATE api_tokens
 SET name = $3, status = $4
 WHERE id = $1 AND user_id = $2
-RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at
+RETURNING id, token_id, user_id, role_id, name, ttl, status, created_at, updated_at, deleted_at, privileges, flow_ids, template_ids, allowed_ips, last_used_at, last_used_ip
 `
 
 type UpdateUserAPITokenParams struct {
@@ -404,6 +496,12 @@ func (q *Queries) UpdateUserAPIToken(ctx context.Context, arg UpdateUserAPIToken
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		pq.Array(&i.Privileges),
+		pq.Array(&i.FlowIds),
+		pq.Array(&i.TemplateIds),
+		pq.Array(&i.AllowedIps),
+		&i.LastUsedAt,
+		&i.LastUsedIp,
 	)
 	return i, err
 }
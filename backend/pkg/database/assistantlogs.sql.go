@@ -8,6 +8,8 @@ package database
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const createAssistantLog = `-- name: CreateAssistantLog :one
@@ -190,6 +192,82 @@ func (q *Queries) GetFlowAssistantLogs(ctx context.Context, arg GetFlowAssistant
 	return items, nil
 }
 
+const getFlowAssistantLogsPage = `-- name: GetFlowAssistantLogsPage :many
+SELECT
+  al.id, al.type, al.message, al.result, al.result_format, al.flow_id, al.assistant_id, al.created_at, al.thinking
+FROM assistantlogs al
+INNER JOIN assistants a ON al.assistant_id = a.id
+INNER JOIN flows f ON al.flow_id = f.id
+WHERE al.flow_id = $1 AND al.assistant_id = $2 AND f.deleted_at IS NULL AND a.deleted_at IS NULL
+  AND ($3::bigint IS NULL OR al.id > $3::bigint)
+  AND ($4::bigint IS NULL OR al.id < $4::bigint)
+  AND ($5::timestamptz IS NULL OR al.created_at >= $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR al.created_at < $6::timestamptz)
+  AND (cardinality($7::text[]) = 0 OR al.type::text = ANY($7::text[]))
+  AND ($8::text = '' OR search_document(al.message, al.thinking, al.result) @@ websearch_to_tsquery('english', $8::text))
+ORDER BY
+  CASE WHEN $9::bool THEN al.id END DESC,
+  al.id ASC
+LIMIT $10::int
+`
+
+type GetFlowAssistantLogsPageParams struct {
+	FlowID      int64         `json:"flow_id"`
+	AssistantID int64         `json:"assistant_id"`
+	AfterID     sql.NullInt64 `json:"after_id"`
+	BeforeID    sql.NullInt64 `json:"before_id"`
+	Since       sql.NullTime  `json:"since"`
+	Until       sql.NullTime  `json:"until"`
+	Types       []string      `json:"types"`
+	Search      string        `json:"search"`
+	Reverse     bool          `json:"reverse"`
+	PageSize    sql.NullInt32 `json:"page_size"`
+}
+
+func (q *Queries) GetFlowAssistantLogsPage(ctx context.Context, arg GetFlowAssistantLogsPageParams) ([]Assistantlog, error) {
+	rows, err := q.db.QueryContext(ctx, getFlowAssistantLogsPage,
+		arg.FlowID,
+		arg.AssistantID,
+		arg.AfterID,
+		arg.BeforeID,
+		arg.Since,
+		arg.Until,
+		pq.Array(arg.Types),
+		arg.Search,
+		arg.Reverse,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Assistantlog
+	for rows.Next() {
+		var i Assistantlog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Message,
+			&i.Result,
+			&i.ResultFormat,
+			&i.FlowID,
+			&i.AssistantID,
+			&i.CreatedAt,
+			&i.Thinking,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserFlowAssistantLogs = `-- name: GetUserFlowAssistantLogs :many
 SELECT
   al.id, al.type, al.message, al.result, al.result_format, al.flow_id, al.assistant_id, al.created_at, al.thinking
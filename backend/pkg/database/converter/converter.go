@@ -2,7 +2,13 @@ package converter
 
 import (
 	"encoding/json"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"time"
 
+	"pentagi/pkg/attackgraph"
 	"pentagi/pkg/database"
 	"pentagi/pkg/graph/model"
 	"pentagi/pkg/providers/pconfig"
@@ -40,6 +46,7 @@ func ConvertFlow(flow database.Flow, containers []database.Container) *model.Flo
 		Status:    model.StatusType(flow.Status),
 		Terminals: ConvertContainers(containers),
 		Provider:  provider,
+		ProjectID: database.NullInt64ToInt64(flow.ProjectID),
 		CreatedAt: flow.CreatedAt.Time,
 		UpdatedAt: flow.UpdatedAt.Time,
 	}
@@ -328,6 +335,43 @@ func ConvertToolCallLog(log database.Toolcall) *model.ToolCallLog {
 	}
 }
 
+func ConvertAttackGraph(graph *attackgraph.Graph) *model.AttackGraph {
+	nodes := make([]*model.AttackGraphNode, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodes = append(nodes, &model.AttackGraphNode{
+			ID:          node.ID,
+			Kind:        model.AttackGraphNodeKind(node.Kind),
+			Label:       node.Label,
+			Name:        node.Name,
+			Summary:     node.Summary,
+			FirstSeenAt: node.FirstSeenAt,
+		})
+	}
+
+	edges := make([]*model.AttackGraphEdge, 0, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		edges = append(edges, &model.AttackGraphEdge{
+			ID:          edge.ID,
+			Kind:        model.AttackGraphEdgeKind(edge.Kind),
+			Source:      edge.Source,
+			Target:      edge.Target,
+			Name:        edge.Name,
+			Description: edge.Description,
+			ToolcallID:  edge.ToolcallID,
+			TaskID:      edge.TaskID,
+			SubtaskID:   edge.SubtaskID,
+			Timestamp:   edge.Timestamp,
+		})
+	}
+
+	return &model.AttackGraph{
+		FlowID: graph.FlowID,
+		Nodes:  nodes,
+		Edges:  edges,
+		Dot:    graph.DOT(),
+	}
+}
+
 func ConvertAssistantLogs(logs []database.Assistantlog) []*model.AssistantLog {
 	glogs := make([]*model.AssistantLog, 0, len(logs))
 	for _, log := range logs {
@@ -431,6 +475,25 @@ func ConvertPrompt(prompt database.Prompt) *model.UserPrompt {
 	}
 }
 
+func ConvertAnonymizationPattern(pattern database.AnonymizationPattern) *model.AnonymizationPattern {
+	return &model.AnonymizationPattern{
+		ID:        pattern.ID,
+		Name:      pattern.Name,
+		Regex:     pattern.Regex,
+		Enabled:   pattern.Enabled,
+		CreatedAt: pattern.CreatedAt.Time,
+		UpdatedAt: pattern.UpdatedAt.Time,
+	}
+}
+
+func ConvertAnonymizationPatterns(patterns []database.AnonymizationPattern) []*model.AnonymizationPattern {
+	result := make([]*model.AnonymizationPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		result = append(result, ConvertAnonymizationPattern(pattern))
+	}
+	return result
+}
+
 func ConvertUserPreferences(pref database.UserPreference) *model.UserPreferences {
 	var data struct {
 		FavoriteFlows []int64 `json:"favoriteFlows"`
@@ -460,15 +523,21 @@ func ConvertAPIToken(token database.ApiToken) *model.APIToken {
 	}
 
 	return &model.APIToken{
-		ID:        token.ID,
-		TokenID:   token.TokenID,
-		UserID:    token.UserID,
-		RoleID:    token.RoleID,
-		Name:      name,
-		TTL:       int(token.Ttl),
-		Status:    model.TokenStatus(token.Status),
-		CreatedAt: token.CreatedAt.Time,
-		UpdatedAt: token.UpdatedAt.Time,
+		ID:          token.ID,
+		TokenID:     token.TokenID,
+		UserID:      token.UserID,
+		RoleID:      token.RoleID,
+		Name:        name,
+		TTL:         int(token.Ttl),
+		Status:      model.TokenStatus(token.Status),
+		Privileges:  nonNilSlice(token.Privileges),
+		FlowIds:     nonNilSlice(token.FlowIds),
+		TemplateIds: nonNilSlice(token.TemplateIds),
+		AllowedIps:  nonNilSlice(token.AllowedIps),
+		LastUsedAt:  database.NullTimeToPtrTime(token.LastUsedAt),
+		LastUsedIP:  database.NullStringToPtrString(token.LastUsedIp),
+		CreatedAt:   token.CreatedAt.Time,
+		UpdatedAt:   token.UpdatedAt.Time,
 	}
 }
 
@@ -479,15 +548,21 @@ func ConvertAPITokenRemoveSecret(token database.APITokenWithSecret) *model.APITo
 	}
 
 	return &model.APIToken{
-		ID:        token.ID,
-		TokenID:   token.TokenID,
-		UserID:    token.UserID,
-		RoleID:    token.RoleID,
-		Name:      name,
-		TTL:       int(token.Ttl),
-		Status:    model.TokenStatus(token.Status),
-		CreatedAt: token.CreatedAt.Time,
-		UpdatedAt: token.UpdatedAt.Time,
+		ID:          token.ID,
+		TokenID:     token.TokenID,
+		UserID:      token.UserID,
+		RoleID:      token.RoleID,
+		Name:        name,
+		TTL:         int(token.Ttl),
+		Status:      model.TokenStatus(token.Status),
+		Privileges:  nonNilSlice(token.Privileges),
+		FlowIds:     nonNilSlice(token.FlowIds),
+		TemplateIds: nonNilSlice(token.TemplateIds),
+		AllowedIps:  nonNilSlice(token.AllowedIps),
+		LastUsedAt:  database.NullTimeToPtrTime(token.LastUsedAt),
+		LastUsedIP:  database.NullStringToPtrString(token.LastUsedIp),
+		CreatedAt:   token.CreatedAt.Time,
+		UpdatedAt:   token.UpdatedAt.Time,
 	}
 }
 
@@ -498,17 +573,31 @@ func ConvertAPITokenWithSecret(token database.APITokenWithSecret) *model.APIToke
 	}
 
 	return &model.APITokenWithSecret{
-		ID:        token.ID,
-		TokenID:   token.TokenID,
-		UserID:    token.UserID,
-		RoleID:    token.RoleID,
-		Name:      name,
-		TTL:       int(token.Ttl),
-		Status:    model.TokenStatus(token.Status),
-		CreatedAt: token.CreatedAt.Time,
-		UpdatedAt: token.UpdatedAt.Time,
-		Token:     token.Token,
+		ID:          token.ID,
+		TokenID:     token.TokenID,
+		UserID:      token.UserID,
+		RoleID:      token.RoleID,
+		Name:        name,
+		TTL:         int(token.Ttl),
+		Status:      model.TokenStatus(token.Status),
+		Privileges:  nonNilSlice(token.Privileges),
+		FlowIds:     nonNilSlice(token.FlowIds),
+		TemplateIds: nonNilSlice(token.TemplateIds),
+		AllowedIps:  nonNilSlice(token.AllowedIps),
+		LastUsedAt:  database.NullTimeToPtrTime(token.LastUsedAt),
+		LastUsedIP:  database.NullStringToPtrString(token.LastUsedIp),
+		CreatedAt:   token.CreatedAt.Time,
+		UpdatedAt:   token.UpdatedAt.Time,
+		Token:       token.Token,
+	}
+}
+
+// nonNilSlice keeps the empty token scopes as empty lists in the API
+func nonNilSlice[T any](s []T) []T {
+	if s == nil {
+		return []T{}
 	}
+	return s
 }
 
 func ConvertAPITokens(tokens []database.ApiToken) []*model.APIToken {
@@ -519,10 +608,33 @@ func ConvertAPITokens(tokens []database.ApiToken) []*model.APIToken {
 	return result
 }
 
+func ConvertRole(role database.GetRoleRow) *model.Role {
+	privileges := slices.Clone(role.Privileges)
+	if privileges == nil {
+		privileges = []string{}
+	}
+	slices.Sort(privileges)
+
+	return &model.Role{
+		ID:         role.ID,
+		Name:       role.Name,
+		Privileges: privileges,
+	}
+}
+
+func ConvertRoles(roles []database.GetRolesRow) []*model.Role {
+	result := make([]*model.Role, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, ConvertRole(database.GetRoleRow(role)))
+	}
+	return result
+}
+
 func ConvertFlowTemplate(template database.FlowTemplate) *model.FlowTemplate {
 	return &model.FlowTemplate{
 		ID:        template.ID,
 		UserID:    template.UserID,
+		ProjectID: database.NullInt64ToInt64(template.ProjectID),
 		Title:     template.Title,
 		Text:      template.Text,
 		CreatedAt: template.CreatedAt.Time,
@@ -538,6 +650,130 @@ func ConvertFlowTemplates(templates []database.FlowTemplate) []*model.FlowTempla
 	return result
 }
 
+func ConvertProject(project database.Project, role *database.ProjectRole) *model.Project {
+	result := &model.Project{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		UserID:      project.UserID,
+		CreatedAt:   project.CreatedAt.Time,
+		UpdatedAt:   project.UpdatedAt.Time,
+	}
+	if role != nil {
+		memberRole := model.ProjectRole(*role)
+		result.Role = &memberRole
+	}
+	return result
+}
+
+func ConvertProjects(projects []database.Project) []*model.Project {
+	result := make([]*model.Project, 0, len(projects))
+	for _, project := range projects {
+		result = append(result, ConvertProject(project, nil))
+	}
+	return result
+}
+
+func ConvertUserProject(row database.GetUserProjectsRow) *model.Project {
+	project := database.Project{
+		ID:          row.ID,
+		Name:        row.Name,
+		Description: row.Description,
+		UserID:      row.UserID,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	return ConvertProject(project, &row.MemberRole)
+}
+
+func ConvertUserProjects(rows []database.GetUserProjectsRow) []*model.Project {
+	result := make([]*model.Project, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, ConvertUserProject(row))
+	}
+	return result
+}
+
+func ConvertProjectMember(member database.GetProjectMembersRow) *model.ProjectMember {
+	return &model.ProjectMember{
+		UserID:    member.UserID,
+		Name:      member.UserName,
+		Mail:      member.UserMail,
+		Role:      model.ProjectRole(member.Role),
+		CreatedAt: member.CreatedAt.Time,
+	}
+}
+
+func ConvertProjectMembers(members []database.GetProjectMembersRow) []*model.ProjectMember {
+	result := make([]*model.ProjectMember, 0, len(members))
+	for _, member := range members {
+		result = append(result, ConvertProjectMember(member))
+	}
+	return result
+}
+
+func ConvertWebhook(hook database.Webhook) *model.Webhook {
+	events := slices.Clone(hook.Events)
+	if events == nil {
+		events = []string{}
+	}
+	flowIDs := slices.Clone(hook.FlowIds)
+	if flowIDs == nil {
+		flowIDs = []int64{}
+	}
+
+	return &model.Webhook{
+		ID:        hook.ID,
+		UserID:    hook.UserID,
+		Name:      hook.Name,
+		URL:       hook.Url,
+		HasSecret: hook.Secret != "",
+		Events:    events,
+		FlowIds:   flowIDs,
+		Enabled:   hook.Enabled,
+		CreatedAt: hook.CreatedAt.Time,
+		UpdatedAt: hook.UpdatedAt.Time,
+	}
+}
+
+func ConvertWebhooks(hooks []database.Webhook) []*model.Webhook {
+	result := make([]*model.Webhook, 0, len(hooks))
+	for _, hook := range hooks {
+		result = append(result, ConvertWebhook(hook))
+	}
+	return result
+}
+
+func ConvertWebhookDelivery(delivery database.WebhookDelivery) *model.WebhookDelivery {
+	result := &model.WebhookDelivery{
+		ID:            delivery.ID,
+		WebhookID:     delivery.WebhookID,
+		Event:         delivery.Event,
+		FlowID:        database.NullInt64ToInt64(delivery.FlowID),
+		Payload:       string(delivery.Payload),
+		Status:        model.WebhookDeliveryStatus(delivery.Status),
+		Attempts:      int(delivery.Attempts),
+		NextAttemptAt: delivery.NextAttemptAt,
+		ResponseBody:  delivery.ResponseBody,
+		Error:         delivery.Error,
+		DeliveredAt:   database.NullTimeToPtrTime(delivery.DeliveredAt),
+		CreatedAt:     delivery.CreatedAt.Time,
+	}
+	if delivery.ResponseStatus.Valid {
+		status := int(delivery.ResponseStatus.Int32)
+		result.ResponseStatus = &status
+	}
+	return result
+}
+
+func ConvertWebhookDeliveries(deliveries []database.WebhookDelivery) []*model.WebhookDelivery {
+	result := make([]*model.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, ConvertWebhookDelivery(delivery))
+	}
+	return result
+}
+
 func ConvertModels(models pconfig.ModelsConfig, rp reasoning.Provider) []*model.ModelConfig {
 	gmodels := make([]*model.ModelConfig, 0, len(models))
 	for _, m := range models {
@@ -563,6 +799,12 @@ func ConvertModels(models pconfig.ModelsConfig, rp reasoning.Provider) []*model.
 		if m.Thinking != nil {
 			modelConfig.Thinking = m.Thinking
 		}
+		if m.ContextWindow > 0 {
+			modelConfig.ContextWindow = &m.ContextWindow
+		}
+		if m.Vision {
+			modelConfig.Vision = &m.Vision
+		}
 		// Surface reasoning capability for any thinking-capable model, not only
 		// those with an explicit reasoning block: models like Gemini declare
 		// thinking:true with no reasoning section, yet Off (thinkingBudget:0) is
@@ -631,6 +873,7 @@ func ConvertProvider(prv database.Provider, cfg *pconfig.ProviderConfig) *model.
 		ID:        prv.ID,
 		Name:      prv.Name,
 		Type:      model.ProviderType(prv.Type),
+		ProjectID: database.NullInt64ToInt64(prv.ProjectID),
 		Agents:    ConvertProviderConfigToGqlModel(cfg),
 		CreatedAt: prv.CreatedAt.Time,
 		UpdatedAt: prv.UpdatedAt.Time,
@@ -738,6 +981,20 @@ func ConvertAgentConfigToGqlModel(ac *pconfig.AgentConfig) *model.AgentConfig {
 	if ac.ExtraBody != nil {
 		result.ExtraBody = ac.ExtraBody
 	}
+	if ac.ToolCallFixer {
+		result.ToolCallFixer = &ac.ToolCallFixer
+	}
+	if ac.Cache != nil {
+		result.Cache = &model.PromptCacheConfig{
+			System:  ac.Cache.System,
+			Tools:   ac.Cache.Tools,
+			History: ac.Cache.History,
+		}
+		if ac.Cache.TTL != pconfig.CacheTTLDefault {
+			ttl := string(ac.Cache.TTL)
+			result.Cache.TTL = &ttl
+		}
+	}
 
 	return result
 }
@@ -850,6 +1107,20 @@ func ConvertAgentConfigFromGqlModel(ac *model.AgentConfig) *pconfig.AgentConfig
 	if ac.ExtraBody != nil {
 		rawConfig["extra_body"] = ac.ExtraBody
 	}
+	if ac.ToolCallFixer != nil && *ac.ToolCallFixer {
+		rawConfig["tool_call_fixer"] = *ac.ToolCallFixer
+	}
+	if ac.Cache != nil {
+		cache := map[string]any{
+			"system":  ac.Cache.System,
+			"tools":   ac.Cache.Tools,
+			"history": ac.Cache.History,
+		}
+		if ac.Cache.TTL != nil && *ac.Cache.TTL != "" {
+			cache["ttl"] = *ac.Cache.TTL
+		}
+		rawConfig["cache"] = cache
+	}
 
 	jsonConfig, err := json.Marshal(rawConfig)
 	if err != nil {
@@ -921,6 +1192,44 @@ func ConvertProviderTestResults(results tester.ProviderTestResults) *model.Provi
 	}
 }
 
+func ConvertProviderCapabilities(capabilities []database.ProviderCapability) []*model.ModelCapabilities {
+	gcapabilities := make([]*model.ModelCapabilities, 0, len(capabilities))
+	for _, c := range capabilities {
+		gcapabilities = append(gcapabilities, ConvertProviderCapability(c))
+	}
+
+	return gcapabilities
+}
+
+func ConvertProviderCapability(c database.ProviderCapability) *model.ModelCapabilities {
+	result := &model.ModelCapabilities{
+		ProviderID:  c.ProviderID,
+		Type:        model.ProviderType(c.Type),
+		Model:       c.Model,
+		AgentTypes:  make([]model.AgentConfigType, 0, len(c.AgentTypes)),
+		Reasoning:   c.Reasoning,
+		MaxContext:  int(c.MaxContext),
+		TestsTotal:  int(c.TestsTotal),
+		TestsPassed: int(c.TestsPassed),
+		UpdatedAt:   c.UpdatedAt.Time,
+	}
+
+	for _, agentType := range c.AgentTypes {
+		result.AgentTypes = append(result.AgentTypes, model.AgentConfigType(agentType))
+	}
+	if c.ToolCalling.Valid {
+		result.ToolCalling = &c.ToolCalling.Float64
+	}
+	if c.JsonMode.Valid {
+		result.JSONMode = &c.JsonMode.Bool
+	}
+	if c.Streaming.Valid {
+		result.Streaming = &c.Streaming.Bool
+	}
+
+	return result
+}
+
 // UsageStatsRow constraint for generic conversion
 type UsageStatsRow interface {
 	database.GetFlowUsageStatsRow |
@@ -1013,6 +1322,7 @@ func ConvertUsageStats[T UsageStatsRow](stats T) *model.UsageStats {
 		TotalUsageCacheOut: int(cacheOut),
 		TotalUsageCostIn:   costIn,
 		TotalUsageCostOut:  costOut,
+		CacheHitRatio:      pconfig.CacheHitRatio(in, cacheIn),
 	}
 }
 
@@ -1335,6 +1645,7 @@ func ConvertUserResource(r database.UserResource) *model.UserResource {
 	return &model.UserResource{
 		ID:        r.ID,
 		UserID:    r.UserID,
+		ProjectID: database.NullInt64ToInt64(r.ProjectID),
 		Name:      r.Name,
 		Path:      r.Path,
 		Size:      int(r.Size),
@@ -1343,3 +1654,97 @@ func ConvertUserResource(r database.UserResource) *model.UserResource {
 		UpdatedAt: r.UpdatedAt.Time,
 	}
 }
+
+// ConvertBackendInstances marks the instance named by the leader row, the
+// leader is empty while nobody holds the leadership
+func ConvertBackendInstances(instances []database.GetBackendInstancesRow, leader string) []*model.BackendInstance {
+	result := make([]*model.BackendInstance, 0, len(instances))
+	for _, instance := range instances {
+		result = append(result, &model.BackendInstance{
+			Name:        instance.Name,
+			StartedAt:   instance.StartedAt,
+			HeartbeatAt: instance.HeartbeatAt,
+			ExpiresAt:   instance.ExpiresAt,
+			Alive:       instance.Alive,
+			Leader:      instance.Alive && instance.Name == leader,
+			FlowsCount:  int(instance.FlowsCount),
+		})
+	}
+	return result
+}
+
+func ConvertFlowLease(lease database.FlowLease, now time.Time) *model.FlowLease {
+	return &model.FlowLease{
+		FlowID:      lease.FlowID,
+		Instance:    lease.Owner,
+		AcquiredAt:  lease.AcquiredAt,
+		HeartbeatAt: lease.HeartbeatAt,
+		ExpiresAt:   lease.ExpiresAt,
+		Expired:     !lease.ExpiresAt.After(now),
+	}
+}
+
+func ConvertFlowLeases(leases []database.FlowLease, now time.Time) []*model.FlowLease {
+	result := make([]*model.FlowLease, 0, len(leases))
+	for _, lease := range leases {
+		result = append(result, ConvertFlowLease(lease, now))
+	}
+	return result
+}
+
+// searchSnippetMarks are the StartSel and StopSel of ts_headline in SearchFlows
+const (
+	searchSnippetOpen  = "<mark>"
+	searchSnippetClose = "</mark>"
+)
+
+func ConvertSearchResult(row database.SearchFlowsRow) *model.SearchResult {
+	link := fmt.Sprintf("/flows/%d", row.FlowID)
+	if kind := model.SearchResultKind(row.Kind); kind != model.SearchResultKindFlow {
+		link += fmt.Sprintf("#%s-%d", kind, row.ObjectID)
+	}
+
+	return &model.SearchResult{
+		Kind:      model.SearchResultKind(row.Kind),
+		FlowID:    row.FlowID,
+		FlowTitle: row.FlowTitle,
+		TaskID:    database.NullInt64ToInt64(row.TaskID),
+		SubtaskID: database.NullInt64ToInt64(row.SubtaskID),
+		ObjectID:  row.ObjectID,
+		Rank:      float64(row.Rank),
+		Snippet:   escapeSearchSnippet(row.Snippet),
+		Link:      link,
+		CreatedAt: row.CreatedAt.Time,
+	}
+}
+
+func ConvertSearchResults(rows []database.SearchFlowsRow) []*model.SearchResult {
+	results := make([]*model.SearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, ConvertSearchResult(row))
+	}
+	return results
+}
+
+// escapeSearchSnippet escapes the logged text of a ts_headline snippet and keeps
+// its highlight marks, so the snippet can be rendered as HTML
+func escapeSearchSnippet(snippet string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(snippet, searchSnippetOpen)
+		if start < 0 {
+			break
+		}
+		term, rest, ok := strings.Cut(snippet[start+len(searchSnippetOpen):], searchSnippetClose)
+		if !ok {
+			break
+		}
+		sb.WriteString(html.EscapeString(snippet[:start]))
+		sb.WriteString(searchSnippetOpen)
+		sb.WriteString(html.EscapeString(term))
+		sb.WriteString(searchSnippetClose)
+		snippet = rest
+	}
+	sb.WriteString(html.EscapeString(snippet))
+	return sb.String()
+}
@@ -1,8 +1,10 @@
 package converter
 
 import (
+	"database/sql"
 	"testing"
 
+	"pentagi/pkg/database"
 	"pentagi/pkg/graph/model"
 	"pentagi/pkg/providers/openai"
 	"pentagi/pkg/providers/pconfig"
@@ -282,3 +284,24 @@ func TestShippedSimpleJSONKeepsJSONModeThroughTheGraphQLRoundTrip(t *testing.T)
 
 	assert.True(t, call.JSONMode, "the simple_json agent must still ask for JSON mode after a save")
 }
+
+func TestConvertSearchResult(t *testing.T) {
+	result := ConvertSearchResult(database.SearchFlowsRow{
+		Kind:      "terminal",
+		FlowID:    12,
+		FlowTitle: "Jenkins",
+		TaskID:    sql.NullInt64{Int64: 3, Valid: true},
+		ObjectID:  345,
+		Rank:      0.5,
+		Snippet:   `curl <mark>jenkins</mark> <script> ... <b><mark>Jenkins</mark>`,
+	})
+
+	assert.Equal(t, "/flows/12#terminal-345", result.Link)
+	assert.Equal(t, int64(3), *result.TaskID)
+	assert.Nil(t, result.SubtaskID)
+	assert.Equal(t, `curl <mark>jenkins</mark> &lt;script&gt; ... &lt;b&gt;<mark>Jenkins</mark>`, result.Snippet)
+
+	result = ConvertSearchResult(database.SearchFlowsRow{Kind: "flow", FlowID: 12, ObjectID: 12, Snippet: "a <mark>b"})
+	assert.Equal(t, "/flows/12", result.Link)
+	assert.Equal(t, "a &lt;mark&gt;b", result.Snippet, "an unclosed mark is escaped")
+}
@@ -64,6 +64,13 @@ func PtrTimeToNullTime(t *time.Time) sql.NullTime {
 	return sql.NullTime{Time: *t, Valid: true}
 }
 
+func NullTimeToPtrTime(t sql.NullTime) *time.Time {
+	if t.Valid {
+		return &t.Time
+	}
+	return nil
+}
+
 func SanitizeUTF8(msg string) string {
 	if msg == "" {
 		return ""
@@ -7,6 +7,9 @@ package database
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const createFlowTemplate = `-- name: CreateFlowTemplate :one
@@ -19,7 +22,7 @@ INSERT INTO flow_templates (
   $2,
   $3
 )
-RETURNING id, user_id, title, text, created_at, updated_at
+RETURNING id, user_id, title, text, created_at, updated_at, project_id
 `
 
 type CreateFlowTemplateParams struct {
@@ -38,6 +41,7 @@ func (q *Queries) CreateFlowTemplate(ctx context.Context, arg CreateFlowTemplate
 		&i.Text,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -58,7 +62,7 @@ func (q *Queries) DeleteFlowTemplate(ctx context.Context, arg DeleteFlowTemplate
 }
 
 const getFlowTemplate = `-- name: GetFlowTemplate :one
-SELECT id, user_id, title, text, created_at, updated_at FROM flow_templates
+SELECT id, user_id, title, text, created_at, updated_at, project_id FROM flow_templates
 WHERE id = $1 AND user_id = $2 LIMIT 1
 `
 
@@ -77,13 +81,53 @@ func (q *Queries) GetFlowTemplate(ctx context.Context, arg GetFlowTemplateParams
 		&i.Text,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
 
+const getFlowTemplatesByIDs = `-- name: GetFlowTemplatesByIDs :many
+SELECT id, user_id, title, text, created_at, updated_at, project_id FROM flow_templates
+WHERE id = ANY($1::BIGINT[])
+ORDER BY id
+`
+
+func (q *Queries) GetFlowTemplatesByIDs(ctx context.Context, ids []int64) ([]FlowTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, getFlowTemplatesByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FlowTemplate
+	for rows.Next() {
+		var i FlowTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Text,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ProjectID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFlowTemplatesByUserID = `-- name: GetFlowTemplatesByUserID :many
-SELECT id, user_id, title, text, created_at, updated_at FROM flow_templates
-WHERE user_id = $1
+SELECT id, user_id, title, text, created_at, updated_at, project_id FROM flow_templates
+WHERE user_id = $1 OR project_id IN (
+  SELECT m.project_id FROM project_members m WHERE m.user_id = $1
+)
 ORDER BY created_at DESC
 `
 
@@ -103,6 +147,7 @@ func (q *Queries) GetFlowTemplatesByUserID(ctx context.Context, userID int64) ([
 			&i.Text,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -123,7 +168,7 @@ SET
   title = $3,
   text = $4
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, title, text, created_at, updated_at
+RETURNING id, user_id, title, text, created_at, updated_at, project_id
 `
 
 type UpdateFlowTemplateParams struct {
@@ -148,6 +193,34 @@ func (q *Queries) UpdateFlowTemplate(ctx context.Context, arg UpdateFlowTemplate
 		&i.Text,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProjectID,
+	)
+	return i, err
+}
+
+const updateFlowTemplateProject = `-- name: UpdateFlowTemplateProject :one
+UPDATE flow_templates
+SET project_id = $1
+WHERE id = $2
+RETURNING id, user_id, title, text, created_at, updated_at, project_id
+`
+
+type UpdateFlowTemplateProjectParams struct {
+	ProjectID sql.NullInt64 `json:"project_id"`
+	ID        int64         `json:"id"`
+}
+
+func (q *Queries) UpdateFlowTemplateProject(ctx context.Context, arg UpdateFlowTemplateProjectParams) (FlowTemplate, error) {
+	row := q.db.QueryRowContext(ctx, updateFlowTemplateProject, arg.ProjectID, arg.ID)
+	var i FlowTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Text,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
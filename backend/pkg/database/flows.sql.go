@@ -19,7 +19,7 @@ INSERT INTO flows (
 VALUES (
   $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, project_id
 `
 
 type CreateFlowParams struct {
@@ -62,6 +62,7 @@ func (q *Queries) CreateFlow(ctx context.Context, arg CreateFlowParams) (Flow, e
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -70,7 +71,7 @@ const deleteFlow = `-- name: DeleteFlow :one
 UPDATE flows
 SET deleted_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, project_id
 `
 
 func (q *Queries) DeleteFlow(ctx context.Context, id int64) (Flow, error) {
@@ -91,13 +92,14 @@ func (q *Queries) DeleteFlow(ctx context.Context, id int64) (Flow, error) {
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.ProjectID,
 	)
 	return i, err
 }
 
 const getFlow = `-- name: GetFlow :one
 SELECT
-  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template
+  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template, f.project_id
 FROM flows f
 WHERE f.id = $1 AND f.deleted_at IS NULL
 `
@@ -120,6 +122,7 @@ func (q *Queries) GetFlow(ctx context.Context, id int64) (Flow, error) {
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -154,7 +157,7 @@ func (q *Queries) GetFlowStats(ctx context.Context, id int64) (GetFlowStatsRow,
 
 const getFlows = `-- name: GetFlows :many
 SELECT
-  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template
+  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template, f.project_id
 FROM flows f
 WHERE f.deleted_at IS NULL
 ORDER BY f.created_at DESC
@@ -184,6 +187,7 @@ func (q *Queries) GetFlows(ctx context.Context) ([]Flow, error) {
 			&i.TraceID,
 			&i.ModelProviderType,
 			&i.ToolCallIDTemplate,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -362,7 +366,7 @@ func (q *Queries) GetFlowsStatsByDayLastWeek(ctx context.Context, userID int64)
 
 const getUserFlow = `-- name: GetUserFlow :one
 SELECT
-  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template
+  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template, f.project_id
 FROM flows f
 INNER JOIN users u ON f.user_id = u.id
 WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL
@@ -391,16 +395,19 @@ func (q *Queries) GetUserFlow(ctx context.Context, arg GetUserFlowParams) (Flow,
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.ProjectID,
 	)
 	return i, err
 }
 
 const getUserFlows = `-- name: GetUserFlows :many
 SELECT
-  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template
+  f.id, f.status, f.title, f.model, f.model_provider_name, f.language, f.functions, f.user_id, f.created_at, f.updated_at, f.deleted_at, f.trace_id, f.model_provider_type, f.tool_call_id_template, f.project_id
 FROM flows f
 INNER JOIN users u ON f.user_id = u.id
-WHERE f.user_id = $1 AND f.deleted_at IS NULL
+WHERE (f.user_id = $1 OR f.project_id IN (
+    SELECT m.project_id FROM project_members m WHERE m.user_id = $1
+  )) AND f.deleted_at IS NULL
 ORDER BY f.created_at DESC
 `
 
@@ -428,6 +435,7 @@ func (q *Queries) GetUserFlows(ctx context.Context, userID int64) ([]Flow, error
 			&i.TraceID,
 			&i.ModelProviderType,
 			&i.ToolCallIDTemplate,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -479,7 +487,7 @@ const updateFlow = `-- name: UpdateFlow :one
 UPDATE flows
 SET title = $1, model = $2, language = $3, tool_call_id_template = $4, functions = $5, trace_id = $6
 WHERE id = $7
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, project_id
 `
 
 type UpdateFlowParams struct {
@@ -518,6 +526,7 @@ func (q *Queries) UpdateFlow(ctx context.Context, arg UpdateFlowParams) (Flow, e
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -526,7 +535,7 @@ const updateFlowLanguage = `-- name: UpdateFlowLanguage :one
 UPDATE flows
 SET language = $1
 WHERE id = $2
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, project_id
 `
 
 type UpdateFlowLanguageParams struct {
@@ -552,6 +561,42 @@ func (q *Queries) UpdateFlowLanguage(ctx context.Context, arg UpdateFlowLanguage
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.ProjectID,
+	)
+	return i, err
+}
+
+const updateFlowProject = `-- name: UpdateFlowProject :one
+UPDATE flows
+SET project_id = $1
+WHERE id = $2
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, project_id
+`
+
+type UpdateFlowProjectParams struct {
+	ProjectID sql.NullInt64 `json:"project_id"`
+	ID        int64         `json:"id"`
+}
+
+func (q *Queries) UpdateFlowProject(ctx context.Context, arg UpdateFlowProjectParams) (Flow, error) {
+	row := q.db.QueryRowContext(ctx, updateFlowProject, arg.ProjectID, arg.ID)
+	var i Flow
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Title,
+		&i.Model,
+		&i.ModelProviderName,
+		&i.Language,
+		&i.Functions,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TraceID,
+		&i.ModelProviderType,
+		&i.ToolCallIDTemplate,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -560,7 +605,7 @@ const updateFlowProvider = `-- name: UpdateFlowProvider :one
 UPDATE flows
 SET model_provider_name = $1, model_provider_type = $2, tool_call_id_template = $3, model = $4
 WHERE id = $5
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, project_id
 `
 
 type UpdateFlowProviderParams struct {
@@ -595,6 +640,7 @@ func (q *Queries) UpdateFlowProvider(ctx context.Context, arg UpdateFlowProvider
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -603,7 +649,7 @@ const updateFlowStatus = `-- name: UpdateFlowStatus :one
 UPDATE flows
 SET status = $1
 WHERE id = $2
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, project_id
 `
 
 type UpdateFlowStatusParams struct {
@@ -629,6 +675,7 @@ func (q *Queries) UpdateFlowStatus(ctx context.Context, arg UpdateFlowStatusPara
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -637,7 +684,7 @@ const updateFlowTitle = `-- name: UpdateFlowTitle :one
 UPDATE flows
 SET title = $1
 WHERE id = $2
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, project_id
 `
 
 type UpdateFlowTitleParams struct {
@@ -663,6 +710,7 @@ func (q *Queries) UpdateFlowTitle(ctx context.Context, arg UpdateFlowTitleParams
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -671,7 +719,7 @@ const updateFlowToolCallIDTemplate = `-- name: UpdateFlowToolCallIDTemplate :one
 UPDATE flows
 SET tool_call_id_template = $1
 WHERE id = $2
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, project_id
 `
 
 type UpdateFlowToolCallIDTemplateParams struct {
@@ -697,6 +745,7 @@ func (q *Queries) UpdateFlowToolCallIDTemplate(ctx context.Context, arg UpdateFl
 		&i.TraceID,
 		&i.ModelProviderType,
 		&i.ToolCallIDTemplate,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -705,7 +754,7 @@ const updateFlowsProviderNameByOldName = `-- name: UpdateFlowsProviderNameByOldN
 UPDATE flows
 SET model_provider_name = $1
 WHERE user_id = $2 AND model_provider_name = $3 AND deleted_at IS NULL
-RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template
+RETURNING id, status, title, model, model_provider_name, language, functions, user_id, created_at, updated_at, deleted_at, trace_id, model_provider_type, tool_call_id_template, project_id
 `
 
 type UpdateFlowsProviderNameByOldNameParams struct {
@@ -743,6 +792,7 @@ func (q *Queries) UpdateFlowsProviderNameByOldName(ctx context.Context, arg Upda
 			&i.TraceID,
 			&i.ModelProviderType,
 			&i.ToolCallIDTemplate,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"pentagi/pkg/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHybridRRFK          = 60
+	defaultHybridCandidateMult = 4
+)
+
+// HybridSearchConfig controls how vector similarity and full-text rankings of
+// knowledge documents are fused with reciprocal rank fusion (RRF).
+//
+// Each ranking contributes weight/(k+rank) to a document's fused score; the
+// text ranking is weighted by TextWeight(docType) and the vector ranking by
+// the remainder, so 0 disables lexical matches and 1 ignores embeddings.
+// FeedbackWeight and PopularityWeight scale scores by quality signals of
+// guides, answers and code samples, see QualityFactor.
+type HybridSearchConfig struct {
+	Enabled          bool
+	K                int
+	TextWeight       float64
+	TextWeights      map[string]float64
+	FeedbackWeight   float64
+	PopularityWeight float64
+}
+
+// NewHybridSearchConfig builds the fusion settings from KNOWLEDGE_HYBRID_*
+// variables. Per doc type weights are given as "doc_type:weight" pairs
+// separated by commas; malformed pairs are skipped with a warning.
+func NewHybridSearchConfig(cfg *config.Config) HybridSearchConfig {
+	hcfg := HybridSearchConfig{
+		Enabled:     cfg.KnowledgeHybridSearch,
+		K:           cfg.KnowledgeHybridRRFK,
+		TextWeight:  clampWeight(cfg.KnowledgeHybridTextWeight),
+		TextWeights: make(map[string]float64),
+
+		FeedbackWeight:   clampWeight(cfg.KnowledgeFeedbackWeight),
+		PopularityWeight: clampWeight(cfg.KnowledgePopularityWeight),
+	}
+	if hcfg.K <= 0 {
+		hcfg.K = defaultHybridRRFK
+	}
+
+	for _, pair := range strings.Split(cfg.KnowledgeHybridTextWeights, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		docType, value, ok := strings.Cut(pair, ":")
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || err != nil || strings.TrimSpace(docType) == "" {
+			logrus.WithField("pair", pair).Warn("skipping invalid KNOWLEDGE_HYBRID_TEXT_WEIGHTS entry")
+			continue
+		}
+		hcfg.TextWeights[strings.TrimSpace(docType)] = clampWeight(weight)
+	}
+
+	return hcfg
+}
+
+// TextWeightFor returns the full-text ranking weight for a doc type.
+func (c HybridSearchConfig) TextWeightFor(docType string) float64 {
+	if weight, ok := c.TextWeights[docType]; ok {
+		return weight
+	}
+	return c.TextWeight
+}
+
+// Candidates returns how many rows each ranking should fetch so that fusion
+// can surface documents ranked below limit by one of the retrievers.
+func (c HybridSearchConfig) Candidates(limit int) int {
+	return max(limit*defaultHybridCandidateMult, limit)
+}
+
+// FusedRank is a document key with its fused score normalized to [0, 1] and
+// its 1-based ranks in the vector and text rankings (0 when absent).
+type FusedRank struct {
+	Key        string
+	Score      float64
+	VectorRank int
+	TextRank   int
+}
+
+// FuseRankings merges two rankings of document keys (best first) with
+// weighted reciprocal rank fusion. textWeight returns the text ranking weight
+// for a key, letting each document use the weight of its own doc type. Scores
+// are divided by the best achievable score, so a document ranked first by
+// both retrievers scores 1.
+func FuseRankings(vector, text []string, k int, textWeight func(key string) float64) []FusedRank {
+	if k <= 0 {
+		k = defaultHybridRRFK
+	}
+
+	fused := make(map[string]*FusedRank, len(vector)+len(text))
+	order := make([]string, 0, len(vector)+len(text))
+	get := func(key string) *FusedRank {
+		if rank, ok := fused[key]; ok {
+			return rank
+		}
+		rank := &FusedRank{Key: key}
+		fused[key] = rank
+		order = append(order, key)
+		return rank
+	}
+
+	for idx, key := range vector {
+		if rank := get(key); rank.VectorRank == 0 {
+			rank.VectorRank = idx + 1
+		}
+	}
+	for idx, key := range text {
+		if rank := get(key); rank.TextRank == 0 {
+			rank.TextRank = idx + 1
+		}
+	}
+
+	norm := float64(k + 1)
+	result := make([]FusedRank, 0, len(order))
+	for _, key := range order {
+		rank := fused[key]
+		weight := clampWeight(textWeight(key))
+		if rank.VectorRank > 0 {
+			rank.Score += (1 - weight) * norm / float64(k+rank.VectorRank)
+		}
+		if rank.TextRank > 0 {
+			rank.Score += weight * norm / float64(k+rank.TextRank)
+		}
+		result = append(result, *rank)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	return result
+}
+
+func clampWeight(weight float64) float64 {
+	return min(max(weight, 0), 1)
+}
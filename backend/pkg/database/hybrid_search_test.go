@@ -0,0 +1,105 @@
+package database
+
+import (
+	"math"
+	"testing"
+
+	"pentagi/pkg/config"
+)
+
+func TestFuseRankings(t *testing.T) {
+	const k = 60
+	constant := func(weight float64) func(string) float64 {
+		return func(string) float64 { return weight }
+	}
+
+	t.Run("document in both rankings wins", func(t *testing.T) {
+		fused := FuseRankings([]string{"a", "b", "c"}, []string{"c", "d"}, k, constant(0.5))
+		if fused[0].Key != "c" {
+			t.Fatalf("expected c first, got %+v", fused)
+		}
+		if fused[0].VectorRank != 3 || fused[0].TextRank != 1 {
+			t.Errorf("unexpected ranks: %+v", fused[0])
+		}
+		if len(fused) != 4 {
+			t.Errorf("expected 4 fused documents, got %d", len(fused))
+		}
+	})
+
+	t.Run("top of both rankings scores one", func(t *testing.T) {
+		fused := FuseRankings([]string{"a"}, []string{"a"}, k, constant(0.3))
+		if math.Abs(fused[0].Score-1) > 1e-9 {
+			t.Errorf("expected score 1, got %f", fused[0].Score)
+		}
+	})
+
+	t.Run("weights select the ranking", func(t *testing.T) {
+		vector, text := []string{"v"}, []string{"t"}
+
+		if fused := FuseRankings(vector, text, k, constant(0)); fused[0].Key != "v" || fused[1].Score != 0 {
+			t.Errorf("zero text weight must ignore text matches, got %+v", fused)
+		}
+		if fused := FuseRankings(vector, text, k, constant(1)); fused[0].Key != "t" || fused[1].Score != 0 {
+			t.Errorf("full text weight must ignore vector matches, got %+v", fused)
+		}
+		if fused := FuseRankings(vector, text, k, constant(0.8)); fused[0].Key != "t" {
+			t.Errorf("higher text weight must favour text matches, got %+v", fused)
+		}
+	})
+
+	t.Run("per key weights", func(t *testing.T) {
+		weights := map[string]float64{"code": 0.9, "guide": 0.1}
+		fused := FuseRankings([]string{"guide"}, []string{"code"}, k, func(key string) float64 {
+			return weights[key]
+		})
+		if fused[0].Key != "guide" || math.Abs(fused[0].Score-0.9) > 1e-9 || math.Abs(fused[1].Score-0.9) > 1e-9 {
+			t.Errorf("each key must use its own weight, got %+v", fused)
+		}
+	})
+
+	t.Run("duplicates keep the best rank", func(t *testing.T) {
+		fused := FuseRankings([]string{"a", "a"}, nil, k, constant(0))
+		if len(fused) != 1 || fused[0].VectorRank != 1 {
+			t.Errorf("unexpected fusion of duplicates: %+v", fused)
+		}
+	})
+
+	t.Run("invalid k falls back to default", func(t *testing.T) {
+		fused := FuseRankings([]string{"a", "b"}, nil, 0, constant(0))
+		want := float64(defaultHybridRRFK+1) / float64(defaultHybridRRFK+2)
+		if math.Abs(fused[1].Score-want) > 1e-9 {
+			t.Errorf("expected %f, got %f", want, fused[1].Score)
+		}
+	})
+}
+
+func TestNewHybridSearchConfig(t *testing.T) {
+	hcfg := NewHybridSearchConfig(&config.Config{
+		KnowledgeHybridSearch:      true,
+		KnowledgeHybridRRFK:        -1,
+		KnowledgeHybridTextWeight:  1.5,
+		KnowledgeHybridTextWeights: " code:0.7, guide:-1,broken,answer:x,:0.2",
+	})
+
+	if !hcfg.Enabled {
+		t.Error("expected hybrid search to be enabled")
+	}
+	if hcfg.K != defaultHybridRRFK {
+		t.Errorf("expected default k, got %d", hcfg.K)
+	}
+	if hcfg.TextWeightFor("memory") != 1 {
+		t.Errorf("default weight must be clamped, got %f", hcfg.TextWeightFor("memory"))
+	}
+	if hcfg.TextWeightFor("code") != 0.7 {
+		t.Errorf("expected code weight 0.7, got %f", hcfg.TextWeightFor("code"))
+	}
+	if hcfg.TextWeightFor("guide") != 0 {
+		t.Errorf("negative weight must be clamped, got %f", hcfg.TextWeightFor("guide"))
+	}
+	if len(hcfg.TextWeights) != 2 {
+		t.Errorf("invalid entries must be skipped, got %v", hcfg.TextWeights)
+	}
+	if hcfg.Candidates(3) != 12 {
+		t.Errorf("expected 12 candidates, got %d", hcfg.Candidates(3))
+	}
+}
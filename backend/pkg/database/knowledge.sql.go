@@ -60,6 +60,141 @@ func (q *Queries) DeleteUserKnowledgeDocument(ctx context.Context, arg DeleteUse
 	return err
 }
 
+const existsUserKnowledgeDocumentHash = `-- name: ExistsUserKnowledgeDocumentHash :one
+SELECT EXISTS (
+  SELECT 1
+  FROM langchain_pg_embedding e
+  INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+  WHERE c.name = 'langchain'
+    AND md5(COALESCE(e.document, '')) = $1::text
+    AND (e.cmetadata ->> 'user_id') = $2::text
+) AS exists
+`
+
+type ExistsUserKnowledgeDocumentHashParams struct {
+	Hash   string `json:"hash"`
+	UserID string `json:"user_id"`
+}
+
+// Reports whether the user already owns a document with exactly this content.
+// hash    md5 hex digest of the stored (trimmed) document text
+// user_id owner filter as a decimal text string (e.g. "42")
+func (q *Queries) ExistsUserKnowledgeDocumentHash(ctx context.Context, arg ExistsUserKnowledgeDocumentHashParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, existsUserKnowledgeDocumentHash, arg.Hash, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const exportKnowledgeDocuments = `-- name: ExportKnowledgeDocuments :many
+SELECT
+  e.uuid::text                              AS id,
+  COALESCE(e.document, '')                  AS document,
+  COALESCE(e.cmetadata::text, '{}')         AS cmetadata,
+  (CASE WHEN $1::boolean
+    THEN COALESCE(e.embedding::text, '')
+    ELSE ''
+  END)::text                                AS embedding
+FROM langchain_pg_embedding e
+INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+WHERE c.name = 'langchain'
+  AND COALESCE(e.cmetadata ->> 'doc_type', '') NOT IN ('memory')
+  AND ($2::text = '' OR (e.cmetadata ->> 'user_id') = $2::text)
+ORDER BY e.uuid
+`
+
+type ExportKnowledgeDocumentsParams struct {
+	WithEmbeddings bool   `json:"with_embeddings"`
+	UserID         string `json:"user_id"`
+}
+
+type ExportKnowledgeDocumentsRow struct {
+	ID        string         `json:"id"`
+	Document  string         `json:"document"`
+	Cmetadata sql.NullString `json:"cmetadata"`
+	Embedding string         `json:"embedding"`
+}
+
+// List non-memory knowledge documents for export to a portable archive.
+// user_id         owner filter as a decimal text string; empty for all users
+// with_embeddings return the embedding vector as text ('[f1,f2,...]'), empty otherwise
+func (q *Queries) ExportKnowledgeDocuments(ctx context.Context, arg ExportKnowledgeDocumentsParams) ([]ExportKnowledgeDocumentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, exportKnowledgeDocuments, arg.WithEmbeddings, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportKnowledgeDocumentsRow
+	for rows.Next() {
+		var i ExportKnowledgeDocumentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Document,
+			&i.Cmetadata,
+			&i.Embedding,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findUserKnowledgeDocumentConflict = `-- name: FindUserKnowledgeDocumentConflict :one
+SELECT
+  e.uuid::text                              AS id
+FROM langchain_pg_embedding e
+INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+WHERE c.name = 'langchain'
+  AND (e.cmetadata ->> 'user_id') = $1::text
+  AND (
+    e.uuid::text = $2::text
+    OR (
+      (e.cmetadata ->> 'origin_instance') = $3::text
+      AND (e.cmetadata ->> 'origin_id') = $4::text
+    )
+    OR md5(COALESCE(e.document, '')) = $5::text
+  )
+ORDER BY md5(COALESCE(e.document, '')) = $5::text
+LIMIT 1
+`
+
+type FindUserKnowledgeDocumentConflictParams struct {
+	UserID         string `json:"user_id"`
+	LocalID        string `json:"local_id"`
+	OriginInstance string `json:"origin_instance"`
+	OriginID       string `json:"origin_id"`
+	Hash           string `json:"hash"`
+}
+
+// Find a document of the user that an archived document would collide with:
+// the same document of this instance, a copy imported earlier from the same
+// origin, or a document with identical content. Provenance matches win over
+// content matches. Returns sql.ErrNoRows when there is no conflict.
+// user_id         owner filter as a decimal text string (e.g. "42")
+// local_id        UUID of the archived document if this instance exported it, empty otherwise
+// origin_instance installation ID the archived document originates from
+// origin_id       UUID of the archived document on its origin instance
+// hash            md5 hex digest of the archived document text
+func (q *Queries) FindUserKnowledgeDocumentConflict(ctx context.Context, arg FindUserKnowledgeDocumentConflictParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, findUserKnowledgeDocumentConflict,
+		arg.UserID,
+		arg.LocalID,
+		arg.OriginInstance,
+		arg.OriginID,
+		arg.Hash,
+	)
+	var id string
+	err := row.Scan(&id)
+	return id, err
+}
+
 const getKnowledgeDocument = `-- name: GetKnowledgeDocument :one
 SELECT
   e.uuid::text                              AS id,
@@ -236,6 +371,49 @@ func (q *Queries) ListFlowKnowledgeDocuments(ctx context.Context, flowID sql.Nul
 	return items, nil
 }
 
+const listProjectKnowledgeDocuments = `-- name: ListProjectKnowledgeDocuments :many
+SELECT
+  e.uuid::text                              AS id,
+  COALESCE(e.document, '')                  AS document,
+  COALESCE(e.cmetadata::text, '{}')         AS cmetadata
+FROM langchain_pg_embedding e
+INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+WHERE c.name = 'langchain'
+  AND COALESCE(e.cmetadata ->> 'doc_type', '') NOT IN ('memory')
+  AND (e.cmetadata ->> 'project_id') = $1
+ORDER BY e.uuid
+`
+
+type ListProjectKnowledgeDocumentsRow struct {
+	ID        string         `json:"id"`
+	Document  string         `json:"document"`
+	Cmetadata sql.NullString `json:"cmetadata"`
+}
+
+// List all non-memory knowledge documents shared with a project.
+func (q *Queries) ListProjectKnowledgeDocuments(ctx context.Context, projectID sql.NullString) ([]ListProjectKnowledgeDocumentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectKnowledgeDocuments, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProjectKnowledgeDocumentsRow
+	for rows.Next() {
+		var i ListProjectKnowledgeDocumentsRow
+		if err := rows.Scan(&i.ID, &i.Document, &i.Cmetadata); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUserKnowledgeDocuments = `-- name: ListUserKnowledgeDocuments :many
 SELECT
   e.uuid::text                              AS id,
@@ -289,6 +467,9 @@ FROM langchain_pg_embedding e
 INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
 WHERE c.name = 'langchain'
   AND COALESCE(e.cmetadata ->> 'doc_type', '') NOT IN ('memory')
+  AND COALESCE(e.cmetadata ->> 'archived', '') <> 'true'
+  AND COALESCE((e.cmetadata ->> 'expires_at')::timestamptz > CURRENT_TIMESTAMP, true)
+  AND vector_dims(e.embedding) = vector_dims($1::vector)
   AND (e.embedding <=> $1::vector)::float8 < $2::float8
 ORDER BY e.embedding <=> $1::vector
 LIMIT $3::int
@@ -308,6 +489,7 @@ type SearchKnowledgeDocumentsRow struct {
 }
 
 // Vector similarity search over all knowledge documents (admin view, no user filter).
+// Archived and expired documents are skipped.
 // Returns rows ordered by cosine similarity descending (highest score first).
 // embedding    query vector as a PostgreSQL vector literal, e.g. '[0.1,0.2,...]'
 // max_distance cosine-distance upper bound (exclusive); equals (1 - score_threshold),
@@ -343,6 +525,97 @@ func (q *Queries) SearchKnowledgeDocuments(ctx context.Context, arg SearchKnowle
 	return items, nil
 }
 
+const searchKnowledgeDocumentsText = `-- name: SearchKnowledgeDocumentsText :many
+WITH q AS (
+  SELECT replace(plainto_tsquery('english', $1::text)::text, '&', '|')::tsquery AS tsq
+)
+SELECT
+  e.uuid::text                                                         AS id,
+  COALESCE(e.document, '')                                             AS document,
+  COALESCE(e.cmetadata::text, '{}')                                   AS cmetadata,
+  (ts_rank_cd(to_tsvector('english', COALESCE(e.document, '')), q.tsq, 32)
+    + word_similarity($1::text, COALESCE(e.document, '')))::float8 AS score
+FROM langchain_pg_embedding e
+INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+CROSS JOIN q
+WHERE c.name = 'langchain'
+  AND ($2::boolean OR COALESCE(e.cmetadata ->> 'doc_type', '') NOT IN ('memory'))
+  AND ($3::text = '' OR (e.cmetadata ->> 'user_id') = $3::text)
+  AND COALESCE(e.cmetadata ->> 'archived', '') <> 'true'
+  AND COALESCE((e.cmetadata ->> 'expires_at')::timestamptz > CURRENT_TIMESTAMP, true)
+  AND NOT EXISTS (
+    SELECT 1 FROM json_each_text($4::json) f
+    WHERE (e.cmetadata ->> f.key) IS DISTINCT FROM f.value
+  )
+  AND (
+    to_tsvector('english', COALESCE(e.document, '')) @@ q.tsq
+    OR $1::text <% COALESCE(e.document, '')
+  )
+ORDER BY score DESC
+LIMIT $5::int
+`
+
+type SearchKnowledgeDocumentsTextParams struct {
+	Query      string          `json:"query"`
+	WithMemory bool            `json:"with_memory"`
+	UserID     string          `json:"user_id"`
+	Filters    json.RawMessage `json:"filters"`
+	Lim        int32           `json:"lim"`
+}
+
+type SearchKnowledgeDocumentsTextRow struct {
+	ID        string         `json:"id"`
+	Document  string         `json:"document"`
+	Cmetadata sql.NullString `json:"cmetadata"`
+	Score     float64        `json:"score"`
+}
+
+// Full-text and trigram search over knowledge documents, the lexical half of
+// hybrid retrieval. Catches exact tokens (CVE IDs, tool flags, hostnames)
+// which embeddings tend to blur. Returns rows ordered by lexical score descending.
+// Archived and expired documents are skipped.
+// query       raw search text; its terms are OR-ed into a tsquery
+// filters     JSON object of cmetadata key/value pairs compared as text,
+//
+//	e.g. '{"doc_type":"guide","guide_type":"pentest"}'; '{}' for none
+//
+// user_id     owner filter as a decimal text string; empty for all users
+// with_memory include memory documents (excluded from the knowledge UI)
+// lim         maximum number of rows to return
+func (q *Queries) SearchKnowledgeDocumentsText(ctx context.Context, arg SearchKnowledgeDocumentsTextParams) ([]SearchKnowledgeDocumentsTextRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchKnowledgeDocumentsText,
+		arg.Query,
+		arg.WithMemory,
+		arg.UserID,
+		arg.Filters,
+		arg.Lim,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchKnowledgeDocumentsTextRow
+	for rows.Next() {
+		var i SearchKnowledgeDocumentsTextRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Document,
+			&i.Cmetadata,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchUserKnowledgeDocuments = `-- name: SearchUserKnowledgeDocuments :many
 SELECT
   e.uuid::text                                                         AS id,
@@ -354,6 +627,9 @@ INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
 WHERE c.name = 'langchain'
   AND COALESCE(e.cmetadata ->> 'doc_type', '') NOT IN ('memory')
   AND (e.cmetadata ->> 'user_id') = $2
+  AND COALESCE(e.cmetadata ->> 'archived', '') <> 'true'
+  AND COALESCE((e.cmetadata ->> 'expires_at')::timestamptz > CURRENT_TIMESTAMP, true)
+  AND vector_dims(e.embedding) = vector_dims($1::vector)
   AND (e.embedding <=> $1::vector)::float8 < $3::float8
 ORDER BY e.embedding <=> $1::vector
 LIMIT $4::int
@@ -374,6 +650,7 @@ type SearchUserKnowledgeDocumentsRow struct {
 }
 
 // Vector similarity search scoped to a specific user (by cmetadata user_id).
+// Archived and expired documents are skipped.
 // Returns rows ordered by cosine similarity descending (highest score first).
 // embedding    query vector as a PostgreSQL vector literal, e.g. '[0.1,0.2,...]'
 // max_distance cosine-distance upper bound (exclusive); equals (1 - score_threshold)
@@ -0,0 +1,696 @@
+package knowledge
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/database/knowledge/ingest"
+	"pentagi/pkg/graph/model"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Knowledge archives are zip files holding manifest.json and documents.jsonl
+// (one archiveDocument per line). The format is versioned: importers accept
+// archives up to ArchiveVersion and reject newer ones.
+const (
+	ArchiveFormat  = "pentagi-knowledge"
+	ArchiveVersion = 1
+
+	archiveManifestName  = "manifest.json"
+	archiveDocumentsName = "documents.jsonl"
+)
+
+// ConflictPolicy decides what happens when an archived document collides with
+// a document the importing user already owns: the same document, an earlier
+// import of it, or a document with identical content.
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictDuplicate ConflictPolicy = "duplicate"
+)
+
+// Valid reports whether the policy is known.
+func (p ConflictPolicy) Valid() bool {
+	return p == ConflictSkip || p == ConflictOverwrite || p == ConflictDuplicate
+}
+
+// Instance identifies the PentAGI installation exporting or importing an archive.
+type Instance struct {
+	InstallationID string
+	TenantID       string
+}
+
+// ArchiveEmbedding describes the vector space of embeddings stored in an archive.
+type ArchiveEmbedding struct {
+	Provider   string `json:"provider"`
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions"`
+}
+
+// String returns the "provider/model" form recorded as document provenance.
+func (e *ArchiveEmbedding) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.Provider + "/" + e.Model
+}
+
+// ArchiveManifest is stored as manifest.json at the root of an archive.
+type ArchiveManifest struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	InstanceID string    `json:"instance_id"`
+	TenantID   string    `json:"tenant_id,omitempty"`
+	Documents  int       `json:"documents"`
+	// Embedding is nil when the archive was exported without embeddings.
+	Embedding *ArchiveEmbedding `json:"embedding,omitempty"`
+}
+
+// archiveOrigin is the instance a document was first created on; it is kept
+// when a previously imported document is exported again.
+type archiveOrigin struct {
+	InstanceID string    `json:"instance_id"`
+	TenantID   string    `json:"tenant_id,omitempty"`
+	DocumentID string    `json:"document_id"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// archiveDocument is one line of documents.jsonl. Owner and flow references
+// are instance specific and never exported.
+type archiveDocument struct {
+	Content     string        `json:"content"`
+	DocType     string        `json:"doc_type"`
+	Question    string        `json:"question,omitempty"`
+	Description string        `json:"description,omitempty"`
+	GuideType   string        `json:"guide_type,omitempty"`
+	AnswerType  string        `json:"answer_type,omitempty"`
+	CodeLang    string        `json:"code_lang,omitempty"`
+	PartSize    int           `json:"part_size,omitempty"`
+	TotalSize   int           `json:"total_size,omitempty"`
+	Manual      bool          `json:"manual,omitempty"`
+	Origin      archiveOrigin `json:"origin"`
+	Embedding   []float32     `json:"embedding,omitempty"`
+}
+
+// ErrInvalidArchive wraps every archive parsing and validation error.
+var ErrInvalidArchive = errors.New("knowledge: invalid archive")
+
+// ExportRequest selects the documents of an export.
+type ExportRequest struct {
+	Filter         *model.KnowledgeFilter
+	WithEmbeddings bool
+	Instance       Instance
+}
+
+// ArchiveImportRequest describes an uploaded knowledge archive. MaxBytes
+// bounds the uncompressed size of the archive, guarding against zip bombs
+// (0 = no limit).
+type ArchiveImportRequest struct {
+	Source   string
+	Data     []byte
+	Conflict ConflictPolicy
+	Instance Instance
+	MaxBytes int64
+}
+
+// archiveImportOptions is recorded as the options of archive import jobs.
+type archiveImportOptions struct {
+	Format         string         `json:"format"`
+	Version        int            `json:"version"`
+	Conflict       ConflictPolicy `json:"conflict"`
+	OriginInstance string         `json:"origin_instance"`
+	OriginTenant   string         `json:"origin_tenant,omitempty"`
+	Embedding      string         `json:"embedding,omitempty"`
+	Reembed        bool           `json:"reembed"`
+}
+
+// ---- Export -----------------------------------------------------------------
+
+func (ks *knowledgeStore) ExportDocuments(ctx context.Context, w io.Writer, req ExportRequest) (*ArchiveManifest, error) {
+	return ks.doExport(ctx, 0, w, req)
+}
+
+func (ks *knowledgeStore) ExportUserDocuments(ctx context.Context, userID int64, w io.Writer, req ExportRequest) (*ArchiveManifest, error) {
+	return ks.doExport(ctx, userID, w, req)
+}
+
+// doExport writes the documents passing the filter as a zip archive to w.
+// Documents stored with vectors of another dimension than the first exported
+// one (e.g. written before a model change) are exported without embeddings.
+func (ks *knowledgeStore) doExport(ctx context.Context, userID int64, w io.Writer, req ExportRequest) (*ArchiveManifest, error) {
+	params := database.ExportKnowledgeDocumentsParams{WithEmbeddings: req.WithEmbeddings}
+	if userID > 0 {
+		params.UserID = strconv.FormatInt(userID, 10)
+	}
+	rows, err := ks.db.ExportKnowledgeDocuments(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: export documents: %w", err)
+	}
+
+	now := time.Now().UTC()
+	manifest := &ArchiveManifest{
+		Format:     ArchiveFormat,
+		Version:    ArchiveVersion,
+		ExportedAt: now,
+		InstanceID: req.Instance.InstallationID,
+		TenantID:   req.Instance.TenantID,
+	}
+
+	zw := zip.NewWriter(w)
+	docsWriter, err := zw.Create(archiveDocumentsName)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: write archive: %w", err)
+	}
+	enc := json.NewEncoder(docsWriter)
+
+	for _, row := range rows {
+		meta := parseMeta(nullStr(row.Cmetadata))
+		if !passesSearchFilter(metaToModelDoc(row.ID, "", meta), req.Filter) {
+			continue
+		}
+
+		doc := archiveDocument{
+			Content:     row.Document,
+			DocType:     meta.DocType,
+			Question:    meta.Question,
+			Description: meta.Description,
+			GuideType:   meta.GuideType,
+			AnswerType:  meta.AnswerType,
+			CodeLang:    meta.CodeLang,
+			PartSize:    meta.PartSize,
+			TotalSize:   meta.TotalSize,
+			Manual:      meta.Manual,
+			Origin: archiveOrigin{
+				InstanceID: req.Instance.InstallationID,
+				TenantID:   req.Instance.TenantID,
+				DocumentID: row.ID,
+				ExportedAt: now,
+			},
+		}
+		if meta.OriginInstance != "" && meta.OriginID != "" {
+			doc.Origin = archiveOrigin{
+				InstanceID: meta.OriginInstance,
+				TenantID:   meta.OriginTenant,
+				DocumentID: meta.OriginID,
+				ExportedAt: now,
+			}
+			if meta.ExportedAt != nil {
+				doc.Origin.ExportedAt = *meta.ExportedAt
+			}
+		}
+
+		if req.WithEmbeddings && row.Embedding != "" {
+			vec, err := parseVector(row.Embedding)
+			if err != nil {
+				return nil, fmt.Errorf("knowledge: export document %s: %w", row.ID, err)
+			}
+			if manifest.Embedding == nil {
+				manifest.Embedding = &ArchiveEmbedding{Dimensions: len(vec)}
+				if ks.embedder != nil {
+					manifest.Embedding.Provider = ks.embedder.Provider()
+					manifest.Embedding.Model = ks.embedder.Model()
+				}
+			}
+			if len(vec) == manifest.Embedding.Dimensions {
+				doc.Embedding = vec
+			}
+		}
+
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("knowledge: write archive: %w", err)
+		}
+		manifest.Documents++
+	}
+
+	manifestWriter, err := zw.Create(archiveManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: write archive: %w", err)
+	}
+	manifestEnc := json.NewEncoder(manifestWriter)
+	manifestEnc.SetIndent("", "  ")
+	if err := manifestEnc.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("knowledge: write archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("knowledge: write archive: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// parseVector parses the PostgreSQL vector literal '[f1,f2,...]'.
+func parseVector(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("invalid vector literal")
+	}
+	s = strings.TrimSpace(s[1 : len(s)-1])
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	vec := make([]float32, len(parts))
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector component %d: %w", i, err)
+		}
+		vec[i] = float32(f)
+	}
+	return vec, nil
+}
+
+// ---- Import -----------------------------------------------------------------
+
+// ReadArchiveManifest validates an archive and returns its manifest; maxBytes
+// bounds the uncompressed size as in ArchiveImportRequest.
+func ReadArchiveManifest(data []byte, maxBytes int64) (*ArchiveManifest, error) {
+	manifest, _, err := readArchive(data, maxBytes)
+	return manifest, err
+}
+
+// readArchive parses and validates the manifest and all documents. The sizes
+// declared in the zip headers are checked up front, and the decompressed
+// bytes are counted as well since the headers can lie.
+func readArchive(data []byte, maxBytes int64) (*ArchiveManifest, []archiveDocument, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	var manifestFile, docsFile *zip.File
+	for _, f := range zr.File {
+		switch f.Name {
+		case archiveManifestName:
+			manifestFile = f
+		case archiveDocumentsName:
+			docsFile = f
+		}
+	}
+	if manifestFile == nil || docsFile == nil {
+		return nil, nil, fmt.Errorf("%w: %s and %s are required", ErrInvalidArchive, archiveManifestName, archiveDocumentsName)
+	}
+
+	remaining := maxBytes
+	if maxBytes <= 0 {
+		remaining = math.MaxInt64 - 1
+	}
+	if manifestFile.UncompressedSize64 > uint64(remaining) ||
+		docsFile.UncompressedSize64 > uint64(remaining)-manifestFile.UncompressedSize64 {
+		return nil, nil, archiveTooLarge(maxBytes)
+	}
+
+	var manifest ArchiveManifest
+	read, err := readArchiveJSON(manifestFile, remaining, func(dec *json.Decoder) error {
+		return dec.Decode(&manifest)
+	})
+	if errors.Is(err, errArchiveTooLarge) {
+		return nil, nil, archiveTooLarge(maxBytes)
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("%w: manifest: %v", ErrInvalidArchive, err)
+	}
+	remaining -= read
+	if manifest.Format != ArchiveFormat {
+		return nil, nil, fmt.Errorf("%w: unknown format %q", ErrInvalidArchive, manifest.Format)
+	}
+	if manifest.Version < 1 || manifest.Version > ArchiveVersion {
+		return nil, nil, fmt.Errorf("%w: unsupported version %d (supported up to %d)",
+			ErrInvalidArchive, manifest.Version, ArchiveVersion)
+	}
+	if manifest.InstanceID == "" {
+		return nil, nil, fmt.Errorf("%w: manifest has no instance_id", ErrInvalidArchive)
+	}
+
+	var docs []archiveDocument
+	_, err = readArchiveJSON(docsFile, remaining, func(dec *json.Decoder) error {
+		for line := 1; dec.More(); line++ {
+			if len(docs) == manifest.Documents {
+				return fmt.Errorf("manifest lists %d documents, found more", manifest.Documents)
+			}
+
+			var doc archiveDocument
+			if err := dec.Decode(&doc); err != nil {
+				return fmt.Errorf("document %d: %w", line, err)
+			}
+			if err := doc.validate(); err != nil {
+				return fmt.Errorf("document %d: %w", line, err)
+			}
+			docs = append(docs, doc)
+		}
+		return nil
+	})
+	if errors.Is(err, errArchiveTooLarge) {
+		return nil, nil, archiveTooLarge(maxBytes)
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if len(docs) != manifest.Documents {
+		return nil, nil, fmt.Errorf("%w: manifest lists %d documents, found %d",
+			ErrInvalidArchive, manifest.Documents, len(docs))
+	}
+
+	return &manifest, docs, nil
+}
+
+var errArchiveTooLarge = errors.New("archive is too large")
+
+func archiveTooLarge(maxBytes int64) error {
+	return fmt.Errorf("%w: uncompressed size exceeds the import limit of %d bytes", ErrInvalidArchive, maxBytes)
+}
+
+// readArchiveJSON decodes the file reading at most limit bytes of it and
+// returns the number of bytes read
+func readArchiveJSON(f *zip.File, limit int64, decode func(dec *json.Decoder) error) (int64, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	// read one byte past the limit so an overflow is detected without
+	// trusting the size declared in the archive header
+	lr := &io.LimitedReader{R: rc, N: limit + 1}
+	err = decode(json.NewDecoder(lr))
+	read := limit + 1 - lr.N
+	if read > limit {
+		return read, errArchiveTooLarge
+	}
+
+	return read, err
+}
+
+func (d *archiveDocument) validate() error {
+	if strings.TrimSpace(d.Content) == "" {
+		return errors.New("content is empty")
+	}
+	if !slices.Contains([]string{"answer", "guide", "code"}, d.DocType) {
+		return fmt.Errorf("unknown doc type %q", d.DocType)
+	}
+	if d.Origin.InstanceID == "" || d.Origin.DocumentID == "" {
+		return errors.New("origin is incomplete")
+	}
+	return nil
+}
+
+// StartArchiveImport validates the archive, creates the job and imports the
+// documents in the background; progress is published as
+// knowledgeImportJobUpdated events.
+func (ks *knowledgeStore) StartArchiveImport(
+	ctx context.Context,
+	userID int64,
+	req ArchiveImportRequest,
+) (*model.KnowledgeImportJob, error) {
+	job, manifest, docs, err := ks.createArchiveImportJob(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := importJobToModel(job)
+	ks.newKnp(userID).KnowledgeImportJobUpdated(ctx, result)
+
+	go func() {
+		// the request context ends with the upload, the import must outlive it
+		if _, err := ks.runArchiveImport(context.Background(), job, req, manifest, docs, nil); err != nil {
+			logrus.WithError(err).WithField("job_id", job.ID).Error("knowledge archive import failed")
+		}
+	}()
+
+	return result, nil
+}
+
+// RunArchiveImport imports an archive synchronously, calling progress after
+// each batch; it is used by command line tools.
+func (ks *knowledgeStore) RunArchiveImport(
+	ctx context.Context,
+	userID int64,
+	req ArchiveImportRequest,
+	progress ImportProgress,
+) (*model.KnowledgeImportJob, error) {
+	job, manifest, docs, err := ks.createArchiveImportJob(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	return ks.runArchiveImport(ctx, job, req, manifest, docs, progress)
+}
+
+func (ks *knowledgeStore) createArchiveImportJob(
+	ctx context.Context,
+	userID int64,
+	req ArchiveImportRequest,
+) (database.KnowledgeImportJob, *ArchiveManifest, []archiveDocument, error) {
+	if err := ks.requireEmbedder(); err != nil {
+		return database.KnowledgeImportJob{}, nil, nil, err
+	}
+	if req.Conflict == "" {
+		req.Conflict = ConflictSkip
+	}
+	if !req.Conflict.Valid() {
+		return database.KnowledgeImportJob{}, nil, nil, fmt.Errorf("knowledge: invalid import options: unknown conflict policy %q", req.Conflict)
+	}
+
+	manifest, docs, err := readArchive(req.Data, req.MaxBytes)
+	if err != nil {
+		return database.KnowledgeImportJob{}, nil, nil, err
+	}
+
+	optsJSON, err := json.Marshal(archiveImportOptions{
+		Format:         manifest.Format,
+		Version:        manifest.Version,
+		Conflict:       req.Conflict,
+		OriginInstance: manifest.InstanceID,
+		OriginTenant:   manifest.TenantID,
+		Embedding:      manifest.Embedding.String(),
+		Reembed:        !ks.canReuseEmbeddings(manifest),
+	})
+	if err != nil {
+		return database.KnowledgeImportJob{}, nil, nil, fmt.Errorf("knowledge: marshal import options: %w", err)
+	}
+
+	job, err := ks.db.CreateKnowledgeImportJob(ctx, database.CreateKnowledgeImportJobParams{
+		UserID:     userID,
+		Source:     req.Source,
+		Options:    optsJSON,
+		TotalFiles: 1,
+	})
+	if err != nil {
+		return database.KnowledgeImportJob{}, nil, nil, fmt.Errorf("knowledge: create import job: %w", err)
+	}
+
+	return job, manifest, docs, nil
+}
+
+// canReuseEmbeddings reports whether archived vectors were produced by the
+// local embedding model; otherwise every document is embedded again.
+func (ks *knowledgeStore) canReuseEmbeddings(manifest *ArchiveManifest) bool {
+	e := manifest.Embedding
+	return e != nil && e.Provider != "" && e.Dimensions > 0 &&
+		e.Provider == ks.embedder.Provider() && e.Model == ks.embedder.Model()
+}
+
+// archiveItem is an archived document that will be inserted, or written over
+// the conflicting document when existingID is set.
+type archiveItem struct {
+	doc        archiveDocument
+	existingID string
+}
+
+// runArchiveImport stores archived documents in batches. Conflicts are
+// resolved by the request policy and counted as duplicates (skip), updates
+// (overwrite) or new documents (duplicate).
+func (ks *knowledgeStore) runArchiveImport(
+	ctx context.Context,
+	job database.KnowledgeImportJob,
+	req ArchiveImportRequest,
+	manifest *ArchiveManifest,
+	docs []archiveDocument,
+	progress ImportProgress,
+) (*model.KnowledgeImportJob, error) {
+	run := ks.newImportRun(job, 0, progress)
+	run.state.TotalChunks = int64(len(docs))
+	if _, err := run.report(ctx); err != nil {
+		return run.fail(ctx, err)
+	}
+
+	conflict := req.Conflict
+	if conflict == "" {
+		conflict = ConflictSkip
+	}
+	reuse := ks.canReuseEmbeddings(manifest)
+	userID := strconv.FormatInt(job.UserID, 10)
+
+	for start := 0; start < len(docs); start += importBatchSize {
+		if err := ctx.Err(); err != nil {
+			return run.fail(ctx, err)
+		}
+
+		batch := docs[start:min(start+importBatchSize, len(docs))]
+		items := make([]archiveItem, 0, len(batch))
+		for _, doc := range batch {
+			if conflict == ConflictDuplicate {
+				items = append(items, archiveItem{doc: doc})
+				continue
+			}
+
+			params := database.FindUserKnowledgeDocumentConflictParams{
+				UserID:         userID,
+				OriginInstance: doc.Origin.InstanceID,
+				OriginID:       doc.Origin.DocumentID,
+				Hash:           ingest.HashContent(doc.Content),
+			}
+			if doc.Origin.InstanceID == req.Instance.InstallationID {
+				params.LocalID = doc.Origin.DocumentID
+			}
+			existingID, err := ks.db.FindUserKnowledgeDocumentConflict(ctx, params)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				items = append(items, archiveItem{doc: doc})
+			case err != nil:
+				return run.fail(ctx, fmt.Errorf("knowledge: check conflicts: %w", err))
+			case conflict == ConflictOverwrite:
+				items = append(items, archiveItem{doc: doc, existingID: existingID})
+			default:
+				run.state.DuplicateDocs++
+			}
+		}
+
+		created, updated, err := ks.importArchiveItems(ctx, job.UserID, manifest, items, reuse)
+		run.state.CreatedDocs += int64(created)
+		run.state.UpdatedDocs += int64(updated)
+		run.state.FailedDocs += int64(len(items) - created - updated)
+		if err != nil {
+			run.state.Error = nsOf(err.Error())
+		}
+		run.state.ProcessedChunks += int64(len(batch))
+
+		if _, err := run.report(ctx); err != nil {
+			return run.fail(ctx, err)
+		}
+	}
+
+	return run.finish(ctx)
+}
+
+// importArchiveItems embeds the items lacking reusable vectors in one request
+// and stores all items, returning the inserted and updated counts and the
+// last error met.
+func (ks *knowledgeStore) importArchiveItems(
+	ctx context.Context,
+	userID int64,
+	manifest *ArchiveManifest,
+	items []archiveItem,
+	reuse bool,
+) (int, int, error) {
+	if len(items) == 0 {
+		return 0, 0, nil
+	}
+
+	vecs := make([][]float32, len(items))
+	var (
+		texts   []string
+		missing []int
+	)
+	for idx, item := range items {
+		if reuse && len(item.doc.Embedding) == manifest.Embedding.Dimensions {
+			vecs[idx] = item.doc.Embedding
+			continue
+		}
+		text := item.doc.Content
+		if len(text) > ks.maxEmbeddingBytes {
+			text = text[:ks.maxEmbeddingBytes]
+		}
+		texts = append(texts, text)
+		missing = append(missing, idx)
+	}
+
+	if len(texts) > 0 {
+		embedded, err := ks.embedder.EmbedDocuments(ctx, texts)
+		if err != nil {
+			return 0, 0, fmt.Errorf("knowledge: compute embeddings: %w", err)
+		}
+		if len(embedded) != len(texts) {
+			return 0, 0, fmt.Errorf("knowledge: embedder returned %d vectors for %d documents", len(embedded), len(texts))
+		}
+		for i, idx := range missing {
+			vecs[idx] = embedded[i]
+		}
+	}
+
+	importedAt := time.Now().UTC()
+	var (
+		created, updated int
+		lastErr          error
+	)
+	for idx, item := range items {
+		doc := item.doc
+		exportedAt := doc.Origin.ExportedAt
+		cmJSON, err := metaToJSON(knowledgeMeta{
+			DocType:        doc.DocType,
+			UserID:         userID,
+			Question:       doc.Question,
+			Description:    doc.Description,
+			GuideType:      doc.GuideType,
+			AnswerType:     doc.AnswerType,
+			CodeLang:       doc.CodeLang,
+			PartSize:       doc.PartSize,
+			TotalSize:      doc.TotalSize,
+			Manual:         doc.Manual,
+			OriginInstance: doc.Origin.InstanceID,
+			OriginTenant:   doc.Origin.TenantID,
+			OriginID:       doc.Origin.DocumentID,
+			OriginModel:    manifest.Embedding.String(),
+			ExportedAt:     &exportedAt,
+			ImportedAt:     &importedAt,
+		})
+		if err != nil {
+			lastErr = fmt.Errorf("knowledge: marshal cmetadata: %w", err)
+			continue
+		}
+
+		if item.existingID != "" {
+			_, err = ks.db.UpdateKnowledgeDocument(ctx, database.UpdateKnowledgeDocumentParams{
+				Uuid:      nsOf(item.existingID),
+				Embedding: formatVector(vecs[idx]),
+				Document:  nsOf(doc.Content),
+				Cmetadata: cmJSON,
+			})
+			if err != nil {
+				lastErr = fmt.Errorf("knowledge: overwrite document %s: %w", item.existingID, err)
+				continue
+			}
+			updated++
+			continue
+		}
+
+		_, err = ks.db.InsertKnowledgeDocument(ctx, database.InsertKnowledgeDocumentParams{
+			Uuid:      uuid.New(),
+			Document:  nsOf(doc.Content),
+			Embedding: formatVector(vecs[idx]),
+			Cmetadata: cmJSON.RawMessage,
+		})
+		if err != nil {
+			lastErr = fmt.Errorf("knowledge: import document %s: %w", doc.Origin.DocumentID, err)
+			continue
+		}
+		created++
+	}
+
+	return created, updated, lastErr
+}
@@ -0,0 +1,438 @@
+package knowledge
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/model"
+)
+
+var (
+	sourceInstance = Instance{InstallationID: "11111111-1111-1111-1111-111111111111", TenantID: "acme"}
+	targetInstance = Instance{InstallationID: "22222222-2222-2222-2222-222222222222"}
+)
+
+// exportFixture exports three documents of user 5 (one of them previously
+// imported from a third instance) and returns the archive bytes.
+func exportFixture(t *testing.T, withEmbeddings bool) ([]byte, *ArchiveManifest) {
+	t.Helper()
+
+	var gotParams database.ExportKnowledgeDocumentsParams
+	db := &mockDB{
+		exportKnowledge: func(_ context.Context, arg database.ExportKnowledgeDocumentsParams) ([]database.ExportKnowledgeDocumentsRow, error) {
+			gotParams = arg
+			return []database.ExportKnowledgeDocumentsRow{
+				{
+					ID:        "doc-guide",
+					Document:  "Install nmap with apt",
+					Cmetadata: nsOf(`{"doc_type":"guide","user_id":5,"flow_id":9,"question":"Install nmap","guide_type":"install"}`),
+					Embedding: "[0.1,0.2]",
+				},
+				{
+					ID:       "doc-answer",
+					Document: "Use sqlmap --batch",
+					Cmetadata: nsOf(`{"doc_type":"answer","user_id":5,"answer_type":"tool","origin_instance":"33333333-3333-3333-3333-333333333333",` +
+						`"origin_id":"first-copy","exported_at":"2026-01-02T03:04:05Z"}`),
+					Embedding: "[0.3,0.4]",
+				},
+				{
+					ID:        "doc-code",
+					Document:  "print(1)",
+					Cmetadata: nsOf(`{"doc_type":"code","user_id":5,"code_lang":"python"}`),
+					Embedding: "[0.5,0.6]",
+				},
+			}, nil
+		},
+	}
+	ks := &knowledgeStore{
+		db:       db,
+		embedder: &mockEmbedder{available: true, provider: "openai", model: "text-embedding-3-small"},
+	}
+
+	var buf bytes.Buffer
+	manifest, err := ks.ExportUserDocuments(t.Context(), 5, &buf, ExportRequest{
+		Filter:         &model.KnowledgeFilter{DocTypes: []model.KnowledgeDocType{model.KnowledgeDocTypeGuide, model.KnowledgeDocTypeAnswer}},
+		WithEmbeddings: withEmbeddings,
+		Instance:       sourceInstance,
+	})
+	if err != nil {
+		t.Fatalf("unexpected export error: %v", err)
+	}
+	if gotParams.UserID != "5" || gotParams.WithEmbeddings != withEmbeddings {
+		t.Errorf("unexpected export params: %+v", gotParams)
+	}
+	return buf.Bytes(), manifest
+}
+
+func TestExportArchive(t *testing.T) {
+	data, manifest := exportFixture(t, true)
+
+	if manifest.Format != ArchiveFormat || manifest.Version != ArchiveVersion || manifest.Documents != 2 {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+	if manifest.InstanceID != sourceInstance.InstallationID || manifest.TenantID != "acme" {
+		t.Errorf("manifest must identify the exporting instance: %+v", manifest)
+	}
+	if manifest.Embedding == nil || manifest.Embedding.String() != "openai/text-embedding-3-small" || manifest.Embedding.Dimensions != 2 {
+		t.Errorf("unexpected embedding info: %+v", manifest.Embedding)
+	}
+
+	read, docs, err := readArchive(data, 0)
+	if err != nil {
+		t.Fatalf("exported archive must be readable: %v", err)
+	}
+	if read.Documents != 2 || len(docs) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(docs))
+	}
+
+	guide := docs[1]
+	if docs[0].Content == "Install nmap with apt" {
+		guide = docs[0]
+	}
+	if guide.Origin.InstanceID != sourceInstance.InstallationID || guide.Origin.DocumentID != "doc-guide" ||
+		guide.GuideType != "install" || len(guide.Embedding) != 2 {
+		t.Errorf("unexpected guide document: %+v", guide)
+	}
+
+	answer := docs[0]
+	if answer.Content != "Use sqlmap --batch" {
+		answer = docs[1]
+	}
+	if answer.Origin.InstanceID != "33333333-3333-3333-3333-333333333333" || answer.Origin.DocumentID != "first-copy" ||
+		answer.Origin.ExportedAt.Year() != 2026 {
+		t.Errorf("re-exported documents must keep their first origin: %+v", answer.Origin)
+	}
+
+	raw := readZipEntry(t, data, archiveDocumentsName)
+	if strings.Contains(raw, "user_id") || strings.Contains(raw, "flow_id") {
+		t.Errorf("instance specific references must not be exported:\n%s", raw)
+	}
+
+	_, noVectors := exportFixture(t, false)
+	if noVectors.Embedding != nil {
+		t.Errorf("archives without embeddings must not describe a model: %+v", noVectors.Embedding)
+	}
+}
+
+func readZipEntry(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(rc); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestRunArchiveImportEmbeddings(t *testing.T) {
+	data, _ := exportFixture(t, true)
+
+	tests := map[string]struct {
+		model       string
+		wantEmbeds  int
+		wantVectors []string
+	}{
+		"same model reuses vectors": {"text-embedding-3-small", 0, []string{"[0.1,0.2]", "[0.3,0.4]"}},
+		"other model re-embeds":     {"text-embedding-3-large", 2, []string{"[0,0.5]", "[1,0.5]"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var inserted []database.InsertKnowledgeDocumentParams
+			embedder := perTextEmbedder()
+			embedder.provider, embedder.model = "openai", tc.model
+			embedded := 0
+			embed := embedder.embedDocumentsFn
+			embedder.embedDocumentsFn = func(ctx context.Context, texts []string) ([][]float32, error) {
+				embedded += len(texts)
+				return embed(ctx, texts)
+			}
+
+			ks := &knowledgeStore{
+				db:                newImportDB(&inserted),
+				embedder:          embedder,
+				newKnp:            newPublisherFactory(&mockPublisher{}),
+				maxEmbeddingBytes: 8192,
+			}
+			job, err := ks.RunArchiveImport(t.Context(), 8, ArchiveImportRequest{
+				Source:   "kb.zip",
+				Data:     data,
+				Instance: targetInstance,
+			}, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if job.Status != model.KnowledgeImportStatusFinished || job.CreatedDocs != 2 || job.TotalChunks != 2 {
+				t.Errorf("unexpected job: %+v", job)
+			}
+			if embedded != tc.wantEmbeds {
+				t.Errorf("expected %d embedded documents, got %d", tc.wantEmbeds, embedded)
+			}
+			if len(inserted) != 2 {
+				t.Fatalf("expected 2 inserted documents, got %d", len(inserted))
+			}
+			for i, want := range tc.wantVectors {
+				if inserted[i].Embedding != want {
+					t.Errorf("document %d: expected vector %s, got %s", i, want, inserted[i].Embedding)
+				}
+			}
+
+			var meta knowledgeMeta
+			if err := json.Unmarshal(inserted[0].Cmetadata, &meta); err != nil {
+				t.Fatal(err)
+			}
+			if meta.UserID != 8 || meta.FlowID != nil || meta.OriginModel != "openai/text-embedding-3-small" ||
+				meta.OriginID == "" || meta.ImportedAt == nil || meta.ExportedAt == nil {
+				t.Errorf("imported documents must carry provenance: %+v", meta)
+			}
+			doc := metaToModelDoc("new", "", meta)
+			if doc.Provenance == nil || doc.Provenance.InstanceID != meta.OriginInstance {
+				t.Errorf("provenance must be exposed on the model: %+v", doc.Provenance)
+			}
+			if restored := metaFromDoc(doc); restored.OriginID != meta.OriginID || restored.ImportedAt == nil {
+				t.Errorf("updates must keep provenance: %+v", restored)
+			}
+		})
+	}
+}
+
+func TestRunArchiveImportConflicts(t *testing.T) {
+	data, _ := exportFixture(t, false)
+
+	tests := map[string]struct {
+		policy      ConflictPolicy
+		wantCreated int
+		wantUpdated int
+		wantSkipped int
+		wantLookups int
+	}{
+		"skip":      {ConflictSkip, 1, 0, 1, 2},
+		"overwrite": {ConflictOverwrite, 1, 1, 0, 2},
+		"duplicate": {ConflictDuplicate, 2, 0, 0, 0},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				inserted    []database.InsertKnowledgeDocumentParams
+				overwritten []string
+				lookups     []database.FindUserKnowledgeDocumentConflictParams
+			)
+			db := newImportDB(&inserted)
+			db.findConflict = func(_ context.Context, arg database.FindUserKnowledgeDocumentConflictParams) (string, error) {
+				lookups = append(lookups, arg)
+				if arg.OriginID == "doc-guide" {
+					return "local-guide", nil
+				}
+				return "", sql.ErrNoRows
+			}
+			db.updateKnowledge = func(_ context.Context, arg database.UpdateKnowledgeDocumentParams) (database.UpdateKnowledgeDocumentRow, error) {
+				overwritten = append(overwritten, arg.Uuid.String)
+				return database.UpdateKnowledgeDocumentRow{ID: arg.Uuid.String}, nil
+			}
+
+			ks := &knowledgeStore{
+				db:                db,
+				embedder:          perTextEmbedder(),
+				newKnp:            newPublisherFactory(&mockPublisher{}),
+				maxEmbeddingBytes: 8192,
+			}
+			job, err := ks.RunArchiveImport(t.Context(), 5, ArchiveImportRequest{
+				Source:   "kb.zip",
+				Data:     data,
+				Conflict: tc.policy,
+				// importing back into the exporting instance
+				Instance: sourceInstance,
+			}, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if job.CreatedDocs != tc.wantCreated || job.UpdatedDocs != tc.wantUpdated || job.DuplicateDocs != tc.wantSkipped {
+				t.Errorf("unexpected counters: %+v", job)
+			}
+			if len(lookups) != tc.wantLookups {
+				t.Fatalf("expected %d conflict lookups, got %d", tc.wantLookups, len(lookups))
+			}
+			for _, lookup := range lookups {
+				if lookup.UserID != "5" || lookup.Hash == "" {
+					t.Errorf("unexpected lookup: %+v", lookup)
+				}
+				local := lookup.OriginInstance == sourceInstance.InstallationID
+				if local != (lookup.LocalID == lookup.OriginID) || (!local && lookup.LocalID != "") {
+					t.Errorf("local id must only be set for documents of this instance: %+v", lookup)
+				}
+			}
+			if tc.wantUpdated > 0 && (len(overwritten) != 1 || overwritten[0] != "local-guide") {
+				t.Errorf("expected the conflicting document to be overwritten, got %v", overwritten)
+			}
+		})
+	}
+}
+
+func TestReadArchiveValidation(t *testing.T) {
+	valid, _ := exportFixture(t, false)
+	if _, err := ReadArchiveManifest(valid, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	manifest := readZipEntry(t, valid, archiveManifestName)
+	docs := readZipEntry(t, valid, archiveDocumentsName)
+	build := func(manifest, docs string) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, content := range map[string]string{archiveManifestName: manifest, archiveDocumentsName: docs} {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write([]byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	tests := map[string][]byte{
+		"not a zip":       []byte("plain text"),
+		"unknown format":  build(strings.Replace(manifest, ArchiveFormat, "other", 1), docs),
+		"newer version":   build(strings.Replace(manifest, `"version": 1`, `"version": 2`, 1), docs),
+		"count mismatch":  build(manifest, docs+docs),
+		"invalid doctype": build(manifest, strings.Replace(docs, `"doc_type":"guide"`, `"doc_type":"memory"`, 1)),
+		"broken document": build(manifest, "{"),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadArchiveManifest(data, 0); !errors.Is(err, ErrInvalidArchive) {
+				t.Errorf("expected invalid archive error, got %v", err)
+			}
+		})
+	}
+
+	ks := &knowledgeStore{embedder: perTextEmbedder()}
+	if _, err := ks.RunArchiveImport(t.Context(), 1, ArchiveImportRequest{Data: valid, Conflict: "merge"}, nil); err == nil ||
+		!strings.Contains(err.Error(), "conflict policy") {
+		t.Errorf("expected conflict policy error, got %v", err)
+	}
+}
+
+func TestReadArchiveSizeLimit(t *testing.T) {
+	valid, _ := exportFixture(t, false)
+	manifest := readZipEntry(t, valid, archiveManifestName)
+	docs := readZipEntry(t, valid, archiveDocumentsName)
+	size := int64(len(manifest) + len(docs))
+
+	if _, err := ReadArchiveManifest(valid, size); err != nil {
+		t.Fatalf("an archive of the limit size must be read, got %v", err)
+	}
+	if _, err := ReadArchiveManifest(valid, size-1); !errors.Is(err, ErrInvalidArchive) ||
+		!strings.Contains(err.Error(), "import limit") {
+		t.Errorf("expected the declared size to exceed the limit, got %v", err)
+	}
+
+	// a small upload whose documents file inflates to 16MB is refused before it's inflated
+	var deflated bytes.Buffer
+	fw, err := flate.NewWriter(&deflated, flate.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write(bytes.Repeat([]byte(" "), 16<<20)); err != nil {
+		t.Fatal(err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var bomb bytes.Buffer
+	zw := zip.NewWriter(&bomb)
+	w, err := zw.Create(archiveManifestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(manifest)); err != nil {
+		t.Fatal(err)
+	}
+	w, err = zw.CreateRaw(&zip.FileHeader{
+		Name:               archiveDocumentsName,
+		Method:             zip.Deflate,
+		CompressedSize64:   uint64(deflated.Len()),
+		UncompressedSize64: 16 << 20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(deflated.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadArchiveManifest(bomb.Bytes(), 1<<20); !errors.Is(err, ErrInvalidArchive) ||
+		!strings.Contains(err.Error(), "import limit") {
+		t.Errorf("expected the inflated size to exceed the limit, got %v", err)
+	}
+
+	// the inflated bytes are counted as well, the zip headers are not trusted
+	zr, err := zip.NewReader(bytes.NewReader(valid), int64(len(valid)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		if f.Name != archiveDocumentsName {
+			continue
+		}
+		_, err := readArchiveJSON(f, int64(len(docs))-1, func(dec *json.Decoder) error {
+			for dec.More() {
+				var doc archiveDocument
+				if err := dec.Decode(&doc); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if !errors.Is(err, errArchiveTooLarge) {
+			t.Errorf("expected the read to stop at the limit, got %v", err)
+		}
+	}
+
+	// decoding stops at the first document past the manifest count
+	var extra bytes.Buffer
+	zw = zip.NewWriter(&extra)
+	for name, content := range map[string]string{
+		archiveManifestName:  manifest,
+		archiveDocumentsName: docs + docs + strings.Repeat("{", 1<<10),
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadArchiveManifest(extra.Bytes(), 0); !errors.Is(err, ErrInvalidArchive) ||
+		!strings.Contains(err.Error(), "found more") {
+		t.Errorf("expected the extra documents to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/model"
+
+	"github.com/google/uuid"
+)
+
+// ---- quality signals --------------------------------------------------------
+
+// loadSignals returns the quality signals of the given documents by UUID.
+func (ks *knowledgeStore) loadSignals(ctx context.Context, ids []string) (map[string]database.GetKnowledgeDocumentSignalsRow, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := ks.db.GetKnowledgeDocumentSignals(ctx, database.GetKnowledgeDocumentSignalsParams{
+		Ids:    ids,
+		Hashes: []string{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: load feedback: %w", err)
+	}
+	signals := make(map[string]database.GetKnowledgeDocumentSignalsRow, len(rows))
+	for _, row := range rows {
+		signals[row.ID] = row
+	}
+	return signals, nil
+}
+
+func signalsToModel(row database.GetKnowledgeDocumentSignalsRow) *model.KnowledgeFeedback {
+	feedback := &model.KnowledgeFeedback{
+		Retrievals: int(row.Retrievals),
+		Useful:     int(row.Useful),
+		NotUseful:  int(row.NotUseful),
+		Upvotes:    int(row.Upvotes),
+		Downvotes:  int(row.Downvotes),
+	}
+	if row.LastRetrievedAt.Valid {
+		feedback.LastRetrievedAt = &row.LastRetrievedAt.Time
+	}
+	return feedback
+}
+
+// attachFeedback fills the Feedback field of the given documents.
+func (ks *knowledgeStore) attachFeedback(ctx context.Context, docs []*model.KnowledgeDocument) error {
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	signals, err := ks.loadSignals(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if row, ok := signals[doc.ID]; ok {
+			doc.Feedback = signalsToModel(row)
+		}
+	}
+	return nil
+}
+
+// rankByQuality scales search scores by the documents' quality signals and
+// keeps the best limit results. Searches from the UI do not count as
+// retrievals: only documents returned to agents are counted by the tools.
+func (ks *knowledgeStore) rankByQuality(
+	ctx context.Context,
+	results []*model.KnowledgeDocumentWithScore,
+	limit int,
+) ([]*model.KnowledgeDocumentWithScore, error) {
+	ids := make([]string, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.Document.ID)
+	}
+	signals, err := ks.loadSignals(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		row, ok := signals[r.Document.ID]
+		if !ok {
+			continue
+		}
+		r.Document.Feedback = signalsToModel(row)
+		r.Score *= ks.hybrid.QualityFactor(database.SignalsFromRow(row))
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// ---- VoteDocument (admin) ---------------------------------------------------
+
+// VoteDocument sets the caller's vote on a document; a nil vote withdraws it.
+func (ks *knowledgeStore) VoteDocument(ctx context.Context, userID int64, id string, vote *model.KnowledgeVote) (*model.KnowledgeDocument, error) {
+	if _, err := ks.GetDocument(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := ks.doVote(ctx, userID, id, vote); err != nil {
+		return nil, err
+	}
+	doc, err := ks.GetDocument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ks.newKnp(userID).KnowledgeDocumentUpdated(ctx, doc)
+	return doc, nil
+}
+
+// ---- VoteUserDocument (user-scoped) -----------------------------------------
+
+func (ks *knowledgeStore) VoteUserDocument(ctx context.Context, userID int64, id string, vote *model.KnowledgeVote) (*model.KnowledgeDocument, error) {
+	if _, err := ks.GetUserDocument(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	if err := ks.doVote(ctx, userID, id, vote); err != nil {
+		return nil, err
+	}
+	doc, err := ks.GetUserDocument(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	ks.newKnp(userID).KnowledgeDocumentUpdated(ctx, doc)
+	return doc, nil
+}
+
+func (ks *knowledgeStore) doVote(ctx context.Context, userID int64, id string, vote *model.KnowledgeVote) error {
+	if vote == nil {
+		if err := ks.db.DeleteKnowledgeDocumentVote(ctx, database.DeleteKnowledgeDocumentVoteParams{
+			DocumentID: id,
+			UserID:     userID,
+		}); err != nil {
+			return fmt.Errorf("knowledge: withdraw vote on %s: %w", id, err)
+		}
+		return nil
+	}
+
+	documentID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("knowledge: invalid document id %s: %w", id, err)
+	}
+	value := int16(1)
+	if *vote == model.KnowledgeVoteDown {
+		value = -1
+	}
+	if err := ks.db.UpsertKnowledgeDocumentVote(ctx, database.UpsertKnowledgeDocumentVoteParams{
+		DocumentID: documentID,
+		UserID:     userID,
+		Vote:       value,
+	}); err != nil {
+		return fmt.Errorf("knowledge: vote on %s: %w", id, err)
+	}
+	return nil
+}
+
+// ---- ArchiveDocument / SetDocumentExpiry / SetDocumentProject ---------------
+
+// ArchiveDocument hides a document from retrieval without deleting it.
+func (ks *knowledgeStore) ArchiveDocument(ctx context.Context, userID int64, id string, archived bool) (*model.KnowledgeDocument, error) {
+	existing, err := ks.GetDocument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ks.doUpdateState(ctx, userID, id, existing, func(meta *knowledgeMeta) {
+		meta.Archived = archived
+	})
+}
+
+func (ks *knowledgeStore) ArchiveUserDocument(ctx context.Context, userID int64, id string, archived bool) (*model.KnowledgeDocument, error) {
+	existing, err := ks.GetUserDocument(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return ks.doUpdateState(ctx, userID, id, existing, func(meta *knowledgeMeta) {
+		meta.Archived = archived
+	})
+}
+
+// SetDocumentExpiry sets the time after which a document is no longer
+// retrieved; a nil expiresAt keeps it forever.
+func (ks *knowledgeStore) SetDocumentExpiry(ctx context.Context, userID int64, id string, expiresAt *time.Time) (*model.KnowledgeDocument, error) {
+	existing, err := ks.GetDocument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ks.doUpdateState(ctx, userID, id, existing, func(meta *knowledgeMeta) {
+		meta.ExpiresAt = expiresAt
+	})
+}
+
+func (ks *knowledgeStore) SetUserDocumentExpiry(ctx context.Context, userID int64, id string, expiresAt *time.Time) (*model.KnowledgeDocument, error) {
+	existing, err := ks.GetUserDocument(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return ks.doUpdateState(ctx, userID, id, existing, func(meta *knowledgeMeta) {
+		meta.ExpiresAt = expiresAt
+	})
+}
+
+// SetDocumentProject shares a document with the members of a project; a nil
+// projectID stops sharing it.
+func (ks *knowledgeStore) SetDocumentProject(ctx context.Context, userID int64, id string, projectID *int64) (*model.KnowledgeDocument, error) {
+	existing, err := ks.GetDocument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ks.doUpdateState(ctx, userID, id, existing, func(meta *knowledgeMeta) {
+		meta.ProjectID = projectID
+	})
+}
+
+func (ks *knowledgeStore) SetUserDocumentProject(ctx context.Context, userID int64, id string, projectID *int64) (*model.KnowledgeDocument, error) {
+	existing, err := ks.GetUserDocument(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return ks.doUpdateState(ctx, userID, id, existing, func(meta *knowledgeMeta) {
+		meta.ProjectID = projectID
+	})
+}
+
+// doUpdateState changes cmetadata only, like doRename; the vector is kept.
+func (ks *knowledgeStore) doUpdateState(
+	ctx context.Context,
+	userID int64,
+	id string,
+	existing *model.KnowledgeDocument,
+	apply func(meta *knowledgeMeta),
+) (*model.KnowledgeDocument, error) {
+	meta := metaFromDoc(existing)
+	apply(&meta)
+
+	cmJSON, err := metaToJSON(meta)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: marshal cmetadata: %w", err)
+	}
+
+	row, err := ks.db.UpdateKnowledgeDocumentMetadata(ctx, database.UpdateKnowledgeDocumentMetadataParams{
+		Uuid:      nsOf(id),
+		Cmetadata: cmJSON,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: update document state %s: %w", id, err)
+	}
+
+	doc := rowToModel(row.ID, row.Document, nullStr(row.Cmetadata), true)
+	doc.Feedback = existing.Feedback
+	ks.newKnp(userID).KnowledgeDocumentUpdated(ctx, doc)
+	return doc, nil
+}
@@ -0,0 +1,144 @@
+package knowledge
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/model"
+)
+
+func TestSearchRanksByQuality(t *testing.T) {
+	db := &mockDB{
+		searchKnowledge: func(_ context.Context, _ database.SearchKnowledgeDocumentsParams) ([]database.SearchKnowledgeDocumentsRow, error) {
+			return []database.SearchKnowledgeDocumentsRow{
+				makeSearchRow("uuid-1", "misleading", `{"doc_type":"answer"}`, 0.9),
+				makeSearchRow("uuid-2", "helpful", `{"doc_type":"answer"}`, 0.8),
+				makeSearchRow("uuid-3", "plain", `{"doc_type":"answer"}`, 0.7),
+			}, nil
+		},
+		getSignals: func(_ context.Context, arg database.GetKnowledgeDocumentSignalsParams) ([]database.GetKnowledgeDocumentSignalsRow, error) {
+			if len(arg.Ids) != 3 {
+				t.Errorf("signals must be loaded for all candidates, got %v", arg.Ids)
+			}
+			return []database.GetKnowledgeDocumentSignalsRow{
+				{ID: "uuid-1", Downvotes: 2},
+				{ID: "uuid-2", Useful: 4, Retrievals: 10},
+			}, nil
+		},
+	}
+	ks := &knowledgeStore{
+		db:       db,
+		embedder: &mockEmbedder{available: true},
+		hybrid:   database.HybridSearchConfig{FeedbackWeight: 0.5, PopularityWeight: 0.1},
+	}
+
+	results, err := ks.SearchDocuments(t.Context(), "query", nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("want 2 results, got %d", len(results))
+	}
+	if results[0].Document.ID != "uuid-2" || results[1].Document.ID != "uuid-3" {
+		t.Fatalf("unexpected order: %s, %s", results[0].Document.ID, results[1].Document.ID)
+	}
+	feedback := results[0].Document.Feedback
+	if feedback == nil || feedback.Useful != 4 || feedback.Retrievals != 10 {
+		t.Fatalf("feedback not attached: %+v", feedback)
+	}
+	if results[1].Document.Feedback != nil {
+		t.Fatalf("documents without signals must have no feedback, got %+v", results[1].Document.Feedback)
+	}
+}
+
+func TestVoteDocument(t *testing.T) {
+	const (
+		userID = int64(5)
+		docID  = "6f1c2f7e-8a0b-4f55-9c43-0a8d7a1e2b3c"
+	)
+	newDB := func() (*mockDB, *[]int16) {
+		votes := &[]int16{}
+		return &mockDB{
+			getKnowledge: func(_ context.Context, uuid string) (database.GetKnowledgeDocumentRow, error) {
+				return makeRow(uuid, "content", `{"doc_type":"guide"}`), nil
+			},
+			upsertVote: func(_ context.Context, arg database.UpsertKnowledgeDocumentVoteParams) error {
+				if arg.DocumentID.String() != docID || arg.UserID != userID {
+					t.Errorf("unexpected vote params: %+v", arg)
+				}
+				*votes = append(*votes, arg.Vote)
+				return nil
+			},
+			deleteVote: func(_ context.Context, arg database.DeleteKnowledgeDocumentVoteParams) error {
+				*votes = append(*votes, 0)
+				return nil
+			},
+		}, votes
+	}
+
+	t.Run("up, down and withdraw", func(t *testing.T) {
+		db, votes := newDB()
+		pub := &mockPublisher{}
+		ks := &knowledgeStore{db: db, newKnp: newPublisherFactory(pub)}
+
+		up, down := model.KnowledgeVoteUp, model.KnowledgeVoteDown
+		for _, vote := range []*model.KnowledgeVote{&up, &down, nil} {
+			if _, err := ks.VoteDocument(t.Context(), userID, docID, vote); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(*votes) != 3 || (*votes)[0] != 1 || (*votes)[1] != -1 || (*votes)[2] != 0 {
+			t.Fatalf("unexpected votes: %v", *votes)
+		}
+		if len(pub.updatedDocs) != 3 {
+			t.Fatalf("want 3 update events, got %d", len(pub.updatedDocs))
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		db, _ := newDB()
+		ks := &knowledgeStore{db: db, newKnp: newPublisherFactory(&mockPublisher{})}
+		up := model.KnowledgeVoteUp
+		if _, err := ks.VoteDocument(t.Context(), userID, "not-a-uuid", &up); err == nil {
+			t.Fatal("expected an error for a malformed document id")
+		}
+	})
+}
+
+func TestArchiveAndExpiry(t *testing.T) {
+	var stored knowledgeMeta
+	db := &mockDB{
+		getKnowledge: func(_ context.Context, uuid string) (database.GetKnowledgeDocumentRow, error) {
+			return makeRow(uuid, "content", `{"doc_type":"answer","question":"q","user_id":3}`), nil
+		},
+		updateKnowledgeMeta: func(_ context.Context, arg database.UpdateKnowledgeDocumentMetadataParams) (database.UpdateKnowledgeDocumentMetadataRow, error) {
+			stored = parseMeta(string(arg.Cmetadata.RawMessage))
+			return database.UpdateKnowledgeDocumentMetadataRow{
+				ID:        arg.Uuid.String,
+				Document:  "content",
+				Cmetadata: sql.NullString{String: string(arg.Cmetadata.RawMessage), Valid: true},
+			}, nil
+		},
+	}
+	ks := &knowledgeStore{db: db, newKnp: newPublisherFactory(&mockPublisher{})}
+
+	doc, err := ks.ArchiveDocument(t.Context(), 1, "doc-id", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Archived || !stored.Archived || stored.Question != "q" || stored.UserID != 3 {
+		t.Fatalf("archive must keep other metadata: doc=%+v stored=%+v", doc, stored)
+	}
+
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	doc, err = ks.SetDocumentExpiry(t.Context(), 1, "doc-id", &expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ExpiresAt == nil || !doc.ExpiresAt.Equal(expiresAt) || stored.ExpiresAt == nil {
+		t.Fatalf("expiry not stored: doc=%v stored=%v", doc.ExpiresAt, stored.ExpiresAt)
+	}
+}
@@ -0,0 +1,375 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/database/knowledge/ingest"
+	"pentagi/pkg/graph/model"
+	"pentagi/pkg/graph/subscriptions"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const importBatchSize = 16
+
+// ImportRequest describes a bulk import: archives must already be expanded
+// with ingest.ExpandArchives, SkippedFiles counts entries dropped on the way.
+type ImportRequest struct {
+	Source       string
+	Files        []ingest.File
+	SkippedFiles int
+	Options      ingest.Options
+}
+
+// ImportProgress receives the job state after each processed batch.
+type ImportProgress func(job *model.KnowledgeImportJob)
+
+// ---- Import jobs ------------------------------------------------------------
+
+func (ks *knowledgeStore) ListImportJobs(ctx context.Context) ([]*model.KnowledgeImportJob, error) {
+	jobs, err := ks.db.GetKnowledgeImportJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: list import jobs: %w", err)
+	}
+	return importJobsToModel(jobs), nil
+}
+
+func (ks *knowledgeStore) ListUserImportJobs(ctx context.Context, userID int64) ([]*model.KnowledgeImportJob, error) {
+	jobs, err := ks.db.GetUserKnowledgeImportJobs(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: list user import jobs: %w", err)
+	}
+	return importJobsToModel(jobs), nil
+}
+
+func (ks *knowledgeStore) GetImportJob(ctx context.Context, id int64) (*model.KnowledgeImportJob, error) {
+	job, err := ks.db.GetKnowledgeImportJob(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: get import job %d: %w", id, err)
+	}
+	return importJobToModel(job), nil
+}
+
+func (ks *knowledgeStore) GetUserImportJob(ctx context.Context, userID, id int64) (*model.KnowledgeImportJob, error) {
+	job, err := ks.db.GetUserKnowledgeImportJob(ctx, database.GetUserKnowledgeImportJobParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: get import job %d: %w", id, err)
+	}
+	return importJobToModel(job), nil
+}
+
+// FailInterruptedImports marks jobs left pending or running by a previous
+// process as failed; imports run in memory and cannot be resumed.
+func (ks *knowledgeStore) FailInterruptedImports(ctx context.Context) error {
+	err := ks.db.FailInterruptedKnowledgeImportJobs(ctx, nsOf("import was interrupted by a server restart"))
+	if err != nil {
+		return fmt.Errorf("knowledge: fail interrupted imports: %w", err)
+	}
+	return nil
+}
+
+// ---- StartImport / RunImport ------------------------------------------------
+
+// StartImport creates the job and processes it in the background; progress is
+// published as knowledgeImportJobUpdated events.
+func (ks *knowledgeStore) StartImport(ctx context.Context, userID int64, req ImportRequest) (*model.KnowledgeImportJob, error) {
+	job, opts, err := ks.createImportJob(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := importJobToModel(job)
+	ks.newKnp(userID).KnowledgeImportJobUpdated(ctx, result)
+
+	go func() {
+		// the request context ends with the upload, the import must outlive it
+		if _, err := ks.runImport(context.Background(), job, req, opts, nil); err != nil {
+			logrus.WithError(err).WithField("job_id", job.ID).Error("knowledge import failed")
+		}
+	}()
+
+	return result, nil
+}
+
+// RunImport creates the job and processes it synchronously, calling progress
+// after each batch; it is used by command line tools.
+func (ks *knowledgeStore) RunImport(
+	ctx context.Context,
+	userID int64,
+	req ImportRequest,
+	progress ImportProgress,
+) (*model.KnowledgeImportJob, error) {
+	job, opts, err := ks.createImportJob(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	return ks.runImport(ctx, job, req, opts, progress)
+}
+
+func (ks *knowledgeStore) createImportJob(
+	ctx context.Context,
+	userID int64,
+	req ImportRequest,
+) (database.KnowledgeImportJob, ingest.Options, error) {
+	if err := ks.requireEmbedder(); err != nil {
+		return database.KnowledgeImportJob{}, ingest.Options{}, err
+	}
+	if len(req.Files) == 0 {
+		return database.KnowledgeImportJob{}, ingest.Options{}, fmt.Errorf("knowledge: nothing to import")
+	}
+
+	opts, err := req.Options.Normalize()
+	if err != nil {
+		return database.KnowledgeImportJob{}, ingest.Options{}, fmt.Errorf("knowledge: invalid import options: %w", err)
+	}
+	optsJSON, err := json.Marshal(opts)
+	if err != nil {
+		return database.KnowledgeImportJob{}, ingest.Options{}, fmt.Errorf("knowledge: marshal import options: %w", err)
+	}
+
+	job, err := ks.db.CreateKnowledgeImportJob(ctx, database.CreateKnowledgeImportJobParams{
+		UserID:     userID,
+		Source:     req.Source,
+		Options:    optsJSON,
+		TotalFiles: int64(len(req.Files) + req.SkippedFiles),
+	})
+	if err != nil {
+		return database.KnowledgeImportJob{}, ingest.Options{}, fmt.Errorf("knowledge: create import job: %w", err)
+	}
+
+	return job, opts, nil
+}
+
+// importRun holds the counters of a running job and reports every change to
+// the database, subscribers and the optional progress callback.
+type importRun struct {
+	ks       *knowledgeStore
+	knp      subscriptions.KnowledgePublisher
+	state    database.UpdateKnowledgeImportJobParams
+	progress ImportProgress
+}
+
+func (ks *knowledgeStore) newImportRun(job database.KnowledgeImportJob, skipped int, progress ImportProgress) *importRun {
+	return &importRun{
+		ks:  ks,
+		knp: ks.newKnp(job.UserID),
+		state: database.UpdateKnowledgeImportJobParams{
+			ID:           job.ID,
+			Status:       database.KnowledgeImportStatusRunning,
+			SkippedFiles: int64(skipped),
+		},
+		progress: progress,
+	}
+}
+
+func (r *importRun) report(ctx context.Context) (*model.KnowledgeImportJob, error) {
+	updated, err := r.ks.db.UpdateKnowledgeImportJob(ctx, r.state)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: update import job %d: %w", r.state.ID, err)
+	}
+	result := importJobToModel(updated)
+	r.knp.KnowledgeImportJobUpdated(ctx, result)
+	if r.progress != nil {
+		r.progress(result)
+	}
+	return result, nil
+}
+
+func (r *importRun) fail(ctx context.Context, cause error) (*model.KnowledgeImportJob, error) {
+	r.state.Status = database.KnowledgeImportStatusFailed
+	r.state.Error = nsOf(cause.Error())
+	// record the failure even when the import context was cancelled
+	result, err := r.report(context.WithoutCancel(ctx))
+	if err != nil {
+		return nil, errors.Join(cause, err)
+	}
+	return result, cause
+}
+
+// finish completes the job; it fails when every stored document failed.
+func (r *importRun) finish(ctx context.Context) (*model.KnowledgeImportJob, error) {
+	if r.state.CreatedDocs == 0 && r.state.UpdatedDocs == 0 && r.state.FailedDocs > 0 {
+		return r.fail(ctx, fmt.Errorf("knowledge: no documents were imported: %s", r.state.Error.String))
+	}
+	r.state.Status = database.KnowledgeImportStatusFinished
+	return r.report(ctx)
+}
+
+// runImport parses all files, then embeds and stores chunks in batches.
+// Chunks whose content the user already has (or that repeat within the
+// import) are counted as duplicates; embedding and insert errors fail only
+// the affected chunks, while database and context errors fail the job.
+func (ks *knowledgeStore) runImport(
+	ctx context.Context,
+	job database.KnowledgeImportJob,
+	req ImportRequest,
+	opts ingest.Options,
+	progress ImportProgress,
+) (*model.KnowledgeImportJob, error) {
+	run := ks.newImportRun(job, req.SkippedFiles, progress)
+
+	var chunks []ingest.Chunk
+	for _, file := range req.Files {
+		fileChunks, err := ingest.Parse(file, opts)
+		if err != nil {
+			run.state.SkippedFiles++
+			if !errors.Is(err, ingest.ErrUnsupported) {
+				logrus.WithError(err).WithField("path", file.Path).Warn("skipping knowledge import file")
+			}
+			continue
+		}
+		chunks = append(chunks, fileChunks...)
+	}
+	run.state.TotalChunks = int64(len(chunks))
+	if _, err := run.report(ctx); err != nil {
+		return run.fail(ctx, err)
+	}
+
+	seen := make(map[string]struct{}, len(chunks))
+	userID := strconv.FormatInt(job.UserID, 10)
+	for start := 0; start < len(chunks); start += importBatchSize {
+		if err := ctx.Err(); err != nil {
+			return run.fail(ctx, err)
+		}
+
+		batch := chunks[start:min(start+importBatchSize, len(chunks))]
+		fresh := make([]ingest.Chunk, 0, len(batch))
+		for _, chunk := range batch {
+			hash := chunk.Hash()
+			if _, ok := seen[hash]; ok {
+				run.state.DuplicateDocs++
+				continue
+			}
+			seen[hash] = struct{}{}
+
+			exists, err := ks.db.ExistsUserKnowledgeDocumentHash(ctx, database.ExistsUserKnowledgeDocumentHashParams{
+				Hash:   hash,
+				UserID: userID,
+			})
+			if err != nil {
+				return run.fail(ctx, fmt.Errorf("knowledge: check duplicate content: %w", err))
+			}
+			if exists {
+				run.state.DuplicateDocs++
+				continue
+			}
+			fresh = append(fresh, chunk)
+		}
+
+		created, err := ks.importChunks(ctx, job.UserID, fresh)
+		run.state.CreatedDocs += int64(created)
+		run.state.FailedDocs += int64(len(fresh) - created)
+		if err != nil {
+			run.state.Error = nsOf(err.Error())
+		}
+		run.state.ProcessedChunks += int64(len(batch))
+
+		if _, err := run.report(ctx); err != nil {
+			return run.fail(ctx, err)
+		}
+	}
+
+	return run.finish(ctx)
+}
+
+// importChunks embeds the chunks in one request and inserts them, returning
+// the number of stored documents and the last error met.
+func (ks *knowledgeStore) importChunks(ctx context.Context, userID int64, chunks []ingest.Chunk) (int, error) {
+	if len(chunks) == 0 {
+		return 0, nil
+	}
+
+	texts := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		text := chunk.Content
+		if len(text) > ks.maxEmbeddingBytes {
+			text = text[:ks.maxEmbeddingBytes]
+		}
+		texts = append(texts, text)
+	}
+
+	vecs, err := ks.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return 0, fmt.Errorf("knowledge: compute embeddings: %w", err)
+	}
+	if len(vecs) != len(chunks) {
+		return 0, fmt.Errorf("knowledge: embedder returned %d vectors for %d chunks", len(vecs), len(chunks))
+	}
+
+	var (
+		created int
+		lastErr error
+	)
+	for idx, chunk := range chunks {
+		cmJSON, err := metaToJSON(knowledgeMeta{
+			DocType:     chunk.DocType,
+			UserID:      userID,
+			Question:    chunk.Question,
+			Description: chunk.Description,
+			GuideType:   chunk.GuideType,
+			AnswerType:  chunk.AnswerType,
+			CodeLang:    chunk.CodeLang,
+			PartSize:    len(chunk.Content),
+			TotalSize:   chunk.TotalSize,
+			Manual:      true,
+		})
+		if err != nil {
+			lastErr = fmt.Errorf("knowledge: marshal cmetadata: %w", err)
+			continue
+		}
+
+		_, err = ks.db.InsertKnowledgeDocument(ctx, database.InsertKnowledgeDocumentParams{
+			Uuid:      uuid.New(),
+			Document:  nsOf(chunk.Content),
+			Embedding: formatVector(vecs[idx]),
+			Cmetadata: cmJSON.RawMessage,
+		})
+		if err != nil {
+			lastErr = fmt.Errorf("knowledge: import %s: %w", chunk.Source, err)
+			continue
+		}
+		created++
+	}
+
+	return created, lastErr
+}
+
+func importJobToModel(job database.KnowledgeImportJob) *model.KnowledgeImportJob {
+	result := &model.KnowledgeImportJob{
+		ID:              job.ID,
+		UserID:          job.UserID,
+		Status:          model.KnowledgeImportStatus(job.Status),
+		Source:          job.Source,
+		TotalFiles:      int(job.TotalFiles),
+		SkippedFiles:    int(job.SkippedFiles),
+		TotalChunks:     int(job.TotalChunks),
+		ProcessedChunks: int(job.ProcessedChunks),
+		CreatedDocs:     int(job.CreatedDocs),
+		UpdatedDocs:     int(job.UpdatedDocs),
+		DuplicateDocs:   int(job.DuplicateDocs),
+		FailedDocs:      int(job.FailedDocs),
+		CreatedAt:       job.CreatedAt.Time,
+		UpdatedAt:       job.UpdatedAt.Time,
+	}
+	if job.Error.Valid {
+		result.Error = &job.Error.String
+	}
+	return result
+}
+
+func importJobsToModel(jobs []database.KnowledgeImportJob) []*model.KnowledgeImportJob {
+	result := make([]*model.KnowledgeImportJob, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, importJobToModel(job))
+	}
+	return result
+}
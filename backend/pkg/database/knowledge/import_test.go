@@ -0,0 +1,188 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/database/knowledge/ingest"
+	"pentagi/pkg/graph/model"
+)
+
+// newImportDB returns a mockDB that stores import job updates in memory and
+// records inserted documents.
+func newImportDB(inserted *[]database.InsertKnowledgeDocumentParams) *mockDB {
+	var job database.KnowledgeImportJob
+	return &mockDB{
+		createImportJob: func(_ context.Context, arg database.CreateKnowledgeImportJobParams) (database.KnowledgeImportJob, error) {
+			job = database.KnowledgeImportJob{
+				ID:         7,
+				UserID:     arg.UserID,
+				Status:     database.KnowledgeImportStatusPending,
+				Source:     arg.Source,
+				Options:    arg.Options,
+				TotalFiles: arg.TotalFiles,
+			}
+			return job, nil
+		},
+		updateImportJob: func(_ context.Context, arg database.UpdateKnowledgeImportJobParams) (database.KnowledgeImportJob, error) {
+			job.Status = arg.Status
+			job.SkippedFiles = arg.SkippedFiles
+			job.TotalChunks = arg.TotalChunks
+			job.ProcessedChunks = arg.ProcessedChunks
+			job.CreatedDocs = arg.CreatedDocs
+			job.UpdatedDocs = arg.UpdatedDocs
+			job.DuplicateDocs = arg.DuplicateDocs
+			job.FailedDocs = arg.FailedDocs
+			job.Error = arg.Error
+			return job, nil
+		},
+		insertKnowledge: func(_ context.Context, arg database.InsertKnowledgeDocumentParams) (string, error) {
+			*inserted = append(*inserted, arg)
+			return arg.Uuid.String(), nil
+		},
+	}
+}
+
+func perTextEmbedder() *mockEmbedder {
+	return &mockEmbedder{
+		available: true,
+		embedDocumentsFn: func(_ context.Context, texts []string) ([][]float32, error) {
+			vecs := make([][]float32, len(texts))
+			for i := range texts {
+				vecs[i] = []float32{float32(i), 0.5}
+			}
+			return vecs, nil
+		},
+	}
+}
+
+func TestRunImport(t *testing.T) {
+	var inserted []database.InsertKnowledgeDocumentParams
+	db := newImportDB(&inserted)
+	var checkedUser string
+	db.existsHash = func(_ context.Context, arg database.ExistsUserKnowledgeDocumentHashParams) (bool, error) {
+		checkedUser = arg.UserID
+		return arg.Hash == ingest.HashContent("already stored"), nil
+	}
+
+	pub := &mockPublisher{}
+	ks := &knowledgeStore{
+		db:                db,
+		embedder:          perTextEmbedder(),
+		newKnp:            newPublisherFactory(pub),
+		maxEmbeddingBytes: 8192,
+	}
+
+	var updates []*model.KnowledgeImportJob
+	job, err := ks.RunImport(t.Context(), 42, ImportRequest{
+		Source: "docs.zip",
+		Files: []ingest.File{
+			{Path: "docs.zip/install.md", Data: []byte("# Install nmap\n\nRun apt-get install nmap.")},
+			{Path: "docs.zip/copy.md", Data: []byte("# Install nmap\n\nRun apt-get install nmap.")},
+			{Path: "docs.zip/old.txt", Data: []byte("already stored")},
+			{Path: "docs.zip/logo.png", Data: []byte("\x89PNG\x00")},
+		},
+		SkippedFiles: 2,
+	}, func(job *model.KnowledgeImportJob) {
+		updates = append(updates, job)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if job.Status != model.KnowledgeImportStatusFinished {
+		t.Errorf("expected finished job, got %s", job.Status)
+	}
+	if job.TotalFiles != 6 || job.SkippedFiles != 3 || job.TotalChunks != 3 || job.ProcessedChunks != 3 {
+		t.Errorf("unexpected file counters: %+v", job)
+	}
+	if job.CreatedDocs != 1 || job.DuplicateDocs != 2 || job.FailedDocs != 0 {
+		t.Errorf("unexpected document counters: %+v", job)
+	}
+	if checkedUser != "42" {
+		t.Errorf("duplicates must be checked per user, got %q", checkedUser)
+	}
+	// parsed, one batch processed, finished
+	if len(updates) != 3 || len(pub.importJobs) != 3 || pub.userID != 42 {
+		t.Errorf("expected 3 progress updates, got %d (published %d)", len(updates), len(pub.importJobs))
+	}
+
+	if len(inserted) != 1 {
+		t.Fatalf("expected 1 inserted document, got %d", len(inserted))
+	}
+	var meta knowledgeMeta
+	if err := json.Unmarshal(inserted[0].Cmetadata, &meta); err != nil {
+		t.Fatalf("invalid cmetadata: %v", err)
+	}
+	if meta.UserID != 42 || meta.DocType != "guide" || meta.GuideType != "install" || !meta.Manual ||
+		meta.Question != "Install nmap" || meta.Description != "Imported from docs.zip/install.md" {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+	if inserted[0].Embedding != "[0,0.5]" {
+		t.Errorf("unexpected embedding %s", inserted[0].Embedding)
+	}
+}
+
+func TestRunImportEmbeddingFailure(t *testing.T) {
+	var inserted []database.InsertKnowledgeDocumentParams
+	embedder := &mockEmbedder{
+		available: true,
+		embedDocumentsFn: func(context.Context, []string) ([][]float32, error) {
+			return nil, errors.New("rate limited")
+		},
+	}
+	ks := &knowledgeStore{
+		db:                newImportDB(&inserted),
+		embedder:          embedder,
+		newKnp:            newPublisherFactory(&mockPublisher{}),
+		maxEmbeddingBytes: 8192,
+	}
+
+	job, err := ks.RunImport(t.Context(), 1, ImportRequest{
+		Source: "notes.md",
+		Files:  []ingest.File{{Path: "notes.md", Data: []byte("# Notes\n\ntext")}},
+	}, nil)
+	if err == nil {
+		t.Fatal("expected an error when nothing could be imported")
+	}
+	if job == nil || job.Status != model.KnowledgeImportStatusFailed || job.FailedDocs != 1 || job.Error == nil {
+		t.Errorf("expected failed job with error, got %+v", job)
+	}
+	if len(inserted) != 0 {
+		t.Errorf("no documents must be inserted, got %d", len(inserted))
+	}
+}
+
+func TestRunImportValidation(t *testing.T) {
+	db := &mockDB{
+		createImportJob: func(context.Context, database.CreateKnowledgeImportJobParams) (database.KnowledgeImportJob, error) {
+			t.Fatal("job must not be created")
+			return database.KnowledgeImportJob{}, nil
+		},
+	}
+	files := []ingest.File{{Path: "a.md", Data: []byte("a")}}
+
+	tests := map[string]struct {
+		embedder *mockEmbedder
+		req      ImportRequest
+	}{
+		"embedder unavailable": {&mockEmbedder{}, ImportRequest{Source: "a.md", Files: files}},
+		"no files":             {perTextEmbedder(), ImportRequest{Source: "empty.zip"}},
+		"invalid options": {perTextEmbedder(), ImportRequest{
+			Source:  "a.md",
+			Files:   files,
+			Options: ingest.Options{Splitter: "semantic"},
+		}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ks := &knowledgeStore{db: db, embedder: tc.embedder, newKnp: newPublisherFactory(&mockPublisher{})}
+			if _, err := ks.RunImport(t.Context(), 1, tc.req, nil); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package ingest
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrTooLarge is returned when the uncompressed import exceeds its size limit.
+var ErrTooLarge = errors.New("import exceeds the size limit")
+
+// ExpandArchives replaces zip archives with the files they contain and
+// returns the resulting files with the number of skipped archive entries.
+// Entries are named "<archive>/<entry path>"; directories, hidden files,
+// macOS metadata and nested archives are skipped. maxBytes bounds the total
+// uncompressed size of all files, guarding against zip bombs (0 = no limit).
+func ExpandArchives(files []File, maxBytes int64) ([]File, int, error) {
+	var (
+		result  []File
+		skipped int
+		total   int64
+	)
+	limit := func(size int64) error {
+		total += size
+		if maxBytes > 0 && total > maxBytes {
+			return ErrTooLarge
+		}
+		return nil
+	}
+
+	for _, file := range files {
+		if !strings.EqualFold(path.Ext(file.Path), ".zip") {
+			if err := limit(int64(len(file.Data))); err != nil {
+				return nil, 0, err
+			}
+			result = append(result, file)
+			continue
+		}
+
+		reader, err := zip.NewReader(bytes.NewReader(file.Data), int64(len(file.Data)))
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to open archive %s: %w", file.Path, err)
+		}
+
+		for _, entry := range reader.File {
+			if entry.FileInfo().IsDir() {
+				continue
+			}
+			name := cleanEntryPath(entry.Name)
+			if name == "" || isHiddenPath(name) || strings.EqualFold(path.Ext(name), ".zip") {
+				skipped++
+				continue
+			}
+
+			data, err := readEntry(entry, maxBytes-total, maxBytes > 0)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to read %s from %s: %w", entry.Name, file.Path, err)
+			}
+			if err := limit(int64(len(data))); err != nil {
+				return nil, 0, err
+			}
+			result = append(result, File{Path: path.Join(file.Path, name), Data: data})
+		}
+	}
+
+	return result, skipped, nil
+}
+
+func readEntry(entry *zip.File, remaining int64, limited bool) ([]byte, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	if !limited {
+		return io.ReadAll(rc)
+	}
+	// read one byte past the remaining budget so an overflow is detected
+	// without trusting the size declared in the archive header
+	data, err := io.ReadAll(io.LimitReader(rc, max(remaining, 0)+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > remaining {
+		return nil, ErrTooLarge
+	}
+
+	return data, nil
+}
+
+func cleanEntryPath(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	return strings.TrimPrefix(name, "/")
+}
+
+// isHiddenPath reports whether any path element is hidden or belongs to
+// archive metadata such as __MACOSX.
+func isHiddenPath(name string) bool {
+	for elem := range strings.SplitSeq(name, "/") {
+		if strings.HasPrefix(elem, ".") || elem == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package ingest
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+var codeLangByExt = map[string]string{
+	".go":    "go",
+	".py":    "python",
+	".js":    "javascript",
+	".mjs":   "javascript",
+	".ts":    "typescript",
+	".rb":    "ruby",
+	".php":   "php",
+	".java":  "java",
+	".kt":    "kotlin",
+	".swift": "swift",
+	".c":     "c",
+	".h":     "c",
+	".cpp":   "cpp",
+	".cc":    "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".rs":    "rust",
+	".sh":    "bash",
+	".bash":  "bash",
+	".zsh":   "bash",
+	".ps1":   "powershell",
+	".pl":    "perl",
+	".lua":   "lua",
+	".sql":   "sql",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+}
+
+var codeLangByInterpreter = map[string]string{
+	"sh":      "bash",
+	"bash":    "bash",
+	"zsh":     "bash",
+	"python":  "python",
+	"python3": "python",
+	"perl":    "perl",
+	"ruby":    "ruby",
+	"node":    "javascript",
+	"php":     "php",
+}
+
+// CodeLangOf detects the programming language of a source file by its
+// extension or, for extensionless scripts, by the shebang interpreter.
+// It returns an empty string for files that are not code.
+func CodeLangOf(filePath string, data []byte) string {
+	if lang, ok := codeLangByExt[strings.ToLower(path.Ext(filePath))]; ok {
+		return lang
+	}
+
+	line, _, _ := strings.Cut(string(data[:min(len(data), 256)]), "\n")
+	shebang, ok := strings.CutPrefix(strings.TrimSpace(line), "#!")
+	if !ok {
+		return ""
+	}
+	fields := strings.Fields(shebang)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+
+	return codeLangByInterpreter[interpreter]
+}
+
+// guideKeywords are scored against a guide's title and content to pick the
+// guide type; earlier types win ties.
+var guideKeywords = []struct {
+	guideType string
+	keywords  []string
+}{
+	{"pentest", []string{
+		"exploit", "vulnerability", "payload", "pentest", "penetration test", "privilege escalation",
+		"reverse shell", "enumeration", "cve-", "injection", "brute force", "lateral movement",
+	}},
+	{"install", []string{
+		"install", "apt-get", "apt install", "pip install", "go install", "brew install",
+		"download", "build from source", "prerequisites",
+	}},
+	{"configure", []string{
+		"configure", "configuration", "config file", "settings", "environment variable", "options",
+	}},
+	{"development", []string{
+		"api", "sdk", "library", "function", "compile", "debug", "unit test", "refactor",
+	}},
+	{"use", []string{
+		"usage", "how to use", "example", "tutorial", "command line", "getting started", "run ",
+	}},
+}
+
+// InferGuideType scores guide keywords in the title (weighted) and content
+// and returns the best matching guide type, or "other" when nothing matches.
+func InferGuideType(title, content string) string {
+	title = strings.ToLower(title)
+	content = strings.ToLower(content)
+
+	best, bestScore := "other", 0
+	for _, guide := range guideKeywords {
+		score := 0
+		for _, keyword := range guide.keywords {
+			score += 3*strings.Count(title, keyword) + strings.Count(content, keyword)
+		}
+		if score > bestScore {
+			best, bestScore = guide.guideType, score
+		}
+	}
+
+	return best
+}
+
+// inferMeta resolves document metadata: explicit options win, then values
+// from the document itself (JSONL fields, code detection), then inference.
+func inferMeta(doc document, opts Options) Chunk {
+	chunk := Chunk{Question: doc.title}
+
+	chunk.DocType = firstValid(docTypes, opts.DocType, doc.docType)
+	if chunk.DocType == "" {
+		chunk.DocType = "guide"
+		if doc.answerType != "" {
+			chunk.DocType = "answer"
+		}
+	}
+
+	switch chunk.DocType {
+	case "guide":
+		chunk.GuideType = firstValid(guideTypes, opts.GuideType, doc.guideType)
+		if chunk.GuideType == "" {
+			chunk.GuideType = InferGuideType(doc.title, doc.content)
+		}
+	case "answer":
+		chunk.AnswerType = firstValid(answerTypes, opts.AnswerType, doc.answerType)
+		if chunk.AnswerType == "" {
+			chunk.AnswerType = "other"
+		}
+	case "code":
+		chunk.CodeLang = doc.codeLang
+		if opts.CodeLang != "" {
+			chunk.CodeLang = opts.CodeLang
+		}
+	}
+
+	return chunk
+}
+
+func firstValid(allowed []string, values ...string) string {
+	for _, value := range values {
+		if slices.Contains(allowed, value) {
+			return value
+		}
+	}
+	return ""
+}
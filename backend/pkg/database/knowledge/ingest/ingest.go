@@ -0,0 +1,211 @@
+// Package ingest turns uploaded documents and repositories into knowledge
+// store chunks: it expands archives, parses Markdown, HTML, plain text, JSONL
+// and source files, infers document metadata and splits content into chunks.
+package ingest
+
+import (
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+const (
+	DefaultChunkSize    = 2000
+	DefaultChunkOverlap = 200
+)
+
+// ErrUnsupported is returned for files that are not imported, such as binaries
+// or unknown formats; callers count them as skipped rather than failed.
+var ErrUnsupported = errors.New("unsupported file format")
+
+// Splitter selects how documents are chunked.
+type Splitter string
+
+const (
+	// SplitterAuto uses the markdown splitter for Markdown and HTML documents
+	// and the recursive character splitter for everything else.
+	SplitterAuto      Splitter = "auto"
+	SplitterMarkdown  Splitter = "markdown"
+	SplitterRecursive Splitter = "recursive"
+	// SplitterNone keeps each document (or JSONL record) as a single chunk.
+	SplitterNone Splitter = "none"
+)
+
+var (
+	docTypes    = []string{"answer", "guide", "code"}
+	guideTypes  = []string{"install", "configure", "use", "pentest", "development", "other"}
+	answerTypes = []string{"guide", "vulnerability", "code", "tool", "other"}
+)
+
+// Options controls parsing and chunking of an import. Empty metadata fields
+// are inferred per document; non-empty ones override inference.
+type Options struct {
+	Splitter     Splitter `json:"splitter"`
+	ChunkSize    int      `json:"chunk_size"`
+	ChunkOverlap int      `json:"chunk_overlap"`
+	DocType      string   `json:"doc_type,omitempty"`
+	GuideType    string   `json:"guide_type,omitempty"`
+	AnswerType   string   `json:"answer_type,omitempty"`
+	CodeLang     string   `json:"code_lang,omitempty"`
+}
+
+// Normalize fills defaults and validates the options.
+func (o Options) Normalize() (Options, error) {
+	switch o.Splitter {
+	case "":
+		o.Splitter = SplitterAuto
+	case SplitterAuto, SplitterMarkdown, SplitterRecursive, SplitterNone:
+	default:
+		return o, fmt.Errorf("unknown splitter %q", o.Splitter)
+	}
+
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = DefaultChunkSize
+	}
+	if o.ChunkOverlap < 0 {
+		o.ChunkOverlap = 0
+	}
+	if o.ChunkOverlap >= o.ChunkSize {
+		o.ChunkOverlap = o.ChunkSize / 10
+	}
+
+	if o.DocType != "" && !slices.Contains(docTypes, o.DocType) {
+		return o, fmt.Errorf("unknown doc type %q", o.DocType)
+	}
+	if o.GuideType != "" && !slices.Contains(guideTypes, o.GuideType) {
+		return o, fmt.Errorf("unknown guide type %q", o.GuideType)
+	}
+	if o.AnswerType != "" && !slices.Contains(answerTypes, o.AnswerType) {
+		return o, fmt.Errorf("unknown answer type %q", o.AnswerType)
+	}
+	o.CodeLang = strings.ToLower(strings.TrimSpace(o.CodeLang))
+
+	return o, nil
+}
+
+// File is a single uploaded or archived file.
+type File struct {
+	Path string
+	Data []byte
+}
+
+// Chunk is one knowledge document produced from a file.
+type Chunk struct {
+	Source      string
+	Question    string
+	Description string
+	Content     string
+	DocType     string
+	GuideType   string
+	AnswerType  string
+	CodeLang    string
+	Part        int
+	Parts       int
+	TotalSize   int
+}
+
+// Hash returns the md5 hex digest of the chunk content, matching
+// md5(document) in PostgreSQL, and is used to skip duplicates.
+func (c Chunk) Hash() string {
+	return HashContent(c.Content)
+}
+
+// HashContent returns the md5 hex digest of content.
+func HashContent(content string) string {
+	sum := md5.Sum([]byte(content)) //nolint:gosec
+	return hex.EncodeToString(sum[:])
+}
+
+// document is a parsed file (or JSONL record) before chunking.
+type document struct {
+	title       string
+	description string
+	content     string
+	markdown    bool
+	docType     string
+	guideType   string
+	answerType  string
+	codeLang    string
+}
+
+// Parse converts a file into chunks. Files that should not be imported return
+// ErrUnsupported; opts must be normalized.
+func Parse(file File, opts Options) ([]Chunk, error) {
+	if isBinary(file.Data) {
+		return nil, ErrUnsupported
+	}
+
+	var (
+		docs []document
+		err  error
+	)
+	ext := strings.ToLower(path.Ext(file.Path))
+	switch {
+	case ext == ".md" || ext == ".markdown":
+		docs = []document{parseMarkdown(file)}
+	case ext == ".html" || ext == ".htm":
+		docs, err = parseHTML(file)
+	case ext == ".jsonl" || ext == ".ndjson":
+		docs, err = parseJSONL(file)
+	case ext == ".txt" || ext == ".text" || ext == ".rst" || ext == ".log" || ext == ".adoc":
+		docs = []document{parseText(file)}
+	default:
+		lang := CodeLangOf(file.Path, file.Data)
+		if lang == "" {
+			return nil, ErrUnsupported
+		}
+		docs = []document{parseCode(file, lang)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []Chunk
+	for _, doc := range docs {
+		parts, err := split(doc, opts)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, makeChunks(file.Path, doc, parts, opts)...)
+	}
+
+	return chunks, nil
+}
+
+func makeChunks(source string, doc document, parts []string, opts Options) []Chunk {
+	meta := inferMeta(doc, opts)
+
+	totalSize := len(strings.TrimSpace(doc.content))
+	chunks := make([]Chunk, 0, len(parts))
+	for idx, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		chunk := meta
+		chunk.Source = source
+		chunk.Content = part
+		chunk.Part = idx + 1
+		chunk.Parts = len(parts)
+		chunk.TotalSize = totalSize
+		chunk.Description = doc.description
+		if chunk.Description == "" {
+			chunk.Description = "Imported from " + source
+			if chunk.Parts > 1 {
+				chunk.Description += fmt.Sprintf(" (part %d of %d)", chunk.Part, chunk.Parts)
+			}
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+func isBinary(data []byte) bool {
+	head := data[:min(len(data), 8000)]
+	return slices.Contains(head, 0)
+}
@@ -0,0 +1,272 @@
+package ingest
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func normalized(t *testing.T, opts Options) Options {
+	t.Helper()
+	opts, err := opts.Normalize()
+	if err != nil {
+		t.Fatalf("unexpected options error: %v", err)
+	}
+	return opts
+}
+
+func TestOptionsNormalize(t *testing.T) {
+	opts := normalized(t, Options{ChunkOverlap: 5000, CodeLang: " Python "})
+	if opts.Splitter != SplitterAuto || opts.ChunkSize != DefaultChunkSize {
+		t.Errorf("expected defaults, got %+v", opts)
+	}
+	if opts.ChunkOverlap != DefaultChunkSize/10 {
+		t.Errorf("overlap must be smaller than chunk size, got %d", opts.ChunkOverlap)
+	}
+	if opts.CodeLang != "python" {
+		t.Errorf("expected normalized code lang, got %q", opts.CodeLang)
+	}
+
+	for _, invalid := range []Options{
+		{Splitter: "semantic"},
+		{DocType: "memory"},
+		{GuideType: "unknown"},
+		{AnswerType: "unknown"},
+	} {
+		if _, err := invalid.Normalize(); err == nil {
+			t.Errorf("expected error for %+v", invalid)
+		}
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	content := "```\n# not a title\n```\n\n# Installing nmap\n\nRun `apt-get install nmap` to install it.\n"
+	chunks, err := Parse(File{Path: "docs/nmap.md", Data: []byte(content)}, normalized(t, Options{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+
+	chunk := chunks[0]
+	if chunk.Question != "Installing nmap" {
+		t.Errorf("unexpected title %q", chunk.Question)
+	}
+	if chunk.DocType != "guide" || chunk.GuideType != "install" {
+		t.Errorf("unexpected inferred types: %+v", chunk)
+	}
+	if chunk.Description != "Imported from docs/nmap.md" || chunk.Source != "docs/nmap.md" {
+		t.Errorf("unexpected source metadata: %+v", chunk)
+	}
+	if chunk.Hash() != HashContent(strings.TrimSpace(content)) {
+		t.Error("hash must cover the trimmed content")
+	}
+}
+
+func TestParseSplitsLargeDocuments(t *testing.T) {
+	var sb strings.Builder
+	for i := range 20 {
+		sb.WriteString("## Section\n\n")
+		sb.WriteString(strings.Repeat("exploit the target service ", 10+i))
+		sb.WriteString("\n\n")
+	}
+	file := File{Path: "writeup.md", Data: []byte(sb.String())}
+
+	chunks, err := Parse(file, normalized(t, Options{ChunkSize: 500, ChunkOverlap: 50}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	for _, chunk := range chunks {
+		if chunk.Parts != len(chunks) || chunk.GuideType != "pentest" {
+			t.Errorf("unexpected chunk metadata: %+v", chunk)
+		}
+		want := fmt.Sprintf("Imported from writeup.md (part %d of %d)", chunk.Part, chunk.Parts)
+		if chunk.Description != want {
+			t.Errorf("expected description %q, got %q", want, chunk.Description)
+		}
+	}
+
+	whole, err := Parse(file, normalized(t, Options{Splitter: SplitterNone, ChunkSize: 500}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(whole) != 1 {
+		t.Errorf("splitter none must keep one chunk, got %d", len(whole))
+	}
+}
+
+func TestParseHTML(t *testing.T) {
+	page := `<html><head><title>Page</title><script>alert(1)</script></head>
+<body><nav>menu</nav><h1>Configuring  Burp</h1><p>Open the proxy settings.</p>
+<ul><li>one</li><li>two</li></ul><pre>listen 127.0.0.1:8080</pre></body></html>`
+
+	chunks, err := Parse(File{Path: "burp.html", Data: []byte(page)}, normalized(t, Options{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+
+	chunk := chunks[0]
+	if chunk.Question != "Configuring Burp" {
+		t.Errorf("unexpected title %q", chunk.Question)
+	}
+	for _, want := range []string{"# Configuring Burp", "Open the proxy settings.", "- one", "```\nlisten 127.0.0.1:8080\n```"} {
+		if !strings.Contains(chunk.Content, want) {
+			t.Errorf("content must contain %q, got:\n%s", want, chunk.Content)
+		}
+	}
+	for _, unwanted := range []string{"alert", "menu"} {
+		if strings.Contains(chunk.Content, unwanted) {
+			t.Errorf("content must not contain %q", unwanted)
+		}
+	}
+}
+
+func TestParseJSONL(t *testing.T) {
+	data := `{"content":"Use sqlmap --batch","question":"How to run sqlmap?","doc_type":"answer","answer_type":"tool"}
+
+{"content":"print(1)\nprint(2)","doc_type":"code","code_lang":"Python","description":"snippet"}
+{"content":"Kerberoasting walkthrough","doc_type":"bogus","guide_type":"pentest"}
+`
+	chunks, err := Parse(File{Path: "kb.jsonl", Data: []byte(data)}, normalized(t, Options{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+
+	if c := chunks[0]; c.DocType != "answer" || c.AnswerType != "tool" || c.Question != "How to run sqlmap?" {
+		t.Errorf("unexpected answer chunk: %+v", c)
+	}
+	if c := chunks[1]; c.DocType != "code" || c.CodeLang != "python" || c.Question != "print(1)" || c.Description != "snippet" {
+		t.Errorf("unexpected code chunk: %+v", c)
+	}
+	if c := chunks[2]; c.DocType != "guide" || c.GuideType != "pentest" {
+		t.Errorf("invalid doc type must fall back to inference: %+v", c)
+	}
+
+	if _, err := Parse(File{Path: "bad.jsonl", Data: []byte("{\"content\":\"ok\"}\n{broken\n")}, normalized(t, Options{})); err == nil ||
+		!strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected line error, got %v", err)
+	}
+	if _, err := Parse(File{Path: "empty.jsonl", Data: []byte(`{"question":"q"}`)}, normalized(t, Options{})); err == nil {
+		t.Error("records without content must fail")
+	}
+}
+
+func TestParseCodeAndOverrides(t *testing.T) {
+	script := File{Path: "tools/scan", Data: []byte("#!/usr/bin/env python3\nimport socket\n")}
+	chunks, err := Parse(script, normalized(t, Options{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chunks[0].DocType != "code" || chunks[0].CodeLang != "python" {
+		t.Errorf("unexpected code chunk: %+v", chunks[0])
+	}
+
+	readme := File{Path: "README.txt", Data: []byte("some notes")}
+	chunks, err = Parse(readme, normalized(t, Options{DocType: "answer", AnswerType: "vulnerability"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chunks[0].DocType != "answer" || chunks[0].AnswerType != "vulnerability" || chunks[0].Question != "README" {
+		t.Errorf("overrides must win: %+v", chunks[0])
+	}
+
+	for _, file := range []File{
+		{Path: "image.png", Data: []byte("\x89PNG\x00\x00")},
+		{Path: "data.bin", Data: []byte("plain but unknown")},
+	} {
+		if _, err := Parse(file, normalized(t, Options{})); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("expected unsupported for %s, got %v", file.Path, err)
+		}
+	}
+}
+
+func TestInferGuideType(t *testing.T) {
+	tests := map[string][2]string{
+		"other":       {"Notes", "nothing relevant here"},
+		"configure":   {"Proxy configuration", "edit the config file"},
+		"development": {"Writing a plugin", "call the api function and compile"},
+		"use":         {"Usage", "example"},
+	}
+	for want, input := range tests {
+		if got := InferGuideType(input[0], input[1]); got != want {
+			t.Errorf("InferGuideType(%q) = %q, want %q", input[0], got, want)
+		}
+	}
+}
+
+func buildZip(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExpandArchives(t *testing.T) {
+	archive := buildZip(t, map[string]string{
+		"repo/README.md":              "# Repo",
+		"repo/../../etc/passwd.txt":   "root",
+		"repo/.git/config":            "[core]",
+		"__MACOSX/repo/._README.md":   "meta",
+		"repo/nested.zip":             "zip",
+		"repo/src/main.go":            "package main",
+		"repo/docs/":                  "",
+		"repo/docs/guide/install.txt": "install",
+	})
+
+	files, skipped, err := ExpandArchives([]File{
+		{Path: "notes.md", Data: []byte("# Notes")},
+		{Path: "repo.zip", Data: archive},
+	}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skipped != 3 {
+		t.Errorf("expected 3 skipped entries, got %d", skipped)
+	}
+
+	paths := make(map[string]bool, len(files))
+	for _, file := range files {
+		paths[file.Path] = true
+	}
+	for _, want := range []string{"notes.md", "repo.zip/repo/README.md", "repo.zip/etc/passwd.txt", "repo.zip/repo/src/main.go", "repo.zip/repo/docs/guide/install.txt"} {
+		if !paths[want] {
+			t.Errorf("missing %s in %v", want, paths)
+		}
+	}
+	if len(files) != 5 {
+		t.Errorf("expected 5 files, got %d", len(files))
+	}
+
+	big := buildZip(t, map[string]string{"big.txt": strings.Repeat("a", 4096)})
+	if _, _, err := ExpandArchives([]File{{Path: "big.zip", Data: big}}, 1024); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected size limit error, got %v", err)
+	}
+	if _, _, err := ExpandArchives([]File{{Path: "broken.zip", Data: []byte("nope")}}, 0); err == nil {
+		t.Error("expected error for a broken archive")
+	}
+}
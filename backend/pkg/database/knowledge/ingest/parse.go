@@ -0,0 +1,271 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/vxcontrol/langchaingo/textsplitter"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxTitleLength  = 200
+	maxJSONLLineLen = 16 * 1024 * 1024
+)
+
+func parseMarkdown(file File) document {
+	content := string(file.Data)
+	return document{
+		title:    markdownTitle(content, file.Path),
+		content:  content,
+		markdown: true,
+	}
+}
+
+func parseText(file File) document {
+	return document{
+		title:   fileTitle(file.Path),
+		content: string(file.Data),
+	}
+}
+
+func parseCode(file File, lang string) document {
+	return document{
+		title:    "Source code of " + file.Path,
+		content:  string(file.Data),
+		docType:  "code",
+		codeLang: lang,
+	}
+}
+
+// markdownTitle returns the text of the first level one or two heading, or
+// the file name when the document has none.
+func markdownTitle(content, filePath string) string {
+	inFence := false
+	for line := range strings.Lines(content) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for _, prefix := range []string{"# ", "## "} {
+			if title, ok := strings.CutPrefix(line, prefix); ok && strings.TrimSpace(title) != "" {
+				return truncateTitle(strings.TrimSpace(title))
+			}
+		}
+	}
+	return fileTitle(filePath)
+}
+
+func fileTitle(filePath string) string {
+	base := path.Base(filePath)
+	return truncateTitle(strings.TrimSuffix(base, path.Ext(base)))
+}
+
+func truncateTitle(title string) string {
+	title = strings.Join(strings.Fields(title), " ")
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		return string(runes[:maxTitleLength])
+	}
+	return title
+}
+
+// parseHTML converts an HTML page into Markdown-like text keeping headings,
+// paragraphs, list items and preformatted blocks, so the markdown splitter can
+// chunk it by sections.
+func parseHTML(file File) ([]document, error) {
+	root, err := html.Parse(bytes.NewReader(file.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html: %w", err)
+	}
+
+	var (
+		sb      strings.Builder
+		title   string
+		heading string
+	)
+	var walk func(n *html.Node, pre bool)
+	walk = func(n *html.Node, pre bool) {
+		switch n.Type {
+		case html.TextNode:
+			if pre {
+				sb.WriteString(n.Data)
+			} else if text := strings.Join(strings.Fields(n.Data), " "); text != "" {
+				sb.WriteString(text)
+				sb.WriteString(" ")
+			}
+			return
+		case html.ElementNode:
+		default:
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c, pre)
+			}
+			return
+		}
+
+		switch n.DataAtom {
+		case atom.Script, atom.Style, atom.Noscript, atom.Nav, atom.Footer, atom.Svg, atom.Template:
+			return
+		case atom.Title:
+			if n.FirstChild != nil && title == "" {
+				title = strings.TrimSpace(n.FirstChild.Data)
+			}
+			return
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			level := int(n.Data[1] - '0')
+			start := sb.Len()
+			sb.WriteString("\n\n" + strings.Repeat("#", level) + " ")
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c, false)
+			}
+			if heading == "" && level <= 2 {
+				heading = strings.TrimLeft(strings.TrimSpace(sb.String()[start:]), "# ")
+			}
+			sb.WriteString("\n\n")
+			return
+		case atom.Pre:
+			sb.WriteString("\n\n```\n")
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c, true)
+			}
+			sb.WriteString("\n```\n\n")
+			return
+		case atom.Li:
+			sb.WriteString("\n- ")
+		case atom.Br:
+			sb.WriteString("\n")
+		case atom.P, atom.Div, atom.Section, atom.Article, atom.Table, atom.Tr, atom.Ul, atom.Ol, atom.Blockquote:
+			sb.WriteString("\n\n")
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, pre)
+		}
+	}
+	walk(root, false)
+
+	if heading != "" {
+		title = heading
+	}
+	if title == "" {
+		title = fileTitle(file.Path)
+	}
+
+	return []document{{
+		title:    truncateTitle(title),
+		content:  normalizeBlankLines(sb.String()),
+		markdown: true,
+	}}, nil
+}
+
+func normalizeBlankLines(text string) string {
+	var (
+		sb    strings.Builder
+		blank = 0
+	)
+	for line := range strings.Lines(text) {
+		line = strings.TrimRight(line, " \t\r\n")
+		if line == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// jsonlRecord is one line of a JSONL import; only content is required.
+type jsonlRecord struct {
+	Content     string `json:"content"`
+	Question    string `json:"question"`
+	Description string `json:"description"`
+	DocType     string `json:"doc_type"`
+	GuideType   string `json:"guide_type"`
+	AnswerType  string `json:"answer_type"`
+	CodeLang    string `json:"code_lang"`
+}
+
+// parseJSONL reads one document per line; blank lines are ignored and any
+// malformed line fails the whole file.
+func parseJSONL(file File) ([]document, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(file.Data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineLen)
+
+	var docs []document
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var record jsonlRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if strings.TrimSpace(record.Content) == "" {
+			return nil, fmt.Errorf("line %d: content is required", line)
+		}
+
+		title := record.Question
+		if strings.TrimSpace(title) == "" {
+			title, _, _ = strings.Cut(strings.TrimSpace(record.Content), "\n")
+		}
+		docs = append(docs, document{
+			title:       truncateTitle(title),
+			description: strings.TrimSpace(record.Description),
+			content:     record.Content,
+			docType:     record.DocType,
+			guideType:   record.GuideType,
+			answerType:  record.AnswerType,
+			codeLang:    strings.ToLower(record.CodeLang),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read jsonl: %w", err)
+	}
+
+	return docs, nil
+}
+
+// split chunks the document content according to the splitter options.
+func split(doc document, opts Options) ([]string, error) {
+	content := strings.TrimSpace(doc.content)
+	if opts.Splitter == SplitterNone || len([]rune(content)) <= opts.ChunkSize {
+		return []string{content}, nil
+	}
+
+	var splitter textsplitter.TextSplitter
+	if opts.Splitter == SplitterMarkdown || (opts.Splitter == SplitterAuto && doc.markdown) {
+		splitter = textsplitter.NewMarkdownTextSplitter(
+			textsplitter.WithChunkSize(opts.ChunkSize),
+			textsplitter.WithChunkOverlap(opts.ChunkOverlap),
+			textsplitter.WithCodeBlocks(true),
+			textsplitter.WithHeadingHierarchy(true),
+		)
+	} else {
+		splitter = textsplitter.NewRecursiveCharacter(
+			textsplitter.WithChunkSize(opts.ChunkSize),
+			textsplitter.WithChunkOverlap(opts.ChunkOverlap),
+		)
+	}
+
+	parts, err := splitter.SplitText(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split document: %w", err)
+	}
+
+	return parts, nil
+}
@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"pentagi/pkg/database"
 	"pentagi/pkg/graph/model"
@@ -42,6 +44,7 @@ type KnowledgeStore interface {
 
 	// User-scoped reads (filter by user_id in cmetadata)
 	ListUserDocuments(ctx context.Context, userID int64, filter *model.KnowledgeFilter, withContent bool) ([]*model.KnowledgeDocument, error)
+	ListProjectDocuments(ctx context.Context, projectID int64, withContent bool) ([]*model.KnowledgeDocument, error)
 	GetUserDocument(ctx context.Context, userID int64, id string) (*model.KnowledgeDocument, error)
 	SearchUserDocuments(ctx context.Context, userID int64, query string, filter *model.KnowledgeFilter, limit int) ([]*model.KnowledgeDocumentWithScore, error)
 
@@ -53,6 +56,38 @@ type KnowledgeStore interface {
 	RenameUserDocument(ctx context.Context, userID int64, id, question string) (*model.KnowledgeDocument, error)
 	DeleteDocument(ctx context.Context, userID int64, id string) error
 	DeleteUserDocument(ctx context.Context, userID int64, id string) error
+
+	// Quality signals and lifecycle; archived and expired documents are kept
+	// but no longer retrieved
+	VoteDocument(ctx context.Context, userID int64, id string, vote *model.KnowledgeVote) (*model.KnowledgeDocument, error)
+	VoteUserDocument(ctx context.Context, userID int64, id string, vote *model.KnowledgeVote) (*model.KnowledgeDocument, error)
+	ArchiveDocument(ctx context.Context, userID int64, id string, archived bool) (*model.KnowledgeDocument, error)
+	ArchiveUserDocument(ctx context.Context, userID int64, id string, archived bool) (*model.KnowledgeDocument, error)
+	SetDocumentExpiry(ctx context.Context, userID int64, id string, expiresAt *time.Time) (*model.KnowledgeDocument, error)
+	SetUserDocumentExpiry(ctx context.Context, userID int64, id string, expiresAt *time.Time) (*model.KnowledgeDocument, error)
+	SetDocumentProject(ctx context.Context, userID int64, id string, projectID *int64) (*model.KnowledgeDocument, error)
+	SetUserDocumentProject(ctx context.Context, userID int64, id string, projectID *int64) (*model.KnowledgeDocument, error)
+
+	// Bulk imports (admin reads are unscoped, user reads filter by owner)
+	ListImportJobs(ctx context.Context) ([]*model.KnowledgeImportJob, error)
+	ListUserImportJobs(ctx context.Context, userID int64) ([]*model.KnowledgeImportJob, error)
+	GetImportJob(ctx context.Context, id int64) (*model.KnowledgeImportJob, error)
+	GetUserImportJob(ctx context.Context, userID, id int64) (*model.KnowledgeImportJob, error)
+	StartImport(ctx context.Context, userID int64, req ImportRequest) (*model.KnowledgeImportJob, error)
+	RunImport(ctx context.Context, userID int64, req ImportRequest, progress ImportProgress) (*model.KnowledgeImportJob, error)
+	FailInterruptedImports(ctx context.Context) error
+
+	// Knowledge archives for sharing documents between instances; archive
+	// imports run as import jobs owned by the importing user
+	ExportDocuments(ctx context.Context, w io.Writer, req ExportRequest) (*ArchiveManifest, error)
+	ExportUserDocuments(ctx context.Context, userID int64, w io.Writer, req ExportRequest) (*ArchiveManifest, error)
+	StartArchiveImport(ctx context.Context, userID int64, req ArchiveImportRequest) (*model.KnowledgeImportJob, error)
+	RunArchiveImport(ctx context.Context, userID int64, req ArchiveImportRequest, progress ImportProgress) (*model.KnowledgeImportJob, error)
+
+	// Re-embedding of stored vectors after the embedding model changes
+	GetEmbeddingStatus(ctx context.Context) (*model.EmbeddingStatus, error)
+	CheckEmbeddingModel(ctx context.Context) (*model.EmbeddingStatus, error)
+	StartEmbeddingMigration(ctx context.Context) (*model.EmbeddingMigration, error)
 }
 
 type knowledgeStore struct {
@@ -61,6 +96,8 @@ type knowledgeStore struct {
 	embedder          embeddings.Embedder      // used for computing new embeddings on create/update
 	newKnp            PublisherFactory
 	maxEmbeddingBytes int
+	hybrid            database.HybridSearchConfig
+	reembed           ReembedConfig
 }
 
 // NewKnowledgeStore constructs a KnowledgeStore.
@@ -72,12 +109,16 @@ type knowledgeStore struct {
 //   - maxEmbeddingBytes is the maximum byte size of text sent to the embedding
 //     model. Text is truncated to this limit before embedding to avoid token
 //     limit errors; the full original text is always stored in the database.
+//   - hybrid enables fusing vector similarity with full-text rankings in search.
+//   - reembed throttles migrations of stored vectors to a new embedding model.
 func NewKnowledgeStore(
 	db database.Querier,
 	store vectorstores.VectorStore,
 	embedder embeddings.Embedder,
 	newKnp PublisherFactory,
 	maxEmbeddingBytes int,
+	hybrid database.HybridSearchConfig,
+	reembed ReembedConfig,
 ) KnowledgeStore {
 	if maxEmbeddingBytes <= 0 {
 		maxEmbeddingBytes = 8192
@@ -88,6 +129,8 @@ func NewKnowledgeStore(
 		embedder:          embedder,
 		newKnp:            newKnp,
 		maxEmbeddingBytes: maxEmbeddingBytes,
+		hybrid:            hybrid,
+		reembed:           reembed,
 	}
 }
 
@@ -100,6 +143,7 @@ type knowledgeMeta struct {
 	FlowID      *int64 `json:"flow_id,omitempty"`
 	TaskID      *int64 `json:"task_id,omitempty"`
 	SubtaskID   *int64 `json:"subtask_id,omitempty"`
+	ProjectID   *int64 `json:"project_id,omitempty"`
 	Question    string `json:"question,omitempty"`
 	Description string `json:"description,omitempty"`
 	GuideType   string `json:"guide_type,omitempty"`
@@ -108,6 +152,18 @@ type knowledgeMeta struct {
 	PartSize    int    `json:"part_size,omitempty"`
 	TotalSize   int    `json:"total_size,omitempty"`
 	Manual      bool   `json:"manual,omitempty"`
+
+	// Archived and expired documents are kept but no longer retrieved.
+	Archived  bool       `json:"archived,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Provenance of documents imported from a knowledge archive.
+	OriginInstance string     `json:"origin_instance,omitempty"`
+	OriginTenant   string     `json:"origin_tenant,omitempty"`
+	OriginID       string     `json:"origin_id,omitempty"`
+	OriginModel    string     `json:"origin_model,omitempty"`
+	ExportedAt     *time.Time `json:"exported_at,omitempty"`
+	ImportedAt     *time.Time `json:"imported_at,omitempty"`
 }
 
 func parseMeta(raw string) knowledgeMeta {
@@ -166,6 +222,8 @@ func metaToModelDoc(id, content string, meta knowledgeMeta) *model.KnowledgeDocu
 		PartSize:  meta.PartSize,
 		TotalSize: meta.TotalSize,
 		Manual:    meta.Manual,
+		Archived:  meta.Archived,
+		ExpiresAt: meta.ExpiresAt,
 	}
 
 	switch meta.DocType {
@@ -188,6 +246,9 @@ func metaToModelDoc(id, content string, meta knowledgeMeta) *model.KnowledgeDocu
 	if meta.SubtaskID != nil {
 		doc.SubtaskID = meta.SubtaskID
 	}
+	if meta.ProjectID != nil {
+		doc.ProjectID = meta.ProjectID
+	}
 	if meta.Description != "" {
 		d := meta.Description
 		doc.Description = &d
@@ -204,9 +265,34 @@ func metaToModelDoc(id, content string, meta knowledgeMeta) *model.KnowledgeDocu
 		cl := meta.CodeLang
 		doc.CodeLang = &cl
 	}
+	if meta.OriginInstance != "" && meta.OriginID != "" {
+		doc.Provenance = metaToProvenance(meta)
+	}
 	return doc
 }
 
+func metaToProvenance(meta knowledgeMeta) *model.KnowledgeProvenance {
+	p := &model.KnowledgeProvenance{
+		InstanceID: meta.OriginInstance,
+		DocumentID: meta.OriginID,
+	}
+	if meta.OriginTenant != "" {
+		tenant := meta.OriginTenant
+		p.TenantID = &tenant
+	}
+	if meta.OriginModel != "" {
+		em := meta.OriginModel
+		p.EmbeddingModel = &em
+	}
+	if meta.ExportedAt != nil {
+		p.ExportedAt = *meta.ExportedAt
+	}
+	if meta.ImportedAt != nil {
+		p.ImportedAt = *meta.ImportedAt
+	}
+	return p
+}
+
 // applyGoFilters applies optional filters that cannot be expressed as single SQL predicates.
 func applyGoFilters(docs []*model.KnowledgeDocument, filter *model.KnowledgeFilter) []*model.KnowledgeDocument {
 	if filter == nil {
@@ -229,6 +315,9 @@ func applyGoFilters(docs []*model.KnowledgeDocument, filter *model.KnowledgeFilt
 		if filter.Manual != nil && doc.Manual != *filter.Manual {
 			continue
 		}
+		if filter.Archived != nil && doc.Archived != *filter.Archived {
+			continue
+		}
 		result = append(result, doc)
 	}
 	return result
@@ -271,7 +360,11 @@ func (ks *knowledgeStore) ListDocuments(ctx context.Context, filter *model.Knowl
 		}
 	}
 
-	return applyGoFilters(docs, filter), nil
+	docs = applyGoFilters(docs, filter)
+	if err := ks.attachFeedback(ctx, docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
 }
 
 // ---- ListUserDocuments (user-scoped) ----------------------------------------
@@ -303,7 +396,30 @@ func (ks *knowledgeStore) ListUserDocuments(ctx context.Context, userID int64, f
 		}
 	}
 
-	return applyGoFilters(docs, filter), nil
+	docs = applyGoFilters(docs, filter)
+	if err := ks.attachFeedback(ctx, docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// ---- ListProjectDocuments (project-scoped) ----------------------------------
+
+func (ks *knowledgeStore) ListProjectDocuments(ctx context.Context, projectID int64, withContent bool) ([]*model.KnowledgeDocument, error) {
+	rows, err := ks.db.ListProjectKnowledgeDocuments(ctx, nsOf(strconv.FormatInt(projectID, 10)))
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: list project docs: %w", err)
+	}
+
+	docs := make([]*model.KnowledgeDocument, 0, len(rows))
+	for _, r := range rows {
+		docs = append(docs, rowToModel(r.ID, r.Document, nullStr(r.Cmetadata), withContent))
+	}
+
+	if err := ks.attachFeedback(ctx, docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
 }
 
 // ---- GetDocument (admin) ----------------------------------------------------
@@ -313,7 +429,11 @@ func (ks *knowledgeStore) GetDocument(ctx context.Context, id string) (*model.Kn
 	if err != nil {
 		return nil, fmt.Errorf("knowledge: get document %s: %w", id, err)
 	}
-	return rowToModel(row.ID, row.Document, nullStr(row.Cmetadata), true), nil
+	doc := rowToModel(row.ID, row.Document, nullStr(row.Cmetadata), true)
+	if err := ks.attachFeedback(ctx, []*model.KnowledgeDocument{doc}); err != nil {
+		return nil, err
+	}
+	return doc, nil
 }
 
 // ---- GetUserDocument (user-scoped) ------------------------------------------
@@ -326,7 +446,11 @@ func (ks *knowledgeStore) GetUserDocument(ctx context.Context, userID int64, id
 	if err != nil {
 		return nil, fmt.Errorf("knowledge: get user document %s: %w", id, err)
 	}
-	return rowToModel(row.ID, row.Document, nullStr(row.Cmetadata), true), nil
+	doc := rowToModel(row.ID, row.Document, nullStr(row.Cmetadata), true)
+	if err := ks.attachFeedback(ctx, []*model.KnowledgeDocument{doc}); err != nil {
+		return nil, err
+	}
+	return doc, nil
 }
 
 // ---- SearchDocuments (admin) ------------------------------------------------
@@ -342,7 +466,10 @@ func (ks *knowledgeStore) SearchUserDocuments(ctx context.Context, userID int64,
 }
 
 // doSearch performs a parameterised vector similarity search and returns
-// matched documents with their cosine-similarity scores and correct UUIDs.
+// matched documents with their scores and correct UUIDs. When hybrid search
+// is enabled the vector ranking is fused with a full-text ranking over the
+// same documents (see database.FuseRankings) and scores are fused ones.
+// Scores are finally scaled by the documents' quality signals.
 //
 // Filters are applied in two layers:
 //  1. SQL layer  — user_id ownership (when userID > 0); cosine-distance
@@ -364,7 +491,60 @@ func (ks *knowledgeStore) doSearch(ctx context.Context, userID int64, query stri
 		query = query[:ks.maxEmbeddingBytes]
 	}
 
-	// Compute query embedding.
+	candidates := ks.hybrid.Candidates(limit)
+
+	vectorRows, err := ks.vectorSearch(ctx, userID, query, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if !ks.hybrid.Enabled {
+		return ks.rankByQuality(ctx, filterSearchRows(vectorRows, filter, candidates), limit)
+	}
+
+	textRows, err := ks.textSearch(ctx, userID, query, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	vectorDocs := filterSearchRows(vectorRows, filter, candidates)
+	textDocs := filterSearchRows(textRows, filter, candidates)
+
+	docs := make(map[string]*model.KnowledgeDocument, len(vectorDocs)+len(textDocs))
+	vectorKeys := make([]string, 0, len(vectorDocs))
+	for _, r := range vectorDocs {
+		docs[r.Document.ID] = r.Document
+		vectorKeys = append(vectorKeys, r.Document.ID)
+	}
+	textKeys := make([]string, 0, len(textDocs))
+	for _, r := range textDocs {
+		docs[r.Document.ID] = r.Document
+		textKeys = append(textKeys, r.Document.ID)
+	}
+
+	fused := database.FuseRankings(vectorKeys, textKeys, ks.hybrid.K, func(id string) float64 {
+		return ks.hybrid.TextWeightFor(string(docs[id].DocType))
+	})
+
+	results := make([]*model.KnowledgeDocumentWithScore, 0, len(fused))
+	for _, rank := range fused {
+		results = append(results, &model.KnowledgeDocumentWithScore{
+			Score:    rank.Score,
+			Document: docs[rank.Key],
+		})
+	}
+	return ks.rankByQuality(ctx, results, limit)
+}
+
+type searchRow struct {
+	ID        string
+	Document  string
+	Cmetadata sql.NullString
+	Score     float64
+}
+
+// vectorSearch embeds the query and returns rows above the cosine-similarity
+// threshold, best first.
+func (ks *knowledgeStore) vectorSearch(ctx context.Context, userID int64, query string, limit int) ([]searchRow, error) {
 	vecs, err := ks.embedder.EmbedDocuments(ctx, []string{query})
 	if err != nil {
 		return nil, fmt.Errorf("knowledge: embed query: %w", err)
@@ -378,15 +558,6 @@ func (ks *knowledgeStore) doSearch(ctx context.Context, userID int64, query stri
 	// distance = 1 - similarity, so maxDist = 1 - threshold.
 	maxDist := float64(1.0 - defaultSearchThreshold)
 
-	type searchRow struct {
-		ID        string
-		Document  string
-		Cmetadata sql.NullString
-		Score     float64
-	}
-
-	var rawRows []searchRow
-
 	if userID > 0 {
 		rows, err := ks.db.SearchUserKnowledgeDocuments(ctx, database.SearchUserKnowledgeDocumentsParams{
 			Embedding:   vecLiteral,
@@ -397,27 +568,59 @@ func (ks *knowledgeStore) doSearch(ctx context.Context, userID int64, query stri
 		if err != nil {
 			return nil, fmt.Errorf("knowledge: similarity search (user %d): %w", userID, err)
 		}
-		rawRows = make([]searchRow, len(rows))
-		for i, r := range rows {
-			rawRows[i] = searchRow{r.ID, r.Document, r.Cmetadata, r.Score}
-		}
-	} else {
-		rows, err := ks.db.SearchKnowledgeDocuments(ctx, database.SearchKnowledgeDocumentsParams{
-			Embedding:   vecLiteral,
-			MaxDistance: maxDist,
-			Lim:         int32(limit), //nolint:gosec
-		})
-		if err != nil {
-			return nil, fmt.Errorf("knowledge: similarity search (admin): %w", err)
-		}
-		rawRows = make([]searchRow, len(rows))
+		rawRows := make([]searchRow, len(rows))
 		for i, r := range rows {
 			rawRows[i] = searchRow{r.ID, r.Document, r.Cmetadata, r.Score}
 		}
+		return rawRows, nil
 	}
 
-	results := make([]*model.KnowledgeDocumentWithScore, 0, len(rawRows))
-	for _, r := range rawRows {
+	rows, err := ks.db.SearchKnowledgeDocuments(ctx, database.SearchKnowledgeDocumentsParams{
+		Embedding:   vecLiteral,
+		MaxDistance: maxDist,
+		Lim:         int32(limit), //nolint:gosec
+	})
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: similarity search (admin): %w", err)
+	}
+	rawRows := make([]searchRow, len(rows))
+	for i, r := range rows {
+		rawRows[i] = searchRow{r.ID, r.Document, r.Cmetadata, r.Score}
+	}
+	return rawRows, nil
+}
+
+// textSearch returns full-text and trigram matches for the query, best first.
+func (ks *knowledgeStore) textSearch(ctx context.Context, userID int64, query string, limit int) ([]searchRow, error) {
+	params := database.SearchKnowledgeDocumentsTextParams{
+		Query:   query,
+		Filters: json.RawMessage(`{}`),
+		Lim:     int32(limit), //nolint:gosec
+	}
+	if userID > 0 {
+		params.UserID = strconv.FormatInt(userID, 10)
+	}
+
+	rows, err := ks.db.SearchKnowledgeDocumentsText(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: full-text search: %w", err)
+	}
+
+	rawRows := make([]searchRow, len(rows))
+	for i, r := range rows {
+		rawRows[i] = searchRow{r.ID, r.Document, r.Cmetadata, r.Score}
+	}
+	return rawRows, nil
+}
+
+// filterSearchRows converts rows to models, keeps those passing the Go-side
+// filters and stops after limit matches.
+func filterSearchRows(rows []searchRow, filter *model.KnowledgeFilter, limit int) []*model.KnowledgeDocumentWithScore {
+	results := make([]*model.KnowledgeDocumentWithScore, 0, min(len(rows), limit))
+	for _, r := range rows {
+		if len(results) >= limit {
+			break
+		}
 		doc := rowToModel(r.ID, r.Document, nullStr(r.Cmetadata), true)
 		if !passesSearchFilter(doc, filter) {
 			continue
@@ -427,7 +630,7 @@ func (ks *knowledgeStore) doSearch(ctx context.Context, userID int64, query stri
 			Document: doc,
 		})
 	}
-	return results, nil
+	return results
 }
 
 // passesSearchFilter checks the Go-side filters that cannot be expressed as
@@ -454,6 +657,9 @@ func passesSearchFilter(doc *model.KnowledgeDocument, filter *model.KnowledgeFil
 	if filter.Manual != nil && doc.Manual != *filter.Manual {
 		return false
 	}
+	if filter.Archived != nil && doc.Archived != *filter.Archived {
+		return false
+	}
 	return true
 }
 
@@ -465,10 +671,11 @@ func (ks *knowledgeStore) CreateDocument(ctx context.Context, userID int64, inpu
 	}
 
 	meta := knowledgeMeta{
-		DocType:  string(input.DocType),
-		UserID:   userID,
-		Question: input.Question,
-		Manual:   true,
+		DocType:   string(input.DocType),
+		UserID:    userID,
+		Question:  input.Question,
+		Manual:    true,
+		ExpiresAt: input.ExpiresAt,
 	}
 	if input.Description != nil {
 		meta.Description = *input.Description
@@ -551,6 +758,8 @@ func metaFromDoc(existing *model.KnowledgeDocument) knowledgeMeta {
 		Question:  existing.Question,
 		PartSize:  existing.PartSize,
 		TotalSize: existing.TotalSize,
+		Archived:  existing.Archived,
+		ExpiresAt: existing.ExpiresAt,
 	}
 	if existing.FlowID != nil {
 		meta.FlowID = existing.FlowID
@@ -561,6 +770,9 @@ func metaFromDoc(existing *model.KnowledgeDocument) knowledgeMeta {
 	if existing.SubtaskID != nil {
 		meta.SubtaskID = existing.SubtaskID
 	}
+	if existing.ProjectID != nil {
+		meta.ProjectID = existing.ProjectID
+	}
 	if existing.Description != nil {
 		meta.Description = *existing.Description
 	}
@@ -573,6 +785,18 @@ func metaFromDoc(existing *model.KnowledgeDocument) knowledgeMeta {
 	if existing.CodeLang != nil {
 		meta.CodeLang = *existing.CodeLang
 	}
+	if p := existing.Provenance; p != nil {
+		meta.OriginInstance = p.InstanceID
+		meta.OriginID = p.DocumentID
+		if p.TenantID != nil {
+			meta.OriginTenant = *p.TenantID
+		}
+		if p.EmbeddingModel != nil {
+			meta.OriginModel = *p.EmbeddingModel
+		}
+		exportedAt, importedAt := p.ExportedAt, p.ImportedAt
+		meta.ExportedAt, meta.ImportedAt = &exportedAt, &importedAt
+	}
 	return meta
 }
 
@@ -772,5 +996,30 @@ func metaToMap(m knowledgeMeta) map[string]any {
 	if m.SubtaskID != nil {
 		mp["subtask_id"] = *m.SubtaskID
 	}
+	if m.ProjectID != nil {
+		mp["project_id"] = *m.ProjectID
+	}
+	if m.Archived {
+		mp["archived"] = true
+	}
+	if m.ExpiresAt != nil {
+		mp["expires_at"] = *m.ExpiresAt
+	}
+	if m.OriginInstance != "" {
+		mp["origin_instance"] = m.OriginInstance
+		mp["origin_id"] = m.OriginID
+		if m.OriginTenant != "" {
+			mp["origin_tenant"] = m.OriginTenant
+		}
+		if m.OriginModel != "" {
+			mp["origin_model"] = m.OriginModel
+		}
+		if m.ExportedAt != nil {
+			mp["exported_at"] = *m.ExportedAt
+		}
+		if m.ImportedAt != nil {
+			mp["imported_at"] = *m.ImportedAt
+		}
+	}
 	return mp
 }
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"testing"
 
 	"pentagi/pkg/database"
@@ -39,6 +40,15 @@ type mockDB struct {
 	deleteUserKnowledge func(ctx context.Context, arg database.DeleteUserKnowledgeDocumentParams) error
 	searchKnowledge     func(ctx context.Context, arg database.SearchKnowledgeDocumentsParams) ([]database.SearchKnowledgeDocumentsRow, error)
 	searchUserKnowledge func(ctx context.Context, arg database.SearchUserKnowledgeDocumentsParams) ([]database.SearchUserKnowledgeDocumentsRow, error)
+	searchKnowledgeText func(ctx context.Context, arg database.SearchKnowledgeDocumentsTextParams) ([]database.SearchKnowledgeDocumentsTextRow, error)
+	existsHash          func(ctx context.Context, arg database.ExistsUserKnowledgeDocumentHashParams) (bool, error)
+	createImportJob     func(ctx context.Context, arg database.CreateKnowledgeImportJobParams) (database.KnowledgeImportJob, error)
+	updateImportJob     func(ctx context.Context, arg database.UpdateKnowledgeImportJobParams) (database.KnowledgeImportJob, error)
+	exportKnowledge     func(ctx context.Context, arg database.ExportKnowledgeDocumentsParams) ([]database.ExportKnowledgeDocumentsRow, error)
+	findConflict        func(ctx context.Context, arg database.FindUserKnowledgeDocumentConflictParams) (string, error)
+	getSignals          func(ctx context.Context, arg database.GetKnowledgeDocumentSignalsParams) ([]database.GetKnowledgeDocumentSignalsRow, error)
+	upsertVote          func(ctx context.Context, arg database.UpsertKnowledgeDocumentVoteParams) error
+	deleteVote          func(ctx context.Context, arg database.DeleteKnowledgeDocumentVoteParams) error
 }
 
 func (m *mockDB) InsertKnowledgeDocument(ctx context.Context, arg database.InsertKnowledgeDocumentParams) (string, error) {
@@ -83,6 +93,45 @@ func (m *mockDB) SearchUserKnowledgeDocuments(ctx context.Context, arg database.
 	}
 	return nil, nil
 }
+func (m *mockDB) SearchKnowledgeDocumentsText(ctx context.Context, arg database.SearchKnowledgeDocumentsTextParams) ([]database.SearchKnowledgeDocumentsTextRow, error) {
+	if m.searchKnowledgeText != nil {
+		return m.searchKnowledgeText(ctx, arg)
+	}
+	return nil, nil
+}
+func (m *mockDB) ExistsUserKnowledgeDocumentHash(ctx context.Context, arg database.ExistsUserKnowledgeDocumentHashParams) (bool, error) {
+	if m.existsHash != nil {
+		return m.existsHash(ctx, arg)
+	}
+	return false, nil
+}
+func (m *mockDB) CreateKnowledgeImportJob(ctx context.Context, arg database.CreateKnowledgeImportJobParams) (database.KnowledgeImportJob, error) {
+	return m.createImportJob(ctx, arg)
+}
+func (m *mockDB) UpdateKnowledgeImportJob(ctx context.Context, arg database.UpdateKnowledgeImportJobParams) (database.KnowledgeImportJob, error) {
+	return m.updateImportJob(ctx, arg)
+}
+func (m *mockDB) ExportKnowledgeDocuments(ctx context.Context, arg database.ExportKnowledgeDocumentsParams) ([]database.ExportKnowledgeDocumentsRow, error) {
+	return m.exportKnowledge(ctx, arg)
+}
+func (m *mockDB) FindUserKnowledgeDocumentConflict(ctx context.Context, arg database.FindUserKnowledgeDocumentConflictParams) (string, error) {
+	if m.findConflict != nil {
+		return m.findConflict(ctx, arg)
+	}
+	return "", sql.ErrNoRows
+}
+func (m *mockDB) GetKnowledgeDocumentSignals(ctx context.Context, arg database.GetKnowledgeDocumentSignalsParams) ([]database.GetKnowledgeDocumentSignalsRow, error) {
+	if m.getSignals != nil {
+		return m.getSignals(ctx, arg)
+	}
+	return nil, nil
+}
+func (m *mockDB) UpsertKnowledgeDocumentVote(ctx context.Context, arg database.UpsertKnowledgeDocumentVoteParams) error {
+	return m.upsertVote(ctx, arg)
+}
+func (m *mockDB) DeleteKnowledgeDocumentVote(ctx context.Context, arg database.DeleteKnowledgeDocumentVoteParams) error {
+	return m.deleteVote(ctx, arg)
+}
 
 // --- mockVectorStore --------------------------------------------------------
 
@@ -102,11 +151,15 @@ func (m *mockVectorStore) AddDocuments(ctx context.Context, docs []schema.Docume
 
 type mockEmbedder struct {
 	available        bool
+	provider         string
+	model            string
 	embedDocumentsFn func(ctx context.Context, texts []string) ([][]float32, error)
 	embedQueryFn     func(ctx context.Context, text string) ([]float32, error)
 }
 
 func (m *mockEmbedder) IsAvailable() bool { return m.available }
+func (m *mockEmbedder) Provider() string  { return m.provider }
+func (m *mockEmbedder) Model() string     { return m.model }
 func (m *mockEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
 	if m.embedDocumentsFn != nil {
 		return m.embedDocumentsFn(ctx, texts)
@@ -126,6 +179,7 @@ type mockPublisher struct {
 	createdDocs []*model.KnowledgeDocument
 	updatedDocs []*model.KnowledgeDocument
 	deletedDocs []*model.KnowledgeDocument
+	importJobs  []*model.KnowledgeImportJob
 	userID      int64
 }
 
@@ -140,6 +194,9 @@ func (m *mockPublisher) KnowledgeDocumentUpdated(_ context.Context, doc *model.K
 func (m *mockPublisher) KnowledgeDocumentDeleted(_ context.Context, doc *model.KnowledgeDocument) {
 	m.deletedDocs = append(m.deletedDocs, doc)
 }
+func (m *mockPublisher) KnowledgeImportJobUpdated(_ context.Context, job *model.KnowledgeImportJob) {
+	m.importJobs = append(m.importJobs, job)
+}
 
 // ============================================================================
 // Helpers
@@ -845,8 +902,8 @@ func TestSearchDocuments(t *testing.T) {
 		if gotParams.MaxDistance != float64(1.0-defaultSearchThreshold) {
 			t.Fatalf("distance threshold: want %f, got %f", float64(1.0-defaultSearchThreshold), gotParams.MaxDistance)
 		}
-		if gotParams.Lim != 7 {
-			t.Fatalf("limit: want 7, got %d", gotParams.Lim)
+		if want := int32(ks.hybrid.Candidates(7)); gotParams.Lim != want {
+			t.Fatalf("limit: want %d candidates, got %d", want, gotParams.Lim)
 		}
 		vec, ok := gotParams.Embedding.(string)
 		if !ok || len(vec) < 2 || vec[0] != '[' {
@@ -867,8 +924,8 @@ func TestSearchDocuments(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		if gotLimit != int32(defaultSearchLimit) {
-			t.Fatalf("default limit: want %d, got %d", defaultSearchLimit, gotLimit)
+		if want := int32(ks.hybrid.Candidates(defaultSearchLimit)); gotLimit != want {
+			t.Fatalf("default limit: want %d candidates, got %d", want, gotLimit)
 		}
 	})
 
@@ -958,8 +1015,8 @@ func TestSearchUserDocuments(t *testing.T) {
 		if gotParams.UserID.String != "42" {
 			t.Fatalf("userID param: want 42, got %q", gotParams.UserID.String)
 		}
-		if gotParams.Lim != 3 {
-			t.Fatalf("limit: want 3, got %d", gotParams.Lim)
+		if want := int32(ks.hybrid.Candidates(3)); gotParams.Lim != want {
+			t.Fatalf("limit: want %d candidates, got %d", want, gotParams.Lim)
 		}
 	})
 
@@ -1017,6 +1074,100 @@ func TestSearchUserDocuments(t *testing.T) {
 	})
 }
 
+func TestSearchDocumentsHybrid(t *testing.T) {
+	ctx := context.Background()
+	hybrid := database.HybridSearchConfig{
+		Enabled:     true,
+		K:           60,
+		TextWeight:  0.3,
+		TextWeights: map[string]float64{"code": 0.9},
+	}
+
+	t.Run("fuses vector and text rankings", func(t *testing.T) {
+		var gotVector database.SearchKnowledgeDocumentsParams
+		var gotText database.SearchKnowledgeDocumentsTextParams
+		db := &mockDB{
+			searchKnowledge: func(_ context.Context, arg database.SearchKnowledgeDocumentsParams) ([]database.SearchKnowledgeDocumentsRow, error) {
+				gotVector = arg
+				return []database.SearchKnowledgeDocumentsRow{
+					makeSearchRow("g1", "guide about log4j", `{"doc_type":"guide"}`, 0.9),
+					makeSearchRow("a1", "answer", `{"doc_type":"answer"}`, 0.8),
+				}, nil
+			},
+			searchKnowledgeText: func(_ context.Context, arg database.SearchKnowledgeDocumentsTextParams) ([]database.SearchKnowledgeDocumentsTextRow, error) {
+				gotText = arg
+				return []database.SearchKnowledgeDocumentsTextRow{
+					{ID: "c1", Document: "exploit CVE-2021-44228", Cmetadata: sql.NullString{String: `{"doc_type":"code"}`, Valid: true}, Score: 0.7},
+					{ID: "a1", Document: "answer", Cmetadata: sql.NullString{String: `{"doc_type":"answer"}`, Valid: true}, Score: 0.4},
+				}, nil
+			},
+		}
+		ks := &knowledgeStore{db: db, embedder: &mockEmbedder{available: true}, maxEmbeddingBytes: 8192, hybrid: hybrid}
+
+		results, err := ks.SearchDocuments(ctx, "CVE-2021-44228", nil, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotVector.Lim != 12 || gotText.Lim != 12 {
+			t.Fatalf("both rankings must fetch candidates, got %d and %d", gotVector.Lim, gotText.Lim)
+		}
+		if gotText.Query != "CVE-2021-44228" || gotText.UserID != "" || gotText.WithMemory || string(gotText.Filters) != "{}" {
+			t.Fatalf("unexpected text search params: %+v", gotText)
+		}
+		if len(results) != 3 {
+			t.Fatalf("want 3 results, got %d", len(results))
+		}
+		// a1 is found by both retrievers; code documents weight text matches
+		// at 0.9, so the exact match c1 outranks the top vector match g1
+		order := []string{results[0].Document.ID, results[1].Document.ID, results[2].Document.ID}
+		if !slices.Equal(order, []string{"a1", "c1", "g1"}) {
+			t.Fatalf("unexpected order: %v", order)
+		}
+		if results[0].Score > 1 || results[2].Score >= results[1].Score {
+			t.Fatalf("unexpected fused scores: %f, %f, %f", results[0].Score, results[1].Score, results[2].Score)
+		}
+	})
+
+	t.Run("user scope and go filters apply to text matches", func(t *testing.T) {
+		var gotText database.SearchKnowledgeDocumentsTextParams
+		db := &mockDB{
+			searchKnowledgeText: func(_ context.Context, arg database.SearchKnowledgeDocumentsTextParams) ([]database.SearchKnowledgeDocumentsTextRow, error) {
+				gotText = arg
+				return []database.SearchKnowledgeDocumentsTextRow{
+					{ID: "c1", Cmetadata: sql.NullString{String: `{"doc_type":"code","user_id":42}`, Valid: true}, Score: 0.7},
+					{ID: "g1", Cmetadata: sql.NullString{String: `{"doc_type":"guide","user_id":42}`, Valid: true}, Score: 0.5},
+				}, nil
+			},
+		}
+		ks := &knowledgeStore{db: db, embedder: &mockEmbedder{available: true}, maxEmbeddingBytes: 8192, hybrid: hybrid}
+
+		results, err := ks.SearchUserDocuments(ctx, 42, "nmap -sV", &model.KnowledgeFilter{
+			DocTypes: []model.KnowledgeDocType{model.KnowledgeDocTypeGuide},
+		}, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotText.UserID != "42" {
+			t.Fatalf("user id must be passed to text search, got %q", gotText.UserID)
+		}
+		if len(results) != 1 || results[0].Document.ID != "g1" {
+			t.Fatalf("expected only the guide document, got %d results", len(results))
+		}
+	})
+
+	t.Run("text search error propagates", func(t *testing.T) {
+		db := &mockDB{
+			searchKnowledgeText: func(_ context.Context, _ database.SearchKnowledgeDocumentsTextParams) ([]database.SearchKnowledgeDocumentsTextRow, error) {
+				return nil, errors.New("db error")
+			},
+		}
+		ks := &knowledgeStore{db: db, embedder: &mockEmbedder{available: true}, maxEmbeddingBytes: 8192, hybrid: hybrid}
+		if _, err := ks.SearchDocuments(ctx, "q", nil, 5); err == nil {
+			t.Fatal("expected error from text search")
+		}
+	})
+}
+
 // ============================================================================
 // Tests: ListDocuments / ListUserDocuments
 // ============================================================================
@@ -0,0 +1,487 @@
+package knowledge
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/model"
+	"pentagi/pkg/providers/embeddings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// knowledgeCollection is the langchain_pg_collection shared by knowledge
+// documents and agent memory.
+const knowledgeCollection = "langchain"
+
+const (
+	defaultReembedBatchSize = 64
+	defaultReembedDelay     = time.Second
+)
+
+// ReembedConfig throttles re-embedding migrations; Auto starts one on
+// startup when the configured embedding model differs from the stored one.
+type ReembedConfig struct {
+	Auto      bool
+	BatchSize int
+	Delay     time.Duration
+}
+
+// NewReembedConfig builds the migration settings from EMBEDDING_REEMBED_*
+// variables.
+func NewReembedConfig(cfg *config.Config) ReembedConfig {
+	rcfg := ReembedConfig{
+		Auto:      cfg.EmbeddingReembedAuto,
+		BatchSize: cfg.EmbeddingReembedBatchSize,
+		Delay:     time.Duration(cfg.EmbeddingReembedDelayMs) * time.Millisecond,
+	}
+	if rcfg.BatchSize <= 0 {
+		rcfg.BatchSize = defaultReembedBatchSize
+	}
+	if rcfg.Delay < 0 {
+		rcfg.Delay = defaultReembedDelay
+	}
+	return rcfg
+}
+
+// modelSwitcher is implemented by embedders that keep serving the previous
+// model while stored vectors are migrated, see embeddings.Switchable.
+type modelSwitcher interface {
+	Target() embeddings.Embedder
+	UseModel(provider, model string) error
+	Cutover()
+}
+
+// targetEmbedder returns the embedder of the configured model.
+func (ks *knowledgeStore) targetEmbedder() embeddings.Embedder {
+	if s, ok := ks.embedder.(modelSwitcher); ok {
+		return s.Target()
+	}
+	return ks.embedder
+}
+
+func embeddingModelName(provider, model string) string {
+	return provider + "/" + model
+}
+
+// ---- Status -----------------------------------------------------------------
+
+func (ks *knowledgeStore) GetEmbeddingStatus(ctx context.Context) (*model.EmbeddingStatus, error) {
+	if err := ks.requireEmbedder(); err != nil {
+		return nil, err
+	}
+
+	target := ks.targetEmbedder()
+	status := &model.EmbeddingStatus{
+		Collection:      knowledgeCollection,
+		ConfiguredModel: embeddingModelName(target.Provider(), target.Model()),
+		QueryModel:      embeddingModelName(ks.embedder.Provider(), ks.embedder.Model()),
+	}
+
+	coll, err := ks.db.GetEmbeddingCollection(ctx, knowledgeCollection)
+	switch {
+	case err == nil:
+		status.StoredModel = embeddingModelName(coll.Provider, coll.Model)
+		status.Dimensions = int(coll.Dimensions)
+		status.Mismatch = status.StoredModel != status.ConfiguredModel
+	case errors.Is(err, sql.ErrNoRows):
+		// nothing recorded yet, the startup check records the configured model
+		status.StoredModel = status.ConfiguredModel
+	default:
+		return nil, fmt.Errorf("knowledge: get embedding collection: %w", err)
+	}
+
+	job, err := ks.db.GetLatestEmbeddingMigration(ctx, knowledgeCollection)
+	switch {
+	case err == nil:
+		status.Migration = embeddingMigrationToModel(job)
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("knowledge: get embedding migration: %w", err)
+	}
+
+	return status, nil
+}
+
+// ---- Startup check ----------------------------------------------------------
+
+// CheckEmbeddingModel compares the configured embedding model with the one
+// that produced the stored vectors. A migration interrupted by a restart is
+// resumed; on mismatch a new one is started when ReembedConfig.Auto is set.
+// Collections without a recorded model are assumed to match the configured one.
+func (ks *knowledgeStore) CheckEmbeddingModel(ctx context.Context) (*model.EmbeddingStatus, error) {
+	if err := ks.requireEmbedder(); err != nil {
+		return nil, err
+	}
+
+	target := ks.targetEmbedder()
+	provider, modelName := target.Provider(), target.Model()
+
+	jobs, err := ks.db.GetRunningEmbeddingMigrations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: get running embedding migrations: %w", err)
+	}
+	for _, job := range jobs {
+		if job.Collection != knowledgeCollection {
+			continue
+		}
+		if job.TargetProvider == provider && job.TargetModel == modelName {
+			logrus.WithField("migration_id", job.ID).Info("resuming embedding migration interrupted by a restart")
+			ks.useSourceModel(job.SourceProvider, job.SourceModel)
+			go ks.runEmbeddingMigration(context.Background(), job)
+			return ks.GetEmbeddingStatus(ctx)
+		}
+
+		// the model was changed again before the migration completed
+		if _, err := ks.db.UpdateEmbeddingMigration(ctx, database.UpdateEmbeddingMigrationParams{
+			Status:           database.EmbeddingMigrationStatusFailed,
+			TargetDimensions: job.TargetDimensions,
+			TotalDocs:        job.TotalDocs,
+			ProcessedDocs:    job.ProcessedDocs,
+			FailedDocs:       job.FailedDocs,
+			Error:            nsOf("embedding model changed before the migration completed"),
+			ID:               job.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("knowledge: fail embedding migration %d: %w", job.ID, err)
+		}
+	}
+
+	coll, err := ks.db.GetEmbeddingCollection(ctx, knowledgeCollection)
+	if errors.Is(err, sql.ErrNoRows) {
+		stats, err := ks.collectionStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := ks.db.UpsertEmbeddingCollection(ctx, database.UpsertEmbeddingCollectionParams{
+			Name:       knowledgeCollection,
+			Provider:   provider,
+			Model:      modelName,
+			Dimensions: stats.Dimensions,
+		}); err != nil {
+			return nil, fmt.Errorf("knowledge: record embedding model: %w", err)
+		}
+		logrus.WithFields(logrus.Fields{
+			"model":     embeddingModelName(provider, modelName),
+			"documents": stats.TotalDocs,
+		}).Info("recorded embedding model of the knowledge collection")
+		return ks.GetEmbeddingStatus(ctx)
+	} else if err != nil {
+		return nil, fmt.Errorf("knowledge: get embedding collection: %w", err)
+	}
+
+	if coll.Provider == provider && coll.Model == modelName {
+		return ks.GetEmbeddingStatus(ctx)
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"stored":     embeddingModelName(coll.Provider, coll.Model),
+		"configured": embeddingModelName(provider, modelName),
+	})
+	if !ks.reembed.Auto {
+		logger.Warn("embedding model changed, stored vectors are incompatible until the startEmbeddingMigration mutation is run")
+		return ks.GetEmbeddingStatus(ctx)
+	}
+
+	logger.Warn("embedding model changed, re-embedding stored documents in the background")
+	if _, err := ks.StartEmbeddingMigration(ctx); err != nil {
+		return nil, err
+	}
+	return ks.GetEmbeddingStatus(ctx)
+}
+
+// ---- Migration --------------------------------------------------------------
+
+// StartEmbeddingMigration re-embeds the stored documents with the configured
+// model in the background. Partial vectors of a failed migration between the
+// same models are kept, so a restarted migration resumes where it stopped.
+func (ks *knowledgeStore) StartEmbeddingMigration(ctx context.Context) (*model.EmbeddingMigration, error) {
+	if err := ks.requireEmbedder(); err != nil {
+		return nil, err
+	}
+
+	target := ks.targetEmbedder()
+	provider, modelName := target.Provider(), target.Model()
+
+	coll, err := ks.db.GetEmbeddingCollection(ctx, knowledgeCollection)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && coll.Provider == provider && coll.Model == modelName) {
+		return nil, fmt.Errorf("knowledge: embedding model is up to date")
+	} else if err != nil {
+		return nil, fmt.Errorf("knowledge: get embedding collection: %w", err)
+	}
+
+	latest, err := ks.db.GetLatestEmbeddingMigration(ctx, knowledgeCollection)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("knowledge: get embedding migration: %w", err)
+	}
+	if err == nil && latest.Status == database.EmbeddingMigrationStatusRunning {
+		return nil, fmt.Errorf("knowledge: embedding migration %d is already running", latest.ID)
+	}
+	resume := err == nil && latest.Status == database.EmbeddingMigrationStatusFailed &&
+		latest.SourceProvider == coll.Provider && latest.SourceModel == coll.Model &&
+		latest.TargetProvider == provider && latest.TargetModel == modelName
+	if !resume {
+		if err := ks.db.ResetReembedVectors(ctx, knowledgeCollection); err != nil {
+			return nil, fmt.Errorf("knowledge: reset re-embedded vectors: %w", err)
+		}
+	}
+
+	stats, err := ks.collectionStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := ks.db.CreateEmbeddingMigration(ctx, database.CreateEmbeddingMigrationParams{
+		Collection:       knowledgeCollection,
+		SourceProvider:   coll.Provider,
+		SourceModel:      coll.Model,
+		SourceDimensions: coll.Dimensions,
+		TargetProvider:   provider,
+		TargetModel:      modelName,
+		TotalDocs:        stats.TotalDocs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("knowledge: create embedding migration: %w", err)
+	}
+	if resume {
+		job.FailedDocs = latest.FailedDocs
+		job.ProcessedDocs = stats.TotalDocs - stats.PendingDocs
+	}
+
+	ks.useSourceModel(coll.Provider, coll.Model)
+	go func() {
+		// the request context ends with the mutation, the migration must outlive it
+		ks.runEmbeddingMigration(context.Background(), job)
+	}()
+
+	return embeddingMigrationToModel(job), nil
+}
+
+// useSourceModel keeps queries and new documents on the stored model while a
+// migration runs. Without it searches compare vectors of different models
+// and only full-text ranking stays meaningful until the migration completes.
+func (ks *knowledgeStore) useSourceModel(provider, modelName string) {
+	s, ok := ks.embedder.(modelSwitcher)
+	if !ok {
+		return
+	}
+	if err := s.UseModel(provider, modelName); err != nil {
+		logrus.WithError(err).Warn("vector search is degraded until the embedding migration completes")
+	}
+}
+
+func (ks *knowledgeStore) collectionStats(ctx context.Context) (database.GetEmbeddingCollectionStatsRow, error) {
+	stats, err := ks.db.GetEmbeddingCollectionStats(ctx, knowledgeCollection)
+	if errors.Is(err, sql.ErrNoRows) {
+		// langchaingo creates the collection with the first vector store
+		return database.GetEmbeddingCollectionStatsRow{}, nil
+	} else if err != nil {
+		return stats, fmt.Errorf("knowledge: get embedding collection stats: %w", err)
+	}
+	return stats, nil
+}
+
+// runEmbeddingMigration builds vectors of the target model batch by batch,
+// pausing between batches. Once no document is left it switches new writes
+// and queries to the target model, embeds documents added meanwhile and moves
+// the new vectors into place.
+func (ks *knowledgeStore) runEmbeddingMigration(ctx context.Context, job database.EmbeddingMigration) (database.EmbeddingMigration, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"migration_id": job.ID,
+		"source":       embeddingModelName(job.SourceProvider, job.SourceModel),
+		"target":       embeddingModelName(job.TargetProvider, job.TargetModel),
+	})
+	fail := func(cause error) (database.EmbeddingMigration, error) {
+		logger.WithError(cause).Error("embedding migration failed")
+		job.Status = database.EmbeddingMigrationStatusFailed
+		job.Error = nsOf(cause.Error())
+		if _, err := ks.updateEmbeddingMigration(ctx, job); err != nil {
+			logger.WithError(err).Error("failed to record embedding migration failure")
+		}
+		return job, cause
+	}
+
+	batchSize, delay := ks.reembed.BatchSize, ks.reembed.Delay
+	if batchSize <= 0 {
+		batchSize = defaultReembedBatchSize
+	}
+	if delay < 0 {
+		delay = defaultReembedDelay
+	}
+
+	target := ks.targetEmbedder()
+	switcher, _ := ks.embedder.(modelSwitcher)
+	cutover := switcher == nil
+
+	for {
+		docs, err := ks.db.GetPendingReembedDocuments(ctx, database.GetPendingReembedDocumentsParams{
+			Collection: knowledgeCollection,
+			Lim:        int32(batchSize),
+		})
+		if err != nil {
+			return fail(fmt.Errorf("knowledge: get pending documents: %w", err))
+		}
+		if len(docs) == 0 {
+			if cutover {
+				break
+			}
+			switcher.Cutover()
+			cutover = true
+			continue
+		}
+
+		if err := ks.reembedBatch(ctx, target, &job, docs); err != nil {
+			return fail(err)
+		}
+
+		stats, err := ks.collectionStats(ctx)
+		if err != nil {
+			return fail(err)
+		}
+		job.TotalDocs = stats.TotalDocs
+		job.ProcessedDocs = stats.TotalDocs - stats.PendingDocs
+		if job, err = ks.updateEmbeddingMigration(ctx, job); err != nil {
+			return fail(err)
+		}
+
+		if delay > 0 && !cutover {
+			select {
+			case <-ctx.Done():
+				return fail(ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+	}
+
+	completed, err := ks.db.CompleteEmbeddingMigration(ctx, job.ID)
+	if err != nil {
+		return fail(fmt.Errorf("knowledge: complete embedding migration: %w", err))
+	}
+
+	logger.WithFields(logrus.Fields{
+		"documents": completed.ProcessedDocs,
+		"failed":    completed.FailedDocs,
+	}).Info("embedding migration completed")
+
+	return completed, nil
+}
+
+// reembedBatch stores target vectors of a batch. When the batch request fails
+// every document is retried alone; documents that still fail keep their old
+// vector unless none of the batch could be embedded, which fails the migration.
+func (ks *knowledgeStore) reembedBatch(
+	ctx context.Context,
+	target embeddings.Embedder,
+	job *database.EmbeddingMigration,
+	docs []database.GetPendingReembedDocumentsRow,
+) error {
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		text := doc.Document
+		if len(text) > ks.maxEmbeddingBytes {
+			text = text[:ks.maxEmbeddingBytes]
+		}
+		texts[i] = text
+	}
+
+	vecs, err := target.EmbedDocuments(ctx, texts)
+	if err == nil && len(vecs) != len(docs) {
+		err = fmt.Errorf("embedder returned %d vectors for %d documents", len(vecs), len(docs))
+	}
+	if err == nil {
+		for i, doc := range docs {
+			if err := ks.setReembedVector(ctx, job, doc.ID, vecs[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var (
+		embedded int
+		rejected []string
+	)
+	for i, doc := range docs {
+		var vecs [][]float32
+		vecs, err = target.EmbedDocuments(ctx, texts[i:i+1])
+		if err == nil && len(vecs) == 1 {
+			if err := ks.setReembedVector(ctx, job, doc.ID, vecs[0]); err != nil {
+				return err
+			}
+			embedded++
+			continue
+		}
+		logrus.WithError(err).WithField("id", doc.ID).Warn("failed to re-embed knowledge document")
+		rejected = append(rejected, doc.ID)
+	}
+	if embedded == 0 {
+		// the provider is likely down, leave the batch pending for a resume
+		return fmt.Errorf("knowledge: embedding provider failed: %w", err)
+	}
+
+	for _, id := range rejected {
+		if err := ks.db.KeepReembedDocumentVector(ctx, nsOf(id)); err != nil {
+			return fmt.Errorf("knowledge: keep document vector: %w", err)
+		}
+		job.FailedDocs++
+	}
+
+	return nil
+}
+
+func (ks *knowledgeStore) setReembedVector(ctx context.Context, job *database.EmbeddingMigration, id string, vec []float32) error {
+	if job.TargetDimensions == 0 {
+		job.TargetDimensions = int32(len(vec))
+	}
+	err := ks.db.SetReembedDocumentVector(ctx, database.SetReembedDocumentVectorParams{
+		Embedding: formatVector(vec),
+		Uuid:      nsOf(id),
+	})
+	if err != nil {
+		return fmt.Errorf("knowledge: store document vector: %w", err)
+	}
+	return nil
+}
+
+func (ks *knowledgeStore) updateEmbeddingMigration(ctx context.Context, job database.EmbeddingMigration) (database.EmbeddingMigration, error) {
+	updated, err := ks.db.UpdateEmbeddingMigration(ctx, database.UpdateEmbeddingMigrationParams{
+		Status:           job.Status,
+		TargetDimensions: job.TargetDimensions,
+		TotalDocs:        job.TotalDocs,
+		ProcessedDocs:    job.ProcessedDocs,
+		FailedDocs:       job.FailedDocs,
+		Error:            job.Error,
+		ID:               job.ID,
+	})
+	if err != nil {
+		return job, fmt.Errorf("knowledge: update embedding migration: %w", err)
+	}
+	return updated, nil
+}
+
+func embeddingMigrationToModel(job database.EmbeddingMigration) *model.EmbeddingMigration {
+	result := &model.EmbeddingMigration{
+		ID:               job.ID,
+		Collection:       job.Collection,
+		Status:           model.EmbeddingMigrationStatus(job.Status),
+		SourceModel:      embeddingModelName(job.SourceProvider, job.SourceModel),
+		SourceDimensions: int(job.SourceDimensions),
+		TargetModel:      embeddingModelName(job.TargetProvider, job.TargetModel),
+		TargetDimensions: int(job.TargetDimensions),
+		TotalDocs:        int(job.TotalDocs),
+		ProcessedDocs:    int(job.ProcessedDocs),
+		FailedDocs:       int(job.FailedDocs),
+		CreatedAt:        job.CreatedAt.Time,
+		UpdatedAt:        job.UpdatedAt.Time,
+	}
+	if job.Error.Valid {
+		result.Error = &job.Error.String
+	}
+	if job.FinishedAt.Valid {
+		result.FinishedAt = &job.FinishedAt.Time
+	}
+	return result
+}
@@ -0,0 +1,256 @@
+package knowledge
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/providers/embeddings"
+)
+
+// reembedDB keeps the langchain collection in memory: documents without a
+// new vector are pending until SetReembedDocumentVector or
+// KeepReembedDocumentVector is called for them.
+type reembedDB struct {
+	*mockDB
+
+	coll   *database.EmbeddingCollection
+	job    database.EmbeddingMigration
+	docs   []database.GetPendingReembedDocumentsRow
+	next   map[string]string
+	kept   map[string]bool
+	resets int
+}
+
+func newReembedDB(docs ...string) *reembedDB {
+	db := &reembedDB{mockDB: &mockDB{}, next: map[string]string{}, kept: map[string]bool{}}
+	for i, doc := range docs {
+		db.docs = append(db.docs, database.GetPendingReembedDocumentsRow{ID: string(rune('a' + i)), Document: doc})
+	}
+	return db
+}
+
+func (db *reembedDB) GetEmbeddingCollection(_ context.Context, _ string) (database.EmbeddingCollection, error) {
+	if db.coll == nil {
+		return database.EmbeddingCollection{}, sql.ErrNoRows
+	}
+	return *db.coll, nil
+}
+func (db *reembedDB) UpsertEmbeddingCollection(_ context.Context, arg database.UpsertEmbeddingCollectionParams) (database.EmbeddingCollection, error) {
+	db.coll = &database.EmbeddingCollection{Name: arg.Name, Provider: arg.Provider, Model: arg.Model, Dimensions: arg.Dimensions}
+	return *db.coll, nil
+}
+func (db *reembedDB) GetEmbeddingCollectionStats(_ context.Context, _ string) (database.GetEmbeddingCollectionStatsRow, error) {
+	pending := len(db.docs) - len(db.next)
+	return database.GetEmbeddingCollectionStatsRow{
+		TotalDocs:   int64(len(db.docs)),
+		PendingDocs: int64(pending),
+		Dimensions:  3,
+	}, nil
+}
+func (db *reembedDB) GetRunningEmbeddingMigrations(_ context.Context) ([]database.EmbeddingMigration, error) {
+	return nil, nil
+}
+func (db *reembedDB) GetLatestEmbeddingMigration(_ context.Context, _ string) (database.EmbeddingMigration, error) {
+	if db.job.ID == 0 {
+		return db.job, sql.ErrNoRows
+	}
+	return db.job, nil
+}
+func (db *reembedDB) GetPendingReembedDocuments(_ context.Context, arg database.GetPendingReembedDocumentsParams) ([]database.GetPendingReembedDocumentsRow, error) {
+	var rows []database.GetPendingReembedDocumentsRow
+	for _, doc := range db.docs {
+		if _, ok := db.next[doc.ID]; !ok && len(rows) < int(arg.Lim) {
+			rows = append(rows, doc)
+		}
+	}
+	return rows, nil
+}
+func (db *reembedDB) SetReembedDocumentVector(_ context.Context, arg database.SetReembedDocumentVectorParams) error {
+	db.next[arg.Uuid.String] = arg.Embedding.(string)
+	return nil
+}
+func (db *reembedDB) KeepReembedDocumentVector(_ context.Context, uuid sql.NullString) error {
+	db.next[uuid.String] = "old"
+	db.kept[uuid.String] = true
+	return nil
+}
+func (db *reembedDB) ResetReembedVectors(_ context.Context, _ string) error {
+	db.resets++
+	db.next = map[string]string{}
+	return nil
+}
+func (db *reembedDB) UpdateEmbeddingMigration(_ context.Context, arg database.UpdateEmbeddingMigrationParams) (database.EmbeddingMigration, error) {
+	db.job.Status = arg.Status
+	db.job.TargetDimensions = arg.TargetDimensions
+	db.job.TotalDocs = arg.TotalDocs
+	db.job.ProcessedDocs = arg.ProcessedDocs
+	db.job.FailedDocs = arg.FailedDocs
+	db.job.Error = arg.Error
+	return db.job, nil
+}
+func (db *reembedDB) CompleteEmbeddingMigration(_ context.Context, id int64) (database.EmbeddingMigration, error) {
+	db.job.Status = database.EmbeddingMigrationStatusCompleted
+	db.coll = &database.EmbeddingCollection{
+		Name:       db.job.Collection,
+		Provider:   db.job.TargetProvider,
+		Model:      db.job.TargetModel,
+		Dimensions: db.job.TargetDimensions,
+	}
+	return db.job, nil
+}
+
+// mockSwitcher records the model switches of a re-embedding migration.
+type mockSwitcher struct {
+	*mockEmbedder
+	target   *mockEmbedder
+	legacy   string
+	cutovers int
+}
+
+func (m *mockSwitcher) Target() embeddings.Embedder { return m.target }
+func (m *mockSwitcher) UseModel(_, model string) error {
+	m.legacy = model
+	return nil
+}
+func (m *mockSwitcher) Cutover() { m.cutovers++ }
+
+func TestRunEmbeddingMigration(t *testing.T) {
+	db := newReembedDB("first", "rejected", "second")
+	db.job = database.EmbeddingMigration{
+		ID:             3,
+		Collection:     knowledgeCollection,
+		Status:         database.EmbeddingMigrationStatusRunning,
+		SourceProvider: "openai",
+		SourceModel:    "text-embedding-ada-002",
+		TargetProvider: "openai",
+		TargetModel:    "text-embedding-3-small",
+	}
+
+	target := &mockEmbedder{
+		available: true,
+		provider:  "openai",
+		model:     "text-embedding-3-small",
+		embedDocumentsFn: func(_ context.Context, texts []string) ([][]float32, error) {
+			vecs := make([][]float32, len(texts))
+			for i, text := range texts {
+				if text == "rejected" {
+					return nil, errors.New("input rejected")
+				}
+				vecs[i] = []float32{1, 2}
+			}
+			return vecs, nil
+		},
+	}
+	switcher := &mockSwitcher{mockEmbedder: target, target: target}
+	ks := &knowledgeStore{
+		db:                db,
+		embedder:          switcher,
+		maxEmbeddingBytes: 8192,
+		reembed:           ReembedConfig{BatchSize: 2},
+	}
+
+	job, err := ks.runEmbeddingMigration(t.Context(), db.job)
+	if err != nil {
+		t.Fatalf("runEmbeddingMigration: %v", err)
+	}
+	if job.Status != database.EmbeddingMigrationStatusCompleted {
+		t.Errorf("status = %s, want completed", job.Status)
+	}
+	if job.TotalDocs != 3 || job.ProcessedDocs != 3 || job.FailedDocs != 1 {
+		t.Errorf("total/processed/failed = %d/%d/%d, want 3/3/1", job.TotalDocs, job.ProcessedDocs, job.FailedDocs)
+	}
+	if job.TargetDimensions != 2 {
+		t.Errorf("target dimensions = %d, want 2", job.TargetDimensions)
+	}
+	if !db.kept["b"] || len(db.kept) != 1 {
+		t.Errorf("kept vectors = %v, want only the rejected document", db.kept)
+	}
+	if db.next["a"] != "[1,2]" || db.next["c"] != "[1,2]" {
+		t.Errorf("new vectors = %v", db.next)
+	}
+	if switcher.cutovers != 1 {
+		t.Errorf("cutovers = %d, want 1", switcher.cutovers)
+	}
+	if db.coll == nil || db.coll.Model != "text-embedding-3-small" || db.coll.Dimensions != 2 {
+		t.Errorf("collection = %+v, want the target model", db.coll)
+	}
+}
+
+func TestRunEmbeddingMigrationProviderDown(t *testing.T) {
+	db := newReembedDB("first", "second")
+	db.job = database.EmbeddingMigration{ID: 1, Collection: knowledgeCollection, Status: database.EmbeddingMigrationStatusRunning}
+
+	ks := &knowledgeStore{
+		db: db,
+		embedder: &mockEmbedder{
+			available: true,
+			embedDocumentsFn: func(_ context.Context, _ []string) ([][]float32, error) {
+				return nil, errors.New("connection refused")
+			},
+		},
+		maxEmbeddingBytes: 8192,
+	}
+
+	job, err := ks.runEmbeddingMigration(t.Context(), db.job)
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("err = %v, want provider error", err)
+	}
+	if job.Status != database.EmbeddingMigrationStatusFailed || db.job.Status != database.EmbeddingMigrationStatusFailed {
+		t.Errorf("status = %s, stored %s, want failed", job.Status, db.job.Status)
+	}
+	if len(db.kept) != 0 {
+		t.Errorf("kept vectors = %v, failed batches must stay pending for a resume", db.kept)
+	}
+}
+
+func TestCheckEmbeddingModel(t *testing.T) {
+	newStore := func(db *reembedDB, auto bool) *knowledgeStore {
+		return &knowledgeStore{
+			db:                db,
+			embedder:          &mockEmbedder{available: true, provider: "openai", model: "text-embedding-3-small"},
+			maxEmbeddingBytes: 8192,
+			reembed:           ReembedConfig{Auto: auto},
+		}
+	}
+
+	t.Run("records unknown collection", func(t *testing.T) {
+		db := newReembedDB("first")
+		status, err := newStore(db, true).CheckEmbeddingModel(t.Context())
+		if err != nil {
+			t.Fatalf("CheckEmbeddingModel: %v", err)
+		}
+		if status.Mismatch || status.StoredModel != "openai/text-embedding-3-small" || status.Dimensions != 3 {
+			t.Errorf("status = %+v", status)
+		}
+		if status.Migration != nil {
+			t.Errorf("unexpected migration %+v", status.Migration)
+		}
+	})
+
+	t.Run("reports mismatch without auto", func(t *testing.T) {
+		db := newReembedDB("first")
+		db.coll = &database.EmbeddingCollection{Name: knowledgeCollection, Provider: "openai", Model: "text-embedding-ada-002", Dimensions: 1536}
+		status, err := newStore(db, false).CheckEmbeddingModel(t.Context())
+		if err != nil {
+			t.Fatalf("CheckEmbeddingModel: %v", err)
+		}
+		if !status.Mismatch || status.StoredModel != "openai/text-embedding-ada-002" {
+			t.Errorf("status = %+v, want mismatch", status)
+		}
+		if db.resets != 0 {
+			t.Errorf("vectors reset without a migration")
+		}
+	})
+
+	t.Run("refuses migration when up to date", func(t *testing.T) {
+		db := newReembedDB("first")
+		db.coll = &database.EmbeddingCollection{Name: knowledgeCollection, Provider: "openai", Model: "text-embedding-3-small"}
+		if _, err := newStore(db, true).StartEmbeddingMigration(t.Context()); err == nil {
+			t.Errorf("expected an error for an up to date collection")
+		}
+	})
+}
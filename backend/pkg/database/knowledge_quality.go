@@ -0,0 +1,63 @@
+package database
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// operatorVoteWeight counts an operator vote as several agent reports:
+	// operators review the content, agents only judge whether it helped them.
+	operatorVoteWeight = 3
+	// popularityRetrievals is the retrieval count earning the full popularity bonus.
+	popularityRetrievals = 1000
+)
+
+// KnowledgeSignals are the quality signals collected for a knowledge document.
+type KnowledgeSignals struct {
+	Retrievals int64
+	Useful     int64
+	NotUseful  int64
+	Upvotes    int64
+	Downvotes  int64
+}
+
+// SignalsFromRow converts GetKnowledgeDocumentSignals results.
+func SignalsFromRow(row GetKnowledgeDocumentSignalsRow) KnowledgeSignals {
+	return KnowledgeSignals{
+		Retrievals: row.Retrievals,
+		Useful:     row.Useful,
+		NotUseful:  row.NotUseful,
+		Upvotes:    row.Upvotes,
+		Downvotes:  row.Downvotes,
+	}
+}
+
+// Feedback returns the smoothed balance of positive and negative reports in
+// (-1, 1); documents with few reports stay close to 0.
+func (s KnowledgeSignals) Feedback() float64 {
+	positive := float64(s.Useful + operatorVoteWeight*s.Upvotes)
+	negative := float64(s.NotUseful + operatorVoteWeight*s.Downvotes)
+	return (positive - negative) / (positive + negative + 2)
+}
+
+// Popularity grows logarithmically with retrievals from 0 to 1.
+func (s KnowledgeSignals) Popularity() float64 {
+	if s.Retrievals <= 0 {
+		return 0
+	}
+	return min(math.Log1p(float64(s.Retrievals))/math.Log1p(popularityRetrievals), 1)
+}
+
+// QualityFactor returns the multiplier applied to a document's search score:
+// 1 + FeedbackWeight*Feedback + PopularityWeight*Popularity, never negative.
+// Documents without signals keep their score.
+func (c HybridSearchConfig) QualityFactor(s KnowledgeSignals) float64 {
+	return max(1+c.FeedbackWeight*s.Feedback()+c.PopularityWeight*s.Popularity(), 0)
+}
+
+// KnowledgeRetired reports whether a document with the given cmetadata
+// "archived" and "expires_at" values must no longer be retrieved.
+func KnowledgeRetired(archived bool, expiresAt *time.Time, now time.Time) bool {
+	return archived || (expiresAt != nil && !expiresAt.After(now))
+}
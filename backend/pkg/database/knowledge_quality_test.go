@@ -0,0 +1,77 @@
+package database
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestQualityFactor(t *testing.T) {
+	hcfg := HybridSearchConfig{FeedbackWeight: 0.5, PopularityWeight: 0.1}
+
+	t.Run("no signals keep the score", func(t *testing.T) {
+		if factor := hcfg.QualityFactor(KnowledgeSignals{}); factor != 1 {
+			t.Errorf("expected 1, got %f", factor)
+		}
+	})
+
+	t.Run("feedback direction", func(t *testing.T) {
+		useful := hcfg.QualityFactor(KnowledgeSignals{Useful: 4})
+		useless := hcfg.QualityFactor(KnowledgeSignals{NotUseful: 4})
+		if useful <= 1 || useless >= 1 {
+			t.Errorf("expected useful > 1 > useless, got %f and %f", useful, useless)
+		}
+		if math.Abs((useful-1)+(useless-1)) > 1e-9 {
+			t.Errorf("opposite reports must shift the score symmetrically, got %f and %f", useful, useless)
+		}
+	})
+
+	t.Run("operator votes outweigh agent reports", func(t *testing.T) {
+		s := KnowledgeSignals{Useful: 2, Downvotes: 1}
+		if s.Feedback() >= 0 {
+			t.Errorf("one downvote must outweigh two useful reports, got %f", s.Feedback())
+		}
+	})
+
+	t.Run("few reports are smoothed", func(t *testing.T) {
+		one := KnowledgeSignals{Useful: 1}.Feedback()
+		many := KnowledgeSignals{Useful: 100}.Feedback()
+		if one >= many || many >= 1 {
+			t.Errorf("expected 0 < %f < %f < 1", one, many)
+		}
+	})
+
+	t.Run("popularity is capped", func(t *testing.T) {
+		if p := (KnowledgeSignals{Retrievals: 1_000_000}).Popularity(); p != 1 {
+			t.Errorf("expected 1, got %f", p)
+		}
+		if p := (KnowledgeSignals{Retrievals: 10}).Popularity(); p <= 0 || p >= 1 {
+			t.Errorf("expected a partial bonus, got %f", p)
+		}
+	})
+
+	t.Run("never negative", func(t *testing.T) {
+		strict := HybridSearchConfig{FeedbackWeight: 1}
+		if factor := strict.QualityFactor(KnowledgeSignals{Downvotes: 1000}); factor < 0 {
+			t.Errorf("expected a non-negative factor, got %f", factor)
+		}
+	})
+}
+
+func TestKnowledgeRetired(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	if KnowledgeRetired(false, nil, now) {
+		t.Error("document without expiry must be retrieved")
+	}
+	if KnowledgeRetired(false, &future, now) {
+		t.Error("document expiring later must be retrieved")
+	}
+	if !KnowledgeRetired(false, &past, now) {
+		t.Error("expired document must not be retrieved")
+	}
+	if !KnowledgeRetired(true, nil, now) {
+		t.Error("archived document must not be retrieved")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sqlc-dev/pqtype"
@@ -146,6 +147,94 @@ func (ns NullContainerType) Value() (driver.Value, error) {
 	return string(ns.ContainerType), nil
 }
 
+type CredentialType string
+
+const (
+	CredentialTypePassword CredentialType = "password"
+	CredentialTypeHash     CredentialType = "hash"
+	CredentialTypeToken    CredentialType = "token"
+	CredentialTypeKey      CredentialType = "key"
+	CredentialTypeOther    CredentialType = "other"
+)
+
+func (e *CredentialType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CredentialType(s)
+	case string:
+		*e = CredentialType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CredentialType: %T", src)
+	}
+	return nil
+}
+
+type NullCredentialType struct {
+	CredentialType CredentialType `json:"credential_type"`
+	Valid          bool           `json:"valid"` // Valid is true if CredentialType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCredentialType) Scan(value interface{}) error {
+	if value == nil {
+		ns.CredentialType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CredentialType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCredentialType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CredentialType), nil
+}
+
+type EmbeddingMigrationStatus string
+
+const (
+	EmbeddingMigrationStatusRunning   EmbeddingMigrationStatus = "running"
+	EmbeddingMigrationStatusCompleted EmbeddingMigrationStatus = "completed"
+	EmbeddingMigrationStatusFailed    EmbeddingMigrationStatus = "failed"
+)
+
+func (e *EmbeddingMigrationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmbeddingMigrationStatus(s)
+	case string:
+		*e = EmbeddingMigrationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmbeddingMigrationStatus: %T", src)
+	}
+	return nil
+}
+
+type NullEmbeddingMigrationStatus struct {
+	EmbeddingMigrationStatus EmbeddingMigrationStatus `json:"embedding_migration_status"`
+	Valid                    bool                     `json:"valid"` // Valid is true if EmbeddingMigrationStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmbeddingMigrationStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmbeddingMigrationStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmbeddingMigrationStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmbeddingMigrationStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmbeddingMigrationStatus), nil
+}
+
 type FlowStatus string
 
 const (
@@ -191,6 +280,50 @@ func (ns NullFlowStatus) Value() (driver.Value, error) {
 	return string(ns.FlowStatus), nil
 }
 
+type KnowledgeImportStatus string
+
+const (
+	KnowledgeImportStatusPending  KnowledgeImportStatus = "pending"
+	KnowledgeImportStatusRunning  KnowledgeImportStatus = "running"
+	KnowledgeImportStatusFinished KnowledgeImportStatus = "finished"
+	KnowledgeImportStatusFailed   KnowledgeImportStatus = "failed"
+)
+
+func (e *KnowledgeImportStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = KnowledgeImportStatus(s)
+	case string:
+		*e = KnowledgeImportStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for KnowledgeImportStatus: %T", src)
+	}
+	return nil
+}
+
+type NullKnowledgeImportStatus struct {
+	KnowledgeImportStatus KnowledgeImportStatus `json:"knowledge_import_status"`
+	Valid                 bool                  `json:"valid"` // Valid is true if KnowledgeImportStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullKnowledgeImportStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.KnowledgeImportStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.KnowledgeImportStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullKnowledgeImportStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.KnowledgeImportStatus), nil
+}
+
 type MsgchainType string
 
 const (
@@ -340,6 +473,49 @@ func (ns NullMsglogType) Value() (driver.Value, error) {
 	return string(ns.MsglogType), nil
 }
 
+type ProjectRole string
+
+const (
+	ProjectRoleOwner  ProjectRole = "owner"
+	ProjectRoleEditor ProjectRole = "editor"
+	ProjectRoleViewer ProjectRole = "viewer"
+)
+
+func (e *ProjectRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProjectRole(s)
+	case string:
+		*e = ProjectRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProjectRole: %T", src)
+	}
+	return nil
+}
+
+type NullProjectRole struct {
+	ProjectRole ProjectRole `json:"project_role"`
+	Valid       bool        `json:"valid"` // Valid is true if ProjectRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProjectRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProjectRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProjectRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProjectRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProjectRole), nil
+}
+
 type PromptType string
 
 const (
@@ -865,6 +1041,49 @@ func (ns NullVecstoreActionType) Value() (driver.Value, error) {
 	return string(ns.VecstoreActionType), nil
 }
 
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSuccess WebhookDeliveryStatus = "success"
+	WebhookDeliveryStatusFailed  WebhookDeliveryStatus = "failed"
+)
+
+func (e *WebhookDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStatus(s)
+	case string:
+		*e = WebhookDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStatus struct {
+	WebhookDeliveryStatus WebhookDeliveryStatus `json:"webhook_delivery_status"`
+	Valid                 bool                  `json:"valid"` // Valid is true if WebhookDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStatus), nil
+}
+
 type Agentlog struct {
 	ID        int64         `json:"id"`
 	Initiator MsgchainType  `json:"initiator"`
@@ -877,17 +1096,33 @@ type Agentlog struct {
 	CreatedAt sql.NullTime  `json:"created_at"`
 }
 
+type AnonymizationPattern struct {
+	ID        int64        `json:"id"`
+	UserID    int64        `json:"user_id"`
+	Name      string       `json:"name"`
+	Regex     string       `json:"regex"`
+	Enabled   bool         `json:"enabled"`
+	CreatedAt sql.NullTime `json:"created_at"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
 type ApiToken struct {
-	ID        int64          `json:"id"`
-	TokenID   string         `json:"token_id"`
-	UserID    int64          `json:"user_id"`
-	RoleID    int64          `json:"role_id"`
-	Name      sql.NullString `json:"name"`
-	Ttl       int64          `json:"ttl"`
-	Status    TokenStatus    `json:"status"`
-	CreatedAt sql.NullTime   `json:"created_at"`
-	UpdatedAt sql.NullTime   `json:"updated_at"`
-	DeletedAt sql.NullTime   `json:"deleted_at"`
+	ID          int64          `json:"id"`
+	TokenID     string         `json:"token_id"`
+	UserID      int64          `json:"user_id"`
+	RoleID      int64          `json:"role_id"`
+	Name        sql.NullString `json:"name"`
+	Ttl         int64          `json:"ttl"`
+	Status      TokenStatus    `json:"status"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Privileges  []string       `json:"privileges"`
+	FlowIds     []int64        `json:"flow_ids"`
+	TemplateIds []int64        `json:"template_ids"`
+	AllowedIps  []string       `json:"allowed_ips"`
+	LastUsedAt  sql.NullTime   `json:"last_used_at"`
+	LastUsedIp  sql.NullString `json:"last_used_ip"`
 }
 
 type Assistant struct {
@@ -921,6 +1156,20 @@ type Assistantlog struct {
 	Thinking     sql.NullString     `json:"thinking"`
 }
 
+type BackendInstance struct {
+	Name        string    `json:"name"`
+	StartedAt   time.Time `json:"started_at"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type BackendLeader struct {
+	ID         int16     `json:"id"`
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
 type Container struct {
 	ID        int64           `json:"id"`
 	Type      ContainerType   `json:"type"`
@@ -934,6 +1183,34 @@ type Container struct {
 	UpdatedAt sql.NullTime    `json:"updated_at"`
 }
 
+type EmbeddingCollection struct {
+	Name       string       `json:"name"`
+	Provider   string       `json:"provider"`
+	Model      string       `json:"model"`
+	Dimensions int32        `json:"dimensions"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+	UpdatedAt  sql.NullTime `json:"updated_at"`
+}
+
+type EmbeddingMigration struct {
+	ID               int64                    `json:"id"`
+	Collection       string                   `json:"collection"`
+	Status           EmbeddingMigrationStatus `json:"status"`
+	SourceProvider   string                   `json:"source_provider"`
+	SourceModel      string                   `json:"source_model"`
+	SourceDimensions int32                    `json:"source_dimensions"`
+	TargetProvider   string                   `json:"target_provider"`
+	TargetModel      string                   `json:"target_model"`
+	TargetDimensions int32                    `json:"target_dimensions"`
+	TotalDocs        int64                    `json:"total_docs"`
+	ProcessedDocs    int64                    `json:"processed_docs"`
+	FailedDocs       int64                    `json:"failed_docs"`
+	Error            sql.NullString           `json:"error"`
+	CreatedAt        sql.NullTime             `json:"created_at"`
+	UpdatedAt        sql.NullTime             `json:"updated_at"`
+	FinishedAt       sql.NullTime             `json:"finished_at"`
+}
+
 type Flow struct {
 	ID                 int64           `json:"id"`
 	Status             FlowStatus      `json:"status"`
@@ -949,15 +1226,128 @@ type Flow struct {
 	TraceID            sql.NullString  `json:"trace_id"`
 	ModelProviderType  ProviderType    `json:"model_provider_type"`
 	ToolCallIDTemplate string          `json:"tool_call_id_template"`
+	ProjectID          sql.NullInt64   `json:"project_id"`
+}
+
+type FlowAnonymizationEntry struct {
+	ID          int64        `json:"id"`
+	FlowID      int64        `json:"flow_id"`
+	Kind        string       `json:"kind"`
+	Value       []byte       `json:"value"`
+	ValueDigest []byte       `json:"value_digest"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+}
+
+type FlowCredential struct {
+	ID         int64          `json:"id"`
+	FlowID     int64          `json:"flow_id"`
+	Type       CredentialType `json:"type"`
+	Username   string         `json:"username"`
+	Secret     []byte         `json:"secret"`
+	SourceHost string         `json:"source_host"`
+	ToolcallID sql.NullInt64  `json:"toolcall_id"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+	UpdatedAt  sql.NullTime   `json:"updated_at"`
+}
+
+type FlowLease struct {
+	FlowID      int64     `json:"flow_id"`
+	Owner       string    `json:"owner"`
+	AcquiredAt  time.Time `json:"acquired_at"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
 }
 
 type FlowTemplate struct {
-	ID        int64        `json:"id"`
-	UserID    int64        `json:"user_id"`
-	Title     string       `json:"title"`
-	Text      string       `json:"text"`
-	CreatedAt sql.NullTime `json:"created_at"`
-	UpdatedAt sql.NullTime `json:"updated_at"`
+	ID        int64         `json:"id"`
+	UserID    int64         `json:"user_id"`
+	Title     string        `json:"title"`
+	Text      string        `json:"text"`
+	CreatedAt sql.NullTime  `json:"created_at"`
+	UpdatedAt sql.NullTime  `json:"updated_at"`
+	ProjectID sql.NullInt64 `json:"project_id"`
+}
+
+type GraphEntity struct {
+	ID          uuid.UUID    `json:"id"`
+	GroupID     string       `json:"group_id"`
+	Label       string       `json:"label"`
+	Name        string       `json:"name"`
+	Summary     string       `json:"summary"`
+	Mentions    int64        `json:"mentions"`
+	FirstSeenAt time.Time    `json:"first_seen_at"`
+	LastSeenAt  time.Time    `json:"last_seen_at"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	UpdatedAt   sql.NullTime `json:"updated_at"`
+}
+
+type GraphEntityEpisode struct {
+	EntityID  uuid.UUID `json:"entity_id"`
+	EpisodeID uuid.UUID `json:"episode_id"`
+}
+
+type GraphEpisode struct {
+	ID                uuid.UUID    `json:"id"`
+	GroupID           string       `json:"group_id"`
+	Name              string       `json:"name"`
+	Author            string       `json:"author"`
+	SourceDescription string       `json:"source_description"`
+	Content           string       `json:"content"`
+	Success           sql.NullBool `json:"success"`
+	ValidAt           time.Time    `json:"valid_at"`
+	CreatedAt         sql.NullTime `json:"created_at"`
+}
+
+type GraphRelation struct {
+	ID         uuid.UUID     `json:"id"`
+	GroupID    string        `json:"group_id"`
+	SourceID   uuid.UUID     `json:"source_id"`
+	TargetID   uuid.UUID     `json:"target_id"`
+	Name       string        `json:"name"`
+	Fact       string        `json:"fact"`
+	Mentions   int64         `json:"mentions"`
+	EpisodeID  uuid.NullUUID `json:"episode_id"`
+	ValidAt    time.Time     `json:"valid_at"`
+	LastSeenAt time.Time     `json:"last_seen_at"`
+	CreatedAt  sql.NullTime  `json:"created_at"`
+	UpdatedAt  sql.NullTime  `json:"updated_at"`
+}
+
+type KnowledgeDocumentStat struct {
+	DocumentID      uuid.UUID    `json:"document_id"`
+	Retrievals      int64        `json:"retrievals"`
+	Useful          int64        `json:"useful"`
+	NotUseful       int64        `json:"not_useful"`
+	LastRetrievedAt sql.NullTime `json:"last_retrieved_at"`
+	CreatedAt       sql.NullTime `json:"created_at"`
+	UpdatedAt       sql.NullTime `json:"updated_at"`
+}
+
+type KnowledgeDocumentVote struct {
+	DocumentID uuid.UUID    `json:"document_id"`
+	UserID     int64        `json:"user_id"`
+	Vote       int16        `json:"vote"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+	UpdatedAt  sql.NullTime `json:"updated_at"`
+}
+
+type KnowledgeImportJob struct {
+	ID              int64                 `json:"id"`
+	UserID          int64                 `json:"user_id"`
+	Status          KnowledgeImportStatus `json:"status"`
+	Source          string                `json:"source"`
+	Options         json.RawMessage       `json:"options"`
+	TotalFiles      int64                 `json:"total_files"`
+	SkippedFiles    int64                 `json:"skipped_files"`
+	TotalChunks     int64                 `json:"total_chunks"`
+	ProcessedChunks int64                 `json:"processed_chunks"`
+	CreatedDocs     int64                 `json:"created_docs"`
+	DuplicateDocs   int64                 `json:"duplicate_docs"`
+	FailedDocs      int64                 `json:"failed_docs"`
+	Error           sql.NullString        `json:"error"`
+	CreatedAt       sql.NullTime          `json:"created_at"`
+	UpdatedAt       sql.NullTime          `json:"updated_at"`
+	UpdatedDocs     int64                 `json:"updated_docs"`
 }
 
 type LangchainPgCollection struct {
@@ -967,11 +1357,12 @@ type LangchainPgCollection struct {
 }
 
 type LangchainPgEmbedding struct {
-	CollectionID uuid.NullUUID         `json:"collection_id"`
-	Embedding    string                `json:"embedding"`
-	Document     sql.NullString        `json:"document"`
-	Cmetadata    pqtype.NullRawMessage `json:"cmetadata"`
-	Uuid         uuid.UUID             `json:"uuid"`
+	CollectionID  uuid.NullUUID         `json:"collection_id"`
+	Embedding     string                `json:"embedding"`
+	Document      sql.NullString        `json:"document"`
+	Cmetadata     pqtype.NullRawMessage `json:"cmetadata"`
+	Uuid          uuid.UUID             `json:"uuid"`
+	EmbeddingNext string                `json:"embedding_next"`
 }
 
 type Msgchain struct {
@@ -1013,6 +1404,23 @@ type Privilege struct {
 	Name   string `json:"name"`
 }
 
+type Project struct {
+	ID          int64        `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	UserID      int64        `json:"user_id"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	UpdatedAt   sql.NullTime `json:"updated_at"`
+}
+
+type ProjectMember struct {
+	ProjectID int64        `json:"project_id"`
+	UserID    int64        `json:"user_id"`
+	Role      ProjectRole  `json:"role"`
+	CreatedAt sql.NullTime `json:"created_at"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
 type Prompt struct {
 	ID        int64        `json:"id"`
 	Type      PromptType   `json:"type"`
@@ -1031,6 +1439,25 @@ type Provider struct {
 	CreatedAt sql.NullTime    `json:"created_at"`
 	UpdatedAt sql.NullTime    `json:"updated_at"`
 	DeletedAt sql.NullTime    `json:"deleted_at"`
+	ProjectID sql.NullInt64   `json:"project_id"`
+}
+
+type ProviderCapability struct {
+	ID          int64           `json:"id"`
+	UserID      int64           `json:"user_id"`
+	Type        ProviderType    `json:"type"`
+	Model       string          `json:"model"`
+	AgentTypes  []string        `json:"agent_types"`
+	ToolCalling sql.NullFloat64 `json:"tool_calling"`
+	JsonMode    sql.NullBool    `json:"json_mode"`
+	Reasoning   bool            `json:"reasoning"`
+	Streaming   sql.NullBool    `json:"streaming"`
+	MaxContext  int64           `json:"max_context"`
+	TestsTotal  int64           `json:"tests_total"`
+	TestsPassed int64           `json:"tests_passed"`
+	CreatedAt   sql.NullTime    `json:"created_at"`
+	UpdatedAt   sql.NullTime    `json:"updated_at"`
+	ProviderID  int64           `json:"provider_id"`
 }
 
 type Role struct {
@@ -1061,6 +1488,12 @@ type Searchlog struct {
 	CreatedAt sql.NullTime     `json:"created_at"`
 }
 
+type SubscriptionPayload struct {
+	ID        int64     `json:"id"`
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Subtask struct {
 	ID          int64         `json:"id"`
 	Status      SubtaskStatus `json:"status"`
@@ -1133,15 +1566,16 @@ type UserPreference struct {
 }
 
 type UserResource struct {
-	ID        int64        `json:"id"`
-	UserID    int64        `json:"user_id"`
-	Hash      string       `json:"hash"`
-	Name      string       `json:"name"`
-	Path      string       `json:"path"`
-	Size      int64        `json:"size"`
-	IsDir     bool         `json:"is_dir"`
-	CreatedAt sql.NullTime `json:"created_at"`
-	UpdatedAt sql.NullTime `json:"updated_at"`
+	ID        int64         `json:"id"`
+	UserID    int64         `json:"user_id"`
+	Hash      string        `json:"hash"`
+	Name      string        `json:"name"`
+	Path      string        `json:"path"`
+	Size      int64         `json:"size"`
+	IsDir     bool          `json:"is_dir"`
+	CreatedAt sql.NullTime  `json:"created_at"`
+	UpdatedAt sql.NullTime  `json:"updated_at"`
+	ProjectID sql.NullInt64 `json:"project_id"`
 }
 
 type Vecstorelog struct {
@@ -1157,3 +1591,33 @@ type Vecstorelog struct {
 	SubtaskID sql.NullInt64      `json:"subtask_id"`
 	CreatedAt sql.NullTime       `json:"created_at"`
 }
+
+type Webhook struct {
+	ID        int64        `json:"id"`
+	UserID    int64        `json:"user_id"`
+	Name      string       `json:"name"`
+	Url       string       `json:"url"`
+	Secret    string       `json:"secret"`
+	Events    []string     `json:"events"`
+	FlowIds   []int64      `json:"flow_ids"`
+	Enabled   bool         `json:"enabled"`
+	CreatedAt sql.NullTime `json:"created_at"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	WebhookID      int64                 `json:"webhook_id"`
+	Event          string                `json:"event"`
+	FlowID         sql.NullInt64         `json:"flow_id"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int32                 `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	ResponseStatus sql.NullInt32         `json:"response_status"`
+	ResponseBody   string                `json:"response_body"`
+	Error          string                `json:"error"`
+	DeliveredAt    sql.NullTime          `json:"delivered_at"`
+	CreatedAt      sql.NullTime          `json:"created_at"`
+	UpdatedAt      sql.NullTime          `json:"updated_at"`
+}
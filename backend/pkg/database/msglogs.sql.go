@@ -8,6 +8,8 @@ package database
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const createMsgLog = `-- name: CreateMsgLog :one
@@ -157,6 +159,86 @@ func (q *Queries) GetFlowMsgLogs(ctx context.Context, flowID int64) ([]Msglog, e
 	return items, nil
 }
 
+const getFlowMsgLogsPage = `-- name: GetFlowMsgLogsPage :many
+SELECT
+  ml.id, ml.type, ml.message, ml.result, ml.flow_id, ml.task_id, ml.subtask_id, ml.created_at, ml.result_format, ml.thinking
+FROM msglogs ml
+INNER JOIN flows f ON ml.flow_id = f.id
+WHERE ml.flow_id = $1 AND f.deleted_at IS NULL
+  AND ($2::bigint IS NULL OR ml.id > $2::bigint)
+  AND ($3::bigint IS NULL OR ml.id < $3::bigint)
+  AND ($4::timestamptz IS NULL OR ml.created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR ml.created_at < $5::timestamptz)
+  AND ($6::bigint IS NULL OR ml.task_id = $6::bigint)
+  AND ($7::bigint IS NULL OR ml.subtask_id = $7::bigint)
+  AND (cardinality($8::text[]) = 0 OR ml.type::text = ANY($8::text[]))
+  AND ($9::text = '' OR search_document(ml.message, ml.thinking, ml.result) @@ websearch_to_tsquery('english', $9::text))
+ORDER BY
+  CASE WHEN $10::bool THEN ml.id END DESC,
+  ml.id ASC
+LIMIT $11::int
+`
+
+type GetFlowMsgLogsPageParams struct {
+	FlowID    int64         `json:"flow_id"`
+	AfterID   sql.NullInt64 `json:"after_id"`
+	BeforeID  sql.NullInt64 `json:"before_id"`
+	Since     sql.NullTime  `json:"since"`
+	Until     sql.NullTime  `json:"until"`
+	TaskID    sql.NullInt64 `json:"task_id"`
+	SubtaskID sql.NullInt64 `json:"subtask_id"`
+	Types     []string      `json:"types"`
+	Search    string        `json:"search"`
+	Reverse   bool          `json:"reverse"`
+	PageSize  sql.NullInt32 `json:"page_size"`
+}
+
+func (q *Queries) GetFlowMsgLogsPage(ctx context.Context, arg GetFlowMsgLogsPageParams) ([]Msglog, error) {
+	rows, err := q.db.QueryContext(ctx, getFlowMsgLogsPage,
+		arg.FlowID,
+		arg.AfterID,
+		arg.BeforeID,
+		arg.Since,
+		arg.Until,
+		arg.TaskID,
+		arg.SubtaskID,
+		pq.Array(arg.Types),
+		arg.Search,
+		arg.Reverse,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Msglog
+	for rows.Next() {
+		var i Msglog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Message,
+			&i.Result,
+			&i.FlowID,
+			&i.TaskID,
+			&i.SubtaskID,
+			&i.CreatedAt,
+			&i.ResultFormat,
+			&i.Thinking,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSubtaskMsgLogs = `-- name: GetSubtaskMsgLogs :many
 SELECT
   ml.id, ml.type, ml.message, ml.result, ml.flow_id, ml.task_id, ml.subtask_id, ml.created_at, ml.result_format, ml.thinking
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 )
 
@@ -19,7 +20,7 @@ INSERT INTO providers (
 ) VALUES (
   $1, $2, $3, $4
 )
-RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at
+RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at, project_id
 `
 
 type CreateProviderParams struct {
@@ -46,6 +47,7 @@ func (q *Queries) CreateProvider(ctx context.Context, arg CreateProviderParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -54,7 +56,7 @@ const deleteProvider = `-- name: DeleteProvider :one
 UPDATE providers
 SET deleted_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at
+RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at, project_id
 `
 
 func (q *Queries) DeleteProvider(ctx context.Context, id int64) (Provider, error) {
@@ -69,6 +71,7 @@ func (q *Queries) DeleteProvider(ctx context.Context, id int64) (Provider, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -77,7 +80,7 @@ const deleteUserProvider = `-- name: DeleteUserProvider :one
 UPDATE providers
 SET deleted_at = CURRENT_TIMESTAMP
 WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
-RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at
+RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at, project_id
 `
 
 type DeleteUserProviderParams struct {
@@ -103,13 +106,14 @@ func (q *Queries) DeleteUserProvider(ctx context.Context, arg DeleteUserProvider
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
 
 const getProvider = `-- name: GetProvider :one
 SELECT
-  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at
+  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at, p.project_id
 FROM providers p
 WHERE p.id = $1 AND p.deleted_at IS NULL
 `
@@ -126,13 +130,14 @@ func (q *Queries) GetProvider(ctx context.Context, id int64) (Provider, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
 
 const getProviders = `-- name: GetProviders :many
 SELECT
-  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at
+  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at, p.project_id
 FROM providers p
 WHERE p.deleted_at IS NULL
 ORDER BY p.created_at ASC
@@ -156,6 +161,7 @@ func (q *Queries) GetProviders(ctx context.Context) ([]Provider, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -172,7 +178,7 @@ func (q *Queries) GetProviders(ctx context.Context) ([]Provider, error) {
 
 const getProvidersByType = `-- name: GetProvidersByType :many
 SELECT
-  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at
+  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at, p.project_id
 FROM providers p
 WHERE p.type = $1 AND p.deleted_at IS NULL
 ORDER BY p.created_at ASC
@@ -196,6 +202,83 @@ func (q *Queries) GetProvidersByType(ctx context.Context, type_ ProviderType) ([
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProjectID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserProjectProviderByName = `-- name: GetUserProjectProviderByName :one
+SELECT
+  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at, p.project_id
+FROM providers p
+INNER JOIN project_members m ON m.project_id = p.project_id
+WHERE p.name = $1 AND m.user_id = $2 AND p.user_id <> $2 AND p.deleted_at IS NULL
+ORDER BY p.created_at ASC
+LIMIT 1
+`
+
+type GetUserProjectProviderByNameParams struct {
+	Name   string `json:"name"`
+	UserID int64  `json:"user_id"`
+}
+
+// A provider another member shares through a project of the user. When
+// several projects share one with that name, the oldest provider wins.
+func (q *Queries) GetUserProjectProviderByName(ctx context.Context, arg GetUserProjectProviderByNameParams) (Provider, error) {
+	row := q.db.QueryRowContext(ctx, getUserProjectProviderByName, arg.Name, arg.UserID)
+	var i Provider
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Type,
+		&i.Name,
+		&i.Config,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProjectID,
+	)
+	return i, err
+}
+
+const getUserProjectProviders = `-- name: GetUserProjectProviders :many
+SELECT
+  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at, p.project_id
+FROM providers p
+INNER JOIN project_members m ON m.project_id = p.project_id
+WHERE m.user_id = $1 AND p.user_id <> $1 AND p.deleted_at IS NULL
+ORDER BY p.created_at ASC
+`
+
+func (q *Queries) GetUserProjectProviders(ctx context.Context, userID int64) ([]Provider, error) {
+	rows, err := q.db.QueryContext(ctx, getUserProjectProviders, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Provider
+	for rows.Next() {
+		var i Provider
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Type,
+			&i.Name,
+			&i.Config,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -212,7 +295,7 @@ func (q *Queries) GetProvidersByType(ctx context.Context, type_ ProviderType) ([
 
 const getUserProvider = `-- name: GetUserProvider :one
 SELECT
-  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at
+  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at, p.project_id
 FROM providers p
 INNER JOIN users u ON p.user_id = u.id
 WHERE p.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
@@ -235,13 +318,14 @@ func (q *Queries) GetUserProvider(ctx context.Context, arg GetUserProviderParams
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
 
 const getUserProviderByName = `-- name: GetUserProviderByName :one
 SELECT
-  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at
+  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at, p.project_id
 FROM providers p
 INNER JOIN users u ON p.user_id = u.id
 WHERE p.name = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
@@ -264,13 +348,14 @@ func (q *Queries) GetUserProviderByName(ctx context.Context, arg GetUserProvider
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
 
 const getUserProviders = `-- name: GetUserProviders :many
 SELECT
-  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at
+  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at, p.project_id
 FROM providers p
 INNER JOIN users u ON p.user_id = u.id
 WHERE p.user_id = $1 AND p.deleted_at IS NULL
@@ -295,6 +380,7 @@ func (q *Queries) GetUserProviders(ctx context.Context, userID int64) ([]Provide
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -311,7 +397,7 @@ func (q *Queries) GetUserProviders(ctx context.Context, userID int64) ([]Provide
 
 const getUserProvidersByType = `-- name: GetUserProvidersByType :many
 SELECT
-  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at
+  p.id, p.user_id, p.type, p.name, p.config, p.created_at, p.updated_at, p.deleted_at, p.project_id
 FROM providers p
 INNER JOIN users u ON p.user_id = u.id
 WHERE p.user_id = $1 AND p.type = $2 AND p.deleted_at IS NULL
@@ -341,6 +427,7 @@ func (q *Queries) GetUserProvidersByType(ctx context.Context, arg GetUserProvide
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -359,7 +446,7 @@ const updateProvider = `-- name: UpdateProvider :one
 UPDATE providers
 SET config = $2, name = $3
 WHERE id = $1
-RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at
+RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at, project_id
 `
 
 type UpdateProviderParams struct {
@@ -380,6 +467,36 @@ func (q *Queries) UpdateProvider(ctx context.Context, arg UpdateProviderParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProjectID,
+	)
+	return i, err
+}
+
+const updateProviderProject = `-- name: UpdateProviderProject :one
+UPDATE providers
+SET project_id = $1
+WHERE id = $2 AND deleted_at IS NULL
+RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at, project_id
+`
+
+type UpdateProviderProjectParams struct {
+	ProjectID sql.NullInt64 `json:"project_id"`
+	ID        int64         `json:"id"`
+}
+
+func (q *Queries) UpdateProviderProject(ctx context.Context, arg UpdateProviderProjectParams) (Provider, error) {
+	row := q.db.QueryRowContext(ctx, updateProviderProject, arg.ProjectID, arg.ID)
+	var i Provider
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Type,
+		&i.Name,
+		&i.Config,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -388,7 +505,7 @@ const updateUserProvider = `-- name: UpdateUserProvider :one
 UPDATE providers
 SET config = $3, name = $4
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at
+RETURNING id, user_id, type, name, config, created_at, updated_at, deleted_at, project_id
 `
 
 type UpdateUserProviderParams struct {
@@ -415,6 +532,7 @@ func (q *Queries) UpdateUserProvider(ctx context.Context, arg UpdateUserProvider
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
@@ -7,34 +7,57 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AcquireBackendLeader(ctx context.Context, arg AcquireBackendLeaderParams) (BackendLeader, error)
+	AcquireFlowLease(ctx context.Context, arg AcquireFlowLeaseParams) (FlowLease, error)
 	AddFavoriteFlow(ctx context.Context, arg AddFavoriteFlowParams) (UserPreference, error)
+	// Count an agent report on documents returned by an earlier search.
+	// Unknown document IDs are ignored; returns the number of documents counted.
+	AddKnowledgeDocumentFeedback(ctx context.Context, arg AddKnowledgeDocumentFeedbackParams) (int64, error)
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	// Moves the target vectors into place, records the target model for the
+	// collection and marks the migration completed in a single statement.
+	CompleteEmbeddingMigration(ctx context.Context, id int64) (EmbeddingMigration, error)
 	CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error)
 	CreateAgentLog(ctx context.Context, arg CreateAgentLogParams) (Agentlog, error)
 	CreateAssistant(ctx context.Context, arg CreateAssistantParams) (Assistant, error)
 	CreateAssistantLog(ctx context.Context, arg CreateAssistantLogParams) (Assistantlog, error)
 	CreateContainer(ctx context.Context, arg CreateContainerParams) (Container, error)
+	CreateEmbeddingMigration(ctx context.Context, arg CreateEmbeddingMigrationParams) (EmbeddingMigration, error)
 	CreateFlow(ctx context.Context, arg CreateFlowParams) (Flow, error)
 	CreateFlowTemplate(ctx context.Context, arg CreateFlowTemplateParams) (FlowTemplate, error)
+	CreateGraphEpisode(ctx context.Context, arg CreateGraphEpisodeParams) (GraphEpisode, error)
+	CreateKnowledgeImportJob(ctx context.Context, arg CreateKnowledgeImportJobParams) (KnowledgeImportJob, error)
 	CreateMsgChain(ctx context.Context, arg CreateMsgChainParams) (Msgchain, error)
 	CreateMsgLog(ctx context.Context, arg CreateMsgLogParams) (Msglog, error)
+	CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error)
 	CreateProvider(ctx context.Context, arg CreateProviderParams) (Provider, error)
 	CreateResultAssistantLog(ctx context.Context, arg CreateResultAssistantLogParams) (Assistantlog, error)
 	CreateResultMsgLog(ctx context.Context, arg CreateResultMsgLogParams) (Msglog, error)
+	CreateRole(ctx context.Context, name string) (Role, error)
 	CreateScreenshot(ctx context.Context, arg CreateScreenshotParams) (Screenshot, error)
 	CreateSearchLog(ctx context.Context, arg CreateSearchLogParams) (Searchlog, error)
+	CreateSubscriptionPayload(ctx context.Context, payload string) (int64, error)
 	CreateSubtask(ctx context.Context, arg CreateSubtaskParams) (Subtask, error)
 	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
 	CreateTermLog(ctx context.Context, arg CreateTermLogParams) (Termlog, error)
 	CreateToolcall(ctx context.Context, arg CreateToolcallParams) (Toolcall, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserAnonymizationPattern(ctx context.Context, arg CreateUserAnonymizationPatternParams) (AnonymizationPattern, error)
 	CreateUserPreferences(ctx context.Context, arg CreateUserPreferencesParams) (UserPreference, error)
 	CreateUserPrompt(ctx context.Context, arg CreateUserPromptParams) (Prompt, error)
 	CreateVectorStoreLog(ctx context.Context, arg CreateVectorStoreLogParams) (Vecstorelog, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
 	DeleteAPIToken(ctx context.Context, id int64) (ApiToken, error)
 	DeleteAssistant(ctx context.Context, id int64) (Assistant, error)
+	DeleteBackendInstance(ctx context.Context, name string) error
+	DeleteExpiredBackendInstances(ctx context.Context, expiresAt time.Time) error
 	DeleteFavoriteFlow(ctx context.Context, arg DeleteFavoriteFlowParams) (UserPreference, error)
 	DeleteFlow(ctx context.Context, id int64) (Flow, error)
 	DeleteFlowAssistantLog(ctx context.Context, id int64) error
@@ -46,13 +69,19 @@ type Querier interface {
 	DeleteFlowTemplate(ctx context.Context, arg DeleteFlowTemplateParams) error
 	// Delete a knowledge document by UUID (admin — no user_id check).
 	DeleteKnowledgeDocument(ctx context.Context, uuid sql.NullString) error
+	DeleteKnowledgeDocumentVote(ctx context.Context, arg DeleteKnowledgeDocumentVoteParams) error
+	DeleteProject(ctx context.Context, id int64) (Project, error)
+	DeleteProjectMember(ctx context.Context, arg DeleteProjectMemberParams) error
 	DeletePrompt(ctx context.Context, id int64) error
 	DeleteProvider(ctx context.Context, id int64) (Provider, error)
+	DeleteRole(ctx context.Context, roleID int64) (Role, error)
+	DeleteSubscriptionPayloads(ctx context.Context, createdAt time.Time) error
 	DeleteSubtask(ctx context.Context, id int64) error
 	DeleteSubtasks(ctx context.Context, ids []int64) error
 	DeleteUser(ctx context.Context, id int64) error
 	DeleteUserAPIToken(ctx context.Context, arg DeleteUserAPITokenParams) (ApiToken, error)
 	DeleteUserAPITokenByTokenID(ctx context.Context, arg DeleteUserAPITokenByTokenIDParams) (ApiToken, error)
+	DeleteUserAnonymizationPattern(ctx context.Context, arg DeleteUserAnonymizationPatternParams) error
 	// Delete a knowledge document by UUID, only if it belongs to the given user.
 	DeleteUserKnowledgeDocument(ctx context.Context, arg DeleteUserKnowledgeDocumentParams) error
 	DeleteUserPreferences(ctx context.Context, userID int64) error
@@ -64,6 +93,27 @@ type Querier interface {
 	// (providers_name_user_id_unique is partial on deleted_at IS NULL). Mirrors the
 	// guard GetUserProvider already applies on the rename path.
 	DeleteUserProvider(ctx context.Context, arg DeleteUserProviderParams) (Provider, error)
+	DeleteUserProviderCapabilities(ctx context.Context, arg DeleteUserProviderCapabilitiesParams) error
+	DeleteWebhook(ctx context.Context, id int64) (Webhook, error)
+	// Reports whether the user already owns a document with exactly this content.
+	// hash    md5 hex digest of the stored (trimmed) document text
+	// user_id owner filter as a decimal text string (e.g. "42")
+	ExistsUserKnowledgeDocumentHash(ctx context.Context, arg ExistsUserKnowledgeDocumentHashParams) (bool, error)
+	// List non-memory knowledge documents for export to a portable archive.
+	// user_id         owner filter as a decimal text string; empty for all users
+	// with_embeddings return the embedding vector as text ('[f1,f2,...]'), empty otherwise
+	ExportKnowledgeDocuments(ctx context.Context, arg ExportKnowledgeDocumentsParams) ([]ExportKnowledgeDocumentsRow, error)
+	FailInterruptedKnowledgeImportJobs(ctx context.Context, error sql.NullString) error
+	// Find a document of the user that an archived document would collide with:
+	// the same document of this instance, a copy imported earlier from the same
+	// origin, or a document with identical content. Provenance matches win over
+	// content matches. Returns sql.ErrNoRows when there is no conflict.
+	// user_id         owner filter as a decimal text string (e.g. "42")
+	// local_id        UUID of the archived document if this instance exported it, empty otherwise
+	// origin_instance installation ID the archived document originates from
+	// origin_id       UUID of the archived document on its origin instance
+	// hash            md5 hex digest of the archived document text
+	FindUserKnowledgeDocumentConflict(ctx context.Context, arg FindUserKnowledgeDocumentConflictParams) (string, error)
 	GetAPIToken(ctx context.Context, id int64) (ApiToken, error)
 	GetAPITokenByTokenID(ctx context.Context, tokenID string) (ApiToken, error)
 	GetAPITokens(ctx context.Context) ([]ApiToken, error)
@@ -78,23 +128,41 @@ type Querier interface {
 	GetAssistantUseAgents(ctx context.Context, id int64) (bool, error)
 	// Get total count of assistants for a specific flow
 	GetAssistantsCountForFlow(ctx context.Context, flowID int64) (int64, error)
+	GetBackendInstances(ctx context.Context) ([]GetBackendInstancesRow, error)
+	GetBackendLeader(ctx context.Context) (BackendLeader, error)
 	GetCallToolcall(ctx context.Context, callID string) (Toolcall, error)
 	GetContainerTermLogs(ctx context.Context, containerID int64) ([]Termlog, error)
 	GetContainers(ctx context.Context) ([]Container, error)
+	GetEmbeddingCollection(ctx context.Context, name string) (EmbeddingCollection, error)
+	// Counts the vectors of a collection and those still waiting for a vector of
+	// the migration target; dimensions is taken from any stored vector, 0 when empty.
+	GetEmbeddingCollectionStats(ctx context.Context, collection string) (GetEmbeddingCollectionStatsRow, error)
+	GetEmbeddingMigration(ctx context.Context, id int64) (EmbeddingMigration, error)
+	GetEnabledWebhooks(ctx context.Context) ([]Webhook, error)
 	GetFlow(ctx context.Context, id int64) (Flow, error)
 	GetFlowAgentLog(ctx context.Context, arg GetFlowAgentLogParams) (Agentlog, error)
 	GetFlowAgentLogs(ctx context.Context, flowID int64) ([]Agentlog, error)
+	GetFlowAgentLogsPage(ctx context.Context, arg GetFlowAgentLogsPageParams) ([]Agentlog, error)
+	GetFlowAnonymizationEntries(ctx context.Context, flowID int64) ([]FlowAnonymizationEntry, error)
+	GetFlowAnonymizationEntry(ctx context.Context, arg GetFlowAnonymizationEntryParams) (FlowAnonymizationEntry, error)
 	GetFlowAssistant(ctx context.Context, arg GetFlowAssistantParams) (Assistant, error)
 	GetFlowAssistantLog(ctx context.Context, id int64) (Assistantlog, error)
 	GetFlowAssistantLogs(ctx context.Context, arg GetFlowAssistantLogsParams) ([]Assistantlog, error)
+	GetFlowAssistantLogsPage(ctx context.Context, arg GetFlowAssistantLogsPageParams) ([]Assistantlog, error)
 	GetFlowAssistants(ctx context.Context, flowID int64) ([]Assistant, error)
 	GetFlowContainers(ctx context.Context, flowID int64) ([]Container, error)
+	GetFlowCredential(ctx context.Context, arg GetFlowCredentialParams) (FlowCredential, error)
+	GetFlowCredentials(ctx context.Context, flowID int64) ([]FlowCredential, error)
+	GetFlowLease(ctx context.Context, flowID int64) (FlowLease, error)
+	GetFlowLeases(ctx context.Context) ([]FlowLease, error)
 	GetFlowMsgChains(ctx context.Context, flowID int64) ([]Msgchain, error)
 	GetFlowMsgLogs(ctx context.Context, flowID int64) ([]Msglog, error)
+	GetFlowMsgLogsPage(ctx context.Context, arg GetFlowMsgLogsPageParams) ([]Msglog, error)
 	GetFlowPrimaryContainer(ctx context.Context, flowID int64) (Container, error)
 	GetFlowScreenshots(ctx context.Context, flowID int64) ([]Screenshot, error)
 	GetFlowSearchLog(ctx context.Context, arg GetFlowSearchLogParams) (Searchlog, error)
 	GetFlowSearchLogs(ctx context.Context, flowID int64) ([]Searchlog, error)
+	GetFlowSearchLogsPage(ctx context.Context, arg GetFlowSearchLogsPageParams) ([]Searchlog, error)
 	// ==================== Flows Analytics Queries ====================
 	// Get total count of tasks, subtasks, and assistants for a specific flow
 	GetFlowStats(ctx context.Context, id int64) (GetFlowStatsRow, error)
@@ -105,10 +173,13 @@ type Querier interface {
 	GetFlowTaskTypeLastMsgChain(ctx context.Context, arg GetFlowTaskTypeLastMsgChainParams) (Msgchain, error)
 	GetFlowTasks(ctx context.Context, flowID int64) ([]Task, error)
 	GetFlowTemplate(ctx context.Context, arg GetFlowTemplateParams) (FlowTemplate, error)
+	GetFlowTemplatesByIDs(ctx context.Context, ids []int64) ([]FlowTemplate, error)
 	GetFlowTemplatesByUserID(ctx context.Context, userID int64) ([]FlowTemplate, error)
 	GetFlowTermLogs(ctx context.Context, flowID int64) ([]Termlog, error)
+	GetFlowTermLogsPage(ctx context.Context, arg GetFlowTermLogsPageParams) ([]Termlog, error)
 	GetFlowToolcall(ctx context.Context, arg GetFlowToolcallParams) (Toolcall, error)
 	GetFlowToolcalls(ctx context.Context, flowID int64) ([]Toolcall, error)
+	GetFlowToolcallsPage(ctx context.Context, arg GetFlowToolcallsPageParams) ([]Toolcall, error)
 	// ==================== Toolcalls Analytics Queries ====================
 	// Get total execution time and count of toolcalls for a specific flow
 	GetFlowToolcallsStats(ctx context.Context, flowID int64) (GetFlowToolcallsStatsRow, error)
@@ -116,6 +187,7 @@ type Querier interface {
 	GetFlowUsageStats(ctx context.Context, flowID int64) (GetFlowUsageStatsRow, error)
 	GetFlowVectorStoreLog(ctx context.Context, arg GetFlowVectorStoreLogParams) (Vecstorelog, error)
 	GetFlowVectorStoreLogs(ctx context.Context, flowID int64) ([]Vecstorelog, error)
+	GetFlowVectorStoreLogsPage(ctx context.Context, arg GetFlowVectorStoreLogsPageParams) ([]Vecstorelog, error)
 	GetFlows(ctx context.Context) ([]Flow, error)
 	// Get flow IDs created in the last 3 months for analytics
 	GetFlowsForPeriodLast3Months(ctx context.Context, userID int64) ([]GetFlowsForPeriodLast3MonthsRow, error)
@@ -129,20 +201,58 @@ type Querier interface {
 	GetFlowsStatsByDayLastMonth(ctx context.Context, userID int64) ([]GetFlowsStatsByDayLastMonthRow, error)
 	// Get flows stats by day for the last week
 	GetFlowsStatsByDayLastWeek(ctx context.Context, userID int64) ([]GetFlowsStatsByDayLastWeekRow, error)
+	GetGraphEntitiesByIDs(ctx context.Context, ids []uuid.UUID) ([]GraphEntity, error)
+	GetGraphEntity(ctx context.Context, id uuid.UUID) (GraphEntity, error)
+	// Relations touching any of the given entities in either direction, the
+	// frontier step of a graph traversal. An empty edge_types list keeps all names.
+	GetGraphEntityRelations(ctx context.Context, arg GetGraphEntityRelationsParams) ([]GraphRelation, error)
+	// Entities mentioned by any of the given episodes, most mentioned first.
+	GetGraphEpisodeEntities(ctx context.Context, arg GetGraphEpisodeEntitiesParams) ([]GraphEntity, error)
+	// The last lim episodes of a group, oldest first.
+	GetGraphGroupEpisodes(ctx context.Context, arg GetGraphGroupEpisodesParams) ([]GraphEpisode, error)
 	// Fetch a single knowledge document by its UUID (admin view — no user_id check).
 	GetKnowledgeDocument(ctx context.Context, uuid string) (GetKnowledgeDocumentRow, error)
+	// Quality signals of documents given by UUID or by md5 hex digest of their
+	// text; search tools only know the text of vector store results.
+	GetKnowledgeDocumentSignals(ctx context.Context, arg GetKnowledgeDocumentSignalsParams) ([]GetKnowledgeDocumentSignalsRow, error)
+	GetKnowledgeImportJob(ctx context.Context, id int64) (KnowledgeImportJob, error)
+	GetKnowledgeImportJobs(ctx context.Context) ([]KnowledgeImportJob, error)
+	GetLatestEmbeddingMigration(ctx context.Context, collection string) (EmbeddingMigration, error)
 	GetMsgChain(ctx context.Context, id int64) (Msgchain, error)
 	// Get all msgchains for a flow (including task and subtask level)
 	GetMsgchainsForFlow(ctx context.Context, flowID int64) ([]GetMsgchainsForFlowRow, error)
+	// Running flows nobody drives, the flows changed lately are skipped because
+	// a replica which is creating or loading them takes the lease right after
+	GetOrphanedFlows(ctx context.Context, graceSeconds int32) ([]Flow, error)
+	GetOwnerFlowLeases(ctx context.Context, owner string) ([]FlowLease, error)
+	// Returns documents of a collection that have no vector of the migration
+	// target yet, including documents added while the migration is running.
+	GetPendingReembedDocuments(ctx context.Context, arg GetPendingReembedDocumentsParams) ([]GetPendingReembedDocumentsRow, error)
+	GetProject(ctx context.Context, id int64) (Project, error)
+	GetProjectFlowTemplates(ctx context.Context, projectID sql.NullInt64) ([]FlowTemplate, error)
+	GetProjectFlows(ctx context.Context, projectID sql.NullInt64) ([]Flow, error)
+	GetProjectMember(ctx context.Context, arg GetProjectMemberParams) (ProjectMember, error)
+	GetProjectMemberUserIDs(ctx context.Context, projectID int64) ([]int64, error)
+	GetProjectMembers(ctx context.Context, projectID int64) ([]GetProjectMembersRow, error)
+	GetProjectProviders(ctx context.Context, projectID sql.NullInt64) ([]Provider, error)
+	GetProjectResources(ctx context.Context, projectID sql.NullInt64) ([]UserResource, error)
+	GetProjects(ctx context.Context) ([]Project, error)
 	GetPrompts(ctx context.Context) ([]Prompt, error)
 	GetProvider(ctx context.Context, id int64) (Provider, error)
+	GetProviderCapabilities(ctx context.Context, providerID int64) ([]ProviderCapability, error)
 	GetProviders(ctx context.Context) ([]Provider, error)
 	GetProvidersByType(ctx context.Context, type_ ProviderType) ([]Provider, error)
 	GetRole(ctx context.Context, id int64) (GetRoleRow, error)
 	GetRoleByName(ctx context.Context, name string) (GetRoleByNameRow, error)
+	// Number of users and API tokens, revoked and deleted ones included, which
+	// reference the role and keep it from being deleted.
+	GetRoleUsage(ctx context.Context, roleID int64) (int64, error)
+	GetRoleUserIDs(ctx context.Context, roleID int64) ([]int64, error)
 	GetRoles(ctx context.Context) ([]GetRolesRow, error)
 	GetRunningContainers(ctx context.Context) ([]Container, error)
+	GetRunningEmbeddingMigrations(ctx context.Context) ([]EmbeddingMigration, error)
 	GetScreenshot(ctx context.Context, id int64) (Screenshot, error)
+	GetSubscriptionPayload(ctx context.Context, id int64) (string, error)
 	GetSubtask(ctx context.Context, id int64) (Subtask, error)
 	GetSubtaskAgentLogs(ctx context.Context, subtaskID sql.NullInt64) ([]Agentlog, error)
 	GetSubtaskMsgChains(ctx context.Context, subtaskID sql.NullInt64) ([]Msgchain, error)
@@ -203,6 +313,8 @@ type Querier interface {
 	GetUserAPIToken(ctx context.Context, arg GetUserAPITokenParams) (ApiToken, error)
 	GetUserAPITokenByTokenID(ctx context.Context, arg GetUserAPITokenByTokenIDParams) (ApiToken, error)
 	GetUserAPITokens(ctx context.Context, userID int64) ([]ApiToken, error)
+	GetUserAnonymizationPattern(ctx context.Context, arg GetUserAnonymizationPatternParams) (AnonymizationPattern, error)
+	GetUserAnonymizationPatterns(ctx context.Context, userID int64) ([]AnonymizationPattern, error)
 	GetUserByHash(ctx context.Context, hash string) (GetUserByHashRow, error)
 	GetUserContainers(ctx context.Context, userID int64) ([]Container, error)
 	GetUserFlow(ctx context.Context, arg GetUserFlowParams) (Flow, error)
@@ -223,12 +335,22 @@ type Querier interface {
 	GetUserFlows(ctx context.Context, userID int64) ([]Flow, error)
 	// Fetch a single knowledge document by UUID, scoped to a specific user.
 	GetUserKnowledgeDocument(ctx context.Context, arg GetUserKnowledgeDocumentParams) (GetUserKnowledgeDocumentRow, error)
+	GetUserKnowledgeImportJob(ctx context.Context, arg GetUserKnowledgeImportJobParams) (KnowledgeImportJob, error)
+	GetUserKnowledgeImportJobs(ctx context.Context, userID int64) ([]KnowledgeImportJob, error)
 	GetUserPreferencesByUserID(ctx context.Context, userID int64) (UserPreference, error)
+	GetUserProject(ctx context.Context, arg GetUserProjectParams) (GetUserProjectRow, error)
+	// A provider another member shares through a project of the user. When
+	// several projects share one with that name, the oldest provider wins.
+	GetUserProjectProviderByName(ctx context.Context, arg GetUserProjectProviderByNameParams) (Provider, error)
+	GetUserProjectProviders(ctx context.Context, userID int64) ([]Provider, error)
+	GetUserProjects(ctx context.Context, userID int64) ([]GetUserProjectsRow, error)
 	GetUserPrompt(ctx context.Context, arg GetUserPromptParams) (Prompt, error)
 	GetUserPromptByType(ctx context.Context, arg GetUserPromptByTypeParams) (Prompt, error)
 	GetUserPrompts(ctx context.Context, userID int64) ([]Prompt, error)
 	GetUserProvider(ctx context.Context, arg GetUserProviderParams) (Provider, error)
 	GetUserProviderByName(ctx context.Context, arg GetUserProviderByNameParams) (Provider, error)
+	GetUserProviderCapabilities(ctx context.Context, userID int64) ([]ProviderCapability, error)
+	GetUserProviderCapabilitiesByType(ctx context.Context, arg GetUserProviderCapabilitiesByTypeParams) ([]ProviderCapability, error)
 	GetUserProviders(ctx context.Context, userID int64) ([]Provider, error)
 	GetUserProvidersByType(ctx context.Context, arg GetUserProvidersByTypeParams) ([]Provider, error)
 	GetUserResourceByID(ctx context.Context, id int64) (UserResource, error)
@@ -242,17 +364,57 @@ type Querier interface {
 	// Get total toolcalls stats for a user
 	GetUserTotalToolcallsStats(ctx context.Context, userID int64) (GetUserTotalToolcallsStatsRow, error)
 	GetUserTotalUsageStats(ctx context.Context, userID int64) (GetUserTotalUsageStatsRow, error)
+	GetUserWebhook(ctx context.Context, arg GetUserWebhookParams) (Webhook, error)
+	GetUserWebhooks(ctx context.Context, userID int64) ([]Webhook, error)
 	GetUsers(ctx context.Context) ([]GetUsersRow, error)
+	GetWebhook(ctx context.Context, id int64) (Webhook, error)
+	GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhooks(ctx context.Context) ([]Webhook, error)
 	// Insert a document with a pre-computed embedding vector and return its UUID.
 	// embedding must be formatted as a PostgreSQL vector literal: '[f1,f2,...]'
 	// cmetadata must be valid JSON text.
 	InsertKnowledgeDocument(ctx context.Context, arg InsertKnowledgeDocumentParams) (string, error)
+	// Marks a document the target model failed to embed as processed; it keeps
+	// its old vector and stays reachable through full-text search only.
+	KeepReembedDocumentVector(ctx context.Context, uuid sql.NullString) error
+	LinkGraphEntityEpisode(ctx context.Context, arg LinkGraphEntityEpisodeParams) error
 	// List all knowledge documents excluding the noisy memory type (admin view).
 	ListAllKnowledgeDocuments(ctx context.Context) ([]ListAllKnowledgeDocumentsRow, error)
 	// List non-memory knowledge documents belonging to a specific flow (admin scoped).
 	ListFlowKnowledgeDocuments(ctx context.Context, flowID sql.NullString) ([]ListFlowKnowledgeDocumentsRow, error)
+	// List all non-memory knowledge documents shared with a project.
+	ListProjectKnowledgeDocuments(ctx context.Context, projectID sql.NullString) ([]ListProjectKnowledgeDocumentsRow, error)
 	// List all non-memory knowledge documents owned by a specific user (user-scoped view).
 	ListUserKnowledgeDocuments(ctx context.Context, userID sql.NullString) ([]ListUserKnowledgeDocumentsRow, error)
+	NotifySubscriptions(ctx context.Context, arg NotifySubscriptionsParams) error
+	// Count one retrieval for each document returned to an agent.
+	RecordKnowledgeRetrievals(ctx context.Context, ids []string) error
+	RegisterBackendInstance(ctx context.Context, arg RegisterBackendInstanceParams) (BackendInstance, error)
+	ReleaseBackendLeader(ctx context.Context, owner string) error
+	ReleaseFlowLease(ctx context.Context, arg ReleaseFlowLeaseParams) error
+	ReleaseOwnerFlowLeases(ctx context.Context, owner string) error
+	RenewBackendInstance(ctx context.Context, arg RenewBackendInstanceParams) (BackendInstance, error)
+	RenewFlowLeases(ctx context.Context, arg RenewFlowLeasesParams) ([]FlowLease, error)
+	// Drops partial target vectors left by an abandoned migration.
+	ResetReembedVectors(ctx context.Context, collection string) error
+	// Ranked full-text hits of flows, tasks, subtasks and logs, the kinds of all_kinds
+	// are searched in every flow and the kinds of own_kinds in the flows of the user and
+	// of the projects the user is a member of; a non-empty flow_ids limits both
+	SearchFlows(ctx context.Context, arg SearchFlowsParams) ([]SearchFlowsRow, error)
+	// Entities of a group seen within [time_start, time_end], matched by full-text
+	// search or by their name appearing verbatim in the query (IP addresses and
+	// CVE IDs are not split into useful lexemes). An empty query matches all,
+	// an empty labels list keeps all labels. Ordered by score, then recency.
+	SearchGraphEntities(ctx context.Context, arg SearchGraphEntitiesParams) ([]SearchGraphEntitiesRow, error)
+	// Episodes of a group valid within [time_start, time_end] matching the query
+	// by full-text search; an empty query matches all. successful_only keeps tool
+	// executions which reported success. Ordered by score, then recency.
+	SearchGraphEpisodes(ctx context.Context, arg SearchGraphEpisodesParams) ([]SearchGraphEpisodesRow, error)
+	// Relations of a group seen within [time_start, time_end] whose fact matches
+	// the query by full-text search; an empty query matches all, an empty
+	// edge_types list keeps all names. Ordered by score, then recency.
+	SearchGraphRelations(ctx context.Context, arg SearchGraphRelationsParams) ([]SearchGraphRelationsRow, error)
 	// Vector similarity search over all knowledge documents (admin view, no user filter).
 	// Returns rows ordered by cosine similarity descending (highest score first).
 	// embedding    query vector as a PostgreSQL vector literal, e.g. '[0.1,0.2,...]'
@@ -260,6 +422,16 @@ type Querier interface {
 	//              e.g. pass 0.8 to get documents with similarity score > 0.2
 	// lim          maximum number of rows to return
 	SearchKnowledgeDocuments(ctx context.Context, arg SearchKnowledgeDocumentsParams) ([]SearchKnowledgeDocumentsRow, error)
+	// Full-text and trigram search over knowledge documents, the lexical half of
+	// hybrid retrieval. Catches exact tokens (CVE IDs, tool flags, hostnames)
+	// which embeddings tend to blur. Returns rows ordered by lexical score descending.
+	// query       raw search text; its terms are OR-ed into a tsquery
+	// filters     JSON object of cmetadata key/value pairs compared as text,
+	//             e.g. '{"doc_type":"guide","guide_type":"pentest"}'; '{}' for none
+	// user_id     owner filter as a decimal text string; empty for all users
+	// with_memory include memory documents (excluded from the knowledge UI)
+	// lim         maximum number of rows to return
+	SearchKnowledgeDocumentsText(ctx context.Context, arg SearchKnowledgeDocumentsTextParams) ([]SearchKnowledgeDocumentsTextRow, error)
 	// Vector similarity search scoped to a specific user (by cmetadata user_id).
 	// Returns rows ordered by cosine similarity descending (highest score first).
 	// embedding    query vector as a PostgreSQL vector literal, e.g. '[0.1,0.2,...]'
@@ -267,6 +439,11 @@ type Querier interface {
 	// lim          maximum number of rows to return
 	// user_id      owner filter as a decimal text string (e.g. "42")
 	SearchUserKnowledgeDocuments(ctx context.Context, arg SearchUserKnowledgeDocumentsParams) ([]SearchUserKnowledgeDocumentsRow, error)
+	// embedding must be formatted as a PostgreSQL vector literal: '[f1,f2,...]'
+	SetReembedDocumentVector(ctx context.Context, arg SetReembedDocumentVectorParams) error
+	// Replaces the privileges of the role in one statement, the rows which are
+	// still granted are kept as they are.
+	SetRolePrivileges(ctx context.Context, arg SetRolePrivilegesParams) error
 	UpdateAPIToken(ctx context.Context, arg UpdateAPITokenParams) (ApiToken, error)
 	UpdateAssistant(ctx context.Context, arg UpdateAssistantParams) (Assistant, error)
 	UpdateAssistantLanguage(ctx context.Context, arg UpdateAssistantLanguageParams) (Assistant, error)
@@ -287,11 +464,14 @@ type Querier interface {
 	UpdateContainerImage(ctx context.Context, arg UpdateContainerImageParams) (Container, error)
 	UpdateContainerStatus(ctx context.Context, arg UpdateContainerStatusParams) (Container, error)
 	UpdateContainerStatusLocalID(ctx context.Context, arg UpdateContainerStatusLocalIDParams) (Container, error)
+	UpdateEmbeddingMigration(ctx context.Context, arg UpdateEmbeddingMigrationParams) (EmbeddingMigration, error)
 	UpdateFlow(ctx context.Context, arg UpdateFlowParams) (Flow, error)
 	UpdateFlowLanguage(ctx context.Context, arg UpdateFlowLanguageParams) (Flow, error)
+	UpdateFlowProject(ctx context.Context, arg UpdateFlowProjectParams) (Flow, error)
 	UpdateFlowProvider(ctx context.Context, arg UpdateFlowProviderParams) (Flow, error)
 	UpdateFlowStatus(ctx context.Context, arg UpdateFlowStatusParams) (Flow, error)
 	UpdateFlowTemplate(ctx context.Context, arg UpdateFlowTemplateParams) (FlowTemplate, error)
+	UpdateFlowTemplateProject(ctx context.Context, arg UpdateFlowTemplateProjectParams) (FlowTemplate, error)
 	UpdateFlowTitle(ctx context.Context, arg UpdateFlowTitleParams) (Flow, error)
 	UpdateFlowToolCallIDTemplate(ctx context.Context, arg UpdateFlowToolCallIDTemplateParams) (Flow, error)
 	// Bulk-renames every flow row of a user still pointing at a provider's old name
@@ -309,11 +489,15 @@ type Querier interface {
 	// therefore a configured embedder — is unnecessary.
 	// cmetadata must be valid JSON text.
 	UpdateKnowledgeDocumentMetadata(ctx context.Context, arg UpdateKnowledgeDocumentMetadataParams) (UpdateKnowledgeDocumentMetadataRow, error)
+	UpdateKnowledgeImportJob(ctx context.Context, arg UpdateKnowledgeImportJobParams) (KnowledgeImportJob, error)
 	UpdateMsgChain(ctx context.Context, arg UpdateMsgChainParams) (Msgchain, error)
 	UpdateMsgChainUsage(ctx context.Context, arg UpdateMsgChainUsageParams) (Msgchain, error)
 	UpdateMsgLogResult(ctx context.Context, arg UpdateMsgLogResultParams) (Msglog, error)
+	UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error)
 	UpdatePrompt(ctx context.Context, arg UpdatePromptParams) (Prompt, error)
 	UpdateProvider(ctx context.Context, arg UpdateProviderParams) (Provider, error)
+	UpdateProviderProject(ctx context.Context, arg UpdateProviderProjectParams) (Provider, error)
+	UpdateRoleName(ctx context.Context, arg UpdateRoleNameParams) (Role, error)
 	UpdateSubtaskContext(ctx context.Context, arg UpdateSubtaskContextParams) (Subtask, error)
 	UpdateSubtaskFailedResult(ctx context.Context, arg UpdateSubtaskFailedResultParams) (Subtask, error)
 	UpdateSubtaskFinishedResult(ctx context.Context, arg UpdateSubtaskFinishedResultParams) (Subtask, error)
@@ -327,6 +511,7 @@ type Querier interface {
 	UpdateToolcallFinishedResult(ctx context.Context, arg UpdateToolcallFinishedResultParams) (Toolcall, error)
 	UpdateToolcallStatus(ctx context.Context, arg UpdateToolcallStatusParams) (Toolcall, error)
 	UpdateUserAPIToken(ctx context.Context, arg UpdateUserAPITokenParams) (ApiToken, error)
+	UpdateUserAnonymizationPattern(ctx context.Context, arg UpdateUserAnonymizationPatternParams) (AnonymizationPattern, error)
 	UpdateUserName(ctx context.Context, arg UpdateUserNameParams) (User, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
 	UpdateUserPasswordChangeRequired(ctx context.Context, arg UpdateUserPasswordChangeRequiredParams) (User, error)
@@ -334,8 +519,28 @@ type Querier interface {
 	UpdateUserPrompt(ctx context.Context, arg UpdateUserPromptParams) (Prompt, error)
 	UpdateUserPromptByType(ctx context.Context, arg UpdateUserPromptByTypeParams) (Prompt, error)
 	UpdateUserProvider(ctx context.Context, arg UpdateUserProviderParams) (Provider, error)
+	UpdateUserResourceProject(ctx context.Context, arg UpdateUserResourceProjectParams) (UserResource, error)
 	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
 	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error)
+	UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error)
+	UpdateWebhookDeliveryResult(ctx context.Context, arg UpdateWebhookDeliveryResultParams) (WebhookDelivery, error)
+	UpsertEmbeddingCollection(ctx context.Context, arg UpsertEmbeddingCollectionParams) (EmbeddingCollection, error)
+	// A value seen again keeps its first entry, so its placeholder never changes
+	// within a flow.
+	UpsertFlowAnonymizationEntry(ctx context.Context, arg UpsertFlowAnonymizationEntryParams) (FlowAnonymizationEntry, error)
+	// A credential is unique by type, username and host within a flow: storing
+	// it again replaces the secret and keeps the toolcall which found it first.
+	UpsertFlowCredential(ctx context.Context, arg UpsertFlowCredentialParams) (FlowCredential, error)
+	// Record a mention of an entity: a new entity is created, a known one gets
+	// its seen window widened and its summary replaced when a new one is given.
+	UpsertGraphEntity(ctx context.Context, arg UpsertGraphEntityParams) (GraphEntity, error)
+	// Record a mention of a fact between two entities, keeping the first time it
+	// was valid and pointing it to the latest source episode.
+	UpsertGraphRelation(ctx context.Context, arg UpsertGraphRelationParams) (GraphRelation, error)
+	// Set the vote of an operator on a document, replacing an earlier one.
+	UpsertKnowledgeDocumentVote(ctx context.Context, arg UpsertKnowledgeDocumentVoteParams) error
+	UpsertProjectMember(ctx context.Context, arg UpsertProjectMemberParams) (ProjectMember, error)
+	UpsertProviderCapability(ctx context.Context, arg UpsertProviderCapabilityParams) (ProviderCapability, error)
 	UpsertUserPreferences(ctx context.Context, arg UpsertUserPreferencesParams) (UserPreference, error)
 }
 
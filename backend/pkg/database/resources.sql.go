@@ -7,12 +7,13 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/lib/pq"
 )
 
 const getAllResourcesAll = `-- name: GetAllResourcesAll :many
-SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
 FROM user_resources
 ORDER BY updated_at DESC, name ASC
 `
@@ -36,6 +37,7 @@ func (q *Queries) GetAllResourcesAll(ctx context.Context) ([]UserResource, error
 			&i.IsDir,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -51,7 +53,7 @@ func (q *Queries) GetAllResourcesAll(ctx context.Context) ([]UserResource, error
 }
 
 const getAllResourcesInDir = `-- name: GetAllResourcesInDir :many
-SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
 FROM user_resources
 WHERE (path = $1 AND is_dir = true)
    OR (path LIKE $2 AND path NOT LIKE $3)
@@ -83,6 +85,7 @@ func (q *Queries) GetAllResourcesInDir(ctx context.Context, arg GetAllResourcesI
 			&i.IsDir,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -98,7 +101,7 @@ func (q *Queries) GetAllResourcesInDir(ctx context.Context, arg GetAllResourcesI
 }
 
 const getAllResourcesRecursive = `-- name: GetAllResourcesRecursive :many
-SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
 FROM user_resources
 WHERE path = $1 OR path LIKE $2
 ORDER BY updated_at DESC, name ASC
@@ -128,6 +131,7 @@ func (q *Queries) GetAllResourcesRecursive(ctx context.Context, arg GetAllResour
 			&i.IsDir,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -143,7 +147,7 @@ func (q *Queries) GetAllResourcesRecursive(ctx context.Context, arg GetAllResour
 }
 
 const getAllResourcesRoot = `-- name: GetAllResourcesRoot :many
-SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
 FROM user_resources
 WHERE path NOT LIKE '%/%'
 ORDER BY updated_at DESC, name ASC
@@ -168,6 +172,7 @@ func (q *Queries) GetAllResourcesRoot(ctx context.Context) ([]UserResource, erro
 			&i.IsDir,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -183,7 +188,7 @@ func (q *Queries) GetAllResourcesRoot(ctx context.Context) ([]UserResource, erro
 }
 
 const getUserResourceByID = `-- name: GetUserResourceByID :one
-SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
 FROM user_resources
 WHERE id = $1
 `
@@ -201,12 +206,13 @@ func (q *Queries) GetUserResourceByID(ctx context.Context, id int64) (UserResour
 		&i.IsDir,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProjectID,
 	)
 	return i, err
 }
 
 const getUserResourcesAll = `-- name: GetUserResourcesAll :many
-SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
 FROM user_resources
 WHERE user_id = $1
 ORDER BY updated_at DESC, name ASC
@@ -231,6 +237,7 @@ func (q *Queries) GetUserResourcesAll(ctx context.Context, userID int64) ([]User
 			&i.IsDir,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -246,7 +253,7 @@ func (q *Queries) GetUserResourcesAll(ctx context.Context, userID int64) ([]User
 }
 
 const getUserResourcesByIDs = `-- name: GetUserResourcesByIDs :many
-SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
 FROM user_resources
 WHERE id = ANY($1::bigint[])
 ORDER BY id ASC
@@ -271,6 +278,7 @@ func (q *Queries) GetUserResourcesByIDs(ctx context.Context, ids []int64) ([]Use
 			&i.IsDir,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -286,7 +294,7 @@ func (q *Queries) GetUserResourcesByIDs(ctx context.Context, ids []int64) ([]Use
 }
 
 const getUserResourcesInDir = `-- name: GetUserResourcesInDir :many
-SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
 FROM user_resources
 WHERE user_id = $1
   AND ((path = $2 AND is_dir = true)
@@ -325,6 +333,7 @@ func (q *Queries) GetUserResourcesInDir(ctx context.Context, arg GetUserResource
 			&i.IsDir,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -340,7 +349,7 @@ func (q *Queries) GetUserResourcesInDir(ctx context.Context, arg GetUserResource
 }
 
 const getUserResourcesRecursive = `-- name: GetUserResourcesRecursive :many
-SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
 FROM user_resources
 WHERE user_id = $1
   AND (path = $2 OR path LIKE $3)
@@ -372,6 +381,7 @@ func (q *Queries) GetUserResourcesRecursive(ctx context.Context, arg GetUserReso
 			&i.IsDir,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -387,7 +397,7 @@ func (q *Queries) GetUserResourcesRecursive(ctx context.Context, arg GetUserReso
 }
 
 const getUserResourcesRoot = `-- name: GetUserResourcesRoot :many
-SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
 FROM user_resources
 WHERE user_id = $1 AND path NOT LIKE '%/%'
 ORDER BY updated_at DESC, name ASC
@@ -412,6 +422,7 @@ func (q *Queries) GetUserResourcesRoot(ctx context.Context, userID int64) ([]Use
 			&i.IsDir,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
 		); err != nil {
 			return nil, err
 		}
@@ -425,3 +436,33 @@ func (q *Queries) GetUserResourcesRoot(ctx context.Context, userID int64) ([]Use
 	}
 	return items, nil
 }
+
+const updateUserResourceProject = `-- name: UpdateUserResourceProject :one
+UPDATE user_resources
+SET project_id = $1
+WHERE id = $2
+RETURNING id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
+`
+
+type UpdateUserResourceProjectParams struct {
+	ProjectID sql.NullInt64 `json:"project_id"`
+	ID        int64         `json:"id"`
+}
+
+func (q *Queries) UpdateUserResourceProject(ctx context.Context, arg UpdateUserResourceProjectParams) (UserResource, error) {
+	row := q.db.QueryRowContext(ctx, updateUserResourceProject, arg.ProjectID, arg.ID)
+	var i UserResource
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Hash,
+		&i.Name,
+		&i.Path,
+		&i.Size,
+		&i.IsDir,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ProjectID,
+	)
+	return i, err
+}
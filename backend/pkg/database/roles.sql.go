@@ -11,6 +11,39 @@ import (
 	"github.com/lib/pq"
 )
 
+const createRole = `-- name: CreateRole :one
+INSERT INTO roles (
+  name
+) VALUES (
+  $1
+)
+RETURNING id, name
+`
+
+func (q *Queries) CreateRole(ctx context.Context, name string) (Role, error) {
+	row := q.db.QueryRowContext(ctx, createRole, name)
+	var i Role
+	err := row.Scan(&i.ID, &i.Name)
+	return i, err
+}
+
+const deleteRole = `-- name: DeleteRole :one
+WITH removed AS (
+  DELETE FROM privileges
+  WHERE role_id = $1
+)
+DELETE FROM roles r
+WHERE r.id = $1
+RETURNING r.id, r.name
+`
+
+func (q *Queries) DeleteRole(ctx context.Context, roleID int64) (Role, error) {
+	row := q.db.QueryRowContext(ctx, deleteRole, roleID)
+	var i Role
+	err := row.Scan(&i.ID, &i.Name)
+	return i, err
+}
+
 const getRole = `-- name: GetRole :one
 SELECT
   r.id,
@@ -63,6 +96,51 @@ func (q *Queries) GetRoleByName(ctx context.Context, name string) (GetRoleByName
 	return i, err
 }
 
+const getRoleUsage = `-- name: GetRoleUsage :one
+SELECT
+  (SELECT COUNT(*) FROM users u WHERE u.role_id = $1)::bigint +
+  (SELECT COUNT(*) FROM api_tokens t WHERE t.role_id = $1)::bigint AS usage
+`
+
+// Number of users and API tokens, revoked and deleted ones included, which
+// reference the role and keep it from being deleted.
+func (q *Queries) GetRoleUsage(ctx context.Context, roleID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getRoleUsage, roleID)
+	var usage int64
+	err := row.Scan(&usage)
+	return usage, err
+}
+
+const getRoleUserIDs = `-- name: GetRoleUserIDs :many
+SELECT id
+FROM users
+WHERE role_id = $1
+ORDER BY id ASC
+`
+
+func (q *Queries) GetRoleUserIDs(ctx context.Context, roleID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getRoleUserIDs, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRoles = `-- name: GetRoles :many
 SELECT
   r.id,
@@ -104,3 +182,44 @@ func (q *Queries) GetRoles(ctx context.Context) ([]GetRolesRow, error) {
 	}
 	return items, nil
 }
+
+const setRolePrivileges = `-- name: SetRolePrivileges :exec
+WITH removed AS (
+  DELETE FROM privileges
+  WHERE role_id = $1::bigint AND NOT (name = ANY($2::text[]))
+)
+INSERT INTO privileges (role_id, name)
+SELECT $1::bigint, UNNEST($2::text[])
+ON CONFLICT DO NOTHING
+`
+
+type SetRolePrivilegesParams struct {
+	RoleID     int64    `json:"role_id"`
+	Privileges []string `json:"privileges"`
+}
+
+// Replaces the privileges of the role in one statement, the rows which are
+// still granted are kept as they are.
+func (q *Queries) SetRolePrivileges(ctx context.Context, arg SetRolePrivilegesParams) error {
+	_, err := q.db.ExecContext(ctx, setRolePrivileges, arg.RoleID, pq.Array(arg.Privileges))
+	return err
+}
+
+const updateRoleName = `-- name: UpdateRoleName :one
+UPDATE roles
+SET name = $2
+WHERE id = $1
+RETURNING id, name
+`
+
+type UpdateRoleNameParams struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (q *Queries) UpdateRoleName(ctx context.Context, arg UpdateRoleNameParams) (Role, error) {
+	row := q.db.QueryRowContext(ctx, updateRoleName, arg.ID, arg.Name)
+	var i Role
+	err := row.Scan(&i.ID, &i.Name)
+	return i, err
+}
@@ -8,6 +8,8 @@ package database
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const createSearchLog = `-- name: CreateSearchLog :one
@@ -139,6 +141,86 @@ func (q *Queries) GetFlowSearchLogs(ctx context.Context, flowID int64) ([]Search
 	return items, nil
 }
 
+const getFlowSearchLogsPage = `-- name: GetFlowSearchLogsPage :many
+SELECT
+  sl.id, sl.initiator, sl.executor, sl.engine, sl.query, sl.result, sl.flow_id, sl.task_id, sl.subtask_id, sl.created_at
+FROM searchlogs sl
+INNER JOIN flows f ON sl.flow_id = f.id
+WHERE sl.flow_id = $1 AND f.deleted_at IS NULL
+  AND ($2::bigint IS NULL OR sl.id > $2::bigint)
+  AND ($3::bigint IS NULL OR sl.id < $3::bigint)
+  AND ($4::timestamptz IS NULL OR sl.created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR sl.created_at < $5::timestamptz)
+  AND ($6::bigint IS NULL OR sl.task_id = $6::bigint)
+  AND ($7::bigint IS NULL OR sl.subtask_id = $7::bigint)
+  AND (cardinality($8::text[]) = 0 OR sl.executor::text = ANY($8::text[]))
+  AND ($9::text = '' OR search_document(sl.query, sl.result) @@ websearch_to_tsquery('english', $9::text))
+ORDER BY
+  CASE WHEN $10::bool THEN sl.id END DESC,
+  sl.id ASC
+LIMIT $11::int
+`
+
+type GetFlowSearchLogsPageParams struct {
+	FlowID    int64         `json:"flow_id"`
+	AfterID   sql.NullInt64 `json:"after_id"`
+	BeforeID  sql.NullInt64 `json:"before_id"`
+	Since     sql.NullTime  `json:"since"`
+	Until     sql.NullTime  `json:"until"`
+	TaskID    sql.NullInt64 `json:"task_id"`
+	SubtaskID sql.NullInt64 `json:"subtask_id"`
+	Types     []string      `json:"types"`
+	Search    string        `json:"search"`
+	Reverse   bool          `json:"reverse"`
+	PageSize  sql.NullInt32 `json:"page_size"`
+}
+
+func (q *Queries) GetFlowSearchLogsPage(ctx context.Context, arg GetFlowSearchLogsPageParams) ([]Searchlog, error) {
+	rows, err := q.db.QueryContext(ctx, getFlowSearchLogsPage,
+		arg.FlowID,
+		arg.AfterID,
+		arg.BeforeID,
+		arg.Since,
+		arg.Until,
+		arg.TaskID,
+		arg.SubtaskID,
+		pq.Array(arg.Types),
+		arg.Search,
+		arg.Reverse,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Searchlog
+	for rows.Next() {
+		var i Searchlog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Initiator,
+			&i.Executor,
+			&i.Engine,
+			&i.Query,
+			&i.Result,
+			&i.FlowID,
+			&i.TaskID,
+			&i.SubtaskID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSubtaskSearchLogs = `-- name: GetSubtaskSearchLogs :many
 SELECT
   sl.id, sl.initiator, sl.executor, sl.engine, sl.query, sl.result, sl.flow_id, sl.task_id, sl.subtask_id, sl.created_at
@@ -8,6 +8,8 @@ package database
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const createTermLog = `-- name: CreateTermLog :one
@@ -139,6 +141,84 @@ func (q *Queries) GetFlowTermLogs(ctx context.Context, flowID int64) ([]Termlog,
 	return items, nil
 }
 
+const getFlowTermLogsPage = `-- name: GetFlowTermLogsPage :many
+SELECT
+  tl.id, tl.type, tl.text, tl.container_id, tl.created_at, tl.flow_id, tl.task_id, tl.subtask_id
+FROM termlogs tl
+INNER JOIN flows f ON tl.flow_id = f.id
+WHERE tl.flow_id = $1 AND f.deleted_at IS NULL
+  AND ($2::bigint IS NULL OR tl.id > $2::bigint)
+  AND ($3::bigint IS NULL OR tl.id < $3::bigint)
+  AND ($4::timestamptz IS NULL OR tl.created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR tl.created_at < $5::timestamptz)
+  AND ($6::bigint IS NULL OR tl.task_id = $6::bigint)
+  AND ($7::bigint IS NULL OR tl.subtask_id = $7::bigint)
+  AND (cardinality($8::text[]) = 0 OR tl.type::text = ANY($8::text[]))
+  AND ($9::text = '' OR search_document(tl.text) @@ websearch_to_tsquery('english', $9::text))
+ORDER BY
+  CASE WHEN $10::bool THEN tl.id END DESC,
+  tl.id ASC
+LIMIT $11::int
+`
+
+type GetFlowTermLogsPageParams struct {
+	FlowID    int64         `json:"flow_id"`
+	AfterID   sql.NullInt64 `json:"after_id"`
+	BeforeID  sql.NullInt64 `json:"before_id"`
+	Since     sql.NullTime  `json:"since"`
+	Until     sql.NullTime  `json:"until"`
+	TaskID    sql.NullInt64 `json:"task_id"`
+	SubtaskID sql.NullInt64 `json:"subtask_id"`
+	Types     []string      `json:"types"`
+	Search    string        `json:"search"`
+	Reverse   bool          `json:"reverse"`
+	PageSize  sql.NullInt32 `json:"page_size"`
+}
+
+func (q *Queries) GetFlowTermLogsPage(ctx context.Context, arg GetFlowTermLogsPageParams) ([]Termlog, error) {
+	rows, err := q.db.QueryContext(ctx, getFlowTermLogsPage,
+		arg.FlowID,
+		arg.AfterID,
+		arg.BeforeID,
+		arg.Since,
+		arg.Until,
+		arg.TaskID,
+		arg.SubtaskID,
+		pq.Array(arg.Types),
+		arg.Search,
+		arg.Reverse,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Termlog
+	for rows.Next() {
+		var i Termlog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Text,
+			&i.ContainerID,
+			&i.CreatedAt,
+			&i.FlowID,
+			&i.TaskID,
+			&i.SubtaskID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSubtaskTermLogs = `-- name: GetSubtaskTermLogs :many
 SELECT
   tl.id, tl.type, tl.text, tl.container_id, tl.created_at, tl.flow_id, tl.task_id, tl.subtask_id
@@ -10,6 +10,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 const createToolcall = `-- name: CreateToolcall :one
@@ -214,6 +216,88 @@ func (q *Queries) GetFlowToolcalls(ctx context.Context, flowID int64) ([]Toolcal
 	return items, nil
 }
 
+const getFlowToolcallsPage = `-- name: GetFlowToolcallsPage :many
+SELECT
+  tc.id, tc.call_id, tc.status, tc.name, tc.args, tc.result, tc.flow_id, tc.task_id, tc.subtask_id, tc.created_at, tc.updated_at, tc.duration_seconds
+FROM toolcalls tc
+INNER JOIN flows f ON tc.flow_id = f.id
+WHERE tc.flow_id = $1 AND f.deleted_at IS NULL
+  AND ($2::bigint IS NULL OR tc.id > $2::bigint)
+  AND ($3::bigint IS NULL OR tc.id < $3::bigint)
+  AND ($4::timestamptz IS NULL OR tc.created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR tc.created_at < $5::timestamptz)
+  AND ($6::bigint IS NULL OR tc.task_id = $6::bigint)
+  AND ($7::bigint IS NULL OR tc.subtask_id = $7::bigint)
+  AND (cardinality($8::text[]) = 0 OR tc.name = ANY($8::text[]))
+  AND ($9::text = '' OR search_document(tc.name, tc.args::text, tc.result) @@ websearch_to_tsquery('english', $9::text))
+ORDER BY
+  CASE WHEN $10::bool THEN tc.id END DESC,
+  tc.id ASC
+LIMIT $11::int
+`
+
+type GetFlowToolcallsPageParams struct {
+	FlowID    int64         `json:"flow_id"`
+	AfterID   sql.NullInt64 `json:"after_id"`
+	BeforeID  sql.NullInt64 `json:"before_id"`
+	Since     sql.NullTime  `json:"since"`
+	Until     sql.NullTime  `json:"until"`
+	TaskID    sql.NullInt64 `json:"task_id"`
+	SubtaskID sql.NullInt64 `json:"subtask_id"`
+	Types     []string      `json:"types"`
+	Search    string        `json:"search"`
+	Reverse   bool          `json:"reverse"`
+	PageSize  sql.NullInt32 `json:"page_size"`
+}
+
+func (q *Queries) GetFlowToolcallsPage(ctx context.Context, arg GetFlowToolcallsPageParams) ([]Toolcall, error) {
+	rows, err := q.db.QueryContext(ctx, getFlowToolcallsPage,
+		arg.FlowID,
+		arg.AfterID,
+		arg.BeforeID,
+		arg.Since,
+		arg.Until,
+		arg.TaskID,
+		arg.SubtaskID,
+		pq.Array(arg.Types),
+		arg.Search,
+		arg.Reverse,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Toolcall
+	for rows.Next() {
+		var i Toolcall
+		if err := rows.Scan(
+			&i.ID,
+			&i.CallID,
+			&i.Status,
+			&i.Name,
+			&i.Args,
+			&i.Result,
+			&i.FlowID,
+			&i.TaskID,
+			&i.SubtaskID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DurationSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFlowToolcallsStats = `-- name: GetFlowToolcallsStats :one
 
 SELECT
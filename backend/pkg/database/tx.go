@@ -0,0 +1,33 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// TxRunner runs fn with queries bound to one transaction, committing it when
+// fn succeeds and rolling it back otherwise
+type TxRunner func(ctx context.Context, fn func(Querier) error) error
+
+// NewTxRunner returns a TxRunner starting its transactions on db
+func NewTxRunner(db *sql.DB) TxRunner {
+	queries := New(db)
+
+	return func(ctx context.Context, fn func(Querier) error) error {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if err := fn(queries.WithTx(tx)); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+
+		return nil
+	}
+}
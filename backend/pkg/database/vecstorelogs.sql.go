@@ -9,6 +9,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+
+	"github.com/lib/pq"
 )
 
 const createVectorStoreLog = `-- name: CreateVectorStoreLog :one
@@ -146,6 +148,87 @@ func (q *Queries) GetFlowVectorStoreLogs(ctx context.Context, flowID int64) ([]V
 	return items, nil
 }
 
+const getFlowVectorStoreLogsPage = `-- name: GetFlowVectorStoreLogsPage :many
+SELECT
+  vl.id, vl.initiator, vl.executor, vl.filter, vl.query, vl.action, vl.result, vl.flow_id, vl.task_id, vl.subtask_id, vl.created_at
+FROM vecstorelogs vl
+INNER JOIN flows f ON vl.flow_id = f.id
+WHERE vl.flow_id = $1 AND f.deleted_at IS NULL
+  AND ($2::bigint IS NULL OR vl.id > $2::bigint)
+  AND ($3::bigint IS NULL OR vl.id < $3::bigint)
+  AND ($4::timestamptz IS NULL OR vl.created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR vl.created_at < $5::timestamptz)
+  AND ($6::bigint IS NULL OR vl.task_id = $6::bigint)
+  AND ($7::bigint IS NULL OR vl.subtask_id = $7::bigint)
+  AND (cardinality($8::text[]) = 0 OR vl.executor::text = ANY($8::text[]))
+  AND ($9::text = '' OR search_document(vl.query, vl.result) @@ websearch_to_tsquery('english', $9::text))
+ORDER BY
+  CASE WHEN $10::bool THEN vl.id END DESC,
+  vl.id ASC
+LIMIT $11::int
+`
+
+type GetFlowVectorStoreLogsPageParams struct {
+	FlowID    int64         `json:"flow_id"`
+	AfterID   sql.NullInt64 `json:"after_id"`
+	BeforeID  sql.NullInt64 `json:"before_id"`
+	Since     sql.NullTime  `json:"since"`
+	Until     sql.NullTime  `json:"until"`
+	TaskID    sql.NullInt64 `json:"task_id"`
+	SubtaskID sql.NullInt64 `json:"subtask_id"`
+	Types     []string      `json:"types"`
+	Search    string        `json:"search"`
+	Reverse   bool          `json:"reverse"`
+	PageSize  sql.NullInt32 `json:"page_size"`
+}
+
+func (q *Queries) GetFlowVectorStoreLogsPage(ctx context.Context, arg GetFlowVectorStoreLogsPageParams) ([]Vecstorelog, error) {
+	rows, err := q.db.QueryContext(ctx, getFlowVectorStoreLogsPage,
+		arg.FlowID,
+		arg.AfterID,
+		arg.BeforeID,
+		arg.Since,
+		arg.Until,
+		arg.TaskID,
+		arg.SubtaskID,
+		pq.Array(arg.Types),
+		arg.Search,
+		arg.Reverse,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Vecstorelog
+	for rows.Next() {
+		var i Vecstorelog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Initiator,
+			&i.Executor,
+			&i.Filter,
+			&i.Query,
+			&i.Action,
+			&i.Result,
+			&i.FlowID,
+			&i.TaskID,
+			&i.SubtaskID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSubtaskVectorStoreLogs = `-- name: GetSubtaskVectorStoreLogs :many
 SELECT
   vl.id, vl.initiator, vl.executor, vl.filter, vl.query, vl.action, vl.result, vl.flow_id, vl.task_id, vl.subtask_id, vl.created_at
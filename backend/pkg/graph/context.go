@@ -2,14 +2,20 @@ package graph
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
 	"slices"
+	"strings"
 
+	"pentagi/pkg/config"
 	"pentagi/pkg/database"
 	"pentagi/pkg/flowfiles"
 	"pentagi/pkg/graph/model"
+	"pentagi/pkg/server/auth"
+	"pentagi/pkg/webhooks"
 )
 
 // This file will not be regenerated automatically.
@@ -26,6 +32,7 @@ const (
 	UserIDKey       GqlContextKey = "userID"
 	UserTypeKey     GqlContextKey = "userType"
 	UserPermissions GqlContextKey = "userPermissions"
+	TokenScopeKey   GqlContextKey = "tokenScope"
 )
 
 func GetUserID(ctx context.Context) (uint64, error) {
@@ -64,6 +71,17 @@ func SetUserPermissions(ctx context.Context, userPermissions []string) context.C
 	return context.WithValue(ctx, UserPermissions, userPermissions)
 }
 
+// GetTokenScope returns the scope of the API token of the request,
+// the zero scope doesn't restrict user sessions
+func GetTokenScope(ctx context.Context) auth.TokenScope {
+	scope, _ := ctx.Value(TokenScopeKey).(auth.TokenScope)
+	return scope
+}
+
+func SetTokenScope(ctx context.Context, scope auth.TokenScope) context.Context {
+	return context.WithValue(ctx, TokenScopeKey, scope)
+}
+
 func validateUserType(ctx context.Context, userTypes ...string) (bool, error) {
 	userType, err := GetUserType(ctx)
 	if err != nil {
@@ -111,19 +129,140 @@ func validatePermissionWithFlowID(
 		return 0, err
 	}
 
+	if !GetTokenScope(ctx).AllowsFlow(flowID) {
+		return 0, fmt.Errorf("flow %d is out of the token scope", flowID)
+	}
+
 	flow, err := db.GetFlow(ctx, flowID)
 	if err != nil {
 		return 0, err
 	}
 
 	if !admin && flow.UserID != int64(uid) {
-		return 0, fmt.Errorf("not permitted")
+		if err := validateProjectAccess(ctx, db, flow.ProjectID, uid, perm); err != nil {
+			return 0, err
+		}
 	}
 
 	return uid, nil
 }
 
-// validateUserResources checks that all given IDs exist and belong to uid (or uid is admin).
+// ProjectRoleAllows tells whether a member with the given role may use perm on
+// an item shared through the project: viewers watch, editors also steer and
+// change it, and only owners delete it.
+func ProjectRoleAllows(role database.ProjectRole, perm string) bool {
+	switch {
+	case strings.HasSuffix(perm, ".view"), strings.HasSuffix(perm, ".subscribe"):
+		return true
+	case strings.HasSuffix(perm, ".delete"):
+		return role == database.ProjectRoleOwner
+	default:
+		return role == database.ProjectRoleOwner || role == database.ProjectRoleEditor
+	}
+}
+
+// validateProjectAccess checks that uid is a member of the project an item of
+// another user is shared through and that the member role allows perm.
+func validateProjectAccess(
+	ctx context.Context,
+	db database.Querier,
+	projectID sql.NullInt64,
+	uid int64,
+	perm string,
+) error {
+	if !projectID.Valid {
+		return fmt.Errorf("not permitted")
+	}
+
+	member, err := db.GetProjectMember(ctx, database.GetProjectMemberParams{
+		ProjectID: projectID.Int64,
+		UserID:    uid,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("not permitted")
+	} else if err != nil {
+		return err
+	}
+
+	if !ProjectRoleAllows(member.Role, perm) {
+		return fmt.Errorf("not permitted")
+	}
+
+	return nil
+}
+
+// validateProjectMember checks that uid is a member of the project with one of
+// the roles (any role when none given), admins pass without being members.
+// Returns the project and the member role, nil for admins who are no members.
+func validateProjectMember(
+	ctx context.Context,
+	db database.Querier,
+	projectID int64,
+	uid int64,
+	admin bool,
+	roles ...database.ProjectRole,
+) (database.Project, *database.ProjectRole, error) {
+	project, err := db.GetProject(ctx, projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Project{}, nil, fmt.Errorf("project not found")
+	} else if err != nil {
+		return database.Project{}, nil, err
+	}
+
+	member, err := db.GetProjectMember(ctx, database.GetProjectMemberParams{
+		ProjectID: projectID,
+		UserID:    uid,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		if admin {
+			return project, nil, nil
+		}
+		return database.Project{}, nil, fmt.Errorf("not permitted")
+	} else if err != nil {
+		return database.Project{}, nil, err
+	}
+
+	if !admin && len(roles) != 0 && !slices.Contains(roles, member.Role) {
+		return database.Project{}, nil, fmt.Errorf("not permitted")
+	}
+
+	return project, &member.Role, nil
+}
+
+// validateItemProject checks that uid may move an item of ownerID from the
+// project it is shared through (current) to projectID: owners of items share
+// them with the projects they edit, owners of projects also stop sharing them.
+func validateItemProject(
+	ctx context.Context,
+	db database.Querier,
+	uid int64,
+	admin bool,
+	ownerID int64,
+	current sql.NullInt64,
+	projectID *int64,
+) error {
+	if projectID != nil {
+		if !admin && ownerID != uid {
+			return fmt.Errorf("not permitted")
+		}
+		_, _, err := validateProjectMember(ctx, db, *projectID, uid, admin,
+			database.ProjectRoleOwner, database.ProjectRoleEditor)
+		return err
+	}
+
+	if admin || ownerID == uid {
+		return nil
+	}
+	if !current.Valid {
+		return fmt.Errorf("not permitted")
+	}
+
+	_, _, err := validateProjectMember(ctx, db, current.Int64, uid, false, database.ProjectRoleOwner)
+	return err
+}
+
+// validateUserResources checks that all given IDs exist and belong to uid, are shared
+// with uid through a project (or uid is admin).
 // Returns the fetched UserResource records for use in copy operations.
 // An empty ids slice is valid and returns nil, nil.
 func validateUserResources(
@@ -154,7 +293,9 @@ func validateUserResources(
 			return nil, fmt.Errorf("resource %d not found", id)
 		}
 		if !isAdmin && r.UserID != uid {
-			return nil, fmt.Errorf("resource %d not accessible", id)
+			if err := validateProjectAccess(ctx, db, r.ProjectID, uid, "resources.view"); err != nil {
+				return nil, fmt.Errorf("resource %d not accessible", id)
+			}
 		}
 		result = append(result, r)
 	}
@@ -191,6 +332,71 @@ func validateKnowledgeFieldLengths(content string, question, description, codeLa
 	return nil
 }
 
+// maxRoleNameLen MUST stay in sync with the REST model (server/models/roles.go
+// `validate` tag).
+const maxRoleNameLen = 50
+
+// Built-in roles seeded by the migrations (server/models/users.go), admins keep
+// every privilege and neither of them can be renamed or deleted.
+const (
+	adminRoleID int64 = 1
+	userRoleID  int64 = 2
+)
+
+// validateRoleInput checks the role name and its privileges against the catalog,
+// the caller must hold every privilege so that nobody grants what they don't have.
+// It returns the privileges sorted and without duplicates.
+func validateRoleInput(ctx context.Context, input model.RoleInput) ([]string, error) {
+	if input.Name == "" {
+		return nil, fmt.Errorf("role name is required")
+	}
+	if len(input.Name) > maxRoleNameLen {
+		return nil, fmt.Errorf("role name must not exceed %d characters", maxRoleNameLen)
+	}
+
+	privs, err := auth.ValidatePrivileges(input.Privileges)
+	if err != nil {
+		return nil, err
+	}
+
+	userPrivs, err := GetUserPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthorized: invalid user permissions: %v", err)
+	}
+	for _, priv := range privs {
+		if !slices.Contains(userPrivs, priv) {
+			return nil, fmt.Errorf("privilege '%s' is not granted to the current user", priv)
+		}
+	}
+
+	return privs, nil
+}
+
+// invalidateRoleCaches drops the cached users of the role and all cached tokens,
+// which carry the role privileges, so that API tokens use the new privileges at
+// once; session cookies pick them up on the next refresh.
+func invalidateRoleCaches(
+	ctx context.Context,
+	db database.Querier,
+	userCache *auth.UserCache,
+	tokenCache *auth.TokenCache,
+	roleID int64,
+) {
+	if userCache != nil {
+		if uids, err := db.GetRoleUserIDs(ctx, roleID); err != nil {
+			userCache.InvalidateAll()
+		} else {
+			for _, uid := range uids {
+				userCache.Invalidate(uint64(uid))
+			}
+		}
+	}
+
+	if tokenCache != nil {
+		tokenCache.InvalidateAll()
+	}
+}
+
 func convertFlowFiles(files flowfiles.Files) []*model.FlowFile {
 	converted := make([]*model.FlowFile, 0, len(files.Files))
 	for _, file := range files.Files {
@@ -210,3 +416,353 @@ func convertFlowFile(file flowfiles.File) *model.FlowFile {
 		ModifiedAt: file.ModifiedAt,
 	}
 }
+
+// filterFlowsByTokenScope drops the flows which are out of the token scope
+func filterFlowsByTokenScope(ctx context.Context, flows []database.Flow) []database.Flow {
+	scope := GetTokenScope(ctx)
+	if !scope.HasFlows() {
+		return flows
+	}
+
+	return slices.DeleteFunc(flows, func(flow database.Flow) bool {
+		return !scope.AllowsFlow(flow.ID)
+	})
+}
+
+// validateTemplateScope checks that a token restricted to some templates creates
+// the flow from one of them: the flow input must be the text of the template
+func validateTemplateScope(ctx context.Context, db database.Querier, input string) error {
+	scope := GetTokenScope(ctx)
+	if !scope.HasTemplates() {
+		return nil
+	}
+
+	templates, err := db.GetFlowTemplatesByIDs(ctx, scope.TemplateIDs)
+	if err != nil {
+		return err
+	}
+
+	input = strings.TrimSpace(input)
+	for _, template := range templates {
+		if strings.TrimSpace(template.Text) == input {
+			return nil
+		}
+	}
+
+	return errors.New("flow input doesn't match any template of the token scope")
+}
+
+// validateAPITokenScope checks the privileges and the scope requested for a new
+// token: the privileges must be held by the user, the flows and the templates
+// must be visible to them
+func validateAPITokenScope(
+	ctx context.Context,
+	db database.Querier,
+	input model.CreateAPITokenInput,
+) ([]string, auth.TokenScope, error) {
+	privileges, err := auth.ValidatePrivileges(input.Privileges)
+	if err != nil {
+		return nil, auth.TokenScope{}, err
+	}
+	if len(privileges) == 0 {
+		privileges = nil
+	}
+
+	prms, err := GetUserPermissions(ctx)
+	if err != nil {
+		return nil, auth.TokenScope{}, fmt.Errorf("unauthorized: invalid user permissions: %v", err)
+	}
+	for _, priv := range privileges {
+		if !slices.Contains(prms, priv) {
+			return nil, auth.TokenScope{}, fmt.Errorf("privilege '%s' is not granted to the current user", priv)
+		}
+	}
+
+	scope, err := auth.NewTokenScope(input.FlowIds, input.TemplateIds, input.AllowedIps)
+	if err != nil {
+		return nil, auth.TokenScope{}, err
+	}
+	scope.FlowIDs = slices.Compact(slices.Sorted(slices.Values(scope.FlowIDs)))
+	scope.TemplateIDs = slices.Compact(slices.Sorted(slices.Values(scope.TemplateIDs)))
+
+	for _, flowID := range scope.FlowIDs {
+		if _, err := validatePermissionWithFlowID(ctx, "flows.view", flowID, db); err != nil {
+			return nil, auth.TokenScope{}, fmt.Errorf("flow %d: %w", flowID, err)
+		}
+	}
+
+	if scope.HasTemplates() {
+		uid, admin, err := validatePermission(ctx, "templates.view")
+		if err != nil {
+			return nil, auth.TokenScope{}, err
+		}
+
+		templates, err := db.GetFlowTemplatesByIDs(ctx, scope.TemplateIDs)
+		if err != nil {
+			return nil, auth.TokenScope{}, err
+		}
+		if len(templates) != len(scope.TemplateIDs) {
+			return nil, auth.TokenScope{}, fmt.Errorf("some templates of the token scope are not found")
+		}
+
+		for _, template := range templates {
+			if admin || template.UserID == uid {
+				continue
+			}
+			if err := validateProjectAccess(ctx, db, template.ProjectID, uid, "templates.view"); err != nil {
+				return nil, auth.TokenScope{}, fmt.Errorf("template %d: %w", template.ID, err)
+			}
+		}
+	}
+
+	return privileges, scope, nil
+}
+
+// validateWebhookInput checks the webhook URL, the event filters and that the
+// flows belong to the webhook owner, whose events are the only ones it gets.
+// A literal internal IP is refused early, the dispatcher checks every address
+// it connects to.
+func validateWebhookInput(
+	ctx context.Context,
+	db database.Querier,
+	cfg *config.Config,
+	ownerID int64,
+	input model.WebhookInput,
+) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return fmt.Errorf("webhook name must not be empty")
+	}
+
+	target, err := url.Parse(input.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("webhook url must be an absolute http or https URL")
+	}
+	if err := webhooks.CheckTargetURL(cfg, target); err != nil {
+		return err
+	}
+
+	for _, event := range input.Events {
+		if err := webhooks.ValidEventFilter(event); err != nil {
+			return err
+		}
+	}
+
+	scope := GetTokenScope(ctx)
+	if scope.HasFlows() && len(input.FlowIds) == 0 {
+		return fmt.Errorf("webhook must be limited to the flows of the token scope")
+	}
+
+	for _, flowID := range input.FlowIds {
+		if !scope.AllowsFlow(flowID) {
+			return fmt.Errorf("flow %d is out of the token scope", flowID)
+		}
+		_, err := db.GetUserFlow(ctx, database.GetUserFlowParams{ID: flowID, UserID: ownerID})
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("flow %d not found", flowID)
+		} else if err != nil {
+			return fmt.Errorf("failed to get flow %d: %w", flowID, err)
+		}
+	}
+
+	return nil
+}
+
+// getWebhook returns the webhook of the user, admins get any webhook
+func getWebhook(ctx context.Context, db database.Querier, webhookID, uid int64, admin bool) (database.Webhook, error) {
+	var (
+		hook database.Webhook
+		err  error
+	)
+	if admin {
+		hook, err = db.GetWebhook(ctx, webhookID)
+	} else {
+		hook, err = db.GetUserWebhook(ctx, database.GetUserWebhookParams{ID: webhookID, UserID: uid})
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Webhook{}, fmt.Errorf("webhook not found")
+	} else if err != nil {
+		return database.Webhook{}, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return hook, nil
+}
+
+// maxLogPageSize bounds first and last of a log filter, a bigger page would
+// bring back the browser freezes the pagination avoids
+const maxLogPageSize = 1000
+
+// logPage holds the log filter in the shape shared by the GetFlow*Page queries
+type logPage struct {
+	afterID   sql.NullInt64
+	beforeID  sql.NullInt64
+	since     sql.NullTime
+	until     sql.NullTime
+	taskID    sql.NullInt64
+	subtaskID sql.NullInt64
+	types     []string
+	search    string
+	reverse   bool
+	pageSize  sql.NullInt32
+}
+
+func newLogPage(filter model.LogFilter) (logPage, error) {
+	page := logPage{
+		afterID:   database.Int64ToNullInt64(filter.After),
+		beforeID:  database.Int64ToNullInt64(filter.Before),
+		since:     database.PtrTimeToNullTime(filter.Since),
+		until:     database.PtrTimeToNullTime(filter.Until),
+		taskID:    database.Int64ToNullInt64(filter.TaskID),
+		subtaskID: database.Int64ToNullInt64(filter.SubtaskID),
+		// an empty array disables the filter, a NULL one would match nothing
+		types: append([]string{}, filter.Types...),
+	}
+
+	if filter.Search != nil {
+		page.search = strings.TrimSpace(*filter.Search)
+	}
+
+	switch {
+	case filter.First != nil && filter.Last != nil:
+		return logPage{}, fmt.Errorf("first and last can't be used together")
+	case filter.First != nil:
+		if *filter.First < 1 || *filter.First > maxLogPageSize {
+			return logPage{}, fmt.Errorf("first must be between 1 and %d", maxLogPageSize)
+		}
+		page.pageSize = sql.NullInt32{Int32: int32(*filter.First), Valid: true}
+	case filter.Last != nil:
+		if *filter.Last < 1 || *filter.Last > maxLogPageSize {
+			return logPage{}, fmt.Errorf("last must be between 1 and %d", maxLogPageSize)
+		}
+		// the newest logs are selected and turned back into ascending order
+		page.pageSize = sql.NullInt32{Int32: int32(*filter.Last), Valid: true}
+		page.reverse = true
+	}
+
+	return page, nil
+}
+
+// ascending undoes the descending order of the logs selected with last
+func ascending[T any](page logPage, logs []T) []T {
+	if page.reverse {
+		slices.Reverse(logs)
+	}
+	return logs
+}
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchOffset    = 10000
+	maxSearchQueryLen  = 1000
+)
+
+// searchKindPermissions are the view privileges needed to search each kind, the
+// matching admin privilege extends the search to the flows of every user
+var searchKindPermissions = map[model.SearchResultKind]string{
+	model.SearchResultKindFlow:     "flows.view",
+	model.SearchResultKindTask:     "tasks.view",
+	model.SearchResultKindSubtask:  "subtasks.view",
+	model.SearchResultKindMessage:  "msglogs.view",
+	model.SearchResultKindTerminal: "termlogs.view",
+	model.SearchResultKindToolcall: "toolcalls.view",
+}
+
+// newSearchParams checks the search arguments and splits the requested kinds by
+// the scope the user may search them in; without kinds every permitted kind is
+// searched, a requested kind without the permission is an error
+func newSearchParams(
+	ctx context.Context,
+	query string,
+	kinds []model.SearchResultKind,
+	limit, offset *int,
+) (database.SearchFlowsParams, error) {
+	uid, err := GetUserID(ctx)
+	if err != nil {
+		return database.SearchFlowsParams{}, fmt.Errorf("unauthorized: invalid user: %v", err)
+	}
+
+	params := database.SearchFlowsParams{
+		UserID: int64(uid),
+		// a token scoped to some flows searches them only, no flows means no scope
+		FlowIds:  append([]int64{}, GetTokenScope(ctx).FlowIDs...),
+		Search:   strings.TrimSpace(query),
+		AllKinds: []string{},
+		OwnKinds: []string{},
+		PageSize: defaultSearchLimit,
+	}
+
+	if params.Search == "" {
+		return database.SearchFlowsParams{}, fmt.Errorf("search query can't be empty")
+	}
+	if len(params.Search) > maxSearchQueryLen {
+		return database.SearchFlowsParams{}, fmt.Errorf("search query can't be longer than %d bytes", maxSearchQueryLen)
+	}
+	if limit != nil {
+		if *limit < 1 || *limit > maxSearchLimit {
+			return database.SearchFlowsParams{}, fmt.Errorf("limit must be between 1 and %d", maxSearchLimit)
+		}
+		params.PageSize = int32(*limit)
+	}
+	if offset != nil {
+		if *offset < 0 || *offset > maxSearchOffset {
+			return database.SearchFlowsParams{}, fmt.Errorf("offset must be between 0 and %d", maxSearchOffset)
+		}
+		params.PageOffset = int32(*offset)
+	}
+
+	requested := len(kinds) != 0
+	if !requested {
+		kinds = model.AllSearchResultKind
+	}
+
+	for _, kind := range kinds {
+		perm, ok := searchKindPermissions[kind]
+		if !ok {
+			return database.SearchFlowsParams{}, fmt.Errorf("unknown search result kind '%s'", kind)
+		}
+
+		_, admin, err := validatePermission(ctx, perm)
+		switch {
+		case err != nil && requested:
+			return database.SearchFlowsParams{}, err
+		case err != nil:
+			continue
+		case admin:
+			params.AllKinds = append(params.AllKinds, kind.String())
+		default:
+			params.OwnKinds = append(params.OwnKinds, kind.String())
+		}
+	}
+
+	if len(params.AllKinds) == 0 && len(params.OwnKinds) == 0 {
+		return database.SearchFlowsParams{}, fmt.Errorf("requested permission to search not found")
+	}
+
+	return params, nil
+}
+
+// filterFlowEventsByTokenScope drops the events of the flows out of the token
+// scope, the channel is passed through as is for a token without flow scope
+func filterFlowEventsByTokenScope(ctx context.Context, events <-chan *model.Flow) <-chan *model.Flow {
+	scope := GetTokenScope(ctx)
+	if !scope.HasFlows() {
+		return events
+	}
+
+	filtered := make(chan *model.Flow)
+	go func() {
+		defer close(filtered)
+		for flow := range events {
+			if !scope.AllowsFlow(flow.ID) {
+				continue
+			}
+			select {
+			case filtered <- flow:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return filtered
+}
@@ -2,9 +2,19 @@ package graph
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"slices"
 	"strings"
 	"testing"
 
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/model"
+	"pentagi/pkg/server/auth"
+	"pentagi/pkg/webhooks"
+
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -388,6 +398,195 @@ func TestGetUserPermissions_NilSlice(t *testing.T) {
 	assert.Nil(t, perms)
 }
 
+// --- projects ---
+
+// projectQuerier knows a single flow and the members of its project
+type projectQuerier struct {
+	database.Querier
+	flow    database.Flow
+	members map[int64]database.ProjectRole
+}
+
+func (q projectQuerier) GetFlow(_ context.Context, id int64) (database.Flow, error) {
+	if id != q.flow.ID {
+		return database.Flow{}, sql.ErrNoRows
+	}
+	return q.flow, nil
+}
+
+func (q projectQuerier) GetProject(_ context.Context, id int64) (database.Project, error) {
+	if !q.flow.ProjectID.Valid || id != q.flow.ProjectID.Int64 {
+		return database.Project{}, sql.ErrNoRows
+	}
+	return database.Project{ID: id, UserID: q.flow.UserID}, nil
+}
+
+func (q projectQuerier) GetProjectMember(
+	_ context.Context, arg database.GetProjectMemberParams,
+) (database.ProjectMember, error) {
+	role, ok := q.members[arg.UserID]
+	if !ok || !q.flow.ProjectID.Valid || arg.ProjectID != q.flow.ProjectID.Int64 {
+		return database.ProjectMember{}, sql.ErrNoRows
+	}
+	return database.ProjectMember{ProjectID: arg.ProjectID, UserID: arg.UserID, Role: role}, nil
+}
+
+func TestProjectRoleAllows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		perm   string
+		viewer bool
+		editor bool
+		owner  bool
+	}{
+		{perm: "flows.view", viewer: true, editor: true, owner: true},
+		{perm: "tasks.subscribe", viewer: true, editor: true, owner: true},
+		{perm: "flows.edit", editor: true, owner: true},
+		{perm: "assistants.create", editor: true, owner: true},
+		{perm: "flows.delete", owner: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.perm, func(t *testing.T) {
+			assert.Equal(t, tt.viewer, ProjectRoleAllows(database.ProjectRoleViewer, tt.perm))
+			assert.Equal(t, tt.editor, ProjectRoleAllows(database.ProjectRoleEditor, tt.perm))
+			assert.Equal(t, tt.owner, ProjectRoleAllows(database.ProjectRoleOwner, tt.perm))
+		})
+	}
+}
+
+func TestValidatePermissionWithFlowID_Project(t *testing.T) {
+	t.Parallel()
+
+	db := projectQuerier{
+		flow: database.Flow{ID: 10, UserID: 1, ProjectID: sql.NullInt64{Int64: 7, Valid: true}},
+		members: map[int64]database.ProjectRole{
+			1: database.ProjectRoleOwner,
+			2: database.ProjectRoleEditor,
+			3: database.ProjectRoleViewer,
+		},
+	}
+	perms := []string{"flows.view", "flows.edit", "flows.delete", "tasks.subscribe"}
+	makeCtx := func(uid uint64) context.Context {
+		return SetUserPermissions(SetUserID(t.Context(), uid), perms)
+	}
+
+	uid, err := validatePermissionWithFlowID(makeCtx(3), "tasks.subscribe", 10, db)
+	require.NoError(t, err, "viewers watch shared flows")
+	assert.Equal(t, int64(3), uid)
+
+	_, err = validatePermissionWithFlowID(makeCtx(3), "flows.edit", 10, db)
+	require.EqualError(t, err, "not permitted", "viewers do not steer shared flows")
+
+	_, err = validatePermissionWithFlowID(makeCtx(2), "flows.edit", 10, db)
+	require.NoError(t, err, "editors steer shared flows")
+
+	_, err = validatePermissionWithFlowID(makeCtx(2), "flows.delete", 10, db)
+	require.EqualError(t, err, "not permitted", "only owners delete shared flows")
+
+	_, err = validatePermissionWithFlowID(makeCtx(4), "flows.view", 10, db)
+	require.EqualError(t, err, "not permitted", "other users do not see the flow")
+
+	db.flow.ProjectID = sql.NullInt64{}
+	_, err = validatePermissionWithFlowID(makeCtx(3), "flows.view", 10, db)
+	require.EqualError(t, err, "not permitted", "flows out of the project are not shared")
+}
+
+func TestValidatePermissionWithFlowID_TokenScope(t *testing.T) {
+	t.Parallel()
+
+	db := projectQuerier{flow: database.Flow{ID: 10, UserID: 1}}
+	ctx := SetUserPermissions(SetUserID(t.Context(), 1), []string{"flows.view"})
+
+	_, err := validatePermissionWithFlowID(ctx, "flows.view", 10, db)
+	require.NoError(t, err, "user sessions are not scoped")
+
+	_, err = validatePermissionWithFlowID(SetTokenScope(ctx, auth.TokenScope{FlowIDs: []int64{10}}), "flows.view", 10, db)
+	require.NoError(t, err, "the flow is in the token scope")
+
+	_, err = validatePermissionWithFlowID(SetTokenScope(ctx, auth.TokenScope{FlowIDs: []int64{11}}), "flows.view", 10, db)
+	require.ErrorContains(t, err, "out of the token scope")
+}
+
+func TestFilterFlowsByTokenScope(t *testing.T) {
+	t.Parallel()
+
+	flows := func() []database.Flow {
+		return []database.Flow{{ID: 1}, {ID: 2}, {ID: 3}}
+	}
+
+	assert.Len(t, filterFlowsByTokenScope(t.Context(), flows()), 3)
+
+	ctx := SetTokenScope(t.Context(), auth.TokenScope{FlowIDs: []int64{1, 3}})
+	assert.Equal(t, []database.Flow{{ID: 1}, {ID: 3}}, filterFlowsByTokenScope(ctx, flows()))
+}
+
+func TestValidateAPITokenScope(t *testing.T) {
+	t.Parallel()
+
+	db := projectQuerier{flow: database.Flow{ID: 10, UserID: 1}}
+	ctx := SetUserPermissions(SetUserID(t.Context(), 1), []string{"flows.view", "flows.create"})
+
+	privs, scope, err := validateAPITokenScope(ctx, db, model.CreateAPITokenInput{
+		Privileges: []string{"flows.view", "flows.view"},
+		FlowIds:    []int64{10, 10},
+		AllowedIps: []string{"10.0.0.0/8"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"flows.view"}, privs)
+	assert.Equal(t, []int64{10}, scope.FlowIDs)
+	assert.Equal(t, []string{"10.0.0.0/8"}, scope.IPs())
+
+	privs, scope, err = validateAPITokenScope(ctx, db, model.CreateAPITokenInput{})
+	require.NoError(t, err)
+	assert.Nil(t, privs, "the token inherits the role privileges")
+	assert.True(t, scope.IsZero())
+
+	tests := []struct {
+		name    string
+		input   model.CreateAPITokenInput
+		wantErr string
+	}{
+		{name: "unknown privilege", input: model.CreateAPITokenInput{Privileges: []string{"flows.destroy"}}, wantErr: "unknown privilege"},
+		{name: "privilege not held", input: model.CreateAPITokenInput{Privileges: []string{"flows.delete"}}, wantErr: "not granted"},
+		{name: "missing flow", input: model.CreateAPITokenInput{FlowIds: []int64{11}}, wantErr: "flow 11"},
+		{name: "invalid address", input: model.CreateAPITokenInput{AllowedIps: []string{"10.0.0.0/40"}}, wantErr: "invalid CIDR"},
+	}
+	for _, tt := range tests {
+		_, _, err := validateAPITokenScope(ctx, db, tt.input)
+		assert.ErrorContains(t, err, tt.wantErr, tt.name)
+	}
+}
+
+func TestValidateItemProject(t *testing.T) {
+	t.Parallel()
+
+	db := projectQuerier{
+		flow: database.Flow{ID: 10, UserID: 1, ProjectID: sql.NullInt64{Int64: 7, Valid: true}},
+		members: map[int64]database.ProjectRole{
+			1: database.ProjectRoleOwner,
+			2: database.ProjectRoleEditor,
+			3: database.ProjectRoleViewer,
+		},
+	}
+	project := int64(7)
+	current := sql.NullInt64{Int64: 7, Valid: true}
+
+	assert.NoError(t, validateItemProject(t.Context(), db, 2, false, 2, sql.NullInt64{}, &project),
+		"editors share their items")
+	assert.Error(t, validateItemProject(t.Context(), db, 3, false, 3, sql.NullInt64{}, &project),
+		"viewers do not share items")
+	assert.Error(t, validateItemProject(t.Context(), db, 2, false, 3, sql.NullInt64{}, &project),
+		"items are shared by their owners only")
+	assert.NoError(t, validateItemProject(t.Context(), db, 1, false, 2, current, nil),
+		"project owners stop sharing items of the members")
+	assert.Error(t, validateItemProject(t.Context(), db, 3, false, 2, current, nil),
+		"viewers do not stop sharing items of others")
+	assert.NoError(t, validateItemProject(t.Context(), db, 4, true, 2, sql.NullInt64{}, &project),
+		"admins share items without being members")
+}
+
 func TestValidateKnowledgeFieldLengths(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -424,7 +623,331 @@ func TestValidateKnowledgeFieldLengths(t *testing.T) {
 	}
 }
 
+func TestValidateRoleInput(t *testing.T) {
+	t.Parallel()
+
+	ctx := SetUserPermissions(t.Context(), []string{"roles.edit", "flows.view", "flows.create"})
+
+	tests := []struct {
+		name    string
+		input   model.RoleInput
+		want    []string
+		wantErr string
+	}{
+		{name: "sorted and deduplicated",
+			input: model.RoleInput{Name: "Operator", Privileges: []string{"flows.view", "flows.create", "flows.view"}},
+			want:  []string{"flows.create", "flows.view"},
+		},
+		{name: "no privileges", input: model.RoleInput{Name: "Nobody", Privileges: nil}, want: []string{}},
+		{name: "empty name", input: model.RoleInput{Privileges: []string{"flows.view"}}, wantErr: "required"},
+		{name: "name over max",
+			input:   model.RoleInput{Name: strings.Repeat("a", maxRoleNameLen+1)},
+			wantErr: "must not exceed",
+		},
+		{name: "unknown privilege",
+			input:   model.RoleInput{Name: "Broken", Privileges: []string{"flows.destroy"}},
+			wantErr: "unknown privilege",
+		},
+		{name: "privilege the caller doesn't hold",
+			input:   model.RoleInput{Name: "Escalated", Privileges: []string{"flows.view", "users.delete"}},
+			wantErr: "not granted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			privs, err := validateRoleInput(ctx, tt.input)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, privs)
+		})
+	}
+}
+
+// roleQuerier serves the role lookups done before a role is written
+type roleQuerier struct {
+	database.Querier
+}
+
+func (roleQuerier) GetRoleByName(context.Context, string) (database.GetRoleByNameRow, error) {
+	return database.GetRoleByNameRow{}, sql.ErrNoRows
+}
+
+func (roleQuerier) GetRole(_ context.Context, id int64) (database.GetRoleRow, error) {
+	return database.GetRoleRow{ID: id, Name: "Operator"}, nil
+}
+
+// roleTxQuerier records the role writes done inside a transaction and fails
+// to set the privileges
+type roleTxQuerier struct {
+	database.Querier
+	writes []string
+}
+
+func (q *roleTxQuerier) CreateRole(_ context.Context, name string) (database.Role, error) {
+	q.writes = append(q.writes, "create")
+	return database.Role{ID: 5, Name: name}, nil
+}
+
+func (q *roleTxQuerier) UpdateRoleName(_ context.Context, arg database.UpdateRoleNameParams) (database.Role, error) {
+	q.writes = append(q.writes, "rename")
+	return database.Role{ID: arg.ID, Name: arg.Name}, nil
+}
+
+func (q *roleTxQuerier) SetRolePrivileges(context.Context, database.SetRolePrivilegesParams) error {
+	q.writes = append(q.writes, "privileges")
+	return errors.New("connection reset")
+}
+
+func TestRoleMutationsRunInOneTransaction(t *testing.T) {
+	t.Parallel()
+
+	ctx := SetUserPermissions(SetUserID(t.Context(), 1), []string{"roles.edit", "flows.view"})
+	ctx = SetUserType(ctx, "local")
+	input := model.RoleInput{Name: "Operator", Privileges: []string{"flows.view"}}
+
+	newResolver := func(tx *roleTxQuerier, rolledBack *bool) *Resolver {
+		return &Resolver{
+			DB:     roleQuerier{},
+			Logger: logrus.NewEntry(logrus.New()),
+			InTx: func(ctx context.Context, fn func(database.Querier) error) error {
+				err := fn(tx)
+				*rolledBack = err != nil
+				return err
+			},
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+
+		tx, rolledBack := &roleTxQuerier{}, false
+		_, err := newResolver(tx, &rolledBack).Mutation().CreateRole(ctx, input)
+		require.ErrorContains(t, err, "failed to set role privileges")
+		assert.Equal(t, []string{"create", "privileges"}, tx.writes, "both writes must go through the transaction")
+		assert.True(t, rolledBack)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		t.Parallel()
+
+		tx, rolledBack := &roleTxQuerier{}, false
+		_, err := newResolver(tx, &rolledBack).Mutation().UpdateRole(ctx, 5, input)
+		require.ErrorContains(t, err, "failed to set role privileges")
+		assert.Equal(t, []string{"rename", "privileges"}, tx.writes, "both writes must go through the transaction")
+		assert.True(t, rolledBack)
+	})
+}
+
 func strOfLen(n int) *string {
 	s := strings.Repeat("a", n)
 	return &s
 }
+
+// --- Log filter ---
+
+func TestNewLogPage(t *testing.T) {
+	t.Parallel()
+
+	ptr := func(v int) *int { return &v }
+	id := int64(500)
+	search := "  nmap  "
+
+	page, err := newLogPage(model.LogFilter{})
+	require.NoError(t, err)
+	assert.NotNil(t, page.types, "an empty filter must not turn into NULL")
+	assert.False(t, page.pageSize.Valid, "without first and last every log is returned")
+
+	page, err = newLogPage(model.LogFilter{First: ptr(100), After: &id, Search: &search})
+	require.NoError(t, err)
+	assert.Equal(t, sql.NullInt32{Int32: 100, Valid: true}, page.pageSize)
+	assert.Equal(t, sql.NullInt64{Int64: 500, Valid: true}, page.afterID)
+	assert.False(t, page.reverse)
+	assert.Equal(t, "nmap", page.search)
+
+	page, err = newLogPage(model.LogFilter{Last: ptr(50), Before: &id})
+	require.NoError(t, err)
+	assert.True(t, page.reverse, "the last logs are selected in descending order")
+	assert.Equal(t, []int{1, 2, 3}, ascending(page, []int{3, 2, 1}))
+
+	_, err = newLogPage(model.LogFilter{First: ptr(1), Last: ptr(1)})
+	require.Error(t, err)
+	_, err = newLogPage(model.LogFilter{First: ptr(0)})
+	require.Error(t, err)
+	_, err = newLogPage(model.LogFilter{Last: ptr(maxLogPageSize + 1)})
+	require.Error(t, err)
+}
+
+func TestNewSearchParams(t *testing.T) {
+	t.Parallel()
+
+	makeCtx := func(perms ...string) context.Context {
+		return SetUserPermissions(SetUserID(t.Context(), 7), perms)
+	}
+	ptr := func(v int) *int { return &v }
+
+	params, err := newSearchParams(makeCtx("flows.admin", "termlogs.view"), "  jenkins  ", nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "jenkins", params.Search)
+	assert.Equal(t, int64(7), params.UserID)
+	assert.Equal(t, []string{"flow"}, params.AllKinds, "admins search every flow")
+	assert.Equal(t, []string{"terminal"}, params.OwnKinds, "kinds without permission are skipped")
+	assert.Equal(t, int32(defaultSearchLimit), params.PageSize)
+
+	params, err = newSearchParams(makeCtx("msglogs.view"), "jenkins",
+		[]model.SearchResultKind{model.SearchResultKindMessage}, ptr(50), ptr(100))
+	require.NoError(t, err)
+	assert.Empty(t, params.AllKinds)
+	assert.Equal(t, []string{"message"}, params.OwnKinds)
+	assert.Equal(t, int32(50), params.PageSize)
+	assert.Equal(t, int32(100), params.PageOffset)
+
+	_, err = newSearchParams(makeCtx("msglogs.view"), "jenkins",
+		[]model.SearchResultKind{model.SearchResultKindToolcall}, nil, nil)
+	require.Error(t, err, "a requested kind must be permitted")
+	_, err = newSearchParams(makeCtx("users.view"), "jenkins", nil, nil, nil)
+	require.Error(t, err)
+	_, err = newSearchParams(makeCtx("flows.view"), "   ", nil, nil, nil)
+	require.Error(t, err)
+	_, err = newSearchParams(makeCtx("flows.view"), "jenkins", nil, ptr(maxSearchLimit+1), nil)
+	require.Error(t, err)
+	_, err = newSearchParams(makeCtx("flows.view"), "jenkins", nil, nil, ptr(-1))
+	require.Error(t, err)
+}
+
+func TestFilterFlowEventsByTokenScope(t *testing.T) {
+	t.Parallel()
+
+	events := func() <-chan *model.Flow {
+		ch := make(chan *model.Flow, 3)
+		ch <- &model.Flow{ID: 1}
+		ch <- &model.Flow{ID: 2}
+		ch <- &model.Flow{ID: 3}
+		close(ch)
+		return ch
+	}
+	collect := func(ch <-chan *model.Flow) []int64 {
+		var ids []int64
+		for flow := range ch {
+			ids = append(ids, flow.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int64{1, 2, 3}, collect(filterFlowEventsByTokenScope(t.Context(), events())))
+
+	ctx := SetTokenScope(t.Context(), auth.TokenScope{FlowIDs: []int64{2}})
+	assert.Equal(t, []int64{2}, collect(filterFlowEventsByTokenScope(ctx, events())))
+}
+
+func TestSetFlowProjectTokenScope(t *testing.T) {
+	t.Parallel()
+
+	r := &Resolver{
+		DB:     projectQuerier{flow: database.Flow{ID: 10, UserID: 1}},
+		Logger: logrus.NewEntry(logrus.New()),
+	}
+	ctx := SetUserPermissions(SetUserID(t.Context(), 1), []string{"flows.edit"})
+	ctx = SetTokenScope(ctx, auth.TokenScope{FlowIDs: []int64{11}})
+
+	_, err := r.Mutation().SetFlowProject(ctx, 10, nil)
+	require.ErrorContains(t, err, "out of the token scope")
+}
+
+// searchQuerier answers SearchFlows with the flow hits the query would return
+type searchQuerier struct {
+	database.Querier
+	flows   []database.Flow
+	members map[int64][]int64
+	params  database.SearchFlowsParams
+}
+
+func (q *searchQuerier) SearchFlows(
+	_ context.Context, arg database.SearchFlowsParams,
+) ([]database.SearchFlowsRow, error) {
+	q.params = arg
+
+	var rows []database.SearchFlowsRow
+	for _, flow := range q.flows {
+		if len(arg.FlowIds) != 0 && !slices.Contains(arg.FlowIds, flow.ID) {
+			continue
+		}
+		own := flow.UserID == arg.UserID ||
+			flow.ProjectID.Valid && slices.Contains(q.members[flow.ProjectID.Int64], arg.UserID)
+		if !slices.Contains(arg.AllKinds, "flow") && (!own || !slices.Contains(arg.OwnKinds, "flow")) {
+			continue
+		}
+		rows = append(rows, database.SearchFlowsRow{
+			Kind:      "flow",
+			FlowID:    flow.ID,
+			FlowTitle: flow.Title,
+			ObjectID:  flow.ID,
+			Snippet:   flow.Title,
+		})
+	}
+	return rows, nil
+}
+
+func TestSearchScope(t *testing.T) {
+	t.Parallel()
+
+	db := &searchQuerier{
+		flows: []database.Flow{
+			{ID: 1, UserID: 1, Title: "jenkins"},
+			{ID: 2, UserID: 1, Title: "jenkins"},
+			{ID: 3, UserID: 2, Title: "jenkins", ProjectID: sql.NullInt64{Int64: 5, Valid: true}},
+			{ID: 4, UserID: 2, Title: "jenkins"},
+		},
+		members: map[int64][]int64{5: {1}},
+	}
+	r := &Resolver{DB: db, Logger: logrus.NewEntry(logrus.New())}
+	search := func(ctx context.Context) []int64 {
+		results, err := r.Query().Search(ctx, "jenkins", nil, nil, nil)
+		require.NoError(t, err)
+		var ids []int64
+		for _, result := range results {
+			ids = append(ids, result.FlowID)
+		}
+		return ids
+	}
+
+	user := SetUserPermissions(SetUserID(t.Context(), 1), []string{"flows.view"})
+	assert.Equal(t, []int64{1, 2, 3}, search(user), "own flows and the flows of the user projects")
+	assert.Empty(t, db.params.AllKinds)
+
+	scoped := SetTokenScope(user, auth.TokenScope{FlowIDs: []int64{2}})
+	assert.Equal(t, []int64{2}, search(scoped), "a scoped token searches its flows only")
+	assert.Equal(t, []int64{2}, db.params.FlowIds)
+
+	member := SetTokenScope(user, auth.TokenScope{FlowIDs: []int64{3, 4}})
+	assert.Equal(t, []int64{3}, search(member), "a project flow is searched by its members")
+
+	admin := SetUserPermissions(SetUserID(t.Context(), 1), []string{"flows.admin"})
+	assert.Equal(t, []int64{1, 2, 3, 4}, search(admin))
+	assert.Equal(t, []int64{4}, search(SetTokenScope(admin, auth.TokenScope{FlowIDs: []int64{4}})),
+		"the token scope limits admins too")
+}
+
+func TestValidateWebhookInputTarget(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	input := func(url string) model.WebhookInput {
+		return model.WebhookInput{Name: "ci", URL: url}
+	}
+
+	require.NoError(t, validateWebhookInput(ctx, nil, &config.Config{}, 1, input("https://hooks.example.com/ci")))
+
+	for _, url := range []string{"http://169.254.169.254/latest", "http://127.0.0.1:8080/", "http://localhost/", "http://[fd00::1]/"} {
+		err := validateWebhookInput(ctx, nil, &config.Config{}, 1, input(url))
+		assert.ErrorIs(t, err, webhooks.ErrForbiddenTarget, url)
+	}
+
+	onPrem := &config.Config{WebhookAllowedHosts: []string{"10.0.0.0/8"}}
+	require.NoError(t, validateWebhookInput(ctx, nil, onPrem, 1, input("http://10.0.0.5/hook")))
+}
@@ -10,28 +10,40 @@ import (
 )
 
 type APIToken struct {
-	ID        int64       `json:"id"`
-	TokenID   string      `json:"tokenId"`
-	UserID    int64       `json:"userId"`
-	RoleID    int64       `json:"roleId"`
-	Name      *string     `json:"name,omitempty"`
-	TTL       int         `json:"ttl"`
-	Status    TokenStatus `json:"status"`
-	CreatedAt time.Time   `json:"createdAt"`
-	UpdatedAt time.Time   `json:"updatedAt"`
+	ID          int64       `json:"id"`
+	TokenID     string      `json:"tokenId"`
+	UserID      int64       `json:"userId"`
+	RoleID      int64       `json:"roleId"`
+	Name        *string     `json:"name,omitempty"`
+	TTL         int         `json:"ttl"`
+	Status      TokenStatus `json:"status"`
+	Privileges  []string    `json:"privileges"`
+	FlowIds     []int64     `json:"flowIds"`
+	TemplateIds []int64     `json:"templateIds"`
+	AllowedIps  []string    `json:"allowedIps"`
+	LastUsedAt  *time.Time  `json:"lastUsedAt,omitempty"`
+	LastUsedIP  *string     `json:"lastUsedIp,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
 }
 
 type APITokenWithSecret struct {
-	ID        int64       `json:"id"`
-	TokenID   string      `json:"tokenId"`
-	UserID    int64       `json:"userId"`
-	RoleID    int64       `json:"roleId"`
-	Name      *string     `json:"name,omitempty"`
-	TTL       int         `json:"ttl"`
-	Status    TokenStatus `json:"status"`
-	CreatedAt time.Time   `json:"createdAt"`
-	UpdatedAt time.Time   `json:"updatedAt"`
-	Token     string      `json:"token"`
+	ID          int64       `json:"id"`
+	TokenID     string      `json:"tokenId"`
+	UserID      int64       `json:"userId"`
+	RoleID      int64       `json:"roleId"`
+	Name        *string     `json:"name,omitempty"`
+	TTL         int         `json:"ttl"`
+	Status      TokenStatus `json:"status"`
+	Privileges  []string    `json:"privileges"`
+	FlowIds     []int64     `json:"flowIds"`
+	TemplateIds []int64     `json:"templateIds"`
+	AllowedIps  []string    `json:"allowedIps"`
+	LastUsedAt  *time.Time  `json:"lastUsedAt,omitempty"`
+	LastUsedIP  *string     `json:"lastUsedIp,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	Token       string      `json:"token"`
 }
 
 type AgentConfig struct {
@@ -52,6 +64,8 @@ type AgentConfig struct {
 	Reasoning         *ReasoningConfig       `json:"reasoning,omitempty"`
 	Price             *ModelPrice            `json:"price,omitempty"`
 	ExtraBody         map[string]interface{} `json:"extraBody,omitempty"`
+	ToolCallFixer     *bool                  `json:"toolCallFixer,omitempty"`
+	Cache             *PromptCacheConfig     `json:"cache,omitempty"`
 }
 
 type AgentLog struct {
@@ -118,6 +132,27 @@ type AgentsPrompts struct {
 	Summarizer    *AgentPrompt  `json:"summarizer"`
 }
 
+type AnonymizationConfig struct {
+	Enabled     bool                           `json:"enabled"`
+	Default     []*DefaultAnonymizationPattern `json:"default"`
+	UserDefined []*AnonymizationPattern        `json:"userDefined"`
+}
+
+type AnonymizationPattern struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Regex     string    `json:"regex"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type AnonymizationPatternInput struct {
+	Name    string `json:"name"`
+	Regex   string `json:"regex"`
+	Enabled bool   `json:"enabled"`
+}
+
 type Assistant struct {
 	ID        int64      `json:"id"`
 	Title     string     `json:"title"`
@@ -142,9 +177,52 @@ type AssistantLog struct {
 	CreatedAt    time.Time      `json:"createdAt"`
 }
 
+type AttackGraph struct {
+	FlowID int64              `json:"flowId"`
+	Nodes  []*AttackGraphNode `json:"nodes"`
+	Edges  []*AttackGraphEdge `json:"edges"`
+	Dot    string             `json:"dot"`
+}
+
+type AttackGraphEdge struct {
+	ID          string              `json:"id"`
+	Kind        AttackGraphEdgeKind `json:"kind"`
+	Source      string              `json:"source"`
+	Target      string              `json:"target"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	ToolcallID  *int64              `json:"toolcallId,omitempty"`
+	TaskID      *int64              `json:"taskId,omitempty"`
+	SubtaskID   *int64              `json:"subtaskId,omitempty"`
+	Timestamp   time.Time           `json:"timestamp"`
+}
+
+type AttackGraphNode struct {
+	ID          string              `json:"id"`
+	Kind        AttackGraphNodeKind `json:"kind"`
+	Label       string              `json:"label"`
+	Name        string              `json:"name"`
+	Summary     string              `json:"summary"`
+	FirstSeenAt time.Time           `json:"firstSeenAt"`
+}
+
+type BackendInstance struct {
+	Name        string    `json:"name"`
+	StartedAt   time.Time `json:"startedAt"`
+	HeartbeatAt time.Time `json:"heartbeatAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Alive       bool      `json:"alive"`
+	Leader      bool      `json:"leader"`
+	FlowsCount  int       `json:"flowsCount"`
+}
+
 type CreateAPITokenInput struct {
-	Name *string `json:"name,omitempty"`
-	TTL  int     `json:"ttl"`
+	Name        *string  `json:"name,omitempty"`
+	TTL         int      `json:"ttl"`
+	Privileges  []string `json:"privileges,omitempty"`
+	FlowIds     []int64  `json:"flowIds,omitempty"`
+	TemplateIds []int64  `json:"templateIds,omitempty"`
+	AllowedIps  []string `json:"allowedIps,omitempty"`
 }
 
 type CreateFlowTemplateInput struct {
@@ -160,6 +238,7 @@ type CreateKnowledgeDocumentInput struct {
 	GuideType   *KnowledgeGuideType  `json:"guideType,omitempty"`
 	AnswerType  *KnowledgeAnswerType `json:"answerType,omitempty"`
 	CodeLang    *string              `json:"codeLang,omitempty"`
+	ExpiresAt   *time.Time           `json:"expiresAt,omitempty"`
 }
 
 type DailyFlowsStats struct {
@@ -177,6 +256,11 @@ type DailyUsageStats struct {
 	Stats *UsageStats `json:"stats"`
 }
 
+type DefaultAnonymizationPattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+}
+
 type DefaultPrompt struct {
 	Type      PromptType `json:"type"`
 	Template  string     `json:"template"`
@@ -202,12 +286,40 @@ type DefaultProvidersConfig struct {
 	Minimax   *ProviderConfig `json:"minimax,omitempty"`
 }
 
+type EmbeddingMigration struct {
+	ID               int64                    `json:"id"`
+	Collection       string                   `json:"collection"`
+	Status           EmbeddingMigrationStatus `json:"status"`
+	SourceModel      string                   `json:"sourceModel"`
+	SourceDimensions int                      `json:"sourceDimensions"`
+	TargetModel      string                   `json:"targetModel"`
+	TargetDimensions int                      `json:"targetDimensions"`
+	TotalDocs        int                      `json:"totalDocs"`
+	ProcessedDocs    int                      `json:"processedDocs"`
+	FailedDocs       int                      `json:"failedDocs"`
+	Error            *string                  `json:"error,omitempty"`
+	CreatedAt        time.Time                `json:"createdAt"`
+	UpdatedAt        time.Time                `json:"updatedAt"`
+	FinishedAt       *time.Time               `json:"finishedAt,omitempty"`
+}
+
+type EmbeddingStatus struct {
+	Collection      string              `json:"collection"`
+	StoredModel     string              `json:"storedModel"`
+	Dimensions      int                 `json:"dimensions"`
+	ConfiguredModel string              `json:"configuredModel"`
+	QueryModel      string              `json:"queryModel"`
+	Mismatch        bool                `json:"mismatch"`
+	Migration       *EmbeddingMigration `json:"migration,omitempty"`
+}
+
 type Flow struct {
 	ID        int64       `json:"id"`
 	Title     string      `json:"title"`
 	Status    StatusType  `json:"status"`
 	Terminals []*Terminal `json:"terminals,omitempty"`
 	Provider  *Provider   `json:"provider"`
+	ProjectID *int64      `json:"projectId,omitempty"`
 	CreatedAt time.Time   `json:"createdAt"`
 	UpdatedAt time.Time   `json:"updatedAt"`
 }
@@ -235,6 +347,15 @@ type FlowFile struct {
 	ModifiedAt time.Time `json:"modifiedAt"`
 }
 
+type FlowLease struct {
+	FlowID      int64     `json:"flowId"`
+	Instance    string    `json:"instance"`
+	AcquiredAt  time.Time `json:"acquiredAt"`
+	HeartbeatAt time.Time `json:"heartbeatAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Expired     bool      `json:"expired"`
+}
+
 type FlowStats struct {
 	TotalTasksCount      int `json:"totalTasksCount"`
 	TotalSubtasksCount   int `json:"totalSubtasksCount"`
@@ -244,6 +365,7 @@ type FlowStats struct {
 type FlowTemplate struct {
 	ID        int64     `json:"id"`
 	UserID    int64     `json:"userId"`
+	ProjectID *int64    `json:"projectId,omitempty"`
 	Title     string    `json:"title"`
 	Text      string    `json:"text"`
 	CreatedAt time.Time `json:"createdAt"`
@@ -272,6 +394,7 @@ type KnowledgeDocument struct {
 	Question    string               `json:"question"`
 	Description *string              `json:"description,omitempty"`
 	UserID      int64                `json:"userId"`
+	ProjectID   *int64               `json:"projectId,omitempty"`
 	FlowID      *int64               `json:"flowId,omitempty"`
 	TaskID      *int64               `json:"taskId,omitempty"`
 	SubtaskID   *int64               `json:"subtaskId,omitempty"`
@@ -281,6 +404,10 @@ type KnowledgeDocument struct {
 	PartSize    int                  `json:"partSize"`
 	TotalSize   int                  `json:"totalSize"`
 	Manual      bool                 `json:"manual"`
+	Provenance  *KnowledgeProvenance `json:"provenance,omitempty"`
+	Archived    bool                 `json:"archived"`
+	ExpiresAt   *time.Time           `json:"expiresAt,omitempty"`
+	Feedback    *KnowledgeFeedback   `json:"feedback,omitempty"`
 }
 
 type KnowledgeDocumentWithScore struct {
@@ -288,6 +415,15 @@ type KnowledgeDocumentWithScore struct {
 	Document *KnowledgeDocument `json:"document"`
 }
 
+type KnowledgeFeedback struct {
+	Retrievals      int        `json:"retrievals"`
+	LastRetrievedAt *time.Time `json:"lastRetrievedAt,omitempty"`
+	Useful          int        `json:"useful"`
+	NotUseful       int        `json:"notUseful"`
+	Upvotes         int        `json:"upvotes"`
+	Downvotes       int        `json:"downvotes"`
+}
+
 type KnowledgeFilter struct {
 	DocTypes    []KnowledgeDocType    `json:"docTypes,omitempty"`
 	GuideTypes  []KnowledgeGuideType  `json:"guideTypes,omitempty"`
@@ -295,6 +431,47 @@ type KnowledgeFilter struct {
 	CodeLangs   []string              `json:"codeLangs,omitempty"`
 	FlowID      *int64                `json:"flowId,omitempty"`
 	Manual      *bool                 `json:"manual,omitempty"`
+	Archived    *bool                 `json:"archived,omitempty"`
+}
+
+type KnowledgeImportJob struct {
+	ID              int64                 `json:"id"`
+	UserID          int64                 `json:"userId"`
+	Status          KnowledgeImportStatus `json:"status"`
+	Source          string                `json:"source"`
+	TotalFiles      int                   `json:"totalFiles"`
+	SkippedFiles    int                   `json:"skippedFiles"`
+	TotalChunks     int                   `json:"totalChunks"`
+	ProcessedChunks int                   `json:"processedChunks"`
+	CreatedDocs     int                   `json:"createdDocs"`
+	UpdatedDocs     int                   `json:"updatedDocs"`
+	DuplicateDocs   int                   `json:"duplicateDocs"`
+	FailedDocs      int                   `json:"failedDocs"`
+	Error           *string               `json:"error,omitempty"`
+	CreatedAt       time.Time             `json:"createdAt"`
+	UpdatedAt       time.Time             `json:"updatedAt"`
+}
+
+type KnowledgeProvenance struct {
+	InstanceID     string    `json:"instanceId"`
+	TenantID       *string   `json:"tenantId,omitempty"`
+	DocumentID     string    `json:"documentId"`
+	EmbeddingModel *string   `json:"embeddingModel,omitempty"`
+	ExportedAt     time.Time `json:"exportedAt"`
+	ImportedAt     time.Time `json:"importedAt"`
+}
+
+type LogFilter struct {
+	After     *int64     `json:"after,omitempty"`
+	Before    *int64     `json:"before,omitempty"`
+	First     *int       `json:"first,omitempty"`
+	Last      *int       `json:"last,omitempty"`
+	Since     *time.Time `json:"since,omitempty"`
+	Until     *time.Time `json:"until,omitempty"`
+	TaskID    *int64     `json:"taskId,omitempty"`
+	SubtaskID *int64     `json:"subtaskId,omitempty"`
+	Types     []string   `json:"types,omitempty"`
+	Search    *string    `json:"search,omitempty"`
 }
 
 type MessageLog struct {
@@ -317,13 +494,30 @@ type ModelAgentsUsageStats struct {
 	Stats      *UsageStats `json:"stats"`
 }
 
+type ModelCapabilities struct {
+	ProviderID  int64             `json:"providerId"`
+	Type        ProviderType      `json:"type"`
+	Model       string            `json:"model"`
+	AgentTypes  []AgentConfigType `json:"agentTypes"`
+	ToolCalling *float64          `json:"toolCalling,omitempty"`
+	JSONMode    *bool             `json:"jsonMode,omitempty"`
+	Reasoning   bool              `json:"reasoning"`
+	Streaming   *bool             `json:"streaming,omitempty"`
+	MaxContext  int               `json:"maxContext"`
+	TestsTotal  int               `json:"testsTotal"`
+	TestsPassed int               `json:"testsPassed"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
 type ModelConfig struct {
-	Name        string              `json:"name"`
-	Description *string             `json:"description,omitempty"`
-	ReleaseDate *time.Time          `json:"releaseDate,omitempty"`
-	Thinking    *bool               `json:"thinking,omitempty"`
-	Reasoning   *ModelReasoningInfo `json:"reasoning,omitempty"`
-	Price       *ModelPrice         `json:"price,omitempty"`
+	Name          string              `json:"name"`
+	Description   *string             `json:"description,omitempty"`
+	ReleaseDate   *time.Time          `json:"releaseDate,omitempty"`
+	Thinking      *bool               `json:"thinking,omitempty"`
+	Reasoning     *ModelReasoningInfo `json:"reasoning,omitempty"`
+	Price         *ModelPrice         `json:"price,omitempty"`
+	ContextWindow *int                `json:"contextWindow,omitempty"`
+	Vision        *bool               `json:"vision,omitempty"`
 }
 
 type ModelPrice struct {
@@ -350,6 +544,36 @@ type ModelUsageStats struct {
 type Mutation struct {
 }
 
+type Project struct {
+	ID          int64        `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	UserID      int64        `json:"userId"`
+	Role        *ProjectRole `json:"role,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+type ProjectInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type ProjectMember struct {
+	UserID    int64       `json:"userId"`
+	Name      string      `json:"name"`
+	Mail      string      `json:"mail"`
+	Role      ProjectRole `json:"role"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+type PromptCacheConfig struct {
+	System  bool    `json:"system"`
+	Tools   bool    `json:"tools"`
+	History bool    `json:"history"`
+	TTL     *string `json:"ttl,omitempty"`
+}
+
 type PromptValidationResult struct {
 	Result    ResultType                 `json:"result"`
 	ErrorType *PromptValidationErrorType `json:"errorType,omitempty"`
@@ -372,6 +596,7 @@ type ProviderConfig struct {
 	ID        int64         `json:"id"`
 	Name      string        `json:"name"`
 	Type      ProviderType  `json:"type"`
+	ProjectID *int64        `json:"projectId,omitempty"`
 	Agents    *AgentsConfig `json:"agents"`
 	CreatedAt time.Time     `json:"createdAt"`
 	UpdatedAt time.Time     `json:"updatedAt"`
@@ -442,6 +667,17 @@ type ReasoningConfig struct {
 	MaxTokens *int             `json:"maxTokens,omitempty"`
 }
 
+type Role struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Privileges []string `json:"privileges"`
+}
+
+type RoleInput struct {
+	Name       string   `json:"name"`
+	Privileges []string `json:"privileges"`
+}
+
 type Screenshot struct {
 	ID        int64     `json:"id"`
 	FlowID    int64     `json:"flowId"`
@@ -465,6 +701,19 @@ type SearchLog struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+type SearchResult struct {
+	Kind      SearchResultKind `json:"kind"`
+	FlowID    int64            `json:"flowId"`
+	FlowTitle string           `json:"flowTitle"`
+	TaskID    *int64           `json:"taskId,omitempty"`
+	SubtaskID *int64           `json:"subtaskId,omitempty"`
+	ObjectID  int64            `json:"objectId"`
+	Rank      float64          `json:"rank"`
+	Snippet   string           `json:"snippet"`
+	Link      string           `json:"link"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
 type Settings struct {
 	Debug              bool   `json:"debug"`
 	AskUser            bool   `json:"askUser"`
@@ -607,6 +856,7 @@ type UsageStats struct {
 	TotalUsageCacheOut int     `json:"totalUsageCacheOut"`
 	TotalUsageCostIn   float64 `json:"totalUsageCostIn"`
 	TotalUsageCostOut  float64 `json:"totalUsageCostOut"`
+	CacheHitRatio      float64 `json:"cacheHitRatio"`
 }
 
 type UserPreferences struct {
@@ -625,6 +875,7 @@ type UserPrompt struct {
 type UserResource struct {
 	ID        int64     `json:"id"`
 	UserID    int64     `json:"userId"`
+	ProjectID *int64    `json:"projectId,omitempty"`
 	Name      string    `json:"name"`
 	Path      string    `json:"path"`
 	Size      int       `json:"size"`
@@ -647,6 +898,44 @@ type VectorStoreLog struct {
 	CreatedAt time.Time         `json:"createdAt"`
 }
 
+type Webhook struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"userId"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	HasSecret bool      `json:"hasSecret"`
+	Events    []string  `json:"events"`
+	FlowIds   []int64   `json:"flowIds"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	WebhookID      int64                 `json:"webhookId"`
+	Event          string                `json:"event"`
+	FlowID         *int64                `json:"flowId,omitempty"`
+	Payload        string                `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+	ResponseStatus *int                  `json:"responseStatus,omitempty"`
+	ResponseBody   string                `json:"responseBody"`
+	Error          string                `json:"error"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+}
+
+type WebhookInput struct {
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Secret  *string  `json:"secret,omitempty"`
+	Events  []string `json:"events"`
+	FlowIds []int64  `json:"flowIds"`
+	Enabled bool     `json:"enabled"`
+}
+
 type AgentConfigType string
 
 const (
@@ -777,6 +1066,137 @@ func (e AgentType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type AttackGraphEdgeKind string
+
+const (
+	AttackGraphEdgeKindAction   AttackGraphEdgeKind = "action"
+	AttackGraphEdgeKindRelation AttackGraphEdgeKind = "relation"
+)
+
+var AllAttackGraphEdgeKind = []AttackGraphEdgeKind{
+	AttackGraphEdgeKindAction,
+	AttackGraphEdgeKindRelation,
+}
+
+func (e AttackGraphEdgeKind) IsValid() bool {
+	switch e {
+	case AttackGraphEdgeKindAction, AttackGraphEdgeKindRelation:
+		return true
+	}
+	return false
+}
+
+func (e AttackGraphEdgeKind) String() string {
+	return string(e)
+}
+
+func (e *AttackGraphEdgeKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AttackGraphEdgeKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AttackGraphEdgeKind", str)
+	}
+	return nil
+}
+
+func (e AttackGraphEdgeKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AttackGraphNodeKind string
+
+const (
+	AttackGraphNodeKindStart         AttackGraphNodeKind = "start"
+	AttackGraphNodeKindAsset         AttackGraphNodeKind = "asset"
+	AttackGraphNodeKindVulnerability AttackGraphNodeKind = "vulnerability"
+	AttackGraphNodeKindCredential    AttackGraphNodeKind = "credential"
+	AttackGraphNodeKindFoothold      AttackGraphNodeKind = "foothold"
+)
+
+var AllAttackGraphNodeKind = []AttackGraphNodeKind{
+	AttackGraphNodeKindStart,
+	AttackGraphNodeKindAsset,
+	AttackGraphNodeKindVulnerability,
+	AttackGraphNodeKindCredential,
+	AttackGraphNodeKindFoothold,
+}
+
+func (e AttackGraphNodeKind) IsValid() bool {
+	switch e {
+	case AttackGraphNodeKindStart, AttackGraphNodeKindAsset, AttackGraphNodeKindVulnerability, AttackGraphNodeKindCredential, AttackGraphNodeKindFoothold:
+		return true
+	}
+	return false
+}
+
+func (e AttackGraphNodeKind) String() string {
+	return string(e)
+}
+
+func (e *AttackGraphNodeKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AttackGraphNodeKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AttackGraphNodeKind", str)
+	}
+	return nil
+}
+
+func (e AttackGraphNodeKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type EmbeddingMigrationStatus string
+
+const (
+	EmbeddingMigrationStatusRunning   EmbeddingMigrationStatus = "running"
+	EmbeddingMigrationStatusCompleted EmbeddingMigrationStatus = "completed"
+	EmbeddingMigrationStatusFailed    EmbeddingMigrationStatus = "failed"
+)
+
+var AllEmbeddingMigrationStatus = []EmbeddingMigrationStatus{
+	EmbeddingMigrationStatusRunning,
+	EmbeddingMigrationStatusCompleted,
+	EmbeddingMigrationStatusFailed,
+}
+
+func (e EmbeddingMigrationStatus) IsValid() bool {
+	switch e {
+	case EmbeddingMigrationStatusRunning, EmbeddingMigrationStatusCompleted, EmbeddingMigrationStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e EmbeddingMigrationStatus) String() string {
+	return string(e)
+}
+
+func (e *EmbeddingMigrationStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = EmbeddingMigrationStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid EmbeddingMigrationStatus", str)
+	}
+	return nil
+}
+
+func (e EmbeddingMigrationStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type KnowledgeAnswerType string
 
 const (
@@ -916,6 +1336,92 @@ func (e KnowledgeGuideType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type KnowledgeImportStatus string
+
+const (
+	KnowledgeImportStatusPending  KnowledgeImportStatus = "pending"
+	KnowledgeImportStatusRunning  KnowledgeImportStatus = "running"
+	KnowledgeImportStatusFinished KnowledgeImportStatus = "finished"
+	KnowledgeImportStatusFailed   KnowledgeImportStatus = "failed"
+)
+
+var AllKnowledgeImportStatus = []KnowledgeImportStatus{
+	KnowledgeImportStatusPending,
+	KnowledgeImportStatusRunning,
+	KnowledgeImportStatusFinished,
+	KnowledgeImportStatusFailed,
+}
+
+func (e KnowledgeImportStatus) IsValid() bool {
+	switch e {
+	case KnowledgeImportStatusPending, KnowledgeImportStatusRunning, KnowledgeImportStatusFinished, KnowledgeImportStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e KnowledgeImportStatus) String() string {
+	return string(e)
+}
+
+func (e *KnowledgeImportStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = KnowledgeImportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid KnowledgeImportStatus", str)
+	}
+	return nil
+}
+
+func (e KnowledgeImportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type KnowledgeVote string
+
+const (
+	KnowledgeVoteUp   KnowledgeVote = "up"
+	KnowledgeVoteDown KnowledgeVote = "down"
+)
+
+var AllKnowledgeVote = []KnowledgeVote{
+	KnowledgeVoteUp,
+	KnowledgeVoteDown,
+}
+
+func (e KnowledgeVote) IsValid() bool {
+	switch e {
+	case KnowledgeVoteUp, KnowledgeVoteDown:
+		return true
+	}
+	return false
+}
+
+func (e KnowledgeVote) String() string {
+	return string(e)
+}
+
+func (e *KnowledgeVote) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = KnowledgeVote(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid KnowledgeVote", str)
+	}
+	return nil
+}
+
+func (e KnowledgeVote) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type MessageLogType string
 
 const (
@@ -1018,6 +1524,49 @@ func (e ModelReasoningMode) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type ProjectRole string
+
+const (
+	ProjectRoleOwner  ProjectRole = "owner"
+	ProjectRoleEditor ProjectRole = "editor"
+	ProjectRoleViewer ProjectRole = "viewer"
+)
+
+var AllProjectRole = []ProjectRole{
+	ProjectRoleOwner,
+	ProjectRoleEditor,
+	ProjectRoleViewer,
+}
+
+func (e ProjectRole) IsValid() bool {
+	switch e {
+	case ProjectRoleOwner, ProjectRoleEditor, ProjectRoleViewer:
+		return true
+	}
+	return false
+}
+
+func (e ProjectRole) String() string {
+	return string(e)
+}
+
+func (e *ProjectRole) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProjectRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProjectRole", str)
+	}
+	return nil
+}
+
+func (e ProjectRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type PromptType string
 
 const (
@@ -1415,6 +1964,55 @@ func (e ResultType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type SearchResultKind string
+
+const (
+	SearchResultKindFlow     SearchResultKind = "flow"
+	SearchResultKindTask     SearchResultKind = "task"
+	SearchResultKindSubtask  SearchResultKind = "subtask"
+	SearchResultKindMessage  SearchResultKind = "message"
+	SearchResultKindTerminal SearchResultKind = "terminal"
+	SearchResultKindToolcall SearchResultKind = "toolcall"
+)
+
+var AllSearchResultKind = []SearchResultKind{
+	SearchResultKindFlow,
+	SearchResultKindTask,
+	SearchResultKindSubtask,
+	SearchResultKindMessage,
+	SearchResultKindTerminal,
+	SearchResultKindToolcall,
+}
+
+func (e SearchResultKind) IsValid() bool {
+	switch e {
+	case SearchResultKindFlow, SearchResultKindTask, SearchResultKindSubtask, SearchResultKindMessage, SearchResultKindTerminal, SearchResultKindToolcall:
+		return true
+	}
+	return false
+}
+
+func (e SearchResultKind) String() string {
+	return string(e)
+}
+
+func (e *SearchResultKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SearchResultKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SearchResultKind", str)
+	}
+	return nil
+}
+
+func (e SearchResultKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type StatusType string
 
 const (
@@ -1717,3 +2315,46 @@ func (e *VectorStoreAction) UnmarshalGQL(v interface{}) error {
 func (e VectorStoreAction) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSuccess WebhookDeliveryStatus = "success"
+	WebhookDeliveryStatusFailed  WebhookDeliveryStatus = "failed"
+)
+
+var AllWebhookDeliveryStatus = []WebhookDeliveryStatus{
+	WebhookDeliveryStatusPending,
+	WebhookDeliveryStatusSuccess,
+	WebhookDeliveryStatusFailed,
+}
+
+func (e WebhookDeliveryStatus) IsValid() bool {
+	switch e {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusSuccess, WebhookDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e WebhookDeliveryStatus) String() string {
+	return string(e)
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookDeliveryStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookDeliveryStatus", str)
+	}
+	return nil
+}
+
+func (e WebhookDeliveryStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
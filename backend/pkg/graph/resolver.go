@@ -9,6 +9,7 @@ import (
 	"pentagi/pkg/providers"
 	"pentagi/pkg/server/auth"
 	"pentagi/pkg/templates"
+	"pentagi/pkg/webhooks"
 
 	"github.com/sirupsen/logrus"
 	"github.com/vxcontrol/cloud/anonymizer"
@@ -20,13 +21,16 @@ import (
 
 type Resolver struct {
 	DB              database.Querier
+	InTx            database.TxRunner
 	Config          *config.Config
 	Logger          *logrus.Entry
 	TokenCache      *auth.TokenCache
+	UserCache       *auth.UserCache
 	DefaultPrompter templates.Prompter
 	ProvidersCtrl   providers.ProviderController
 	Controller      controller.FlowController
 	Subscriptions   subscriptions.SubscriptionsController
 	Knowledge       knowledge.KnowledgeStore
 	Replacer        anonymizer.Replacer
+	Webhooks        *webhooks.Dispatcher
 }
@@ -10,11 +10,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"pentagi/pkg/attackgraph"
 	"pentagi/pkg/controller"
 	"pentagi/pkg/database"
 	"pentagi/pkg/database/converter"
 	"pentagi/pkg/flowfiles"
 	"pentagi/pkg/graph/model"
+	"pentagi/pkg/graph/subscriptions"
+	"pentagi/pkg/providers/anonymizer"
 	"pentagi/pkg/providers/anthropic"
 	"pentagi/pkg/providers/bedrock"
 	"pentagi/pkg/providers/deepseek"
@@ -58,6 +61,10 @@ func (r *mutationResolver) CreateFlow(ctx context.Context, modelProvider string,
 		return nil, fmt.Errorf("user input is required")
 	}
 
+	if err := validateTemplateScope(ctx, r.DB, input); err != nil {
+		return nil, err
+	}
+
 	var dbResources []database.UserResource
 	if _, isAdmin, err := validatePermission(ctx, "resources.view"); err == nil {
 		dbResources, err = validateUserResources(ctx, r.DB, uid, isAdmin, resourceIds)
@@ -296,6 +303,9 @@ func (r *mutationResolver) CreateAssistant(ctx context.Context, flowID int64, mo
 		if err != nil {
 			return nil, err
 		}
+		if err = validateTemplateScope(ctx, r.DB, input); err != nil {
+			return nil, err
+		}
 	} else {
 		uid, err = validatePermissionWithFlowID(ctx, "assistants.create", flowID, r.DB)
 		if err != nil {
@@ -497,20 +507,21 @@ func (r *mutationResolver) TestAgent(ctx context.Context, typeArg model.Provider
 }
 
 // TestProvider is the resolver for the testProvider field.
-func (r *mutationResolver) TestProvider(ctx context.Context, typeArg model.ProviderType, agents model.AgentsConfig) (*model.ProviderTestResult, error) {
+func (r *mutationResolver) TestProvider(ctx context.Context, typeArg model.ProviderType, agents model.AgentsConfig, providerID *int64) (*model.ProviderTestResult, error) {
 	uid, _, err := validatePermission(ctx, "settings.providers.view")
 	if err != nil {
 		return nil, err
 	}
 
 	r.Logger.WithFields(logrus.Fields{
-		"uid":  uid,
-		"type": typeArg.String(),
+		"uid":         uid,
+		"type":        typeArg.String(),
+		"provider_id": providerID,
 	}).Debug("test provider")
 
 	cfg := converter.ConvertAgentsConfigFromGqlModel(&agents)
 	prvtype := provider.ProviderType(typeArg)
-	result, err := r.ProvidersCtrl.TestProvider(ctx, prvtype, cfg)
+	result, err := r.ProvidersCtrl.TestProvider(ctx, uid, providerID, prvtype, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -772,6 +783,103 @@ func (r *mutationResolver) DeletePrompt(ctx context.Context, promptID int64) (mo
 	return model.ResultTypeSuccess, nil
 }
 
+// CreateAnonymizationPattern is the resolver for the createAnonymizationPattern field.
+func (r *mutationResolver) CreateAnonymizationPattern(ctx context.Context, input model.AnonymizationPatternInput) (*model.AnonymizationPattern, error) {
+	uid, _, err := validatePermission(ctx, "settings.user.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":     uid,
+		"name":    input.Name,
+		"enabled": input.Enabled,
+	}).Debug("create anonymization pattern")
+
+	builtin, err := anonymizer.BuiltinPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if err := anonymizer.ValidatePattern(builtin, name, input.Regex); err != nil {
+		return nil, err
+	}
+
+	pattern, err := r.DB.CreateUserAnonymizationPattern(ctx, database.CreateUserAnonymizationPatternParams{
+		UserID:  uid,
+		Name:    name,
+		Regex:   input.Regex,
+		Enabled: input.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertAnonymizationPattern(pattern), nil
+}
+
+// UpdateAnonymizationPattern is the resolver for the updateAnonymizationPattern field.
+func (r *mutationResolver) UpdateAnonymizationPattern(ctx context.Context, patternID int64, input model.AnonymizationPatternInput) (*model.AnonymizationPattern, error) {
+	uid, _, err := validatePermission(ctx, "settings.user.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":     uid,
+		"pattern": patternID,
+		"name":    input.Name,
+		"enabled": input.Enabled,
+	}).Debug("update anonymization pattern")
+
+	builtin, err := anonymizer.BuiltinPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if err := anonymizer.ValidatePattern(builtin, name, input.Regex); err != nil {
+		return nil, err
+	}
+
+	pattern, err := r.DB.UpdateUserAnonymizationPattern(ctx, database.UpdateUserAnonymizationPatternParams{
+		Name:    name,
+		Regex:   input.Regex,
+		Enabled: input.Enabled,
+		ID:      patternID,
+		UserID:  uid,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertAnonymizationPattern(pattern), nil
+}
+
+// DeleteAnonymizationPattern is the resolver for the deleteAnonymizationPattern field.
+func (r *mutationResolver) DeleteAnonymizationPattern(ctx context.Context, patternID int64) (model.ResultType, error) {
+	uid, _, err := validatePermission(ctx, "settings.user.edit")
+	if err != nil {
+		return model.ResultTypeError, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":     uid,
+		"pattern": patternID,
+	}).Debug("delete anonymization pattern")
+
+	err = r.DB.DeleteUserAnonymizationPattern(ctx, database.DeleteUserAnonymizationPatternParams{
+		ID:     patternID,
+		UserID: uid,
+	})
+	if err != nil {
+		return model.ResultTypeError, err
+	}
+
+	return model.ResultTypeSuccess, nil
+}
+
 // CreateAPIToken is the resolver for the createAPIToken field.
 func (r *mutationResolver) CreateAPIToken(ctx context.Context, input model.CreateAPITokenInput) (*model.APITokenWithSecret, error) {
 	uid, _, err := validatePermission(ctx, "settings.tokens.create")
@@ -806,6 +914,11 @@ func (r *mutationResolver) CreateAPIToken(ctx context.Context, input model.Creat
 		"ttl":  input.TTL,
 	}).Debug("create api token")
 
+	privileges, scope, err := validateAPITokenScope(ctx, r.DB, input)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := r.DB.GetUser(ctx, uid)
 	if err != nil {
 		return nil, err
@@ -829,12 +942,16 @@ func (r *mutationResolver) CreateAPIToken(ctx context.Context, input model.Creat
 	}
 
 	apiToken, err := r.DB.CreateAPIToken(ctx, database.CreateAPITokenParams{
-		TokenID: tokenID,
-		UserID:  uid,
-		RoleID:  user.RoleID,
-		Name:    nameStr,
-		Ttl:     int64(input.TTL),
-		Status:  database.TokenStatusActive,
+		TokenID:     tokenID,
+		UserID:      uid,
+		RoleID:      user.RoleID,
+		Name:        nameStr,
+		Ttl:         int64(input.TTL),
+		Status:      database.TokenStatusActive,
+		Privileges:  privileges,
+		FlowIds:     scope.FlowIDs,
+		TemplateIds: scope.TemplateIDs,
+		AllowedIps:  scope.IPs(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create token in database: %w", err)
@@ -964,6 +1081,192 @@ func (r *mutationResolver) DeleteAPIToken(ctx context.Context, tokenID string) (
 	return true, nil
 }
 
+// CreateRole is the resolver for the createRole field.
+func (r *mutationResolver) CreateRole(ctx context.Context, input model.RoleInput) (*model.Role, error) {
+	uid, _, err := validatePermission(ctx, "roles.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	isUserSession, err := validateUserType(ctx, userSessionTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isUserSession {
+		return nil, fmt.Errorf("unauthorized: non-user session is not allowed to create roles")
+	}
+
+	privs, err := validateRoleInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":        uid,
+		"name":       input.Name,
+		"privileges": privs,
+	}).Debug("create role")
+
+	if _, err := r.DB.GetRoleByName(ctx, input.Name); err == nil {
+		return nil, fmt.Errorf("role '%s' already exists", input.Name)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	var role database.Role
+	err = r.InTx(ctx, func(db database.Querier) error {
+		var err error
+		if role, err = db.CreateRole(ctx, input.Name); err != nil {
+			return fmt.Errorf("failed to create role: %w", err)
+		}
+
+		err = db.SetRolePrivileges(ctx, database.SetRolePrivilegesParams{
+			RoleID:     role.ID,
+			Privileges: privs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set role privileges: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertRole(database.GetRoleRow{
+		ID:         role.ID,
+		Name:       role.Name,
+		Privileges: privs,
+	}), nil
+}
+
+// UpdateRole is the resolver for the updateRole field.
+func (r *mutationResolver) UpdateRole(ctx context.Context, roleID int64, input model.RoleInput) (*model.Role, error) {
+	uid, _, err := validatePermission(ctx, "roles.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	isUserSession, err := validateUserType(ctx, userSessionTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isUserSession {
+		return nil, fmt.Errorf("unauthorized: non-user session is not allowed to update roles")
+	}
+
+	privs, err := validateRoleInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":        uid,
+		"roleID":     roleID,
+		"name":       input.Name,
+		"privileges": privs,
+	}).Debug("update role")
+
+	role, err := r.DB.GetRole(ctx, roleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("role not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	// the admin role keeps every privilege, new ones are granted to it by migrations
+	if role.ID == adminRoleID {
+		return nil, fmt.Errorf("built-in role '%s' can't be changed", role.Name)
+	}
+	if role.ID == userRoleID && input.Name != role.Name {
+		return nil, fmt.Errorf("built-in role '%s' can't be renamed", role.Name)
+	}
+
+	if other, err := r.DB.GetRoleByName(ctx, input.Name); err == nil && other.ID != role.ID {
+		return nil, fmt.Errorf("role '%s' already exists", input.Name)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	var updated database.Role
+	err = r.InTx(ctx, func(db database.Querier) error {
+		var err error
+		updated, err = db.UpdateRoleName(ctx, database.UpdateRoleNameParams{
+			ID:   role.ID,
+			Name: input.Name,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+
+		err = db.SetRolePrivileges(ctx, database.SetRolePrivilegesParams{
+			RoleID:     role.ID,
+			Privileges: privs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set role privileges: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invalidateRoleCaches(ctx, r.DB, r.UserCache, r.TokenCache, role.ID)
+
+	return converter.ConvertRole(database.GetRoleRow{
+		ID:         updated.ID,
+		Name:       updated.Name,
+		Privileges: privs,
+	}), nil
+}
+
+// DeleteRole is the resolver for the deleteRole field.
+func (r *mutationResolver) DeleteRole(ctx context.Context, roleID int64) (model.ResultType, error) {
+	uid, _, err := validatePermission(ctx, "roles.edit")
+	if err != nil {
+		return model.ResultTypeError, err
+	}
+
+	isUserSession, err := validateUserType(ctx, userSessionTypes...)
+	if err != nil {
+		return model.ResultTypeError, err
+	}
+
+	if !isUserSession {
+		return model.ResultTypeError, fmt.Errorf("unauthorized: non-user session is not allowed to delete roles")
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":    uid,
+		"roleID": roleID,
+	}).Debug("delete role")
+
+	if roleID == adminRoleID || roleID == userRoleID {
+		return model.ResultTypeError, fmt.Errorf("built-in role can't be deleted")
+	}
+
+	usage, err := r.DB.GetRoleUsage(ctx, roleID)
+	if err != nil {
+		return model.ResultTypeError, fmt.Errorf("failed to get role usage: %w", err)
+	}
+	if usage != 0 {
+		return model.ResultTypeError, fmt.Errorf("role is assigned to %d users or tokens", usage)
+	}
+
+	if _, err := r.DB.DeleteRole(ctx, roleID); errors.Is(err, sql.ErrNoRows) {
+		return model.ResultTypeError, fmt.Errorf("role not found")
+	} else if err != nil {
+		return model.ResultTypeError, fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	return model.ResultTypeSuccess, nil
+}
+
 // AddFavoriteFlow is the resolver for the addFavoriteFlow field.
 func (r *mutationResolver) AddFavoriteFlow(ctx context.Context, flowID int64) (model.ResultType, error) {
 	_, err := validatePermissionWithFlowID(ctx, "flows.view", flowID, r.DB)
@@ -1172,97 +1475,583 @@ func (r *mutationResolver) DeleteFlowTemplate(ctx context.Context, templateID in
 	return model.ResultTypeSuccess, nil
 }
 
-// CreateKnowledgeDocument is the resolver for the createKnowledgeDocument field.
-func (r *mutationResolver) CreateKnowledgeDocument(ctx context.Context, input model.CreateKnowledgeDocumentInput) (*model.KnowledgeDocument, error) {
-	uid, _, err := validatePermission(ctx, "knowledge.create")
+// CreateProject is the resolver for the createProject field.
+func (r *mutationResolver) CreateProject(ctx context.Context, input model.ProjectInput) (*model.Project, error) {
+	uid, _, err := validatePermission(ctx, "projects.create")
 	if err != nil {
 		return nil, err
 	}
 
-	if input.Content == "" || input.Question == "" {
-		return nil, fmt.Errorf("content and question are required")
-	}
-	if err := validateKnowledgeFieldLengths(input.Content, &input.Question, input.Description, input.CodeLang); err != nil {
-		return nil, err
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, fmt.Errorf("project name must not be empty")
 	}
 
 	r.Logger.WithFields(logrus.Fields{
-		"uid":      uid,
-		"doc_type": input.DocType,
-	}).Debug("create knowledge document")
+		"uid":  uid,
+		"name": input.Name,
+	}).Debug("create project")
 
-	return r.Knowledge.CreateDocument(ctx, uid, input)
+	project, err := r.DB.CreateProject(ctx, database.CreateProjectParams{
+		Name:        input.Name,
+		Description: input.Description,
+		UserID:      uid,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	member, err := r.DB.UpsertProjectMember(ctx, database.UpsertProjectMemberParams{
+		ProjectID: project.ID,
+		UserID:    uid,
+		Role:      database.ProjectRoleOwner,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add project owner: %w", err)
+	}
+
+	return converter.ConvertProject(project, &member.Role), nil
 }
 
-// UpdateKnowledgeDocument is the resolver for the updateKnowledgeDocument field.
-func (r *mutationResolver) UpdateKnowledgeDocument(ctx context.Context, id string, input model.UpdateKnowledgeDocumentInput) (*model.KnowledgeDocument, error) {
-	uid, admin, err := validatePermission(ctx, "knowledge.edit")
+// UpdateProject is the resolver for the updateProject field.
+func (r *mutationResolver) UpdateProject(ctx context.Context, projectID int64, input model.ProjectInput) (*model.Project, error) {
+	uid, admin, err := validatePermission(ctx, "projects.edit")
 	if err != nil {
 		return nil, err
 	}
 
-	if input.Content == "" {
-		return nil, fmt.Errorf("content is required")
-	}
-	if err := validateKnowledgeFieldLengths(input.Content, input.Question, input.Description, input.CodeLang); err != nil {
-		return nil, err
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, fmt.Errorf("project name must not be empty")
 	}
 
 	r.Logger.WithFields(logrus.Fields{
-		"uid":   uid,
-		"admin": admin,
-		"id":    id,
-	}).Debug("update knowledge document")
-
-	if admin {
-		return r.Knowledge.UpdateDocument(ctx, uid, id, input)
-	}
-	return r.Knowledge.UpdateUserDocument(ctx, uid, id, input)
-}
+		"uid":       uid,
+		"admin":     admin,
+		"projectID": projectID,
+		"name":      input.Name,
+	}).Debug("update project")
 
-// RenameKnowledgeDocument is the resolver for the renameKnowledgeDocument field.
-func (r *mutationResolver) RenameKnowledgeDocument(ctx context.Context, id string, question string) (*model.KnowledgeDocument, error) {
-	uid, admin, err := validatePermission(ctx, "knowledge.edit")
+	_, role, err := validateProjectMember(ctx, r.DB, projectID, uid, admin, database.ProjectRoleOwner)
 	if err != nil {
 		return nil, err
 	}
 
-	r.Logger.WithFields(logrus.Fields{
-		"uid":   uid,
-		"admin": admin,
-		"id":    id,
-	}).Debug("rename knowledge document")
-
-	if admin {
-		return r.Knowledge.RenameDocument(ctx, uid, id, question)
+	project, err := r.DB.UpdateProject(ctx, database.UpdateProjectParams{
+		Name:        input.Name,
+		Description: input.Description,
+		ID:          projectID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project: %w", err)
 	}
-	return r.Knowledge.RenameUserDocument(ctx, uid, id, question)
+
+	return converter.ConvertProject(project, role), nil
 }
 
-// DeleteKnowledgeDocument is the resolver for the deleteKnowledgeDocument field.
-func (r *mutationResolver) DeleteKnowledgeDocument(ctx context.Context, id string) (model.ResultType, error) {
-	uid, admin, err := validatePermission(ctx, "knowledge.delete")
+// DeleteProject is the resolver for the deleteProject field.
+func (r *mutationResolver) DeleteProject(ctx context.Context, projectID int64) (model.ResultType, error) {
+	uid, admin, err := validatePermission(ctx, "projects.delete")
 	if err != nil {
 		return model.ResultTypeError, err
 	}
 
 	r.Logger.WithFields(logrus.Fields{
-		"uid":   uid,
-		"admin": admin,
-		"id":    id,
-	}).Debug("delete knowledge document")
+		"uid":       uid,
+		"admin":     admin,
+		"projectID": projectID,
+	}).Debug("delete project")
 
-	var deleteErr error
-	if admin {
-		deleteErr = r.Knowledge.DeleteDocument(ctx, uid, id)
-	} else {
-		deleteErr = r.Knowledge.DeleteUserDocument(ctx, uid, id)
-	}
-	if deleteErr != nil {
-		return model.ResultTypeError, deleteErr
+	if _, _, err := validateProjectMember(ctx, r.DB, projectID, uid, admin, database.ProjectRoleOwner); err != nil {
+		return model.ResultTypeError, err
 	}
-	return model.ResultTypeSuccess, nil
-}
+
+	flows, err := r.DB.GetProjectFlows(ctx, sql.NullInt64{Int64: projectID, Valid: true})
+	if err != nil {
+		return model.ResultTypeError, fmt.Errorf("failed to get project flows: %w", err)
+	}
+
+	// the members are needed to tell them the shared flows are gone, so the
+	// events are published before the project and its members are deleted
+	for _, flow := range flows {
+		flow.ProjectID = sql.NullInt64{}
+		r.Subscriptions.NewFlowPublisher(flow.UserID, flow.ID).FlowUnshared(ctx, flow, nil, projectID)
+	}
+
+	if _, err := r.DB.DeleteProject(ctx, projectID); err != nil {
+		return model.ResultTypeError, fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	return model.ResultTypeSuccess, nil
+}
+
+// SetProjectMember is the resolver for the setProjectMember field.
+func (r *mutationResolver) SetProjectMember(ctx context.Context, projectID int64, userID int64, role model.ProjectRole) (*model.ProjectMember, error) {
+	uid, admin, err := validatePermission(ctx, "projects.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"projectID": projectID,
+		"userID":    userID,
+		"role":      role.String(),
+	}).Debug("set project member")
+
+	project, _, err := validateProjectMember(ctx, r.DB, projectID, uid, admin, database.ProjectRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.UserID == userID && role != model.ProjectRoleOwner {
+		return nil, fmt.Errorf("the project creator stays its owner")
+	}
+
+	user, err := r.DB.GetUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("user not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	member, err := r.DB.UpsertProjectMember(ctx, database.UpsertProjectMemberParams{
+		ProjectID: projectID,
+		UserID:    userID,
+		Role:      database.ProjectRole(role),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set project member: %w", err)
+	}
+
+	return converter.ConvertProjectMember(database.GetProjectMembersRow{
+		ProjectID: member.ProjectID,
+		UserID:    member.UserID,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt,
+		UpdatedAt: member.UpdatedAt,
+		UserName:  user.Name,
+		UserMail:  user.Mail,
+	}), nil
+}
+
+// RemoveProjectMember is the resolver for the removeProjectMember field.
+func (r *mutationResolver) RemoveProjectMember(ctx context.Context, projectID int64, userID int64) (model.ResultType, error) {
+	uid, admin, err := validatePermission(ctx, "projects.edit")
+	if err != nil {
+		return model.ResultTypeError, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"projectID": projectID,
+		"userID":    userID,
+	}).Debug("remove project member")
+
+	// members leave a project on their own, owners remove the others
+	var roles []database.ProjectRole
+	if userID != uid {
+		roles = append(roles, database.ProjectRoleOwner)
+	}
+
+	project, _, err := validateProjectMember(ctx, r.DB, projectID, uid, admin, roles...)
+	if err != nil {
+		return model.ResultTypeError, err
+	}
+
+	if project.UserID == userID {
+		return model.ResultTypeError, fmt.Errorf("the project creator can not leave the project")
+	}
+
+	err = r.DB.DeleteProjectMember(ctx, database.DeleteProjectMemberParams{
+		ProjectID: projectID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return model.ResultTypeError, fmt.Errorf("failed to remove project member: %w", err)
+	}
+
+	return model.ResultTypeSuccess, nil
+}
+
+// SetFlowProject is the resolver for the setFlowProject field.
+func (r *mutationResolver) SetFlowProject(ctx context.Context, flowID int64, projectID *int64) (*model.Flow, error) {
+	uid, err := validatePermissionWithFlowID(ctx, "flows.edit", flowID, r.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	_, admin, err := validatePermission(ctx, "flows.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"flowID":    flowID,
+		"projectID": projectID,
+	}).Debug("set flow project")
+
+	flow, err := r.DB.GetFlow(ctx, flowID)
+	if err != nil {
+		return nil, fmt.Errorf("flow not found: %w", err)
+	}
+
+	if err := validateItemProject(ctx, r.DB, uid, admin, flow.UserID, flow.ProjectID, projectID); err != nil {
+		return nil, err
+	}
+
+	updated, err := r.DB.UpdateFlowProject(ctx, database.UpdateFlowProjectParams{
+		ProjectID: database.Int64ToNullInt64(projectID),
+		ID:        flowID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set flow project: %w", err)
+	}
+
+	containers, err := r.DB.GetFlowContainers(ctx, flowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow containers: %w", err)
+	}
+
+	publisher := r.Subscriptions.NewFlowPublisher(updated.UserID, updated.ID)
+	if flow.ProjectID.Valid && flow.ProjectID != updated.ProjectID {
+		publisher.FlowUnshared(ctx, updated, containers, flow.ProjectID.Int64)
+	}
+	publisher.FlowUpdated(ctx, updated, containers)
+
+	return converter.ConvertFlow(updated, containers), nil
+}
+
+// SetFlowTemplateProject is the resolver for the setFlowTemplateProject field.
+func (r *mutationResolver) SetFlowTemplateProject(ctx context.Context, templateID int64, projectID *int64) (*model.FlowTemplate, error) {
+	uid, admin, err := validatePermission(ctx, "templates.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	isUserSession, err := validateUserType(ctx, userSessionTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isUserSession {
+		return nil, fmt.Errorf("unauthorized: non-user session is not allowed to update templates")
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":        uid,
+		"templateID": templateID,
+		"projectID":  projectID,
+	}).Debug("set flow template project")
+
+	// templates are changed by their owners only, like on update
+	template, err := r.DB.GetFlowTemplate(ctx, database.GetFlowTemplateParams{
+		ID:     templateID,
+		UserID: uid,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("template not found: %w", err)
+	}
+
+	if err := validateItemProject(ctx, r.DB, uid, admin, template.UserID, template.ProjectID, projectID); err != nil {
+		return nil, err
+	}
+
+	template, err = r.DB.UpdateFlowTemplateProject(ctx, database.UpdateFlowTemplateProjectParams{
+		ProjectID: database.Int64ToNullInt64(projectID),
+		ID:        templateID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set template project: %w", err)
+	}
+
+	r.Subscriptions.NewFlowTemplatePublisher(uid).FlowTemplateUpdated(ctx, template)
+
+	return converter.ConvertFlowTemplate(template), nil
+}
+
+// SetResourceProject is the resolver for the setResourceProject field.
+func (r *mutationResolver) SetResourceProject(ctx context.Context, resourceID int64, projectID *int64) (*model.UserResource, error) {
+	uid, admin, err := validatePermission(ctx, "resources.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":        uid,
+		"admin":      admin,
+		"resourceID": resourceID,
+		"projectID":  projectID,
+	}).Debug("set resource project")
+
+	recs, err := r.DB.GetUserResourcesByIDs(ctx, []int64{resourceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch resource: %w", err)
+	}
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("resource %d not found", resourceID)
+	}
+
+	rec := recs[0]
+	if err := validateItemProject(ctx, r.DB, uid, admin, rec.UserID, rec.ProjectID, projectID); err != nil {
+		return nil, err
+	}
+
+	rec, err = r.DB.UpdateUserResourceProject(ctx, database.UpdateUserResourceProjectParams{
+		ProjectID: database.Int64ToNullInt64(projectID),
+		ID:        resourceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set resource project: %w", err)
+	}
+
+	resource := converter.ConvertUserResource(rec)
+	r.Subscriptions.NewResourcePublisher(rec.UserID).ResourceUpdated(ctx, resource)
+
+	return resource, nil
+}
+
+// SetProviderProject is the resolver for the setProviderProject field.
+func (r *mutationResolver) SetProviderProject(ctx context.Context, providerID int64, projectID *int64) (*model.ProviderConfig, error) {
+	uid, admin, err := validatePermission(ctx, "settings.providers.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"provider":  providerID,
+		"projectID": projectID,
+	}).Debug("set provider project")
+
+	prv, err := r.DB.GetProvider(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider %d: %w", providerID, err)
+	}
+
+	if err := validateItemProject(ctx, r.DB, uid, admin, prv.UserID, prv.ProjectID, projectID); err != nil {
+		return nil, err
+	}
+
+	prv, err = r.DB.UpdateProviderProject(ctx, database.UpdateProviderProjectParams{
+		ProjectID: database.Int64ToNullInt64(projectID),
+		ID:        providerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set provider project: %w", err)
+	}
+
+	var cfg pconfig.ProviderConfig
+	if len(prv.Config) == 0 {
+		prv.Config = []byte(pconfig.EmptyProviderConfigRaw)
+	}
+	if err := json.Unmarshal(prv.Config, &cfg); err != nil {
+		return nil, err
+	}
+
+	r.Subscriptions.NewProviderPublisher(prv.UserID).ProviderUpdated(ctx, prv, &cfg)
+
+	return converter.ConvertProvider(prv, &cfg), nil
+}
+
+// SetKnowledgeDocumentProject is the resolver for the setKnowledgeDocumentProject field.
+func (r *mutationResolver) SetKnowledgeDocumentProject(ctx context.Context, id string, projectID *int64) (*model.KnowledgeDocument, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":        uid,
+		"admin":      admin,
+		"id":         id,
+		"project_id": projectID,
+	}).Debug("set knowledge document project")
+
+	doc, err := r.Knowledge.GetDocument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	current := database.Int64ToNullInt64(doc.ProjectID)
+	if err := validateItemProject(ctx, r.DB, uid, admin, doc.UserID, current, projectID); err != nil {
+		return nil, err
+	}
+
+	if admin || doc.UserID != uid {
+		return r.Knowledge.SetDocumentProject(ctx, uid, id, projectID)
+	}
+	return r.Knowledge.SetUserDocumentProject(ctx, uid, id, projectID)
+}
+
+// CreateKnowledgeDocument is the resolver for the createKnowledgeDocument field.
+func (r *mutationResolver) CreateKnowledgeDocument(ctx context.Context, input model.CreateKnowledgeDocumentInput) (*model.KnowledgeDocument, error) {
+	uid, _, err := validatePermission(ctx, "knowledge.create")
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Content == "" || input.Question == "" {
+		return nil, fmt.Errorf("content and question are required")
+	}
+	if err := validateKnowledgeFieldLengths(input.Content, &input.Question, input.Description, input.CodeLang); err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":      uid,
+		"doc_type": input.DocType,
+	}).Debug("create knowledge document")
+
+	return r.Knowledge.CreateDocument(ctx, uid, input)
+}
+
+// UpdateKnowledgeDocument is the resolver for the updateKnowledgeDocument field.
+func (r *mutationResolver) UpdateKnowledgeDocument(ctx context.Context, id string, input model.UpdateKnowledgeDocumentInput) (*model.KnowledgeDocument, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Content == "" {
+		return nil, fmt.Errorf("content is required")
+	}
+	if err := validateKnowledgeFieldLengths(input.Content, input.Question, input.Description, input.CodeLang); err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":   uid,
+		"admin": admin,
+		"id":    id,
+	}).Debug("update knowledge document")
+
+	if admin {
+		return r.Knowledge.UpdateDocument(ctx, uid, id, input)
+	}
+	return r.Knowledge.UpdateUserDocument(ctx, uid, id, input)
+}
+
+// RenameKnowledgeDocument is the resolver for the renameKnowledgeDocument field.
+func (r *mutationResolver) RenameKnowledgeDocument(ctx context.Context, id string, question string) (*model.KnowledgeDocument, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":   uid,
+		"admin": admin,
+		"id":    id,
+	}).Debug("rename knowledge document")
+
+	if admin {
+		return r.Knowledge.RenameDocument(ctx, uid, id, question)
+	}
+	return r.Knowledge.RenameUserDocument(ctx, uid, id, question)
+}
+
+// DeleteKnowledgeDocument is the resolver for the deleteKnowledgeDocument field.
+func (r *mutationResolver) DeleteKnowledgeDocument(ctx context.Context, id string) (model.ResultType, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.delete")
+	if err != nil {
+		return model.ResultTypeError, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":   uid,
+		"admin": admin,
+		"id":    id,
+	}).Debug("delete knowledge document")
+
+	var deleteErr error
+	if admin {
+		deleteErr = r.Knowledge.DeleteDocument(ctx, uid, id)
+	} else {
+		deleteErr = r.Knowledge.DeleteUserDocument(ctx, uid, id)
+	}
+	if deleteErr != nil {
+		return model.ResultTypeError, deleteErr
+	}
+	return model.ResultTypeSuccess, nil
+}
+
+// VoteKnowledgeDocument is the resolver for the voteKnowledgeDocument field.
+func (r *mutationResolver) VoteKnowledgeDocument(ctx context.Context, id string, vote *model.KnowledgeVote) (*model.KnowledgeDocument, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":   uid,
+		"admin": admin,
+		"id":    id,
+		"vote":  vote,
+	}).Debug("vote knowledge document")
+
+	if admin {
+		return r.Knowledge.VoteDocument(ctx, uid, id, vote)
+	}
+	return r.Knowledge.VoteUserDocument(ctx, uid, id, vote)
+}
+
+// ArchiveKnowledgeDocument is the resolver for the archiveKnowledgeDocument field.
+func (r *mutationResolver) ArchiveKnowledgeDocument(ctx context.Context, id string, archived bool) (*model.KnowledgeDocument, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":      uid,
+		"admin":    admin,
+		"id":       id,
+		"archived": archived,
+	}).Debug("archive knowledge document")
+
+	if admin {
+		return r.Knowledge.ArchiveDocument(ctx, uid, id, archived)
+	}
+	return r.Knowledge.ArchiveUserDocument(ctx, uid, id, archived)
+}
+
+// SetKnowledgeDocumentExpiry is the resolver for the setKnowledgeDocumentExpiry field.
+func (r *mutationResolver) SetKnowledgeDocumentExpiry(ctx context.Context, id string, expiresAt *time.Time) (*model.KnowledgeDocument, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":        uid,
+		"admin":      admin,
+		"id":         id,
+		"expires_at": expiresAt,
+	}).Debug("set knowledge document expiry")
+
+	if admin {
+		return r.Knowledge.SetDocumentExpiry(ctx, uid, id, expiresAt)
+	}
+	return r.Knowledge.SetUserDocumentExpiry(ctx, uid, id, expiresAt)
+}
+
+// StartEmbeddingMigration is the resolver for the startEmbeddingMigration field.
+func (r *mutationResolver) StartEmbeddingMigration(ctx context.Context) (*model.EmbeddingMigration, error) {
+	uid, _, err := validatePermission(ctx, "knowledge.admin")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid": uid,
+	}).Debug("start embedding migration")
+
+	return r.Knowledge.StartEmbeddingMigration(ctx)
+}
 
 // AnonymizeText is the resolver for the anonymizeText field.
 func (r *mutationResolver) AnonymizeText(ctx context.Context, text string) (string, error) {
@@ -1282,6 +2071,190 @@ func (r *mutationResolver) AnonymizeText(ctx context.Context, text string) (stri
 	return r.Replacer.ReplaceString(text), nil
 }
 
+// CreateWebhook is the resolver for the createWebhook field.
+func (r *mutationResolver) CreateWebhook(ctx context.Context, input model.WebhookInput) (*model.Webhook, error) {
+	uid, _, err := validatePermission(ctx, "webhooks.create")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":  uid,
+		"name": input.Name,
+	}).Debug("create webhook")
+
+	if err := validateWebhookInput(ctx, r.DB, r.Config, uid, input); err != nil {
+		return nil, err
+	}
+
+	var secret string
+	if input.Secret != nil {
+		secret = *input.Secret
+	}
+
+	hook, err := r.DB.CreateWebhook(ctx, database.CreateWebhookParams{
+		UserID:  uid,
+		Name:    input.Name,
+		Url:     input.URL,
+		Secret:  secret,
+		Events:  input.Events,
+		FlowIds: input.FlowIds,
+		Enabled: input.Enabled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	if r.Webhooks != nil {
+		r.Webhooks.Invalidate()
+	}
+
+	return converter.ConvertWebhook(hook), nil
+}
+
+// UpdateWebhook is the resolver for the updateWebhook field.
+func (r *mutationResolver) UpdateWebhook(ctx context.Context, webhookID int64, input model.WebhookInput) (*model.Webhook, error) {
+	uid, admin, err := validatePermission(ctx, "webhooks.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"webhookID": webhookID,
+		"name":      input.Name,
+	}).Debug("update webhook")
+
+	hook, err := getWebhook(ctx, r.DB, webhookID, uid, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateWebhookInput(ctx, r.DB, r.Config, hook.UserID, input); err != nil {
+		return nil, err
+	}
+
+	secret := hook.Secret
+	if input.Secret != nil {
+		secret = *input.Secret
+	}
+
+	hook, err = r.DB.UpdateWebhook(ctx, database.UpdateWebhookParams{
+		Name:    input.Name,
+		Url:     input.URL,
+		Secret:  secret,
+		Events:  input.Events,
+		FlowIds: input.FlowIds,
+		Enabled: input.Enabled,
+		ID:      hook.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	if r.Webhooks != nil {
+		r.Webhooks.Invalidate()
+	}
+
+	return converter.ConvertWebhook(hook), nil
+}
+
+// DeleteWebhook is the resolver for the deleteWebhook field.
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, webhookID int64) (model.ResultType, error) {
+	uid, admin, err := validatePermission(ctx, "webhooks.delete")
+	if err != nil {
+		return model.ResultTypeError, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"webhookID": webhookID,
+	}).Debug("delete webhook")
+
+	hook, err := getWebhook(ctx, r.DB, webhookID, uid, admin)
+	if err != nil {
+		return model.ResultTypeError, err
+	}
+
+	if _, err := r.DB.DeleteWebhook(ctx, hook.ID); err != nil {
+		return model.ResultTypeError, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	if r.Webhooks != nil {
+		r.Webhooks.Invalidate()
+	}
+
+	return model.ResultTypeSuccess, nil
+}
+
+// TestWebhook is the resolver for the testWebhook field.
+func (r *mutationResolver) TestWebhook(ctx context.Context, webhookID int64) (*model.WebhookDelivery, error) {
+	uid, admin, err := validatePermission(ctx, "webhooks.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"webhookID": webhookID,
+	}).Debug("test webhook")
+
+	if r.Webhooks == nil {
+		return nil, fmt.Errorf("webhooks are not available")
+	}
+
+	hook, err := getWebhook(ctx, r.DB, webhookID, uid, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := r.Webhooks.Ping(ctx, hook)
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertWebhookDelivery(delivery), nil
+}
+
+// ReplayWebhookDelivery is the resolver for the replayWebhookDelivery field.
+func (r *mutationResolver) ReplayWebhookDelivery(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error) {
+	uid, admin, err := validatePermission(ctx, "webhooks.edit")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":        uid,
+		"admin":      admin,
+		"deliveryID": deliveryID,
+	}).Debug("replay webhook delivery")
+
+	if r.Webhooks == nil {
+		return nil, fmt.Errorf("webhooks are not available")
+	}
+
+	delivery, err := r.DB.GetWebhookDelivery(ctx, deliveryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("webhook delivery not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	if _, err := getWebhook(ctx, r.DB, delivery.WebhookID, uid, admin); err != nil {
+		return nil, err
+	}
+
+	replay, err := r.Webhooks.Replay(ctx, delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertWebhookDelivery(replay), nil
+}
+
 // Providers is the resolver for the providers field.
 func (r *queryResolver) Providers(ctx context.Context) ([]*model.Provider, error) {
 	uid, _, err := validatePermission(ctx, "providers.view")
@@ -1353,6 +2326,7 @@ func (r *queryResolver) Flows(ctx context.Context) ([]*model.Flow, error) {
 	if err != nil {
 		return nil, err
 	}
+	flows = filterFlowsByTokenScope(ctx, flows)
 
 	if _, admin, err = validatePermission(ctx, "containers.view"); err == nil {
 		if admin {
@@ -1469,7 +2443,7 @@ func (r *queryResolver) Screenshots(ctx context.Context, flowID int64) ([]*model
 }
 
 // TerminalLogs is the resolver for the terminalLogs field.
-func (r *queryResolver) TerminalLogs(ctx context.Context, flowID int64) ([]*model.TerminalLog, error) {
+func (r *queryResolver) TerminalLogs(ctx context.Context, flowID int64, filter *model.LogFilter) ([]*model.TerminalLog, error) {
 	uid, err := validatePermissionWithFlowID(ctx, "termlogs.view", flowID, r.DB)
 	if err != nil {
 		return nil, err
@@ -1480,7 +2454,30 @@ func (r *queryResolver) TerminalLogs(ctx context.Context, flowID int64) ([]*mode
 		"flow": flowID,
 	}).Debug("get term logs")
 
-	logs, err := r.DB.GetFlowTermLogs(ctx, flowID)
+	var logs []database.Termlog
+	if filter == nil {
+		logs, err = r.DB.GetFlowTermLogs(ctx, flowID)
+	} else {
+		page, perr := newLogPage(*filter)
+		if perr != nil {
+			return nil, perr
+		}
+
+		logs, err = r.DB.GetFlowTermLogsPage(ctx, database.GetFlowTermLogsPageParams{
+			FlowID:    flowID,
+			AfterID:   page.afterID,
+			BeforeID:  page.beforeID,
+			Since:     page.since,
+			Until:     page.until,
+			TaskID:    page.taskID,
+			SubtaskID: page.subtaskID,
+			Types:     page.types,
+			Search:    page.search,
+			Reverse:   page.reverse,
+			PageSize:  page.pageSize,
+		})
+		logs = ascending(page, logs)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1489,7 +2486,7 @@ func (r *queryResolver) TerminalLogs(ctx context.Context, flowID int64) ([]*mode
 }
 
 // MessageLogs is the resolver for the messageLogs field.
-func (r *queryResolver) MessageLogs(ctx context.Context, flowID int64) ([]*model.MessageLog, error) {
+func (r *queryResolver) MessageLogs(ctx context.Context, flowID int64, filter *model.LogFilter) ([]*model.MessageLog, error) {
 	uid, err := validatePermissionWithFlowID(ctx, "msglogs.view", flowID, r.DB)
 	if err != nil {
 		return nil, err
@@ -1500,7 +2497,30 @@ func (r *queryResolver) MessageLogs(ctx context.Context, flowID int64) ([]*model
 		"flow": flowID,
 	}).Debug("get msg logs")
 
-	logs, err := r.DB.GetFlowMsgLogs(ctx, flowID)
+	var logs []database.Msglog
+	if filter == nil {
+		logs, err = r.DB.GetFlowMsgLogs(ctx, flowID)
+	} else {
+		page, perr := newLogPage(*filter)
+		if perr != nil {
+			return nil, perr
+		}
+
+		logs, err = r.DB.GetFlowMsgLogsPage(ctx, database.GetFlowMsgLogsPageParams{
+			FlowID:    flowID,
+			AfterID:   page.afterID,
+			BeforeID:  page.beforeID,
+			Since:     page.since,
+			Until:     page.until,
+			TaskID:    page.taskID,
+			SubtaskID: page.subtaskID,
+			Types:     page.types,
+			Search:    page.search,
+			Reverse:   page.reverse,
+			PageSize:  page.pageSize,
+		})
+		logs = ascending(page, logs)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1509,7 +2529,7 @@ func (r *queryResolver) MessageLogs(ctx context.Context, flowID int64) ([]*model
 }
 
 // AgentLogs is the resolver for the agentLogs field.
-func (r *queryResolver) AgentLogs(ctx context.Context, flowID int64) ([]*model.AgentLog, error) {
+func (r *queryResolver) AgentLogs(ctx context.Context, flowID int64, filter *model.LogFilter) ([]*model.AgentLog, error) {
 	uid, err := validatePermissionWithFlowID(ctx, "agentlogs.view", flowID, r.DB)
 	if err != nil {
 		return nil, err
@@ -1520,7 +2540,30 @@ func (r *queryResolver) AgentLogs(ctx context.Context, flowID int64) ([]*model.A
 		"flow": flowID,
 	}).Debug("get agent logs")
 
-	logs, err := r.DB.GetFlowAgentLogs(ctx, flowID)
+	var logs []database.Agentlog
+	if filter == nil {
+		logs, err = r.DB.GetFlowAgentLogs(ctx, flowID)
+	} else {
+		page, perr := newLogPage(*filter)
+		if perr != nil {
+			return nil, perr
+		}
+
+		logs, err = r.DB.GetFlowAgentLogsPage(ctx, database.GetFlowAgentLogsPageParams{
+			FlowID:    flowID,
+			AfterID:   page.afterID,
+			BeforeID:  page.beforeID,
+			Since:     page.since,
+			Until:     page.until,
+			TaskID:    page.taskID,
+			SubtaskID: page.subtaskID,
+			Types:     page.types,
+			Search:    page.search,
+			Reverse:   page.reverse,
+			PageSize:  page.pageSize,
+		})
+		logs = ascending(page, logs)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1529,7 +2572,7 @@ func (r *queryResolver) AgentLogs(ctx context.Context, flowID int64) ([]*model.A
 }
 
 // SearchLogs is the resolver for the searchLogs field.
-func (r *queryResolver) SearchLogs(ctx context.Context, flowID int64) ([]*model.SearchLog, error) {
+func (r *queryResolver) SearchLogs(ctx context.Context, flowID int64, filter *model.LogFilter) ([]*model.SearchLog, error) {
 	uid, err := validatePermissionWithFlowID(ctx, "searchlogs.view", flowID, r.DB)
 	if err != nil {
 		return nil, err
@@ -1540,7 +2583,30 @@ func (r *queryResolver) SearchLogs(ctx context.Context, flowID int64) ([]*model.
 		"flow": flowID,
 	}).Debug("get search logs")
 
-	logs, err := r.DB.GetFlowSearchLogs(ctx, flowID)
+	var logs []database.Searchlog
+	if filter == nil {
+		logs, err = r.DB.GetFlowSearchLogs(ctx, flowID)
+	} else {
+		page, perr := newLogPage(*filter)
+		if perr != nil {
+			return nil, perr
+		}
+
+		logs, err = r.DB.GetFlowSearchLogsPage(ctx, database.GetFlowSearchLogsPageParams{
+			FlowID:    flowID,
+			AfterID:   page.afterID,
+			BeforeID:  page.beforeID,
+			Since:     page.since,
+			Until:     page.until,
+			TaskID:    page.taskID,
+			SubtaskID: page.subtaskID,
+			Types:     page.types,
+			Search:    page.search,
+			Reverse:   page.reverse,
+			PageSize:  page.pageSize,
+		})
+		logs = ascending(page, logs)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1549,7 +2615,7 @@ func (r *queryResolver) SearchLogs(ctx context.Context, flowID int64) ([]*model.
 }
 
 // VectorStoreLogs is the resolver for the vectorStoreLogs field.
-func (r *queryResolver) VectorStoreLogs(ctx context.Context, flowID int64) ([]*model.VectorStoreLog, error) {
+func (r *queryResolver) VectorStoreLogs(ctx context.Context, flowID int64, filter *model.LogFilter) ([]*model.VectorStoreLog, error) {
 	uid, err := validatePermissionWithFlowID(ctx, "vecstorelogs.view", flowID, r.DB)
 	if err != nil {
 		return nil, err
@@ -1560,7 +2626,30 @@ func (r *queryResolver) VectorStoreLogs(ctx context.Context, flowID int64) ([]*m
 		"flow": flowID,
 	}).Debug("get vector store logs")
 
-	logs, err := r.DB.GetFlowVectorStoreLogs(ctx, flowID)
+	var logs []database.Vecstorelog
+	if filter == nil {
+		logs, err = r.DB.GetFlowVectorStoreLogs(ctx, flowID)
+	} else {
+		page, perr := newLogPage(*filter)
+		if perr != nil {
+			return nil, perr
+		}
+
+		logs, err = r.DB.GetFlowVectorStoreLogsPage(ctx, database.GetFlowVectorStoreLogsPageParams{
+			FlowID:    flowID,
+			AfterID:   page.afterID,
+			BeforeID:  page.beforeID,
+			Since:     page.since,
+			Until:     page.until,
+			TaskID:    page.taskID,
+			SubtaskID: page.subtaskID,
+			Types:     page.types,
+			Search:    page.search,
+			Reverse:   page.reverse,
+			PageSize:  page.pageSize,
+		})
+		logs = ascending(page, logs)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1569,7 +2658,7 @@ func (r *queryResolver) VectorStoreLogs(ctx context.Context, flowID int64) ([]*m
 }
 
 // ToolCallLogs is the resolver for the toolCallLogs field.
-func (r *queryResolver) ToolCallLogs(ctx context.Context, flowID int64) ([]*model.ToolCallLog, error) {
+func (r *queryResolver) ToolCallLogs(ctx context.Context, flowID int64, filter *model.LogFilter) ([]*model.ToolCallLog, error) {
 	uid, err := validatePermissionWithFlowID(ctx, "toolcalls.view", flowID, r.DB)
 	if err != nil {
 		return nil, err
@@ -1580,7 +2669,30 @@ func (r *queryResolver) ToolCallLogs(ctx context.Context, flowID int64) ([]*mode
 		"flow": flowID,
 	}).Debug("get tool call logs")
 
-	logs, err := r.DB.GetFlowToolcalls(ctx, flowID)
+	var logs []database.Toolcall
+	if filter == nil {
+		logs, err = r.DB.GetFlowToolcalls(ctx, flowID)
+	} else {
+		page, perr := newLogPage(*filter)
+		if perr != nil {
+			return nil, perr
+		}
+
+		logs, err = r.DB.GetFlowToolcallsPage(ctx, database.GetFlowToolcallsPageParams{
+			FlowID:    flowID,
+			AfterID:   page.afterID,
+			BeforeID:  page.beforeID,
+			Since:     page.since,
+			Until:     page.until,
+			TaskID:    page.taskID,
+			SubtaskID: page.subtaskID,
+			Types:     page.types,
+			Search:    page.search,
+			Reverse:   page.reverse,
+			PageSize:  page.pageSize,
+		})
+		logs = ascending(page, logs)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1588,8 +2700,28 @@ func (r *queryResolver) ToolCallLogs(ctx context.Context, flowID int64) ([]*mode
 	return converter.ConvertToolCallLogs(logs), nil
 }
 
+// FlowAttackGraph is the resolver for the flowAttackGraph field.
+func (r *queryResolver) FlowAttackGraph(ctx context.Context, flowID int64) (*model.AttackGraph, error) {
+	uid, err := validatePermissionWithFlowID(ctx, "toolcalls.view", flowID, r.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":  uid,
+		"flow": flowID,
+	}).Debug("get flow attack graph")
+
+	graph, err := attackgraph.NewLoader(r.DB, r.Config, r.ProvidersCtrl.GraphitiClient()).Load(ctx, flowID)
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertAttackGraph(graph), nil
+}
+
 // AssistantLogs is the resolver for the assistantLogs field.
-func (r *queryResolver) AssistantLogs(ctx context.Context, flowID int64, assistantID int64) ([]*model.AssistantLog, error) {
+func (r *queryResolver) AssistantLogs(ctx context.Context, flowID int64, assistantID int64, filter *model.LogFilter) ([]*model.AssistantLog, error) {
 	uid, err := validatePermissionWithFlowID(ctx, "assistantlogs.view", flowID, r.DB)
 	if err != nil {
 		return nil, err
@@ -1601,10 +2733,32 @@ func (r *queryResolver) AssistantLogs(ctx context.Context, flowID int64, assista
 		"assistant": assistantID,
 	}).Debug("get assistant logs")
 
-	logs, err := r.DB.GetFlowAssistantLogs(ctx, database.GetFlowAssistantLogsParams{
-		FlowID:      flowID,
-		AssistantID: assistantID,
-	})
+	var logs []database.Assistantlog
+	if filter == nil {
+		logs, err = r.DB.GetFlowAssistantLogs(ctx, database.GetFlowAssistantLogsParams{
+			FlowID:      flowID,
+			AssistantID: assistantID,
+		})
+	} else {
+		page, perr := newLogPage(*filter)
+		if perr != nil {
+			return nil, perr
+		}
+
+		logs, err = r.DB.GetFlowAssistantLogsPage(ctx, database.GetFlowAssistantLogsPageParams{
+			FlowID:      flowID,
+			AssistantID: assistantID,
+			AfterID:     page.afterID,
+			BeforeID:    page.beforeID,
+			Since:       page.since,
+			Until:       page.until,
+			Types:       page.types,
+			Search:      page.search,
+			Reverse:     page.reverse,
+			PageSize:    page.pageSize,
+		})
+		logs = ascending(page, logs)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -2227,6 +3381,33 @@ func (r *queryResolver) SettingsProviders(ctx context.Context) (*model.Providers
 	return &config, nil
 }
 
+// ProviderCapabilities is the resolver for the providerCapabilities field.
+func (r *queryResolver) ProviderCapabilities(ctx context.Context, typeArg *model.ProviderType) ([]*model.ModelCapabilities, error) {
+	uid, _, err := validatePermission(ctx, "settings.providers.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid": uid,
+	}).Debug("get provider capabilities")
+
+	var capabilities []database.ProviderCapability
+	if typeArg != nil {
+		capabilities, err = r.DB.GetUserProviderCapabilitiesByType(ctx, database.GetUserProviderCapabilitiesByTypeParams{
+			UserID: uid,
+			Type:   database.ProviderType(*typeArg),
+		})
+	} else {
+		capabilities, err = r.DB.GetUserProviderCapabilities(ctx, uid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertProviderCapabilities(capabilities), nil
+}
+
 // SettingsPrompts is the resolver for the settingsPrompts field.
 func (r *queryResolver) SettingsPrompts(ctx context.Context) (*model.PromptsConfig, error) {
 	uid, _, err := validatePermission(ctx, "settings.prompts.view")
@@ -2293,6 +3474,43 @@ func (r *queryResolver) SettingsUser(ctx context.Context) (*model.UserPreference
 	return converter.ConvertUserPreferences(prefs), nil
 }
 
+// SettingsAnonymization is the resolver for the settingsAnonymization field.
+func (r *queryResolver) SettingsAnonymization(ctx context.Context) (*model.AnonymizationConfig, error) {
+	uid, _, err := validatePermission(ctx, "settings.user.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid": uid,
+	}).Debug("get anonymization settings")
+
+	patterns, err := r.DB.GetUserAnonymizationPatterns(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	builtin, err := anonymizer.BuiltinPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	// the patterns of the instance secrets are never listed, they contain them
+	defaults := make([]*model.DefaultAnonymizationPattern, 0, len(builtin))
+	for _, pattern := range builtin {
+		defaults = append(defaults, &model.DefaultAnonymizationPattern{
+			Name:  pattern.Name,
+			Regex: pattern.Regex,
+		})
+	}
+
+	return &model.AnonymizationConfig{
+		Enabled:     r.Config.LLMAnonymizationEnabled,
+		Default:     defaults,
+		UserDefined: converter.ConvertAnonymizationPatterns(patterns),
+	}, nil
+}
+
 // APIToken is the resolver for the apiToken field.
 func (r *queryResolver) APIToken(ctx context.Context, tokenID string) (*model.APIToken, error) {
 	uid, admin, err := validatePermission(ctx, "settings.tokens.view")
@@ -2365,6 +3583,56 @@ func (r *queryResolver) APITokens(ctx context.Context) ([]*model.APIToken, error
 	return converter.ConvertAPITokens(tokens), nil
 }
 
+// Roles is the resolver for the roles field.
+func (r *queryResolver) Roles(ctx context.Context) ([]*model.Role, error) {
+	uid, _, err := validatePermission(ctx, "roles.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid": uid,
+	}).Debug("get roles")
+
+	roles, err := r.DB.GetRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	return converter.ConvertRoles(roles), nil
+}
+
+// Role is the resolver for the role field.
+func (r *queryResolver) Role(ctx context.Context, roleID int64) (*model.Role, error) {
+	uid, _, err := validatePermission(ctx, "roles.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":    uid,
+		"roleID": roleID,
+	}).Debug("get role")
+
+	role, err := r.DB.GetRole(ctx, roleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("role not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return converter.ConvertRole(role), nil
+}
+
+// Privileges is the resolver for the privileges field.
+func (r *queryResolver) Privileges(ctx context.Context) ([]string, error) {
+	if _, _, err := validatePermission(ctx, "roles.view"); err != nil {
+		return nil, err
+	}
+
+	return auth.KnownPrivileges(), nil
+}
+
 // FlowTemplate is the resolver for the flowTemplate field.
 func (r *queryResolver) FlowTemplate(ctx context.Context, templateID int64) (*model.FlowTemplate, error) {
 	uid, _, err := validatePermission(ctx, "templates.view")
@@ -2394,43 +3662,282 @@ func (r *queryResolver) FlowTemplate(ctx context.Context, templateID int64) (*mo
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("template not found")
 		}
-		return nil, fmt.Errorf("failed to get template: %w", err)
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	return converter.ConvertFlowTemplate(template), nil
+}
+
+// FlowTemplates is the resolver for the flowTemplates field.
+func (r *queryResolver) FlowTemplates(ctx context.Context) ([]*model.FlowTemplate, error) {
+	uid, _, err := validatePermission(ctx, "templates.view")
+	if err != nil {
+		return nil, err
+	}
+
+	isUserSession, err := validateUserType(ctx, userSessionTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isUserSession {
+		return nil, fmt.Errorf("unauthorized: non-user session is not allowed to view templates")
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid": uid,
+	}).Debug("get flow templates")
+
+	templates, err := r.DB.GetFlowTemplatesByUserID(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get templates: %w", err)
+	}
+
+	return converter.ConvertFlowTemplates(templates), nil
+}
+
+// Resources is the resolver for the resources field.
+func (r *queryResolver) Resources(ctx context.Context, path *string, recursive *bool) ([]*model.UserResource, error) {
+	uid, admin, err := validatePermission(ctx, "resources.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"path":      path,
+		"recursive": recursive,
+	}).Debug("list user resources")
+
+	reqPath := ""
+	if path != nil {
+		reqPath = strings.TrimSpace(*path)
+		if reqPath != "" {
+			reqPath, err = resources.SanitizeResourcePath(reqPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	isRecursive := recursive != nil && *recursive
+
+	var recs []database.UserResource
+
+	if !admin {
+		switch {
+		case reqPath == "" && isRecursive:
+			recs, err = r.DB.GetUserResourcesAll(ctx, uid)
+		case reqPath == "":
+			recs, err = r.DB.GetUserResourcesRoot(ctx, uid)
+		case isRecursive:
+			escaped := resources.EscapeLike(reqPath)
+			recs, err = r.DB.GetUserResourcesRecursive(ctx, database.GetUserResourcesRecursiveParams{
+				UserID:      uid,
+				DirPath:     reqPath,
+				ChildPrefix: escaped + "/%",
+			})
+		default:
+			escaped := resources.EscapeLike(reqPath)
+			recs, err = r.DB.GetUserResourcesInDir(ctx, database.GetUserResourcesInDirParams{
+				UserID:      uid,
+				DirPath:     reqPath,
+				ChildPrefix: escaped + "/%",
+				DeepPrefix:  escaped + "/%/%",
+			})
+		}
+	} else {
+		switch {
+		case reqPath == "" && isRecursive:
+			recs, err = r.DB.GetAllResourcesAll(ctx)
+		case reqPath == "":
+			recs, err = r.DB.GetAllResourcesRoot(ctx)
+		case isRecursive:
+			escaped := resources.EscapeLike(reqPath)
+			recs, err = r.DB.GetAllResourcesRecursive(ctx, database.GetAllResourcesRecursiveParams{
+				DirPath:     reqPath,
+				ChildPrefix: escaped + "/%",
+			})
+		default:
+			escaped := resources.EscapeLike(reqPath)
+			recs, err = r.DB.GetAllResourcesInDir(ctx, database.GetAllResourcesInDirParams{
+				DirPath:     reqPath,
+				ChildPrefix: escaped + "/%",
+				DeepPrefix:  escaped + "/%/%",
+			})
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	return converter.ConvertUserResources(recs), nil
+}
+
+// Projects is the resolver for the projects field.
+func (r *queryResolver) Projects(ctx context.Context) ([]*model.Project, error) {
+	uid, admin, err := validatePermission(ctx, "projects.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":   uid,
+		"admin": admin,
+	}).Debug("get projects")
+
+	if admin {
+		projects, err := r.DB.GetProjects(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get projects: %w", err)
+		}
+		return converter.ConvertProjects(projects), nil
+	}
+
+	projects, err := r.DB.GetUserProjects(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	return converter.ConvertUserProjects(projects), nil
+}
+
+// Project is the resolver for the project field.
+func (r *queryResolver) Project(ctx context.Context, projectID int64) (*model.Project, error) {
+	uid, admin, err := validatePermission(ctx, "projects.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"projectID": projectID,
+	}).Debug("get project")
+
+	project, role, err := validateProjectMember(ctx, r.DB, projectID, uid, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertProject(project, role), nil
+}
+
+// ProjectMembers is the resolver for the projectMembers field.
+func (r *queryResolver) ProjectMembers(ctx context.Context, projectID int64) ([]*model.ProjectMember, error) {
+	uid, admin, err := validatePermission(ctx, "projects.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"projectID": projectID,
+	}).Debug("get project members")
+
+	if _, _, err := validateProjectMember(ctx, r.DB, projectID, uid, admin); err != nil {
+		return nil, err
+	}
+
+	members, err := r.DB.GetProjectMembers(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project members: %w", err)
+	}
+
+	return converter.ConvertProjectMembers(members), nil
+}
+
+// ProjectFlows is the resolver for the projectFlows field.
+func (r *queryResolver) ProjectFlows(ctx context.Context, projectID int64) ([]*model.Flow, error) {
+	uid, admin, err := validatePermission(ctx, "flows.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"projectID": projectID,
+	}).Debug("get project flows")
+
+	if _, _, err := validateProjectMember(ctx, r.DB, projectID, uid, admin); err != nil {
+		return nil, err
+	}
+
+	flows, err := r.DB.GetProjectFlows(ctx, sql.NullInt64{Int64: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project flows: %w", err)
+	}
+	flows = filterFlowsByTokenScope(ctx, flows)
+
+	var containers []database.Container
+	if _, _, err = validatePermission(ctx, "containers.view"); err == nil {
+		for _, flow := range flows {
+			flowContainers, err := r.DB.GetFlowContainers(ctx, flow.ID)
+			if err != nil {
+				return nil, err
+			}
+			containers = append(containers, flowContainers...)
+		}
 	}
 
-	return converter.ConvertFlowTemplate(template), nil
+	return converter.ConvertFlows(flows, containers), nil
 }
 
-// FlowTemplates is the resolver for the flowTemplates field.
-func (r *queryResolver) FlowTemplates(ctx context.Context) ([]*model.FlowTemplate, error) {
-	uid, _, err := validatePermission(ctx, "templates.view")
+// ProjectTemplates is the resolver for the projectTemplates field.
+func (r *queryResolver) ProjectTemplates(ctx context.Context, projectID int64) ([]*model.FlowTemplate, error) {
+	uid, admin, err := validatePermission(ctx, "templates.view")
 	if err != nil {
 		return nil, err
 	}
 
-	isUserSession, err := validateUserType(ctx, userSessionTypes...)
-	if err != nil {
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"projectID": projectID,
+	}).Debug("get project templates")
+
+	if _, _, err := validateProjectMember(ctx, r.DB, projectID, uid, admin); err != nil {
 		return nil, err
 	}
 
-	if !isUserSession {
-		return nil, fmt.Errorf("unauthorized: non-user session is not allowed to view templates")
+	templates, err := r.DB.GetProjectFlowTemplates(ctx, sql.NullInt64{Int64: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project templates: %w", err)
+	}
+
+	return converter.ConvertFlowTemplates(templates), nil
+}
+
+// ProjectResources is the resolver for the projectResources field.
+func (r *queryResolver) ProjectResources(ctx context.Context, projectID int64) ([]*model.UserResource, error) {
+	uid, admin, err := validatePermission(ctx, "resources.view")
+	if err != nil {
+		return nil, err
 	}
 
 	r.Logger.WithFields(logrus.Fields{
-		"uid": uid,
-	}).Debug("get flow templates")
+		"uid":       uid,
+		"admin":     admin,
+		"projectID": projectID,
+	}).Debug("get project resources")
 
-	templates, err := r.DB.GetFlowTemplatesByUserID(ctx, uid)
+	if _, _, err := validateProjectMember(ctx, r.DB, projectID, uid, admin); err != nil {
+		return nil, err
+	}
+
+	recs, err := r.DB.GetProjectResources(ctx, sql.NullInt64{Int64: projectID, Valid: true})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get templates: %w", err)
+		return nil, fmt.Errorf("failed to get project resources: %w", err)
 	}
 
-	return converter.ConvertFlowTemplates(templates), nil
+	return converter.ConvertUserResources(recs), nil
 }
 
-// Resources is the resolver for the resources field.
-func (r *queryResolver) Resources(ctx context.Context, path *string, recursive *bool) ([]*model.UserResource, error) {
-	uid, admin, err := validatePermission(ctx, "resources.view")
+// ProjectProviders is the resolver for the projectProviders field.
+func (r *queryResolver) ProjectProviders(ctx context.Context, projectID int64) ([]*model.ProviderConfig, error) {
+	uid, admin, err := validatePermission(ctx, "settings.providers.view")
 	if err != nil {
 		return nil, err
 	}
@@ -2438,72 +3945,55 @@ func (r *queryResolver) Resources(ctx context.Context, path *string, recursive *
 	r.Logger.WithFields(logrus.Fields{
 		"uid":       uid,
 		"admin":     admin,
-		"path":      path,
-		"recursive": recursive,
-	}).Debug("list user resources")
+		"projectID": projectID,
+	}).Debug("get project providers")
 
-	reqPath := ""
-	if path != nil {
-		reqPath = strings.TrimSpace(*path)
-		if reqPath != "" {
-			reqPath, err = resources.SanitizeResourcePath(reqPath)
-			if err != nil {
-				return nil, err
-			}
-		}
+	if _, _, err := validateProjectMember(ctx, r.DB, projectID, uid, admin); err != nil {
+		return nil, err
 	}
-	isRecursive := recursive != nil && *recursive
 
-	var recs []database.UserResource
+	providers, err := r.DB.GetProjectProviders(ctx, sql.NullInt64{Int64: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project providers: %w", err)
+	}
 
-	if !admin {
-		switch {
-		case reqPath == "" && isRecursive:
-			recs, err = r.DB.GetUserResourcesAll(ctx, uid)
-		case reqPath == "":
-			recs, err = r.DB.GetUserResourcesRoot(ctx, uid)
-		case isRecursive:
-			escaped := resources.EscapeLike(reqPath)
-			recs, err = r.DB.GetUserResourcesRecursive(ctx, database.GetUserResourcesRecursiveParams{
-				UserID:      uid,
-				DirPath:     reqPath,
-				ChildPrefix: escaped + "/%",
-			})
-		default:
-			escaped := resources.EscapeLike(reqPath)
-			recs, err = r.DB.GetUserResourcesInDir(ctx, database.GetUserResourcesInDirParams{
-				UserID:      uid,
-				DirPath:     reqPath,
-				ChildPrefix: escaped + "/%",
-				DeepPrefix:  escaped + "/%/%",
-			})
+	result := make([]*model.ProviderConfig, 0, len(providers))
+	for _, prv := range providers {
+		var cfg pconfig.ProviderConfig
+
+		if len(prv.Config) == 0 {
+			prv.Config = []byte(pconfig.EmptyProviderConfigRaw)
 		}
-	} else {
-		switch {
-		case reqPath == "" && isRecursive:
-			recs, err = r.DB.GetAllResourcesAll(ctx)
-		case reqPath == "":
-			recs, err = r.DB.GetAllResourcesRoot(ctx)
-		case isRecursive:
-			escaped := resources.EscapeLike(reqPath)
-			recs, err = r.DB.GetAllResourcesRecursive(ctx, database.GetAllResourcesRecursiveParams{
-				DirPath:     reqPath,
-				ChildPrefix: escaped + "/%",
-			})
-		default:
-			escaped := resources.EscapeLike(reqPath)
-			recs, err = r.DB.GetAllResourcesInDir(ctx, database.GetAllResourcesInDirParams{
-				DirPath:     reqPath,
-				ChildPrefix: escaped + "/%",
-				DeepPrefix:  escaped + "/%/%",
-			})
+		if err := json.Unmarshal(prv.Config, &cfg); err != nil {
+			r.Logger.WithError(err).Errorf("failed to unmarshal provider config: %s", prv.Config)
+			continue
 		}
+
+		result = append(result, converter.ConvertProvider(prv, &cfg))
 	}
+
+	return result, nil
+}
+
+// ProjectKnowledgeDocuments is the resolver for the projectKnowledgeDocuments field.
+func (r *queryResolver) ProjectKnowledgeDocuments(ctx context.Context, projectID int64, withContent bool) ([]*model.KnowledgeDocument, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.view")
 	if err != nil {
-		return nil, fmt.Errorf("failed to list resources: %w", err)
+		return nil, err
 	}
 
-	return converter.ConvertUserResources(recs), nil
+	r.Logger.WithFields(logrus.Fields{
+		"uid":          uid,
+		"admin":        admin,
+		"project_id":   projectID,
+		"with_content": withContent,
+	}).Debug("list project knowledge documents")
+
+	if _, _, err := validateProjectMember(ctx, r.DB, projectID, uid, admin); err != nil {
+		return nil, err
+	}
+
+	return r.Knowledge.ListProjectDocuments(ctx, projectID, withContent)
 }
 
 // KnowledgeDocuments is the resolver for the knowledgeDocuments field.
@@ -2568,6 +4058,196 @@ func (r *queryResolver) SearchKnowledge(ctx context.Context, query string, filte
 	return r.Knowledge.SearchUserDocuments(ctx, uid, query, filter, lim)
 }
 
+// KnowledgeImportJobs is the resolver for the knowledgeImportJobs field.
+func (r *queryResolver) KnowledgeImportJobs(ctx context.Context) ([]*model.KnowledgeImportJob, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":   uid,
+		"admin": admin,
+	}).Debug("list knowledge import jobs")
+
+	if admin {
+		return r.Knowledge.ListImportJobs(ctx)
+	}
+	return r.Knowledge.ListUserImportJobs(ctx, uid)
+}
+
+// KnowledgeImportJob is the resolver for the knowledgeImportJob field.
+func (r *queryResolver) KnowledgeImportJob(ctx context.Context, id int64) (*model.KnowledgeImportJob, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":   uid,
+		"admin": admin,
+		"id":    id,
+	}).Debug("get knowledge import job")
+
+	if admin {
+		return r.Knowledge.GetImportJob(ctx, id)
+	}
+	return r.Knowledge.GetUserImportJob(ctx, uid, id)
+}
+
+// EmbeddingStatus is the resolver for the embeddingStatus field.
+func (r *queryResolver) EmbeddingStatus(ctx context.Context) (*model.EmbeddingStatus, error) {
+	uid, _, err := validatePermission(ctx, "knowledge.admin")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid": uid,
+	}).Debug("get embedding status")
+
+	return r.Knowledge.GetEmbeddingStatus(ctx)
+}
+
+// Webhooks is the resolver for the webhooks field.
+func (r *queryResolver) Webhooks(ctx context.Context) ([]*model.Webhook, error) {
+	uid, admin, err := validatePermission(ctx, "webhooks.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":   uid,
+		"admin": admin,
+	}).Debug("get webhooks")
+
+	var hooks []database.Webhook
+	if admin {
+		hooks, err = r.DB.GetWebhooks(ctx)
+	} else {
+		hooks, err = r.DB.GetUserWebhooks(ctx, uid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	return converter.ConvertWebhooks(hooks), nil
+}
+
+// WebhookEvents is the resolver for the webhookEvents field.
+func (r *queryResolver) WebhookEvents(ctx context.Context) ([]string, error) {
+	if _, _, err := validatePermission(ctx, "webhooks.view"); err != nil {
+		return nil, err
+	}
+
+	events := make([]string, 0, len(subscriptions.FlowEventTypes))
+	for _, event := range subscriptions.FlowEventTypes {
+		events = append(events, string(event))
+	}
+
+	return events, nil
+}
+
+// WebhookDeliveries is the resolver for the webhookDeliveries field.
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, webhookID int64, limit *int) ([]*model.WebhookDelivery, error) {
+	uid, admin, err := validatePermission(ctx, "webhooks.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       uid,
+		"admin":     admin,
+		"webhookID": webhookID,
+	}).Debug("get webhook deliveries")
+
+	if _, err := getWebhook(ctx, r.DB, webhookID, uid, admin); err != nil {
+		return nil, err
+	}
+
+	deliveriesLimit := int32(50)
+	if limit != nil && *limit > 0 {
+		deliveriesLimit = int32(min(*limit, 500))
+	}
+
+	deliveries, err := r.DB.GetWebhookDeliveries(ctx, database.GetWebhookDeliveriesParams{
+		WebhookID: webhookID,
+		Limit:     deliveriesLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	return converter.ConvertWebhookDeliveries(deliveries), nil
+}
+
+// BackendInstances is the resolver for the backendInstances field.
+func (r *queryResolver) BackendInstances(ctx context.Context) ([]*model.BackendInstance, error) {
+	uid, _, err := validatePermission(ctx, "instances.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid": uid,
+	}).Debug("get backend instances")
+
+	instances, err := r.DB.GetBackendInstances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backend instances: %w", err)
+	}
+
+	var leader string
+	if row, err := r.DB.GetBackendLeader(ctx); err == nil {
+		leader = row.Owner
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get backend leader: %w", err)
+	}
+
+	return converter.ConvertBackendInstances(instances, leader), nil
+}
+
+// FlowLeases is the resolver for the flowLeases field.
+func (r *queryResolver) FlowLeases(ctx context.Context) ([]*model.FlowLease, error) {
+	uid, _, err := validatePermission(ctx, "instances.view")
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid": uid,
+	}).Debug("get flow leases")
+
+	leases, err := r.DB.GetFlowLeases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow leases: %w", err)
+	}
+
+	return converter.ConvertFlowLeases(leases, time.Now()), nil
+}
+
+// Search is the resolver for the search field.
+func (r *queryResolver) Search(ctx context.Context, query string, kinds []model.SearchResultKind, limit *int, offset *int) ([]*model.SearchResult, error) {
+	params, err := newSearchParams(ctx, query, kinds, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.WithFields(logrus.Fields{
+		"uid":       params.UserID,
+		"query":     params.Search[:min(len(params.Search), 200)],
+		"all_kinds": params.AllKinds,
+		"own_kinds": params.OwnKinds,
+	}).Debug("search flows")
+
+	rows, err := r.DB.SearchFlows(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search flows: %w", err)
+	}
+
+	return converter.ConvertSearchResults(rows), nil
+}
+
 // FlowCreated is the resolver for the flowCreated field.
 func (r *subscriptionResolver) FlowCreated(ctx context.Context) (<-chan *model.Flow, error) {
 	uid, admin, err := validatePermission(ctx, "flows.subscribe")
@@ -2575,12 +4255,18 @@ func (r *subscriptionResolver) FlowCreated(ctx context.Context) (<-chan *model.F
 		return nil, err
 	}
 
+	var events <-chan *model.Flow
 	subscriber := r.Subscriptions.NewFlowSubscriber(uid, 0)
 	if admin {
-		return subscriber.FlowCreatedAdmin(ctx)
+		events, err = subscriber.FlowCreatedAdmin(ctx)
+	} else {
+		events, err = subscriber.FlowCreated(ctx)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return subscriber.FlowCreated(ctx)
+	return filterFlowEventsByTokenScope(ctx, events), nil
 }
 
 // FlowDeleted is the resolver for the flowDeleted field.
@@ -2590,12 +4276,18 @@ func (r *subscriptionResolver) FlowDeleted(ctx context.Context) (<-chan *model.F
 		return nil, err
 	}
 
+	var events <-chan *model.Flow
 	subscriber := r.Subscriptions.NewFlowSubscriber(uid, 0)
 	if admin {
-		return subscriber.FlowDeletedAdmin(ctx)
+		events, err = subscriber.FlowDeletedAdmin(ctx)
+	} else {
+		events, err = subscriber.FlowDeleted(ctx)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return subscriber.FlowDeleted(ctx)
+	return filterFlowEventsByTokenScope(ctx, events), nil
 }
 
 // FlowUpdated is the resolver for the flowUpdated field.
@@ -2605,12 +4297,18 @@ func (r *subscriptionResolver) FlowUpdated(ctx context.Context) (<-chan *model.F
 		return nil, err
 	}
 
+	var events <-chan *model.Flow
 	subscriber := r.Subscriptions.NewFlowSubscriber(uid, 0)
 	if admin {
-		return subscriber.FlowUpdatedAdmin(ctx)
+		events, err = subscriber.FlowUpdatedAdmin(ctx)
+	} else {
+		events, err = subscriber.FlowUpdated(ctx)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return subscriber.FlowUpdated(ctx)
+	return filterFlowEventsByTokenScope(ctx, events), nil
 }
 
 // TaskCreated is the resolver for the taskCreated field.
@@ -3050,6 +4748,20 @@ func (r *subscriptionResolver) KnowledgeDocumentDeleted(ctx context.Context) (<-
 	return sub.KnowledgeDocumentDeleted(ctx)
 }
 
+// KnowledgeImportJobUpdated is the resolver for the knowledgeImportJobUpdated field.
+func (r *subscriptionResolver) KnowledgeImportJobUpdated(ctx context.Context) (<-chan *model.KnowledgeImportJob, error) {
+	uid, admin, err := validatePermission(ctx, "knowledge.subscribe")
+	if err != nil {
+		return nil, err
+	}
+
+	sub := r.Subscriptions.NewKnowledgeSubscriber(uid)
+	if admin {
+		return sub.KnowledgeImportJobUpdatedAdmin(ctx)
+	}
+	return sub.KnowledgeImportJobUpdated(ctx)
+}
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
@@ -2,12 +2,15 @@ package subscriptions
 
 import (
 	"context"
+	"slices"
 	"sync"
 	"time"
 
 	"pentagi/pkg/database"
 	"pentagi/pkg/graph/model"
 	"pentagi/pkg/providers/pconfig"
+
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -32,6 +35,71 @@ type SubscriptionsController interface {
 	NewKnowledgePublisher(userID int64) KnowledgePublisher
 }
 
+// ProjectMembers looks up the members of a project, the flow events of a flow
+// shared through the project are published to them as well as to its owner.
+type ProjectMembers interface {
+	GetProjectMemberUserIDs(ctx context.Context, projectID int64) ([]int64, error)
+}
+
+// FlowEventType names a flow event delivered to the sink
+type FlowEventType string
+
+const (
+	FlowEventFlowCreated         FlowEventType = "flow.created"
+	FlowEventFlowUpdated         FlowEventType = "flow.updated"
+	FlowEventFlowDeleted         FlowEventType = "flow.deleted"
+	FlowEventTaskCreated         FlowEventType = "task.created"
+	FlowEventTaskUpdated         FlowEventType = "task.updated"
+	FlowEventAssistantCreated    FlowEventType = "assistant.created"
+	FlowEventAssistantUpdated    FlowEventType = "assistant.updated"
+	FlowEventAssistantDeleted    FlowEventType = "assistant.deleted"
+	FlowEventFlowFileAdded       FlowEventType = "flow_file.added"
+	FlowEventFlowFileUpdated     FlowEventType = "flow_file.updated"
+	FlowEventFlowFileDeleted     FlowEventType = "flow_file.deleted"
+	FlowEventScreenshotAdded     FlowEventType = "screenshot.added"
+	FlowEventTerminalLogAdded    FlowEventType = "terminal_log.added"
+	FlowEventMessageLogAdded     FlowEventType = "message_log.added"
+	FlowEventMessageLogUpdated   FlowEventType = "message_log.updated"
+	FlowEventAgentLogAdded       FlowEventType = "agent_log.added"
+	FlowEventSearchLogAdded      FlowEventType = "search_log.added"
+	FlowEventVectorStoreLogAdded FlowEventType = "vector_store_log.added"
+	FlowEventToolCallLogAdded    FlowEventType = "tool_call_log.added"
+	FlowEventToolCallLogUpdated  FlowEventType = "tool_call_log.updated"
+	FlowEventAssistantLogAdded   FlowEventType = "assistant_log.added"
+	FlowEventAssistantLogUpdated FlowEventType = "assistant_log.updated"
+)
+
+// FlowEventTypes lists every flow event in the order of the flow lifecycle
+var FlowEventTypes = []FlowEventType{
+	FlowEventFlowCreated, FlowEventFlowUpdated, FlowEventFlowDeleted,
+	FlowEventTaskCreated, FlowEventTaskUpdated,
+	FlowEventAssistantCreated, FlowEventAssistantUpdated, FlowEventAssistantDeleted,
+	FlowEventFlowFileAdded, FlowEventFlowFileUpdated, FlowEventFlowFileDeleted,
+	FlowEventScreenshotAdded,
+	FlowEventTerminalLogAdded,
+	FlowEventMessageLogAdded, FlowEventMessageLogUpdated,
+	FlowEventAgentLogAdded,
+	FlowEventSearchLogAdded,
+	FlowEventVectorStoreLogAdded,
+	FlowEventToolCallLogAdded, FlowEventToolCallLogUpdated,
+	FlowEventAssistantLogAdded, FlowEventAssistantLogUpdated,
+}
+
+// FlowEvent carries the same GraphQL model the flow subscribers receive, UserID
+// is the owner of the flow
+type FlowEvent struct {
+	Type   FlowEventType
+	UserID int64
+	FlowID int64
+	Data   any
+}
+
+// FlowEventSink receives every flow event besides the subscribers, e.g. to send
+// it to webhooks; it's called by the publisher and must not block it.
+type FlowEventSink interface {
+	HandleFlowEvent(ctx context.Context, event FlowEvent)
+}
+
 type UserContext interface {
 	GetUserID() int64
 	SetUserID(userID int64)
@@ -112,6 +180,9 @@ type FlowPublisher interface {
 	FlowCreated(ctx context.Context, flow database.Flow, terms []database.Container)
 	FlowDeleted(ctx context.Context, flow database.Flow, terms []database.Container)
 	FlowUpdated(ctx context.Context, flow database.Flow, terms []database.Container)
+	// FlowUnshared tells the members of the project the flow was shared through
+	// and who can't see it any longer that the flow is gone for them.
+	FlowUnshared(ctx context.Context, flow database.Flow, terms []database.Container, projectID int64)
 	TaskCreated(ctx context.Context, task database.Task, subtasks []database.Subtask)
 	TaskUpdated(ctx context.Context, task database.Task, subtasks []database.Subtask)
 	AssistantCreated(ctx context.Context, assistant database.Assistant)
@@ -146,6 +217,8 @@ type KnowledgeSubscriber interface {
 	KnowledgeDocumentCreatedAdmin(ctx context.Context) (<-chan *model.KnowledgeDocument, error)
 	KnowledgeDocumentUpdatedAdmin(ctx context.Context) (<-chan *model.KnowledgeDocument, error)
 	KnowledgeDocumentDeletedAdmin(ctx context.Context) (<-chan *model.KnowledgeDocument, error)
+	KnowledgeImportJobUpdated(ctx context.Context) (<-chan *model.KnowledgeImportJob, error)
+	KnowledgeImportJobUpdatedAdmin(ctx context.Context) (<-chan *model.KnowledgeImportJob, error)
 	UserContext
 }
 
@@ -155,6 +228,7 @@ type KnowledgePublisher interface {
 	KnowledgeDocumentCreated(ctx context.Context, doc *model.KnowledgeDocument)
 	KnowledgeDocumentUpdated(ctx context.Context, doc *model.KnowledgeDocument)
 	KnowledgeDocumentDeleted(ctx context.Context, doc *model.KnowledgeDocument)
+	KnowledgeImportJobUpdated(ctx context.Context, job *model.KnowledgeImportJob)
 	UserContext
 }
 
@@ -245,64 +319,118 @@ type controller struct {
 	knowledgeDocumentCreatedAdmin Channel[*model.KnowledgeDocument]
 	knowledgeDocumentUpdatedAdmin Channel[*model.KnowledgeDocument]
 	knowledgeDocumentDeletedAdmin Channel[*model.KnowledgeDocument]
+
+	knowledgeImportJobUpdated      Channel[*model.KnowledgeImportJob]
+	knowledgeImportJobUpdatedAdmin Channel[*model.KnowledgeImportJob]
+
+	members ProjectMembers
+	sink    FlowEventSink
 }
 
-func NewSubscriptionsController() SubscriptionsController {
+// NewSubscriptionsController creates the controller, members may be nil when
+// nobody but the owners of flows listens to their events, sink may be nil
+// when flow events aren't sent anywhere else and transport may be nil when
+// the backend runs as a single replica.
+func NewSubscriptionsController(
+	members ProjectMembers,
+	sink FlowEventSink,
+	transport Transport,
+) SubscriptionsController {
+	b := newBus(transport)
+
 	return &controller{
-		flowCreatedAdmin:    NewChannel[*model.Flow](),
-		flowCreated:         NewChannel[*model.Flow](),
-		flowDeletedAdmin:    NewChannel[*model.Flow](),
-		flowDeleted:         NewChannel[*model.Flow](),
-		flowUpdatedAdmin:    NewChannel[*model.Flow](),
-		flowUpdated:         NewChannel[*model.Flow](),
-		taskCreated:         NewChannel[*model.Task](),
-		taskUpdated:         NewChannel[*model.Task](),
-		assistantCreated:    NewChannel[*model.Assistant](),
-		assistantUpdated:    NewChannel[*model.Assistant](),
-		assistantDeleted:    NewChannel[*model.Assistant](),
-		flowFileAdded:       NewChannel[*model.FlowFile](),
-		flowFileUpdated:     NewChannel[*model.FlowFile](),
-		flowFileDeleted:     NewChannel[*model.FlowFile](),
-		screenshotAdded:     NewChannel[*model.Screenshot](),
-		terminalLogAdded:    NewChannel[*model.TerminalLog](),
-		messageLogAdded:     NewChannel[*model.MessageLog](),
-		messageLogUpdated:   NewChannel[*model.MessageLog](),
-		agentLogAdded:       NewChannel[*model.AgentLog](),
-		searchLogAdded:      NewChannel[*model.SearchLog](),
-		vecStoreLogAdded:    NewChannel[*model.VectorStoreLog](),
-		toolCallLogAdded:    NewChannel[*model.ToolCallLog](),
-		toolCallLogUpdated:  NewChannel[*model.ToolCallLog](),
-		assistantLogAdded:   NewChannel[*model.AssistantLog](),
-		assistantLogUpdated: NewChannel[*model.AssistantLog](),
-
-		providerCreated: NewChannel[*model.ProviderConfig](),
-		providerUpdated: NewChannel[*model.ProviderConfig](),
-		providerDeleted: NewChannel[*model.ProviderConfig](),
-
-		apiTokenCreated: NewChannel[*model.APIToken](),
-		apiTokenUpdated: NewChannel[*model.APIToken](),
-		apiTokenDeleted: NewChannel[*model.APIToken](),
-
-		settingsUserUpdated: NewChannel[*model.UserPreferences](),
-
-		flowTemplateCreated: NewChannel[*model.FlowTemplate](),
-		flowTemplateUpdated: NewChannel[*model.FlowTemplate](),
-		flowTemplateDeleted: NewChannel[*model.FlowTemplate](),
-
-		resourceAdded:        NewChannel[*model.UserResource](),
-		resourceUpdated:      NewChannel[*model.UserResource](),
-		resourceDeleted:      NewChannel[*model.UserResource](),
-		resourceAddedAdmin:   NewChannel[*model.UserResource](),
-		resourceUpdatedAdmin: NewChannel[*model.UserResource](),
-		resourceDeletedAdmin: NewChannel[*model.UserResource](),
-
-		knowledgeDocumentCreated:      NewChannel[*model.KnowledgeDocument](),
-		knowledgeDocumentUpdated:      NewChannel[*model.KnowledgeDocument](),
-		knowledgeDocumentDeleted:      NewChannel[*model.KnowledgeDocument](),
-		knowledgeDocumentCreatedAdmin: NewChannel[*model.KnowledgeDocument](),
-		knowledgeDocumentUpdatedAdmin: NewChannel[*model.KnowledgeDocument](),
-		knowledgeDocumentDeletedAdmin: NewChannel[*model.KnowledgeDocument](),
+		members: members,
+		sink:    sink,
+
+		flowCreatedAdmin:    newBusChannel[*model.Flow](b, "flowCreatedAdmin"),
+		flowCreated:         newBusChannel[*model.Flow](b, "flowCreated"),
+		flowDeletedAdmin:    newBusChannel[*model.Flow](b, "flowDeletedAdmin"),
+		flowDeleted:         newBusChannel[*model.Flow](b, "flowDeleted"),
+		flowUpdatedAdmin:    newBusChannel[*model.Flow](b, "flowUpdatedAdmin"),
+		flowUpdated:         newBusChannel[*model.Flow](b, "flowUpdated"),
+		taskCreated:         newBusChannel[*model.Task](b, "taskCreated"),
+		taskUpdated:         newBusChannel[*model.Task](b, "taskUpdated"),
+		assistantCreated:    newBusChannel[*model.Assistant](b, "assistantCreated"),
+		assistantUpdated:    newBusChannel[*model.Assistant](b, "assistantUpdated"),
+		assistantDeleted:    newBusChannel[*model.Assistant](b, "assistantDeleted"),
+		flowFileAdded:       newBusChannel[*model.FlowFile](b, "flowFileAdded"),
+		flowFileUpdated:     newBusChannel[*model.FlowFile](b, "flowFileUpdated"),
+		flowFileDeleted:     newBusChannel[*model.FlowFile](b, "flowFileDeleted"),
+		screenshotAdded:     newBusChannel[*model.Screenshot](b, "screenshotAdded"),
+		terminalLogAdded:    newBusChannel[*model.TerminalLog](b, "terminalLogAdded"),
+		messageLogAdded:     newBusChannel[*model.MessageLog](b, "messageLogAdded"),
+		messageLogUpdated:   newBusChannel[*model.MessageLog](b, "messageLogUpdated"),
+		agentLogAdded:       newBusChannel[*model.AgentLog](b, "agentLogAdded"),
+		searchLogAdded:      newBusChannel[*model.SearchLog](b, "searchLogAdded"),
+		vecStoreLogAdded:    newBusChannel[*model.VectorStoreLog](b, "vecStoreLogAdded"),
+		toolCallLogAdded:    newBusChannel[*model.ToolCallLog](b, "toolCallLogAdded"),
+		toolCallLogUpdated:  newBusChannel[*model.ToolCallLog](b, "toolCallLogUpdated"),
+		assistantLogAdded:   newBusChannel[*model.AssistantLog](b, "assistantLogAdded"),
+		assistantLogUpdated: newBusChannel[*model.AssistantLog](b, "assistantLogUpdated"),
+
+		providerCreated: newBusChannel[*model.ProviderConfig](b, "providerCreated"),
+		providerUpdated: newBusChannel[*model.ProviderConfig](b, "providerUpdated"),
+		providerDeleted: newBusChannel[*model.ProviderConfig](b, "providerDeleted"),
+
+		apiTokenCreated: newBusChannel[*model.APIToken](b, "apiTokenCreated"),
+		apiTokenUpdated: newBusChannel[*model.APIToken](b, "apiTokenUpdated"),
+		apiTokenDeleted: newBusChannel[*model.APIToken](b, "apiTokenDeleted"),
+
+		settingsUserUpdated: newBusChannel[*model.UserPreferences](b, "settingsUserUpdated"),
+
+		flowTemplateCreated: newBusChannel[*model.FlowTemplate](b, "flowTemplateCreated"),
+		flowTemplateUpdated: newBusChannel[*model.FlowTemplate](b, "flowTemplateUpdated"),
+		flowTemplateDeleted: newBusChannel[*model.FlowTemplate](b, "flowTemplateDeleted"),
+
+		resourceAdded:        newBusChannel[*model.UserResource](b, "resourceAdded"),
+		resourceUpdated:      newBusChannel[*model.UserResource](b, "resourceUpdated"),
+		resourceDeleted:      newBusChannel[*model.UserResource](b, "resourceDeleted"),
+		resourceAddedAdmin:   newBusChannel[*model.UserResource](b, "resourceAddedAdmin"),
+		resourceUpdatedAdmin: newBusChannel[*model.UserResource](b, "resourceUpdatedAdmin"),
+		resourceDeletedAdmin: newBusChannel[*model.UserResource](b, "resourceDeletedAdmin"),
+
+		knowledgeDocumentCreated:      newBusChannel[*model.KnowledgeDocument](b, "knowledgeDocumentCreated"),
+		knowledgeDocumentUpdated:      newBusChannel[*model.KnowledgeDocument](b, "knowledgeDocumentUpdated"),
+		knowledgeDocumentDeleted:      newBusChannel[*model.KnowledgeDocument](b, "knowledgeDocumentDeleted"),
+		knowledgeDocumentCreatedAdmin: newBusChannel[*model.KnowledgeDocument](b, "knowledgeDocumentCreatedAdmin"),
+		knowledgeDocumentUpdatedAdmin: newBusChannel[*model.KnowledgeDocument](b, "knowledgeDocumentUpdatedAdmin"),
+		knowledgeDocumentDeletedAdmin: newBusChannel[*model.KnowledgeDocument](b, "knowledgeDocumentDeletedAdmin"),
+
+		knowledgeImportJobUpdated:      newBusChannel[*model.KnowledgeImportJob](b, "knowledgeImportJobUpdated"),
+		knowledgeImportJobUpdatedAdmin: newBusChannel[*model.KnowledgeImportJob](b, "knowledgeImportJobUpdatedAdmin"),
+	}
+}
+
+// projectMembers returns the members of the project except the given user, a
+// failed lookup only costs the members the event.
+func (s *controller) projectMembers(ctx context.Context, projectID, exceptUserID int64) []int64 {
+	if s.members == nil {
+		return nil
+	}
+
+	userIDs, err := s.members.GetProjectMemberUserIDs(ctx, projectID)
+	if err != nil {
+		logrus.WithError(err).WithField("project_id", projectID).Warn("failed to get project members")
+		return nil
+	}
+
+	return slices.DeleteFunc(userIDs, func(userID int64) bool {
+		return userID == exceptUserID
+	})
+}
+
+// emitFlowEvent passes the flow event to the sink if there is one
+func (s *controller) emitFlowEvent(ctx context.Context, eventType FlowEventType, userID, flowID int64, data any) {
+	if s.sink == nil {
+		return
 	}
+
+	s.sink.HandleFlowEvent(ctx, FlowEvent{
+		Type:   eventType,
+		UserID: userID,
+		FlowID: flowID,
+		Data:   data,
+	})
 }
 
 func (s *controller) NewFlowPublisher(userID, flowID int64) FlowPublisher {
@@ -389,8 +517,10 @@ func NewChannel[T any]() Channel[T] {
 }
 
 type channel[T any] struct {
-	mx   *sync.RWMutex
-	subs map[int64][]chan T
+	mx    *sync.RWMutex
+	subs  map[int64][]chan T
+	topic string
+	bus   *bus
 }
 
 func (c *channel[T]) Subscribe(ctx context.Context, id int64) <-chan T {
@@ -426,6 +556,16 @@ func (c *channel[T]) Subscribe(ctx context.Context, id int64) <-chan T {
 }
 
 func (c *channel[T]) Publish(ctx context.Context, id int64, data T) {
+	c.bus.send(c.topic, id, false, data)
+	c.publish(ctx, id, data)
+}
+
+func (c *channel[T]) Broadcast(ctx context.Context, data T) {
+	c.bus.send(c.topic, 0, true, data)
+	c.broadcast(ctx, data)
+}
+
+func (c *channel[T]) publish(ctx context.Context, id int64, data T) {
 	c.mx.RLock()
 	defer c.mx.RUnlock()
 
@@ -443,7 +583,7 @@ func (c *channel[T]) Publish(ctx context.Context, id int64, data T) {
 	}
 }
 
-func (c *channel[T]) Broadcast(ctx context.Context, data T) {
+func (c *channel[T]) broadcast(ctx context.Context, data T) {
 	c.mx.RLock()
 	defer c.mx.RUnlock()
 
@@ -0,0 +1,119 @@
+package subscriptions
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticMembers map[int64][]int64
+
+func (m staticMembers) GetProjectMemberUserIDs(_ context.Context, projectID int64) ([]int64, error) {
+	return append([]int64(nil), m[projectID]...), nil
+}
+
+func receivedFlows(ch <-chan *model.Flow) []int64 {
+	var ids []int64
+	for {
+		select {
+		case flow := <-ch:
+			ids = append(ids, flow.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestFlowEventsReachProjectMembers(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	ctrl := NewSubscriptionsController(staticMembers{7: {1, 2, 3}, 8: {1, 3}}, nil, nil)
+
+	updated := map[int64]<-chan *model.Flow{}
+	deleted := map[int64]<-chan *model.Flow{}
+	for _, userID := range []int64{1, 2, 3, 4} {
+		sub := ctrl.NewFlowSubscriber(userID, 0)
+		var err error
+		updated[userID], err = sub.FlowUpdated(ctx)
+		require.NoError(t, err)
+		deleted[userID], err = sub.FlowDeleted(ctx)
+		require.NoError(t, err)
+	}
+
+	flow := database.Flow{ID: 10, UserID: 1, ProjectID: sql.NullInt64{Int64: 7, Valid: true}}
+	pub := ctrl.NewFlowPublisher(flow.UserID, flow.ID)
+	pub.FlowUpdated(ctx, flow, nil)
+
+	assert.Equal(t, []int64{10}, receivedFlows(updated[1]), "the owner gets the event once")
+	assert.Equal(t, []int64{10}, receivedFlows(updated[2]))
+	assert.Equal(t, []int64{10}, receivedFlows(updated[3]))
+	assert.Empty(t, receivedFlows(updated[4]), "users out of the project get nothing")
+
+	// moving the flow to another project removes it for the members left behind
+	flow.ProjectID = sql.NullInt64{Int64: 8, Valid: true}
+	pub.FlowUnshared(ctx, flow, nil, 7)
+
+	assert.Empty(t, receivedFlows(deleted[1]), "the owner keeps the flow")
+	assert.Equal(t, []int64{10}, receivedFlows(deleted[2]))
+	assert.Empty(t, receivedFlows(deleted[3]), "members of the new project keep the flow")
+	assert.Empty(t, receivedFlows(deleted[4]))
+}
+
+func TestFlowEventsWithoutProjectMembers(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	ctrl := NewSubscriptionsController(nil, nil, nil)
+
+	ch, err := ctrl.NewFlowSubscriber(1, 0).FlowCreated(ctx)
+	require.NoError(t, err)
+
+	flow := database.Flow{ID: 10, UserID: 1, ProjectID: sql.NullInt64{Int64: 7, Valid: true}}
+	ctrl.NewFlowPublisher(flow.UserID, flow.ID).FlowCreated(ctx, flow, nil)
+
+	assert.Equal(t, []int64{10}, receivedFlows(ch))
+}
+
+type recordingSink struct {
+	events []FlowEvent
+}
+
+func (s *recordingSink) HandleFlowEvent(_ context.Context, event FlowEvent) {
+	s.events = append(s.events, event)
+}
+
+func TestFlowEventsReachSink(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	sink := &recordingSink{}
+	ctrl := NewSubscriptionsController(nil, sink, nil)
+
+	flow := database.Flow{ID: 10, UserID: 1}
+	ctrl.NewFlowPublisher(0, 0).FlowCreated(ctx, flow, nil)
+
+	pub := ctrl.NewFlowPublisher(flow.UserID, flow.ID)
+	pub.TaskCreated(ctx, database.Task{ID: 20, FlowID: flow.ID}, nil)
+	pub.AssistantLogUpdated(ctx, database.Assistantlog{ID: 30, FlowID: flow.ID}, true)
+	pub.AssistantLogUpdated(ctx, database.Assistantlog{ID: 30, FlowID: flow.ID}, false)
+
+	require.Len(t, sink.events, 3, "streamed assistant log chunks are not passed to the sink")
+
+	assert.Equal(t, FlowEventFlowCreated, sink.events[0].Type)
+	assert.Equal(t, int64(1), sink.events[0].UserID, "flow events are owned by the flow owner")
+	assert.Equal(t, int64(10), sink.events[0].FlowID)
+	assert.IsType(t, &model.Flow{}, sink.events[0].Data)
+
+	assert.Equal(t, FlowEventTaskCreated, sink.events[1].Type)
+	assert.Equal(t, int64(10), sink.events[1].FlowID)
+	assert.IsType(t, &model.Task{}, sink.events[1].Data)
+
+	assert.Equal(t, FlowEventAssistantLogUpdated, sink.events[2].Type)
+}
@@ -0,0 +1,166 @@
+package subscriptions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	postgresChannel = "pentagi_subscriptions"
+	// NOTIFY payloads are limited to 8000 bytes, larger events are stored in
+	// the subscription_payloads table and the notification refers to the row
+	maxNotifyPayloadLen = 7900
+	payloadsTTL         = time.Minute
+	listenerPingPeriod  = 90 * time.Second
+)
+
+type notification struct {
+	Message
+	Ref int64 `json:"ref,omitempty"`
+}
+
+type postgresTransport struct {
+	db       database.Querier
+	channel  string
+	listener *pq.Listener
+	logger   *logrus.Entry
+
+	mx        sync.Mutex
+	cleanedAt time.Time
+}
+
+// NewPostgresTransport passes the events through LISTEN/NOTIFY of the backend
+// database, so the replicas need no other infrastructure
+func NewPostgresTransport(cfg *config.Config, db database.Querier) (Transport, error) {
+	logger := logrus.WithField("component", "subscriptions-postgres")
+
+	// the tenants share the database, so the channel is scoped like other names
+	channel := strings.ReplaceAll(cfg.ScopedName(postgresChannel), "-", "_")
+
+	listener := pq.NewListener(cfg.DatabaseURL, time.Second, time.Minute,
+		func(event pq.ListenerEventType, err error) {
+			switch event {
+			case pq.ListenerEventDisconnected:
+				logger.WithError(err).Warn("subscriptions listener disconnected")
+			case pq.ListenerEventReconnected:
+				logger.Info("subscriptions listener reconnected")
+			case pq.ListenerEventConnectionAttemptFailed:
+				logger.WithError(err).Warn("subscriptions listener failed to connect")
+			}
+		},
+	)
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen to postgres channel '%s': %w", channel, err)
+	}
+
+	return &postgresTransport{
+		db:       db,
+		channel:  channel,
+		listener: listener,
+		logger:   logger,
+	}, nil
+}
+
+func (t *postgresTransport) Publish(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(notification{Message: msg})
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	if len(payload) > maxNotifyPayloadLen {
+		ref, err := t.db.CreateSubscriptionPayload(ctx, string(msg.Data))
+		if err != nil {
+			return fmt.Errorf("failed to store subscription payload: %w", err)
+		}
+
+		msg.Data = nil
+		if payload, err = json.Marshal(notification{Message: msg, Ref: ref}); err != nil {
+			return fmt.Errorf("failed to encode notification: %w", err)
+		}
+
+		t.cleanup(ctx)
+	}
+
+	return t.db.NotifySubscriptions(ctx, database.NotifySubscriptionsParams{
+		Channel: t.channel,
+		Payload: string(payload),
+	})
+}
+
+func (t *postgresTransport) Listen(ctx context.Context, handle func(Message)) error {
+	ticker := time.NewTicker(listenerPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			// a broken connection is only noticed on the next query
+			go t.listener.Ping()
+		case n, ok := <-t.listener.Notify:
+			if !ok {
+				return nil
+			}
+			if n == nil {
+				// the listener reconnected, events sent meanwhile are lost
+				t.logger.Warn("subscription events may have been lost while reconnecting")
+				continue
+			}
+
+			msg, err := t.decode(ctx, n.Extra)
+			if err != nil {
+				t.logger.WithError(err).Error("failed to decode notification")
+				continue
+			}
+			handle(msg)
+		}
+	}
+}
+
+func (t *postgresTransport) Close() error {
+	return t.listener.Close()
+}
+
+func (t *postgresTransport) decode(ctx context.Context, payload string) (Message, error) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return Message{}, err
+	}
+
+	if n.Ref != 0 {
+		data, err := t.db.GetSubscriptionPayload(ctx, n.Ref)
+		if err != nil {
+			return Message{}, fmt.Errorf("failed to get subscription payload %d: %w", n.Ref, err)
+		}
+		n.Data = json.RawMessage(data)
+	}
+
+	return n.Message, nil
+}
+
+// cleanup drops the stored payloads every replica has fetched already
+func (t *postgresTransport) cleanup(ctx context.Context) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if time.Since(t.cleanedAt) < payloadsTTL {
+		return
+	}
+	t.cleanedAt = time.Now()
+
+	if err := t.db.DeleteSubscriptionPayloads(ctx, time.Now().Add(-payloadsTTL)); err != nil {
+		t.logger.WithError(err).Warn("failed to delete old subscription payloads")
+	}
+}
@@ -2,6 +2,7 @@ package subscriptions
 
 import (
 	"context"
+	"slices"
 
 	"pentagi/pkg/database"
 	"pentagi/pkg/database/converter"
@@ -33,96 +34,174 @@ func (p *flowPublisher) SetUserID(userID int64) {
 
 func (p *flowPublisher) FlowCreated(ctx context.Context, flow database.Flow, terms []database.Container) {
 	flowModel := converter.ConvertFlow(flow, terms)
-	p.ctrl.flowCreated.Publish(ctx, p.userID, flowModel)
+	p.publishFlow(ctx, p.ctrl.flowCreated, flow, flowModel)
 	p.ctrl.flowCreatedAdmin.Broadcast(ctx, flowModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventFlowCreated, flow.UserID, flow.ID, flowModel)
 }
 
 func (p *flowPublisher) FlowDeleted(ctx context.Context, flow database.Flow, terms []database.Container) {
 	flowModel := converter.ConvertFlow(flow, terms)
-	p.ctrl.flowDeleted.Publish(ctx, p.userID, flowModel)
+	p.publishFlow(ctx, p.ctrl.flowDeleted, flow, flowModel)
 	p.ctrl.flowDeletedAdmin.Broadcast(ctx, flowModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventFlowDeleted, flow.UserID, flow.ID, flowModel)
 }
 
 func (p *flowPublisher) FlowUpdated(ctx context.Context, flow database.Flow, terms []database.Container) {
 	flowModel := converter.ConvertFlow(flow, terms)
-	p.ctrl.flowUpdated.Publish(ctx, p.userID, flowModel)
+	p.publishFlow(ctx, p.ctrl.flowUpdated, flow, flowModel)
 	p.ctrl.flowUpdatedAdmin.Broadcast(ctx, flowModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventFlowUpdated, flow.UserID, flow.ID, flowModel)
+}
+
+func (p *flowPublisher) FlowUnshared(
+	ctx context.Context,
+	flow database.Flow,
+	terms []database.Container,
+	projectID int64,
+) {
+	var stillShared []int64
+	if flow.ProjectID.Valid {
+		stillShared = p.ctrl.projectMembers(ctx, flow.ProjectID.Int64, p.userID)
+	}
+
+	flowModel := converter.ConvertFlow(flow, terms)
+	for _, userID := range p.ctrl.projectMembers(ctx, projectID, p.userID) {
+		if !slices.Contains(stillShared, userID) {
+			p.ctrl.flowDeleted.Publish(ctx, userID, flowModel)
+		}
+	}
+}
+
+// publishFlow sends the flow event to the owner and to the members of the
+// project the flow is shared through
+func (p *flowPublisher) publishFlow(
+	ctx context.Context,
+	ch Channel[*model.Flow],
+	flow database.Flow,
+	flowModel *model.Flow,
+) {
+	ch.Publish(ctx, p.userID, flowModel)
+	if !flow.ProjectID.Valid {
+		return
+	}
+
+	for _, userID := range p.ctrl.projectMembers(ctx, flow.ProjectID.Int64, p.userID) {
+		ch.Publish(ctx, userID, flowModel)
+	}
 }
 
 func (p *flowPublisher) TaskCreated(ctx context.Context, task database.Task, subtasks []database.Subtask) {
-	p.ctrl.taskCreated.Publish(ctx, p.flowID, converter.ConvertTask(task, subtasks))
+	taskModel := converter.ConvertTask(task, subtasks)
+	p.ctrl.taskCreated.Publish(ctx, p.flowID, taskModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventTaskCreated, p.userID, p.flowID, taskModel)
 }
 
 func (p *flowPublisher) TaskUpdated(ctx context.Context, task database.Task, subtasks []database.Subtask) {
-	p.ctrl.taskUpdated.Publish(ctx, p.flowID, converter.ConvertTask(task, subtasks))
+	taskModel := converter.ConvertTask(task, subtasks)
+	p.ctrl.taskUpdated.Publish(ctx, p.flowID, taskModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventTaskUpdated, p.userID, p.flowID, taskModel)
 }
 
 func (p *flowPublisher) AssistantCreated(ctx context.Context, assistant database.Assistant) {
-	p.ctrl.assistantCreated.Publish(ctx, p.flowID, converter.ConvertAssistant(assistant))
+	assistantModel := converter.ConvertAssistant(assistant)
+	p.ctrl.assistantCreated.Publish(ctx, p.flowID, assistantModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventAssistantCreated, p.userID, p.flowID, assistantModel)
 }
 
 func (p *flowPublisher) AssistantUpdated(ctx context.Context, assistant database.Assistant) {
-	p.ctrl.assistantUpdated.Publish(ctx, p.flowID, converter.ConvertAssistant(assistant))
+	assistantModel := converter.ConvertAssistant(assistant)
+	p.ctrl.assistantUpdated.Publish(ctx, p.flowID, assistantModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventAssistantUpdated, p.userID, p.flowID, assistantModel)
 }
 
 func (p *flowPublisher) AssistantDeleted(ctx context.Context, assistant database.Assistant) {
-	p.ctrl.assistantDeleted.Publish(ctx, p.flowID, converter.ConvertAssistant(assistant))
+	assistantModel := converter.ConvertAssistant(assistant)
+	p.ctrl.assistantDeleted.Publish(ctx, p.flowID, assistantModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventAssistantDeleted, p.userID, p.flowID, assistantModel)
 }
 
 func (p *flowPublisher) FlowFileAdded(ctx context.Context, file *model.FlowFile) {
 	p.ctrl.flowFileAdded.Publish(ctx, p.flowID, file)
+	p.ctrl.emitFlowEvent(ctx, FlowEventFlowFileAdded, p.userID, p.flowID, file)
 }
 
 func (p *flowPublisher) FlowFileUpdated(ctx context.Context, file *model.FlowFile) {
 	p.ctrl.flowFileUpdated.Publish(ctx, p.flowID, file)
+	p.ctrl.emitFlowEvent(ctx, FlowEventFlowFileUpdated, p.userID, p.flowID, file)
 }
 
 func (p *flowPublisher) FlowFileDeleted(ctx context.Context, file *model.FlowFile) {
 	p.ctrl.flowFileDeleted.Publish(ctx, p.flowID, file)
+	p.ctrl.emitFlowEvent(ctx, FlowEventFlowFileDeleted, p.userID, p.flowID, file)
 }
 
 func (p *flowPublisher) ScreenshotAdded(ctx context.Context, screenshot database.Screenshot) {
-	p.ctrl.screenshotAdded.Publish(ctx, p.flowID, converter.ConvertScreenshot(screenshot))
+	screenshotModel := converter.ConvertScreenshot(screenshot)
+	p.ctrl.screenshotAdded.Publish(ctx, p.flowID, screenshotModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventScreenshotAdded, p.userID, p.flowID, screenshotModel)
 }
 
 func (p *flowPublisher) TerminalLogAdded(ctx context.Context, terminalLog database.Termlog) {
-	p.ctrl.terminalLogAdded.Publish(ctx, p.flowID, converter.ConvertTerminalLog(terminalLog))
+	logModel := converter.ConvertTerminalLog(terminalLog)
+	p.ctrl.terminalLogAdded.Publish(ctx, p.flowID, logModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventTerminalLogAdded, p.userID, p.flowID, logModel)
 }
 
 func (p *flowPublisher) MessageLogAdded(ctx context.Context, messageLog database.Msglog) {
-	p.ctrl.messageLogAdded.Publish(ctx, p.flowID, converter.ConvertMessageLog(messageLog))
+	logModel := converter.ConvertMessageLog(messageLog)
+	p.ctrl.messageLogAdded.Publish(ctx, p.flowID, logModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventMessageLogAdded, p.userID, p.flowID, logModel)
 }
 
 func (p *flowPublisher) MessageLogUpdated(ctx context.Context, messageLog database.Msglog) {
-	p.ctrl.messageLogUpdated.Publish(ctx, p.flowID, converter.ConvertMessageLog(messageLog))
+	logModel := converter.ConvertMessageLog(messageLog)
+	p.ctrl.messageLogUpdated.Publish(ctx, p.flowID, logModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventMessageLogUpdated, p.userID, p.flowID, logModel)
 }
 
 func (p *flowPublisher) AgentLogAdded(ctx context.Context, agentLog database.Agentlog) {
-	p.ctrl.agentLogAdded.Publish(ctx, p.flowID, converter.ConvertAgentLog(agentLog))
+	logModel := converter.ConvertAgentLog(agentLog)
+	p.ctrl.agentLogAdded.Publish(ctx, p.flowID, logModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventAgentLogAdded, p.userID, p.flowID, logModel)
 }
 
 func (p *flowPublisher) SearchLogAdded(ctx context.Context, searchLog database.Searchlog) {
-	p.ctrl.searchLogAdded.Publish(ctx, p.flowID, converter.ConvertSearchLog(searchLog))
+	logModel := converter.ConvertSearchLog(searchLog)
+	p.ctrl.searchLogAdded.Publish(ctx, p.flowID, logModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventSearchLogAdded, p.userID, p.flowID, logModel)
 }
 
 func (p *flowPublisher) VectorStoreLogAdded(ctx context.Context, vectorStoreLog database.Vecstorelog) {
-	p.ctrl.vecStoreLogAdded.Publish(ctx, p.flowID, converter.ConvertVectorStoreLog(vectorStoreLog))
+	logModel := converter.ConvertVectorStoreLog(vectorStoreLog)
+	p.ctrl.vecStoreLogAdded.Publish(ctx, p.flowID, logModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventVectorStoreLogAdded, p.userID, p.flowID, logModel)
 }
 
 func (p *flowPublisher) ToolCallLogAdded(ctx context.Context, toolCallLog database.Toolcall) {
-	p.ctrl.toolCallLogAdded.Publish(ctx, p.flowID, converter.ConvertToolCallLog(toolCallLog))
+	logModel := converter.ConvertToolCallLog(toolCallLog)
+	p.ctrl.toolCallLogAdded.Publish(ctx, p.flowID, logModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventToolCallLogAdded, p.userID, p.flowID, logModel)
 }
 
 func (p *flowPublisher) ToolCallLogUpdated(ctx context.Context, toolCallLog database.Toolcall) {
-	p.ctrl.toolCallLogUpdated.Publish(ctx, p.flowID, converter.ConvertToolCallLog(toolCallLog))
+	logModel := converter.ConvertToolCallLog(toolCallLog)
+	p.ctrl.toolCallLogUpdated.Publish(ctx, p.flowID, logModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventToolCallLogUpdated, p.userID, p.flowID, logModel)
 }
 
 func (p *flowPublisher) AssistantLogAdded(ctx context.Context, assistantLog database.Assistantlog) {
-	p.ctrl.assistantLogAdded.Publish(ctx, p.flowID, converter.ConvertAssistantLog(assistantLog, false))
+	logModel := converter.ConvertAssistantLog(assistantLog, false)
+	p.ctrl.assistantLogAdded.Publish(ctx, p.flowID, logModel)
+	p.ctrl.emitFlowEvent(ctx, FlowEventAssistantLogAdded, p.userID, p.flowID, logModel)
 }
 
 func (p *flowPublisher) AssistantLogUpdated(ctx context.Context, assistantLog database.Assistantlog, appendPart bool) {
-	p.ctrl.assistantLogUpdated.Publish(ctx, p.flowID, converter.ConvertAssistantLog(assistantLog, appendPart))
+	logModel := converter.ConvertAssistantLog(assistantLog, appendPart)
+	p.ctrl.assistantLogUpdated.Publish(ctx, p.flowID, logModel)
+	// streamed chunks only make sense to the UI, the sink gets the complete message
+	if !appendPart {
+		p.ctrl.emitFlowEvent(ctx, FlowEventAssistantLogUpdated, p.userID, p.flowID, logModel)
+	}
 }
 
 func (p *flowPublisher) KnowledgeDocumentCreated(ctx context.Context, doc *model.KnowledgeDocument) {
@@ -283,3 +362,8 @@ func (p *knowledgePublisher) KnowledgeDocumentDeleted(ctx context.Context, doc *
 	p.ctrl.knowledgeDocumentDeleted.Publish(ctx, p.userID, doc)
 	p.ctrl.knowledgeDocumentDeletedAdmin.Broadcast(ctx, doc)
 }
+
+func (p *knowledgePublisher) KnowledgeImportJobUpdated(ctx context.Context, job *model.KnowledgeImportJob) {
+	p.ctrl.knowledgeImportJobUpdated.Publish(ctx, p.userID, job)
+	p.ctrl.knowledgeImportJobUpdatedAdmin.Broadcast(ctx, job)
+}
@@ -0,0 +1,95 @@
+package subscriptions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"pentagi/pkg/config"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	redisChannel        = "pentagi-subscriptions"
+	redisConnectTimeout = 10 * time.Second
+)
+
+type redisTransport struct {
+	client  *redis.Client
+	pubsub  *redis.PubSub
+	channel string
+	logger  *logrus.Entry
+}
+
+// NewRedisTransport passes the events through Redis pub/sub, it takes the load
+// of the frequent log events off the database
+func NewRedisTransport(cfg *config.Config) (Transport, error) {
+	if cfg.SubscriptionsRedisURL == "" {
+		return nil, fmt.Errorf("SUBSCRIPTIONS_REDIS_URL is required for the redis subscriptions transport")
+	}
+
+	opts, err := redis.ParseURL(cfg.SubscriptionsRedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscriptions redis url: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+
+	client := redis.NewClient(opts)
+	channel := cfg.ScopedName(redisChannel)
+	pubsub := client.Subscribe(ctx, channel)
+
+	// the first reply confirms the subscription, events published before it are lost
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		client.Close()
+		return nil, fmt.Errorf("failed to subscribe to redis channel '%s': %w", channel, err)
+	}
+
+	return &redisTransport{
+		client:  client,
+		pubsub:  pubsub,
+		channel: channel,
+		logger:  logrus.WithField("component", "subscriptions-redis"),
+	}, nil
+}
+
+func (t *redisTransport) Publish(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	return t.client.Publish(ctx, t.channel, payload).Err()
+}
+
+func (t *redisTransport) Listen(ctx context.Context, handle func(Message)) error {
+	messages := t.pubsub.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			var msg Message
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				t.logger.WithError(err).Error("failed to decode message")
+				continue
+			}
+			handle(msg)
+		}
+	}
+}
+
+func (t *redisTransport) Close() error {
+	return errors.Join(t.pubsub.Close(), t.client.Close())
+}
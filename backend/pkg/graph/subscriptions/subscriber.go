@@ -299,3 +299,11 @@ func (s *knowledgeSubscriber) KnowledgeDocumentUpdatedAdmin(ctx context.Context)
 func (s *knowledgeSubscriber) KnowledgeDocumentDeletedAdmin(ctx context.Context) (<-chan *model.KnowledgeDocument, error) {
 	return s.ctrl.knowledgeDocumentDeletedAdmin.Subscribe(ctx, s.userID), nil
 }
+
+func (s *knowledgeSubscriber) KnowledgeImportJobUpdated(ctx context.Context) (<-chan *model.KnowledgeImportJob, error) {
+	return s.ctrl.knowledgeImportJobUpdated.Subscribe(ctx, s.userID), nil
+}
+
+func (s *knowledgeSubscriber) KnowledgeImportJobUpdatedAdmin(ctx context.Context) (<-chan *model.KnowledgeImportJob, error) {
+	return s.ctrl.knowledgeImportJobUpdatedAdmin.Subscribe(ctx, s.userID), nil
+}
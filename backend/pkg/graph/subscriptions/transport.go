@@ -0,0 +1,194 @@
+package subscriptions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	TransportMemory   = "memory"
+	TransportPostgres = "postgres"
+	TransportRedis    = "redis"
+)
+
+const (
+	defBusQueueLen    = 4096
+	defBusSendTimeout = 5 * time.Second
+)
+
+// Message is a subscription event passed between the backend replicas, Key is
+// the user the event is published to unless it's a broadcast
+type Message struct {
+	Node      string          `json:"node"`
+	Topic     string          `json:"topic"`
+	Key       int64           `json:"key,omitempty"`
+	Broadcast bool            `json:"broadcast,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// Transport carries the subscription events between the backend replicas, so
+// that the WebSocket clients of every replica get the events published on any
+// of them. A replica gets its own messages back and skips them itself.
+type Transport interface {
+	Publish(ctx context.Context, msg Message) error
+	// Listen calls handle for every received message in the order they were
+	// published until the context is done or the transport is closed
+	Listen(ctx context.Context, handle func(Message)) error
+	Close() error
+}
+
+// NewTransport creates the transport chosen by SUBSCRIPTIONS_TRANSPORT, it's nil
+// for the memory transport which keeps the events inside the process
+func NewTransport(cfg *config.Config, db database.Querier) (Transport, error) {
+	switch cfg.SubscriptionsTransport {
+	case "", TransportMemory:
+		return nil, nil
+	case TransportPostgres:
+		return NewPostgresTransport(cfg, db)
+	case TransportRedis:
+		return NewRedisTransport(cfg)
+	default:
+		return nil, fmt.Errorf("unknown subscriptions transport '%s'", cfg.SubscriptionsTransport)
+	}
+}
+
+// bus connects the channels of the controller to the transport: published events
+// are queued and sent by a single goroutine to keep their order, received events
+// are delivered to the local subscribers of the channel with the same topic.
+type bus struct {
+	node      string
+	transport Transport
+	queue     chan Message
+	logger    *logrus.Entry
+
+	mx       sync.RWMutex
+	handlers map[string]func(ctx context.Context, msg Message)
+}
+
+func newBus(transport Transport) *bus {
+	if transport == nil {
+		return nil
+	}
+
+	b := &bus{
+		node:      uuid.New().String(),
+		transport: transport,
+		queue:     make(chan Message, defBusQueueLen),
+		logger:    logrus.WithField("component", "subscriptions-bus"),
+		handlers:  make(map[string]func(ctx context.Context, msg Message)),
+	}
+
+	go b.forward()
+	go b.listen()
+
+	return b
+}
+
+func (b *bus) handle(topic string, handler func(ctx context.Context, msg Message)) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.handlers[topic] = handler
+}
+
+// send queues the event for the other replicas, it never blocks the publisher
+// and drops the event when the transport doesn't keep up
+func (b *bus) send(topic string, key int64, broadcast bool, data any) {
+	if b == nil {
+		return
+	}
+
+	// the data is encoded right away, the publisher may change it afterwards
+	raw, err := json.Marshal(data)
+	if err != nil {
+		b.logger.WithError(err).WithField("topic", topic).Error("failed to encode subscription event")
+		return
+	}
+
+	msg := Message{
+		Node:      b.node,
+		Topic:     topic,
+		Key:       key,
+		Broadcast: broadcast,
+		Data:      raw,
+	}
+
+	select {
+	case b.queue <- msg:
+	default:
+		b.logger.WithField("topic", topic).Warn("subscriptions bus queue is full, event is dropped")
+	}
+}
+
+func (b *bus) forward() {
+	for msg := range b.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), defBusSendTimeout)
+		if err := b.transport.Publish(ctx, msg); err != nil {
+			b.logger.WithError(err).WithField("topic", msg.Topic).Error("failed to publish subscription event")
+		}
+		cancel()
+	}
+}
+
+func (b *bus) listen() {
+	if err := b.transport.Listen(context.Background(), b.dispatch); err != nil {
+		b.logger.WithError(err).Error("subscriptions transport stopped")
+	}
+}
+
+func (b *bus) dispatch(msg Message) {
+	if msg.Node == b.node {
+		return
+	}
+
+	b.mx.RLock()
+	handler, ok := b.handlers[msg.Topic]
+	b.mx.RUnlock()
+
+	if !ok {
+		b.logger.WithField("topic", msg.Topic).Debug("subscription event of unknown topic is skipped")
+		return
+	}
+
+	handler(context.Background(), msg)
+}
+
+// newBusChannel creates a channel which also passes its events to the other
+// replicas through the bus, it's a local channel when the bus is nil
+func newBusChannel[T any](b *bus, topic string) Channel[T] {
+	c := &channel[T]{
+		mx:    &sync.RWMutex{},
+		subs:  make(map[int64][]chan T),
+		topic: topic,
+		bus:   b,
+	}
+
+	if b != nil {
+		b.handle(topic, c.receive)
+	}
+
+	return c
+}
+
+func (c *channel[T]) receive(ctx context.Context, msg Message) {
+	var data T
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		c.bus.logger.WithError(err).WithField("topic", msg.Topic).Error("failed to decode subscription event")
+		return
+	}
+
+	if msg.Broadcast {
+		c.broadcast(ctx, data)
+	} else {
+		c.publish(ctx, msg.Key, data)
+	}
+}
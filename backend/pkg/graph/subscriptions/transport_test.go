@@ -0,0 +1,146 @@
+package subscriptions
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hub stands in for postgres or redis: every message reaches every transport,
+// including the one which published it
+type hub struct {
+	mx    sync.Mutex
+	peers []*hubTransport
+}
+
+type hubTransport struct {
+	hub      *hub
+	messages chan Message
+	done     chan struct{}
+	once     sync.Once
+}
+
+func (h *hub) connect() *hubTransport {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	t := &hubTransport{hub: h, messages: make(chan Message, 100), done: make(chan struct{})}
+	h.peers = append(h.peers, t)
+	return t
+}
+
+func (t *hubTransport) Publish(_ context.Context, msg Message) error {
+	t.hub.mx.Lock()
+	defer t.hub.mx.Unlock()
+
+	for _, peer := range t.hub.peers {
+		peer.messages <- msg
+	}
+	return nil
+}
+
+func (t *hubTransport) Listen(ctx context.Context, handle func(Message)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.done:
+			return nil
+		case msg := <-t.messages:
+			handle(msg)
+		}
+	}
+}
+
+func (t *hubTransport) Close() error {
+	t.once.Do(func() { close(t.done) })
+	return nil
+}
+
+func waitFlows(t *testing.T, ch <-chan *model.Flow, count int) []int64 {
+	t.Helper()
+
+	var ids []int64
+	timeout := time.After(2 * time.Second)
+	for len(ids) < count {
+		select {
+		case flow := <-ch:
+			ids = append(ids, flow.ID)
+		case <-timeout:
+			t.Fatalf("got %d of %d events", len(ids), count)
+		}
+	}
+
+	// give a duplicate the time to arrive
+	time.Sleep(50 * time.Millisecond)
+	return append(ids, receivedFlows(ch)...)
+}
+
+func TestEventsReachOtherReplicas(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	h := &hub{}
+	first, second := h.connect(), h.connect()
+	defer first.Close()
+	defer second.Close()
+
+	sink := &recordingSink{}
+	replica1 := NewSubscriptionsController(nil, sink, first)
+	replica2 := NewSubscriptionsController(nil, nil, second)
+
+	local, err := replica1.NewFlowSubscriber(1, 0).FlowCreated(ctx)
+	require.NoError(t, err)
+	remote, err := replica2.NewFlowSubscriber(1, 0).FlowCreated(ctx)
+	require.NoError(t, err)
+	remoteAdmin, err := replica2.NewFlowSubscriber(2, 0).FlowCreatedAdmin(ctx)
+	require.NoError(t, err)
+	other, err := replica2.NewFlowSubscriber(3, 0).FlowCreated(ctx)
+	require.NoError(t, err)
+
+	flow := database.Flow{ID: 10, UserID: 1, Title: "remote"}
+	replica1.NewFlowPublisher(flow.UserID, flow.ID).FlowCreated(ctx, flow, nil)
+
+	assert.Equal(t, []int64{10}, waitFlows(t, local, 1), "the own echo is skipped")
+	assert.Equal(t, []int64{10}, waitFlows(t, remote, 1))
+	assert.Equal(t, []int64{10}, waitFlows(t, remoteAdmin, 1), "broadcasts reach every subscriber")
+	assert.Empty(t, receivedFlows(other), "events keep their user")
+	assert.Len(t, sink.events, 1, "only the publishing replica passes events to the sink")
+}
+
+func TestEventsKeepDataAcrossReplicas(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	h := &hub{}
+	first, second := h.connect(), h.connect()
+	defer first.Close()
+	defer second.Close()
+
+	replica1 := NewSubscriptionsController(nil, nil, first)
+	replica2 := NewSubscriptionsController(nil, nil, second)
+
+	ch, err := replica2.NewFlowSubscriber(1, 10).TaskUpdated(ctx)
+	require.NoError(t, err)
+
+	task := database.Task{ID: 20, FlowID: 10, Status: database.TaskStatusRunning, Title: "scan", Input: "nmap"}
+	replica1.NewFlowPublisher(1, 10).TaskUpdated(ctx, task, nil)
+
+	select {
+	case got := <-ch:
+		assert.Equal(t, int64(20), got.ID)
+		assert.Equal(t, int64(10), got.FlowID)
+		assert.Equal(t, model.StatusTypeRunning, got.Status)
+		assert.Equal(t, "scan", got.Title)
+		assert.Equal(t, "nmap", got.Input)
+	case <-time.After(2 * time.Second):
+		t.Fatal("task event didn't reach the other replica")
+	}
+}
@@ -3,6 +3,7 @@ package graphiti
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	graphiti "github.com/vxcontrol/graphiti-go-client"
@@ -13,6 +14,7 @@ type (
 	Observation        = graphiti.Observation
 	Message            = graphiti.Message
 	AddMessagesRequest = graphiti.AddMessagesRequest
+	Episode            = graphiti.Episode
 
 	// Search request/response types
 	TemporalSearchRequest            = graphiti.TemporalSearchRequest
@@ -38,9 +40,24 @@ type (
 	TimeWindow      = graphiti.TimeWindow
 )
 
-// Client wraps the Graphiti client with Pentagi-specific functionality
+// backend stores episodes and serves the searches of a knowledge graph
+type backend interface {
+	AddMessages(ctx context.Context, req AddMessagesRequest) error
+	GetEpisodes(ctx context.Context, groupID string, lastN int) ([]Episode, error)
+	TemporalWindowSearch(ctx context.Context, req TemporalSearchRequest) (*TemporalSearchResponse, error)
+	EntityRelationshipsSearch(ctx context.Context, req EntityRelationshipSearchRequest) (*EntityRelationshipSearchResponse, error)
+	DiverseResultsSearch(ctx context.Context, req DiverseSearchRequest) (*DiverseSearchResponse, error)
+	EpisodeContextSearch(ctx context.Context, req EpisodeContextSearchRequest) (*EpisodeContextSearchResponse, error)
+	SuccessfulToolsSearch(ctx context.Context, req SuccessfulToolsSearchRequest) (*SuccessfulToolsSearchResponse, error)
+	RecentContextSearch(ctx context.Context, req RecentContextSearchRequest) (*RecentContextSearchResponse, error)
+	EntityByLabelSearch(ctx context.Context, req EntityByLabelSearchRequest) (*EntityByLabelSearchResponse, error)
+}
+
+// Client wraps the Graphiti client with Pentagi-specific functionality.
+// It is backed either by a remote Graphiti service or by the built-in
+// Postgres knowledge graph.
 type Client struct {
-	client  *graphiti.Client
+	backend backend
 	enabled bool
 	timeout time.Duration
 }
@@ -74,7 +91,7 @@ func NewClient(url string, timeout time.Duration, enabled bool) (*Client, error)
 	}
 
 	return &Client{
-		client:  client,
+		backend: remoteBackend{client: client},
 		enabled: true,
 		timeout: timeout,
 	}, nil
@@ -94,13 +111,20 @@ func (c *Client) GetTimeout() time.Duration {
 }
 
 // AddMessages adds messages to Graphiti (no-op if disabled)
-func (c *Client) AddMessages(ctx context.Context, req graphiti.AddMessagesRequest) error {
+func (c *Client) AddMessages(ctx context.Context, req AddMessagesRequest) error {
 	if !c.IsEnabled() {
 		return nil
 	}
 
-	_, err := c.client.AddMessages(req)
-	return err
+	return c.backend.AddMessages(ctx, req)
+}
+
+// GetEpisodes returns the last lastN episodes of a group, oldest first
+func (c *Client) GetEpisodes(ctx context.Context, groupID string, lastN int) ([]Episode, error) {
+	if !c.IsEnabled() {
+		return nil, fmt.Errorf("graphiti is not enabled")
+	}
+	return c.backend.GetEpisodes(ctx, groupID, lastN)
 }
 
 // TemporalWindowSearch searches within a time window
@@ -108,7 +132,7 @@ func (c *Client) TemporalWindowSearch(ctx context.Context, req TemporalSearchReq
 	if !c.IsEnabled() {
 		return nil, fmt.Errorf("graphiti is not enabled")
 	}
-	return c.client.TemporalWindowSearch(req)
+	return c.backend.TemporalWindowSearch(ctx, req)
 }
 
 // EntityRelationshipsSearch finds relationships from a center node
@@ -116,7 +140,7 @@ func (c *Client) EntityRelationshipsSearch(ctx context.Context, req EntityRelati
 	if !c.IsEnabled() {
 		return nil, fmt.Errorf("graphiti is not enabled")
 	}
-	return c.client.EntityRelationshipsSearch(req)
+	return c.backend.EntityRelationshipsSearch(ctx, req)
 }
 
 // DiverseResultsSearch gets diverse, non-redundant results
@@ -124,7 +148,7 @@ func (c *Client) DiverseResultsSearch(ctx context.Context, req DiverseSearchRequ
 	if !c.IsEnabled() {
 		return nil, fmt.Errorf("graphiti is not enabled")
 	}
-	return c.client.DiverseResultsSearch(req)
+	return c.backend.DiverseResultsSearch(ctx, req)
 }
 
 // EpisodeContextSearch searches through agent responses and tool execution records
@@ -132,7 +156,7 @@ func (c *Client) EpisodeContextSearch(ctx context.Context, req EpisodeContextSea
 	if !c.IsEnabled() {
 		return nil, fmt.Errorf("graphiti is not enabled")
 	}
-	return c.client.EpisodeContextSearch(req)
+	return c.backend.EpisodeContextSearch(ctx, req)
 }
 
 // SuccessfulToolsSearch finds successful tool executions and attack patterns
@@ -140,7 +164,7 @@ func (c *Client) SuccessfulToolsSearch(ctx context.Context, req SuccessfulToolsS
 	if !c.IsEnabled() {
 		return nil, fmt.Errorf("graphiti is not enabled")
 	}
-	return c.client.SuccessfulToolsSearch(req)
+	return c.backend.SuccessfulToolsSearch(ctx, req)
 }
 
 // RecentContextSearch retrieves recent relevant context
@@ -148,7 +172,7 @@ func (c *Client) RecentContextSearch(ctx context.Context, req RecentContextSearc
 	if !c.IsEnabled() {
 		return nil, fmt.Errorf("graphiti is not enabled")
 	}
-	return c.client.RecentContextSearch(req)
+	return c.backend.RecentContextSearch(ctx, req)
 }
 
 // EntityByLabelSearch searches for entities by label/type
@@ -156,5 +180,54 @@ func (c *Client) EntityByLabelSearch(ctx context.Context, req EntityByLabelSearc
 	if !c.IsEnabled() {
 		return nil, fmt.Errorf("graphiti is not enabled")
 	}
-	return c.client.EntityByLabelSearch(req)
+	return c.backend.EntityByLabelSearch(ctx, req)
+}
+
+// remoteBackend adapts the Graphiti service client, which does not take contexts
+type remoteBackend struct {
+	client *graphiti.Client
+}
+
+func (b remoteBackend) AddMessages(_ context.Context, req AddMessagesRequest) error {
+	_, err := b.client.AddMessages(req)
+	return err
+}
+
+func (b remoteBackend) GetEpisodes(_ context.Context, groupID string, lastN int) ([]Episode, error) {
+	episodes, err := b.client.GetEpisodes(groupID, lastN)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(episodes, func(a, b Episode) int {
+		return a.ValidAt.Compare(b.ValidAt)
+	})
+	return episodes, nil
+}
+
+func (b remoteBackend) TemporalWindowSearch(_ context.Context, req TemporalSearchRequest) (*TemporalSearchResponse, error) {
+	return b.client.TemporalWindowSearch(req)
+}
+
+func (b remoteBackend) EntityRelationshipsSearch(_ context.Context, req EntityRelationshipSearchRequest) (*EntityRelationshipSearchResponse, error) {
+	return b.client.EntityRelationshipsSearch(req)
+}
+
+func (b remoteBackend) DiverseResultsSearch(_ context.Context, req DiverseSearchRequest) (*DiverseSearchResponse, error) {
+	return b.client.DiverseResultsSearch(req)
+}
+
+func (b remoteBackend) EpisodeContextSearch(_ context.Context, req EpisodeContextSearchRequest) (*EpisodeContextSearchResponse, error) {
+	return b.client.EpisodeContextSearch(req)
+}
+
+func (b remoteBackend) SuccessfulToolsSearch(_ context.Context, req SuccessfulToolsSearchRequest) (*SuccessfulToolsSearchResponse, error) {
+	return b.client.SuccessfulToolsSearch(req)
+}
+
+func (b remoteBackend) RecentContextSearch(_ context.Context, req RecentContextSearchRequest) (*RecentContextSearchResponse, error) {
+	return b.client.RecentContextSearch(req)
+}
+
+func (b remoteBackend) EntityByLabelSearch(_ context.Context, req EntityByLabelSearchRequest) (*EntityByLabelSearchResponse, error) {
+	return b.client.EntityByLabelSearch(req)
 }
@@ -0,0 +1,272 @@
+package graphiti
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Entity labels and relation names of the built-in graph, a subset of the
+// taxonomy the pentester and memorist prompts give to agents, so node_labels
+// and edge_types filters work the same with either backend.
+const (
+	LabelHost          = "Host"
+	LabelService       = "Service"
+	LabelCredential    = "Credential"
+	LabelAccount       = "Account"
+	LabelVulnerability = "Vulnerability"
+
+	EdgeRunsService           = "RUNS_SERVICE"
+	EdgeDetectedVulnerability = "DETECTED_VULNERABILITY"
+	EdgeAuthenticatesTo       = "AUTHENTICATES_TO"
+	EdgeYieldedAccess         = "YIELDED_ACCESS"
+)
+
+const (
+	// maxExtractedEntities bounds the work done for a single huge tool output
+	maxExtractedEntities = 200
+	maxSummaryLength     = 200
+)
+
+var (
+	ipv4Regex       = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	urlRegex        = regexp.MustCompile(`\bhttps?://[^\s"'<>()\[\]]+`)
+	nmapReportRegex = regexp.MustCompile(`Nmap scan report for (\S+)(?: \(([0-9.]+)\))?`)
+	openPortRegex   = regexp.MustCompile(`^\s*(\d{1,5})/(tcp|udp)\s+open\s+(\S+)\s*(.*)$`)
+	cveRegex        = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,7}\b`)
+	credentialRegex = regexp.MustCompile(`(?i)\b(?:login|user(?:name)?)\s*[:=]\s*(\S+)\s+pass(?:word)?\s*[:=]\s*\S+`)
+	uidRegex        = regexp.MustCompile(`\buid=(\d+)\(([^)\s]+)\)`)
+	statusRegex     = regexp.MustCompile(`(?m)^Status: (success|failure)\s*$`)
+)
+
+type entityKey struct {
+	label string
+	name  string
+}
+
+type extractedEntity struct {
+	entityKey
+	summary string
+}
+
+type extractedRelation struct {
+	source entityKey
+	target entityKey
+	name   string
+	fact   string
+}
+
+type extraction struct {
+	entities  []extractedEntity
+	relations []extractedRelation
+	index     map[entityKey]int
+	seen      map[extractedRelationKey]struct{}
+}
+
+type extractedRelationKey struct {
+	source, target entityKey
+	name           string
+}
+
+// extractEntities finds engagement entities in an agent response or a tool
+// execution record with plain pattern matching. It is deliberately
+// conservative: hosts are IPv4 addresses, URL hosts and nmap report targets,
+// services come from nmap open port lines, credentials keep only the login,
+// never the secret. Services, vulnerabilities and accounts are attached to
+// the host mentioned last before them.
+func extractEntities(content string) *extraction {
+	ex := &extraction{
+		index: make(map[entityKey]int),
+		seen:  make(map[extractedRelationKey]struct{}),
+	}
+
+	// the zero key means no host or subject was mentioned yet
+	var host, subject entityKey
+	for _, line := range strings.Split(content, "\n") {
+		if len(ex.entities) >= maxExtractedEntities {
+			break
+		}
+
+		if m := nmapReportRegex.FindStringSubmatch(line); m != nil {
+			name, summary := m[1], ""
+			if m[2] != "" {
+				name, summary = m[2], m[1]
+			}
+			host = ex.addEntity(LabelHost, name, summary)
+			subject = host
+		}
+		for _, ip := range ipv4Regex.FindAllString(line, -1) {
+			if isTargetIP(ip) {
+				host = ex.addEntity(LabelHost, ip, "")
+				subject = host
+			}
+		}
+		for _, raw := range urlRegex.FindAllString(line, -1) {
+			if u, err := url.Parse(raw); err == nil && u.Hostname() != "" && !isLoopbackName(u.Hostname()) {
+				host = ex.addEntity(LabelHost, strings.ToLower(u.Hostname()), "")
+				subject = host
+			}
+		}
+
+		if m := openPortRegex.FindStringSubmatch(line); m != nil && host.name != "" {
+			summary := strings.TrimSpace(m[3] + " " + m[4])
+			service := ex.addEntity(LabelService, fmt.Sprintf("%s:%s/%s", host.name, m[1], m[2]), summary)
+			ex.addRelation(host, service, EdgeRunsService,
+				fmt.Sprintf("%s runs %s on %s/%s", host.name, summary, m[1], m[2]))
+			subject = service
+		}
+
+		for _, cve := range cveRegex.FindAllString(line, -1) {
+			vuln := ex.addEntity(LabelVulnerability, strings.ToUpper(cve), truncateSummary(line))
+			if subject.name != "" {
+				ex.addRelation(subject, vuln, EdgeDetectedVulnerability,
+					fmt.Sprintf("%s is affected by %s", subject.name, vuln.name))
+			}
+		}
+
+		for _, m := range credentialRegex.FindAllStringSubmatch(line, -1) {
+			login := strings.Trim(m[1], `"'`)
+			credential := ex.addEntity(LabelCredential, qualify(login, host),
+				fmt.Sprintf("valid password for %s", qualify(login, host)))
+			account := ex.addEntity(LabelAccount, qualify(login, host), "")
+			ex.addRelation(credential, account, EdgeYieldedAccess,
+				fmt.Sprintf("password credential for %s yields access to account %s", credential.name, login))
+			if host.name != "" {
+				ex.addRelation(credential, host, EdgeAuthenticatesTo,
+					fmt.Sprintf("credential %s authenticates to %s", credential.name, host.name))
+			}
+		}
+
+		for _, m := range uidRegex.FindAllStringSubmatch(line, -1) {
+			account := ex.addEntity(LabelAccount, qualify(m[2], host), fmt.Sprintf("uid=%s(%s)", m[1], m[2]))
+			if host.name != "" {
+				ex.addRelation(account, host, EdgeAuthenticatesTo,
+					fmt.Sprintf("account %s (uid %s) has access to %s", m[2], m[1], host.name))
+			}
+		}
+	}
+
+	return ex
+}
+
+// Entity is an engagement entity found by ExtractEntities
+type Entity struct {
+	Label   string
+	Name    string
+	Summary string
+}
+
+// Relation links two entities found by ExtractEntities
+type Relation struct {
+	SourceLabel string
+	SourceName  string
+	TargetLabel string
+	TargetName  string
+	Name        string
+	Fact        string
+}
+
+// ExtractEntities runs the extractor of the built-in graph over any text,
+// so other views of a flow agree with it on what an entity is. Entities are
+// returned in the order they are first mentioned.
+func ExtractEntities(content string) ([]Entity, []Relation) {
+	ex := extractEntities(content)
+
+	entities := make([]Entity, 0, len(ex.entities))
+	for _, e := range ex.entities {
+		entities = append(entities, Entity{Label: e.label, Name: e.name, Summary: e.summary})
+	}
+	relations := make([]Relation, 0, len(ex.relations))
+	for _, r := range ex.relations {
+		relations = append(relations, Relation{
+			SourceLabel: r.source.label,
+			SourceName:  r.source.name,
+			TargetLabel: r.target.label,
+			TargetName:  r.target.name,
+			Name:        r.name,
+			Fact:        r.fact,
+		})
+	}
+	return entities, relations
+}
+
+// addEntity records an entity once, keeping the first non-empty summary,
+// and returns its key.
+func (ex *extraction) addEntity(label, name, summary string) entityKey {
+	key := entityKey{label: label, name: name}
+	if i, ok := ex.index[key]; ok {
+		if ex.entities[i].summary == "" {
+			ex.entities[i].summary = summary
+		}
+		return key
+	}
+
+	ex.index[key] = len(ex.entities)
+	ex.entities = append(ex.entities, extractedEntity{entityKey: key, summary: summary})
+	return key
+}
+
+func (ex *extraction) addRelation(source, target entityKey, name, fact string) {
+	key := extractedRelationKey{source: source, target: target, name: name}
+	if _, ok := ex.seen[key]; ok || source == target {
+		return
+	}
+	ex.seen[key] = struct{}{}
+	ex.relations = append(ex.relations, extractedRelation{
+		source: source,
+		target: target,
+		name:   name,
+		fact:   fact,
+	})
+}
+
+// episodeSuccess reports the status of a tool execution record; ok is false
+// for agent responses which have none.
+func episodeSuccess(content string) (success, ok bool) {
+	m := statusRegex.FindStringSubmatch(content)
+	if m == nil {
+		return false, false
+	}
+	return m[1] == "success", true
+}
+
+// isTargetIP rejects strings which only look like addresses and addresses
+// which never identify an engagement target.
+func isTargetIP(s string) bool {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return false
+	}
+	return !ip.IsLoopback() && !ip.IsUnspecified() && !ip.Equal(net.IPv4bcast)
+}
+
+func isLoopbackName(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// qualify names accounts and credentials by host since the same login on
+// two hosts is two different accounts.
+func qualify(login string, host entityKey) string {
+	if host.name == "" {
+		return login
+	}
+	return login + "@" + host.name
+}
+
+func truncateSummary(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) <= maxSummaryLength {
+		return line
+	}
+	cut := maxSummaryLength
+	for cut > 0 && line[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return line[:cut] + "..."
+}
@@ -0,0 +1,116 @@
+package graphiti
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractEntities_NmapScan(t *testing.T) {
+	t.Parallel()
+
+	content := `Tool: terminal
+Arguments: {"input":"nmap -sV 10.0.0.5"}
+Status: success
+Result: Nmap scan report for web.corp.local (10.0.0.5)
+PORT   STATE SERVICE VERSION
+22/tcp open  ssh     OpenSSH 8.2p1 Ubuntu
+80/tcp open  http    Apache httpd 2.4.49
+| vulners: CVE-2021-41773 9.8
+Nmap done: 1 IP address (1 host up) scanned`
+
+	ex := extractEntities(content)
+
+	host := entityKey{LabelHost, "10.0.0.5"}
+	ssh := entityKey{LabelService, "10.0.0.5:22/tcp"}
+	http := entityKey{LabelService, "10.0.0.5:80/tcp"}
+	cve := entityKey{LabelVulnerability, "CVE-2021-41773"}
+
+	require.Contains(t, ex.index, host)
+	assert.Equal(t, "web.corp.local", ex.entities[ex.index[host]].summary)
+	require.Contains(t, ex.index, ssh)
+	assert.Equal(t, "ssh OpenSSH 8.2p1 Ubuntu", ex.entities[ex.index[ssh]].summary)
+	require.Contains(t, ex.index, http)
+	require.Contains(t, ex.index, cve)
+
+	assert.Contains(t, ex.seen, extractedRelationKey{host, ssh, EdgeRunsService})
+	assert.Contains(t, ex.seen, extractedRelationKey{host, http, EdgeRunsService})
+	// the vulnerability belongs to the service listed right before it
+	assert.Contains(t, ex.seen, extractedRelationKey{http, cve, EdgeDetectedVulnerability})
+	assert.Len(t, ex.relations, 3)
+
+	success, ok := episodeSuccess(content)
+	assert.True(t, ok)
+	assert.True(t, success)
+}
+
+func TestExtractEntities_Credentials(t *testing.T) {
+	t.Parallel()
+
+	content := `[22][ssh] host: 192.168.1.20   login: admin   password: S3cret!
+uid=0(root) gid=0(root) groups=0(root)`
+
+	ex := extractEntities(content)
+
+	host := entityKey{LabelHost, "192.168.1.20"}
+	credential := entityKey{LabelCredential, "admin@192.168.1.20"}
+	admin := entityKey{LabelAccount, "admin@192.168.1.20"}
+	root := entityKey{LabelAccount, "root@192.168.1.20"}
+
+	for _, key := range []entityKey{host, credential, admin, root} {
+		require.Contains(t, ex.index, key)
+	}
+	for _, e := range ex.entities {
+		assert.NotContains(t, e.summary, "S3cret", "secrets must never be stored")
+		assert.NotContains(t, e.name, "S3cret", "secrets must never be stored")
+	}
+	for _, r := range ex.relations {
+		assert.NotContains(t, r.fact, "S3cret", "secrets must never be stored")
+	}
+
+	assert.Contains(t, ex.seen, extractedRelationKey{credential, admin, EdgeYieldedAccess})
+	assert.Contains(t, ex.seen, extractedRelationKey{credential, host, EdgeAuthenticatesTo})
+	assert.Contains(t, ex.seen, extractedRelationKey{root, host, EdgeAuthenticatesTo})
+	assert.Equal(t, "uid=0(root)", ex.entities[ex.index[root]].summary)
+}
+
+func TestExtractEntities_IgnoresNoise(t *testing.T) {
+	t.Parallel()
+
+	ex := extractEntities(`Agent: pentester
+Response: listening on 0.0.0.0:8080 and 127.0.0.1, see http://localhost:3000/ and version 999.1.2.3
+Context: Task 1, Subtask 2`)
+
+	assert.Empty(t, ex.entities)
+	assert.Empty(t, ex.relations)
+
+	_, ok := episodeSuccess("Agent: pentester\nResponse: done")
+	assert.False(t, ok, "agent responses have no status")
+}
+
+func TestExtractEntities_URLHost(t *testing.T) {
+	t.Parallel()
+
+	ex := extractEntities("found admin panel at https://Shop.Example.com/admin/login.php")
+
+	assert.Contains(t, ex.index, entityKey{LabelHost, "shop.example.com"})
+	assert.Len(t, ex.entities, 1)
+}
+
+func TestTruncateSummary(t *testing.T) {
+	t.Parallel()
+
+	short := "  CVE-2021-41773 path traversal  "
+	assert.Equal(t, "CVE-2021-41773 path traversal", truncateSummary(short))
+
+	long := ""
+	for len(long) < maxSummaryLength*2 {
+		long += "обход "
+	}
+	got := truncateSummary(long)
+	assert.LessOrEqual(t, len(got), maxSummaryLength+len("..."))
+	assert.Contains(t, got, "...")
+	assert.True(t, utf8.ValidString(got), "summary must not split a rune")
+}
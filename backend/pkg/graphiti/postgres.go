@@ -0,0 +1,753 @@
+package graphiti
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pentagi/pkg/database"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPostgresMaxResults = 10
+	maxPostgresMaxResults     = 100
+	defaultPostgresMaxDepth   = 2
+	maxPostgresMaxDepth       = 3
+	defaultRecencyWindow      = 24 * time.Hour
+)
+
+var (
+	// the whole history, for searches without a time window
+	minSearchTime = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	maxSearchTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	recencyWindows = map[string]time.Duration{
+		"1h":  time.Hour,
+		"6h":  6 * time.Hour,
+		"24h": 24 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+	}
+
+	// per diversity level, how many results may share a source entity, an
+	// entity label or an episode name
+	diversityCaps = map[string]int{
+		"low":    0,
+		"medium": 3,
+		"high":   1,
+	}
+)
+
+// graphStore is the part of database.Querier used by the built-in graph
+type graphStore interface {
+	CreateGraphEpisode(ctx context.Context, arg database.CreateGraphEpisodeParams) (database.GraphEpisode, error)
+	UpsertGraphEntity(ctx context.Context, arg database.UpsertGraphEntityParams) (database.GraphEntity, error)
+	UpsertGraphRelation(ctx context.Context, arg database.UpsertGraphRelationParams) (database.GraphRelation, error)
+	LinkGraphEntityEpisode(ctx context.Context, arg database.LinkGraphEntityEpisodeParams) error
+	GetGraphEntity(ctx context.Context, id uuid.UUID) (database.GraphEntity, error)
+	GetGraphEntitiesByIDs(ctx context.Context, ids []uuid.UUID) ([]database.GraphEntity, error)
+	GetGraphEntityRelations(ctx context.Context, arg database.GetGraphEntityRelationsParams) ([]database.GraphRelation, error)
+	GetGraphEpisodeEntities(ctx context.Context, arg database.GetGraphEpisodeEntitiesParams) ([]database.GraphEntity, error)
+	GetGraphGroupEpisodes(ctx context.Context, arg database.GetGraphGroupEpisodesParams) ([]database.GraphEpisode, error)
+	SearchGraphEntities(ctx context.Context, arg database.SearchGraphEntitiesParams) ([]database.SearchGraphEntitiesRow, error)
+	SearchGraphEpisodes(ctx context.Context, arg database.SearchGraphEpisodesParams) ([]database.SearchGraphEpisodesRow, error)
+	SearchGraphRelations(ctx context.Context, arg database.SearchGraphRelationsParams) ([]database.SearchGraphRelationsRow, error)
+}
+
+// postgresBackend is the built-in knowledge graph: episodes are stored as is
+// and engagement entities are extracted from them by pattern matching, so it
+// needs neither Graphiti nor Neo4j nor an LLM.
+type postgresBackend struct {
+	db graphStore
+}
+
+// NewPostgresClient creates a client backed by the built-in Postgres
+// knowledge graph; unlike NewClient there is no service to check.
+func NewPostgresClient(db database.Querier, timeout time.Duration, enabled bool) *Client {
+	if !enabled {
+		return &Client{enabled: false}
+	}
+
+	return &Client{
+		backend: &postgresBackend{db: db},
+		enabled: true,
+		timeout: timeout,
+	}
+}
+
+func (b *postgresBackend) AddMessages(ctx context.Context, req AddMessagesRequest) error {
+	for _, msg := range req.Messages {
+		if err := b.addMessage(ctx, req.GroupID, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *postgresBackend) addMessage(ctx context.Context, groupID string, msg Message) error {
+	seenAt := msg.Timestamp
+	if seenAt.IsZero() {
+		seenAt = time.Now()
+	}
+	seenAt = seenAt.UTC()
+
+	var success sql.NullBool
+	success.Bool, success.Valid = episodeSuccess(msg.Content)
+
+	episode, err := b.db.CreateGraphEpisode(ctx, database.CreateGraphEpisodeParams{
+		GroupID:           groupID,
+		Name:              msg.Name,
+		Author:            msg.Author,
+		SourceDescription: msg.SourceDescription,
+		Content:           msg.Content,
+		Success:           success,
+		ValidAt:           seenAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store graph episode: %w", err)
+	}
+
+	ex := extractEntities(msg.Content)
+	ids := make(map[entityKey]uuid.UUID, len(ex.entities))
+	for _, e := range ex.entities {
+		entity, err := b.db.UpsertGraphEntity(ctx, database.UpsertGraphEntityParams{
+			GroupID: groupID,
+			Label:   e.label,
+			Name:    e.name,
+			Summary: e.summary,
+			SeenAt:  seenAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store graph entity %s %q: %w", e.label, e.name, err)
+		}
+		ids[e.entityKey] = entity.ID
+
+		err = b.db.LinkGraphEntityEpisode(ctx, database.LinkGraphEntityEpisodeParams{
+			EntityID:  entity.ID,
+			EpisodeID: episode.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to link graph entity to episode: %w", err)
+		}
+	}
+
+	for _, r := range ex.relations {
+		_, err := b.db.UpsertGraphRelation(ctx, database.UpsertGraphRelationParams{
+			GroupID:   groupID,
+			SourceID:  ids[r.source],
+			TargetID:  ids[r.target],
+			Name:      r.name,
+			Fact:      r.fact,
+			EpisodeID: uuid.NullUUID{UUID: episode.ID, Valid: true},
+			SeenAt:    seenAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store graph relation %s: %w", r.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (b *postgresBackend) GetEpisodes(ctx context.Context, groupID string, lastN int) ([]Episode, error) {
+	if lastN <= 0 {
+		return nil, fmt.Errorf("lastN must be positive")
+	}
+
+	episodes, err := b.db.GetGraphGroupEpisodes(ctx, database.GetGraphGroupEpisodesParams{
+		GroupID: groupID,
+		Lim:     int32(lastN),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph episodes: %w", err)
+	}
+
+	result := make([]Episode, 0, len(episodes))
+	for _, e := range episodes {
+		result = append(result, Episode{
+			UUID:              e.ID.String(),
+			GroupID:           e.GroupID,
+			Name:              e.Name,
+			Content:           e.Content,
+			Source:            "message",
+			SourceDescription: e.SourceDescription,
+			CreatedAt:         e.CreatedAt.Time,
+			ValidAt:           e.ValidAt,
+		})
+	}
+	return result, nil
+}
+
+func (b *postgresBackend) TemporalWindowSearch(ctx context.Context, req TemporalSearchRequest) (*TemporalSearchResponse, error) {
+	w := searchWindow{
+		query:   req.Query,
+		groupID: groupOf(req.GroupID),
+		start:   req.TimeStart,
+		end:     req.TimeEnd,
+		limit:   resultLimit(req.MaxResults),
+	}
+
+	resp := &TemporalSearchResponse{TimeWindow: TimeWindow{Start: w.start, End: w.end}}
+	var err error
+	if resp.Edges, resp.EdgeScores, err = b.searchEdges(ctx, w); err != nil {
+		return nil, err
+	}
+	if resp.Nodes, resp.NodeScores, err = b.searchNodes(ctx, w, nil); err != nil {
+		return nil, err
+	}
+	if resp.Episodes, resp.EpisodeScores, err = b.searchEpisodes(ctx, w, false); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *postgresBackend) RecentContextSearch(ctx context.Context, req RecentContextSearchRequest) (*RecentContextSearchResponse, error) {
+	window := defaultRecencyWindow
+	if req.RecencyWindow != "" {
+		var ok bool
+		if window, ok = recencyWindows[req.RecencyWindow]; !ok {
+			return nil, fmt.Errorf("unsupported recency window %q", req.RecencyWindow)
+		}
+	}
+
+	end := time.Now().UTC()
+	w := searchWindow{
+		query:   req.Query,
+		groupID: groupOf(req.GroupID),
+		start:   end.Add(-window),
+		end:     end,
+		limit:   resultLimit(req.MaxResults),
+	}
+
+	resp := &RecentContextSearchResponse{TimeWindow: TimeWindow{Start: w.start, End: w.end}}
+	var err error
+	if resp.Edges, resp.EdgeScores, err = b.searchEdges(ctx, w); err != nil {
+		return nil, err
+	}
+	if resp.Nodes, resp.NodeScores, err = b.searchNodes(ctx, w, nil); err != nil {
+		return nil, err
+	}
+	if resp.Episodes, resp.EpisodeScores, err = b.searchEpisodes(ctx, w, false); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// EntityRelationshipsSearch walks the graph breadth first from the center
+// entity up to max depth. Results are ordered by distance and, within the
+// same distance, by how many query terms they contain.
+func (b *postgresBackend) EntityRelationshipsSearch(ctx context.Context, req EntityRelationshipSearchRequest) (*EntityRelationshipSearchResponse, error) {
+	centerID, err := uuid.Parse(req.CenterNodeUUID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid center node uuid %q: %w", req.CenterNodeUUID, err)
+	}
+
+	center, err := b.db.GetGraphEntity(ctx, centerID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && req.GroupID != nil && center.GroupID != *req.GroupID) {
+		return nil, fmt.Errorf("center node %s not found", req.CenterNodeUUID)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get center node: %w", err)
+	}
+
+	maxDepth := req.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultPostgresMaxDepth
+	}
+	maxDepth = min(maxDepth, maxPostgresMaxDepth)
+	limit := resultLimit(req.MaxResults)
+
+	var edgeTypes []string
+	if req.EdgeTypes != nil {
+		edgeTypes = *req.EdgeTypes
+	}
+
+	type ranked[T any] struct {
+		item     T
+		distance int
+		score    int
+	}
+
+	var (
+		edges    []ranked[EdgeResult]
+		nodes    []ranked[database.GraphEntity]
+		distance = map[uuid.UUID]int{centerID: 0}
+		seen     = make(map[uuid.UUID]struct{})
+		frontier = []uuid.UUID{centerID}
+	)
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		relations, err := b.db.GetGraphEntityRelations(ctx, database.GetGraphEntityRelationsParams{
+			Ids:       frontier,
+			EdgeTypes: nonNil(edgeTypes),
+			Lim:       int32(maxPostgresMaxResults),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entity relations: %w", err)
+		}
+
+		var next []uuid.UUID
+		for _, r := range relations {
+			if _, ok := seen[r.ID]; ok {
+				continue
+			}
+			seen[r.ID] = struct{}{}
+			edges = append(edges, ranked[EdgeResult]{edgeResult(r), depth, termScore(req.Query, r.Fact)})
+			for _, id := range []uuid.UUID{r.SourceID, r.TargetID} {
+				if _, ok := distance[id]; !ok {
+					distance[id] = depth
+					next = append(next, id)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	ids := make([]uuid.UUID, 0, len(distance))
+	for id := range distance {
+		if id != centerID {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		entities, err := b.db.GetGraphEntitiesByIDs(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get related entities: %w", err)
+		}
+		for _, e := range entities {
+			if req.NodeLabels != nil && len(*req.NodeLabels) > 0 && !containsFold(*req.NodeLabels, e.Label) {
+				continue
+			}
+			nodes = append(nodes, ranked[database.GraphEntity]{e, distance[e.ID], termScore(req.Query, e.Name+" "+e.Summary)})
+		}
+	}
+
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].distance != edges[j].distance {
+			return edges[i].distance < edges[j].distance
+		}
+		return edges[i].score > edges[j].score
+	})
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].distance != nodes[j].distance {
+			return nodes[i].distance < nodes[j].distance
+		}
+		if nodes[i].score != nodes[j].score {
+			return nodes[i].score > nodes[j].score
+		}
+		return nodes[i].item.Name < nodes[j].item.Name
+	})
+
+	centerNode := nodeResult(center)
+	resp := &EntityRelationshipSearchResponse{CenterNode: &centerNode}
+	for _, e := range edges[:min(len(edges), limit)] {
+		resp.Edges = append(resp.Edges, e.item)
+		resp.EdgeDistances = append(resp.EdgeDistances, float64(e.distance))
+	}
+	for _, n := range nodes[:min(len(nodes), limit)] {
+		resp.Nodes = append(resp.Nodes, nodeResult(n.item))
+		resp.NodeDistances = append(resp.NodeDistances, float64(n.distance))
+	}
+	return resp, nil
+}
+
+// DiverseResultsSearch over-fetches and then caps how many results may share
+// a source entity, a label or an episode name, instead of the maximal
+// marginal relevance reranking Graphiti does with embeddings.
+func (b *postgresBackend) DiverseResultsSearch(ctx context.Context, req DiverseSearchRequest) (*DiverseSearchResponse, error) {
+	level := req.DiversityLevel
+	if level == "" {
+		level = "medium"
+	}
+	perGroup, ok := diversityCaps[level]
+	if !ok {
+		return nil, fmt.Errorf("unsupported diversity level %q", req.DiversityLevel)
+	}
+
+	limit := resultLimit(req.MaxResults)
+	w := searchWindow{
+		query:   req.Query,
+		groupID: groupOf(req.GroupID),
+		start:   minSearchTime,
+		end:     maxSearchTime,
+		limit:   min(limit*3, maxPostgresMaxResults),
+	}
+
+	edges, edgeScores, err := b.searchEdges(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	nodes, nodeScores, err := b.searchNodes(ctx, w, nil)
+	if err != nil {
+		return nil, err
+	}
+	episodes, episodeScores, err := b.searchEpisodes(ctx, w, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DiverseSearchResponse{}
+	resp.Edges, resp.EdgeMMRScores = diversify(edges, edgeScores, limit, perGroup,
+		func(e EdgeResult) string { return e.SourceNodeUUID })
+	resp.Nodes, resp.NodeMMRScores = diversify(nodes, nodeScores, limit, perGroup,
+		func(n NodeResult) string { return strings.Join(n.Labels, ",") })
+	resp.Episodes, resp.EpisodeScores = diversify(episodes, episodeScores, limit, perGroup,
+		func(e EpisodeResult) string { return e.Source })
+	return resp, nil
+}
+
+func (b *postgresBackend) EpisodeContextSearch(ctx context.Context, req EpisodeContextSearchRequest) (*EpisodeContextSearchResponse, error) {
+	w := searchWindow{
+		query:   req.Query,
+		groupID: groupOf(req.GroupID),
+		start:   minSearchTime,
+		end:     maxSearchTime,
+		limit:   resultLimit(req.MaxResults),
+	}
+
+	episodes, scores, err := b.searchEpisodes(ctx, w, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &EpisodeContextSearchResponse{Episodes: episodes, RerankerScores: scores}
+	resp.MentionedNodes, resp.MentionedNodeScores, err = b.episodeEntities(ctx, episodes, w.limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SuccessfulToolsSearch returns tool executions which reported success with
+// the entities and facts they produced; min mentions filters out entities
+// and facts seen fewer times across the engagement.
+func (b *postgresBackend) SuccessfulToolsSearch(ctx context.Context, req SuccessfulToolsSearchRequest) (*SuccessfulToolsSearchResponse, error) {
+	w := searchWindow{
+		query:   req.Query,
+		groupID: groupOf(req.GroupID),
+		start:   minSearchTime,
+		end:     maxSearchTime,
+		limit:   resultLimit(req.MaxResults),
+	}
+
+	episodes, scores, err := b.searchEpisodes(ctx, w, true)
+	if err != nil {
+		return nil, err
+	}
+	resp := &SuccessfulToolsSearchResponse{Episodes: episodes, EpisodeScores: scores}
+
+	if resp.Nodes, resp.NodeMentionCounts, err = b.episodeEntities(ctx, episodes, w.limit, req.MinMentions); err != nil {
+		return nil, err
+	}
+
+	rows, err := b.db.SearchGraphRelations(ctx, w.relationsParams())
+	if err != nil {
+		return nil, fmt.Errorf("failed to search graph relations: %w", err)
+	}
+	for _, r := range rows {
+		if r.Mentions < int64(req.MinMentions) {
+			continue
+		}
+		resp.Edges = append(resp.Edges, edgeResult(graphRelationFromRow(r)))
+		resp.EdgeMentionCounts = append(resp.EdgeMentionCounts, float64(r.Mentions))
+	}
+	return resp, nil
+}
+
+func (b *postgresBackend) EntityByLabelSearch(ctx context.Context, req EntityByLabelSearchRequest) (*EntityByLabelSearchResponse, error) {
+	if len(req.NodeLabels) == 0 {
+		return nil, fmt.Errorf("node labels are required")
+	}
+
+	w := searchWindow{
+		query:   req.Query,
+		groupID: groupOf(req.GroupID),
+		start:   minSearchTime,
+		end:     maxSearchTime,
+		limit:   resultLimit(req.MaxResults),
+	}
+
+	resp := &EntityByLabelSearchResponse{}
+	var err error
+	if resp.Nodes, resp.NodeScores, err = b.searchNodes(ctx, w, req.NodeLabels); err != nil {
+		return nil, err
+	}
+	if len(resp.Nodes) == 0 {
+		return resp, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(resp.Nodes))
+	for _, n := range resp.Nodes {
+		ids = append(ids, uuid.MustParse(n.UUID))
+	}
+	var edgeTypes []string
+	if req.EdgeTypes != nil {
+		edgeTypes = *req.EdgeTypes
+	}
+	relations, err := b.db.GetGraphEntityRelations(ctx, database.GetGraphEntityRelationsParams{
+		Ids:       ids,
+		EdgeTypes: nonNil(edgeTypes),
+		Lim:       int32(w.limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity relations: %w", err)
+	}
+	for _, r := range relations {
+		resp.Edges = append(resp.Edges, edgeResult(r))
+		resp.EdgeScores = append(resp.EdgeScores, float64(termScore(req.Query, r.Fact)))
+	}
+	return resp, nil
+}
+
+// searchWindow holds the filters shared by the searches over entities,
+// relations and episodes
+type searchWindow struct {
+	query   string
+	groupID string
+	start   time.Time
+	end     time.Time
+	limit   int
+}
+
+func (w searchWindow) relationsParams() database.SearchGraphRelationsParams {
+	return database.SearchGraphRelationsParams{
+		Query:     w.query,
+		GroupID:   w.groupID,
+		EdgeTypes: []string{},
+		TimeStart: w.start,
+		TimeEnd:   w.end,
+		Lim:       int32(w.limit),
+	}
+}
+
+func (b *postgresBackend) searchEdges(ctx context.Context, w searchWindow) ([]EdgeResult, []float64, error) {
+	rows, err := b.db.SearchGraphRelations(ctx, w.relationsParams())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to search graph relations: %w", err)
+	}
+
+	edges := make([]EdgeResult, 0, len(rows))
+	scores := make([]float64, 0, len(rows))
+	for _, r := range rows {
+		edges = append(edges, edgeResult(graphRelationFromRow(r)))
+		scores = append(scores, r.Score)
+	}
+	return edges, scores, nil
+}
+
+func (b *postgresBackend) searchNodes(ctx context.Context, w searchWindow, labels []string) ([]NodeResult, []float64, error) {
+	rows, err := b.db.SearchGraphEntities(ctx, database.SearchGraphEntitiesParams{
+		Query:     w.query,
+		GroupID:   w.groupID,
+		Labels:    nonNil(labels),
+		TimeStart: w.start,
+		TimeEnd:   w.end,
+		Lim:       int32(w.limit),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to search graph entities: %w", err)
+	}
+
+	nodes := make([]NodeResult, 0, len(rows))
+	scores := make([]float64, 0, len(rows))
+	for _, r := range rows {
+		nodes = append(nodes, nodeResult(graphEntityFromRow(r)))
+		scores = append(scores, r.Score)
+	}
+	return nodes, scores, nil
+}
+
+func (b *postgresBackend) searchEpisodes(ctx context.Context, w searchWindow, successfulOnly bool) ([]EpisodeResult, []float64, error) {
+	rows, err := b.db.SearchGraphEpisodes(ctx, database.SearchGraphEpisodesParams{
+		Query:          w.query,
+		GroupID:        w.groupID,
+		TimeStart:      w.start,
+		TimeEnd:        w.end,
+		SuccessfulOnly: successfulOnly,
+		Lim:            int32(w.limit),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to search graph episodes: %w", err)
+	}
+
+	episodes := make([]EpisodeResult, 0, len(rows))
+	scores := make([]float64, 0, len(rows))
+	for _, r := range rows {
+		episodes = append(episodes, EpisodeResult{
+			UUID:              r.ID.String(),
+			Content:           r.Content,
+			Source:            r.Name,
+			SourceDescription: r.SourceDescription,
+			CreatedAt:         r.ValidAt,
+			ValidAt:           r.ValidAt,
+		})
+		scores = append(scores, r.Score)
+	}
+	return episodes, scores, nil
+}
+
+// episodeEntities returns the entities mentioned by the episodes with their
+// mention counts, skipping those mentioned fewer than minMentions times.
+func (b *postgresBackend) episodeEntities(
+	ctx context.Context,
+	episodes []EpisodeResult,
+	limit, minMentions int,
+) ([]NodeResult, []float64, error) {
+	if len(episodes) == 0 {
+		return nil, nil, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(episodes))
+	for _, e := range episodes {
+		ids = append(ids, uuid.MustParse(e.UUID))
+	}
+	entities, err := b.db.GetGraphEpisodeEntities(ctx, database.GetGraphEpisodeEntitiesParams{
+		Ids: ids,
+		Lim: int32(limit),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get episode entities: %w", err)
+	}
+
+	var (
+		nodes  []NodeResult
+		counts []float64
+	)
+	for _, e := range entities {
+		if e.Mentions < int64(minMentions) {
+			continue
+		}
+		nodes = append(nodes, nodeResult(e))
+		counts = append(counts, float64(e.Mentions))
+	}
+	return nodes, counts, nil
+}
+
+func nodeResult(e database.GraphEntity) NodeResult {
+	return NodeResult{
+		UUID:      e.ID.String(),
+		Name:      e.Name,
+		Labels:    []string{e.Label},
+		Summary:   e.Summary,
+		CreatedAt: e.FirstSeenAt,
+		Attributes: map[string]interface{}{
+			"mentions":     e.Mentions,
+			"last_seen_at": e.LastSeenAt,
+		},
+	}
+}
+
+func edgeResult(r database.GraphRelation) EdgeResult {
+	validAt := r.ValidAt
+	return EdgeResult{
+		UUID:           r.ID.String(),
+		Name:           r.Name,
+		Fact:           r.Fact,
+		SourceNodeUUID: r.SourceID.String(),
+		TargetNodeUUID: r.TargetID.String(),
+		ValidAt:        &validAt,
+		CreatedAt:      r.ValidAt,
+	}
+}
+
+func graphEntityFromRow(r database.SearchGraphEntitiesRow) database.GraphEntity {
+	return database.GraphEntity{
+		ID:          r.ID,
+		GroupID:     r.GroupID,
+		Label:       r.Label,
+		Name:        r.Name,
+		Summary:     r.Summary,
+		Mentions:    r.Mentions,
+		FirstSeenAt: r.FirstSeenAt,
+		LastSeenAt:  r.LastSeenAt,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+func graphRelationFromRow(r database.SearchGraphRelationsRow) database.GraphRelation {
+	return database.GraphRelation{
+		ID:         r.ID,
+		GroupID:    r.GroupID,
+		SourceID:   r.SourceID,
+		TargetID:   r.TargetID,
+		Name:       r.Name,
+		Fact:       r.Fact,
+		Mentions:   r.Mentions,
+		EpisodeID:  r.EpisodeID,
+		ValidAt:    r.ValidAt,
+		LastSeenAt: r.LastSeenAt,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+// diversify keeps results in order while allowing at most perGroup of them
+// per key; zero means no cap.
+func diversify[T any](items []T, scores []float64, limit, perGroup int, key func(T) string) ([]T, []float64) {
+	var (
+		kept       []T
+		keptScores []float64
+		counts     = make(map[string]int)
+	)
+	for i, item := range items {
+		if len(kept) == limit {
+			break
+		}
+		k := key(item)
+		if perGroup > 0 && counts[k] >= perGroup {
+			continue
+		}
+		counts[k]++
+		kept = append(kept, item)
+		keptScores = append(keptScores, scores[i])
+	}
+	return kept, keptScores
+}
+
+// termScore counts the query words found in text, a cheap relevance signal
+// for results which come from graph traversal rather than text search.
+func termScore(query, text string) int {
+	text = strings.ToLower(text)
+	score := 0
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if len(term) > 2 && strings.Contains(text, term) {
+			score++
+		}
+	}
+	return score
+}
+
+func resultLimit(maxResults int) int {
+	if maxResults <= 0 {
+		return defaultPostgresMaxResults
+	}
+	return min(maxResults, maxPostgresMaxResults)
+}
+
+func groupOf(groupID *string) string {
+	if groupID == nil {
+		return ""
+	}
+	return *groupID
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// nonNil keeps empty filters from being sent as NULL arrays
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
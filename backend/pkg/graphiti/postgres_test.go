@@ -0,0 +1,471 @@
+package graphiti
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+	"time"
+
+	"pentagi/pkg/database"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryGraph is an in-memory graphStore; its searches only apply the
+// group, label, time and successful filters, text matching is left to SQL.
+type memoryGraph struct {
+	episodes  []database.GraphEpisode
+	entities  []database.GraphEntity
+	relations []database.GraphRelation
+	links     map[uuid.UUID][]uuid.UUID // episode -> entities
+
+	episodeParams []database.SearchGraphEpisodesParams
+}
+
+func newMemoryGraph() *memoryGraph {
+	return &memoryGraph{links: make(map[uuid.UUID][]uuid.UUID)}
+}
+
+func (m *memoryGraph) CreateGraphEpisode(_ context.Context, arg database.CreateGraphEpisodeParams) (database.GraphEpisode, error) {
+	e := database.GraphEpisode{
+		ID:                uuid.New(),
+		GroupID:           arg.GroupID,
+		Name:              arg.Name,
+		Author:            arg.Author,
+		SourceDescription: arg.SourceDescription,
+		Content:           arg.Content,
+		Success:           arg.Success,
+		ValidAt:           arg.ValidAt,
+	}
+	m.episodes = append(m.episodes, e)
+	return e, nil
+}
+
+func (m *memoryGraph) UpsertGraphEntity(_ context.Context, arg database.UpsertGraphEntityParams) (database.GraphEntity, error) {
+	for i, e := range m.entities {
+		if e.GroupID == arg.GroupID && e.Label == arg.Label && e.Name == arg.Name {
+			m.entities[i].Mentions++
+			if arg.Summary != "" {
+				m.entities[i].Summary = arg.Summary
+			}
+			if arg.SeenAt.After(e.LastSeenAt) {
+				m.entities[i].LastSeenAt = arg.SeenAt
+			}
+			return m.entities[i], nil
+		}
+	}
+	e := database.GraphEntity{
+		ID:          uuid.New(),
+		GroupID:     arg.GroupID,
+		Label:       arg.Label,
+		Name:        arg.Name,
+		Summary:     arg.Summary,
+		Mentions:    1,
+		FirstSeenAt: arg.SeenAt,
+		LastSeenAt:  arg.SeenAt,
+	}
+	m.entities = append(m.entities, e)
+	return e, nil
+}
+
+func (m *memoryGraph) UpsertGraphRelation(_ context.Context, arg database.UpsertGraphRelationParams) (database.GraphRelation, error) {
+	for i, r := range m.relations {
+		if r.SourceID == arg.SourceID && r.TargetID == arg.TargetID && r.Name == arg.Name {
+			m.relations[i].Mentions++
+			m.relations[i].EpisodeID = arg.EpisodeID
+			return m.relations[i], nil
+		}
+	}
+	r := database.GraphRelation{
+		ID:         uuid.New(),
+		GroupID:    arg.GroupID,
+		SourceID:   arg.SourceID,
+		TargetID:   arg.TargetID,
+		Name:       arg.Name,
+		Fact:       arg.Fact,
+		Mentions:   1,
+		EpisodeID:  arg.EpisodeID,
+		ValidAt:    arg.SeenAt,
+		LastSeenAt: arg.SeenAt,
+	}
+	m.relations = append(m.relations, r)
+	return r, nil
+}
+
+func (m *memoryGraph) LinkGraphEntityEpisode(_ context.Context, arg database.LinkGraphEntityEpisodeParams) error {
+	if !slices.Contains(m.links[arg.EpisodeID], arg.EntityID) {
+		m.links[arg.EpisodeID] = append(m.links[arg.EpisodeID], arg.EntityID)
+	}
+	return nil
+}
+
+func (m *memoryGraph) GetGraphEntity(_ context.Context, id uuid.UUID) (database.GraphEntity, error) {
+	for _, e := range m.entities {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return database.GraphEntity{}, sql.ErrNoRows
+}
+
+func (m *memoryGraph) GetGraphEntitiesByIDs(_ context.Context, ids []uuid.UUID) ([]database.GraphEntity, error) {
+	var result []database.GraphEntity
+	for _, e := range m.entities {
+		if slices.Contains(ids, e.ID) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryGraph) GetGraphEntityRelations(_ context.Context, arg database.GetGraphEntityRelationsParams) ([]database.GraphRelation, error) {
+	var result []database.GraphRelation
+	for _, r := range m.relations {
+		touches := slices.Contains(arg.Ids, r.SourceID) || slices.Contains(arg.Ids, r.TargetID)
+		if touches && (len(arg.EdgeTypes) == 0 || slices.Contains(arg.EdgeTypes, r.Name)) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryGraph) GetGraphEpisodeEntities(_ context.Context, arg database.GetGraphEpisodeEntitiesParams) ([]database.GraphEntity, error) {
+	var ids []uuid.UUID
+	for _, episodeID := range arg.Ids {
+		ids = append(ids, m.links[episodeID]...)
+	}
+	var result []database.GraphEntity
+	for _, e := range m.entities {
+		if slices.Contains(ids, e.ID) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryGraph) GetGraphGroupEpisodes(_ context.Context, arg database.GetGraphGroupEpisodesParams) ([]database.GraphEpisode, error) {
+	var result []database.GraphEpisode
+	for _, e := range m.episodes {
+		if e.GroupID == arg.GroupID {
+			result = append(result, e)
+		}
+	}
+	if len(result) > int(arg.Lim) {
+		result = result[len(result)-int(arg.Lim):]
+	}
+	return result, nil
+}
+
+func (m *memoryGraph) SearchGraphEntities(_ context.Context, arg database.SearchGraphEntitiesParams) ([]database.SearchGraphEntitiesRow, error) {
+	var result []database.SearchGraphEntitiesRow
+	for _, e := range m.entities {
+		if (arg.GroupID == "" || e.GroupID == arg.GroupID) &&
+			(len(arg.Labels) == 0 || slices.Contains(arg.Labels, e.Label)) &&
+			!e.LastSeenAt.Before(arg.TimeStart) && !e.FirstSeenAt.After(arg.TimeEnd) {
+			result = append(result, database.SearchGraphEntitiesRow{
+				ID: e.ID, GroupID: e.GroupID, Label: e.Label, Name: e.Name, Summary: e.Summary,
+				Mentions: e.Mentions, FirstSeenAt: e.FirstSeenAt, LastSeenAt: e.LastSeenAt, Score: 1,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryGraph) SearchGraphEpisodes(_ context.Context, arg database.SearchGraphEpisodesParams) ([]database.SearchGraphEpisodesRow, error) {
+	m.episodeParams = append(m.episodeParams, arg)
+	var result []database.SearchGraphEpisodesRow
+	for _, e := range m.episodes {
+		if (arg.GroupID == "" || e.GroupID == arg.GroupID) &&
+			!e.ValidAt.Before(arg.TimeStart) && !e.ValidAt.After(arg.TimeEnd) &&
+			(!arg.SuccessfulOnly || (e.Success.Valid && e.Success.Bool)) {
+			result = append(result, database.SearchGraphEpisodesRow{
+				ID: e.ID, GroupID: e.GroupID, Name: e.Name, Author: e.Author,
+				SourceDescription: e.SourceDescription, Content: e.Content,
+				Success: e.Success, ValidAt: e.ValidAt, Score: 1,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryGraph) SearchGraphRelations(_ context.Context, arg database.SearchGraphRelationsParams) ([]database.SearchGraphRelationsRow, error) {
+	var result []database.SearchGraphRelationsRow
+	for _, r := range m.relations {
+		if (arg.GroupID == "" || r.GroupID == arg.GroupID) &&
+			(len(arg.EdgeTypes) == 0 || slices.Contains(arg.EdgeTypes, r.Name)) &&
+			!r.LastSeenAt.Before(arg.TimeStart) && !r.ValidAt.After(arg.TimeEnd) {
+			result = append(result, database.SearchGraphRelationsRow{
+				ID: r.ID, GroupID: r.GroupID, SourceID: r.SourceID, TargetID: r.TargetID,
+				Name: r.Name, Fact: r.Fact, Mentions: r.Mentions, EpisodeID: r.EpisodeID,
+				ValidAt: r.ValidAt, LastSeenAt: r.LastSeenAt, Score: 1,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryGraph) entity(t *testing.T, label, name string) database.GraphEntity {
+	t.Helper()
+	for _, e := range m.entities {
+		if e.Label == label && e.Name == name {
+			return e
+		}
+	}
+	t.Fatalf("entity %s %q not found", label, name)
+	return database.GraphEntity{}
+}
+
+const (
+	testGroupID  = "flow-1"
+	nmapEpisode  = "Tool: terminal\nStatus: success\nResult: Nmap scan report for 10.0.0.5\n80/tcp open http Apache httpd 2.4.49\n| CVE-2021-41773\n"
+	agentEpisode = "Agent: pentester\nResponse: 10.0.0.5 looks like the web server\n"
+)
+
+func seedGraph(t *testing.T, at time.Time) (*memoryGraph, *postgresBackend) {
+	t.Helper()
+
+	db := newMemoryGraph()
+	b := &postgresBackend{db: db}
+	err := b.AddMessages(t.Context(), AddMessagesRequest{
+		GroupID: testGroupID,
+		Messages: []Message{
+			{Content: nmapEpisode, Name: "tool_execution_terminal", Author: "pentester Agent", Timestamp: at},
+			{Content: agentEpisode, Name: "agent_response", Author: "pentester Agent", Timestamp: at},
+		},
+	})
+	require.NoError(t, err)
+	return db, b
+}
+
+func TestPostgresBackend_AddMessages(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 9, 1, 10, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	db, _ := seedGraph(t, at)
+
+	require.Len(t, db.episodes, 2)
+	assert.Equal(t, sql.NullBool{Bool: true, Valid: true}, db.episodes[0].Success)
+	assert.False(t, db.episodes[1].Success.Valid, "agent responses have no status")
+	assert.Equal(t, time.UTC, db.episodes[0].ValidAt.Location())
+
+	host := db.entity(t, LabelHost, "10.0.0.5")
+	service := db.entity(t, LabelService, "10.0.0.5:80/tcp")
+	vuln := db.entity(t, LabelVulnerability, "CVE-2021-41773")
+	assert.Equal(t, int64(2), host.Mentions, "the host is mentioned by both episodes")
+	assert.Len(t, db.links[db.episodes[0].ID], 3)
+	assert.Equal(t, []uuid.UUID{host.ID}, db.links[db.episodes[1].ID])
+
+	require.Len(t, db.relations, 2)
+	assert.Equal(t, host.ID, db.relations[0].SourceID)
+	assert.Equal(t, service.ID, db.relations[0].TargetID)
+	assert.Equal(t, EdgeRunsService, db.relations[0].Name)
+	assert.Equal(t, vuln.ID, db.relations[1].TargetID)
+	assert.Equal(t, uuid.NullUUID{UUID: db.episodes[0].ID, Valid: true}, db.relations[1].EpisodeID)
+}
+
+func TestPostgresBackend_GetEpisodes(t *testing.T) {
+	t.Parallel()
+
+	_, b := seedGraph(t, time.Now())
+
+	episodes, err := b.GetEpisodes(t.Context(), testGroupID, 1)
+	require.NoError(t, err)
+	require.Len(t, episodes, 1)
+	assert.Equal(t, "agent_response", episodes[0].Name)
+	assert.Equal(t, agentEpisode, episodes[0].Content)
+
+	episodes, err = b.GetEpisodes(t.Context(), "flow-2", 10)
+	require.NoError(t, err)
+	assert.Empty(t, episodes)
+
+	_, err = b.GetEpisodes(t.Context(), testGroupID, 0)
+	assert.Error(t, err)
+}
+
+func TestPostgresBackend_EntityRelationshipsSearch(t *testing.T) {
+	t.Parallel()
+
+	db, b := seedGraph(t, time.Now())
+	host := db.entity(t, LabelHost, "10.0.0.5")
+	group := testGroupID
+
+	t.Run("depth limits the traversal", func(t *testing.T) {
+		t.Parallel()
+
+		resp, err := b.EntityRelationshipsSearch(t.Context(), EntityRelationshipSearchRequest{
+			Query: "web", GroupID: &group, CenterNodeUUID: host.ID.String(), MaxDepth: 1,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, resp.CenterNode)
+		assert.Equal(t, host.ID.String(), resp.CenterNode.UUID)
+		require.Len(t, resp.Nodes, 1)
+		assert.Equal(t, "10.0.0.5:80/tcp", resp.Nodes[0].Name)
+		assert.Equal(t, []float64{1}, resp.EdgeDistances)
+
+		resp, err = b.EntityRelationshipsSearch(t.Context(), EntityRelationshipSearchRequest{
+			Query: "web", GroupID: &group, CenterNodeUUID: host.ID.String(), MaxDepth: 2,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Nodes, 2)
+		assert.Equal(t, "CVE-2021-41773", resp.Nodes[1].Name)
+		assert.Equal(t, []float64{1, 2}, resp.NodeDistances)
+	})
+
+	t.Run("label filter", func(t *testing.T) {
+		t.Parallel()
+
+		labels := []string{LabelVulnerability}
+		resp, err := b.EntityRelationshipsSearch(t.Context(), EntityRelationshipSearchRequest{
+			Query: "cve", GroupID: &group, CenterNodeUUID: host.ID.String(), NodeLabels: &labels,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Nodes, 1)
+		assert.Equal(t, []string{LabelVulnerability}, resp.Nodes[0].Labels)
+	})
+
+	t.Run("center node of another group", func(t *testing.T) {
+		t.Parallel()
+
+		other := "flow-2"
+		_, err := b.EntityRelationshipsSearch(t.Context(), EntityRelationshipSearchRequest{
+			Query: "web", GroupID: &other, CenterNodeUUID: host.ID.String(),
+		})
+		require.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("malformed center node", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := b.EntityRelationshipsSearch(t.Context(), EntityRelationshipSearchRequest{
+			Query: "web", CenterNodeUUID: "host-1",
+		})
+		require.ErrorContains(t, err, "invalid center node uuid")
+	})
+}
+
+func TestPostgresBackend_RecentContextSearch(t *testing.T) {
+	t.Parallel()
+
+	_, b := seedGraph(t, time.Now().Add(-2*time.Hour))
+	group := testGroupID
+
+	resp, err := b.RecentContextSearch(t.Context(), RecentContextSearchRequest{
+		Query: "apache", GroupID: &group, RecencyWindow: "1h",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Episodes)
+	assert.Empty(t, resp.Nodes)
+	assert.Equal(t, time.Hour, resp.TimeWindow.End.Sub(resp.TimeWindow.Start))
+
+	resp, err = b.RecentContextSearch(t.Context(), RecentContextSearchRequest{
+		Query: "apache", GroupID: &group, RecencyWindow: "6h",
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.Episodes, 2)
+	assert.Len(t, resp.Nodes, 3)
+	assert.Len(t, resp.Edges, 2)
+	assert.Len(t, resp.EpisodeScores, len(resp.Episodes))
+
+	_, err = b.RecentContextSearch(t.Context(), RecentContextSearchRequest{Query: "apache", RecencyWindow: "2w"})
+	require.ErrorContains(t, err, "unsupported recency window")
+}
+
+func TestPostgresBackend_TemporalWindowSearch(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	_, b := seedGraph(t, at)
+	group := testGroupID
+
+	resp, err := b.TemporalWindowSearch(t.Context(), TemporalSearchRequest{
+		Query: "apache", GroupID: &group, TimeStart: at.Add(-time.Hour), TimeEnd: at.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.Episodes, 2)
+	assert.Equal(t, "tool_execution_terminal", resp.Episodes[0].Source)
+
+	resp, err = b.TemporalWindowSearch(t.Context(), TemporalSearchRequest{
+		Query: "apache", GroupID: &group, TimeStart: at.Add(time.Hour), TimeEnd: at.Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Episodes)
+	assert.Empty(t, resp.Edges)
+}
+
+func TestPostgresBackend_SuccessfulToolsSearch(t *testing.T) {
+	t.Parallel()
+
+	db, b := seedGraph(t, time.Now())
+	group := testGroupID
+
+	resp, err := b.SuccessfulToolsSearch(t.Context(), SuccessfulToolsSearchRequest{
+		Query: "nmap", GroupID: &group, MinMentions: 2,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, db.episodeParams)
+	assert.True(t, db.episodeParams[len(db.episodeParams)-1].SuccessfulOnly)
+	require.Len(t, resp.Episodes, 1)
+	// only the host is mentioned twice, relations once
+	require.Len(t, resp.Nodes, 1)
+	assert.Equal(t, "10.0.0.5", resp.Nodes[0].Name)
+	assert.Equal(t, []float64{2}, resp.NodeMentionCounts)
+	assert.Empty(t, resp.Edges)
+}
+
+func TestPostgresBackend_DiverseResultsSearch(t *testing.T) {
+	t.Parallel()
+
+	_, b := seedGraph(t, time.Now())
+	group := testGroupID
+
+	resp, err := b.DiverseResultsSearch(t.Context(), DiverseSearchRequest{
+		Query: "apache", GroupID: &group, DiversityLevel: "high",
+	})
+	require.NoError(t, err)
+	// one node per label and one episode per name
+	assert.Len(t, resp.Nodes, 3)
+	assert.Len(t, resp.Episodes, 2)
+	assert.Len(t, resp.Edges, 2, "the two relations have different source entities")
+
+	resp, err = b.DiverseResultsSearch(t.Context(), DiverseSearchRequest{
+		Query: "apache", GroupID: &group, DiversityLevel: "high", MaxResults: 1,
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.Nodes, 1)
+
+	_, err = b.DiverseResultsSearch(t.Context(), DiverseSearchRequest{Query: "apache", DiversityLevel: "extreme"})
+	require.ErrorContains(t, err, "unsupported diversity level")
+}
+
+func TestPostgresBackend_EntityByLabelSearch(t *testing.T) {
+	t.Parallel()
+
+	_, b := seedGraph(t, time.Now())
+	group := testGroupID
+
+	resp, err := b.EntityByLabelSearch(t.Context(), EntityByLabelSearchRequest{
+		Query: "apache", GroupID: &group, NodeLabels: []string{LabelService},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Nodes, 1)
+	assert.Equal(t, "10.0.0.5:80/tcp", resp.Nodes[0].Name)
+	assert.Len(t, resp.Edges, 2, "the service is the target of one relation and the source of another")
+	assert.Len(t, resp.EdgeScores, len(resp.Edges))
+
+	_, err = b.EntityByLabelSearch(t.Context(), EntityByLabelSearchRequest{Query: "apache"})
+	require.Error(t, err)
+}
+
+func TestNewPostgresClient(t *testing.T) {
+	t.Parallel()
+
+	client := NewPostgresClient(nil, time.Second, false)
+	assert.False(t, client.IsEnabled())
+
+	client = NewPostgresClient(database.New(nil), 5*time.Second, true)
+	assert.True(t, client.IsEnabled())
+	assert.Equal(t, 5*time.Second, client.GetTimeout())
+}
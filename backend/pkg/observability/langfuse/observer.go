@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
 	"sync"
 	"time"
 
@@ -46,6 +47,7 @@ type observer struct {
 	interval    time.Duration
 	timeout     time.Duration
 	queueSize   int
+	redactor    func(string) string
 	queue       chan *api.IngestionEvent
 	flusher     chan chan error
 }
@@ -168,6 +170,10 @@ func (o *observer) flush(ctx context.Context, batch []*api.IngestionEvent) error
 		return nil
 	}
 
+	if o.redactor != nil {
+		batch = o.redactBatch(batch)
+	}
+
 	metadata := map[string]any{
 		"batch_size":      len(batch),
 		"app_release":     o.release,
@@ -202,6 +208,54 @@ func (o *observer) flush(ctx context.Context, batch []*api.IngestionEvent) error
 	return nil
 }
 
+// redactBatch applies the redactor to every event. An event which cannot be
+// redacted is dropped rather than sent as is.
+func (o *observer) redactBatch(batch []*api.IngestionEvent) []*api.IngestionEvent {
+	result := make([]*api.IngestionEvent, 0, len(batch))
+	for _, event := range batch {
+		redacted, err := redactEvent(event, o.redactor)
+		if err != nil {
+			logrus.WithError(err).Error("failed to redact Langfuse event, dropping it")
+			continue
+		}
+		result = append(result, redacted)
+	}
+	return result
+}
+
+// redactEvent round-trips the variant an event holds through JSON. The
+// event union cannot be decoded as a whole: it would pick the first variant
+// the JSON fits, so the redacted JSON is decoded into the same variant.
+func redactEvent(event *api.IngestionEvent, redactor func(string) string) (*api.IngestionEvent, error) {
+	var redacted api.IngestionEvent
+	src, dst := reflect.ValueOf(event).Elem(), reflect.ValueOf(&redacted).Elem()
+	for idx := 0; idx < src.NumField(); idx++ {
+		variant := src.Field(idx)
+		if !dst.Field(idx).CanSet() || variant.Kind() != reflect.Pointer || variant.IsNil() {
+			continue
+		}
+
+		data, err := json.Marshal(variant.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		text := redactor(string(data))
+		if text == string(data) {
+			return event, nil
+		}
+
+		value := reflect.New(variant.Type().Elem())
+		if err := json.Unmarshal([]byte(text), value.Interface()); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal redacted event: %w", err)
+		}
+		dst.Field(idx).Set(value)
+		return &redacted, nil
+	}
+
+	return event, nil
+}
+
 // flushWithSplit flushes the batch and, if the request fails only because
 // the serialized payload exceeded Langfuse's body-size limit (413), splits
 // the batch in half and retries each half recursively instead of discarding
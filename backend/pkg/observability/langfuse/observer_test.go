@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -269,3 +270,49 @@ func TestObserverFlushWithSplit_OversizedSingleEventIsDroppedNotRetriedForever(t
 
 	_ = o.Shutdown(context.Background())
 }
+
+func TestRedactEventKeepsVariant(t *testing.T) {
+	redactor := func(s string) string {
+		return strings.ReplaceAll(s, "S3cret!", "{{cred:1}}")
+	}
+
+	event := &api.IngestionEvent{IngestionEventTwo: &api.IngestionEventTwo{
+		ID:        newSpanID(),
+		Timestamp: getCurrentTimeString(),
+		Type:      api.IngestionEventTwoType(ingestionCreateSpan).Ptr(),
+		Body: &api.CreateSpanBody{
+			Name:  getStringRef("terminal"),
+			Input: map[string]any{"input": "sshpass -p 'S3cret!' ssh admin@10.0.0.5"},
+		},
+	}}
+
+	redacted, err := redactEvent(event, redactor)
+	if err != nil {
+		t.Fatalf("redactEvent: %v", err)
+	}
+	if redacted.IngestionEventTwo == nil || redacted.IngestionEventZero != nil {
+		t.Fatalf("redacted event changed its variant: %+v", redacted)
+	}
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(data), "S3cret!") || !strings.Contains(string(data), "{{cred:1}}") {
+		t.Fatalf("secret was not redacted: %s", data)
+	}
+	if !strings.Contains(string(data), `"type":"span-create"`) {
+		t.Fatalf("event type was lost: %s", data)
+	}
+
+	clean := &api.IngestionEvent{IngestionEventTwo: &api.IngestionEventTwo{
+		ID:   newSpanID(),
+		Body: &api.CreateSpanBody{Name: getStringRef("nmap")},
+	}}
+	same, err := redactEvent(clean, redactor)
+	if err != nil {
+		t.Fatalf("redactEvent: %v", err)
+	}
+	if same != clean {
+		t.Fatal("an event without secrets should be sent as is")
+	}
+}
@@ -39,3 +39,12 @@ func WithQueueSize(size int) ObserverOption {
 		o.queueSize = size
 	}
 }
+
+// WithRedactor rewrites every event before it is sent, e.g. to replace
+// secrets with references. It sees the event serialized as JSON, so the
+// text it replaces must be JSON-escaped as well.
+func WithRedactor(redactor func(string) string) ObserverOption {
+	return func(o *observer) {
+		o.redactor = redactor
+	}
+}
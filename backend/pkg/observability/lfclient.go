@@ -59,7 +59,9 @@ func (c *langfuseClient) ForceFlush(ctx context.Context) error {
 	return nil
 }
 
-func NewLangfuseClient(ctx context.Context, cfg *config.Config) (LangfuseClient, error) {
+// NewLangfuseClient creates the client and its observer; opts are applied to
+// the observer after the defaults derived from cfg.
+func NewLangfuseClient(ctx context.Context, cfg *config.Config, opts ...langfuse.ObserverOption) (LangfuseClient, error) {
 	if cfg.LangfuseBaseURL == "" {
 		return nil, fmt.Errorf("langfuse base url is not set: %w", ErrNotConfigured)
 	}
@@ -82,7 +84,7 @@ func NewLangfuseClient(ctx context.Context, cfg *config.Config) (LangfuseClient,
 		},
 	}
 
-	opts := []langfuse.ClientContextOption{
+	clientOpts := []langfuse.ClientContextOption{
 		langfuse.WithBaseURL(cfg.LangfuseBaseURL),
 		langfuse.WithPublicKey(cfg.LangfusePublicKey),
 		langfuse.WithSecretKey(cfg.LangfuseSecretKey),
@@ -91,19 +93,20 @@ func NewLangfuseClient(ctx context.Context, cfg *config.Config) (LangfuseClient,
 		langfuse.WithMaxAttempts(DefaultMaxAttempts),
 	}
 
-	client, err := langfuse.NewClient(opts...)
+	client, err := langfuse.NewClient(clientOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create langfuse client: %w", err)
 	}
 
-	observer := langfuse.NewObserver(client,
+	observerOpts := append([]langfuse.ObserverOption{
 		langfuse.WithSendInterval(DefaultObservationInterval),
 		langfuse.WithSendTimeout(DefaultObservationTimeout),
 		langfuse.WithQueueSize(DefaultQueueSize),
 		langfuse.WithProject(cfg.LangfuseProjectID),
 		langfuse.WithRelease(version.GetBinaryVersion()),
 		langfuse.WithEnvironment(cfg.TenantID),
-	)
+	}, opts...)
+	observer := langfuse.NewObserver(client, observerOpts...)
 
 	return &langfuseClient{
 		http:     httpClient,
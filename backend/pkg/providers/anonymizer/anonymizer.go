@@ -0,0 +1,317 @@
+// Package anonymizer keeps sensitive values of a flow away from the LLM
+// providers. Every chain and prompt sent to a provider gets IPs, hostnames,
+// credentials and the other pattern matches replaced with per-flow
+// placeholders such as {{anon:ipv4-address-12}}; the response gets them
+// replaced back before the tool calls run, so the rest of PentAGI only ever
+// sees the real values.
+package anonymizer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/vault"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+)
+
+// anonymizedCacheSize bounds the texts remembered as already anonymized: the
+// whole chain is sent on every call, only its new messages need matching
+const anonymizedCacheSize = 4096
+
+// imageOmittedNote replaces the images of a chain sent to a provider
+const imageOmittedNote = "[image omitted: LLM traffic anonymization is enabled]"
+
+// knownPlaceholderRegex matches the placeholders of this package and of the
+// credential vault, patterns must not anonymize them a second time
+var knownPlaceholderRegex = regexp.MustCompile(`\{\{(?:anon|cred):[^{}]+\}\}`)
+
+type Anonymizer struct {
+	matcher *matcher
+	mapping *mapping
+	cache   *lru.Cache[[32]byte, string]
+}
+
+// New creates the anonymizer of a flow from the built-in patterns merged with
+// the anonymization patterns of the flow's user. The secrets of the instance
+// configuration are always anonymized, a user can not turn them off.
+func New(
+	ctx context.Context,
+	db database.Querier,
+	cfg *config.Config,
+	credentialVault *vault.Vault,
+	flowID, userID int64,
+) (*Anonymizer, error) {
+	builtin, err := BuiltinPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	userPatterns, err := db.GetUserAnonymizationPatterns(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user anonymization patterns: %w", err)
+	}
+
+	m, err := newMatcher(append(MergePatterns(builtin, userPatterns), cfg.GetSecretPatterns()...))
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := lru.New[[32]byte, string](anonymizedCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anonymized text cache: %w", err)
+	}
+
+	return &Anonymizer{
+		matcher: m,
+		mapping: newMapping(db, credentialVault, flowID),
+		cache:   cache,
+	}, nil
+}
+
+// AnonymizeText replaces every sensitive value in text with its placeholder
+func (a *Anonymizer) AnonymizeText(ctx context.Context, text string) (string, error) {
+	if text == "" {
+		return text, nil
+	}
+	if err := a.mapping.load(ctx); err != nil {
+		return "", err
+	}
+
+	key := sha256.Sum256([]byte(text))
+	if anonymized, ok := a.cache.Get(key); ok {
+		return anonymized, nil
+	}
+
+	// placeholders already in the text are not values to anonymize
+	spans := a.matcher.find(text, knownPlaceholderRegex.FindAllStringIndex(text, -1))
+	if len(spans) == 0 {
+		a.cache.Add(key, text)
+		return text, nil
+	}
+
+	var (
+		sb   strings.Builder
+		last int
+	)
+	for _, sp := range spans {
+		placeholder, err := a.mapping.placeholder(ctx, sp.kind, text[sp.start:sp.end])
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(text[last:sp.start])
+		sb.WriteString(placeholder)
+		last = sp.end
+	}
+	sb.WriteString(text[last:])
+
+	anonymized := sb.String()
+	a.cache.Add(key, anonymized)
+
+	return anonymized, nil
+}
+
+// RevealText replaces the placeholders of the flow in text with the values
+func (a *Anonymizer) RevealText(ctx context.Context, text string) (string, error) {
+	if err := a.mapping.load(ctx); err != nil {
+		return "", err
+	}
+	return a.mapping.reveal(ctx, text, func(value string) string { return value })
+}
+
+// AnonymizeArguments anonymizes the string values of tool call arguments.
+// Working on the decoded strings keeps a value the same whether it was seen
+// in a tool result or in JSON, where it may be escaped.
+func (a *Anonymizer) AnonymizeArguments(ctx context.Context, args string) (string, error) {
+	var value any
+	decoder := json.NewDecoder(strings.NewReader(args))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return a.AnonymizeText(ctx, args)
+	}
+
+	changed := false
+	value, err := a.walkStrings(value, func(text string) (string, error) {
+		anonymized, err := a.AnonymizeText(ctx, text)
+		if err == nil && anonymized != text {
+			changed = true
+		}
+		return anonymized, err
+	})
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		return args, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("failed to encode anonymized arguments: %w", err)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// RevealArguments replaces the placeholders in tool call arguments, escaping
+// the values for JSON when the arguments are valid JSON
+func (a *Anonymizer) RevealArguments(ctx context.Context, args string) (string, error) {
+	if !json.Valid([]byte(args)) {
+		return a.RevealText(ctx, args)
+	}
+	if err := a.mapping.load(ctx); err != nil {
+		return "", err
+	}
+
+	return a.mapping.reveal(ctx, args, func(value string) string {
+		escaped, err := json.Marshal(value)
+		if err != nil {
+			return value
+		}
+		return string(escaped[1 : len(escaped)-1])
+	})
+}
+
+func (a *Anonymizer) walkStrings(value any, fn func(string) (string, error)) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case []any:
+		for i, item := range v {
+			res, err := a.walkStrings(item, fn)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = res
+		}
+		return v, nil
+	case map[string]any:
+		for key, item := range v {
+			res, err := a.walkStrings(item, fn)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = res
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// AnonymizeChain returns a copy of chain ready to be sent to a provider: text,
+// tool call arguments and tool results are anonymized. Reasoning signed by a
+// provider is sent back untouched, as the model wrote it with placeholders.
+// Images can not be anonymized, a screenshot shows the values as they are, so
+// they are replaced with a note.
+func (a *Anonymizer) AnonymizeChain(ctx context.Context, chain []llms.MessageContent) ([]llms.MessageContent, error) {
+	result := make([]llms.MessageContent, 0, len(chain))
+	for _, msg := range chain {
+		parts := make([]llms.ContentPart, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			var err error
+			switch p := part.(type) {
+			case llms.TextContent:
+				if p.Text, err = a.AnonymizeText(ctx, p.Text); err != nil {
+					return nil, err
+				}
+				if p.Reasoning != nil && len(p.Reasoning.Signature) == 0 && p.Reasoning.Content != "" {
+					reasoning := *p.Reasoning
+					if reasoning.Content, err = a.AnonymizeText(ctx, reasoning.Content); err != nil {
+						return nil, err
+					}
+					p.Reasoning = &reasoning
+				}
+				part = p
+			case llms.ToolCall:
+				if p.FunctionCall != nil {
+					call := *p.FunctionCall
+					if call.Arguments, err = a.AnonymizeArguments(ctx, call.Arguments); err != nil {
+						return nil, err
+					}
+					p.FunctionCall = &call
+				}
+				part = p
+			case llms.ToolCallResponse:
+				if p.Content, err = a.AnonymizeText(ctx, p.Content); err != nil {
+					return nil, err
+				}
+				part = p
+			case llms.ImageURLContent, llms.BinaryContent:
+				part = llms.TextContent{Text: imageOmittedNote}
+			}
+			parts = append(parts, part)
+		}
+		result = append(result, llms.MessageContent{Role: msg.Role, Parts: parts})
+	}
+
+	return result, nil
+}
+
+// RevealResponse puts the values back into the content and the tool call
+// arguments of a response. Reasoning keeps the placeholders, see
+// AnonymizeChain.
+func (a *Anonymizer) RevealResponse(ctx context.Context, resp *llms.ContentResponse) error {
+	if resp == nil {
+		return nil
+	}
+
+	var err error
+	for _, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		if choice.Content, err = a.RevealText(ctx, choice.Content); err != nil {
+			return err
+		}
+		if choice.FuncCall != nil {
+			call := *choice.FuncCall
+			if call.Arguments, err = a.RevealArguments(ctx, call.Arguments); err != nil {
+				return err
+			}
+			choice.FuncCall = &call
+		}
+		for i := range choice.ToolCalls {
+			if choice.ToolCalls[i].FunctionCall == nil {
+				continue
+			}
+			call := *choice.ToolCalls[i].FunctionCall
+			if call.Arguments, err = a.RevealArguments(ctx, call.Arguments); err != nil {
+				return err
+			}
+			choice.ToolCalls[i].FunctionCall = &call
+		}
+	}
+
+	return nil
+}
+
+// wrapStream reveals the placeholders in streamed text chunks. A placeholder
+// split between two chunks is streamed as it is, the final message is
+// revealed as a whole.
+func (a *Anonymizer) wrapStream(streamCb streaming.Callback) streaming.Callback {
+	if streamCb == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, chunk streaming.Chunk) error {
+		if chunk.Type == streaming.ChunkTypeText {
+			if content, err := a.RevealText(ctx, chunk.Content); err == nil {
+				chunk.Content = content
+			}
+		}
+		return streamCb(ctx, chunk)
+	}
+}
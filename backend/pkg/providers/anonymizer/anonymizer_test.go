@@ -0,0 +1,339 @@
+package anonymizer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/providers/embeddings"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/vault"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+)
+
+// memoryQuerier keeps anonymization patterns and entries the way the queries do
+type memoryQuerier struct {
+	database.Querier
+	patterns []database.AnonymizationPattern
+	entries  []database.FlowAnonymizationEntry
+}
+
+func (m *memoryQuerier) GetUserAnonymizationPatterns(
+	_ context.Context, userID int64,
+) ([]database.AnonymizationPattern, error) {
+	var rows []database.AnonymizationPattern
+	for _, row := range m.patterns {
+		if row.UserID == userID {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (m *memoryQuerier) GetFlowAnonymizationEntries(
+	_ context.Context, flowID int64,
+) ([]database.FlowAnonymizationEntry, error) {
+	var rows []database.FlowAnonymizationEntry
+	for _, row := range m.entries {
+		if row.FlowID == flowID {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (m *memoryQuerier) GetFlowAnonymizationEntry(
+	_ context.Context, arg database.GetFlowAnonymizationEntryParams,
+) (database.FlowAnonymizationEntry, error) {
+	for _, row := range m.entries {
+		if row.ID == arg.ID && row.FlowID == arg.FlowID {
+			return row, nil
+		}
+	}
+	return database.FlowAnonymizationEntry{}, sql.ErrNoRows
+}
+
+func (m *memoryQuerier) UpsertFlowAnonymizationEntry(
+	_ context.Context, arg database.UpsertFlowAnonymizationEntryParams,
+) (database.FlowAnonymizationEntry, error) {
+	for _, row := range m.entries {
+		if row.FlowID == arg.FlowID && bytes.Equal(row.ValueDigest, arg.ValueDigest) {
+			return row, nil
+		}
+	}
+
+	row := database.FlowAnonymizationEntry{
+		ID:          int64(len(m.entries) + 1),
+		FlowID:      arg.FlowID,
+		Kind:        arg.Kind,
+		Value:       arg.Value,
+		ValueDigest: arg.ValueDigest,
+	}
+	m.entries = append(m.entries, row)
+	return row, nil
+}
+
+// recordingProvider remembers the chain it got and answers with a tool call
+type recordingProvider struct {
+	provider.Provider
+	chain []llms.MessageContent
+	args  string
+}
+
+func (p *recordingProvider) CallWithTools(
+	ctx context.Context,
+	_ pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	_ []llms.Tool,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	p.chain = chain
+	if streamCb != nil {
+		if err := streamCb(ctx, streaming.Chunk{Type: streaming.ChunkTypeText, Content: "scanning"}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+		ToolCalls: []llms.ToolCall{{
+			ID:           "call_1",
+			FunctionCall: &llms.FunctionCall{Name: "terminal", Arguments: p.args},
+		}},
+	}}}, nil
+}
+
+func newTestAnonymizer(t *testing.T, db *memoryQuerier, flowID int64) *Anonymizer {
+	t.Helper()
+
+	cfg := &config.Config{CredentialVaultKey: "test-vault-key"}
+	anon, err := New(context.Background(), db, cfg, vault.New(db, cfg), flowID, 1)
+	require.NoError(t, err)
+
+	return anon
+}
+
+func TestAnonymizeText(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := &memoryQuerier{}
+	anon := newTestAnonymizer(t, db, 1)
+
+	text := "nmap -sV 203.0.113.7 && ping 203.0.113.7"
+	anonymized, err := anon.AnonymizeText(ctx, text)
+	require.NoError(t, err)
+	assert.NotContains(t, anonymized, "203.0.113.7")
+
+	matches := placeholderRegex.FindAllString(anonymized, -1)
+	require.Len(t, matches, 2)
+	assert.Equal(t, matches[0], matches[1], "a value keeps its placeholder")
+
+	again, err := anon.AnonymizeText(ctx, anonymized)
+	require.NoError(t, err)
+	assert.Equal(t, anonymized, again, "placeholders are not anonymized a second time")
+
+	revealed, err := anon.RevealText(ctx, anonymized)
+	require.NoError(t, err)
+	assert.Equal(t, text, revealed)
+
+	// another anonymizer of the flow, e.g. after a restart, uses the same mapping
+	restored := newTestAnonymizer(t, db, 1)
+	restoredText, err := restored.AnonymizeText(ctx, "ping -c 1 203.0.113.7")
+	require.NoError(t, err)
+	assert.Contains(t, restoredText, matches[0])
+	require.Len(t, db.entries, 1)
+
+	// the entries of another flow stay unknown
+	other := newTestAnonymizer(t, db, 2)
+	unknown, err := other.RevealText(ctx, anonymized)
+	require.NoError(t, err)
+	assert.Equal(t, anonymized, unknown)
+}
+
+func TestRevealArguments(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := &memoryQuerier{patterns: []database.AnonymizationPattern{
+		{ID: 1, UserID: 1, Name: "Lab Secret", Regex: `lab"secret\d+`, Enabled: true},
+	}}
+	anon := newTestAnonymizer(t, db, 1)
+
+	anonymized, err := anon.AnonymizeText(ctx, `found lab"secret42 in config`)
+	require.NoError(t, err)
+	placeholder := placeholderRegex.FindString(anonymized)
+	require.Equal(t, Placeholder("Lab Secret", 1), placeholder)
+
+	args, err := anon.RevealArguments(ctx, `{"input":"echo `+placeholder+`"}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{"input":"echo lab\"secret42"}`, args, "values are JSON-escaped")
+
+	text, err := anon.RevealArguments(ctx, "echo "+placeholder)
+	require.NoError(t, err)
+	assert.Equal(t, `echo lab"secret42`, text)
+
+	// the escaped value in JSON arguments gets the placeholder of the value
+	anonymizedArgs, err := anon.AnonymizeArguments(ctx, `{"input":"echo lab\"secret42","timeout":60}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{"input":"echo `+placeholder+`","timeout":60}`, anonymizedArgs)
+}
+
+func TestAnonymizedProvider(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	anon := newTestAnonymizer(t, &memoryQuerier{}, 1)
+
+	placeholder, err := anon.AnonymizeText(ctx, "203.0.113.7")
+	require.NoError(t, err)
+
+	inner := &recordingProvider{args: `{"input":"nmap ` + placeholder + `"}`}
+	prv := anon.Wrap(inner)
+	assert.Same(t, inner, anon.Wrap(prv).(*anonymizedProvider).Provider, "providers are not wrapped twice")
+	assert.Nil(t, (*Anonymizer)(nil).Wrap(nil))
+	assert.Same(t, inner, (*Anonymizer)(nil).Wrap(inner), "without an anonymizer the provider is not wrapped")
+
+	chain := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeHuman, "scan the host 203.0.113.7"),
+		{Role: llms.ChatMessageTypeTool, Parts: []llms.ContentPart{llms.ToolCallResponse{
+			ToolCallID: "call_0",
+			Name:       "terminal",
+			Content:    "203.0.113.7 is up",
+		}}},
+	}
+
+	var streamed []string
+	resp, err := prv.CallWithTools(ctx, pconfig.OptionsTypePentester, chain, nil,
+		func(_ context.Context, chunk streaming.Chunk) error {
+			streamed = append(streamed, chunk.Content)
+			return nil
+		})
+	require.NoError(t, err)
+
+	assert.Equal(t, "scan the host "+placeholder, inner.chain[0].Parts[0].(llms.TextContent).Text)
+	assert.Equal(t, placeholder+" is up", inner.chain[1].Parts[0].(llms.ToolCallResponse).Content)
+	assert.Equal(t, "scan the host 203.0.113.7", chain[0].Parts[0].(llms.TextContent).Text,
+		"the chain of the caller is not changed")
+	assert.Equal(t, []string{"scanning"}, streamed)
+
+	require.Len(t, resp.Choices, 1)
+	require.Len(t, resp.Choices[0].ToolCalls, 1)
+	assert.Equal(t, `{"input":"nmap 203.0.113.7"}`, resp.Choices[0].ToolCalls[0].FunctionCall.Arguments)
+}
+
+func TestAnonymizeChainImages(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	anon := newTestAnonymizer(t, &memoryQuerier{}, 1)
+
+	chain := []llms.MessageContent{{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{
+		llms.TextContent{Text: "screenshot of http://203.0.113.7/login"},
+		llms.BinaryPart("image/png", []byte("\x89PNG 203.0.113.7")),
+		llms.ImageURLPart("data:image/png;base64,iVBORw0KGgo="),
+	}}}
+
+	anonymized, err := anon.AnonymizeChain(ctx, chain)
+	require.NoError(t, err)
+	require.Len(t, anonymized, 1)
+
+	parts := anonymized[0].Parts
+	require.Len(t, parts, 3)
+	assert.NotContains(t, parts[0].(llms.TextContent).Text, "203.0.113.7")
+	for _, part := range parts[1:] {
+		assert.Equal(t, llms.TextContent{Text: imageOmittedNote}, part, "images never reach the provider")
+	}
+	assert.IsType(t, llms.BinaryContent{}, chain[0].Parts[1], "the chain of the caller is not changed")
+}
+
+// recordingEmbedder remembers the texts it got
+type recordingEmbedder struct {
+	embeddings.Embedder
+	texts []string
+}
+
+func (e *recordingEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	e.texts = append(e.texts, texts...)
+	return make([][]float32, len(texts)), nil
+}
+
+func (e *recordingEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	e.texts = append(e.texts, text)
+	return nil, nil
+}
+
+func TestAnonymizedEmbedder(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	anon := newTestAnonymizer(t, &memoryQuerier{}, 1)
+
+	placeholder, err := anon.AnonymizeText(ctx, "203.0.113.7")
+	require.NoError(t, err)
+
+	inner := &recordingEmbedder{}
+	embedder := anon.WrapEmbedder(inner)
+	assert.Same(t, inner, anon.WrapEmbedder(embedder).(*anonymizedEmbedder).Embedder, "embedders are not wrapped twice")
+	assert.Same(t, inner, (*Anonymizer)(nil).WrapEmbedder(inner), "without an anonymizer the embedder is not wrapped")
+
+	vectors, err := embedder.EmbedDocuments(ctx, []string{"nmap 203.0.113.7", "no values"})
+	require.NoError(t, err)
+	assert.Len(t, vectors, 2)
+
+	_, err = embedder.EmbedQuery(ctx, "open ports of 203.0.113.7")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"nmap " + placeholder, "no values", "open ports of " + placeholder}, inner.texts)
+
+	db := &memoryQuerier{}
+	unavailable, err := New(ctx, db, &config.Config{}, vault.New(db, &config.Config{}), 1, 1)
+	require.NoError(t, err)
+	_, err = unavailable.WrapEmbedder(inner).EmbedQuery(ctx, "ping 203.0.113.7")
+	assert.ErrorIs(t, err, vault.ErrNotAvailable)
+}
+
+func TestAnonymizerNeedsVault(t *testing.T) {
+	t.Parallel()
+
+	db := &memoryQuerier{}
+	anon, err := New(context.Background(), db, &config.Config{}, vault.New(db, &config.Config{}), 1, 1)
+	require.NoError(t, err)
+
+	_, err = anon.AnonymizeText(context.Background(), "ping 203.0.113.7")
+	assert.ErrorIs(t, err, vault.ErrNotAvailable, "values are never sent when the mapping can not be stored")
+}
+
+func TestMergePatterns(t *testing.T) {
+	t.Parallel()
+
+	builtin := []Pattern{
+		{Name: "IPv4 Address", Regex: `ipv4`},
+		{Name: "Email Address", Regex: `email`},
+		{Name: "Domain Name Enhanced", Regex: `domain`},
+	}
+	merged := MergePatterns(builtin, []database.AnonymizationPattern{
+		{Name: "Email Address", Enabled: false},
+		{Name: "Domain Name Enhanced", Regex: `lab\.local`, Enabled: true},
+		{Name: "Lab Token", Regex: `tok-\w+`, Enabled: true},
+		{Name: "Unused", Regex: `unused`, Enabled: false},
+	})
+
+	assert.Equal(t, []Pattern{
+		{Name: "IPv4 Address", Regex: `ipv4`},
+		{Name: "Domain Name Enhanced", Regex: `lab\.local`},
+		{Name: "Lab Token", Regex: `tok-\w+`},
+	}, merged)
+
+	assert.NoError(t, ValidatePattern(builtin, "Email Address", ""))
+	assert.Error(t, ValidatePattern(builtin, "Lab Token", ""))
+	assert.Error(t, ValidatePattern(builtin, " ", `tok`))
+	assert.Error(t, ValidatePattern(builtin, "Lab Token", `tok-(`))
+}
@@ -0,0 +1,50 @@
+package anonymizer
+
+import (
+	"context"
+
+	"pentagi/pkg/providers/embeddings"
+)
+
+// anonymizedEmbedder sends the texts of the wrapped embedder through the
+// anonymizer, the embedding provider gets the placeholders instead of the
+// values while the vector store keeps the documents as they are
+type anonymizedEmbedder struct {
+	embeddings.Embedder
+	anonymizer *Anonymizer
+}
+
+// WrapEmbedder returns e with its input anonymized. A nil anonymizer (the
+// pipeline is off) returns e as it is.
+func (a *Anonymizer) WrapEmbedder(e embeddings.Embedder) embeddings.Embedder {
+	if a == nil || e == nil {
+		return e
+	}
+	if wrapped, ok := e.(*anonymizedEmbedder); ok {
+		e = wrapped.Embedder
+	}
+
+	return &anonymizedEmbedder{Embedder: e, anonymizer: a}
+}
+
+func (e *anonymizedEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	anonymized := make([]string, 0, len(texts))
+	for _, text := range texts {
+		text, err := e.anonymizer.AnonymizeText(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		anonymized = append(anonymized, text)
+	}
+
+	return e.Embedder.EmbedDocuments(ctx, anonymized)
+}
+
+func (e *anonymizedEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	text, err := e.anonymizer.AnonymizeText(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.Embedder.EmbedQuery(ctx, text)
+}
@@ -0,0 +1,208 @@
+package anonymizer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/vault"
+)
+
+// mappingPurpose keeps the sealed values apart from other per-flow data
+const mappingPurpose = "llm-anonymization"
+
+var (
+	placeholderRegex = regexp.MustCompile(`\{\{anon:([a-z0-9-]+)-(\d+)\}\}`)
+	kindSlugRegex    = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Placeholder is what the LLM sees instead of a value: the kind of the value
+// and the ID of its mapping entry, e.g. {{anon:ipv4-address-12}}
+func Placeholder(kind string, id int64) string {
+	slug := strings.Trim(kindSlugRegex.ReplaceAllString(strings.ToLower(kind), "-"), "-")
+	if slug == "" {
+		slug = "value"
+	}
+	return fmt.Sprintf("{{anon:%s-%d}}", slug, id)
+}
+
+// mapping is the reversible value to placeholder mapping of a flow. Entries
+// are sealed in the database, so placeholders stay the same across restarts
+// and between the providers of one flow (the flow and its assistants).
+type mapping struct {
+	db     database.Querier
+	vault  *vault.Vault
+	flowID int64
+
+	mx           sync.RWMutex
+	loaded       bool
+	placeholders map[string]string // value -> placeholder
+	values       map[string]string // placeholder -> value
+}
+
+func newMapping(db database.Querier, vlt *vault.Vault, flowID int64) *mapping {
+	return &mapping{
+		db:           db,
+		vault:        vlt,
+		flowID:       flowID,
+		placeholders: make(map[string]string),
+		values:       make(map[string]string),
+	}
+}
+
+func (m *mapping) load(ctx context.Context) error {
+	m.mx.RLock()
+	loaded := m.loaded
+	m.mx.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	if !m.vault.IsAvailable() {
+		return fmt.Errorf("anonymization mapping can not be stored: %w", vault.ErrNotAvailable)
+	}
+
+	entries, err := m.db.GetFlowAnonymizationEntries(ctx, m.flowID)
+	if err != nil {
+		return fmt.Errorf("failed to get flow anonymization entries: %w", err)
+	}
+
+	for _, entry := range entries {
+		if err := m.remember(entry); err != nil {
+			return err
+		}
+	}
+
+	m.mx.Lock()
+	m.loaded = true
+	m.mx.Unlock()
+
+	return nil
+}
+
+// placeholder returns the placeholder of value, creating its entry when the
+// flow has not seen the value yet
+func (m *mapping) placeholder(ctx context.Context, kind, value string) (string, error) {
+	m.mx.RLock()
+	placeholder, ok := m.placeholders[value]
+	m.mx.RUnlock()
+	if ok {
+		return placeholder, nil
+	}
+
+	sealed, err := m.vault.SealFlowData(m.flowID, mappingPurpose, []byte(value))
+	if err != nil {
+		return "", fmt.Errorf("failed to seal anonymized value: %w", err)
+	}
+	digest, err := m.vault.FlowDigest(m.flowID, mappingPurpose, []byte(value))
+	if err != nil {
+		return "", fmt.Errorf("failed to digest anonymized value: %w", err)
+	}
+
+	entry, err := m.db.UpsertFlowAnonymizationEntry(ctx, database.UpsertFlowAnonymizationEntryParams{
+		FlowID:      m.flowID,
+		Kind:        kind,
+		Value:       sealed,
+		ValueDigest: digest,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store anonymization entry: %w", err)
+	}
+
+	// the entry may be an older one with another kind, its placeholder wins
+	if err := m.remember(entry); err != nil {
+		return "", err
+	}
+
+	return Placeholder(entry.Kind, entry.ID), nil
+}
+
+// value returns the value behind a placeholder; a placeholder of this flow
+// which another provider created is looked up in the database
+func (m *mapping) value(ctx context.Context, placeholder string, id int64) (string, bool, error) {
+	m.mx.RLock()
+	value, ok := m.values[placeholder]
+	m.mx.RUnlock()
+	if ok {
+		return value, true, nil
+	}
+
+	entry, err := m.db.GetFlowAnonymizationEntry(ctx, database.GetFlowAnonymizationEntryParams{
+		ID:     id,
+		FlowID: m.flowID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("failed to get anonymization entry %d: %w", id, err)
+	}
+
+	if err := m.remember(entry); err != nil {
+		return "", false, err
+	}
+
+	m.mx.RLock()
+	value, ok = m.values[placeholder]
+	m.mx.RUnlock()
+
+	return value, ok, nil
+}
+
+func (m *mapping) remember(entry database.FlowAnonymizationEntry) error {
+	value, err := m.vault.OpenFlowData(m.flowID, mappingPurpose, entry.Value)
+	if err != nil {
+		return fmt.Errorf("failed to open anonymization entry %d: %w", entry.ID, err)
+	}
+
+	placeholder := Placeholder(entry.Kind, entry.ID)
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.placeholders[string(value)] = placeholder
+	m.values[placeholder] = string(value)
+
+	return nil
+}
+
+// reveal replaces the placeholders of this flow in text with their values,
+// escape prepares a value for the text it goes into. A placeholder the flow
+// does not know stays as it is.
+func (m *mapping) reveal(ctx context.Context, text string, escape func(string) string) (string, error) {
+	matches := placeholderRegex.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return text, nil
+	}
+
+	values := make(map[string]string, len(matches))
+	for _, match := range matches {
+		if _, ok := values[match[0]]; ok {
+			continue
+		}
+
+		id, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		value, ok, err := m.value(ctx, match[0], id)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			values[match[0]] = escape(value)
+		}
+	}
+
+	return placeholderRegex.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if value, ok := values[placeholder]; ok {
+			return value
+		}
+		return placeholder
+	}), nil
+}
@@ -0,0 +1,191 @@
+package anonymizer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"pentagi/pkg/database"
+
+	"github.com/vxcontrol/cloud/anonymizer/patterns"
+	"github.com/wasilibs/go-re2"
+	"github.com/wasilibs/go-re2/experimental"
+)
+
+type Pattern = patterns.Pattern
+
+// BuiltinPatterns are the patterns of the anonymizer library, the ones a user
+// can replace or turn off
+func BuiltinPatterns() ([]Pattern, error) {
+	all, err := patterns.LoadPatterns(patterns.PatternListTypeAll)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load anonymizer patterns: %w", err)
+	}
+
+	return all.Patterns, nil
+}
+
+// MergePatterns applies the patterns of a user to the built-in ones: a user
+// pattern named like a built-in one replaces it, or removes it when disabled,
+// any other enabled pattern is added.
+func MergePatterns(builtin []Pattern, user []database.AnonymizationPattern) []Pattern {
+	overrides := make(map[string]database.AnonymizationPattern, len(user))
+	for _, up := range user {
+		overrides[up.Name] = up
+	}
+
+	result := make([]Pattern, 0, len(builtin)+len(user))
+	for _, pt := range builtin {
+		up, ok := overrides[pt.Name]
+		if !ok {
+			result = append(result, pt)
+			continue
+		}
+		delete(overrides, pt.Name)
+		if !up.Enabled {
+			continue
+		}
+		if up.Regex != "" {
+			pt.Regex = up.Regex
+		}
+		result = append(result, pt)
+	}
+
+	for _, up := range user {
+		if _, ok := overrides[up.Name]; ok && up.Enabled && up.Regex != "" {
+			result = append(result, Pattern{Name: up.Name, Regex: up.Regex})
+		}
+	}
+
+	return result
+}
+
+// ValidatePattern checks a user pattern before it is stored. The regex may be
+// empty only for a pattern which turns a built-in one off or keeps its regex.
+func ValidatePattern(builtin []Pattern, name, regex string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("pattern name must not be empty")
+	}
+
+	if regex == "" {
+		for _, pt := range builtin {
+			if pt.Name == name {
+				return nil
+			}
+		}
+		return fmt.Errorf("pattern '%s' is not a built-in pattern and needs a regex", name)
+	}
+
+	if _, err := re2.Compile(regex); err != nil {
+		return fmt.Errorf("invalid regex of pattern '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// span is a sensitive value found in a text
+type span struct {
+	start int
+	end   int
+	kind  string
+}
+
+// matcher finds the values to anonymize. Like the replacer of the anonymizer
+// library it replaces only the "replace" group of a pattern (or the first
+// group), so the context a pattern needs, e.g. "password=", stays in place.
+type matcher struct {
+	patterns []Pattern
+	regexes  []*re2.Regexp
+	groups   []int
+	set      *experimental.Set
+	mx       sync.Mutex
+}
+
+func newMatcher(pts []Pattern) (*matcher, error) {
+	m := &matcher{}
+	if len(pts) == 0 {
+		return m, nil
+	}
+
+	exprs := make([]string, 0, len(pts))
+	for _, pt := range pts {
+		re, err := re2.Compile(pt.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile pattern '%s': %w", pt.Name, err)
+		}
+
+		group := re.SubexpIndex("replace")
+		if group == -1 {
+			group = min(1, re.NumSubexp())
+		}
+
+		m.patterns = append(m.patterns, pt)
+		m.regexes = append(m.regexes, re)
+		m.groups = append(m.groups, group)
+		exprs = append(exprs, pt.Regex)
+	}
+
+	set, err := experimental.CompileSet(exprs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile pattern set: %w", err)
+	}
+	m.set = set
+
+	return m, nil
+}
+
+// find returns the values in text ordered by position. A value overlapping
+// one of the excluded ranges, an earlier or a longer value is dropped.
+func (m *matcher) find(text string, exclude [][]int) []span {
+	if m.set == nil || text == "" {
+		return nil
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	var spans []span
+	for _, idx := range m.set.FindAllString(text, len(m.regexes)) {
+		group := m.groups[idx]
+		for _, loc := range m.regexes[idx].FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[2*group], loc[2*group+1]
+			if start < 0 || start == end || overlaps(start, end, exclude) {
+				continue
+			}
+			spans = append(spans, span{start: start, end: end, kind: m.patterns[idx].Name})
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		if spans[i].end != spans[j].end {
+			return spans[i].end > spans[j].end
+		}
+		return spans[i].kind < spans[j].kind
+	})
+
+	result := spans[:0]
+	last := 0
+	for _, sp := range spans {
+		if sp.start < last {
+			continue
+		}
+		result = append(result, sp)
+		last = sp.end
+	}
+
+	return result
+}
+
+func overlaps(start, end int, ranges [][]int) bool {
+	for _, r := range ranges {
+		if start < r[1] && r[0] < end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package anonymizer
+
+import (
+	"context"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+)
+
+// anonymizedProvider sends every call of the wrapped provider through the
+// anonymizer, the other methods are the wrapped provider's own
+type anonymizedProvider struct {
+	provider.Provider
+	anonymizer *Anonymizer
+}
+
+// Wrap returns prv with its LLM calls anonymized. A nil anonymizer (the
+// pipeline is off) returns prv as it is.
+func (a *Anonymizer) Wrap(prv provider.Provider) provider.Provider {
+	if a == nil || prv == nil {
+		return prv
+	}
+	if wrapped, ok := prv.(*anonymizedProvider); ok {
+		prv = wrapped.Provider
+	}
+
+	return &anonymizedProvider{Provider: prv, anonymizer: a}
+}
+
+func (p *anonymizedProvider) Call(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	prompt string,
+) (string, error) {
+	prompt, err := p.anonymizer.AnonymizeText(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := p.Provider.Call(ctx, opt, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	return p.anonymizer.RevealText(ctx, result)
+}
+
+func (p *anonymizedProvider) CallEx(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	chain, err := p.anonymizer.AnonymizeChain(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Provider.CallEx(ctx, opt, chain, p.anonymizer.wrapStream(streamCb))
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, p.anonymizer.RevealResponse(ctx, resp)
+}
+
+func (p *anonymizedProvider) CallWithTools(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	tools []llms.Tool,
+	streamCb streaming.Callback,
+) (*llms.ContentResponse, error) {
+	chain, err := p.anonymizer.AnonymizeChain(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Provider.CallWithTools(ctx, opt, chain, tools, p.anonymizer.wrapStream(streamCb))
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, p.anonymizer.RevealResponse(ctx, resp)
+}
+
+func (p *anonymizedProvider) CallWithExtraOptions(
+	ctx context.Context,
+	opt pconfig.ProviderOptionsType,
+	chain []llms.MessageContent,
+	tools []llms.Tool,
+	streamCb streaming.Callback,
+	extra ...llms.CallOption,
+) (*llms.ContentResponse, error) {
+	chain, err := p.anonymizer.AnonymizeChain(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Provider.CallWithExtraOptions(ctx, opt, chain, tools, p.anonymizer.wrapStream(streamCb), extra...)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, p.anonymizer.RevealResponse(ctx, resp)
+}
@@ -84,13 +84,6 @@ func New(
 		anthropic.WithModel(AnthropicAgentModel),
 		anthropic.WithBaseURL(baseURL),
 		anthropic.WithHTTPClient(httpClient),
-		// Enable prompt caching for cost optimization (90% savings on cached reads)
-		anthropic.WithDefaultCacheStrategy(anthropic.CacheStrategy{
-			CacheTools:    true,
-			CacheSystem:   true,
-			CacheMessages: true,
-			TTL:           "5m",
-		}),
 	)
 	if err != nil {
 		return nil, err
@@ -149,7 +142,7 @@ func (p *anthropicProvider) Call(
 	prompt string,
 ) (string, error) {
 	ctx, options := p.providerConfig.PrepareAdaptiveCallOptions(
-		ctx, p.models, opt, p.providerConfig.GetOptionsForType(opt),
+		ctx, p.models, opt, p.callOptions(opt),
 	)
 	return provider.WrapGenerateFromSinglePrompt(
 		ctx, p, opt, p.llm, prompt,
@@ -165,7 +158,7 @@ func (p *anthropicProvider) CallEx(
 ) (*llms.ContentResponse, error) {
 	ctx, options := p.providerConfig.PrepareAdaptiveCallOptions(ctx, p.models, opt, append([]llms.CallOption{
 		llms.WithStreamingFunc(streamCb),
-	}, p.providerConfig.GetOptionsForType(opt)...))
+	}, p.callOptions(opt)...))
 	return provider.WrapGenerateContent(
 		ctx, p, opt, p.llm.GenerateContent, chain,
 		options...,
@@ -182,7 +175,7 @@ func (p *anthropicProvider) CallWithTools(
 	ctx, options := p.providerConfig.PrepareAdaptiveCallOptions(ctx, p.models, opt, append([]llms.CallOption{
 		llms.WithTools(tools),
 		llms.WithStreamingFunc(streamCb),
-	}, p.providerConfig.GetOptionsForType(opt)...))
+	}, p.callOptions(opt)...))
 	return provider.WrapGenerateContent(
 		ctx, p, opt, p.llm.GenerateContent, chain,
 		options...,
@@ -203,7 +196,7 @@ func (p *anthropicProvider) CallWithExtraOptions(
 	if len(tools) > 0 {
 		base = append(base, llms.WithTools(tools))
 	}
-	base = append(base, p.providerConfig.GetOptionsForType(opt)...)
+	base = append(base, p.callOptions(opt)...)
 
 	ctx, options := p.providerConfig.PrepareAdaptiveCallOptions(ctx, p.models, opt, base)
 	options = append(options, extra...)
@@ -211,6 +204,38 @@ func (p *anthropicProvider) CallWithExtraOptions(
 	return provider.WrapGenerateContent(ctx, p, opt, p.llm.GenerateContent, chain, options...)
 }
 
+// defaultCacheStrategy is used for agents without a cache section: prompt
+// caching saves up to 90% on cached reads, so every stable prefix is marked.
+var defaultCacheStrategy = anthropic.CacheStrategy{
+	CacheTools:    true,
+	CacheSystem:   true,
+	CacheMessages: true,
+	TTL:           string(pconfig.CacheTTL5Minutes),
+}
+
+// callOptions returns the agent's configured options followed by its prompt
+// cache breakpoints: tools, then system, then the last message of the history.
+// The strategy is set per call rather than on the client because the client
+// level one is OR-merged and could not be switched off for a single agent.
+func (p *anthropicProvider) callOptions(opt pconfig.ProviderOptionsType) []llms.CallOption {
+	options := p.providerConfig.GetOptionsForType(opt)
+
+	strategy := defaultCacheStrategy
+	if cache := p.providerConfig.CacheConfigForType(opt); cache != nil {
+		if !cache.IsEnabled() {
+			return options
+		}
+		strategy = anthropic.CacheStrategy{
+			CacheTools:    cache.Tools,
+			CacheSystem:   cache.System,
+			CacheMessages: cache.History,
+			TTL:           string(cache.TTL),
+		}
+	}
+
+	return append(options, anthropic.WithCacheStrategy(strategy))
+}
+
 func (p *anthropicProvider) GetUsage(info map[string]any) pconfig.CallUsage {
 	return pconfig.NewCallUsage(info)
 }
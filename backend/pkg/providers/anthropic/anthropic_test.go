@@ -1,11 +1,15 @@
 package anthropic
 
 import (
+	"encoding/json"
 	"testing"
 
 	"pentagi/pkg/config"
 	"pentagi/pkg/providers/pconfig"
 	"pentagi/pkg/providers/provider"
+
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/anthropic"
 )
 
 func TestConfigLoading(t *testing.T) {
@@ -104,3 +108,45 @@ func TestModelsLoading(t *testing.T) {
 		}
 	}
 }
+
+func TestCallOptionsCacheStrategy(t *testing.T) {
+	strategyFor := func(t *testing.T, cache string) (anthropic.CacheStrategy, bool) {
+		t.Helper()
+
+		var providerConfig pconfig.ProviderConfig
+		data := `{"simple": {"model": "claude-sonnet-4-5"` + cache + `}}`
+		if err := json.Unmarshal([]byte(data), &providerConfig); err != nil {
+			t.Fatalf("failed to parse config: %v", err)
+		}
+
+		prov := &anthropicProvider{providerConfig: &providerConfig}
+		opts := llms.CallOptions{}
+		for _, option := range prov.callOptions(pconfig.OptionsTypeSimple) {
+			option(&opts)
+		}
+
+		strategy, ok := opts.Metadata["anthropic:cache_strategy"].(anthropic.CacheStrategy)
+		return strategy, ok
+	}
+
+	t.Run("default", func(t *testing.T) {
+		strategy, ok := strategyFor(t, "")
+		if !ok || strategy != defaultCacheStrategy {
+			t.Errorf("expected default cache strategy, got %+v (set: %v)", strategy, ok)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		if strategy, ok := strategyFor(t, `, "cache": {}`); ok {
+			t.Errorf("expected no cache strategy, got %+v", strategy)
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		strategy, ok := strategyFor(t, `, "cache": {"system": true, "ttl": "1h"}`)
+		expected := anthropic.CacheStrategy{CacheSystem: true, TTL: "1h"}
+		if !ok || strategy != expected {
+			t.Errorf("expected %+v, got %+v (set: %v)", expected, strategy, ok)
+		}
+	})
+}
@@ -246,6 +246,7 @@ func (p *bedrockProvider) CallEx(
 	options = append(options, configOptions...)
 	options = append(options, llms.WithTools(tools))
 	ctx, options = p.providerConfig.PrepareAdaptiveCallOptions(ctx, p.models, opt, options)
+	chain = applyCacheBreakpoint(chain, p.providerConfig.CacheConfigForType(opt))
 
 	return provider.WrapGenerateContent(ctx, p, opt, p.llm.GenerateContent, chain, options...)
 }
@@ -271,6 +272,7 @@ func (p *bedrockProvider) CallWithTools(
 	// Put cleaned tools after config to override any dirty tools restored from config.
 	options := append(configOptions, llms.WithStreamingFunc(streamCb), llms.WithTools(tools))
 	ctx, options = p.providerConfig.PrepareAdaptiveCallOptions(ctx, p.models, opt, options)
+	chain = applyCacheBreakpoint(chain, p.providerConfig.CacheConfigForType(opt))
 
 	return provider.WrapGenerateContent(ctx, p, opt, p.llm.GenerateContent, chain, options...)
 }
@@ -298,6 +300,7 @@ func (p *bedrockProvider) CallWithExtraOptions(
 
 	ctx, options := p.providerConfig.PrepareAdaptiveCallOptions(ctx, p.models, opt, base)
 	options = append(options, extra...)
+	chain = applyCacheBreakpoint(chain, p.providerConfig.CacheConfigForType(opt))
 
 	return provider.WrapGenerateContent(ctx, p, opt, p.llm.GenerateContent, chain, options...)
 }
@@ -310,6 +313,76 @@ func (p *bedrockProvider) GetToolCallIDTemplate(ctx context.Context, prompter te
 	return provider.DetermineToolCallIDTemplate(ctx, p, pconfig.OptionsTypeSimple, prompter, BedrockToolCallIDTemplate)
 }
 
+// applyCacheBreakpoint marks the chain for Bedrock prompt caching when the
+// agent enables any cache breakpoint. The Converse API turns caching on for the
+// whole request once a single part carries cache control, and then places its
+// own cache points after the system prompt and after the last message, so one
+// marker on the system prompt (or on the last human text without one) covers
+// both the system and the history breakpoints. The input chain is not modified.
+func applyCacheBreakpoint(chain []llms.MessageContent, cache *pconfig.CacheConfig) []llms.MessageContent {
+	if !cache.IsEnabled() {
+		return chain
+	}
+
+	msgIdx, partIdx := findCacheableTextPart(chain, llms.ChatMessageTypeSystem)
+	if msgIdx < 0 {
+		msgIdx, partIdx = findCacheableTextPart(chain, llms.ChatMessageTypeHuman)
+	}
+	if msgIdx < 0 {
+		return chain
+	}
+
+	result := make([]llms.MessageContent, len(chain))
+	copy(result, chain)
+
+	parts := make([]llms.ContentPart, len(chain[msgIdx].Parts))
+	copy(parts, chain[msgIdx].Parts)
+	parts[partIdx] = bedrock.WithCacheControl(parts[partIdx], &llms.CacheControl{
+		Type:     "ephemeral",
+		Duration: cache.Duration(),
+	})
+	result[msgIdx].Parts = parts
+
+	return result
+}
+
+// findCacheableTextPart returns the position of the last non-empty text part
+// in the first message of the role for system prompts, or in the last message
+// of the role otherwise; -1 if that message has no text.
+func findCacheableTextPart(chain []llms.MessageContent, role llms.ChatMessageType) (int, int) {
+	lastTextPart := func(msg llms.MessageContent) int {
+		for idx := len(msg.Parts) - 1; idx >= 0; idx-- {
+			if text, ok := msg.Parts[idx].(llms.TextContent); ok && text.Text != "" {
+				return idx
+			}
+		}
+		return -1
+	}
+
+	if role == llms.ChatMessageTypeSystem {
+		for msgIdx, msg := range chain {
+			if msg.Role == role {
+				if partIdx := lastTextPart(msg); partIdx >= 0 {
+					return msgIdx, partIdx
+				}
+				return -1, -1
+			}
+		}
+		return -1, -1
+	}
+
+	for msgIdx := len(chain) - 1; msgIdx >= 0; msgIdx-- {
+		if chain[msgIdx].Role == role {
+			if partIdx := lastTextPart(chain[msgIdx]); partIdx >= 0 {
+				return msgIdx, partIdx
+			}
+			return -1, -1
+		}
+	}
+
+	return -1, -1
+}
+
 func extractToolsFromOptions(options []llms.CallOption) []llms.Tool {
 	var opts llms.CallOptions
 
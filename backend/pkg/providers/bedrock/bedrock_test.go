@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sort"
 	"testing"
+	"time"
 
 	"pentagi/pkg/config"
 	"pentagi/pkg/providers/pconfig"
@@ -1260,3 +1261,59 @@ func createToolWithJsonSchemaType(name string) llms.Tool {
 		},
 	}
 }
+
+func TestApplyCacheBreakpoint(t *testing.T) {
+	chain := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, "system prompt"),
+		llms.TextParts(llms.ChatMessageTypeHuman, "first question"),
+		llms.TextParts(llms.ChatMessageTypeAI, "answer"),
+		llms.TextParts(llms.ChatMessageTypeHuman, "second question"),
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		for _, cache := range []*pconfig.CacheConfig{nil, {TTL: pconfig.CacheTTLOneHour}} {
+			result := applyCacheBreakpoint(chain, cache)
+			if _, ok := result[0].Parts[0].(bedrock.CachedContent); ok {
+				t.Errorf("expected no cache control for %+v", cache)
+			}
+		}
+	})
+
+	t.Run("system prompt", func(t *testing.T) {
+		result := applyCacheBreakpoint(chain, &pconfig.CacheConfig{History: true, TTL: pconfig.CacheTTLOneHour})
+		cached, ok := result[0].Parts[0].(bedrock.CachedContent)
+		if !ok {
+			t.Fatalf("expected system prompt to carry cache control, got %T", result[0].Parts[0])
+		}
+		if cached.CacheControl.Duration != time.Hour {
+			t.Errorf("expected 1h cache duration, got %v", cached.CacheControl.Duration)
+		}
+		if text, ok := cached.ContentPart.(llms.TextContent); !ok || text.Text != "system prompt" {
+			t.Errorf("expected wrapped system prompt, got %v", cached.ContentPart)
+		}
+		if _, ok := chain[0].Parts[0].(llms.TextContent); !ok {
+			t.Error("expected input chain to stay unmodified")
+		}
+	})
+
+	t.Run("without system prompt", func(t *testing.T) {
+		result := applyCacheBreakpoint(chain[1:], &pconfig.CacheConfig{System: true})
+		if _, ok := result[2].Parts[0].(bedrock.CachedContent); !ok {
+			t.Errorf("expected last human message to carry cache control, got %T", result[2].Parts[0])
+		}
+		if _, ok := result[0].Parts[0].(bedrock.CachedContent); ok {
+			t.Error("expected earlier human message to stay unmarked")
+		}
+	})
+
+	t.Run("no text", func(t *testing.T) {
+		imageChain := []llms.MessageContent{{
+			Role:  llms.ChatMessageTypeHuman,
+			Parts: []llms.ContentPart{llms.BinaryContent{MIMEType: "image/png", Data: []byte{1}}},
+		}}
+		result := applyCacheBreakpoint(imageChain, &pconfig.CacheConfig{System: true})
+		if _, ok := result[0].Parts[0].(llms.BinaryContent); !ok {
+			t.Errorf("expected chain without text to stay unmarked, got %T", result[0].Parts[0])
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package providers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester"
+
+	"github.com/sirupsen/logrus"
+)
+
+// errToolCallArgsNotObject is the error handed to the tool call fixer when
+// arguments are repaired before execution, so its prompt explains what is
+// wrong the same way a real tool error would.
+var errToolCallArgsNotObject = errors.New("tool call arguments must be a valid JSON object")
+
+// usesToolCallFixer reports whether the agent behind opt is configured to
+// repair malformed tool call arguments before execution.
+func (fp *flowProvider) usesToolCallFixer(opt pconfig.ProviderOptionsType) bool {
+	agentConfig := fp.GetProviderConfig().AgentConfigForType(opt)
+	return agentConfig != nil && agentConfig.ToolCallFixer
+}
+
+func isJSONObject(data json.RawMessage) bool {
+	var object map[string]json.RawMessage
+	return json.Unmarshal(data, &object) == nil && object != nil
+}
+
+// storeCapabilities persists the capabilities measured by a TestProvider run of
+// the saved provider prv, one row per model. A model measured again replaces
+// its previous row; models not part of this run keep theirs.
+func (pc *providerController) storeCapabilities(
+	ctx context.Context,
+	prv database.Provider,
+	capabilities []tester.ModelCapabilities,
+) error {
+	for _, caps := range capabilities {
+		agentTypes := make([]string, 0, len(caps.AgentTypes))
+		for _, opt := range caps.AgentTypes {
+			agentTypes = append(agentTypes, string(opt))
+		}
+
+		params := database.UpsertProviderCapabilityParams{
+			UserID:      prv.UserID,
+			ProviderID:  prv.ID,
+			Type:        prv.Type,
+			Model:       caps.Model,
+			AgentTypes:  agentTypes,
+			Reasoning:   caps.Reasoning,
+			MaxContext:  caps.MaxContext,
+			TestsTotal:  int64(caps.TestsTotal),
+			TestsPassed: int64(caps.TestsPassed),
+		}
+		if caps.ToolCalling != nil {
+			params.ToolCalling = sql.NullFloat64{Float64: *caps.ToolCalling, Valid: true}
+		}
+		if caps.JSONMode != nil {
+			params.JsonMode = sql.NullBool{Bool: *caps.JSONMode, Valid: true}
+		}
+		if caps.Streaming != nil {
+			params.Streaming = sql.NullBool{Bool: *caps.Streaming, Valid: true}
+		}
+
+		if _, err := pc.db.UpsertProviderCapability(ctx, params); err != nil {
+			return fmt.Errorf("failed to store capabilities of model '%s': %w", caps.Model, err)
+		}
+	}
+
+	return nil
+}
+
+// tuneProvider applies the capabilities just measured for the saved provider
+// prv to its stored config and saves the result. It only runs right after a
+// TestProvider run of that provider: the operator's later edits are saved as
+// submitted and are never tuned back.
+func (pc *providerController) tuneProvider(
+	ctx context.Context,
+	prv database.Provider,
+	capabilities []tester.ModelCapabilities,
+) error {
+	config, err := pconfig.LoadConfigData(prv.Config, nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse provider config: %w", err)
+	}
+
+	measured := make(map[string]tester.ModelCapabilities, len(capabilities))
+	for _, caps := range capabilities {
+		measured[caps.Model] = caps
+	}
+
+	tuned := tester.TuneProviderConfig(config, measured)
+	if len(tuned) == 0 {
+		return nil
+	}
+
+	rawConfig, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal provider config: %w", err)
+	}
+
+	_, err = pc.db.UpdateUserProvider(ctx, database.UpdateUserProviderParams{
+		ID:     prv.ID,
+		UserID: prv.UserID,
+		Name:   prv.Name,
+		Config: rawConfig,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save tuned provider config: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":     prv.UserID,
+		"provider_id": prv.ID,
+		"type":        prv.Type,
+		"agent_types": tuned,
+	}).Info("provider config tuned from measured model capabilities")
+
+	return nil
+}
+
+// minMeasuredContextWindow is the smallest measured prompt, in tokens, taken
+// as a context window. The built-in tests send short prompts, so a smaller
+// value says nothing about the window and would only force early summaries.
+const minMeasuredContextWindow = 16 * 1024
+
+// measuredModel identifies a model a TestProvider run measured on a saved
+// provider of the user
+type measuredModel struct {
+	prvname provider.ProviderName
+	model   string
+}
+
+// measuredContextWindows returns the largest prompts the models of the user's
+// own providers served during TestProvider runs, used as context windows for
+// models the catalog gives no window. A lookup error only disables the
+// fallback.
+func (pc *providerController) measuredContextWindows(ctx context.Context, userID int64) map[measuredModel]int {
+	providers, err := pc.db.GetUserProviders(ctx, userID)
+	if err != nil {
+		logrus.WithError(err).Warnf("failed to load providers of user %d", userID)
+		return nil
+	}
+
+	rows, err := pc.db.GetUserProviderCapabilities(ctx, userID)
+	if err != nil {
+		logrus.WithError(err).Warnf("failed to load provider capabilities for user %d", userID)
+		return nil
+	}
+
+	names := make(map[int64]provider.ProviderName, len(providers))
+	for _, prv := range providers {
+		names[prv.ID] = provider.ProviderName(prv.Name)
+	}
+
+	windows := make(map[measuredModel]int, len(rows))
+	for _, row := range rows {
+		prvname, ok := names[row.ProviderID]
+		if !ok || row.MaxContext < minMeasuredContextWindow {
+			continue
+		}
+		windows[measuredModel{prvname: prvname, model: row.Model}] = int(row.MaxContext)
+	}
+
+	return windows
+}
+
+// ConvertCapabilities maps a stored capability row back to the tester view.
+func ConvertCapabilities(row database.ProviderCapability) tester.ModelCapabilities {
+	caps := tester.ModelCapabilities{
+		Model:       row.Model,
+		AgentTypes:  make([]pconfig.ProviderOptionsType, 0, len(row.AgentTypes)),
+		Reasoning:   row.Reasoning,
+		MaxContext:  row.MaxContext,
+		TestsTotal:  int(row.TestsTotal),
+		TestsPassed: int(row.TestsPassed),
+	}
+
+	for _, opt := range row.AgentTypes {
+		caps.AgentTypes = append(caps.AgentTypes, pconfig.ProviderOptionsType(opt))
+	}
+	if row.ToolCalling.Valid {
+		caps.ToolCalling = &row.ToolCalling.Float64
+	}
+	if row.JsonMode.Valid {
+		caps.JSONMode = &row.JsonMode.Bool
+	}
+	if row.Streaming.Valid {
+		caps.Streaming = &row.Streaming.Bool
+	}
+
+	return caps
+}
@@ -2,8 +2,10 @@ package custom
 
 import (
 	"context"
+	"fmt"
 	"os"
 
+	"pentagi/pkg/cast"
 	"pentagi/pkg/config"
 	"pentagi/pkg/providers/pconfig"
 	"pentagi/pkg/providers/provider"
@@ -49,7 +51,8 @@ func DefaultProviderConfig(cfg *config.Config) (*pconfig.ProviderConfig, error)
 }
 
 type customProvider struct {
-	llm            *openai.LLM
+	llm            llms.Model
+	profile        string
 	model          string
 	models         pconfig.ModelsConfig
 	providerName   provider.ProviderName
@@ -70,6 +73,14 @@ func New(
 		return nil, err
 	}
 
+	switch cfg.LLMServerProfile {
+	case "", GenericProfile:
+	case LocalProfile:
+		httpClient = newLocalHTTPClient(httpClient, cast.ToolCallIDTemplate)
+	default:
+		return nil, fmt.Errorf("unsupported LLM server profile: %s", cfg.LLMServerProfile)
+	}
+
 	opts := []openai.Option{
 		openai.WithToken(baseKey),
 		openai.WithModel(baseModel),
@@ -97,8 +108,14 @@ func New(
 		models = pconfig.ModelsConfig{}
 	}
 
+	var llm llms.Model = client
+	if cfg.LLMServerProfile == LocalProfile {
+		llm = newLocalModel(client, cfg.LLMServerToolGrammar)
+	}
+
 	return &customProvider{
-		llm:            client,
+		llm:            llm,
+		profile:        cfg.LLMServerProfile,
 		model:          baseModel,
 		models:         models,
 		providerName:   providerName,
@@ -177,13 +194,10 @@ func (p *customProvider) CallWithTools(
 	tools []llms.Tool,
 	streamCb streaming.Callback,
 ) (*llms.ContentResponse, error) {
-	return provider.WrapGenerateContent(
-		ctx, p, opt, p.llm.GenerateContent, chain,
-		append([]llms.CallOption{
-			llms.WithTools(tools),
-			llms.WithStreamingFunc(streamCb),
-		}, p.providerConfig.GetOptionsForType(opt)...)...,
-	)
+	options := append(p.toolOptions(opt, tools), llms.WithStreamingFunc(streamCb))
+	options = append(options, p.providerConfig.GetOptionsForType(opt)...)
+
+	return provider.WrapGenerateContent(ctx, p, opt, p.llm.GenerateContent, chain, options...)
 }
 
 // CallWithExtraOptions: extra is appended last, so it overrides the config.
@@ -197,7 +211,7 @@ func (p *customProvider) CallWithExtraOptions(
 ) (*llms.ContentResponse, error) {
 	options := []llms.CallOption{llms.WithStreamingFunc(streamCb)}
 	if len(tools) > 0 {
-		options = append(options, llms.WithTools(tools))
+		options = append(options, p.toolOptions(opt, tools)...)
 	}
 	options = append(options, p.providerConfig.GetOptionsForType(opt)...)
 	options = append(options, extra...)
@@ -205,10 +219,23 @@ func (p *customProvider) CallWithExtraOptions(
 	return provider.WrapGenerateContent(ctx, p, opt, p.llm.GenerateContent, chain, options...)
 }
 
+func (p *customProvider) toolOptions(opt pconfig.ProviderOptionsType, tools []llms.Tool) []llms.CallOption {
+	if p.profile != LocalProfile {
+		return []llms.CallOption{llms.WithTools(tools)}
+	}
+
+	return localToolOptions(tools, opt == pconfig.OptionsTypeAssistant)
+}
+
 func (p *customProvider) GetUsage(info map[string]any) pconfig.CallUsage {
 	return pconfig.NewCallUsage(info)
 }
 
 func (p *customProvider) GetToolCallIDTemplate(ctx context.Context, prompter templates.Prompter) (string, error) {
+	// the local profile rewrites every tool call ID, so there is nothing to probe
+	if p.profile == LocalProfile {
+		return cast.ToolCallIDTemplate, nil
+	}
+
 	return provider.DetermineToolCallIDTemplate(ctx, p, pconfig.OptionsTypeSimple, prompter, "")
 }
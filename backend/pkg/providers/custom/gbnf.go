@@ -0,0 +1,293 @@
+package custom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vxcontrol/langchaingo/llms"
+)
+
+// gbnfPrimitives are the shared rules every generated grammar relies on. They
+// follow the JSON grammar shipped with llama.cpp (grammars/json.gbnf) so the
+// output is always parseable by encoding/json.
+const gbnfPrimitives = `ws ::= [ \t\n]*
+string ::= "\"" ( [^"\\\x7F\x00-\x1F] | "\\" ( ["\\/bfnrt] | "u" [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] ) )* "\""
+integer ::= "-"? ( "0" | [1-9] [0-9]* )
+number ::= integer ( "." [0-9]+ )? ( [eE] [-+]? [0-9]+ )?
+boolean ::= "true" | "false"
+null ::= "null"
+value ::= object | array | string | number | boolean | null
+object ::= "{" ws ( string ws ":" ws value ( ws "," ws string ws ":" ws value )* )? ws "}"
+array ::= "[" ws ( value ( ws "," ws value )* )? ws "]"
+`
+
+var reGBNFRuleName = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+
+// gbnfBuilder turns tool JSON schemas into GBNF rules. Only the schema subset
+// PentAGI tools actually use is translated exactly; anything else falls back
+// to the generic JSON value rule, which still guarantees well-formed output.
+type gbnfBuilder struct {
+	rules map[string]string
+	order []string
+}
+
+func newGBNFBuilder() *gbnfBuilder {
+	return &gbnfBuilder{rules: make(map[string]string)}
+}
+
+// BuildToolCallGrammar returns a GBNF grammar which only accepts a single
+// `{"name": ..., "arguments": {...}}` object for one of the given tools, with
+// arguments constrained by the tool's parameters schema. An optional leading
+// <think> block is allowed so reasoning models keep thinking. If allowText is
+// set, a plain text answer (anything not starting with "{") is accepted too.
+func BuildToolCallGrammar(tools []llms.Tool, allowText bool) (string, error) {
+	b := newGBNFBuilder()
+
+	var calls []string
+	for _, tool := range tools {
+		if tool.Function == nil || tool.Function.Name == "" {
+			continue
+		}
+
+		schema, err := toolSchema(tool.Function.Parameters)
+		if err != nil {
+			return "", fmt.Errorf("invalid parameters schema of tool '%s': %w", tool.Function.Name, err)
+		}
+
+		name := b.ruleName("call-" + tool.Function.Name)
+		args := b.schemaRule(name+"-args", schema)
+		quoted, _ := json.Marshal(tool.Function.Name)
+		b.add(name, fmt.Sprintf(`"{" ws "\"name\"" ws ":" ws %s ws "," ws "\"arguments\"" ws ":" ws %s ws "}"`,
+			gbnfLiteral(string(quoted)), args))
+		calls = append(calls, name)
+	}
+
+	if len(calls) == 0 {
+		return "", fmt.Errorf("no tools to build grammar from")
+	}
+
+	body := strings.Join(calls, " | ")
+	if allowText {
+		body += " | answer"
+		b.add("answer", `[^{ \t\n] [^\x00]*`)
+	}
+	b.add("think", `"<think>" [^<]* "</think>"`)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "root ::= ( think ws )? ( %s ) ws\n", body)
+	for _, name := range b.order {
+		fmt.Fprintf(&sb, "%s ::= %s\n", name, b.rules[name])
+	}
+	sb.WriteString(gbnfPrimitives)
+
+	return sb.String(), nil
+}
+
+func toolSchema(parameters any) (map[string]any, error) {
+	if parameters == nil {
+		return map[string]any{"type": "object"}, nil
+	}
+
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+func (b *gbnfBuilder) ruleName(name string) string {
+	name = strings.Trim(reGBNFRuleName.ReplaceAllString(name, "-"), "-")
+	base, idx := name, 1
+	for {
+		if _, ok := b.rules[name]; !ok {
+			return name
+		}
+		idx++
+		name = fmt.Sprintf("%s-%d", base, idx)
+	}
+}
+
+func (b *gbnfBuilder) add(name, body string) {
+	if _, ok := b.rules[name]; !ok {
+		b.order = append(b.order, name)
+	}
+	b.rules[name] = body
+}
+
+// schemaRule returns an expression matching values of the schema; composite
+// schemas get their own named rule to keep the grammar readable.
+func (b *gbnfBuilder) schemaRule(name string, schema map[string]any) string {
+	if values, ok := schema["enum"].([]any); ok && len(values) > 0 {
+		alts := make([]string, 0, len(values))
+		for _, value := range values {
+			data, err := json.Marshal(value)
+			if err != nil {
+				continue
+			}
+			alts = append(alts, gbnfLiteral(string(data)))
+		}
+		if len(alts) > 0 {
+			return b.named(name, strings.Join(alts, " | "))
+		}
+	}
+
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if variants, ok := schema[key].([]any); ok && len(variants) > 0 {
+			alts := make([]string, 0, len(variants))
+			for idx, variant := range variants {
+				if sub, ok := variant.(map[string]any); ok {
+					alts = append(alts, b.schemaRule(fmt.Sprintf("%s-%d", name, idx+1), sub))
+				}
+			}
+			if len(alts) > 0 {
+				return b.named(name, strings.Join(alts, " | "))
+			}
+		}
+	}
+
+	switch typ := schema["type"].(type) {
+	case string:
+		return b.typeRule(name, typ, schema)
+	case []any:
+		alts := make([]string, 0, len(typ))
+		for _, t := range typ {
+			if s, ok := t.(string); ok {
+				alts = append(alts, b.typeRule(fmt.Sprintf("%s-%s", name, s), s, schema))
+			}
+		}
+		if len(alts) > 0 {
+			return b.named(name, strings.Join(alts, " | "))
+		}
+	}
+
+	return "value"
+}
+
+func (b *gbnfBuilder) typeRule(name, typ string, schema map[string]any) string {
+	switch typ {
+	case "string", "integer", "number", "boolean", "null":
+		return typ
+	case "array":
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return "array"
+		}
+		item := b.schemaRule(name+"-item", items)
+		return b.named(name, fmt.Sprintf(`"[" ws ( %s ( ws "," ws %s )* )? ws "]"`, item, item))
+	case "object":
+		return b.objectRule(name, schema)
+	default:
+		return "value"
+	}
+}
+
+func (b *gbnfBuilder) objectRule(name string, schema map[string]any) string {
+	properties, ok := schema["properties"].(map[string]any)
+	if !ok || len(properties) == 0 {
+		return "object"
+	}
+
+	required := make(map[string]bool)
+	var keys []string
+	if list, ok := schema["required"].([]any); ok {
+		for _, item := range list {
+			key, ok := item.(string)
+			if !ok || required[key] {
+				continue
+			}
+			if _, exists := properties[key]; exists {
+				required[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	var optional []string
+	for key := range properties {
+		if !required[key] {
+			optional = append(optional, key)
+		}
+	}
+	sort.Strings(optional)
+
+	pair := func(key string) string {
+		sub, _ := properties[key].(map[string]any)
+		if sub == nil {
+			sub = map[string]any{}
+		}
+		data, _ := json.Marshal(key)
+		return fmt.Sprintf(`%s ws ":" ws %s`, gbnfLiteral(string(data)), b.schemaRule(name+"-"+key, sub))
+	}
+
+	// required properties are emitted in the declared order, optional ones
+	// follow in a fixed order and each of them may be omitted
+	var parts []string
+	for idx, key := range keys {
+		if idx > 0 {
+			parts = append(parts, `ws "," ws`)
+		}
+		parts = append(parts, pair(key))
+	}
+
+	optPairs := make([]string, len(optional))
+	for idx, key := range optional {
+		optPairs[idx] = pair(key)
+	}
+
+	if len(keys) > 0 {
+		for _, p := range optPairs {
+			parts = append(parts, fmt.Sprintf(`( ws "," ws %s )?`, p))
+		}
+	} else {
+		alts := make([]string, 0, len(optPairs))
+		for idx, p := range optPairs {
+			alt := []string{p}
+			for _, rest := range optPairs[idx+1:] {
+				alt = append(alt, fmt.Sprintf(`( ws "," ws %s )?`, rest))
+			}
+			alts = append(alts, strings.Join(alt, " "))
+		}
+		parts = append(parts, fmt.Sprintf("( %s )?", strings.Join(alts, " | ")))
+	}
+
+	return b.named(name, fmt.Sprintf(`"{" ws %s ws "}"`, strings.Join(parts, " ")))
+}
+
+func (b *gbnfBuilder) named(name, body string) string {
+	name = b.ruleName(name)
+	b.add(name, body)
+	return name
+}
+
+// gbnfLiteral quotes s as a GBNF string literal.
+func gbnfLiteral(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,150 @@
+package custom
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/vxcontrol/langchaingo/llms"
+)
+
+var (
+	reGBNFQuoted = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|\[(?:[^\]\\]|\\.)*\]`)
+	reGBNFIdent  = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9-]*`)
+)
+
+// grammarRules parses "name ::= body" lines and fails on references to
+// undefined rules, which llama.cpp would reject when loading the grammar.
+func grammarRules(t *testing.T, grammar string) map[string]string {
+	t.Helper()
+
+	rules := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(grammar), "\n") {
+		name, body, ok := strings.Cut(line, " ::= ")
+		if !ok {
+			t.Fatalf("invalid grammar line: %q", line)
+		}
+		if _, exists := rules[name]; exists {
+			t.Fatalf("rule %q defined twice", name)
+		}
+		rules[name] = body
+	}
+
+	for name, body := range rules {
+		for _, ref := range reGBNFIdent.FindAllString(reGBNFQuoted.ReplaceAllString(body, " "), -1) {
+			if _, ok := rules[ref]; !ok {
+				t.Errorf("rule %q references undefined rule %q", name, ref)
+			}
+		}
+	}
+
+	return rules
+}
+
+func TestBuildToolCallGrammar(t *testing.T) {
+	tools := []llms.Tool{
+		{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name: "terminal",
+				Parameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"input":   map[string]any{"type": "string"},
+						"timeout": map[string]any{"type": "integer"},
+						"detach":  map[string]any{"type": "boolean"},
+						"message": map[string]any{"type": "string"},
+					},
+					"required": []string{"input", "message"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name: "report.finding",
+				Parameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"severity": map[string]any{"type": "string", "enum": []string{"low", "high"}},
+						"tags":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+						"score":    map[string]any{"type": []string{"number", "null"}},
+					},
+				},
+			},
+		},
+	}
+
+	grammar, err := BuildToolCallGrammar(tools, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rules := grammarRules(t, grammar)
+
+	root := rules["root"]
+	if !strings.Contains(root, "call-terminal | call-report-finding") {
+		t.Errorf("root must list every tool, got %q", root)
+	}
+	if strings.Contains(root, "answer") {
+		t.Errorf("root must not allow text answers, got %q", root)
+	}
+
+	args := rules["call-terminal-args"]
+	if !strings.HasPrefix(args, `"{" ws "\"input\"" ws ":" ws string ws "," ws "\"message\""`) {
+		t.Errorf("required properties must come first in declared order, got %q", args)
+	}
+	if !strings.Contains(args, `( ws "," ws "\"detach\"" ws ":" ws boolean )?`) {
+		t.Errorf("optional properties must be optional, got %q", args)
+	}
+
+	if got := rules["call-report-finding-args-severity"]; got != `"\"low\"" | "\"high\""` {
+		t.Errorf("unexpected enum rule: %q", got)
+	}
+	if got := rules["call-report-finding-args-score"]; got != "number | null" {
+		t.Errorf("unexpected type union rule: %q", got)
+	}
+	if !strings.Contains(rules["call-report-finding"], `"\"report.finding\""`) {
+		t.Errorf("tool name must be a literal, got %q", rules["call-report-finding"])
+	}
+
+	// without required properties any of the optional ones may come first
+	findingArgs := rules["call-report-finding-args"]
+	if strings.Count(findingArgs, " | ") != 2 {
+		t.Errorf("expected one alternative per optional property, got %q", findingArgs)
+	}
+}
+
+func TestBuildToolCallGrammarAllowText(t *testing.T) {
+	tools := []llms.Tool{{
+		Type:     "function",
+		Function: &llms.FunctionDefinition{Name: "search"},
+	}}
+
+	grammar, err := BuildToolCallGrammar(tools, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rules := grammarRules(t, grammar)
+	if !strings.Contains(rules["root"], "call-search | answer") {
+		t.Errorf("root must allow text answers, got %q", rules["root"])
+	}
+	if !strings.HasSuffix(rules["call-search"], `ws object ws "}"`) {
+		t.Errorf("tool without parameters must accept any object, got %q", rules["call-search"])
+	}
+}
+
+func TestBuildToolCallGrammarErrors(t *testing.T) {
+	if _, err := BuildToolCallGrammar(nil, false); err == nil {
+		t.Error("expected error for empty tools")
+	}
+
+	tools := []llms.Tool{{
+		Type:     "function",
+		Function: &llms.FunctionDefinition{Name: "broken", Parameters: make(chan int)},
+	}}
+	if _, err := BuildToolCallGrammar(tools, false); err == nil {
+		t.Error("expected error for invalid parameters schema")
+	}
+}
@@ -0,0 +1,465 @@
+package custom
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"pentagi/pkg/cast"
+	"pentagi/pkg/templates"
+
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/openai"
+	"github.com/vxcontrol/langchaingo/llms/reasoning"
+)
+
+// GenericProfile is the default LLM_SERVER_PROFILE value: responses are passed
+// through as the server returns them.
+const GenericProfile = "generic"
+
+// LocalProfile is the LLM_SERVER_PROFILE value for local inference servers
+// (llama.cpp, vLLM, LM Studio and alike) which need the response fixups below
+// to drive PentAGI flows reliably.
+const LocalProfile = "local"
+
+const toolChoiceRequired = "required"
+
+var (
+	reLocalReasoningBlock = regexp.MustCompile(`(?s)^(.*?)<(think|thinking|reasoning)>(.*?)</(?:think|thinking|reasoning)>\s*(.*)$`)
+	reLocalReasoningTail  = regexp.MustCompile(`(?s)^(.*?)</(?:think|thinking|reasoning)>\s*(.*)$`)
+)
+
+// localModel wraps the OpenAI-compatible client with the local profile
+// behavior: optional GBNF-constrained tool calling, reasoning extraction from
+// inline tags and tool call IDs in the ToolCallIDTemplate format.
+type localModel struct {
+	llm         llms.Model
+	toolGrammar bool
+}
+
+func newLocalModel(llm llms.Model, toolGrammar bool) *localModel {
+	return &localModel{llm: llm, toolGrammar: toolGrammar}
+}
+
+func (m *localModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func (m *localModel) GenerateContent(
+	ctx context.Context,
+	messages []llms.MessageContent,
+	options ...llms.CallOption,
+) (*llms.ContentResponse, error) {
+	var opts llms.CallOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	var grammarTools []llms.Tool
+	if m.toolGrammar && len(opts.Tools) > 0 {
+		grammar, err := BuildToolCallGrammar(opts.Tools, opts.ToolChoice != toolChoiceRequired)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build tool call grammar: %w", err)
+		}
+
+		// llama.cpp rejects custom grammar together with native tools, so the
+		// tools are described in the prompt and the call is parsed back from text
+		extraBody := make(map[string]any)
+		if opts.Metadata != nil {
+			if existing, ok := opts.Metadata["openai:extra_body"].(map[string]any); ok {
+				maps.Copy(extraBody, existing)
+			}
+		}
+		extraBody["grammar"] = grammar
+
+		options = append(options,
+			llms.WithTools(nil),
+			llms.WithToolChoice(nil),
+			openai.WithExtraBody(extraBody),
+		)
+		messages = renderGrammarChain(messages, opts.Tools)
+		grammarTools = opts.Tools
+	}
+
+	resp, err := m.llm.GenerateContent(ctx, messages, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, choice := range resp.Choices {
+		normalizeReasoning(choice)
+		if len(grammarTools) > 0 {
+			parseGrammarToolCall(choice, grammarTools)
+		}
+	}
+
+	return resp, nil
+}
+
+// localToolOptions forces a tool call for every agent except the assistant,
+// which may answer the user with plain text. Servers map tool_choice=required
+// onto grammar-constrained decoding of the tool arguments.
+func localToolOptions(tools []llms.Tool, allowText bool) []llms.CallOption {
+	options := []llms.CallOption{llms.WithTools(tools)}
+	if !allowText && len(tools) > 0 {
+		options = append(options, llms.WithToolChoice(toolChoiceRequired))
+	}
+
+	return options
+}
+
+// normalizeReasoning moves inline reasoning blocks out of the answer text when
+// the server did not return them in a dedicated field. It also covers chat
+// templates which put the opening <think> tag into the prompt, so only the
+// closing tag appears in the output.
+func normalizeReasoning(choice *llms.ContentChoice) {
+	if choice == nil || !choice.Reasoning.IsEmpty() {
+		return
+	}
+
+	var thinking, content string
+	if matches := reLocalReasoningBlock.FindStringSubmatch(choice.Content); matches != nil {
+		thinking = matches[3]
+		content = strings.TrimSpace(matches[1])
+		if rest := strings.TrimSpace(matches[4]); rest != "" {
+			content = strings.TrimSpace(content + "\n" + rest)
+		}
+	} else if matches := reLocalReasoningTail.FindStringSubmatch(choice.Content); matches != nil {
+		thinking = matches[1]
+		content = strings.TrimSpace(matches[2])
+	} else {
+		return
+	}
+
+	choice.Content = content
+	if thinking = strings.TrimSpace(thinking); thinking != "" {
+		choice.Reasoning = &reasoning.ContentReasoning{Content: thinking}
+	}
+}
+
+// renderGrammarChain rewrites the chain for servers without native tools:
+// tool definitions go to the system prompt, previous tool calls become the
+// JSON objects the grammar produces and tool results become user messages.
+func renderGrammarChain(chain []llms.MessageContent, tools []llms.Tool) []llms.MessageContent {
+	var sb strings.Builder
+	sb.WriteString("You have access to the following tools. To call a tool, answer only with a JSON object ")
+	sb.WriteString(`{"name": "<tool name>", "arguments": {<tool arguments>}} and nothing else.`)
+	sb.WriteString("\n\nAvailable tools:\n")
+	for _, tool := range tools {
+		if tool.Function == nil {
+			continue
+		}
+		params, _ := json.Marshal(tool.Function.Parameters)
+		fmt.Fprintf(&sb, "- %s: %s\n  parameters: %s\n", tool.Function.Name, tool.Function.Description, params)
+	}
+	toolsPrompt := sb.String()
+
+	result := make([]llms.MessageContent, 0, len(chain)+1)
+	hasSystem := false
+	for idx, msg := range chain {
+		switch msg.Role {
+		case llms.ChatMessageTypeSystem:
+			if !hasSystem {
+				msg = llms.MessageContent{
+					Role:  msg.Role,
+					Parts: append(append([]llms.ContentPart{}, msg.Parts...), llms.TextContent{Text: "\n\n" + toolsPrompt}),
+				}
+				hasSystem = true
+			}
+			result = append(result, msg)
+
+		case llms.ChatMessageTypeAI:
+			parts := make([]llms.ContentPart, 0, len(msg.Parts))
+			for _, part := range msg.Parts {
+				call, ok := part.(llms.ToolCall)
+				if !ok || call.FunctionCall == nil {
+					parts = append(parts, part)
+					continue
+				}
+				parts = append(parts, llms.TextContent{Text: renderToolCall(call.FunctionCall)})
+			}
+			result = append(result, llms.MessageContent{Role: msg.Role, Parts: parts})
+
+		case llms.ChatMessageTypeTool:
+			parts := make([]llms.ContentPart, 0, len(msg.Parts))
+			for _, part := range msg.Parts {
+				if resp, ok := part.(llms.ToolCallResponse); ok {
+					part = llms.TextContent{Text: fmt.Sprintf("Result of the tool '%s':\n%s", resp.Name, resp.Content)}
+				}
+				parts = append(parts, part)
+			}
+
+			// consecutive tool results are merged into one user message
+			if idx > 0 && chain[idx-1].Role == llms.ChatMessageTypeTool {
+				last := len(result) - 1
+				result[last].Parts = append(result[last].Parts, parts...)
+				continue
+			}
+			result = append(result, llms.MessageContent{Role: llms.ChatMessageTypeHuman, Parts: parts})
+
+		default:
+			result = append(result, msg)
+		}
+	}
+
+	if !hasSystem {
+		result = append([]llms.MessageContent{llms.TextParts(llms.ChatMessageTypeSystem, toolsPrompt)}, result...)
+	}
+
+	return result
+}
+
+func renderToolCall(call *llms.FunctionCall) string {
+	args := json.RawMessage(call.Arguments)
+	if !json.Valid(args) {
+		args = json.RawMessage("{}")
+	}
+
+	data, err := json.Marshal(struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}{call.Name, args})
+	if err != nil {
+		return call.Arguments
+	}
+
+	return string(data)
+}
+
+// parseGrammarToolCall turns a grammar-constrained JSON answer back into a
+// native tool call; plain text answers are left untouched.
+func parseGrammarToolCall(choice *llms.ContentChoice, tools []llms.Tool) {
+	if choice == nil || len(choice.ToolCalls) > 0 {
+		return
+	}
+
+	content := strings.TrimSpace(choice.Content)
+	if !strings.HasPrefix(content, "{") {
+		return
+	}
+
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal([]byte(content), &call); err != nil || call.Name == "" {
+		return
+	}
+
+	known := false
+	for _, tool := range tools {
+		if tool.Function != nil && tool.Function.Name == call.Name {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return
+	}
+
+	args := string(call.Arguments)
+	if args == "" || args == "null" {
+		args = "{}"
+	}
+
+	funcCall := &llms.FunctionCall{Name: call.Name, Arguments: args}
+	choice.Content = ""
+	choice.FuncCall = funcCall
+	choice.ToolCalls = []llms.ToolCall{{
+		ID:           templates.GenerateFromPattern(cast.ToolCallIDTemplate, call.Name),
+		Type:         "function",
+		FunctionCall: funcCall,
+	}}
+}
+
+// localTransport rewrites tool call IDs in chat completion responses, both
+// plain and streamed, with IDs generated from the template. Local servers
+// often omit the IDs, reuse them across turns or emit formats other providers
+// reject when the chain is replayed later.
+type localTransport struct {
+	base     http.RoundTripper
+	template string
+}
+
+func newLocalHTTPClient(client *http.Client, template string) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	wrapped := *client
+	wrapped.Transport = &localTransport{base: base, template: template}
+	return &wrapped
+}
+
+func (t *localTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK || !strings.HasSuffix(req.URL.Path, "/chat/completions") {
+		return resp, err
+	}
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "text/event-stream") {
+		resp.Body = newStreamRewriter(resp.Body, t.rewriteChunk)
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err == nil && t.rewriteResponse(payload) {
+		if data, err := json.Marshal(payload); err == nil {
+			body = data
+		}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+
+	return resp, nil
+}
+
+func (t *localTransport) rewriteResponse(payload map[string]any) bool {
+	changed := false
+	for _, choice := range asSlice(payload["choices"]) {
+		message, _ := asMap(choice)["message"].(map[string]any)
+		for _, item := range asSlice(message["tool_calls"]) {
+			call := asMap(item)
+			if call == nil {
+				continue
+			}
+			function := asMap(call["function"])
+			name, _ := function["name"].(string)
+			call["id"] = templates.GenerateFromPattern(t.template, name)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// rewriteChunk keeps one generated ID per streamed tool call: it is set on
+// every delta which identifies the call (carries an ID or a function name)
+// while plain argument continuations stay as they are.
+func (t *localTransport) rewriteChunk(payload map[string]any, ids map[string]string) bool {
+	changed := false
+	for cidx, choice := range asSlice(payload["choices"]) {
+		choiceMap := asMap(choice)
+		delta, _ := choiceMap["delta"].(map[string]any)
+		for tidx, item := range asSlice(delta["tool_calls"]) {
+			call := asMap(item)
+			if call == nil {
+				continue
+			}
+
+			id, _ := call["id"].(string)
+			function := asMap(call["function"])
+			name, _ := function["name"].(string)
+			if id == "" && name == "" {
+				continue
+			}
+
+			key := fmt.Sprintf("%v:%v", valueOr(choiceMap["index"], cidx), valueOr(call["index"], tidx))
+			generated, ok := ids[key]
+			if !ok {
+				generated = templates.GenerateFromPattern(t.template, name)
+				ids[key] = generated
+			}
+			call["id"] = generated
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+type streamRewriter struct {
+	*io.PipeReader
+	body io.ReadCloser
+}
+
+func newStreamRewriter(body io.ReadCloser, rewrite func(map[string]any, map[string]string) bool) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer body.Close()
+
+		ids := make(map[string]string)
+		reader := bufio.NewReader(body)
+		for {
+			line, err := reader.ReadString('\n')
+			if len(line) > 0 {
+				if _, werr := io.WriteString(pw, rewriteStreamLine(line, ids, rewrite)); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					pw.Close()
+				} else {
+					pw.CloseWithError(err)
+				}
+				return
+			}
+		}
+	}()
+
+	return &streamRewriter{PipeReader: pr, body: body}
+}
+
+func (s *streamRewriter) Close() error {
+	s.PipeReader.Close()
+	return s.body.Close()
+}
+
+func rewriteStreamLine(line string, ids map[string]string, rewrite func(map[string]any, map[string]string) bool) string {
+	data, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "data:")
+	if !ok {
+		return line
+	}
+
+	data = strings.TrimSpace(data)
+	if data == "" || data == "[DONE]" {
+		return line
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(data), &payload); err != nil || !rewrite(payload, ids) {
+		return line
+	}
+
+	patched, err := json.Marshal(payload)
+	if err != nil {
+		return line
+	}
+
+	return "data: " + string(patched) + "\n"
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func valueOr(v any, def int) any {
+	if v == nil {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,390 @@
+package custom
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/cast"
+	"pentagi/pkg/config"
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester/stub"
+	"pentagi/pkg/providers/tester/testdata"
+	"pentagi/pkg/templates"
+
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/reasoning"
+	"github.com/vxcontrol/langchaingo/llms/streaming"
+)
+
+func TestNormalizeReasoning(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		reasoning *reasoning.ContentReasoning
+		wantText  string
+		wantThink string
+	}{
+		{
+			name:      "think block",
+			content:   "<think>\nplan the answer\n</think>\n\n42",
+			wantText:  "42",
+			wantThink: "plan the answer",
+		},
+		{
+			name:      "missing opening tag",
+			content:   "plan the answer\n</think>\n\n42",
+			wantText:  "42",
+			wantThink: "plan the answer",
+		},
+		{
+			name:      "reasoning tag with prefix",
+			content:   "Answer:<reasoning>check twice</reasoning> 42",
+			wantText:  "Answer:\n42",
+			wantThink: "check twice",
+		},
+		{
+			name:      "empty think block",
+			content:   "<think></think>42",
+			wantText:  "42",
+			wantThink: "",
+		},
+		{
+			name:     "plain text",
+			content:  "42",
+			wantText: "42",
+		},
+		{
+			name:      "reasoning already parsed by server",
+			content:   "done </think> 42",
+			reasoning: &reasoning.ContentReasoning{Content: "server side"},
+			wantText:  "done </think> 42",
+			wantThink: "server side",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			choice := &llms.ContentChoice{Content: tt.content, Reasoning: tt.reasoning}
+			normalizeReasoning(choice)
+
+			if choice.Content != tt.wantText {
+				t.Errorf("content: got %q, want %q", choice.Content, tt.wantText)
+			}
+			var gotThink string
+			if choice.Reasoning != nil {
+				gotThink = choice.Reasoning.Content
+			}
+			if gotThink != tt.wantThink {
+				t.Errorf("reasoning: got %q, want %q", gotThink, tt.wantThink)
+			}
+		})
+	}
+}
+
+func TestParseGrammarToolCall(t *testing.T) {
+	tools := []llms.Tool{{Type: "function", Function: &llms.FunctionDefinition{Name: "search"}}}
+
+	choice := &llms.ContentChoice{Content: ` {"name": "search", "arguments": {"query": "nmap"}}`}
+	parseGrammarToolCall(choice, tools)
+
+	if choice.Content != "" || len(choice.ToolCalls) != 1 {
+		t.Fatalf("expected a single tool call, got %+v", choice)
+	}
+	call := choice.ToolCalls[0]
+	if call.FunctionCall.Name != "search" || call.FunctionCall.Arguments != `{"query": "nmap"}` {
+		t.Errorf("unexpected function call: %+v", call.FunctionCall)
+	}
+	if err := templates.ValidatePattern(cast.ToolCallIDTemplate, []templates.PatternSample{{Value: call.ID}}); err != nil {
+		t.Errorf("tool call id doesn't match template: %v", err)
+	}
+
+	for _, content := range []string{
+		"plain answer",
+		`{"name": "unknown", "arguments": {}}`,
+		`{"name": "search", "arguments": `,
+	} {
+		choice := &llms.ContentChoice{Content: content}
+		parseGrammarToolCall(choice, tools)
+		if choice.Content != content || len(choice.ToolCalls) != 0 {
+			t.Errorf("content %q must be left untouched, got %+v", content, choice)
+		}
+	}
+}
+
+func TestRenderGrammarChain(t *testing.T) {
+	tools := []llms.Tool{{
+		Type:     "function",
+		Function: &llms.FunctionDefinition{Name: "check_port", Description: "Checks a port"},
+	}}
+	chain := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, "system prompt"),
+		llms.TextParts(llms.ChatMessageTypeHuman, "check ports"),
+		{
+			Role: llms.ChatMessageTypeAI,
+			Parts: []llms.ContentPart{
+				llms.ToolCall{ID: "1", Type: "function", FunctionCall: &llms.FunctionCall{Name: "check_port", Arguments: `{"port":22}`}},
+				llms.ToolCall{ID: "2", Type: "function", FunctionCall: &llms.FunctionCall{Name: "check_port", Arguments: `{"port":80}`}},
+			},
+		},
+		{Role: llms.ChatMessageTypeTool, Parts: []llms.ContentPart{llms.ToolCallResponse{ToolCallID: "1", Name: "check_port", Content: "open"}}},
+		{Role: llms.ChatMessageTypeTool, Parts: []llms.ContentPart{llms.ToolCallResponse{ToolCallID: "2", Name: "check_port", Content: "closed"}}},
+	}
+
+	result := renderGrammarChain(chain, tools)
+	if len(result) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(result))
+	}
+
+	system := result[0].Parts[len(result[0].Parts)-1].(llms.TextContent).Text
+	if !strings.Contains(system, "- check_port: Checks a port") {
+		t.Errorf("system prompt must describe tools, got %q", system)
+	}
+	if len(chain[0].Parts) != 1 {
+		t.Error("original chain must not be modified")
+	}
+
+	ai := result[2]
+	if got := ai.Parts[0].(llms.TextContent).Text; got != `{"name":"check_port","arguments":{"port":22}}` {
+		t.Errorf("unexpected rendered tool call: %s", got)
+	}
+
+	results := result[3]
+	if results.Role != llms.ChatMessageTypeHuman || len(results.Parts) != 2 {
+		t.Fatalf("tool results must be merged into one user message, got %+v", results)
+	}
+	if got := results.Parts[1].(llms.TextContent).Text; got != "Result of the tool 'check_port':\nclosed" {
+		t.Errorf("unexpected rendered tool result: %q", got)
+	}
+
+	// a system message is added when the chain has none
+	result = renderGrammarChain(chain[1:2], tools)
+	if len(result) != 2 || result[0].Role != llms.ChatMessageTypeSystem {
+		t.Errorf("expected system message to be prepended, got %+v", result)
+	}
+}
+
+func TestLocalTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("stream") != "" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, `data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"type":"function","function":{"name":"echo","arguments":""}}]}}]}`+"\n\n")
+			io.WriteString(w, `data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{}"}}]}}]}`+"\n\n")
+			io.WriteString(w, `data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"0","function":{"arguments":""}}]}}]}`+"\n\n")
+			io.WriteString(w, "data: [DONE]\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"x","choices":[{"message":{"role":"assistant","tool_calls":[`+
+			`{"type":"function","function":{"name":"echo","arguments":"{}"}},`+
+			`{"id":"0","type":"function","function":{"name":"echo","arguments":"{}"}}]}}]}`)
+	}))
+	defer server.Close()
+
+	client := newLocalHTTPClient(server.Client(), "call_{r:8:d}")
+	validate := func(id string) {
+		t.Helper()
+		if err := templates.ValidatePattern("call_{r:8:d}", []templates.PatternSample{{Value: id}}); err != nil {
+			t.Errorf("unexpected tool call id %q: %v", id, err)
+		}
+	}
+
+	resp, err := client.Post(server.URL+"/v1/chat/completions", "application/json", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var payload struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []struct {
+					ID string `json:"id"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+
+	calls := payload.Choices[0].Message.ToolCalls
+	if len(calls) != 2 || calls[0].ID == calls[1].ID {
+		t.Fatalf("expected two distinct tool call ids, got %+v", calls)
+	}
+	validate(calls[0].ID)
+	validate(calls[1].ID)
+
+	resp, err = client.Post(server.URL+"/v1/chat/completions?stream=1", "application/json", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n\n")
+	if len(lines) != 4 || lines[3] != "data: [DONE]" {
+		t.Fatalf("unexpected stream: %q", body)
+	}
+
+	var ids []string
+	for _, line := range lines[:3] {
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					ToolCalls []struct {
+						ID string `json:"id"`
+					} `json:"tool_calls"`
+				} `json:"delta"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk); err != nil {
+			t.Fatalf("invalid chunk %q: %v", line, err)
+		}
+		ids = append(ids, chunk.Choices[0].Delta.ToolCalls[0].ID)
+	}
+
+	validate(ids[0])
+	if ids[1] != "" {
+		t.Errorf("argument continuation must keep an empty id, got %q", ids[1])
+	}
+	if ids[2] != ids[0] {
+		t.Errorf("the same tool call must keep its id, got %q and %q", ids[0], ids[2])
+	}
+}
+
+func TestLocalProfileWithStubServer(t *testing.T) {
+	registry, err := testdata.LoadLocalRegistry()
+	if err != nil {
+		t.Fatalf("failed to load local tests: %v", err)
+	}
+
+	srv := stub.New(registry.GetAllTests())
+	defer srv.Close()
+
+	newProvider := func(t *testing.T, profile string, toolGrammar bool) provider.Provider {
+		t.Helper()
+
+		cfg := &config.Config{
+			LLMServerURL:         srv.URL(),
+			LLMServerKey:         "stub",
+			LLMServerModel:       stub.DefaultModel,
+			LLMServerProfile:     profile,
+			LLMServerToolGrammar: toolGrammar,
+		}
+		providerConfig, err := DefaultProviderConfig(cfg)
+		if err != nil {
+			t.Fatalf("failed to create provider config: %v", err)
+		}
+		prv, err := New(cfg, provider.DefaultProviderNameCustom, providerConfig)
+		if err != nil {
+			t.Fatalf("failed to create provider: %v", err)
+		}
+		return prv
+	}
+
+	var toolDef testdata.TestDefinition
+	for _, def := range registry.GetAllTests() {
+		if def.ID == "local_tool_missing_id_streaming" {
+			toolDef = def
+		}
+	}
+	chain, err := toolDef.Messages.ToMessageContent()
+	if err != nil {
+		t.Fatalf("failed to build chain: %v", err)
+	}
+	tools := []llms.Tool{{
+		Type: "function",
+		Function: &llms.FunctionDefinition{
+			Name:        toolDef.Tools[0].Name,
+			Description: toolDef.Tools[0].Description,
+			Parameters:  toolDef.Tools[0].Parameters,
+		},
+	}}
+	streamCb := func(ctx context.Context, chunk streaming.Chunk) error { return nil }
+
+	t.Run("generic profile fails on missing ids", func(t *testing.T) {
+		prv := newProvider(t, "", false)
+		if _, err := prv.CallWithTools(context.Background(), pconfig.OptionsTypePrimaryAgent, chain, tools, streamCb); err == nil {
+			t.Error("expected streaming error without tool call ids")
+		}
+	})
+
+	for _, toolGrammar := range []bool{false, true} {
+		prv := newProvider(t, LocalProfile, toolGrammar)
+
+		for _, cb := range []streaming.Callback{nil, streamCb} {
+			resp, err := prv.CallWithTools(context.Background(), pconfig.OptionsTypePrimaryAgent, chain, tools, cb)
+			if err != nil {
+				t.Fatalf("grammar=%v streaming=%v: unexpected error: %v", toolGrammar, cb != nil, err)
+			}
+
+			calls := resp.Choices[0].ToolCalls
+			if len(calls) != 1 || calls[0].FunctionCall.Name != "lookup_host" {
+				t.Fatalf("grammar=%v streaming=%v: unexpected tool calls: %+v", toolGrammar, cb != nil, calls)
+			}
+			if err := templates.ValidatePattern(cast.ToolCallIDTemplate, []templates.PatternSample{{Value: calls[0].ID}}); err != nil {
+				t.Errorf("grammar=%v streaming=%v: %v", toolGrammar, cb != nil, err)
+			}
+		}
+
+		template, err := prv.GetToolCallIDTemplate(context.Background(), nil)
+		if err != nil || template != cast.ToolCallIDTemplate {
+			t.Errorf("unexpected tool call id template %q: %v", template, err)
+		}
+	}
+
+	t.Run("reasoning is extracted", func(t *testing.T) {
+		prv := newProvider(t, LocalProfile, false)
+		chain := []llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeHuman, "What is 9 + 8? Write only the number without any other text."),
+		}
+		for _, cb := range []streaming.Callback{nil, streamCb} {
+			resp, err := prv.CallEx(context.Background(), pconfig.OptionsTypeSimple, chain, cb)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			choice := resp.Choices[0]
+			if choice.Content != "17" || choice.Reasoning.IsEmpty() {
+				t.Errorf("streaming=%v: unexpected choice: %q / %+v", cb != nil, choice.Content, choice.Reasoning)
+			}
+		}
+	})
+
+	t.Run("unsupported profile", func(t *testing.T) {
+		cfg := &config.Config{LLMServerURL: srv.URL(), LLMServerKey: "stub", LLMServerProfile: "remote"}
+		providerConfig, err := DefaultProviderConfig(cfg)
+		if err != nil {
+			t.Fatalf("failed to create provider config: %v", err)
+		}
+		if _, err := New(cfg, provider.DefaultProviderNameCustom, providerConfig); err == nil {
+			t.Error("expected error for unsupported profile")
+		}
+	})
+}
+
+func TestLocalToolOptions(t *testing.T) {
+	tools := []llms.Tool{{Type: "function", Function: &llms.FunctionDefinition{Name: "search"}}}
+
+	apply := func(options []llms.CallOption) llms.CallOptions {
+		var opts llms.CallOptions
+		for _, option := range options {
+			option(&opts)
+		}
+		return opts
+	}
+
+	if opts := apply(localToolOptions(tools, false)); opts.ToolChoice != toolChoiceRequired || len(opts.Tools) != 1 {
+		t.Errorf("agents must be forced to call tools, got %+v", opts.ToolChoice)
+	}
+	if opts := apply(localToolOptions(tools, true)); opts.ToolChoice != nil {
+		t.Errorf("assistant must be allowed to answer with text, got %+v", opts.ToolChoice)
+	}
+}
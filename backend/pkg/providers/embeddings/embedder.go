@@ -25,6 +25,10 @@ type constructor func(cfg *config.Config, httpClient *http.Client) (embeddings.E
 type Embedder interface {
 	embeddings.Embedder
 	IsAvailable() bool
+	// Provider and Model identify the vector space of produced embeddings;
+	// both are empty when no embedding provider is configured.
+	Provider() string
+	Model() string
 }
 
 type embedder struct {
@@ -35,6 +39,20 @@ func (e *embedder) IsAvailable() bool {
 	return e.Embedder != nil
 }
 
+func (e *embedder) Provider() string {
+	if w, ok := e.Embedder.(*wrapper); ok {
+		return w.provider
+	}
+	return ""
+}
+
+func (e *embedder) Model() string {
+	if w, ok := e.Embedder.(*wrapper); ok {
+		return w.model
+	}
+	return ""
+}
+
 func New(cfg *config.Config) (Embedder, error) {
 	httpClient, err := system.GetHTTPClient(cfg)
 	if err != nil {
@@ -58,6 +76,8 @@ func New(cfg *config.Config) (Embedder, error) {
 		f = newGoogleAI
 	case "voyageai":
 		f = newVoyageAI
+	case "local":
+		f = newLocal
 	case "none":
 		return &embedder{nil}, nil
 	default:
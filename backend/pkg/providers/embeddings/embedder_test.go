@@ -24,6 +24,7 @@ func TestNew_AllProviders(t *testing.T) {
 		{"huggingface", "huggingface", true},
 		{"googleai", "googleai", true},
 		{"voyageai", "voyageai", true},
+		{"local", "local", true},
 		{"none", "none", false},
 	}
 
@@ -244,6 +245,64 @@ func TestIsAvailable_ValidEmbedder(t *testing.T) {
 	assert.True(t, e.IsAvailable())
 }
 
+func TestProviderAndModel(t *testing.T) {
+	t.Parallel()
+
+	e, err := New(&config.Config{
+		EmbeddingProvider: "openai",
+		OpenAIKey:         "test-key",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "openai", e.Provider())
+	assert.Equal(t, "text-embedding-ada-002", e.Model())
+
+	e, err = New(&config.Config{
+		EmbeddingProvider: "ollama",
+		EmbeddingURL:      "http://localhost:11434",
+		EmbeddingModel:    "nomic-embed-text",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ollama", e.Provider())
+	assert.Equal(t, "nomic-embed-text", e.Model())
+
+	none := &embedder{nil}
+	assert.Empty(t, none.Provider())
+	assert.Empty(t, none.Model())
+}
+
+func TestSwitchable(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		EmbeddingProvider: "openai",
+		EmbeddingModel:    "text-embedding-3-small",
+		OpenAIKey:         "test-key",
+	}
+	target, err := New(cfg)
+	require.NoError(t, err)
+
+	s := NewSwitchable(cfg, target)
+	assert.True(t, s.IsAvailable())
+	assert.False(t, s.Legacy())
+	assert.Equal(t, "text-embedding-3-small", s.Model())
+
+	require.NoError(t, s.UseModel("openai", "text-embedding-ada-002"))
+	assert.True(t, s.Legacy())
+	assert.Equal(t, "openai", s.Provider())
+	assert.Equal(t, "text-embedding-ada-002", s.Model())
+	assert.Equal(t, "text-embedding-3-small", s.Target().Model())
+
+	s.Cutover()
+	assert.False(t, s.Legacy())
+	assert.Equal(t, "text-embedding-3-small", s.Model())
+
+	err = s.UseModel("ollama", "nomic-embed-text")
+	assert.Error(t, err)
+	assert.False(t, s.Legacy())
+	// the legacy embedder is built from a copy of the configuration
+	assert.Equal(t, "text-embedding-3-small", cfg.EmbeddingModel)
+}
+
 func TestNew_Ollama_WithCustomModel(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,94 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	hashModelPrefix       = "hash"
+	defaultHashDimensions = 768
+	maxHashDimensions     = 4096
+	// hashTrigramWeight keeps character trigrams, which match spelling
+	// variants of identifiers, below whole words.
+	hashTrigramWeight = 0.5
+)
+
+// hashBackend embeds texts in-process by feature hashing of words and
+// character trigrams. Vectors are deterministic and need no model files, so
+// it suits tests and air-gapped setups where lexical similarity is enough.
+type hashBackend struct {
+	dimensions int
+}
+
+// parseHashModel returns the vector size of a "hash" or "hash-<dimensions>" model.
+func parseHashModel(model string) (int, error) {
+	if model == hashModelPrefix {
+		return defaultHashDimensions, nil
+	}
+	raw, ok := strings.CutPrefix(model, hashModelPrefix+"-")
+	if !ok {
+		return 0, fmt.Errorf("in-process embeddings support only %s-<dimensions> models, got %q", hashModelPrefix, model)
+	}
+	dimensions, err := strconv.Atoi(raw)
+	if err != nil || dimensions < 1 || dimensions > maxHashDimensions {
+		return 0, fmt.Errorf("invalid dimensions in embedding model %q: must be 1..%d", model, maxHashDimensions)
+	}
+	return dimensions, nil
+}
+
+func (b hashBackend) embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = hashEmbedding(text, b.dimensions)
+	}
+	return vectors, nil
+}
+
+// hashEmbedding returns the L2-normalized hashed feature vector of text.
+func hashEmbedding(text string, dimensions int) []float32 {
+	vector := make([]float64, dimensions)
+	add := func(feature string, weight float64) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		// the top bit picks the sign so colliding features tend to cancel out
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+		vector[sum%uint64(dimensions)] += weight
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		add("w:"+word, 1)
+		runes := []rune("^" + word + "$")
+		for i := 0; i+3 <= len(runes); i++ {
+			add("t:"+string(runes[i:i+3]), hashTrigramWeight)
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+
+	result := make([]float32, dimensions)
+	if norm == 0 {
+		// texts without words still need a valid direction for cosine distance
+		result[0] = 1
+		return result
+	}
+	norm = math.Sqrt(norm)
+	for i, v := range vector {
+		result[i] = float32(v / norm)
+	}
+	return result
+}
@@ -0,0 +1,198 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/observability/langfuse"
+
+	"github.com/vxcontrol/langchaingo/embeddings"
+)
+
+const (
+	defaultLocalBatchSize = 32
+	// maxLocalErrorBody limits how much of a failed sidecar response is quoted.
+	maxLocalErrorBody = 1024
+)
+
+// localBackend computes embeddings for one batch of already prepared texts.
+type localBackend interface {
+	embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// localEmbedder serves embeddings without remote services: in-process for
+// hash models or through a sidecar on EMBEDDING_URL. Texts are truncated to
+// EMBEDDING_MAX_TEXT_BYTES and sent in batches of EMBEDDING_BATCH_SIZE.
+type localEmbedder struct {
+	backend       localBackend
+	batchSize     int
+	maxTextBytes  int
+	stripNewLines bool
+}
+
+func newLocal(cfg *config.Config, httpClient *http.Client) (embeddings.Embedder, error) {
+	model, provider := cfg.EmbeddingModel, "local"
+
+	metadata := langfuse.Metadata{
+		"strip_new_lines": cfg.EmbeddingStripNewLines,
+		"batch_size":      cfg.EmbeddingBatchSize,
+		"max_text_bytes":  cfg.EmbeddingMaxTextBytes,
+	}
+
+	var backend localBackend
+	if cfg.EmbeddingURL != "" {
+		if model == "" {
+			model = "default"
+		}
+		if httpClient == nil {
+			httpClient = http.DefaultClient
+		}
+		backend = &sidecarBackend{
+			url:        cfg.EmbeddingURL,
+			key:        cfg.EmbeddingKey,
+			model:      model,
+			httpClient: httpClient,
+		}
+		metadata["url"] = cfg.EmbeddingURL
+	} else {
+		if model == "" {
+			model = fmt.Sprintf("%s-%d", hashModelPrefix, defaultHashDimensions)
+		}
+		dimensions, err := parseHashModel(model)
+		if err != nil {
+			return nil, fmt.Errorf("EMBEDDING_URL is not set and %w", err)
+		}
+		backend = hashBackend{dimensions: dimensions}
+	}
+
+	batchSize := cfg.EmbeddingBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultLocalBatchSize
+	}
+
+	return &wrapper{
+		model:    model,
+		provider: provider,
+		metadata: metadata,
+		Embedder: &localEmbedder{
+			backend:       backend,
+			batchSize:     batchSize,
+			maxTextBytes:  cfg.EmbeddingMaxTextBytes,
+			stripNewLines: cfg.EmbeddingStripNewLines,
+		},
+	}, nil
+}
+
+func (e *localEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += e.batchSize {
+		end := min(start+e.batchSize, len(texts))
+
+		batch := make([]string, 0, end-start)
+		for _, text := range texts[start:end] {
+			batch = append(batch, e.prepare(text))
+		}
+
+		batchVectors, err := e.backend.embed(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(batchVectors) != len(batch) {
+			return nil, fmt.Errorf("local embedder returned %d vectors for %d texts", len(batchVectors), len(batch))
+		}
+		vectors = append(vectors, batchVectors...)
+	}
+
+	return vectors, nil
+}
+
+func (e *localEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := e.EmbedDocuments(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func (e *localEmbedder) prepare(text string) string {
+	if e.stripNewLines {
+		text = strings.ReplaceAll(text, "\n", " ")
+	}
+	return truncateUTF8(text, e.maxTextBytes)
+}
+
+// truncateUTF8 cuts text to at most maxBytes without splitting a rune.
+func truncateUTF8(text string, maxBytes int) string {
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		return text
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
+// sidecarBackend speaks a minimal JSON protocol so any local ONNX or gguf
+// runtime can be put behind a thin adapter:
+//
+//	POST EMBEDDING_URL {"model": "...", "input": ["text", ...]}
+//	200 OK             {"embeddings": [[0.1, ...], ...]}
+//
+// Vectors must be returned in input order.
+type sidecarBackend struct {
+	url        string
+	key        string
+	model      string
+	httpClient *http.Client
+}
+
+type sidecarRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type sidecarResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+func (b *sidecarBackend) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(sidecarRequest{Model: b.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal local embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.key != "" {
+		req.Header.Set("Authorization", "Bearer "+b.key)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("local embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxLocalErrorBody))
+		return nil, fmt.Errorf("local embedding sidecar returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var result sidecarResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode local embedding response: %w", err)
+	}
+
+	return result.Embeddings, nil
+}
@@ -0,0 +1,138 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"pentagi/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+func TestNew_Local_Hash(t *testing.T) {
+	t.Parallel()
+
+	e, err := New(&config.Config{
+		EmbeddingProvider: "local",
+		EmbeddingModel:    "hash-64",
+	})
+	require.NoError(t, err)
+	assert.True(t, e.IsAvailable())
+	assert.Equal(t, "local", e.Provider())
+	assert.Equal(t, "hash-64", e.Model())
+
+	ctx := context.Background()
+	first, err := e.EmbedQuery(ctx, "nmap -sV scan of CVE-2021-44228")
+	require.NoError(t, err)
+	require.Len(t, first, 64)
+
+	again, err := e.EmbedQuery(ctx, "nmap -sV scan of CVE-2021-44228")
+	require.NoError(t, err)
+	assert.Equal(t, first, again, "hash embeddings must be deterministic")
+
+	related, err := e.EmbedQuery(ctx, "log4j CVE-2021-44228 exploitation")
+	require.NoError(t, err)
+	unrelated, err := e.EmbedQuery(ctx, "quarterly marketing budget review")
+	require.NoError(t, err)
+	assert.Greater(t, cosine(first, related), cosine(first, unrelated))
+
+	empty, err := e.EmbedQuery(ctx, "   ")
+	require.NoError(t, err)
+	assert.InDelta(t, 1, cosine(empty, empty), 1e-6, "empty text needs a valid direction")
+}
+
+func TestNew_Local_DefaultModel(t *testing.T) {
+	t.Parallel()
+
+	e, err := New(&config.Config{EmbeddingProvider: "local"})
+	require.NoError(t, err)
+	assert.Equal(t, "hash-768", e.Model())
+}
+
+func TestNew_Local_InvalidModel(t *testing.T) {
+	t.Parallel()
+
+	for _, model := range []string{"all-MiniLM-L6-v2", "hash-0", "hash-abc", "hash-100000"} {
+		e, err := New(&config.Config{EmbeddingProvider: "local", EmbeddingModel: model})
+		require.Error(t, err, model)
+		assert.False(t, e.IsAvailable())
+	}
+}
+
+func TestNew_Local_Sidecar(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	var maxInput atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var req sidecarRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "bge-small", req.Model)
+
+		resp := sidecarResponse{}
+		for _, text := range req.Input {
+			assert.NotContains(t, text, "\n")
+			maxInput.Store(max(maxInput.Load(), int32(len(text))))
+			resp.Embeddings = append(resp.Embeddings, []float32{float32(len(text)), 1})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	defer server.Close()
+
+	e, err := New(&config.Config{
+		EmbeddingProvider:      "local",
+		EmbeddingURL:           server.URL,
+		EmbeddingKey:           "secret",
+		EmbeddingModel:         "bge-small",
+		EmbeddingBatchSize:     2,
+		EmbeddingMaxTextBytes:  8,
+		EmbeddingStripNewLines: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "bge-small", e.Model())
+
+	vectors, err := e.EmbedDocuments(context.Background(), []string{"a", "b\nc", "ééééé", "long text over the limit", "z"})
+	require.NoError(t, err)
+	require.Len(t, vectors, 5)
+	assert.Equal(t, int32(3), requests.Load(), "five texts in batches of two")
+	assert.Equal(t, float32(1), vectors[0][0])
+	assert.Equal(t, float32(8), vectors[2][0], "truncation keeps whole runes")
+	assert.Equal(t, float32(8), vectors[3][0])
+	assert.LessOrEqual(t, maxInput.Load(), int32(8))
+}
+
+func TestNew_Local_SidecarError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	e, err := New(&config.Config{EmbeddingProvider: "local", EmbeddingURL: server.URL})
+	require.NoError(t, err)
+
+	_, err = e.EmbedQuery(context.Background(), "query")
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "model not loaded"), err.Error())
+}
@@ -0,0 +1,105 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"pentagi/pkg/config"
+)
+
+// Switchable serves embeddings from the model stored vectors belong to.
+// While a re-embedding migration rebuilds the vectors for the configured
+// model, queries and new documents keep using the previous model so searches
+// stay consistent; Cutover switches to the configured model once the new
+// vectors are in place.
+type Switchable struct {
+	cfg    *config.Config
+	target Embedder
+
+	mx     sync.RWMutex
+	legacy Embedder
+}
+
+// NewSwitchable wraps the embedder of the configured model.
+func NewSwitchable(cfg *config.Config, target Embedder) *Switchable {
+	return &Switchable{cfg: cfg, target: target}
+}
+
+// NewWithModel creates an embedder of the configured provider for another model.
+func NewWithModel(cfg *config.Config, model string) (Embedder, error) {
+	mcfg := *cfg
+	mcfg.EmbeddingModel = model
+	return New(&mcfg)
+}
+
+// Target returns the embedder of the configured model.
+func (s *Switchable) Target() Embedder {
+	return s.target
+}
+
+// UseModel routes embedding calls to a previous model until Cutover is called.
+// Only a model of the configured provider can be served: the endpoint and
+// credentials of another provider are no longer known.
+func (s *Switchable) UseModel(provider, model string) error {
+	if provider != s.target.Provider() {
+		return fmt.Errorf("embedding provider %s is no longer configured", provider)
+	}
+
+	legacy, err := NewWithModel(s.cfg, model)
+	if err != nil {
+		return fmt.Errorf("failed to create embedder for %s/%s: %w", provider, model, err)
+	}
+	if !legacy.IsAvailable() || legacy.Model() != model {
+		return fmt.Errorf("embedding provider %s does not support model selection", provider)
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.legacy = legacy
+
+	return nil
+}
+
+// Cutover switches embedding calls to the configured model.
+func (s *Switchable) Cutover() {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.legacy = nil
+}
+
+// Legacy reports whether calls are served by a previous model.
+func (s *Switchable) Legacy() bool {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.legacy != nil
+}
+
+func (s *Switchable) active() Embedder {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	if s.legacy != nil {
+		return s.legacy
+	}
+	return s.target
+}
+
+func (s *Switchable) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return s.active().EmbedDocuments(ctx, texts)
+}
+
+func (s *Switchable) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return s.active().EmbedQuery(ctx, text)
+}
+
+func (s *Switchable) IsAvailable() bool {
+	return s.target.IsAvailable()
+}
+
+func (s *Switchable) Provider() string {
+	return s.active().Provider()
+}
+
+func (s *Switchable) Model() string {
+	return s.active().Model()
+}
@@ -0,0 +1,130 @@
+package gemini
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"pentagi/pkg/cast"
+	"pentagi/pkg/providers/pconfig"
+
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/googleai"
+)
+
+const (
+	// minContextCacheTokens is the smallest system prompt worth an explicit
+	// context cache; Gemini rejects caches below its per-model minimum and
+	// storage is billed, so shorter prompts are left to implicit caching
+	minContextCacheTokens = 4096
+
+	// contextCacheExpiryMargin keeps a cache from being used right before it
+	// expires on the server side
+	contextCacheExpiryMargin = 30 * time.Second
+)
+
+// contextCache creates and reuses Gemini explicit context caches. Unlike
+// Anthropic and Bedrock, Gemini has no inline cache breakpoints: the cached
+// prefix is created up front and referenced by name, and a request that
+// references it must not repeat the system instruction or declare tools.
+type contextCache struct {
+	mx      sync.Mutex
+	helper  *googleai.CachingHelper
+	entries map[[32]byte]contextCacheEntry
+}
+
+type contextCacheEntry struct {
+	name      string // empty when creation failed and must not be retried yet
+	expiresAt time.Time
+}
+
+func newContextCache(helper *googleai.CachingHelper) *contextCache {
+	return &contextCache{
+		helper:  helper,
+		entries: make(map[[32]byte]contextCacheEntry),
+	}
+}
+
+// apply moves the leading system prompt of the chain into a context cache when
+// the agent caches its system prompt, the call declares no tools and the prompt
+// is large enough. It returns the chain to send and the options referencing the
+// cache; on any failure the chain is returned as is and the call is uncached.
+func (cc *contextCache) apply(
+	ctx context.Context,
+	model string,
+	chain []llms.MessageContent,
+	cache *pconfig.CacheConfig,
+) ([]llms.MessageContent, []llms.CallOption) {
+	if cc == nil || cc.helper == nil || cache == nil || !cache.System {
+		return chain, nil
+	}
+	if len(chain) < 2 || chain[0].Role != llms.ChatMessageTypeSystem {
+		return chain, nil
+	}
+	if cast.EstimateMessageTokens(&chain[0], pconfig.ModelTokenizerGemini.BytesPerToken()) < minContextCacheTokens {
+		return chain, nil
+	}
+
+	name := cc.cachedContentName(ctx, model, chain[0], cache.Duration())
+	if name == "" {
+		return chain, nil
+	}
+
+	return chain[1:], []llms.CallOption{googleai.WithCachedContent(name)}
+}
+
+func (cc *contextCache) cachedContentName(
+	ctx context.Context,
+	model string,
+	system llms.MessageContent,
+	ttl time.Duration,
+) string {
+	key := contextCacheKey(model, system)
+	now := time.Now()
+
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+
+	if entry, ok := cc.entries[key]; ok && now.Before(entry.expiresAt) {
+		return entry.name
+	}
+
+	// failed creations are remembered for a full TTL too, so a prompt below the
+	// model minimum or a rejected model does not cost a request on every call
+	entry := contextCacheEntry{expiresAt: now.Add(ttl - contextCacheExpiryMargin)}
+	displayName := fmt.Sprintf("pentagi-%x", key[:8])
+	cached, err := cc.helper.CreateCachedContent(ctx, model, []llms.MessageContent{system}, ttl, displayName)
+	if err == nil && cached != nil {
+		entry.name = cached.Name
+	}
+	cc.entries[key] = entry
+
+	cc.evictExpired(now)
+
+	return entry.name
+}
+
+func (cc *contextCache) evictExpired(now time.Time) {
+	for key, entry := range cc.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(cc.entries, key)
+		}
+	}
+}
+
+func contextCacheKey(model string, system llms.MessageContent) [32]byte {
+	hash := sha256.New()
+	hash.Write([]byte(model))
+	for _, part := range system.Parts {
+		if text, ok := part.(llms.TextContent); ok {
+			hash.Write([]byte{0})
+			hash.Write([]byte(text.Text))
+		}
+	}
+
+	var key [32]byte
+	copy(key[:], hash.Sum(nil))
+	return key
+}
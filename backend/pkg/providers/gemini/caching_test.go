@@ -0,0 +1,83 @@
+package gemini
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/providers/pconfig"
+
+	"github.com/vxcontrol/langchaingo/llms"
+	"github.com/vxcontrol/langchaingo/llms/googleai"
+)
+
+func TestContextCacheApply(t *testing.T) {
+	largeSystem := llms.TextParts(llms.ChatMessageTypeSystem, strings.Repeat("rules ", 4*minContextCacheTokens))
+	human := llms.TextParts(llms.ChatMessageTypeHuman, "question")
+	chain := []llms.MessageContent{largeSystem, human}
+	model := "gemini-2.5-pro"
+
+	cc := newContextCache(&googleai.CachingHelper{})
+	cc.entries[contextCacheKey(model, largeSystem)] = contextCacheEntry{
+		name:      "cachedContents/test",
+		expiresAt: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name    string
+		chain   []llms.MessageContent
+		cache   *pconfig.CacheConfig
+		cached  bool
+		options int
+	}{
+		{"not configured", chain, nil, false, 0},
+		{"history only", chain, &pconfig.CacheConfig{History: true}, false, 0},
+		{"small system prompt", []llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeSystem, "short"), human,
+		}, &pconfig.CacheConfig{System: true}, false, 0},
+		{"no system prompt", []llms.MessageContent{human, human}, &pconfig.CacheConfig{System: true}, false, 0},
+		{"cached system prompt", chain, &pconfig.CacheConfig{System: true}, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, options := cc.apply(t.Context(), model, tt.chain, tt.cache)
+			if len(options) != tt.options {
+				t.Errorf("expected %d options, got %d", tt.options, len(options))
+			}
+			if tt.cached && (len(result) != 1 || result[0].Role != llms.ChatMessageTypeHuman) {
+				t.Errorf("expected system prompt to be moved into the cache, got %v", result)
+			}
+			if !tt.cached && len(result) != len(tt.chain) {
+				t.Errorf("expected chain to stay unchanged, got %d messages", len(result))
+			}
+		})
+	}
+}
+
+func TestContextCacheFailedEntry(t *testing.T) {
+	system := llms.TextParts(llms.ChatMessageTypeSystem, "prompt")
+	model := "gemini-2.5-flash"
+
+	cc := newContextCache(&googleai.CachingHelper{})
+	cc.entries[contextCacheKey(model, system)] = contextCacheEntry{expiresAt: time.Now().Add(time.Minute)}
+
+	// a remembered failure is not retried until it expires
+	if name := cc.cachedContentName(t.Context(), model, system, time.Hour); name != "" {
+		t.Errorf("expected failed entry to disable caching, got %q", name)
+	}
+}
+
+func TestContextCacheKey(t *testing.T) {
+	system := llms.TextParts(llms.ChatMessageTypeSystem, "prompt")
+	if contextCacheKey("m1", system) == contextCacheKey("m2", system) {
+		t.Error("expected different models to use different caches")
+	}
+	other := llms.TextParts(llms.ChatMessageTypeSystem, "other prompt")
+	if contextCacheKey("m1", system) == contextCacheKey("m1", other) {
+		t.Error("expected different prompts to use different caches")
+	}
+	if contextCacheKey("m1", system) != contextCacheKey("m1", llms.TextParts(llms.ChatMessageTypeSystem, "prompt")) {
+		t.Error("expected equal prompts to share a cache")
+	}
+}
@@ -63,6 +63,7 @@ func DefaultModels() (pconfig.ModelsConfig, error) {
 
 type geminiProvider struct {
 	llm            *googleai.GoogleAI
+	cache          *contextCache
 	models         pconfig.ModelsConfig
 	providerName   provider.ProviderName
 	providerConfig *pconfig.ProviderConfig
@@ -105,8 +106,14 @@ func New(
 		return nil, err
 	}
 
+	cachingHelper, err := googleai.NewCachingHelper(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
 	return &geminiProvider{
 		llm:            client,
+		cache:          newContextCache(cachingHelper),
 		models:         models,
 		providerName:   providerName,
 		providerConfig: providerConfig,
@@ -169,12 +176,14 @@ func (p *geminiProvider) CallEx(
 	chain []llms.MessageContent,
 	streamCb streaming.Callback,
 ) (*llms.ContentResponse, error) {
-	return provider.WrapGenerateContent(
-		ctx, p, opt, p.llm.GenerateContent, chain,
-		append([]llms.CallOption{
-			llms.WithStreamingFunc(streamCb),
-		}, p.providerConfig.GetOptionsForType(opt)...)...,
-	)
+	options := append([]llms.CallOption{
+		llms.WithStreamingFunc(streamCb),
+	}, p.providerConfig.GetOptionsForType(opt)...)
+
+	chain, cacheOptions := p.cache.apply(ctx, p.Model(opt), chain, p.providerConfig.CacheConfigForType(opt))
+	options = append(options, cacheOptions...)
+
+	return provider.WrapGenerateContent(ctx, p, opt, p.llm.GenerateContent, chain, options...)
 }
 
 func (p *geminiProvider) CallWithTools(
@@ -209,6 +218,13 @@ func (p *geminiProvider) CallWithExtraOptions(
 	options = append(options, p.providerConfig.GetOptionsForType(opt)...)
 	options = append(options, extra...)
 
+	// cached content can not be combined with tool declarations
+	if len(tools) == 0 {
+		var cacheOptions []llms.CallOption
+		chain, cacheOptions = p.cache.apply(ctx, p.Model(opt), chain, p.providerConfig.CacheConfigForType(opt))
+		options = append(options, cacheOptions...)
+	}
+
 	return provider.WrapGenerateContent(ctx, p, opt, p.llm.GenerateContent, chain, options...)
 }
 
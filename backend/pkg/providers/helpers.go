@@ -299,6 +299,61 @@ func (fp *flowProvider) ensureChainConsistency(chain []llms.MessageContent) ([]l
 	return ast.Messages(), nil
 }
 
+// Binds the summarizer to the context window of the agent's model: the window
+// its models config declares, or else the largest prompt a TestProvider run
+// measured for it
+func (fp *flowProvider) contextSummarizer(
+	optAgentType pconfig.ProviderOptionsType,
+	summarizer csum.Summarizer,
+) csum.Summarizer {
+	if summarizer == nil {
+		return nil
+	}
+
+	model := fp.Model(optAgentType)
+	window := csum.ContextWindow{}
+	if modelConfig := fp.GetModels().FindModel(model); modelConfig != nil {
+		window.Tokens = modelConfig.ContextWindow
+		window.BytesPerToken = modelConfig.Tokenizer.BytesPerToken()
+	}
+	if window.Tokens <= 0 {
+		window.Tokens = fp.contextWindows[measuredModel{prvname: fp.Name(), model: model}]
+	}
+	if window.Tokens <= 0 {
+		return summarizer
+	}
+
+	return summarizer.WithContextWindow(window)
+}
+
+// supportsVision reports whether the agent's model is declared to accept
+// image parts in its models config.
+func (fp *flowProvider) supportsVision(optAgentType pconfig.ProviderOptionsType) bool {
+	modelConfig := fp.GetModels().FindModel(fp.Model(optAgentType))
+	return modelConfig != nil && modelConfig.Vision
+}
+
+// withAttachments returns the chain to send to the model with the images queued
+// by the last tool calls appended as a human message. The stored chain is left
+// untouched, so the images are seen once and do not grow the chain.
+func withAttachments(chain []llms.MessageContent, attachments []tools.Attachment) []llms.MessageContent {
+	if len(attachments) == 0 {
+		return chain
+	}
+
+	msg := llms.MessageContent{Role: llms.ChatMessageTypeHuman}
+	for _, attachment := range attachments {
+		msg.Parts = append(msg.Parts,
+			llms.TextContent{Text: attachment.Description},
+			llms.BinaryPart(attachment.MimeType, attachment.Data),
+		)
+	}
+
+	result := make([]llms.MessageContent, 0, len(chain)+1)
+	result = append(result, chain...)
+	return append(result, msg)
+}
+
 func (fp *flowProvider) getTaskPrimaryAgentChainSummary(
 	ctx context.Context,
 	taskID int64,
@@ -567,6 +622,7 @@ func (fp *flowProvider) restoreChain(
 				MaxQABytes:     maxQABytesAfterRestore,
 				KeepQASections: keepQASectionsAfterRestore,
 			})
+			summarizer = fp.contextSummarizer(optAgentType, summarizer)
 
 			chain, err = summarizer.SummarizeChain(ctx, summarizeHandler, ast.Messages(), fp.tcIDTemplate)
 			if err != nil {
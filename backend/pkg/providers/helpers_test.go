@@ -12,6 +12,7 @@ import (
 
 	"pentagi/pkg/cast"
 	"pentagi/pkg/config"
+	"pentagi/pkg/tools"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vxcontrol/langchaingo/llms"
@@ -1273,3 +1274,25 @@ func TestWrapToolCallIDTemplateError(t *testing.T) {
 		})
 	}
 }
+
+func TestWithAttachments(t *testing.T) {
+	chain := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, "system"),
+		llms.TextParts(llms.ChatMessageTypeHuman, "task"),
+	}
+
+	assert.Equal(t, chain, withAttachments(chain, nil))
+
+	result := withAttachments(chain, []tools.Attachment{
+		{Name: "s.png", MimeType: "image/png", Data: []byte("png"), Description: "Screenshot of 'https://example.com'"},
+	})
+	assert.Len(t, chain, 2, "stored chain must not be modified")
+	if assert.Len(t, result, 3) {
+		msg := result[2]
+		assert.Equal(t, llms.ChatMessageTypeHuman, msg.Role)
+		if assert.Len(t, msg.Parts, 2) {
+			assert.Equal(t, llms.TextContent{Text: "Screenshot of 'https://example.com'"}, msg.Parts[0])
+			assert.Equal(t, llms.BinaryPart("image/png", []byte("png")), msg.Parts[1])
+		}
+	}
+}
@@ -121,6 +121,20 @@ func (c *CallUsage) UpdateCost(price *PriceInfo) {
 	c.CostOutput = float64(c.Output) * price.Output / 1e6
 }
 
+// CacheHitRatio returns the share of input tokens served from the provider
+// prompt cache. Input token counts already include cache reads.
+func (c *CallUsage) CacheHitRatio() float64 {
+	return CacheHitRatio(c.Input, c.CacheRead)
+}
+
+// CacheHitRatio returns cacheRead/input clamped to [0, 1], zero without input.
+func CacheHitRatio(input, cacheRead int64) float64 {
+	if input <= 0 || cacheRead <= 0 {
+		return 0.0
+	}
+	return min(float64(cacheRead)/float64(input), 1.0)
+}
+
 func (c *CallUsage) IsZero() bool {
 	return c.Input == 0 &&
 		c.Output == 0 &&
@@ -176,6 +190,58 @@ type ModelConfig struct {
 	Thinking    *bool               `json:"thinking,omitempty" yaml:"thinking,omitempty"`
 	Reasoning   *ModelReasoningInfo `json:"reasoning,omitempty" yaml:"reasoning,omitempty"`
 	Price       *PriceInfo          `json:"price,omitempty" yaml:"price,omitempty"`
+	// ContextWindow is the model's context size in tokens; zero means unknown,
+	// in which case chain summarization falls back to the global byte limits.
+	ContextWindow int            `json:"context_window,omitempty" yaml:"context_window,omitempty"`
+	Tokenizer     ModelTokenizer `json:"tokenizer,omitempty" yaml:"tokenizer,omitempty"`
+	// Vision marks models that accept image parts; agents on other models get
+	// a text-only view instead of browser screenshots.
+	Vision bool `json:"vision,omitempty" yaml:"vision,omitempty"`
+}
+
+// ModelTokenizer names the tokenizer family of a model. It is only used to
+// estimate token counts from text size, so families with a similar density
+// share a value and exact vocabularies are not needed.
+type ModelTokenizer string
+
+const (
+	ModelTokenizerDefault ModelTokenizer = ""
+	ModelTokenizerO200K   ModelTokenizer = "o200k"  // GPT-4o and later OpenAI models
+	ModelTokenizerCL100K  ModelTokenizer = "cl100k" // GPT-4 / GPT-3.5 and most OpenAI-compatible models
+	ModelTokenizerClaude  ModelTokenizer = "claude"
+	ModelTokenizerGemini  ModelTokenizer = "gemini"
+	ModelTokenizerLlama   ModelTokenizer = "llama" // Llama, Mistral, Qwen and other SentencePiece/BPE local models
+)
+
+// BytesPerToken returns the average number of UTF-8 bytes per token for the
+// tokenizer family on the mixed prose, code and tool output of agent chains.
+// The values lean low so estimates err on the side of more tokens.
+func (t ModelTokenizer) BytesPerToken() float64 {
+	switch t {
+	case ModelTokenizerO200K:
+		return 3.8
+	case ModelTokenizerCL100K:
+		return 3.6
+	case ModelTokenizerClaude:
+		return 3.3
+	case ModelTokenizerGemini:
+		return 3.8
+	case ModelTokenizerLlama:
+		return 3.2
+	default:
+		return 3.0
+	}
+}
+
+// FindModel returns the config of the named model, or nil if it is unknown.
+func (mc ModelsConfig) FindModel(name string) *ModelConfig {
+	for idx := range mc {
+		if mc[idx].Name == name {
+			return &mc[idx]
+		}
+	}
+
+	return nil
 }
 
 // ModelReasoningMode declares a model's reasoning capability. It is distinct from
@@ -259,7 +325,67 @@ type AgentConfig struct {
 	Reasoning         ReasoningConfig `json:"reasoning,omitempty" yaml:"reasoning,omitempty"`
 	Price             *PriceInfo      `json:"price,omitempty" yaml:"price,omitempty"`
 	ExtraBody         map[string]any  `json:"extra_body,omitempty" yaml:"extra_body,omitempty"`
-	raw               map[string]any  `json:"-" yaml:"-"`
+	// ToolCallFixer routes tool call arguments that are not a valid JSON object
+	// through the tool call fixer agent before execution instead of waiting for
+	// the tool itself to reject them. It is not a wire option (BuildOptions
+	// ignores it); it is set by hand or by capability-based tuning for models
+	// whose tool calling proved unreliable under ctester.
+	ToolCallFixer bool `json:"tool_call_fixer,omitempty" yaml:"tool_call_fixer,omitempty"`
+	// Cache places prompt cache breakpoints on the stable prefix of the agent's
+	// calls. Like ToolCallFixer it is not a generic wire option: only providers
+	// with explicit prompt caching read it (see CacheConfigForType).
+	Cache *CacheConfig   `json:"cache,omitempty" yaml:"cache,omitempty"`
+	raw   map[string]any `json:"-" yaml:"-"`
+}
+
+// CacheTTL is the lifetime of a prompt cache entry
+type CacheTTL string
+
+const (
+	CacheTTLDefault  CacheTTL = ""
+	CacheTTL5Minutes CacheTTL = "5m"
+	CacheTTLOneHour  CacheTTL = "1h"
+)
+
+// CacheConfig selects which stable parts of an agent call are marked for
+// provider-side prompt caching. Each flag is a breakpoint: the provider caches
+// the whole request prefix up to it, so later breakpoints include earlier ones.
+//   - Anthropic honors every flag as an inline cache_control breakpoint and
+//     caches all three with a 5m TTL for agents without a cache section;
+//   - Bedrock (Converse API) caches the system prompt and the history together
+//     once any flag is set, tool definitions are not cacheable there;
+//   - Gemini creates explicit context caches for the system prompt of calls
+//     without tools, its implicit caching covers the rest.
+type CacheConfig struct {
+	System  bool     `json:"system,omitempty" yaml:"system,omitempty"`   // system prompt
+	Tools   bool     `json:"tools,omitempty" yaml:"tools,omitempty"`     // tool definitions
+	History bool     `json:"history,omitempty" yaml:"history,omitempty"` // conversation up to the last message, summarized parts included
+	TTL     CacheTTL `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+func (cc *CacheConfig) IsEnabled() bool {
+	return cc != nil && (cc.System || cc.Tools || cc.History)
+}
+
+// Duration returns the cache entry lifetime, five minutes unless one hour is set
+func (cc *CacheConfig) Duration() time.Duration {
+	if cc != nil && cc.TTL == CacheTTLOneHour {
+		return time.Hour
+	}
+	return 5 * time.Minute
+}
+
+func (cc *CacheConfig) Validate() error {
+	if cc == nil {
+		return nil
+	}
+
+	switch cc.TTL {
+	case CacheTTLDefault, CacheTTL5Minutes, CacheTTLOneHour:
+		return nil
+	default:
+		return fmt.Errorf("cache.ttl %q must be one of %q, %q", cc.TTL, CacheTTL5Minutes, CacheTTLOneHour)
+	}
 }
 
 // ProviderConfig represents the configuration for all agents
@@ -344,6 +470,9 @@ func (ac *AgentConfig) Validate() error {
 		(ac.Price.Input < 0 || ac.Price.Output < 0 || ac.Price.CacheRead < 0 || ac.Price.CacheWrite < 0) {
 		return fmt.Errorf("price values must be >= 0")
 	}
+	if err := ac.Cache.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -476,6 +605,18 @@ func (mc *ModelConfig) UnmarshalJSON(data []byte) error {
 		mc.Reasoning = &reasoning
 	}
 
+	if contextWindow, ok := raw["context_window"].(float64); ok {
+		mc.ContextWindow = int(contextWindow)
+	}
+
+	if tokenizer, ok := raw["tokenizer"].(string); ok {
+		mc.Tokenizer = ModelTokenizer(tokenizer)
+	}
+
+	if vision, ok := raw["vision"].(bool); ok {
+		mc.Vision = vision
+	}
+
 	return nil
 }
 
@@ -539,6 +680,18 @@ func (mc *ModelConfig) UnmarshalYAML(value *yaml.Node) error {
 		mc.Reasoning = &reasoning
 	}
 
+	if contextWindow, ok := raw["context_window"].(int); ok {
+		mc.ContextWindow = contextWindow
+	}
+
+	if tokenizer, ok := raw["tokenizer"].(string); ok {
+		mc.Tokenizer = ModelTokenizer(tokenizer)
+	}
+
+	if vision, ok := raw["vision"].(bool); ok {
+		mc.Vision = vision
+	}
+
 	return nil
 }
 
@@ -564,6 +717,15 @@ func (mc ModelConfig) MarshalJSON() ([]byte, error) {
 	if mc.Reasoning != nil {
 		aux["reasoning"] = mc.Reasoning
 	}
+	if mc.ContextWindow > 0 {
+		aux["context_window"] = mc.ContextWindow
+	}
+	if mc.Tokenizer != ModelTokenizerDefault {
+		aux["tokenizer"] = string(mc.Tokenizer)
+	}
+	if mc.Vision {
+		aux["vision"] = mc.Vision
+	}
 
 	return json.Marshal(aux)
 }
@@ -590,6 +752,15 @@ func (mc ModelConfig) MarshalYAML() (any, error) {
 	if mc.Reasoning != nil {
 		aux["reasoning"] = mc.Reasoning
 	}
+	if mc.ContextWindow > 0 {
+		aux["context_window"] = mc.ContextWindow
+	}
+	if mc.Tokenizer != ModelTokenizerDefault {
+		aux["tokenizer"] = string(mc.Tokenizer)
+	}
+	if mc.Vision {
+		aux["vision"] = mc.Vision
+	}
 
 	return aux, nil
 }
@@ -818,10 +989,54 @@ func (ac *AgentConfig) marshalMap() map[string]any {
 	if ac.ExtraBody != nil {
 		output["extra_body"] = ac.ExtraBody
 	}
+	if ac.ToolCallFixer {
+		output["tool_call_fixer"] = ac.ToolCallFixer
+	}
+	if ac.Cache != nil {
+		output["cache"] = ac.Cache
+	}
 
 	return output
 }
 
+// SetJSON switches JSON mode on or off. BuildOptions gates JSON mode on the
+// "json" key being present in the raw map rather than on the field value, so
+// the raw map is kept in sync here; assigning ac.JSON directly on a loaded
+// config would not change what is sent on the wire.
+func (ac *AgentConfig) SetJSON(enabled bool) {
+	if ac == nil {
+		return
+	}
+
+	ac.JSON = enabled
+	if ac.raw == nil {
+		return
+	}
+	if enabled {
+		ac.raw["json"] = true
+	} else {
+		delete(ac.raw, "json")
+	}
+}
+
+// SetToolCallFixer enables or disables pre-execution repair of malformed tool
+// call arguments, keeping the raw map in sync so the value survives marshaling.
+func (ac *AgentConfig) SetToolCallFixer(enabled bool) {
+	if ac == nil {
+		return
+	}
+
+	ac.ToolCallFixer = enabled
+	if ac.raw == nil {
+		return
+	}
+	if enabled {
+		ac.raw["tool_call_fixer"] = true
+	} else {
+		delete(ac.raw, "tool_call_fixer")
+	}
+}
+
 func (ac *AgentConfig) MarshalJSON() ([]byte, error) {
 	if ac == nil {
 		return []byte("null"), nil
@@ -980,6 +1195,17 @@ func (pc *ProviderConfig) AgentConfigForType(optType ProviderOptionsType) *Agent
 	}
 }
 
+// CacheConfigForType returns the prompt caching settings of the agent, or nil
+// when the agent leaves caching to the provider default. A non-nil config with
+// every flag off explicitly disables caching.
+func (pc *ProviderConfig) CacheConfigForType(optType ProviderOptionsType) *CacheConfig {
+	if agentConfig := pc.AgentConfigForType(optType); agentConfig != nil {
+		return agentConfig.Cache
+	}
+
+	return nil
+}
+
 func (pc *ProviderConfig) GetPriceInfoForType(optType ProviderOptionsType) *PriceInfo {
 	if agentConfig := pc.AgentConfigForType(optType); agentConfig != nil {
 		return agentConfig.Price
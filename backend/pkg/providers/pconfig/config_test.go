@@ -204,6 +204,41 @@ func TestModelConfigReasoningCapability(t *testing.T) {
 	assert.Equal(t, []llms.ReasoningEffort{"low", "high", "xhigh"}, backYAML.Reasoning.Efforts)
 }
 
+// TestModelConfigContextWindow guards the context window and tokenizer metadata
+// through the custom (un)marshalers: the summarizer budgets chains from them.
+func TestModelConfigContextWindow(t *testing.T) {
+	yamlData := []byte("- name: m1\n  context_window: 32768\n  tokenizer: llama\n  vision: true\n- name: m2\n")
+	models, err := LoadModelsConfigData(yamlData)
+	require.NoError(t, err)
+	require.Len(t, models, 2)
+	assert.Equal(t, 32768, models[0].ContextWindow)
+	assert.Equal(t, ModelTokenizerLlama, models[0].Tokenizer)
+	assert.True(t, models[0].Vision)
+	assert.Zero(t, models[1].ContextWindow)
+	assert.False(t, models[1].Vision)
+
+	jsonBytes, err := json.Marshal(models[0])
+	require.NoError(t, err)
+	var backJSON ModelConfig
+	require.NoError(t, json.Unmarshal(jsonBytes, &backJSON))
+	assert.Equal(t, 32768, backJSON.ContextWindow)
+	assert.Equal(t, ModelTokenizerLlama, backJSON.Tokenizer)
+	assert.True(t, backJSON.Vision)
+
+	yamlBytes, err := yaml.Marshal(models[0])
+	require.NoError(t, err)
+	var backYAML ModelConfig
+	require.NoError(t, yaml.Unmarshal(yamlBytes, &backYAML))
+	assert.Equal(t, 32768, backYAML.ContextWindow)
+	assert.Equal(t, ModelTokenizerLlama, backYAML.Tokenizer)
+	assert.True(t, backYAML.Vision)
+
+	require.NotNil(t, models.FindModel("m1"))
+	assert.Nil(t, models.FindModel("unknown"))
+	assert.Less(t, ModelTokenizerLlama.BytesPerToken(), ModelTokenizerO200K.BytesPerToken())
+	assert.Equal(t, 3.0, ModelTokenizerDefault.BytesPerToken())
+}
+
 func TestReasoningConfig_EffectiveMode(t *testing.T) {
 	tests := []struct {
 		name string
@@ -2169,3 +2204,53 @@ func TestModelConfig_MarshalYAML(t *testing.T) {
 		})
 	}
 }
+
+func TestAgentConfigCache(t *testing.T) {
+	var cfg ProviderConfig
+	err := yaml.Unmarshal([]byte(`
+simple:
+  model: default-model
+coder:
+  model: cached-model
+  cache:
+    system: true
+    tools: true
+    ttl: 1h
+installer:
+  model: uncached-model
+  cache: {}
+`), &cfg)
+	require.NoError(t, err)
+
+	assert.Nil(t, cfg.CacheConfigForType(OptionsTypeSimple), "no cache section keeps the provider default")
+
+	coder := cfg.CacheConfigForType(OptionsTypeCoder)
+	require.NotNil(t, coder)
+	assert.True(t, coder.IsEnabled())
+	assert.Equal(t, CacheConfig{System: true, Tools: true, TTL: CacheTTLOneHour}, *coder)
+	assert.Equal(t, time.Hour, coder.Duration())
+
+	installer := cfg.CacheConfigForType(OptionsTypeInstaller)
+	require.NotNil(t, installer)
+	assert.False(t, installer.IsEnabled(), "empty cache section disables caching")
+	assert.Equal(t, 5*time.Minute, installer.Duration())
+
+	data, err := json.Marshal(cfg.Coder)
+	require.NoError(t, err)
+	var restored AgentConfig
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, coder, restored.Cache)
+
+	invalid := AgentConfig{Model: "model", Cache: &CacheConfig{System: true, TTL: "10m"}}
+	assert.Error(t, invalid.Validate())
+}
+
+func TestCacheHitRatio(t *testing.T) {
+	assert.Equal(t, 0.0, CacheHitRatio(0, 100))
+	assert.Equal(t, 0.0, CacheHitRatio(100, 0))
+	assert.Equal(t, 0.25, CacheHitRatio(400, 100))
+	assert.Equal(t, 1.0, CacheHitRatio(100, 150), "ratio is clamped")
+
+	usage := CallUsage{Input: 1000, CacheRead: 900}
+	assert.Equal(t, 0.9, usage.CacheHitRatio())
+}
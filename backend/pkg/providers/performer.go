@@ -64,6 +64,14 @@ func (fp *flowProvider) performAgentChain(
 		summarizerHandler = fp.GetSummarizeResultHandler(taskID, subtaskID)
 	)
 
+	summarizer = fp.contextSummarizer(optAgentType, summarizer)
+
+	// tool calls of this chain queue images (e.g. browser screenshots) here;
+	// they are sent with the next model call only and never stored in the chain
+	var attachments []tools.Attachment
+	ctx = tools.PutVisionSupport(ctx, fp.supportsVision(optAgentType))
+	ctx = tools.PutAttachments(ctx)
+
 	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(fp.flowID, taskID, subtaskID, logrus.Fields{
 		"provider":     fp.Type(),
 		"agent":        optAgentType,
@@ -125,7 +133,10 @@ func (fp *flowProvider) performAgentChain(
 				),
 			}
 		} else {
-			result, err = fp.callWithRetries(ctx, optAgentType, chainID, taskID, subtaskID, chain, executor, executionContext)
+			callChain := withAttachments(chain, attachments)
+			attachments = nil
+
+			result, err = fp.callWithRetries(ctx, optAgentType, chainID, taskID, subtaskID, callChain, executor, executionContext)
 			if err != nil {
 				obs.LogErrorOrCancel(logger, err, "failed to call agent chain")
 				return err
@@ -192,6 +203,7 @@ func (fp *flowProvider) performAgentChain(
 			response, err := fp.execToolCall(
 				ctx, optAgentType, chainID, idx, result, monitor, detector, executor, taskID, subtaskID, chain,
 			)
+			attachments = append(attachments, tools.PopAttachments(ctx)...)
 
 			if toolTypeMapping[funcName] != tools.AgentToolType {
 				fp.storeToolExecutionToGraphiti(
@@ -330,6 +342,23 @@ func (fp *flowProvider) execToolCall(
 		response string
 	)
 
+	if fp.usesToolCallFixer(optAgentType) && !isJSONObject(funcArgs) {
+		// The model is known to produce malformed arguments (see
+		// pconfig.AgentConfig.ToolCallFixer), so repair them up front rather
+		// than spending the first retry on a call the tool will reject anyway.
+		funcSchema, err := executor.GetToolSchema(funcName)
+		if err != nil {
+			logger.WithError(err).Error("failed to get tool schema")
+			return "", fmt.Errorf("failed to get tool schema: %w", err)
+		}
+
+		funcArgs, err = fp.fixToolCallArgs(ctx, funcName, funcArgs, funcSchema, errToolCallArgsNotObject)
+		if err != nil {
+			obs.LogErrorOrCancel(logger, err, "failed to fix tool call args")
+			return "", fmt.Errorf("failed to fix tool call args: %w", err)
+		}
+	}
+
 	for idx := 0; idx <= maxRetriesToCallFunction; idx++ {
 		if idx == maxRetriesToCallFunction {
 			err = fmt.Errorf("reached max retries to call function: %w", err)
@@ -18,6 +18,7 @@ import (
 	"pentagi/pkg/graphiti"
 	obs "pentagi/pkg/observability"
 	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/providers/anonymizer"
 	"pentagi/pkg/providers/embeddings"
 	"pentagi/pkg/providers/pconfig"
 	"pentagi/pkg/providers/provider"
@@ -164,10 +165,18 @@ type flowProvider struct {
 
 	summarizerCache *lru.Cache[[32]byte, string]
 
+	// contextWindows holds the measured windows of the user's models, used
+	// when the models config of a provider declares none
+	contextWindows map[measuredModel]int
+
 	maxGACallsLimit int
 	maxLACallsLimit int
 	buildMonitor    executionMonitorBuilder
 
+	// anonymizer is nil unless LLM_ANONYMIZATION_ENABLED is set, a provider
+	// set later is wrapped the same way as the first one
+	anonymizer *anonymizer.Anonymizer
+
 	provider.Provider
 }
 
@@ -206,6 +215,7 @@ func (fp *flowProvider) SetProvider(ctx context.Context, newProvider provider.Pr
 
 	fp.mx.RLock()
 	current, prompter := fp.Provider, fp.prompter
+	newProvider = fp.anonymizer.Wrap(newProvider)
 	fp.mx.RUnlock()
 
 	if current != nil && current.Name() == newProvider.Name() &&
@@ -44,6 +44,7 @@ type modelInfo struct {
 	Description         string       `json:"description,omitempty"`
 	SupportedParameters []string     `json:"supported_parameters,omitempty"`
 	Pricing             *pricingInfo `json:"pricing,omitempty"`
+	ContextLength       *int         `json:"context_length,omitempty"`
 }
 
 // fallbackModelInfo represents simplified model structure for fallback parsing
@@ -168,6 +169,11 @@ func parseFullModels(models []modelInfo, prefix string) pconfig.ModelsConfig {
 			modelConfig.ReleaseDate = &releaseDate
 		}
 
+		// Parse context window if available
+		if model.ContextLength != nil && *model.ContextLength > 0 {
+			modelConfig.ContextWindow = *model.ContextLength
+		}
+
 		// Check for reasoning support in supported_parameters
 		if len(model.SupportedParameters) > 0 {
 			thinking := slices.Contains(model.SupportedParameters, "reasoning")
@@ -236,6 +236,7 @@ func TestLoadModelsFromHTTP_WithoutPrefix(t *testing.T) {
 					"created": 1686588896,
 					"description": "Model B description",
 					"supported_parameters": ["reasoning", "tools"],
+					"context_length": 131072,
 					"pricing": {
 						"prompt": "0.0001",
 						"completion": "0.0005"
@@ -273,6 +274,12 @@ func TestLoadModelsFromHTTP_WithoutPrefix(t *testing.T) {
 	if models[1].Thinking == nil || !*models[1].Thinking {
 		t.Error("Expected thinking capability for second model")
 	}
+	if models[1].ContextWindow != 131072 {
+		t.Errorf("Expected context window 131072, got %d", models[1].ContextWindow)
+	}
+	if models[0].ContextWindow != 0 {
+		t.Errorf("Expected unknown context window for first model, got %d", models[0].ContextWindow)
+	}
 	if models[1].Price == nil {
 		t.Error("Expected pricing for second model")
 	} else {
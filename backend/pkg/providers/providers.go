@@ -20,12 +20,14 @@ import (
 	"pentagi/pkg/docker"
 	"pentagi/pkg/graphiti"
 	obs "pentagi/pkg/observability"
+	"pentagi/pkg/providers/anonymizer"
 	"pentagi/pkg/providers/embeddings"
 	"pentagi/pkg/providers/pconfig"
 	"pentagi/pkg/providers/provider"
 	"pentagi/pkg/providers/tester"
 	"pentagi/pkg/templates"
 	"pentagi/pkg/tools"
+	"pentagi/pkg/vault"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/sirupsen/logrus"
@@ -131,6 +133,8 @@ type ProviderController interface {
 	) (tester.AgentTestResults, error)
 	TestProvider(
 		ctx context.Context,
+		userID int64,
+		prvID *int64,
 		prvtype provider.ProviderType,
 		config *pconfig.ProviderConfig,
 	) (tester.ProviderTestResults, error)
@@ -191,9 +195,14 @@ func NewProviderController(
 		return nil, fmt.Errorf("config is required")
 	}
 
-	embedder, err := embeddings.New(cfg)
-	if err != nil {
+	var embedder embeddings.Embedder
+	if e, err := embeddings.New(cfg); err != nil {
 		logrus.WithError(err).Errorf("failed to create embedder '%s'", cfg.EmbeddingProvider)
+		embedder = e
+	} else {
+		// lets the knowledge store keep serving the previous model while
+		// stored vectors are migrated to a newly configured one
+		embedder = embeddings.NewSwitchable(cfg, e)
 	}
 
 	providers := make(provider.Providers)
@@ -225,6 +234,7 @@ func NewProviderController(
 		MaxQASections:  cfg.SummarizerMaxQASections,
 		MaxQABytes:     cfg.SummarizerMaxQABytes,
 		KeepQASections: cfg.SummarizerKeepQASections,
+		ContextPercent: cfg.SummarizerContextPercent,
 	})
 
 	summarizerAssistant := csum.NewSummarizer(csum.SummarizerConfig{
@@ -236,16 +246,27 @@ func NewProviderController(
 		MaxQASections:  cfg.AssistantSummarizerMaxQASections,
 		MaxQABytes:     cfg.AssistantSummarizerMaxQABytes,
 		KeepQASections: cfg.AssistantSummarizerKeepQASections,
+		ContextPercent: cfg.AssistantSummarizerContextPercent,
 	})
 
-	graphitiClient, err := graphiti.NewClient(
-		cfg.GraphitiURL,
-		time.Duration(cfg.GraphitiTimeout)*time.Second,
-		cfg.GraphitiEnabled && cfg.GraphitiURL != "",
-	)
-	if err != nil {
-		logrus.WithError(err).Warn("failed to initialize graphiti client, continuing without it")
-		graphitiClient = &graphiti.Client{}
+	var graphitiClient *graphiti.Client
+	switch cfg.KnowledgeGraphBackend {
+	case "postgres":
+		graphitiClient = graphiti.NewPostgresClient(db, time.Duration(cfg.GraphitiTimeout)*time.Second, true)
+	default:
+		if cfg.KnowledgeGraphBackend != "graphiti" {
+			logrus.WithField("backend", cfg.KnowledgeGraphBackend).
+				Warn("unknown knowledge graph backend, falling back to graphiti")
+		}
+		graphitiClient, err = graphiti.NewClient(
+			cfg.GraphitiURL,
+			time.Duration(cfg.GraphitiTimeout)*time.Second,
+			cfg.GraphitiEnabled && cfg.GraphitiURL != "",
+		)
+		if err != nil {
+			logrus.WithError(err).Warn("failed to initialize graphiti client, continuing without it")
+			graphitiClient = &graphiti.Client{}
+		}
 	}
 
 	pc := &providerController{
@@ -301,6 +322,12 @@ func (pc *providerController) NewFlowProvider(
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
 
+	anon, err := pc.newAnonymizer(ctx, flowID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anonymizer: %w", err)
+	}
+	prv = anon.Wrap(prv)
+
 	imageTmpl, err := prompter.RenderTemplate(templates.PromptTypeImageChooser, map[string]any{
 		"DefaultImage":           pc.docker.GetDefaultImage(),
 		"DefaultImageForPentest": pc.cfg.DockerDefaultImageForPentest,
@@ -354,7 +381,7 @@ func (pc *providerController) NewFlowProvider(
 		db:              pc.db,
 		mx:              &sync.RWMutex{},
 		cfg:             pc.cfg,
-		embedder:        pc.embedder,
+		embedder:        anon.WrapEmbedder(pc.embedder),
 		graphitiClient:  pc.graphitiClient,
 		flowID:          flowID,
 		callCounter:     newAtomicInt64(pc.startCallNumber.Add(deltaCallCounter)),
@@ -368,6 +395,8 @@ func (pc *providerController) NewFlowProvider(
 		executor:        executor,
 		summarizer:      pc.summarizerAgent,
 		summarizerCache: newSummarizerCache(),
+		contextWindows:  pc.measuredContextWindows(ctx, userID),
+		anonymizer:      anon,
 		Provider:        prv,
 		maxGACallsLimit: pc.cfg.MaxGeneralAgentToolCalls,
 		maxLACallsLimit: pc.cfg.MaxLimitedAgentToolCalls,
@@ -400,11 +429,17 @@ func (pc *providerController) LoadFlowProvider(
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
 
+	anon, err := pc.newAnonymizer(ctx, flowID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anonymizer: %w", err)
+	}
+	prv = anon.Wrap(prv)
+
 	fp := &flowProvider{
 		db:              pc.db,
 		mx:              &sync.RWMutex{},
 		cfg:             pc.cfg,
-		embedder:        pc.embedder,
+		embedder:        anon.WrapEmbedder(pc.embedder),
 		graphitiClient:  pc.graphitiClient,
 		flowID:          flowID,
 		callCounter:     newAtomicInt64(pc.startCallNumber.Add(deltaCallCounter)),
@@ -418,6 +453,8 @@ func (pc *providerController) LoadFlowProvider(
 		executor:        executor,
 		summarizer:      pc.summarizerAgent,
 		summarizerCache: newSummarizerCache(),
+		contextWindows:  pc.measuredContextWindows(ctx, userID),
+		anonymizer:      anon,
 		Provider:        prv,
 		maxGACallsLimit: pc.cfg.MaxGeneralAgentToolCalls,
 		maxLACallsLimit: pc.cfg.MaxLimitedAgentToolCalls,
@@ -433,6 +470,16 @@ func (pc *providerController) LoadFlowProvider(
 	return fp, nil
 }
 
+// newAnonymizer returns the anonymization pipeline of a flow, or nil when
+// LLM_ANONYMIZATION_ENABLED is off
+func (pc *providerController) newAnonymizer(ctx context.Context, flowID, userID int64) (*anonymizer.Anonymizer, error) {
+	if !pc.cfg.LLMAnonymizationEnabled {
+		return nil, nil
+	}
+
+	return anonymizer.New(ctx, pc.db, pc.cfg, vault.New(pc.db, pc.cfg), flowID, userID)
+}
+
 func (pc *providerController) Embedder() embeddings.Embedder {
 	return pc.embedder
 }
@@ -458,6 +505,12 @@ func (pc *providerController) NewAssistantProvider(
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
 
+	anon, err := pc.newAnonymizer(ctx, flowID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anonymizer: %w", err)
+	}
+	prv = anon.Wrap(prv)
+
 	languageTmpl, err := prompter.RenderTemplate(templates.PromptTypeLanguageChooser, map[string]any{
 		"Input": input,
 	})
@@ -499,7 +552,7 @@ func (pc *providerController) NewAssistantProvider(
 			db:              pc.db,
 			mx:              &sync.RWMutex{},
 			cfg:             pc.cfg,
-			embedder:        pc.embedder,
+			embedder:        anon.WrapEmbedder(pc.embedder),
 			graphitiClient:  pc.graphitiClient,
 			flowID:          flowID,
 			callCounter:     newAtomicInt64(pc.startCallNumber.Add(deltaCallCounter)),
@@ -512,6 +565,8 @@ func (pc *providerController) NewAssistantProvider(
 			streamCb:        streamCb,
 			summarizer:      pc.summarizerAgent,
 			summarizerCache: newSummarizerCache(),
+			contextWindows:  pc.measuredContextWindows(ctx, userID),
+			anonymizer:      anon,
 			Provider:        prv,
 			maxGACallsLimit: pc.cfg.MaxGeneralAgentToolCalls,
 			maxLACallsLimit: pc.cfg.MaxLimitedAgentToolCalls,
@@ -545,6 +600,12 @@ func (pc *providerController) LoadAssistantProvider(
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
 
+	anon, err := pc.newAnonymizer(ctx, flowID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anonymizer: %w", err)
+	}
+	prv = anon.Wrap(prv)
+
 	ap := &assistantProvider{
 		id:         assistantID,
 		summarizer: pc.summarizerAssistant,
@@ -552,7 +613,7 @@ func (pc *providerController) LoadAssistantProvider(
 			db:              pc.db,
 			mx:              &sync.RWMutex{},
 			cfg:             pc.cfg,
-			embedder:        pc.embedder,
+			embedder:        anon.WrapEmbedder(pc.embedder),
 			graphitiClient:  pc.graphitiClient,
 			flowID:          flowID,
 			callCounter:     newAtomicInt64(pc.startCallNumber.Add(deltaCallCounter)),
@@ -565,6 +626,8 @@ func (pc *providerController) LoadAssistantProvider(
 			streamCb:        streamCb,
 			summarizer:      pc.summarizerAgent,
 			summarizerCache: newSummarizerCache(),
+			contextWindows:  pc.measuredContextWindows(ctx, userID),
+			anonymizer:      anon,
 			Provider:        prv,
 			maxGACallsLimit: pc.cfg.MaxGeneralAgentToolCalls,
 			maxLACallsLimit: pc.cfg.MaxLimitedAgentToolCalls,
@@ -606,6 +669,18 @@ func (pc *providerController) GetProvider(
 		return pc.NewProvider(prv)
 	}
 
+	// Then providers other members share through the projects of the user
+	prv, err = pc.db.GetUserProjectProviderByName(ctx, database.GetUserProjectProviderByNameParams{
+		Name:   string(prvname),
+		UserID: userID,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get project provider '%s' from database: %w", prvname, err)
+	}
+	if err == nil {
+		return pc.NewProvider(prv)
+	}
+
 	// Fall back to built-in default providers
 	return pc.Providers.Get(prvname)
 }
@@ -621,6 +696,28 @@ func (pc *providerController) GetProviders(
 		providersMap[prvname] = prv
 	}
 
+	// Copy providers shared through projects, the own providers of the user
+	// override them and the oldest one wins among the shared ones
+	shared, err := pc.db.GetUserProjectProviders(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project providers: %w", err)
+	}
+
+	sharedNames := make(map[string]struct{}, len(shared))
+	for _, prv := range shared {
+		if _, ok := sharedNames[prv.Name]; ok {
+			continue
+		}
+		sharedNames[prv.Name] = struct{}{}
+
+		p, err := pc.NewProvider(prv)
+		if err != nil {
+			logrus.WithError(err).Warnf("skipping unusable project provider '%s' (type '%s')", prv.Name, prv.Type)
+			continue
+		}
+		providersMap[provider.ProviderName(prv.Name)] = p
+	}
+
 	// Copy user providers
 	providers, err := pc.db.GetUserProviders(ctx, userID)
 	if err != nil {
@@ -935,15 +1032,38 @@ func (pc *providerController) TestAgent(
 	return result, nil
 }
 
+// TestProvider runs the full test suite against config. When prvID names a
+// saved provider of the user, the measured model capabilities are stored for
+// it and its stored config is tuned from them; a config which is not saved
+// yet is only tested.
 func (pc *providerController) TestProvider(
 	ctx context.Context,
+	userID int64,
+	prvID *int64,
 	prvtype provider.ProviderType,
 	config *pconfig.ProviderConfig,
 ) (tester.ProviderTestResults, error) {
 	ctx, span := obs.Observer.NewSpan(ctx, obs.SpanKindInternal, "providers.TestProvider")
 	defer span.End()
 
-	var results tester.ProviderTestResults
+	var (
+		results tester.ProviderTestResults
+		saved   *database.Provider
+	)
+
+	if prvID != nil {
+		prv, err := pc.db.GetUserProvider(ctx, database.GetUserProviderParams{
+			ID:     *prvID,
+			UserID: userID,
+		})
+		if err != nil {
+			return results, fmt.Errorf("failed to get provider: %w", err)
+		}
+		if provider.ProviderType(prv.Type) != prvtype {
+			return results, fmt.Errorf("provider %d is of type '%s', not '%s'", prv.ID, prv.Type, prvtype)
+		}
+		saved = &prv
+	}
 
 	// Patch config with defaults
 	patchedConfig, err := pc.patchProviderConfig(prvtype, config)
@@ -968,6 +1088,19 @@ func (pc *providerController) TestProvider(
 	if err != nil {
 		return results, fmt.Errorf("failed to test provider: %w", err)
 	}
+	if saved == nil {
+		return results, nil
+	}
+
+	// The results themselves are what the caller asked for; failing to record
+	// the derived capabilities only costs the tuning, so it is logged rather
+	// than returned.
+	capabilities := tester.BuildModelCapabilities(testProvider, results)
+	if err := pc.storeCapabilities(ctx, *saved, capabilities); err != nil {
+		logrus.WithError(err).Warnf("failed to store capabilities of provider %d", saved.ID)
+	} else if err := pc.tuneProvider(ctx, *saved, capabilities); err != nil {
+		logrus.WithError(err).Warnf("failed to tune provider %d", saved.ID)
+	}
 
 	return results, nil
 }
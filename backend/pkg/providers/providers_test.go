@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"pentagi/pkg/config"
+	"pentagi/pkg/csum"
 	"pentagi/pkg/database"
 	"pentagi/pkg/providers/anthropic"
 	"pentagi/pkg/providers/bedrock"
@@ -21,6 +23,7 @@ import (
 	"pentagi/pkg/providers/pconfig"
 	"pentagi/pkg/providers/provider"
 	"pentagi/pkg/providers/qwen"
+	"pentagi/pkg/providers/tester"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -37,13 +40,40 @@ func (s stubTypedProvider) Type() provider.ProviderType { return s.ptype }
 
 type stubProvidersQuerier struct {
 	database.Querier
-	rows []database.Provider
+	rows   []database.Provider
+	shared []database.Provider
 }
 
 func (s stubProvidersQuerier) GetUserProviders(context.Context, int64) ([]database.Provider, error) {
 	return s.rows, nil
 }
 
+func (s stubProvidersQuerier) GetUserProjectProviders(context.Context, int64) ([]database.Provider, error) {
+	return s.shared, nil
+}
+
+func (s stubProvidersQuerier) GetUserProviderByName(
+	_ context.Context, arg database.GetUserProviderByNameParams,
+) (database.Provider, error) {
+	for _, row := range s.rows {
+		if row.Name == arg.Name {
+			return row, nil
+		}
+	}
+	return database.Provider{}, sql.ErrNoRows
+}
+
+func (s stubProvidersQuerier) GetUserProjectProviderByName(
+	_ context.Context, arg database.GetUserProjectProviderByNameParams,
+) (database.Provider, error) {
+	for _, row := range s.shared {
+		if row.Name == arg.Name {
+			return row, nil
+		}
+	}
+	return database.Provider{}, sql.ErrNoRows
+}
+
 func TestGetProviders_SkipsUserProviderOfDisabledType(t *testing.T) {
 	pc := &providerController{
 		cfg: &config.Config{},
@@ -83,6 +113,37 @@ func TestGetProviders_StaleUserRowSpansValidSibling(t *testing.T) {
 	assert.NotContains(t, got, provider.ProviderName("stale-minimax"), "the unbuildable sibling is skipped")
 }
 
+func TestGetProviders_IncludesProjectProviders(t *testing.T) {
+	pc := &providerController{
+		cfg: &config.Config{},
+		db: stubProvidersQuerier{
+			rows: []database.Provider{{Name: "ollama-user", Type: "ollama"}},
+			shared: []database.Provider{
+				{Name: "ollama-team", Type: "ollama", UserID: 2},
+				{Name: "ollama-user", Type: "ollama", UserID: 2},
+				{Name: "stale-minimax", Type: "minimax", UserID: 2},
+			},
+		},
+		Providers: provider.Providers{
+			"ollama-default": stubTypedProvider{ptype: provider.ProviderOllama},
+		},
+	}
+
+	got, err := pc.GetProviders(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Contains(t, got, provider.ProviderName("ollama-team"), "a provider shared through a project is usable")
+	assert.Contains(t, got, provider.ProviderName("ollama-user"))
+	assert.NotContains(t, got, provider.ProviderName("stale-minimax"), "an unbuildable shared provider is skipped")
+
+	prv, err := pc.GetProvider(context.Background(), "ollama-team", 1)
+	require.NoError(t, err, "the shared provider is found by name before the built-in ones")
+	assert.Equal(t, provider.ProviderOllama, prv.Type())
+
+	_, err = pc.GetProvider(context.Background(), "unknown", 1)
+	assert.Error(t, err)
+}
+
 func TestBuildDefaultConfigs_DisabledProviderBadPathIsNotFatal(t *testing.T) {
 	cfg := &config.Config{
 		BedrockConfig: filepath.Join(t.TempDir(), "missing.yml"),
@@ -310,3 +371,221 @@ func TestCallWithSetupRetries_ContextAlreadyCanceled_StopsWithoutRetrying(t *tes
 	assert.ErrorIs(t, err, context.Canceled)
 	assert.Equal(t, 1, prv.callCount, "a context.Canceled error from Call must stop retrying immediately")
 }
+
+// Every bundled model declares its context window, otherwise summarization
+// falls back to the global byte limits for it.
+func TestCatalogModelsDeclareContextWindows(t *testing.T) {
+	t.Parallel()
+
+	catalogs := map[string]func() (pconfig.ModelsConfig, error){
+		"anthropic": anthropic.DefaultModels,
+		"bedrock":   bedrock.DefaultModels,
+		"gemini":    gemini.DefaultModels,
+		"deepseek":  deepseek.DefaultModels,
+		"glm":       glm.DefaultModels,
+		"kimi":      kimi.DefaultModels,
+		"minimax":   minimax.DefaultModels,
+		"openai":    openai.DefaultModels,
+		"qwen":      qwen.DefaultModels,
+	}
+
+	tokenizers := map[pconfig.ModelTokenizer]bool{
+		pconfig.ModelTokenizerDefault: true,
+		pconfig.ModelTokenizerO200K:   true,
+		pconfig.ModelTokenizerCL100K:  true,
+		pconfig.ModelTokenizerClaude:  true,
+		pconfig.ModelTokenizerGemini:  true,
+		pconfig.ModelTokenizerLlama:   true,
+	}
+
+	for name, load := range catalogs {
+		models, err := load()
+		require.NoError(t, err, name)
+
+		for _, model := range models {
+			assert.Positive(t, model.ContextWindow, "%s/%s has no context window", name, model.Name)
+			assert.True(t, tokenizers[model.Tokenizer], "%s/%s has unknown tokenizer %q", name, model.Name, model.Tokenizer)
+		}
+	}
+}
+
+// windowRecorder records the window contextSummarizer binds it to
+type windowRecorder struct {
+	csum.Summarizer
+	window *csum.ContextWindow
+}
+
+func (w windowRecorder) WithContextWindow(window csum.ContextWindow) csum.Summarizer {
+	*w.window = window
+	return w
+}
+
+// stubModelsProvider serves one model for every agent type from the given
+// models config
+type stubModelsProvider struct {
+	provider.Provider
+	name   provider.ProviderName
+	model  string
+	models pconfig.ModelsConfig
+}
+
+func (s stubModelsProvider) Name() provider.ProviderName              { return s.name }
+func (s stubModelsProvider) Model(pconfig.ProviderOptionsType) string { return s.model }
+func (s stubModelsProvider) GetModels() pconfig.ModelsConfig          { return s.models }
+
+func TestContextSummarizer(t *testing.T) {
+	t.Parallel()
+
+	prvConfig, err := anthropic.DefaultProviderConfig()
+	require.NoError(t, err)
+	models, err := anthropic.DefaultModels()
+	require.NoError(t, err)
+
+	model := prvConfig.AgentConfigForType(pconfig.OptionsTypePrimaryAgent).Model
+	modelConfig := models.FindModel(model)
+	require.NotNil(t, modelConfig, "default primary agent model %s is not in the catalog", model)
+
+	t.Run("bundled model", func(t *testing.T) {
+		var window csum.ContextWindow
+		fp := &flowProvider{Provider: stubModelsProvider{name: "anthropic", model: model, models: models}}
+
+		fp.contextSummarizer(pconfig.OptionsTypePrimaryAgent, windowRecorder{window: &window})
+		assert.Equal(t, modelConfig.ContextWindow, window.Tokens)
+		assert.Equal(t, pconfig.ModelTokenizerClaude.BytesPerToken(), window.BytesPerToken)
+	})
+
+	t.Run("measured window", func(t *testing.T) {
+		var window csum.ContextWindow
+		fp := &flowProvider{
+			Provider: stubModelsProvider{name: "lab-llama", model: "llama-local"},
+			contextWindows: map[measuredModel]int{
+				{prvname: "lab-llama", model: "llama-local"}:   32768,
+				{prvname: "other-llama", model: "llama-local"}: 65536,
+			},
+		}
+
+		fp.contextSummarizer(pconfig.OptionsTypePrimaryAgent, windowRecorder{window: &window})
+		assert.Equal(t, 32768, window.Tokens)
+	})
+
+	t.Run("unknown window", func(t *testing.T) {
+		var window csum.ContextWindow
+		fp := &flowProvider{Provider: stubModelsProvider{name: "lab-llama", model: "llama-local"}}
+
+		fp.contextSummarizer(pconfig.OptionsTypePrimaryAgent, windowRecorder{window: &window})
+		assert.Zero(t, window.Tokens, "a model without a known window keeps the byte limits")
+	})
+}
+
+// capabilitiesQuerier keeps the rows the capability helpers read and write
+type capabilitiesQuerier struct {
+	database.Querier
+	providers []database.Provider
+	rows      []database.ProviderCapability
+	upserts   []database.UpsertProviderCapabilityParams
+	updates   []database.UpdateUserProviderParams
+}
+
+func (q *capabilitiesQuerier) GetUserProviders(_ context.Context, userID int64) ([]database.Provider, error) {
+	var providers []database.Provider
+	for _, prv := range q.providers {
+		if prv.UserID == userID {
+			providers = append(providers, prv)
+		}
+	}
+	return providers, nil
+}
+
+func (q *capabilitiesQuerier) GetUserProviderCapabilities(_ context.Context, userID int64) ([]database.ProviderCapability, error) {
+	var rows []database.ProviderCapability
+	for _, row := range q.rows {
+		if row.UserID == userID {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (q *capabilitiesQuerier) UpsertProviderCapability(
+	_ context.Context, arg database.UpsertProviderCapabilityParams,
+) (database.ProviderCapability, error) {
+	q.upserts = append(q.upserts, arg)
+	return database.ProviderCapability{ProviderID: arg.ProviderID, Model: arg.Model}, nil
+}
+
+func (q *capabilitiesQuerier) UpdateUserProvider(
+	_ context.Context, arg database.UpdateUserProviderParams,
+) (database.Provider, error) {
+	q.updates = append(q.updates, arg)
+	return database.Provider{ID: arg.ID, UserID: arg.UserID, Name: arg.Name, Config: arg.Config}, nil
+}
+
+func TestStoreAndTuneProvider(t *testing.T) {
+	t.Parallel()
+
+	db := &capabilitiesQuerier{}
+	pc := &providerController{db: db}
+	prv := database.Provider{
+		ID:     7,
+		UserID: 1,
+		Type:   database.ProviderType(provider.ProviderCustom),
+		Name:   "lab",
+		Config: []byte(`{"simple":{"model":"flaky"},"simple_json":{"model":"flaky","json":true},"coder":{"model":"solid"}}`),
+	}
+
+	toolCalling, jsonMode := 0.5, false
+	solidToolCalling, solidJSON := 1.0, true
+	capabilities := []tester.ModelCapabilities{
+		{Model: "flaky", ToolCalling: &toolCalling, JSONMode: &jsonMode},
+		{Model: "solid", ToolCalling: &solidToolCalling, JSONMode: &solidJSON},
+	}
+
+	require.NoError(t, pc.storeCapabilities(context.Background(), prv, capabilities))
+	require.Len(t, db.upserts, 2)
+	for _, upsert := range db.upserts {
+		assert.Equal(t, prv.ID, upsert.ProviderID, "capabilities must be stored for the tested provider")
+		assert.Equal(t, prv.UserID, upsert.UserID)
+		assert.Equal(t, prv.Type, upsert.Type)
+	}
+
+	require.NoError(t, pc.tuneProvider(context.Background(), prv, capabilities))
+	require.Len(t, db.updates, 1)
+	assert.Equal(t, prv.ID, db.updates[0].ID)
+	assert.Equal(t, prv.Name, db.updates[0].Name)
+
+	tuned, err := pconfig.LoadConfigData(db.updates[0].Config, nil)
+	require.NoError(t, err)
+	assert.True(t, tuned.Simple.ToolCallFixer)
+	assert.False(t, tuned.SimpleJSON.JSON)
+	assert.True(t, tuned.SimpleJSON.ToolCallFixer)
+	assert.False(t, tuned.Coder.ToolCallFixer, "a reliable model must be left alone")
+
+	// a run that finds nothing to change leaves the stored config as it is
+	db.updates = nil
+	require.NoError(t, pc.tuneProvider(context.Background(), prv, capabilities[1:]))
+	assert.Empty(t, db.updates)
+}
+
+func TestMeasuredContextWindows(t *testing.T) {
+	t.Parallel()
+
+	db := &capabilitiesQuerier{
+		providers: []database.Provider{
+			{ID: 1, UserID: 1, Name: "lab-a"},
+			{ID: 2, UserID: 1, Name: "lab-b"},
+		},
+		rows: []database.ProviderCapability{
+			{UserID: 1, ProviderID: 1, Model: "llama", MaxContext: 32768},
+			{UserID: 1, ProviderID: 2, Model: "llama", MaxContext: 131072},
+			{UserID: 1, ProviderID: 2, Model: "tiny", MaxContext: 2048},
+			{UserID: 1, ProviderID: 3, Model: "llama", MaxContext: 65536},
+		},
+	}
+	pc := &providerController{db: db}
+
+	windows := pc.measuredContextWindows(context.Background(), 1)
+	assert.Equal(t, map[measuredModel]int{
+		{prvname: "lab-a", model: "llama"}: 32768,
+		{prvname: "lab-b", model: "llama"}: 131072,
+	}, windows, "each provider keeps its own window, short test prompts and unknown providers are skipped")
+}
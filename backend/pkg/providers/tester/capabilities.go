@@ -0,0 +1,238 @@
+package tester
+
+import (
+	"slices"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester/testdata"
+)
+
+// ToolCallingReliabilityThreshold is the minimum share of passed tool tests a
+// model must reach before TuneProviderConfig leaves its agents on plain tool
+// calling; below it every agent using the model gets ToolCallFixer enabled.
+const ToolCallingReliabilityThreshold = 0.8
+
+// ModelCapabilities summarizes what a single model proved able to do across
+// every agent that used it during one TestProvider run. Pointer fields are nil
+// when no test exercised the capability, so "not measured" stays distinct
+// from "measured and failed".
+type ModelCapabilities struct {
+	Model      string                        `json:"model"`
+	AgentTypes []pconfig.ProviderOptionsType `json:"agent_types"`
+	// ToolCalling is the share of tool tests (including the multi-turn
+	// file_edit exchange) that passed, in [0, 1].
+	ToolCalling *float64 `json:"tool_calling,omitempty"`
+	// JSONMode reports whether every JSON test passed. Results where the
+	// model proactively rejected structured output as unsupported are left out:
+	// they say nothing about plain JSON mode.
+	JSONMode *bool `json:"json_mode,omitempty"`
+	// Reasoning reports whether any response carried reasoning content.
+	Reasoning bool `json:"reasoning"`
+	// Streaming reports whether every streaming test passed.
+	Streaming *bool `json:"streaming,omitempty"`
+	// MaxContext is the largest prompt, in input tokens, the model served
+	// successfully during the run. It is a lower bound on the real window, not
+	// the window itself: the built-in tests never try to fill it.
+	MaxContext  int64 `json:"max_context"`
+	TestsTotal  int   `json:"tests_total"`
+	TestsPassed int   `json:"tests_passed"`
+}
+
+// ForAgentType returns the results collected for one agent type.
+func (r ProviderTestResults) ForAgentType(opt pconfig.ProviderOptionsType) AgentTestResults {
+	switch opt {
+	case pconfig.OptionsTypeSimple:
+		return r.Simple
+	case pconfig.OptionsTypeSimpleJSON:
+		return r.SimpleJSON
+	case pconfig.OptionsTypePrimaryAgent:
+		return r.PrimaryAgent
+	case pconfig.OptionsTypeAssistant:
+		return r.Assistant
+	case pconfig.OptionsTypeGenerator:
+		return r.Generator
+	case pconfig.OptionsTypeRefiner:
+		return r.Refiner
+	case pconfig.OptionsTypeAdviser:
+		return r.Adviser
+	case pconfig.OptionsTypeReflector:
+		return r.Reflector
+	case pconfig.OptionsTypeSearcher:
+		return r.Searcher
+	case pconfig.OptionsTypeEnricher:
+		return r.Enricher
+	case pconfig.OptionsTypeCoder:
+		return r.Coder
+	case pconfig.OptionsTypeInstaller:
+		return r.Installer
+	case pconfig.OptionsTypePentester:
+		return r.Pentester
+	default:
+		return nil
+	}
+}
+
+// capabilityCounters accumulates per-model pass/total tallies while walking
+// the results of every agent type that shares the model.
+type capabilityCounters struct {
+	caps                      ModelCapabilities
+	toolTotal, toolPassed     int
+	jsonTotal, jsonPassed     int
+	streamTotal, streamPassed int
+}
+
+// BuildModelCapabilities folds a TestProvider run into one ModelCapabilities
+// entry per distinct model, resolving each agent type to the model prv
+// actually assigns it. Agent types without results or without a model are
+// skipped. Entries are returned in the order their model first appears in
+// pconfig.AllAgentTypes.
+func BuildModelCapabilities(prv provider.Provider, results ProviderTestResults) []ModelCapabilities {
+	counters := make(map[string]*capabilityCounters)
+	order := make([]string, 0)
+
+	for _, opt := range pconfig.AllAgentTypes {
+		agentResults := results.ForAgentType(opt)
+		modelName := prv.Model(opt)
+		if len(agentResults) == 0 || modelName == "" {
+			continue
+		}
+
+		c, ok := counters[modelName]
+		if !ok {
+			c = &capabilityCounters{caps: ModelCapabilities{Model: modelName}}
+			counters[modelName] = c
+			order = append(order, modelName)
+		}
+		if !slices.Contains(c.caps.AgentTypes, opt) {
+			c.caps.AgentTypes = append(c.caps.AgentTypes, opt)
+		}
+
+		for _, result := range agentResults {
+			c.add(result)
+		}
+	}
+
+	capabilities := make([]ModelCapabilities, 0, len(order))
+	for _, modelName := range order {
+		capabilities = append(capabilities, counters[modelName].build())
+	}
+
+	return capabilities
+}
+
+func (c *capabilityCounters) add(result testdata.TestResult) {
+	if result.Unsupported {
+		return
+	}
+
+	c.caps.TestsTotal++
+	if result.Success {
+		c.caps.TestsPassed++
+		c.caps.MaxContext = max(c.caps.MaxContext, result.InputTokens)
+	}
+	if result.Reasoning {
+		c.caps.Reasoning = true
+	}
+
+	switch result.Type {
+	case testdata.TestTypeTool, testdata.TestTypeFileEdit:
+		c.toolTotal++
+		if result.Success {
+			c.toolPassed++
+		}
+	case testdata.TestTypeJSON:
+		c.jsonTotal++
+		if result.Success {
+			c.jsonPassed++
+		}
+	}
+
+	if result.Streaming {
+		c.streamTotal++
+		if result.Success {
+			c.streamPassed++
+		}
+	}
+}
+
+func (c *capabilityCounters) build() ModelCapabilities {
+	caps := c.caps
+
+	if c.toolTotal > 0 {
+		ratio := float64(c.toolPassed) / float64(c.toolTotal)
+		caps.ToolCalling = &ratio
+	}
+	if c.jsonTotal > 0 {
+		passed := c.jsonPassed == c.jsonTotal
+		caps.JSONMode = &passed
+	}
+	if c.streamTotal > 0 {
+		passed := c.streamPassed == c.streamTotal
+		caps.Streaming = &passed
+	}
+
+	return caps
+}
+
+// TuneProviderConfig adjusts the agents in cfg in place using previously
+// measured capabilities, keyed by model name:
+//   - JSON mode is switched off for agents whose model failed the JSON tests;
+//   - ToolCallFixer is switched on for agents whose model passed fewer than
+//     ToolCallingReliabilityThreshold of the tool tests.
+//
+// Only explicitly configured agents are touched (fallback slots such as an
+// unset SimpleJSON stay unset), and nothing is ever switched the other way:
+// a model that passed does not override an operator's deliberate choice. It
+// returns the agent types that were changed.
+func TuneProviderConfig(cfg *pconfig.ProviderConfig, capabilities map[string]ModelCapabilities) []pconfig.ProviderOptionsType {
+	if cfg == nil || len(capabilities) == 0 {
+		return nil
+	}
+
+	agents := map[pconfig.ProviderOptionsType]*pconfig.AgentConfig{
+		pconfig.OptionsTypeSimple:       cfg.Simple,
+		pconfig.OptionsTypeSimpleJSON:   cfg.SimpleJSON,
+		pconfig.OptionsTypePrimaryAgent: cfg.PrimaryAgent,
+		pconfig.OptionsTypeAssistant:    cfg.Assistant,
+		pconfig.OptionsTypeGenerator:    cfg.Generator,
+		pconfig.OptionsTypeRefiner:      cfg.Refiner,
+		pconfig.OptionsTypeAdviser:      cfg.Adviser,
+		pconfig.OptionsTypeReflector:    cfg.Reflector,
+		pconfig.OptionsTypeSearcher:     cfg.Searcher,
+		pconfig.OptionsTypeEnricher:     cfg.Enricher,
+		pconfig.OptionsTypeCoder:        cfg.Coder,
+		pconfig.OptionsTypeInstaller:    cfg.Installer,
+		pconfig.OptionsTypePentester:    cfg.Pentester,
+	}
+
+	var tuned []pconfig.ProviderOptionsType
+	for _, opt := range pconfig.AllAgentTypes {
+		agentConfig := agents[opt]
+		if agentConfig == nil || agentConfig.Model == "" {
+			continue
+		}
+
+		caps, ok := capabilities[agentConfig.Model]
+		if !ok {
+			continue
+		}
+
+		changed := false
+		if agentConfig.JSON && caps.JSONMode != nil && !*caps.JSONMode {
+			agentConfig.SetJSON(false)
+			changed = true
+		}
+		if !agentConfig.ToolCallFixer && caps.ToolCalling != nil &&
+			*caps.ToolCalling < ToolCallingReliabilityThreshold {
+			agentConfig.SetToolCallFixer(true)
+			changed = true
+		}
+
+		if changed {
+			tuned = append(tuned, opt)
+		}
+	}
+
+	return tuned
+}
@@ -0,0 +1,130 @@
+package tester
+
+import (
+	"encoding/json"
+	"testing"
+
+	"pentagi/pkg/providers/pconfig"
+	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/providers/tester/mock"
+	"pentagi/pkg/providers/tester/testdata"
+)
+
+func TestBuildModelCapabilities(t *testing.T) {
+	mockProvider := mock.NewProvider(provider.ProviderCustom, provider.DefaultProviderNameCustom, "test-model")
+
+	results := ProviderTestResults{
+		Simple: AgentTestResults{
+			{Type: testdata.TestTypeCompletion, Success: true, Streaming: true, InputTokens: 120},
+			{Type: testdata.TestTypeTool, Success: true, InputTokens: 900},
+			{Type: testdata.TestTypeTool, Success: false, InputTokens: 5000},
+		},
+		SimpleJSON: AgentTestResults{
+			{Type: testdata.TestTypeJSON, Success: false},
+			{Type: testdata.TestTypeJSON, Success: false, Unsupported: true},
+		},
+		Coder: AgentTestResults{
+			{Type: testdata.TestTypeFileEdit, Success: true, Reasoning: true},
+			{Type: testdata.TestTypeCompletion, Success: false, Streaming: true},
+		},
+	}
+
+	capabilities := BuildModelCapabilities(mockProvider, results)
+	if len(capabilities) != 1 {
+		t.Fatalf("expected capabilities for a single model, got %d", len(capabilities))
+	}
+
+	caps := capabilities[0]
+	if caps.Model != "test-model" {
+		t.Errorf("unexpected model %q", caps.Model)
+	}
+	if len(caps.AgentTypes) != 3 {
+		t.Errorf("expected 3 agent types, got %v", caps.AgentTypes)
+	}
+	if caps.ToolCalling == nil || *caps.ToolCalling != 2.0/3.0 {
+		t.Errorf("expected tool calling ratio 2/3, got %v", caps.ToolCalling)
+	}
+	if caps.JSONMode == nil || *caps.JSONMode {
+		t.Errorf("expected failed json mode, got %v", caps.JSONMode)
+	}
+	if caps.Streaming == nil || *caps.Streaming {
+		t.Errorf("expected failed streaming, got %v", caps.Streaming)
+	}
+	if !caps.Reasoning {
+		t.Error("expected reasoning to be detected")
+	}
+	if caps.MaxContext != 900 {
+		t.Errorf("expected max context from passed tests only, got %d", caps.MaxContext)
+	}
+	if caps.TestsTotal != 6 || caps.TestsPassed != 3 {
+		t.Errorf("expected 3/6 passed tests (unsupported excluded), got %d/%d", caps.TestsPassed, caps.TestsTotal)
+	}
+}
+
+func TestBuildModelCapabilitiesUnmeasured(t *testing.T) {
+	mockProvider := mock.NewProvider(provider.ProviderCustom, provider.DefaultProviderNameCustom, "test-model")
+
+	capabilities := BuildModelCapabilities(mockProvider, ProviderTestResults{
+		Simple: AgentTestResults{{Type: testdata.TestTypeCompletion, Success: true}},
+	})
+	if len(capabilities) != 1 {
+		t.Fatalf("expected capabilities for a single model, got %d", len(capabilities))
+	}
+	if caps := capabilities[0]; caps.ToolCalling != nil || caps.JSONMode != nil || caps.Streaming != nil {
+		t.Errorf("expected unmeasured capabilities to stay nil, got %+v", caps)
+	}
+}
+
+func TestTuneProviderConfig(t *testing.T) {
+	var cfg pconfig.ProviderConfig
+	err := json.Unmarshal([]byte(`{
+		"simple": {"model": "weak-model"},
+		"simple_json": {"model": "weak-model", "json": true},
+		"pentester": {"model": "strong-model", "json": true}
+	}`), &cfg)
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	failed, passed := false, true
+	unreliable, reliable := 0.5, 1.0
+	capabilities := map[string]ModelCapabilities{
+		"weak-model":   {Model: "weak-model", JSONMode: &failed, ToolCalling: &unreliable},
+		"strong-model": {Model: "strong-model", JSONMode: &passed, ToolCalling: &reliable},
+	}
+
+	tuned := TuneProviderConfig(&cfg, capabilities)
+	if len(tuned) != 2 {
+		t.Fatalf("expected simple and simple_json to be tuned, got %v", tuned)
+	}
+
+	if cfg.SimpleJSON.JSON || !cfg.SimpleJSON.ToolCallFixer {
+		t.Errorf("expected simple_json to drop json mode and enable fixer, got %+v", cfg.SimpleJSON)
+	}
+	if !cfg.Simple.ToolCallFixer {
+		t.Error("expected simple to enable tool call fixer")
+	}
+	if !cfg.Pentester.JSON || cfg.Pentester.ToolCallFixer {
+		t.Errorf("expected pentester to stay untouched, got %+v", cfg.Pentester)
+	}
+
+	// the raw map drives both BuildOptions and marshaling, so it must follow
+	data, err := json.Marshal(cfg.SimpleJSON)
+	if err != nil {
+		t.Fatalf("failed to marshal agent config: %v", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("failed to parse marshaled agent config: %v", err)
+	}
+	if _, ok := raw["json"]; ok {
+		t.Errorf("expected json key to be removed, got %s", data)
+	}
+	if raw["tool_call_fixer"] != true {
+		t.Errorf("expected tool_call_fixer key to be set, got %s", data)
+	}
+
+	if tuned := TuneProviderConfig(&cfg, capabilities); len(tuned) != 0 {
+		t.Errorf("expected tuning to be idempotent, got %v", tuned)
+	}
+}
@@ -320,9 +320,21 @@ func executeTest(ctx context.Context, req testRequest) (testdata.TestResult, err
 	// let test case validate and produce result
 	result := req.testCase.Execute(response, latency)
 	result.Capability = req.testCase.Capability()
+	result.InputTokens = responseInputTokens(response)
 	return result, nil
 }
 
+// responseInputTokens extracts the prompt token count the provider reported
+// for response; plain string responses from Call carry no usage info.
+func responseInputTokens(response any) int64 {
+	resp, ok := response.(*llms.ContentResponse)
+	if !ok || resp == nil || len(resp.Choices) == 0 {
+		return 0
+	}
+
+	return pconfig.NewCallUsage(resp.Choices[0].GenerationInfo).Input
+}
+
 // isUnsupportedCapabilityError reports whether err is one of the SDK's typed
 // "this model/provider does not support the requested capability" sentinels,
 // proactively returned by the langchaingo adapters before any network call —
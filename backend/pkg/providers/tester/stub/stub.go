@@ -0,0 +1,336 @@
+// Package stub provides an OpenAI-compatible chat completions server which
+// answers test registry cases the way local inference servers (llama.cpp,
+// vLLM, LM Studio) typically do: reasoning inlined into the content with a
+// missing opening <think> tag, tool calls without IDs and, when a GBNF grammar
+// is sent instead of tools, tool calls rendered as plain JSON text.
+package stub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"pentagi/pkg/providers/tester/testdata"
+)
+
+// DefaultModel is the only model the stub server lists and answers for.
+const DefaultModel = "local-stub"
+
+// streamChunkSize is small on purpose so reasoning tags are split across
+// chunks, like tokens produced by a real local server.
+const streamChunkSize = 5
+
+const stubReasoning = "The user asks a direct question, I should answer it exactly as requested."
+
+type Server struct {
+	server    *httptest.Server
+	answers   map[string]testdata.TestDefinition
+	requests  atomic.Int64
+	createdAt int64
+}
+
+// New starts a stub server answering the given test definitions, matched by
+// the last user message of a request.
+func New(definitions []testdata.TestDefinition) *Server {
+	s := &Server{
+		answers:   make(map[string]testdata.TestDefinition, len(definitions)),
+		createdAt: time.Now().Unix(),
+	}
+
+	for _, def := range definitions {
+		if key := lastUserMessage(def); key != "" {
+			s.answers[key] = def
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/models", s.handleModels)
+	mux.HandleFunc("/v1/chat/completions", s.handleChatCompletions)
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// URL returns the base URL to use as LLM_SERVER_URL.
+func (s *Server) URL() string {
+	return s.server.URL + "/v1"
+}
+
+// Requests returns the number of chat completion requests served so far.
+func (s *Server) Requests() int64 {
+	return s.requests.Load()
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Tools    []any         `json:"tools"`
+	Stream   bool          `json:"stream"`
+	Grammar  string        `json:"grammar"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type toolCall struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type answer struct {
+	content   string
+	toolCalls []toolCall
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{
+		"object": "list",
+		"data": []map[string]any{{
+			"id":       DefaultModel,
+			"object":   "model",
+			"created":  s.createdAt,
+			"owned_by": "stub",
+		}},
+	})
+}
+
+func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.requests.Add(1)
+	ans := s.answer(req)
+	id := fmt.Sprintf("chatcmpl-stub-%d", s.requests.Load())
+
+	if req.Stream {
+		s.writeStream(w, id, req.Model, ans)
+		return
+	}
+
+	message := map[string]any{"role": "assistant", "content": ans.content}
+	finishReason := "stop"
+	if len(ans.toolCalls) > 0 {
+		message["tool_calls"] = ans.toolCalls
+		finishReason = "tool_calls"
+	}
+
+	writeJSON(w, map[string]any{
+		"id":      id,
+		"object":  "chat.completion",
+		"created": s.createdAt,
+		"model":   req.Model,
+		"choices": []map[string]any{{
+			"index":         0,
+			"message":       message,
+			"finish_reason": finishReason,
+		}},
+		"usage": usage(req, ans),
+	})
+}
+
+func (s *Server) answer(req chatRequest) answer {
+	var last string
+	for idx := len(req.Messages) - 1; idx >= 0; idx-- {
+		if req.Messages[idx].Role == "user" {
+			last = strings.TrimSpace(messageText(req.Messages[idx].Content))
+			break
+		}
+	}
+
+	def, ok := s.answers[last]
+	if !ok {
+		return answer{content: stubReasoning + "\n</think>\n\nOK"}
+	}
+
+	switch def.Type {
+	case testdata.TestTypeTool:
+		calls := expectedToolCalls(def.Expected)
+		if len(req.Tools) > 0 {
+			return answer{content: "", toolCalls: calls}
+		}
+		if req.Grammar != "" && len(calls) > 0 {
+			data, _ := json.Marshal(map[string]json.RawMessage{
+				"name":      json.RawMessage(mustMarshal(calls[0].Function.Name)),
+				"arguments": json.RawMessage(calls[0].Function.Arguments),
+			})
+			return answer{content: "<think>" + stubReasoning + "</think>\n" + string(data)}
+		}
+		return answer{content: "I can't call tools without tool definitions."}
+
+	case testdata.TestTypeJSON:
+		data, _ := json.Marshal(def.Expected)
+		return answer{content: "<think>" + stubReasoning + "</think>\n" + string(data)}
+
+	default:
+		return answer{content: stubReasoning + "\n</think>\n\n" + fmt.Sprint(def.Expected)}
+	}
+}
+
+func (s *Server) writeStream(w http.ResponseWriter, id, model string, ans answer) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	send := func(delta map[string]any, finishReason any) {
+		data, _ := json.Marshal(map[string]any{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": s.createdAt,
+			"model":   model,
+			"choices": []map[string]any{{
+				"index":         0,
+				"delta":         delta,
+				"finish_reason": finishReason,
+			}},
+		})
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	send(map[string]any{"role": "assistant", "content": ""}, nil)
+
+	runes := []rune(ans.content)
+	for start := 0; start < len(runes); start += streamChunkSize {
+		end := min(start+streamChunkSize, len(runes))
+		send(map[string]any{"content": string(runes[start:end])}, nil)
+	}
+
+	// the first delta of each call names the function but carries no ID,
+	// arguments follow in small continuation deltas
+	for idx, call := range ans.toolCalls {
+		send(map[string]any{"tool_calls": []map[string]any{{
+			"index": idx,
+			"type":  "function",
+			"function": map[string]any{
+				"name":      call.Function.Name,
+				"arguments": "",
+			},
+		}}}, nil)
+
+		args := call.Function.Arguments
+		for start := 0; start < len(args); start += streamChunkSize {
+			end := min(start+streamChunkSize, len(args))
+			send(map[string]any{"tool_calls": []map[string]any{{
+				"index":    idx,
+				"function": map[string]any{"arguments": args[start:end]},
+			}}}, nil)
+		}
+	}
+
+	finishReason := "stop"
+	if len(ans.toolCalls) > 0 {
+		finishReason = "tool_calls"
+	}
+	send(map[string]any{}, finishReason)
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+func lastUserMessage(def testdata.TestDefinition) string {
+	for idx := len(def.Messages) - 1; idx >= 0; idx-- {
+		switch strings.ToLower(def.Messages[idx].Role) {
+		case "user", "human":
+			return strings.TrimSpace(def.Messages[idx].Content)
+		}
+	}
+
+	return strings.TrimSpace(def.Prompt)
+}
+
+func expectedToolCalls(expected any) []toolCall {
+	items, _ := expected.([]any)
+
+	calls := make([]toolCall, 0, len(items))
+	for idx, item := range items {
+		exp, _ := item.(map[string]any)
+		name, _ := exp["function_name"].(string)
+		if name == "" {
+			continue
+		}
+
+		args, _ := json.Marshal(exp["arguments"])
+		call := toolCall{Index: idx, Type: "function"}
+		call.Function.Name = name
+		call.Function.Arguments = string(args)
+		calls = append(calls, call)
+	}
+
+	return calls
+}
+
+func messageText(content any) string {
+	switch v := content.(type) {
+	case string:
+		return v
+	case []any:
+		var parts []string
+		for _, item := range v {
+			part, _ := item.(map[string]any)
+			if text, ok := part["text"].(string); ok {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	default:
+		return ""
+	}
+}
+
+func usage(req chatRequest, ans answer) map[string]int {
+	var prompt int
+	for _, msg := range req.Messages {
+		prompt += len(messageText(msg.Content)) / 4
+	}
+
+	completion := len(ans.content) / 4
+	for _, call := range ans.toolCalls {
+		completion += len(call.Function.Arguments) / 4
+	}
+
+	return map[string]int{
+		"prompt_tokens":     prompt,
+		"completion_tokens": completion,
+		"total_tokens":      prompt + completion,
+	}
+}
+
+func mustMarshal(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,93 @@
+package stub
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/providers/tester/testdata"
+)
+
+func TestServer(t *testing.T) {
+	registry, err := testdata.LoadLocalRegistry()
+	if err != nil {
+		t.Fatalf("failed to load local tests: %v", err)
+	}
+
+	srv := New(registry.GetAllTests())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL() + "/models")
+	if err != nil {
+		t.Fatalf("models request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), DefaultModel) {
+		t.Errorf("models must list %s, got %s", DefaultModel, body)
+	}
+
+	post := func(req map[string]any) map[string]any {
+		t.Helper()
+
+		data, _ := json.Marshal(req)
+		resp, err := http.Post(srv.URL()+"/chat/completions", "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("chat request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var payload struct {
+			Choices []struct {
+				Message map[string]any `json:"message"`
+			} `json:"choices"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return payload.Choices[0].Message
+	}
+
+	message := post(map[string]any{
+		"model": DefaultModel,
+		"messages": []map[string]any{
+			{"role": "user", "content": "What is 7 * 6? Write only the number without any other text."},
+		},
+	})
+	if content := message["content"].(string); !strings.HasSuffix(content, "</think>\n\n42") || strings.Contains(content, "<think>") {
+		t.Errorf("completion must inline reasoning without the opening tag, got %q", content)
+	}
+
+	message = post(map[string]any{
+		"model": DefaultModel,
+		"messages": []map[string]any{
+			{"role": "user", "content": []map[string]any{
+				{"type": "text", "text": "Call the lookup_host function with hostname 'scanme.nmap.org'"},
+			}},
+		},
+		"tools": []any{map[string]any{"type": "function"}},
+	})
+	calls, _ := message["tool_calls"].([]any)
+	if len(calls) != 1 {
+		t.Fatalf("expected one tool call, got %+v", message)
+	}
+	call := calls[0].(map[string]any)
+	if call["id"] != "" || call["function"].(map[string]any)["arguments"] != `{"hostname":"scanme.nmap.org"}` {
+		t.Errorf("unexpected tool call: %+v", call)
+	}
+
+	message = post(map[string]any{
+		"model":    DefaultModel,
+		"messages": []map[string]any{{"role": "user", "content": "unknown question"}},
+	})
+	if content := message["content"].(string); !strings.HasSuffix(content, "OK") {
+		t.Errorf("unknown requests must get a generic answer, got %q", content)
+	}
+
+	if srv.Requests() != 3 {
+		t.Errorf("expected 3 served requests, got %d", srv.Requests())
+	}
+}
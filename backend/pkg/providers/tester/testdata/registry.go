@@ -7,7 +7,7 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-//go:embed tests.yml
+//go:embed tests.yml local_tests.yml
 var testsData embed.FS
 
 // TestRegistry manages test definitions and creates test suites
@@ -24,6 +24,16 @@ func LoadBuiltinRegistry() (*TestRegistry, error) {
 	return LoadRegistryFromYAML(data)
 }
 
+// LoadLocalRegistry loads the curated test definitions for the local provider
+// profile from embedded local_tests.yml
+func LoadLocalRegistry() (*TestRegistry, error) {
+	data, err := testsData.ReadFile("local_tests.yml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local tests: %w", err)
+	}
+	return LoadRegistryFromYAML(data)
+}
+
 // LoadRegistryFromYAML creates registry from YAML data
 func LoadRegistryFromYAML(data []byte) (*TestRegistry, error) {
 	var definitions []TestDefinition
@@ -22,4 +22,7 @@ type TestResult struct {
 	Streaming   bool          `json:"streaming"`
 	Reasoning   bool          `json:"reasoning"`
 	Latency     time.Duration `json:"latency"`
+	// InputTokens is the prompt size the provider reported for the final
+	// call of the test, zero when the response carried no usage info.
+	InputTokens int64 `json:"input_tokens,omitempty"`
 }
@@ -0,0 +1,189 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// chainLockKey serializes appends to the hash chain across replicas
+	chainLockKey = 0x61756469745f6c67 // "audit_lg"
+
+	verifyBatchSize = 1000
+
+	defaultMaxDiffBytes = 64 << 10 // 64KB
+)
+
+type actorContextKey struct{}
+
+// Actor identifies who performed the audited action and from where
+type Actor struct {
+	UserID    *uint64
+	UserType  string
+	TokenID   *string
+	IP        string
+	UserAgent string
+}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+func GetActor(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorContextKey{}).(Actor)
+	return actor, ok
+}
+
+// Recorder appends events to the hash-chained audit log, a nil recorder
+// records nothing so callers don't have to check whether auditing is enabled
+type Recorder struct {
+	db           *gorm.DB
+	mx           sync.Mutex
+	maxDiffBytes int
+	forwarder    *SyslogForwarder
+}
+
+func NewRecorder(db *gorm.DB, maxDiffBytes int, forwarder *SyslogForwarder) *Recorder {
+	if maxDiffBytes <= 0 {
+		maxDiffBytes = defaultMaxDiffBytes
+	}
+
+	return &Recorder{
+		db:           db,
+		maxDiffBytes: maxDiffBytes,
+		forwarder:    forwarder,
+	}
+}
+
+// Record links the event to the last one of the chain and stores it,
+// the stored event is forwarded to syslog when it's configured
+func (r *Recorder) Record(event models.AuditEvent) (models.AuditEvent, error) {
+	if r == nil {
+		return event, nil
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	tx := r.db.Begin()
+	if err := tx.Error; err != nil {
+		return event, fmt.Errorf("failed to begin audit transaction: %w", err)
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	if r.db.Dialect().GetName() == "postgres" {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", chainLockKey).Error; err != nil {
+			return event, fmt.Errorf("failed to lock audit chain: %w", err)
+		}
+	}
+
+	var last models.AuditEvent
+	err := tx.Select("hash").Order("id DESC").Limit(1).Take(&last).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return event, fmt.Errorf("failed to get last audit event: %w", err)
+	}
+
+	event.ID = 0
+	event.PrevHash = last.Hash
+	event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	event.Hash = EventHash(event)
+
+	if err := tx.Create(&event).Error; err != nil {
+		return event, fmt.Errorf("failed to store audit event: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return event, fmt.Errorf("failed to commit audit event: %w", err)
+	}
+
+	r.forwarder.Send(event)
+
+	return event, nil
+}
+
+// EventHash returns the SHA-256 of the event fields and the hash of the previous event
+func EventHash(event models.AuditEvent) string {
+	payload, _ := json.Marshal(struct {
+		PrevHash   string  `json:"prev_hash"`
+		CreatedAt  string  `json:"created_at"`
+		UserID     *uint64 `json:"user_id"`
+		UserType   string  `json:"user_type"`
+		TokenID    *string `json:"token_id"`
+		Action     string  `json:"action"`
+		Method     string  `json:"method"`
+		TargetType string  `json:"target_type"`
+		TargetID   string  `json:"target_id"`
+		Diff       string  `json:"diff"`
+		Status     string  `json:"status"`
+		Error      string  `json:"error"`
+		IP         string  `json:"ip"`
+		UserAgent  string  `json:"user_agent"`
+	}{
+		PrevHash:   event.PrevHash,
+		CreatedAt:  event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UserID:     event.UserID,
+		UserType:   event.UserType,
+		TokenID:    event.TokenID,
+		Action:     event.Action,
+		Method:     event.Method,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Diff:       string(event.Diff),
+		Status:     event.Status.String(),
+		Error:      event.Error,
+		IP:         event.IP,
+		UserAgent:  event.UserAgent,
+	})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify walks the whole chain and reports the first event which was changed or
+// which follows a removed one; removing the newest events is only detectable by
+// comparing the returned last hash with a copy kept outside, e.g. in the SIEM
+func Verify(db *gorm.DB) (models.AuditVerification, error) {
+	var result models.AuditVerification
+
+	for {
+		var events []models.AuditEvent
+		err := db.Where("id > ?", result.LastID).Order("id ASC").Limit(verifyBatchSize).Find(&events).Error
+		if err != nil {
+			return result, fmt.Errorf("failed to get audit events: %w", err)
+		}
+
+		for _, event := range events {
+			switch {
+			case event.PrevHash != result.LastHash:
+				result.Reason = "previous hash mismatch, an event before it was removed or changed"
+			case EventHash(event) != event.Hash:
+				result.Reason = "event hash mismatch, the event was changed"
+			}
+			if result.Reason != "" {
+				result.BrokenID = &event.ID
+				return result, nil
+			}
+
+			result.Checked++
+			result.LastID = event.ID
+			result.LastHash = event.Hash
+		}
+
+		if len(events) < verifyBatchSize {
+			break
+		}
+	}
+
+	result.Valid = true
+	return result, nil
+}
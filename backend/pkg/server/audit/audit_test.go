@@ -0,0 +1,331 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	db.Exec(`
+		CREATE TABLE audit_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			user_type TEXT NOT NULL DEFAULT '',
+			token_id TEXT,
+			action TEXT NOT NULL,
+			method TEXT NOT NULL DEFAULT '',
+			target_type TEXT NOT NULL DEFAULT '',
+			target_id TEXT NOT NULL DEFAULT '',
+			diff TEXT,
+			status TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			ip TEXT NOT NULL DEFAULT '',
+			user_agent TEXT NOT NULL DEFAULT '',
+			prev_hash TEXT NOT NULL DEFAULT '',
+			hash TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		)
+	`)
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+func recordEvents(t *testing.T, recorder *Recorder, count int) []models.AuditEvent {
+	t.Helper()
+
+	uid := uint64(1)
+	events := make([]models.AuditEvent, 0, count)
+	for i := 0; i < count; i++ {
+		event, err := recorder.Record(models.AuditEvent{
+			UserID:     &uid,
+			UserType:   "local",
+			Action:     "flows.create",
+			Method:     "POST /api/v1/flows/",
+			TargetType: "flow",
+			Diff:       models.AuditDiff(`{"input":"scan"}`),
+			Status:     models.AuditStatusSuccess,
+			IP:         "10.0.0.1",
+		})
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestRecorder_RecordLinksChain(t *testing.T) {
+	db := setupTestDB(t)
+	recorder := NewRecorder(db, 0, nil)
+
+	events := recordEvents(t, recorder, 3)
+
+	assert.Empty(t, events[0].PrevHash)
+	assert.Equal(t, events[0].Hash, events[1].PrevHash)
+	assert.Equal(t, events[1].Hash, events[2].PrevHash)
+
+	var stored []models.AuditEvent
+	require.NoError(t, db.Order("id ASC").Find(&stored).Error)
+	require.Len(t, stored, 3)
+	for i, event := range stored {
+		assert.Equal(t, events[i].Hash, EventHash(event), "stored event %d must hash the same", event.ID)
+		assert.NoError(t, event.Valid())
+	}
+
+	result, err := Verify(db)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, uint64(3), result.Checked)
+	assert.Equal(t, events[2].ID, result.LastID)
+	assert.Equal(t, events[2].Hash, result.LastHash)
+}
+
+func TestRecorder_NilRecorder(t *testing.T) {
+	var recorder *Recorder
+
+	event, err := recorder.Record(models.AuditEvent{Action: "flows.create"})
+	require.NoError(t, err)
+	assert.Empty(t, event.Hash)
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(db *gorm.DB, events []models.AuditEvent)
+		brokenAt int
+		reason   string
+	}{
+		{
+			name: "changed field",
+			tamper: func(db *gorm.DB, events []models.AuditEvent) {
+				db.Exec("UPDATE audit_events SET target_id = '42' WHERE id = ?", events[1].ID)
+			},
+			brokenAt: 1,
+			reason:   "event hash mismatch",
+		},
+		{
+			name: "changed diff",
+			tamper: func(db *gorm.DB, events []models.AuditEvent) {
+				db.Exec(`UPDATE audit_events SET diff = '{"input":"other"}' WHERE id = ?`, events[0].ID)
+			},
+			brokenAt: 0,
+			reason:   "event hash mismatch",
+		},
+		{
+			name: "removed event",
+			tamper: func(db *gorm.DB, events []models.AuditEvent) {
+				db.Exec("DELETE FROM audit_events WHERE id = ?", events[1].ID)
+			},
+			brokenAt: 2,
+			reason:   "previous hash mismatch",
+		},
+		{
+			name: "rehashed event",
+			tamper: func(db *gorm.DB, events []models.AuditEvent) {
+				event := events[1]
+				event.Action = "flows.delete"
+				db.Exec("UPDATE audit_events SET action = ?, hash = ? WHERE id = ?", event.Action, EventHash(event), event.ID)
+			},
+			brokenAt: 2,
+			reason:   "previous hash mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			events := recordEvents(t, NewRecorder(db, 0, nil), 3)
+
+			tt.tamper(db, events)
+
+			result, err := Verify(db)
+			require.NoError(t, err)
+			assert.False(t, result.Valid)
+			require.NotNil(t, result.BrokenID)
+			assert.Equal(t, events[tt.brokenAt].ID, *result.BrokenID)
+			assert.Contains(t, result.Reason, tt.reason)
+		})
+	}
+}
+
+func TestRouteAction(t *testing.T) {
+	tests := []struct {
+		method     string
+		route      string
+		params     gin.Params
+		action     string
+		targetType string
+		targetID   string
+	}{
+		{http.MethodPost, "/flows/", nil, "flows.create", "flow", ""},
+		{http.MethodPut, "/flows/:flowID", gin.Params{{Key: "flowID", Value: "7"}}, "flows.update", "flow", "7"},
+		{http.MethodDelete, "/flows/:flowID", gin.Params{{Key: "flowID", Value: "7"}}, "flows.delete", "flow", "7"},
+		{
+			http.MethodPost, "/flows/:flowID/files/", gin.Params{{Key: "flowID", Value: "7"}},
+			"flows.files.create", "flow", "7",
+		},
+		{
+			http.MethodPost, "/prompts/:promptType/default", gin.Params{{Key: "promptType", Value: "primary_agent"}},
+			"prompts.default", "prompt", "primary_agent",
+		},
+		{http.MethodPost, "/auth/login", nil, "auth.login", "auth", ""},
+		{http.MethodPut, "/user/password", nil, "user.password", "user", ""},
+		{http.MethodPost, "/tokens", nil, "tokens.create", "token", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.route, func(t *testing.T) {
+			action, targetType, targetID := routeAction(tt.method, tt.route, tt.params)
+			assert.Equal(t, tt.action, action)
+			assert.Equal(t, tt.targetType, targetType)
+			assert.Equal(t, tt.targetID, targetID)
+		})
+	}
+}
+
+func TestMakeBodyDiff(t *testing.T) {
+	t.Run("json body is redacted", func(t *testing.T) {
+		body := []byte(`{"mail":"admin@pentagi.com","password":"secret","nested":{"api_key":"k","token_id":"abcdefghij"}}`)
+		diff := MakeBodyDiff("application/json; charset=utf-8", body, int64(len(body)), 1024)
+
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal([]byte(diff), &decoded))
+		assert.Equal(t, "admin@pentagi.com", decoded["mail"])
+		assert.Equal(t, redactedValue, decoded["password"])
+		nested := decoded["nested"].(map[string]any)
+		assert.Equal(t, redactedValue, nested["api_key"])
+		assert.Equal(t, "abcdefghij", nested["token_id"])
+	})
+
+	t.Run("form body is redacted", func(t *testing.T) {
+		body := []byte("SAMLResponse=abc&RelayState=xyz")
+		diff := MakeBodyDiff("application/x-www-form-urlencoded", body, int64(len(body)), 1024)
+		assert.JSONEq(t, `{"SAMLResponse":"[REDACTED]","RelayState":"xyz"}`, string(diff))
+	})
+
+	t.Run("binary body is summarized", func(t *testing.T) {
+		diff := MakeBodyDiff("application/octet-stream", []byte{1, 2, 3}, 3, 1024)
+		assert.JSONEq(t, `{"content_type":"application/octet-stream","size":3}`, string(diff))
+	})
+
+	t.Run("large body is truncated", func(t *testing.T) {
+		body := []byte(`{"input":"` + strings.Repeat("a", 100) + `"}`)
+		diff := MakeBodyDiff("application/json", body, int64(len(body)), 32)
+		assert.JSONEq(t, `{"content_type":"application/json","size":112,"truncated":true}`, string(diff))
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		assert.Empty(t, MakeBodyDiff("application/json", nil, 0, 1024))
+	})
+}
+
+func TestFormatSyslog(t *testing.T) {
+	event := models.AuditEvent{
+		ID:        5,
+		Action:    "auth.login",
+		Status:    models.AuditStatusFailure,
+		Hash:      strings.Repeat("a", 64),
+		CreatedAt: time.Date(2026, 10, 31, 12, 0, 0, 123456000, time.UTC),
+	}
+
+	message, err := FormatSyslog(event)
+	require.NoError(t, err)
+
+	// authpriv (10) * 8 + warning (4)
+	prefix := "<84>1 2026-10-31T12:00:00.123456Z " + syslogHostname + " pentagi "
+	assert.True(t, strings.HasPrefix(string(message), prefix), string(message))
+
+	payload := message[bytes.IndexByte(message, '{'):]
+	var decoded models.AuditEvent
+	require.NoError(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, event.ID, decoded.ID)
+	assert.Equal(t, event.Action, decoded.Action)
+
+	event.Status = models.AuditStatusSuccess
+	message, err = FormatSyslog(event)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(message), "<85>1 "))
+}
+
+func TestMiddleware_RecordsRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+	recorder := NewRecorder(db, 0, nil)
+
+	router := gin.New()
+	api := router.Group("/api/v1")
+	api.Use(recorder.Middleware("/api/v1", "/api/v1/graphql"))
+	api.Use(func(c *gin.Context) {
+		c.Set("uid", uint64(3))
+		c.Set("tid", "local")
+		c.Set("tkid", "abcdefghij")
+	})
+	api.POST("/flows/", func(c *gin.Context) {
+		var body map[string]any
+		require.NoError(t, c.ShouldBindJSON(&body))
+		assert.Equal(t, "scan", body["input"], "handler must see the original body")
+		c.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"id": 12}})
+	})
+	api.DELETE("/flows/:flowID", func(c *gin.Context) {
+		c.JSON(http.StatusForbidden, gin.H{"status": "error", "code": "Flows.NotPermitted"})
+	})
+	api.GET("/flows/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "success"})
+	})
+	api.POST("/graphql", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": nil})
+	})
+
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/api/v1/flows/", strings.NewReader(`{"input":"scan","api_key":"k"}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/flows/9", nil),
+		httptest.NewRequest(http.MethodGet, "/api/v1/flows/", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/graphql", strings.NewReader(`{}`)),
+	}
+	for _, req := range requests {
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var events []models.AuditEvent
+	require.NoError(t, db.Order("id ASC").Find(&events).Error)
+	require.Len(t, events, 2, "only state changing REST requests are recorded")
+
+	created := events[0]
+	assert.Equal(t, "flows.create", created.Action)
+	assert.Equal(t, "flow", created.TargetType)
+	assert.Equal(t, "12", created.TargetID)
+	assert.Equal(t, models.AuditStatusSuccess, created.Status)
+	require.NotNil(t, created.UserID)
+	assert.Equal(t, uint64(3), *created.UserID)
+	require.NotNil(t, created.TokenID)
+	assert.Equal(t, "abcdefghij", *created.TokenID)
+	assert.JSONEq(t, `{"input":"scan","api_key":"[REDACTED]"}`, string(created.Diff))
+
+	deleted := events[1]
+	assert.Equal(t, "flows.delete", deleted.Action)
+	assert.Equal(t, "9", deleted.TargetID)
+	assert.Equal(t, models.AuditStatusFailure, deleted.Status)
+	assert.Equal(t, "Flows.NotPermitted", deleted.Error)
+	assert.Equal(t, created.Hash, deleted.PrevHash)
+}
@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"pentagi/pkg/server/models"
+)
+
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeys are parts of the field names which values never get into the audit log
+var sensitiveKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"api_key",
+	"apikey",
+	"passphrase",
+	"private_key",
+	"privatekey",
+	"credential",
+	"samlresponse",
+}
+
+// allowedKeys look sensitive but identify the target of the action
+var allowedKeys = []string{
+	"token_id",
+	"tokenid",
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, allowed := range allowedKeys {
+		if key == allowed {
+			return false
+		}
+	}
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact replaces values of sensitive fields in the decoded JSON document
+func Redact(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			if isSensitiveKey(key) {
+				value[key] = redactedValue
+			} else {
+				value[key] = Redact(item)
+			}
+		}
+		return value
+	case []any:
+		for i, item := range value {
+			value[i] = Redact(item)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// MakeDiff encodes the redacted change into a document which fits the size limit
+func MakeDiff(value any, maxBytes int) models.AuditDiff {
+	if value == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return diffSummary(map[string]any{"error": "failed to encode change: " + err.Error()})
+	}
+
+	// typed values (GraphQL inputs) are decoded again to apply the redaction by field names
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return diffSummary(map[string]any{"error": "failed to decode change: " + err.Error()})
+	}
+
+	return limitDiff(Redact(decoded), len(data), maxBytes)
+}
+
+// MakeBodyDiff makes the change document from the raw request body
+func MakeBodyDiff(contentType string, body []byte, size int64, maxBytes int) models.AuditDiff {
+	if size < 0 {
+		size = int64(len(body))
+	}
+	if size == 0 {
+		return ""
+	}
+
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	summary := map[string]any{"content_type": mediaType, "size": size}
+	if int64(len(body)) < size || len(body) > maxBytes {
+		summary["truncated"] = true
+		return diffSummary(summary)
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var decoded any
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			summary["error"] = "invalid JSON body"
+			return diffSummary(summary)
+		}
+		return limitDiff(Redact(decoded), len(body), maxBytes)
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			summary["error"] = "invalid form body"
+			return diffSummary(summary)
+		}
+		form := make(map[string]any, len(values))
+		for key, items := range values {
+			if len(items) == 1 {
+				form[key] = items[0]
+			} else {
+				form[key] = items
+			}
+		}
+		return limitDiff(Redact(form), len(body), maxBytes)
+	default:
+		// uploads and other binary bodies are described by their size only
+		return diffSummary(summary)
+	}
+}
+
+func limitDiff(value any, size, maxBytes int) models.AuditDiff {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return diffSummary(map[string]any{"error": "failed to encode change: " + err.Error()})
+	}
+	if len(data) > maxBytes {
+		return diffSummary(map[string]any{"size": size, "truncated": true})
+	}
+	return models.AuditDiff(data)
+}
+
+func diffSummary(summary map[string]any) models.AuditDiff {
+	data, _ := json.Marshal(summary)
+	return models.AuditDiff(data)
+}
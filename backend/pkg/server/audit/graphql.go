@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/sirupsen/logrus"
+)
+
+// GraphqlExtension records the mutations of the GraphQL API
+type GraphqlExtension struct {
+	recorder *Recorder
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = GraphqlExtension{}
+
+func (r *Recorder) GraphqlExtension() GraphqlExtension {
+	return GraphqlExtension{recorder: r}
+}
+
+func (GraphqlExtension) ExtensionName() string {
+	return "AuditLog"
+}
+
+func (GraphqlExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e GraphqlExtension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if e.recorder == nil || fc == nil || fc.Object != "Mutation" || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	res, err := next(ctx)
+
+	event := mutationEvent(ctx, fc, e.recorder.maxDiffBytes)
+	if err != nil {
+		event.Status = models.AuditStatusFailure
+		event.Error = truncate(err.Error(), 1000)
+	}
+
+	if _, recErr := e.recorder.Record(event); recErr != nil {
+		logrus.WithContext(ctx).WithError(recErr).
+			WithField("component", "pentagi-audit").
+			Errorf("failed to record audit event '%s'", event.Action)
+	}
+
+	return res, err
+}
+
+func mutationEvent(ctx context.Context, fc *graphql.FieldContext, maxDiffBytes int) models.AuditEvent {
+	actor, _ := GetActor(ctx)
+	name := fc.Field.Name
+
+	event := models.AuditEvent{
+		UserID:    actor.UserID,
+		UserType:  actor.UserType,
+		TokenID:   actor.TokenID,
+		Action:    "graphql." + name,
+		Method:    "mutation " + name,
+		Status:    models.AuditStatusSuccess,
+		IP:        actor.IP,
+		UserAgent: actor.UserAgent,
+	}
+
+	if oc := graphql.GetOperationContext(ctx); oc != nil && oc.OperationName != "" {
+		event.Method = "mutation " + oc.OperationName + "." + name
+	}
+
+	// the first ID argument in the schema order is the target, e.g. flowId of stopFlow
+	for _, arg := range fc.Field.Arguments {
+		if arg.Name != "id" && !strings.HasSuffix(arg.Name, "Id") && !strings.HasSuffix(arg.Name, "ID") {
+			continue
+		}
+		if value, ok := fc.Args[arg.Name]; ok && value != nil {
+			event.TargetType = strings.TrimSuffix(strings.TrimSuffix(arg.Name, "Id"), "ID")
+			event.TargetID = fmt.Sprint(derefArg(value))
+			break
+		}
+	}
+	if event.TargetType == "" || event.TargetType == "id" {
+		event.TargetType = mutationTarget(name)
+	}
+
+	args := make(map[string]any, len(fc.Args))
+	for key, value := range fc.Args {
+		args[key] = uploadsSummary(value)
+	}
+	event.Diff = MakeDiff(args, maxDiffBytes)
+
+	return event
+}
+
+// mutationTarget guesses the target type by the mutation name, e.g. "project" of "createProject"
+func mutationTarget(name string) string {
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			target := name[i:]
+			return strings.ToLower(target[:1]) + target[1:]
+		}
+	}
+	return name
+}
+
+func derefArg(value any) any {
+	switch value := value.(type) {
+	case *int64:
+		return *value
+	case *string:
+		return *value
+	default:
+		return value
+	}
+}
+
+// uploadsSummary replaces the uploaded files by their names and sizes
+func uploadsSummary(value any) any {
+	summary := func(upload graphql.Upload) map[string]any {
+		return map[string]any{"filename": upload.Filename, "size": upload.Size}
+	}
+
+	switch value := value.(type) {
+	case graphql.Upload:
+		return summary(value)
+	case *graphql.Upload:
+		if value == nil {
+			return nil
+		}
+		return summary(*value)
+	case []*graphql.Upload:
+		result := make([]any, 0, len(value))
+		for _, upload := range value {
+			if upload != nil {
+				result = append(result, summary(*upload))
+			}
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	return strings.ToValidUTF8(s[:limit], "")
+}
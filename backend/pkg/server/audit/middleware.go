@@ -0,0 +1,215 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+const maxCapturedResponseBytes = 64 << 10 // 64KB
+
+var methodVerbs = map[string]string{
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "update",
+	http.MethodDelete: "delete",
+}
+
+// ActorFromGin collects the actor from the auth middleware fields, it falls back to
+// the session to catch the user which has just logged in by the request
+func ActorFromGin(c *gin.Context) Actor {
+	actor := Actor{
+		UserType:  c.GetString("tid"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+
+	if uid := c.GetUint64("uid"); uid != 0 {
+		actor.UserID = &uid
+	}
+	if tokenID := c.GetString("tkid"); tokenID != "" {
+		actor.TokenID = &tokenID
+	}
+
+	if _, ok := c.Get(sessions.DefaultKey); ok && actor.UserID == nil {
+		session := sessions.Default(c)
+		if uid, ok := session.Get("uid").(uint64); ok && uid != 0 {
+			actor.UserID = &uid
+		}
+		if tid, ok := session.Get("tid").(string); ok && actor.UserType == "" {
+			actor.UserType = tid
+		}
+	}
+
+	return actor
+}
+
+type responseCapture struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseCapture) capture(data []byte) {
+	if left := maxCapturedResponseBytes - w.body.Len(); left > 0 {
+		w.body.Write(data[:min(left, len(data))])
+	}
+}
+
+func (w *responseCapture) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseCapture) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Middleware records every state changing request of the REST API routes
+// under basePath except the skipped ones, which are audited on their own
+func (r *Recorder) Middleware(basePath string, skipPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if r == nil || route == "" || slices.Contains(skipPaths, route) {
+			c.Next()
+			return
+		}
+		if _, ok := methodVerbs[c.Request.Method]; !ok {
+			c.Next()
+			return
+		}
+
+		diff := r.readBodyDiff(c)
+		writer := &responseCapture{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+		event := restEvent(c, basePath, route, writer.body.Bytes())
+		event.Diff = diff
+
+		if _, err := r.Record(event); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("failed to record audit event '%s'", event.Action)
+		}
+	}
+}
+
+// readBodyDiff reads the request body up to the diff limit and restores it for the handler
+func (r *Recorder) readBodyDiff(c *gin.Context) models.AuditDiff {
+	req := c.Request
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	if strings.HasPrefix(strings.ToLower(contentType), "multipart/") || req.ContentLength > int64(r.maxDiffBytes) {
+		return MakeBodyDiff(contentType, nil, req.ContentLength, r.maxDiffBytes)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, int64(r.maxDiffBytes)+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	if err != nil {
+		return diffSummary(map[string]any{"error": "failed to read body: " + err.Error()})
+	}
+
+	return MakeBodyDiff(contentType, body, req.ContentLength, r.maxDiffBytes)
+}
+
+func restEvent(c *gin.Context, basePath, route string, response []byte) models.AuditEvent {
+	actor := ActorFromGin(c)
+	action, targetType, targetID := routeAction(c.Request.Method, strings.TrimPrefix(route, basePath), c.Params)
+
+	event := models.AuditEvent{
+		UserID:     actor.UserID,
+		UserType:   actor.UserType,
+		TokenID:    actor.TokenID,
+		Action:     action,
+		Method:     c.Request.Method + " " + c.Request.URL.Path,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Status:     models.AuditStatusSuccess,
+		IP:         actor.IP,
+		UserAgent:  actor.UserAgent,
+	}
+
+	var body struct {
+		Code string `json:"code"`
+		Data struct {
+			ID any `json:"id"`
+		} `json:"data"`
+	}
+	_ = json.Unmarshal(response, &body)
+
+	status := c.Writer.Status()
+	if status >= http.StatusBadRequest {
+		event.Status = models.AuditStatusFailure
+		event.Error = body.Code
+		if event.Error == "" {
+			event.Error = http.StatusText(status)
+		}
+	} else if event.TargetID == "" && body.Data.ID != nil {
+		// the created item is known only from the response
+		if id, err := json.Marshal(body.Data.ID); err == nil {
+			event.TargetID = strings.Trim(string(id), `"`)
+		}
+	}
+
+	return event
+}
+
+// routeAction names the action by the route, e.g. "POST /flows/" is "flows.create",
+// "PUT /flows/:flowID" is "flows.update" of the flow and "POST /auth/login" is "auth.login";
+// the last route parameter is the target of the action
+func routeAction(method, route string, params gin.Params) (string, string, string) {
+	var (
+		names      []string
+		targetType string
+		targetID   string
+		lastParam  bool
+	)
+
+	for _, segment := range strings.Split(strings.Trim(route, "/"), "/") {
+		switch {
+		case segment == "":
+			continue
+		case strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*"):
+			name := segment[1:]
+			targetID = strings.TrimPrefix(params.ByName(name), "/")
+			targetType = strings.TrimSuffix(strings.TrimSuffix(name, "ID"), "Id")
+			if targetType == name && len(names) != 0 {
+				targetType = strings.TrimSuffix(names[len(names)-1], "s")
+			}
+			lastParam = true
+		default:
+			names = append(names, segment)
+			lastParam = false
+		}
+	}
+
+	if len(names) == 0 {
+		names = append(names, "api")
+	}
+	if targetType == "" {
+		targetType = strings.TrimSuffix(names[0], "s")
+	}
+
+	// a literal after the collection is the action itself, e.g. "/prompts/:promptType/default"
+	if lastParam || strings.HasSuffix(route, "/") || len(names) == 1 {
+		names = append(names, methodVerbs[method])
+	}
+
+	return strings.Join(names, "."), targetType, targetID
+}
@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"pentagi/pkg/server/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	syslogAppName     = "pentagi"
+	syslogMsgID       = "audit"
+	syslogFacility    = 10 // authpriv
+	syslogSevNotice   = 5
+	syslogSevWarning  = 4
+	syslogQueueSize   = 1024
+	syslogDialTimeout = 5 * time.Second
+)
+
+var syslogHostname = func() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "-"
+	}
+	return hostname
+}()
+
+// FormatJSONL returns the event as a line of the JSON Lines export
+func FormatJSONL(event models.AuditEvent) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit event %d: %w", event.ID, err)
+	}
+	return append(data, '\n'), nil
+}
+
+// FormatSyslog returns the event as an RFC 5424 message with the JSON event as the payload,
+// failed actions are reported with the warning severity
+func FormatSyslog(event models.AuditEvent) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit event %d: %w", event.ID, err)
+	}
+
+	severity := syslogSevNotice
+	if event.Status == models.AuditStatusFailure {
+		severity = syslogSevWarning
+	}
+
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s - ",
+		syslogFacility*8+severity,
+		event.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		syslogHostname,
+		syslogAppName,
+		os.Getpid(),
+		syslogMsgID,
+	)
+
+	return append([]byte(header), data...), nil
+}
+
+// SyslogForwarder sends the recorded events to a remote syslog collector in the background,
+// events are dropped when the collector doesn't keep up
+type SyslogForwarder struct {
+	network string
+	address string
+	queue   chan models.AuditEvent
+	logger  *logrus.Entry
+}
+
+// NewSyslogForwarder accepts udp://, tcp:// and tls:// collector URLs
+func NewSyslogForwarder(rawURL string) (*SyslogForwarder, error) {
+	syslogURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog URL: %w", err)
+	}
+
+	switch syslogURL.Scheme {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("unsupported syslog scheme '%s', expected udp, tcp or tls", syslogURL.Scheme)
+	}
+
+	address := syslogURL.Host
+	if syslogURL.Port() == "" {
+		port := "514"
+		if syslogURL.Scheme == "tls" {
+			port = "6514"
+		}
+		address = net.JoinHostPort(syslogURL.Hostname(), port)
+	}
+
+	f := &SyslogForwarder{
+		network: syslogURL.Scheme,
+		address: address,
+		queue:   make(chan models.AuditEvent, syslogQueueSize),
+		logger:  logrus.WithField("component", "pentagi-audit"),
+	}
+	go f.run()
+
+	return f, nil
+}
+
+// Send queues the event without blocking the audited request
+func (f *SyslogForwarder) Send(event models.AuditEvent) {
+	if f == nil {
+		return
+	}
+
+	select {
+	case f.queue <- event:
+	default:
+		f.logger.WithField("id", event.ID).Warn("syslog queue is full, audit event is not forwarded")
+	}
+}
+
+// Close stops the forwarder after the queued events are sent
+func (f *SyslogForwarder) Close() {
+	if f != nil {
+		close(f.queue)
+	}
+}
+
+func (f *SyslogForwarder) run() {
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	for event := range f.queue {
+		message, err := FormatSyslog(event)
+		if err != nil {
+			f.logger.WithError(err).Error("failed to format audit event for syslog")
+			continue
+		}
+
+		// a broken stream connection is reopened once for the same message
+		for attempt := 0; attempt < 2; attempt++ {
+			if conn == nil {
+				if conn, err = f.dial(); err != nil {
+					conn = nil
+					break
+				}
+			}
+			if err = f.write(conn, message); err == nil {
+				break
+			}
+			conn.Close()
+			conn = nil
+		}
+		if err != nil {
+			f.logger.WithError(err).WithField("id", event.ID).Warn("failed to forward audit event to syslog")
+		}
+	}
+}
+
+func (f *SyslogForwarder) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: syslogDialTimeout}
+	if f.network == "tls" {
+		return tls.DialWithDialer(dialer, "tcp", f.address, &tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	return dialer.Dial(f.network, f.address)
+}
+
+func (f *SyslogForwarder) write(conn net.Conn, message []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(syslogDialTimeout)); err != nil {
+		return err
+	}
+
+	// stream transports use the octet counting framing of RFC 6587
+	if f.network != "udp" {
+		message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+
+	_, err := conn.Write(message)
+	return err
+}
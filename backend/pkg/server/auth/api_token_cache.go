@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
@@ -13,13 +14,25 @@ import (
 type tokenCacheEntry struct {
 	status     models.TokenStatus
 	privileges []string
+	scope      TokenScope
 	notFound   bool // negative caching
 	expiresAt  time.Time
 }
 
+// tokenUsage is the last use of a token recorded in the database
+type tokenUsage struct {
+	at time.Time
+	ip string
+}
+
+// lastUsedInterval limits how often the last use of a token is written while
+// it keeps being used from the same address
+const lastUsedInterval = time.Minute
+
 // TokenCache provides caching for token status lookups
 type TokenCache struct {
 	cache sync.Map
+	used  sync.Map
 	ttl   time.Duration
 	db    *gorm.DB
 }
@@ -39,13 +52,32 @@ func (tc *TokenCache) SetTTL(ttl time.Duration) {
 
 // GetStatus retrieves token status and privileges from cache or database
 func (tc *TokenCache) GetStatus(tokenID string) (models.TokenStatus, []string, error) {
+	entry, err := tc.load(tokenID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return entry.status, entry.privileges, nil
+}
+
+// GetScope retrieves the flows, templates and addresses the token is restricted to
+func (tc *TokenCache) GetScope(tokenID string) (TokenScope, error) {
+	entry, err := tc.load(tokenID)
+	if err != nil {
+		return TokenScope{}, err
+	}
+
+	return entry.scope, nil
+}
+
+func (tc *TokenCache) load(tokenID string) (tokenCacheEntry, error) {
 	if entry, ok := tc.cache.Load(tokenID); ok {
 		cached := entry.(tokenCacheEntry)
 		if time.Now().Before(cached.expiresAt) {
 			if cached.notFound {
-				return "", nil, gorm.ErrRecordNotFound
+				return tokenCacheEntry{}, gorm.ErrRecordNotFound
 			}
-			return cached.status, cached.privileges, nil
+			return cached, nil
 		}
 		tc.cache.Delete(tokenID)
 	}
@@ -57,14 +89,14 @@ func (tc *TokenCache) GetStatus(tokenID string) (models.TokenStatus, []string, e
 				notFound:  true,
 				expiresAt: time.Now().Add(tc.ttl),
 			})
-			return "", nil, gorm.ErrRecordNotFound
+			return tokenCacheEntry{}, gorm.ErrRecordNotFound
 		}
-		return "", nil, err
+		return tokenCacheEntry{}, err
 	}
 
 	var privileges []models.Privilege
 	if err := tc.db.Where("role_id = ?", token.RoleID).Find(&privileges).Error; err != nil {
-		return "", nil, err
+		return tokenCacheEntry{}, err
 	}
 
 	privNames := make([]string, len(privileges))
@@ -72,17 +104,46 @@ func (tc *TokenCache) GetStatus(tokenID string) (models.TokenStatus, []string, e
 		privNames[i] = priv.Name
 	}
 
+	// the token may be limited to a part of the role privileges
+	privNames = ScopePrivileges(privNames, token.Privileges)
+
 	// always add automation privilege for API tokens
 	privNames = append(privNames, PrivilegeAutomation)
 
-	tc.cache.Store(tokenID, tokenCacheEntry{
+	scope, err := NewTokenScope(token.FlowIDs, token.TemplateIDs, token.AllowedIPs)
+	if err != nil {
+		return tokenCacheEntry{}, fmt.Errorf("invalid token scope: %w", err)
+	}
+
+	entry := tokenCacheEntry{
 		status:     token.Status,
 		privileges: privNames,
+		scope:      scope,
 		notFound:   false,
 		expiresAt:  time.Now().Add(tc.ttl),
-	})
+	}
+	tc.cache.Store(tokenID, entry)
+
+	return entry, nil
+}
 
-	return token.Status, privNames, nil
+// TouchLastUsed records the time and the source address of the token use, the
+// database is written at most once a minute unless the address changes
+func (tc *TokenCache) TouchLastUsed(tokenID, ip string) error {
+	now := time.Now()
+	if entry, ok := tc.used.Load(tokenID); ok {
+		if used := entry.(tokenUsage); used.ip == ip && now.Sub(used.at) < lastUsedInterval {
+			return nil
+		}
+	}
+	tc.used.Store(tokenID, tokenUsage{at: now, ip: ip})
+
+	return tc.db.Model(&models.APIToken{}).
+		Where("token_id = ?", tokenID).
+		UpdateColumns(map[string]any{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		}).Error
 }
 
 // Invalidate removes a specific token from cache
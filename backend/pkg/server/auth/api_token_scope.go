@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenScope restricts an API token beyond the privileges it carries: to some
+// flows, to creating flows from some templates and to some source addresses.
+// An empty list doesn't restrict the token.
+type TokenScope struct {
+	FlowIDs     []int64
+	TemplateIDs []int64
+	AllowedIPs  []netip.Prefix
+}
+
+// NewTokenScope builds the scope from the stored token fields, addresses may be
+// plain IPs or CIDR ranges
+func NewTokenScope(flowIDs, templateIDs []int64, allowedIPs []string) (TokenScope, error) {
+	scope := TokenScope{
+		FlowIDs:     slices.Clone(flowIDs),
+		TemplateIDs: slices.Clone(templateIDs),
+	}
+
+	for _, ip := range allowedIPs {
+		prefix, err := ParseAllowedIP(ip)
+		if err != nil {
+			return TokenScope{}, err
+		}
+		scope.AllowedIPs = append(scope.AllowedIPs, prefix)
+	}
+
+	return scope, nil
+}
+
+// ParseAllowedIP parses an IP or a CIDR range of the token allowlist
+func ParseAllowedIP(value string) (netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR range '%s': %w", value, err)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address '%s': %w", value, err)
+	}
+	addr = addr.Unmap()
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// IsZero reports whether the scope doesn't restrict the token at all
+func (s TokenScope) IsZero() bool {
+	return len(s.FlowIDs) == 0 && len(s.TemplateIDs) == 0 && len(s.AllowedIPs) == 0
+}
+
+// HasFlows reports whether the token is restricted to some flows
+func (s TokenScope) HasFlows() bool {
+	return len(s.FlowIDs) != 0
+}
+
+// HasTemplates reports whether the token may only create flows from some templates
+func (s TokenScope) HasTemplates() bool {
+	return len(s.TemplateIDs) != 0
+}
+
+func (s TokenScope) AllowsFlow(flowID int64) bool {
+	return !s.HasFlows() || slices.Contains(s.FlowIDs, flowID)
+}
+
+func (s TokenScope) AllowsTemplate(templateID int64) bool {
+	return !s.HasTemplates() || slices.Contains(s.TemplateIDs, templateID)
+}
+
+func (s TokenScope) AllowsIP(ip string) bool {
+	if len(s.AllowedIPs) == 0 {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range s.AllowedIPs {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IPs returns the allowlist in the form it is stored
+func (s TokenScope) IPs() []string {
+	if len(s.AllowedIPs) == 0 {
+		return nil
+	}
+
+	ips := make([]string, 0, len(s.AllowedIPs))
+	for _, prefix := range s.AllowedIPs {
+		if prefix.IsSingleIP() {
+			ips = append(ips, prefix.Addr().String())
+		} else {
+			ips = append(ips, prefix.String())
+		}
+	}
+
+	return ips
+}
+
+// Narrow returns the scope of a token minted within this scope: the requested
+// restrictions must fit into the current ones, and the missing ones are inherited
+// so that a restricted token can't mint a token with wider reach
+func (s TokenScope) Narrow(req TokenScope) (TokenScope, error) {
+	result := req
+
+	if s.HasFlows() {
+		if !req.HasFlows() {
+			result.FlowIDs = slices.Clone(s.FlowIDs)
+		}
+		for _, flowID := range result.FlowIDs {
+			if !s.AllowsFlow(flowID) {
+				return TokenScope{}, fmt.Errorf("flow %d is out of the current token scope", flowID)
+			}
+		}
+	}
+
+	if s.HasTemplates() {
+		if !req.HasTemplates() {
+			result.TemplateIDs = slices.Clone(s.TemplateIDs)
+		}
+		for _, templateID := range result.TemplateIDs {
+			if !s.AllowsTemplate(templateID) {
+				return TokenScope{}, fmt.Errorf("template %d is out of the current token scope", templateID)
+			}
+		}
+	}
+
+	if len(s.AllowedIPs) != 0 {
+		if len(req.AllowedIPs) == 0 {
+			result.AllowedIPs = slices.Clone(s.AllowedIPs)
+		}
+		for _, prefix := range result.AllowedIPs {
+			if !slices.ContainsFunc(s.AllowedIPs, func(allowed netip.Prefix) bool {
+				return allowed.Bits() <= prefix.Bits() && allowed.Contains(prefix.Addr())
+			}) {
+				return TokenScope{}, fmt.Errorf("address range '%s' is out of the current token scope", prefix)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ScopePrivileges returns the privileges of a token: the privileges of its role
+// when the token lists none, otherwise the listed ones which the role still holds
+func ScopePrivileges(rolePrivs, tokenPrivs []string) []string {
+	if len(tokenPrivs) == 0 {
+		return slices.Clone(rolePrivs)
+	}
+
+	privs := make([]string, 0, len(tokenPrivs))
+	for _, priv := range rolePrivs {
+		if slices.Contains(tokenPrivs, priv) {
+			privs = append(privs, priv)
+		}
+	}
+
+	return privs
+}
+
+const tokenScopeKey = "tsc"
+
+// SetTokenScope stores the scope of the API token which authorized the request
+func SetTokenScope(c *gin.Context, scope TokenScope) {
+	c.Set(tokenScopeKey, scope)
+}
+
+// GetTokenScope returns the scope of the API token which authorized the request,
+// the zero scope for user sessions
+func GetTokenScope(c *gin.Context) TokenScope {
+	if scope, ok := c.Get(tokenScopeKey); ok {
+		if scope, ok := scope.(TokenScope); ok {
+			return scope
+		}
+	}
+	return TokenScope{}
+}
+
+// flowDataGroups are the route groups which list the data of all flows at once,
+// a token restricted to some flows reaches this data through the flow routes only
+var flowDataGroups = []string{
+	"agentlogs", "assistantlogs", "containers", "msglogs", "screenshots",
+	"searchlogs", "termlogs", "toolcalls", "usage", "vecstorelogs",
+}
+
+// checkFlowScope checks the flow of the matched route against the token scope
+func checkFlowScope(c *gin.Context, scope TokenScope) error {
+	if !scope.HasFlows() {
+		return nil
+	}
+
+	if param := c.Param("flowID"); param != "" {
+		flowID, err := strconv.ParseInt(param, 10, 64)
+		if err != nil || !scope.AllowsFlow(flowID) {
+			return fmt.Errorf("flow '%s' is out of the token scope", param)
+		}
+		return nil
+	}
+
+	path := c.FullPath()
+	for _, group := range flowDataGroups {
+		if strings.Contains(path, "/"+group+"/") {
+			return fmt.Errorf("listing %s of all flows is out of the token scope", group)
+		}
+	}
+
+	return nil
+}
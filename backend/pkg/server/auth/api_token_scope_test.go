@@ -0,0 +1,103 @@
+package auth_test
+
+import (
+	"testing"
+
+	"pentagi/pkg/server/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenScope(t *testing.T) {
+	scope, err := auth.NewTokenScope([]int64{1, 2}, nil, []string{"10.0.0.1", "192.168.1.7/24", "::ffff:127.0.0.1"})
+	require.NoError(t, err)
+	assert.False(t, scope.IsZero())
+	assert.True(t, scope.HasFlows())
+	assert.False(t, scope.HasTemplates())
+	assert.Equal(t, []string{"10.0.0.1", "192.168.1.0/24", "127.0.0.1"}, scope.IPs())
+
+	scope, err = auth.NewTokenScope(nil, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, scope.IsZero())
+	assert.Nil(t, scope.IPs())
+
+	for _, ip := range []string{"", "10.0.0", "10.0.0.0/33", "example.com"} {
+		_, err = auth.NewTokenScope(nil, nil, []string{ip})
+		assert.Error(t, err, ip)
+	}
+}
+
+func TestTokenScope_Allows(t *testing.T) {
+	scope, err := auth.NewTokenScope([]int64{3}, []int64{7}, []string{"10.0.0.0/8", "2001:db8::1"})
+	require.NoError(t, err)
+
+	assert.True(t, scope.AllowsFlow(3))
+	assert.False(t, scope.AllowsFlow(4))
+	assert.True(t, scope.AllowsTemplate(7))
+	assert.False(t, scope.AllowsTemplate(8))
+
+	assert.True(t, scope.AllowsIP("10.1.2.3"))
+	assert.True(t, scope.AllowsIP("::ffff:10.1.2.3"))
+	assert.True(t, scope.AllowsIP("2001:db8::1"))
+	assert.False(t, scope.AllowsIP("11.0.0.1"))
+	assert.False(t, scope.AllowsIP("2001:db8::2"))
+	assert.False(t, scope.AllowsIP("invalid"))
+
+	var empty auth.TokenScope
+	assert.True(t, empty.AllowsFlow(4))
+	assert.True(t, empty.AllowsTemplate(8))
+	assert.True(t, empty.AllowsIP("11.0.0.1"))
+}
+
+func TestTokenScope_Narrow(t *testing.T) {
+	current, err := auth.NewTokenScope([]int64{1, 2}, []int64{5}, []string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	// missing restrictions are inherited
+	narrowed, err := current.Narrow(auth.TokenScope{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, narrowed.FlowIDs)
+	assert.Equal(t, []int64{5}, narrowed.TemplateIDs)
+	assert.Equal(t, []string{"10.0.0.0/8"}, narrowed.IPs())
+
+	req, err := auth.NewTokenScope([]int64{2}, nil, []string{"10.1.0.0/16"})
+	require.NoError(t, err)
+	narrowed, err = current.Narrow(req)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, narrowed.FlowIDs)
+	assert.Equal(t, []int64{5}, narrowed.TemplateIDs)
+	assert.Equal(t, []string{"10.1.0.0/16"}, narrowed.IPs())
+
+	testCases := []struct {
+		name        string
+		flowIDs     []int64
+		templateIDs []int64
+		allowedIPs  []string
+	}{
+		{name: "foreign flow", flowIDs: []int64{3}},
+		{name: "foreign template", templateIDs: []int64{6}},
+		{name: "wider range", allowedIPs: []string{"0.0.0.0/0"}},
+		{name: "foreign address", allowedIPs: []string{"11.0.0.1"}},
+	}
+	for _, tc := range testCases {
+		scope, err := auth.NewTokenScope(tc.flowIDs, tc.templateIDs, tc.allowedIPs)
+		require.NoError(t, err)
+		_, err = current.Narrow(scope)
+		assert.Error(t, err, tc.name)
+	}
+
+	// an unrestricted scope keeps the request as is
+	narrowed, err = auth.TokenScope{}.Narrow(req)
+	require.NoError(t, err)
+	assert.Equal(t, req, narrowed)
+}
+
+func TestScopePrivileges(t *testing.T) {
+	role := []string{"flows.create", "flows.view", "settings.tokens.view"}
+
+	assert.Equal(t, role, auth.ScopePrivileges(role, nil))
+	assert.Equal(t, []string{"flows.create", "flows.view"},
+		auth.ScopePrivileges(role, []string{"flows.view", "flows.create", "flows.admin"}))
+	assert.Empty(t, auth.ScopePrivileges(role, []string{"users.view"}))
+}
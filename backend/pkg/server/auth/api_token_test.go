@@ -1,6 +1,8 @@
 package auth_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,6 +54,12 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			name TEXT,
 			ttl INTEGER NOT NULL,
 			status TEXT NOT NULL DEFAULT 'active',
+			privileges TEXT,
+			flow_ids TEXT,
+			template_ids TEXT,
+			allowed_ips TEXT,
+			last_used_at DATETIME,
+			last_used_ip TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			deleted_at DATETIME
@@ -477,3 +486,67 @@ func TestAPITokenAuthentication_LegacyProtoToken(t *testing.T) {
 
 	assert.True(t, server.CallAndGetStatus(t, "Bearer "+legacyToken))
 }
+
+func TestAPITokenAuthentication_Scope(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	tokenCache := auth.NewTokenCache(db)
+	userCache := auth.NewUserCache(db)
+	authMiddleware := auth.NewAuthMiddleware("/base/url", "test", tokenCache, userCache)
+
+	tokenID, err := auth.GenerateTokenID()
+	require.NoError(t, err)
+	apiToken := models.APIToken{
+		TokenID:    tokenID,
+		UserID:     1,
+		RoleID:     2,
+		TTL:        3600,
+		Status:     models.TokenStatusActive,
+		Privileges: pq.StringArray{"flows.view", "flows.admin"},
+		FlowIDs:    pq.Int64Array{5},
+		AllowedIPs: pq.StringArray{"10.0.0.0/8"},
+	}
+	require.NoError(t, db.Create(&apiToken).Error)
+
+	claims := auth.MakeAPITokenClaims(tokenID, "testhash", 1, 2, 3600)
+	tokenString, err := auth.MakeAPIToken("test", claims)
+	require.NoError(t, err)
+
+	var privileges []string
+	router := gin.New()
+	handler := func(c *gin.Context) {
+		privileges = c.GetStringSlice("prm")
+		c.Status(http.StatusOK)
+	}
+	router.Use(authMiddleware.AuthTokenRequired)
+	router.GET("/flows/", handler)
+	router.GET("/flows/:flowID", auth.PrivilegesRequired("flows.view"), handler)
+	router.GET("/termlogs/", handler)
+
+	call := func(path, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// source address is out of the allowlist
+	assert.Equal(t, http.StatusForbidden, call("/flows/5", "127.0.0.1:4000"))
+
+	assert.Equal(t, http.StatusOK, call("/flows/5", "10.1.2.3:4000"))
+	assert.ElementsMatch(t, []string{"flows.view", auth.PrivilegeAutomation}, privileges)
+
+	// flows out of the scope and listings across all flows are denied
+	assert.Equal(t, http.StatusForbidden, call("/flows/6", "10.1.2.3:4000"))
+	assert.Equal(t, http.StatusForbidden, call("/termlogs/", "10.1.2.3:4000"))
+	assert.Equal(t, http.StatusOK, call("/flows/", "10.1.2.3:4000"))
+
+	var stored models.APIToken
+	require.NoError(t, db.Where("token_id = ?", tokenID).Take(&stored).Error)
+	require.NotNil(t, stored.LastUsedAt)
+	require.NotNil(t, stored.LastUsedIP)
+	assert.Equal(t, "10.1.2.3", *stored.LastUsedIP)
+}
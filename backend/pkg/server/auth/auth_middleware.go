@@ -247,6 +247,23 @@ func (p *AuthMiddleware) tryProtoTokenAuthentication(c *gin.Context) (authResult
 		return authResultFail, fmt.Errorf("%w - token invalid for this installation", errUserHashMismatch)
 	}
 
+	scope, err := p.tokenCache.GetScope(apiClaims.TokenID)
+	if err != nil {
+		return authResultFail, fmt.Errorf("error checking token scope: %w", err)
+	}
+
+	clientIP := c.ClientIP()
+	if !scope.AllowsIP(clientIP) {
+		return authResultFail, fmt.Errorf("token is not allowed from '%s'", clientIP)
+	}
+	if err := checkFlowScope(c, scope); err != nil {
+		return authResultFail, err
+	}
+
+	if err := p.tokenCache.TouchLastUsed(apiClaims.TokenID, clientIP); err != nil {
+		logrus.WithError(err).WithField("tid", apiClaims.TokenID).Warn("failed to record api token use")
+	}
+
 	// generate UUID from user hash (fallback to empty string if hash is invalid)
 	uuid, err := rdb.MakeUuidStrFromHash(apiClaims.UHASH)
 	if err != nil {
@@ -264,6 +281,8 @@ func (p *AuthMiddleware) tryProtoTokenAuthentication(c *gin.Context) (authResult
 	c.Set("exp", apiClaims.ExpiresAt.Unix())
 	c.Set("uuid", uuid)
 	c.Set("cpt", "automation")
+	c.Set("tkid", apiClaims.TokenID)
+	SetTokenScope(c, scope)
 
 	return authResultOk, nil
 }
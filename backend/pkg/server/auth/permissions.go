@@ -37,6 +37,13 @@ func PrivilegesRequired(privs ...string) gin.HandlerFunc {
 				return
 			}
 		}
+
+		if err := checkFlowScope(c, GetTokenScope(c)); err != nil {
+			response.Error(c, response.ErrNotPermitted, err)
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -44,3 +51,67 @@ func PrivilegesRequired(privs ...string) gin.HandlerFunc {
 func LookupPerm(prm []string, perm string) bool {
 	return slices.Contains(prm, perm)
 }
+
+// PrivilegeAuditView allows to query, export and verify the audit log
+const PrivilegeAuditView = "audit.view"
+
+// PrivilegeRolesEdit allows to create, update and delete roles and their privileges
+const PrivilegeRolesEdit = "roles.edit"
+
+// knownPrivileges is the catalog of privileges seeded by the migrations, roles
+// may only be granted privileges from this list
+var knownPrivileges = []string{
+	"agentlogs.admin", "agentlogs.subscribe", "agentlogs.view",
+	"anonymize.call",
+	"assistantlogs.admin", "assistantlogs.subscribe", "assistantlogs.view",
+	"assistants.admin", "assistants.create", "assistants.delete", "assistants.edit", "assistants.subscribe", "assistants.view",
+	"audit.view",
+	"containers.admin", "containers.view",
+	"flow_files.admin", "flow_files.delete", "flow_files.download", "flow_files.edit", "flow_files.subscribe", "flow_files.upload", "flow_files.view",
+	"flows.admin", "flows.create", "flows.delete", "flows.edit", "flows.subscribe", "flows.view",
+	"instances.view",
+	"knowledge.admin", "knowledge.create", "knowledge.delete", "knowledge.edit", "knowledge.search", "knowledge.subscribe", "knowledge.view",
+	"msglogs.admin", "msglogs.subscribe", "msglogs.view",
+	"projects.admin", "projects.create", "projects.delete", "projects.edit", "projects.view",
+	"prompts.edit", "prompts.view",
+	"providers.view",
+	"resources.admin", "resources.delete", "resources.download", "resources.edit", "resources.subscribe", "resources.upload", "resources.view",
+	"roles.edit", "roles.view",
+	"screenshots.admin", "screenshots.download", "screenshots.subscribe", "screenshots.view",
+	"searchlogs.admin", "searchlogs.subscribe", "searchlogs.view",
+	"settings.admin",
+	"settings.prompts.admin", "settings.prompts.edit", "settings.prompts.view",
+	"settings.providers.admin", "settings.providers.edit", "settings.providers.subscribe", "settings.providers.view",
+	"settings.tokens.admin", "settings.tokens.create", "settings.tokens.delete", "settings.tokens.edit", "settings.tokens.subscribe", "settings.tokens.view",
+	"settings.user.admin", "settings.user.edit", "settings.user.subscribe", "settings.user.view",
+	"settings.view",
+	"subtasks.admin", "subtasks.view",
+	"tasks.admin", "tasks.subscribe", "tasks.view",
+	"templates.admin", "templates.create", "templates.delete", "templates.edit", "templates.subscribe", "templates.view",
+	"termlogs.admin", "termlogs.subscribe", "termlogs.view",
+	"toolcalls.admin", "toolcalls.view",
+	"usage.admin", "usage.view",
+	"users.create", "users.delete", "users.edit", "users.view",
+	"vecstorelogs.admin", "vecstorelogs.subscribe", "vecstorelogs.view",
+	"webhooks.admin", "webhooks.create", "webhooks.delete", "webhooks.edit", "webhooks.view",
+}
+
+// KnownPrivileges returns the sorted catalog of privileges which can be granted to a role
+func KnownPrivileges() []string {
+	return slices.Clone(knownPrivileges)
+}
+
+// ValidatePrivileges checks the privileges against the catalog and returns
+// them sorted and without duplicates
+func ValidatePrivileges(privs []string) ([]string, error) {
+	result := make([]string, 0, len(privs))
+	for _, priv := range privs {
+		if _, ok := slices.BinarySearch(knownPrivileges, priv); !ok {
+			return nil, fmt.Errorf("unknown privilege '%s'", priv)
+		}
+		result = append(result, priv)
+	}
+
+	slices.Sort(result)
+	return slices.Compact(result), nil
+}
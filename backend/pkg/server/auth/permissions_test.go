@@ -1,11 +1,17 @@
 package auth_test
 
 import (
-	"pentagi/pkg/server/auth"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"testing"
 
+	"pentagi/pkg/server/auth"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPrivilegesRequired(t *testing.T) {
@@ -34,3 +40,39 @@ func TestPrivilegesRequired(t *testing.T) {
 	server.Authorize(t, []string{"priv1", "priv2"})
 	assert.True(t, server.CallAndGetStatus(t))
 }
+
+func TestKnownPrivileges(t *testing.T) {
+	privs := auth.KnownPrivileges()
+	assert.True(t, slices.IsSorted(privs), "catalog must be sorted")
+	assert.Contains(t, privs, auth.PrivilegeRolesEdit)
+
+	// every privilege granted by the migrations must be in the catalog
+	files, err := filepath.Glob("../../../migrations/sql/*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	granted := regexp.MustCompile(`\(\s*\d+\s*,\s*'([a-z_]+(?:\.[a-z_]+)+)'\s*\)`)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		for _, match := range granted.FindAllStringSubmatch(string(data), -1) {
+			assert.Contains(t, privs, match[1], "privilege from %s", filepath.Base(file))
+		}
+	}
+}
+
+func TestValidatePrivileges(t *testing.T) {
+	privs, err := auth.ValidatePrivileges([]string{"flows.view", "flows.create", "flows.view"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"flows.create", "flows.view"}, privs)
+
+	privs, err = auth.ValidatePrivileges(nil)
+	require.NoError(t, err)
+	assert.Empty(t, privs)
+
+	_, err = auth.ValidatePrivileges([]string{"flows.view", "flows.destroy"})
+	assert.ErrorContains(t, err, "flows.destroy")
+
+	_, err = auth.ValidatePrivileges([]string{auth.PrivilegeAutomation})
+	assert.Error(t, err, "automation is implied by API tokens and can't be granted")
+}
@@ -50,6 +50,7 @@ type UsageStats struct {
 	TotalUsageCacheOut int     `json:"total_usage_cache_out" validate:"min=0"`
 	TotalUsageCostIn   float64 `json:"total_usage_cost_in" validate:"min=0"`
 	TotalUsageCostOut  float64 `json:"total_usage_cost_out" validate:"min=0"`
+	CacheHitRatio      float64 `json:"cache_hit_ratio" validate:"min=0,max=1"`
 }
 
 // Valid is function to control input/output data
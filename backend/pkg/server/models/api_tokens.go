@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
 )
 
 // TokenStatus represents the status of an API token
@@ -41,16 +42,22 @@ func (s TokenStatus) Validate(db *gorm.DB) {
 // APIToken is model to contain API token metadata
 // nolint:lll
 type APIToken struct {
-	ID        uint64      `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
-	TokenID   string      `form:"token_id" json:"token_id" validate:"required,len=10" gorm:"type:TEXT;NOT NULL;UNIQUE_INDEX"`
-	UserID    uint64      `form:"user_id" json:"user_id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL"`
-	RoleID    uint64      `form:"role_id" json:"role_id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL"`
-	Name      *string     `form:"name,omitempty" json:"name,omitempty" validate:"omitempty,max=100" gorm:"type:TEXT"`
-	TTL       uint64      `form:"ttl" json:"ttl" validate:"required,min=60,max=94608000" gorm:"type:BIGINT;NOT NULL"`
-	Status    TokenStatus `form:"status" json:"status" validate:"valid,required" gorm:"type:TOKEN_STATUS;NOT NULL;default:'active'"`
-	CreatedAt time.Time   `form:"created_at" json:"created_at" validate:"required" gorm:"type:TIMESTAMPTZ;NOT NULL;default:CURRENT_TIMESTAMP"`
-	UpdatedAt time.Time   `form:"updated_at" json:"updated_at" validate:"required" gorm:"type:TIMESTAMPTZ;NOT NULL;default:CURRENT_TIMESTAMP"`
-	DeletedAt *time.Time  `form:"deleted_at,omitempty" json:"deleted_at,omitempty" validate:"omitempty" sql:"index" gorm:"type:TIMESTAMPTZ"`
+	ID          uint64         `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	TokenID     string         `form:"token_id" json:"token_id" validate:"required,len=10" gorm:"type:TEXT;NOT NULL;UNIQUE_INDEX"`
+	UserID      uint64         `form:"user_id" json:"user_id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL"`
+	RoleID      uint64         `form:"role_id" json:"role_id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL"`
+	Name        *string        `form:"name,omitempty" json:"name,omitempty" validate:"omitempty,max=100" gorm:"type:TEXT"`
+	TTL         uint64         `form:"ttl" json:"ttl" validate:"required,min=60,max=94608000" gorm:"type:BIGINT;NOT NULL"`
+	Status      TokenStatus    `form:"status" json:"status" validate:"valid,required" gorm:"type:TOKEN_STATUS;NOT NULL;default:'active'"`
+	Privileges  pq.StringArray `form:"privileges,omitempty" json:"privileges,omitempty" validate:"omitempty,dive,max=70,required" gorm:"column:privileges;type:TEXT[]" swaggertype:"array,string"`
+	FlowIDs     pq.Int64Array  `form:"flow_ids,omitempty" json:"flow_ids,omitempty" validate:"omitempty,dive,min=1" gorm:"column:flow_ids;type:BIGINT[]" swaggertype:"array,integer"`
+	TemplateIDs pq.Int64Array  `form:"template_ids,omitempty" json:"template_ids,omitempty" validate:"omitempty,dive,min=1" gorm:"column:template_ids;type:BIGINT[]" swaggertype:"array,integer"`
+	AllowedIPs  pq.StringArray `form:"allowed_ips,omitempty" json:"allowed_ips,omitempty" validate:"omitempty,dive,ip|cidr" gorm:"column:allowed_ips;type:TEXT[]" swaggertype:"array,string"`
+	LastUsedAt  *time.Time     `form:"last_used_at,omitempty" json:"last_used_at,omitempty" validate:"omitempty" gorm:"column:last_used_at;type:TIMESTAMPTZ"`
+	LastUsedIP  *string        `form:"last_used_ip,omitempty" json:"last_used_ip,omitempty" validate:"omitempty" gorm:"column:last_used_ip;type:TEXT"`
+	CreatedAt   time.Time      `form:"created_at" json:"created_at" validate:"required" gorm:"type:TIMESTAMPTZ;NOT NULL;default:CURRENT_TIMESTAMP"`
+	UpdatedAt   time.Time      `form:"updated_at" json:"updated_at" validate:"required" gorm:"type:TIMESTAMPTZ;NOT NULL;default:CURRENT_TIMESTAMP"`
+	DeletedAt   *time.Time     `form:"deleted_at,omitempty" json:"deleted_at,omitempty" validate:"omitempty" sql:"index" gorm:"type:TIMESTAMPTZ"`
 }
 
 // TableName returns the table name string to guaranty use correct table
@@ -93,6 +100,14 @@ func (ats APITokenWithSecret) Valid() error {
 type CreateAPITokenRequest struct {
 	Name *string `form:"name,omitempty" json:"name,omitempty" validate:"omitempty,max=100"`
 	TTL  uint64  `form:"ttl" json:"ttl" validate:"required,min=60,max=94608000"` // from 1 minute to 3 years
+	// Privileges must be held by the creator, the token gets all privileges of the role when empty
+	Privileges []string `form:"privileges,omitempty" json:"privileges,omitempty" validate:"omitempty,max=200,dive,max=70,required"`
+	// FlowIDs restricts the token to these flows
+	FlowIDs []int64 `form:"flow_ids,omitempty" json:"flow_ids,omitempty" validate:"omitempty,max=100,dive,min=1"`
+	// TemplateIDs restricts the flows the token creates to these templates
+	TemplateIDs []int64 `form:"template_ids,omitempty" json:"template_ids,omitempty" validate:"omitempty,max=100,dive,min=1"`
+	// AllowedIPs restricts the source addresses of the token, IPs or CIDR ranges
+	AllowedIPs []string `form:"allowed_ips,omitempty" json:"allowed_ips,omitempty" validate:"omitempty,max=50,dive,ip|cidr"`
 }
 
 // Valid is function to control input/output data
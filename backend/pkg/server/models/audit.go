@@ -0,0 +1,209 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// AuditStatus represents the outcome of an audited action
+type AuditStatus string
+
+const (
+	AuditStatusSuccess AuditStatus = "success"
+	AuditStatusFailure AuditStatus = "failure"
+)
+
+func (s AuditStatus) String() string {
+	return string(s)
+}
+
+// Valid is function to control input/output data
+func (s AuditStatus) Valid() error {
+	switch s {
+	case AuditStatusSuccess, AuditStatusFailure:
+		return nil
+	default:
+		return fmt.Errorf("invalid AuditStatus: %s", s)
+	}
+}
+
+// Validate is function to use callback to control input/output data
+func (s AuditStatus) Validate(db *gorm.DB) {
+	if err := s.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// AuditDiff is the JSON document of the requested change, it's stored and
+// returned byte for byte because it's a part of the event hash
+type AuditDiff string
+
+// MarshalJSON returns the document as is
+func (c AuditDiff) MarshalJSON() ([]byte, error) {
+	if c == "" {
+		return []byte("null"), nil
+	}
+	return []byte(c), nil
+}
+
+// UnmarshalJSON keeps the raw document
+func (c *AuditDiff) UnmarshalJSON(data []byte) error {
+	if !json.Valid(data) {
+		return fmt.Errorf("invalid AuditDiff: not a JSON document")
+	}
+	if string(data) == "null" {
+		*c = ""
+		return nil
+	}
+	*c = AuditDiff(data)
+	return nil
+}
+
+// Value stores the empty document as NULL
+func (c AuditDiff) Value() (driver.Value, error) {
+	if c == "" {
+		return nil, nil
+	}
+	return string(c), nil
+}
+
+// Scan reads the document as is
+func (c *AuditDiff) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*c = ""
+	case []byte:
+		*c = AuditDiff(src)
+	case string:
+		*c = AuditDiff(src)
+	default:
+		return fmt.Errorf("invalid AuditDiff: unsupported type %T", src)
+	}
+	return nil
+}
+
+// AuditEvent is model to contain an append-only record of a user or admin action
+// nolint:lll
+type AuditEvent struct {
+	ID         uint64      `form:"id" json:"id" validate:"min=0,numeric" gorm:"type:BIGINT;NOT NULL;PRIMARY_KEY;AUTO_INCREMENT"`
+	UserID     *uint64     `form:"user_id,omitempty" json:"user_id,omitempty" validate:"omitempty,min=0,numeric" gorm:"type:BIGINT"`
+	UserType   string      `form:"user_type" json:"user_type" validate:"omitempty,max=20" gorm:"type:TEXT;NOT NULL;default:''"`
+	TokenID    *string     `form:"token_id,omitempty" json:"token_id,omitempty" validate:"omitempty,len=10" gorm:"type:TEXT"`
+	Action     string      `form:"action" json:"action" validate:"required,max=200" gorm:"type:TEXT;NOT NULL"`
+	Method     string      `form:"method" json:"method" validate:"max=300" gorm:"type:TEXT;NOT NULL;default:''"`
+	TargetType string      `form:"target_type" json:"target_type" validate:"max=100" gorm:"type:TEXT;NOT NULL;default:''"`
+	TargetID   string      `form:"target_id" json:"target_id" validate:"max=300" gorm:"type:TEXT;NOT NULL;default:''"`
+	Diff       AuditDiff   `form:"diff,omitempty" json:"diff,omitempty" validate:"omitempty" gorm:"column:diff;type:JSON" swaggertype:"object"`
+	Status     AuditStatus `form:"status" json:"status" validate:"valid,required" gorm:"type:TEXT;NOT NULL"`
+	Error      string      `form:"error" json:"error,omitempty" validate:"max=1000" gorm:"type:TEXT;NOT NULL;default:''"`
+	IP         string      `form:"ip" json:"ip" validate:"max=100" gorm:"column:ip;type:TEXT;NOT NULL;default:''"`
+	UserAgent  string      `form:"user_agent" json:"user_agent" validate:"max=1000" gorm:"type:TEXT;NOT NULL;default:''"`
+	PrevHash   string      `form:"prev_hash" json:"prev_hash" validate:"omitempty,len=64,hexadecimal" gorm:"type:TEXT;NOT NULL;default:''"`
+	Hash       string      `form:"hash" json:"hash" validate:"len=64,hexadecimal" gorm:"type:TEXT;NOT NULL"`
+	CreatedAt  time.Time   `form:"created_at" json:"created_at" validate:"required" gorm:"type:TIMESTAMPTZ;NOT NULL"`
+}
+
+// TableName returns the table name string to guaranty use correct table
+func (e *AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// Valid is function to control input/output data
+func (e AuditEvent) Valid() error {
+	if err := e.Status.Valid(); err != nil {
+		return err
+	}
+	return validate.Struct(e)
+}
+
+// Validate is function to use callback to control input/output data
+func (e AuditEvent) Validate(db *gorm.DB) {
+	if err := e.Valid(); err != nil {
+		db.AddError(err)
+	}
+}
+
+// AuditVerification is the result of checking the hash chain of the audit log
+// nolint:lll
+type AuditVerification struct {
+	Valid    bool    `json:"valid"`
+	Checked  uint64  `json:"checked"`
+	LastID   uint64  `json:"last_id"`
+	LastHash string  `json:"last_hash,omitempty"`
+	BrokenID *uint64 `json:"broken_id,omitempty"`
+	Reason   string  `json:"reason,omitempty"`
+}
+
+// AuditExportFormat is the format of the exported audit events
+type AuditExportFormat string
+
+const (
+	AuditExportFormatJSONL  AuditExportFormat = "jsonl"
+	AuditExportFormatSyslog AuditExportFormat = "syslog"
+)
+
+// Valid is function to control input/output data
+func (f AuditExportFormat) Valid() error {
+	switch f {
+	case AuditExportFormatJSONL, AuditExportFormatSyslog:
+		return nil
+	default:
+		return fmt.Errorf("invalid AuditExportFormat: %s", f)
+	}
+}
+
+// AuditExportQuery filters the exported audit events, AfterID lets a collector
+// pull only the events it hasn't seen yet
+// nolint:lll
+type AuditExportQuery struct {
+	Format     AuditExportFormat `form:"format,default=jsonl" json:"format" validate:"valid,required" enums:"jsonl,syslog" default:"jsonl"`
+	AfterID    uint64            `form:"after_id" json:"after_id,omitempty" validate:"min=0"`
+	UserID     *uint64           `form:"user_id" json:"user_id,omitempty" validate:"omitempty,min=0"`
+	TokenID    string            `form:"token_id" json:"token_id,omitempty" validate:"omitempty,len=10"`
+	Action     string            `form:"action" json:"action,omitempty" validate:"max=200"`
+	TargetType string            `form:"target_type" json:"target_type,omitempty" validate:"max=100"`
+	TargetID   string            `form:"target_id" json:"target_id,omitempty" validate:"max=300"`
+	Status     AuditStatus       `form:"status" json:"status,omitempty" validate:"omitempty,valid" enums:"success,failure"`
+	Since      *time.Time        `form:"since" json:"since,omitempty" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until      *time.Time        `form:"until" json:"until,omitempty" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// Valid is function to control input/output data
+func (q AuditExportQuery) Valid() error {
+	return validate.Struct(q)
+}
+
+// Scope returns the conditions of the query, the action matches as a prefix
+// so "flows" selects every flow action
+func (q AuditExportQuery) Scope(db *gorm.DB) *gorm.DB {
+	db = db.Where("id > ?", q.AfterID)
+	if q.UserID != nil {
+		db = db.Where("user_id = ?", *q.UserID)
+	}
+	if q.TokenID != "" {
+		db = db.Where("token_id = ?", q.TokenID)
+	}
+	if q.Action != "" {
+		db = db.Where("action = ? OR action LIKE ?", q.Action, q.Action+".%")
+	}
+	if q.TargetType != "" {
+		db = db.Where("target_type = ?", q.TargetType)
+	}
+	if q.TargetID != "" {
+		db = db.Where("target_id = ?", q.TargetID)
+	}
+	if q.Status != "" {
+		db = db.Where("status = ?", q.Status)
+	}
+	if q.Since != nil {
+		db = db.Where("created_at >= ?", *q.Since)
+	}
+	if q.Until != nil {
+		db = db.Where("created_at < ?", *q.Until)
+	}
+	return db
+}
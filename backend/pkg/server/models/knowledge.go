@@ -3,7 +3,9 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"pentagi/pkg/database/knowledge/ingest"
 	gqlmodel "pentagi/pkg/graph/model"
 )
 
@@ -23,6 +25,13 @@ type knowledgeRawMeta struct {
 	PartSize    int    `json:"part_size"`
 	TotalSize   int    `json:"total_size"`
 	Manual      bool   `json:"manual"`
+
+	OriginInstance string     `json:"origin_instance"`
+	OriginTenant   string     `json:"origin_tenant"`
+	OriginID       string     `json:"origin_id"`
+	OriginModel    string     `json:"origin_model"`
+	ExportedAt     *time.Time `json:"exported_at"`
+	ImportedAt     *time.Time `json:"imported_at"`
 }
 
 func knowledgeMetaFromJSON(raw string) knowledgeRawMeta {
@@ -63,6 +72,25 @@ func knowledgeMetaToEntry(id, content string, m knowledgeRawMeta) KnowledgeDocEn
 		cl := m.CodeLang
 		entry.CodeLang = &cl
 	}
+	if m.OriginInstance != "" && m.OriginID != "" {
+		p := &KnowledgeProvenanceEntry{
+			InstanceID: m.OriginInstance,
+			DocumentID: m.OriginID,
+		}
+		if m.OriginTenant != "" {
+			p.TenantID = &m.OriginTenant
+		}
+		if m.OriginModel != "" {
+			p.EmbeddingModel = &m.OriginModel
+		}
+		if m.ExportedAt != nil {
+			p.ExportedAt = *m.ExportedAt
+		}
+		if m.ImportedAt != nil {
+			p.ImportedAt = *m.ImportedAt
+		}
+		entry.Provenance = p
+	}
 	return entry
 }
 
@@ -160,6 +188,19 @@ type KnowledgeDocEntry struct {
 	PartSize    int                  `json:"part_size"`
 	TotalSize   int                  `json:"total_size"`
 	Manual      bool                 `json:"manual"`
+	// Provenance is set for documents imported from a knowledge archive.
+	Provenance *KnowledgeProvenanceEntry `json:"provenance,omitempty"`
+}
+
+// KnowledgeProvenanceEntry is the origin of a document imported from another
+// instance's knowledge archive.
+type KnowledgeProvenanceEntry struct {
+	InstanceID     string    `json:"instance_id"`
+	TenantID       *string   `json:"tenant_id,omitempty"`
+	DocumentID     string    `json:"document_id"`
+	EmbeddingModel *string   `json:"embedding_model,omitempty"`
+	ExportedAt     time.Time `json:"exported_at"`
+	ImportedAt     time.Time `json:"imported_at"`
 }
 
 // KnowledgeDocList is the REST list response (Total matches rdb.TableQuery return type).
@@ -188,6 +229,33 @@ type KnowledgeSearchResult struct {
 	Total int                     `json:"total"`
 }
 
+// KnowledgeImportJobEntry is the REST representation of a bulk import job.
+//
+//nolint:lll
+type KnowledgeImportJobEntry struct {
+	ID              int64     `json:"id"`
+	UserID          int64     `json:"user_id"`
+	Status          string    `json:"status" enums:"pending,running,finished,failed"`
+	Source          string    `json:"source"`
+	TotalFiles      int       `json:"total_files"`
+	SkippedFiles    int       `json:"skipped_files"`
+	TotalChunks     int       `json:"total_chunks"`
+	ProcessedChunks int       `json:"processed_chunks"`
+	CreatedDocs     int       `json:"created_docs"`
+	UpdatedDocs     int       `json:"updated_docs"`
+	DuplicateDocs   int       `json:"duplicate_docs"`
+	FailedDocs      int       `json:"failed_docs"`
+	Error           *string   `json:"error,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// KnowledgeImportJobList is the REST list response for import jobs.
+type KnowledgeImportJobList struct {
+	Items []KnowledgeImportJobEntry `json:"items"`
+	Total uint64                    `json:"total"`
+}
+
 // ==================== Query / request models =================================
 
 // KnowledgeListQuery holds query-string parameters for the list endpoint.
@@ -282,6 +350,114 @@ func (r UpdateKnowledgeDocRequest) Valid() error {
 	return validate.Struct(r)
 }
 
+// KnowledgeImportRequest holds the optional multipart form fields sent along
+// with the files of a bulk import; unset metadata is inferred per document.
+//
+//nolint:lll
+type KnowledgeImportRequest struct {
+	Splitter     string               `form:"splitter" json:"splitter,omitempty" validate:"omitempty,oneof=auto markdown recursive none" enums:"auto,markdown,recursive,none" default:"auto"`
+	ChunkSize    int                  `form:"chunk_size" json:"chunk_size,omitempty" validate:"omitempty,min=100,max=65536"`
+	ChunkOverlap *int                 `form:"chunk_overlap" json:"chunk_overlap,omitempty" validate:"omitempty,min=0,max=16384"`
+	DocType      *KnowledgeDocType    `form:"doc_type" json:"doc_type,omitempty" validate:"omitempty,valid"`
+	GuideType    *KnowledgeGuideType  `form:"guide_type" json:"guide_type,omitempty" validate:"omitempty,valid"`
+	AnswerType   *KnowledgeAnswerType `form:"answer_type" json:"answer_type,omitempty" validate:"omitempty,valid"`
+	CodeLang     *string              `form:"code_lang" json:"code_lang,omitempty" validate:"omitempty,max=100"`
+}
+
+// Valid implements IValid.
+func (r KnowledgeImportRequest) Valid() error {
+	if r.DocType != nil {
+		if err := r.DocType.Valid(); err != nil {
+			return err
+		}
+	}
+	if r.GuideType != nil {
+		if err := r.GuideType.Valid(); err != nil {
+			return err
+		}
+	}
+	if r.AnswerType != nil {
+		if err := r.AnswerType.Valid(); err != nil {
+			return err
+		}
+	}
+	return validate.Struct(r)
+}
+
+// ToOptions applies the request on top of the server default import options.
+func (r KnowledgeImportRequest) ToOptions(defaults ingest.Options) ingest.Options {
+	opts := defaults
+	if r.Splitter != "" {
+		opts.Splitter = ingest.Splitter(r.Splitter)
+	}
+	if r.ChunkSize > 0 {
+		opts.ChunkSize = r.ChunkSize
+	}
+	if r.ChunkOverlap != nil {
+		opts.ChunkOverlap = *r.ChunkOverlap
+	}
+	if r.DocType != nil {
+		opts.DocType = string(*r.DocType)
+	}
+	if r.GuideType != nil {
+		opts.GuideType = string(*r.GuideType)
+	}
+	if r.AnswerType != nil {
+		opts.AnswerType = string(*r.AnswerType)
+	}
+	if r.CodeLang != nil {
+		opts.CodeLang = *r.CodeLang
+	}
+	return opts
+}
+
+// KnowledgeExportQuery holds query-string parameters for the export endpoint.
+//
+//nolint:lll
+type KnowledgeExportQuery struct {
+	WithEmbeddings bool                  `form:"with_embeddings" json:"with_embeddings"`
+	DocTypes       []KnowledgeDocType    `form:"doc_types[]" json:"doc_types,omitempty"`
+	GuideTypes     []KnowledgeGuideType  `form:"guide_types[]" json:"guide_types,omitempty"`
+	AnswerTypes    []KnowledgeAnswerType `form:"answer_types[]" json:"answer_types,omitempty"`
+	CodeLangs      []string              `form:"code_langs[]" json:"code_langs,omitempty"`
+	FlowID         *int64                `form:"flow_id" json:"flow_id,omitempty"`
+	Manual         *bool                 `form:"manual" json:"manual,omitempty"`
+}
+
+// Valid validates all enum values supplied in the export query.
+func (q KnowledgeExportQuery) Valid() error {
+	return q.listQuery().Valid()
+}
+
+// ToGQLFilter converts the export query to a GraphQL KnowledgeFilter.
+func (q KnowledgeExportQuery) ToGQLFilter() *gqlmodel.KnowledgeFilter {
+	return q.listQuery().ToGQLFilter()
+}
+
+func (q KnowledgeExportQuery) listQuery() KnowledgeListQuery {
+	return KnowledgeListQuery{
+		DocTypes:    q.DocTypes,
+		GuideTypes:  q.GuideTypes,
+		AnswerTypes: q.AnswerTypes,
+		CodeLangs:   q.CodeLangs,
+		FlowID:      q.FlowID,
+		Manual:      q.Manual,
+	}
+}
+
+// KnowledgeArchiveImportRequest holds the multipart form fields sent along
+// with a knowledge archive.
+//
+//nolint:lll
+type KnowledgeArchiveImportRequest struct {
+	Conflict string `form:"conflict" json:"conflict,omitempty" validate:"omitempty,oneof=skip overwrite duplicate" enums:"skip,overwrite,duplicate" default:"skip"`
+}
+
+// Valid implements IValid.
+func (r KnowledgeArchiveImportRequest) Valid() error {
+	return validate.Struct(r)
+}
+
 // KnowledgeSearchRequest is the POST body for semantic search.
 //
 //nolint:lll
@@ -347,6 +523,16 @@ func KnowledgeDocFromGQL(doc *gqlmodel.KnowledgeDocument) KnowledgeDocEntry {
 	if doc.CodeLang != nil {
 		entry.CodeLang = doc.CodeLang
 	}
+	if p := doc.Provenance; p != nil {
+		entry.Provenance = &KnowledgeProvenanceEntry{
+			InstanceID:     p.InstanceID,
+			TenantID:       p.TenantID,
+			DocumentID:     p.DocumentID,
+			EmbeddingModel: p.EmbeddingModel,
+			ExportedAt:     p.ExportedAt,
+			ImportedAt:     p.ImportedAt,
+		}
+	}
 	return entry
 }
 
@@ -474,3 +660,33 @@ func (r KnowledgeSearchRequest) ToGQLFilter() *gqlmodel.KnowledgeFilter {
 	}
 	return f
 }
+
+// KnowledgeImportJobFromGQL converts a GraphQL KnowledgeImportJob to its REST representation.
+func KnowledgeImportJobFromGQL(job *gqlmodel.KnowledgeImportJob) KnowledgeImportJobEntry {
+	return KnowledgeImportJobEntry{
+		ID:              job.ID,
+		UserID:          job.UserID,
+		Status:          string(job.Status),
+		Source:          job.Source,
+		TotalFiles:      job.TotalFiles,
+		SkippedFiles:    job.SkippedFiles,
+		TotalChunks:     job.TotalChunks,
+		ProcessedChunks: job.ProcessedChunks,
+		CreatedDocs:     job.CreatedDocs,
+		UpdatedDocs:     job.UpdatedDocs,
+		DuplicateDocs:   job.DuplicateDocs,
+		FailedDocs:      job.FailedDocs,
+		Error:           job.Error,
+		CreatedAt:       job.CreatedAt,
+		UpdatedAt:       job.UpdatedAt,
+	}
+}
+
+// KnowledgeImportJobListFromGQL converts a slice of GraphQL import jobs to a REST list response.
+func KnowledgeImportJobListFromGQL(jobs []*gqlmodel.KnowledgeImportJob) KnowledgeImportJobList {
+	items := make([]KnowledgeImportJobEntry, 0, len(jobs))
+	for _, job := range jobs {
+		items = append(items, KnowledgeImportJobFromGQL(job))
+	}
+	return KnowledgeImportJobList{Items: items, Total: uint64(len(items))}
+}
@@ -72,3 +72,15 @@ func (rp RolePrivileges) Validate(db *gorm.DB) {
 		db.AddError(err)
 	}
 }
+
+// RoleInput is model to contain role data to create or update it
+// nolint:lll
+type RoleInput struct {
+	Name       string   `form:"name" json:"name" validate:"max=50,required"`
+	Privileges []string `form:"privileges" json:"privileges" validate:"dive,max=70,required"`
+}
+
+// Valid is function to control input/output data
+func (ri RoleInput) Valid() error {
+	return validate.Struct(ri)
+}
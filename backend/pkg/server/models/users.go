@@ -9,7 +9,10 @@ import (
 	"github.com/jinzhu/gorm"
 )
 
-const RoleUser = 2
+const (
+	RoleAdmin = 1
+	RoleUser  = 2
+)
 
 type UserStatus string
 
@@ -9,9 +9,20 @@ import (
 
 type OAuthEmailResolver func(ctx context.Context, nonce string, token *oauth2.Token) (string, bool, error)
 
+// OAuthUser is the identity of the user asserted by the external provider
+type OAuthUser struct {
+	Email    string
+	Verified bool
+	Name     string
+	Groups   []string
+}
+
+type OAuthUserResolver func(ctx context.Context, nonce string, token *oauth2.Token) (OAuthUser, error)
+
 type OAuthClient interface {
 	ProviderName() string
 	ResolveEmail(ctx context.Context, nonce string, token *oauth2.Token) (string, bool, error)
+	ResolveUser(ctx context.Context, nonce string, token *oauth2.Token) (OAuthUser, error)
 	TokenSource(ctx context.Context, token *oauth2.Token) oauth2.TokenSource
 	Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error)
 	RefreshToken(ctx context.Context, token string) (*oauth2.Token, error)
@@ -23,6 +34,7 @@ type oauthClient struct {
 	verifier      string
 	conf          *oauth2.Config
 	emailResolver OAuthEmailResolver
+	userResolver  OAuthUserResolver
 }
 
 func NewOAuthClient(name string, conf *oauth2.Config, emailResolver OAuthEmailResolver) OAuthClient {
@@ -34,17 +46,44 @@ func NewOAuthClient(name string, conf *oauth2.Config, emailResolver OAuthEmailRe
 	}
 }
 
+// NewOAuthUserClient creates a client for providers which assert more than the email
+func NewOAuthUserClient(name string, conf *oauth2.Config, userResolver OAuthUserResolver) OAuthClient {
+	return &oauthClient{
+		name:         name,
+		verifier:     oauth2.GenerateVerifier(),
+		conf:         conf,
+		userResolver: userResolver,
+	}
+}
+
 func (o *oauthClient) ProviderName() string {
 	return o.name
 }
 
 func (o *oauthClient) ResolveEmail(ctx context.Context, nonce string, token *oauth2.Token) (string, bool, error) {
 	if o.emailResolver == nil {
-		return "", false, fmt.Errorf("email resolver is not set")
+		user, err := o.ResolveUser(ctx, nonce, token)
+		return user.Email, user.Verified, err
 	}
 	return o.emailResolver(ctx, nonce, token)
 }
 
+func (o *oauthClient) ResolveUser(ctx context.Context, nonce string, token *oauth2.Token) (OAuthUser, error) {
+	if o.userResolver != nil {
+		return o.userResolver(ctx, nonce, token)
+	}
+	if o.emailResolver == nil {
+		return OAuthUser{}, fmt.Errorf("user resolver is not set")
+	}
+
+	email, verified, err := o.emailResolver(ctx, nonce, token)
+	if err != nil {
+		return OAuthUser{}, err
+	}
+
+	return OAuthUser{Email: email, Verified: verified}, nil
+}
+
 func (o *oauthClient) TokenSource(ctx context.Context, token *oauth2.Token) oauth2.TokenSource {
 	return o.conf.TokenSource(ctx, token)
 }
@@ -0,0 +1,191 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// OIDCConfig describes a generic OpenID Connect provider (Keycloak, Okta, Azure AD, ...)
+type OIDCConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// claim names support dotted paths to nested objects, e.g. "realm_access.roles"
+	EmailClaim  string
+	NameClaim   string
+	GroupsClaim string
+	// TrustEmail accepts emails which are not marked as verified by the provider
+	TrustEmail bool
+}
+
+// NewOIDCOAuthClient discovers the provider configuration from the issuer URL
+// and returns a client resolving the user from the ID token and userinfo claims
+func NewOIDCOAuthClient(ctx context.Context, cfg OIDCConfig) (OAuthClient, error) {
+	if cfg.Name == "" {
+		cfg.Name = "oidc"
+	}
+	if cfg.IssuerURL == "" || cfg.ClientID == "" {
+		return nil, fmt.Errorf("issuer URL and client ID are required for %s provider", cfg.Name)
+	}
+	if cfg.EmailClaim == "" {
+		cfg.EmailClaim = "email"
+	}
+	if cfg.NameClaim == "" {
+		cfg.NameClaim = "name"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+
+	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not discover %s OpenID provider: %w", cfg.Name, err)
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	} else if !slices.Contains(scopes, oidc.ScopeOpenID) {
+		scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+	}
+
+	return NewOAuthUserClient(cfg.Name, &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       scopes,
+		Endpoint:     provider.Endpoint(),
+	}, newOIDCUserResolver(provider, cfg)), nil
+}
+
+func newOIDCUserResolver(provider *oidc.Provider, cfg OIDCConfig) OAuthUserResolver {
+	verifier := provider.Verifier(&oidc.Config{ClientID: cfg.ClientID})
+
+	return func(ctx context.Context, nonce string, token *oauth2.Token) (OAuthUser, error) {
+		oidToken, ok := token.Extra("id_token").(string)
+		if !ok {
+			return OAuthUser{}, fmt.Errorf("id_token is not present in the token")
+		}
+
+		idToken, err := verifier.Verify(ctx, oidToken)
+		if err != nil {
+			return OAuthUser{}, fmt.Errorf("could not verify %s ID Token: %w", cfg.Name, err)
+		}
+
+		if idToken.Nonce != nonce {
+			return OAuthUser{}, fmt.Errorf("nonce mismatch in %s ID Token", cfg.Name)
+		}
+
+		// at_hash is optional in the code flow, it's verified only when present
+		if idToken.AccessTokenHash != "" {
+			if err := idToken.VerifyAccessToken(token.AccessToken); err != nil {
+				return OAuthUser{}, fmt.Errorf("failed to verify %s Access Token: %w", cfg.Name, err)
+			}
+		}
+
+		claims := map[string]any{}
+		if err := idToken.Claims(&claims); err != nil {
+			return OAuthUser{}, fmt.Errorf("failed to parse %s ID Token claims: %w", cfg.Name, err)
+		}
+
+		// some providers (Okta by default) put profile claims only into the userinfo response
+		_, hasEmail := lookupClaim(claims, cfg.EmailClaim)
+		_, hasGroups := lookupClaim(claims, cfg.GroupsClaim)
+		if (!hasEmail || !hasGroups) && provider.UserInfoEndpoint() != "" {
+			info, err := provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+			if err != nil {
+				return OAuthUser{}, fmt.Errorf("failed to get %s user info: %w", cfg.Name, err)
+			}
+
+			extra := map[string]any{}
+			if err := info.Claims(&extra); err != nil {
+				return OAuthUser{}, fmt.Errorf("failed to parse %s user info claims: %w", cfg.Name, err)
+			}
+			for key, value := range extra {
+				if _, ok := claims[key]; !ok {
+					claims[key] = value
+				}
+			}
+		}
+
+		user := mapOIDCClaims(claims, cfg)
+		if user.Email == "" {
+			return OAuthUser{}, fmt.Errorf("claim %q is empty in %s ID Token", cfg.EmailClaim, cfg.Name)
+		}
+
+		return user, nil
+	}
+}
+
+func mapOIDCClaims(claims map[string]any, cfg OIDCConfig) OAuthUser {
+	user := OAuthUser{Verified: cfg.TrustEmail}
+
+	if value, ok := lookupClaim(claims, cfg.EmailClaim); ok {
+		user.Email, _ = value.(string)
+	}
+	if value, ok := lookupClaim(claims, cfg.NameClaim); ok {
+		user.Name, _ = value.(string)
+	}
+	if value, ok := lookupClaim(claims, cfg.GroupsClaim); ok {
+		user.Groups = claimStrings(value)
+	}
+
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		user.Verified = user.Verified || verified
+	case string:
+		user.Verified = user.Verified || strings.EqualFold(verified, "true")
+	}
+
+	return user
+}
+
+// lookupClaim resolves the claim by its name first and then as a dotted path
+func lookupClaim(claims map[string]any, name string) (any, bool) {
+	if value, ok := claims[name]; ok {
+		return value, true
+	}
+
+	var current any = claims
+	for _, part := range strings.Split(name, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func claimStrings(value any) []string {
+	switch value := value.(type) {
+	case string:
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	case []string:
+		return value
+	case []any:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,226 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+const (
+	mockIDPClientID = "pentagi"
+	mockIDPKeyID    = "test-key"
+)
+
+// mockIDP is a minimal OpenID provider serving discovery, JWKS, token and userinfo endpoints
+type mockIDP struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	claims   jwt.MapClaims
+	userinfo map[string]any
+}
+
+func newMockIDP(t *testing.T) *mockIDP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &mockIDP{key: key}
+	mux := http.NewServeMux()
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		doc := map[string]any{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/authorize",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"jwks_uri":                              idp.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		}
+		if idp.userinfo != nil {
+			doc["userinfo_endpoint"] = idp.server.URL + "/userinfo"
+		}
+		writeJSON(w, doc)
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]any{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": mockIDPKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idp.idToken(t),
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, idp.userinfo)
+	})
+
+	return idp
+}
+
+func (m *mockIDP) idToken(t *testing.T) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"iss": m.server.URL,
+		"aud": mockIDPClientID,
+		"sub": "user-1",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for key, value := range m.claims {
+		claims[key] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = mockIDPKeyID
+	signed, err := token.SignedString(m.key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func (m *mockIDP) login(t *testing.T, cfg OIDCConfig) (OAuthUser, error) {
+	t.Helper()
+
+	ctx := context.Background()
+	cfg.IssuerURL = m.server.URL
+	cfg.ClientID = mockIDPClientID
+
+	client, err := NewOIDCOAuthClient(ctx, cfg)
+	require.NoError(t, err)
+
+	token, err := client.Exchange(ctx, "code")
+	require.NoError(t, err)
+
+	return client.ResolveUser(ctx, "nonce", token)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func TestOIDCClient_ResolveUser(t *testing.T) {
+	idp := newMockIDP(t)
+	idp.claims = jwt.MapClaims{
+		"nonce":          "nonce",
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"name":           "Alice",
+		"groups":         []string{"pentesters", "admins"},
+	}
+
+	user, err := idp.login(t, OIDCConfig{Name: "keycloak"})
+	require.NoError(t, err)
+	assert.Equal(t, OAuthUser{
+		Email:    "alice@example.com",
+		Verified: true,
+		Name:     "Alice",
+		Groups:   []string{"pentesters", "admins"},
+	}, user)
+}
+
+func TestOIDCClient_ResolveUserNestedClaims(t *testing.T) {
+	idp := newMockIDP(t)
+	idp.claims = jwt.MapClaims{
+		"nonce":              "nonce",
+		"preferred_username": "bob@example.com",
+		"realm_access":       map[string]any{"roles": []string{"operators"}},
+	}
+
+	user, err := idp.login(t, OIDCConfig{
+		EmailClaim:  "preferred_username",
+		GroupsClaim: "realm_access.roles",
+		TrustEmail:  true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "bob@example.com", user.Email)
+	assert.True(t, user.Verified)
+	assert.Equal(t, []string{"operators"}, user.Groups)
+}
+
+func TestOIDCClient_ResolveUserFromUserInfo(t *testing.T) {
+	idp := newMockIDP(t)
+	idp.claims = jwt.MapClaims{"nonce": "nonce"}
+	idp.userinfo = map[string]any{
+		"sub":            "user-1",
+		"email":          "carol@example.com",
+		"email_verified": "true",
+		"groups":         []string{"everyone"},
+	}
+
+	user, err := idp.login(t, OIDCConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "carol@example.com", user.Email)
+	assert.True(t, user.Verified)
+	assert.Equal(t, []string{"everyone"}, user.Groups)
+}
+
+func TestOIDCClient_ResolveUserErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"nonce mismatch", jwt.MapClaims{"nonce": "other", "email": "alice@example.com"}},
+		{"wrong audience", jwt.MapClaims{"nonce": "nonce", "email": "alice@example.com", "aud": "other"}},
+		{"missing email", jwt.MapClaims{"nonce": "nonce"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIDP(t)
+			idp.claims = tt.claims
+
+			_, err := idp.login(t, OIDCConfig{})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOIDCClient_MissingIDToken(t *testing.T) {
+	idp := newMockIDP(t)
+
+	client, err := NewOIDCOAuthClient(context.Background(), OIDCConfig{
+		IssuerURL: idp.server.URL,
+		ClientID:  mockIDPClientID,
+	})
+	require.NoError(t, err)
+
+	_, err = client.ResolveUser(context.Background(), "nonce", &oauth2.Token{AccessToken: "access-token"})
+	assert.ErrorContains(t, err, "id_token is not present")
+}
+
+func TestNewOIDCOAuthClient_Validation(t *testing.T) {
+	_, err := NewOIDCOAuthClient(context.Background(), OIDCConfig{IssuerURL: "http://127.0.0.1:1"})
+	assert.ErrorContains(t, err, "client ID are required")
+
+	_, err = NewOIDCOAuthClient(context.Background(), OIDCConfig{IssuerURL: "http://127.0.0.1:1", ClientID: "id"})
+	assert.ErrorContains(t, err, "could not discover")
+}
@@ -0,0 +1,65 @@
+package oauth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RoleMappingFallback matches any user which is not matched by other groups
+const RoleMappingFallback = "*"
+
+type GroupRole struct {
+	Group string
+	Role  string
+}
+
+// RoleMapping maps groups asserted by the identity provider to local role names,
+// entries are evaluated in order and the first matching group wins
+type RoleMapping []GroupRole
+
+// ParseRoleMapping parses "group=Role;other group=Role" definitions, the role is split
+// by the last "=" so groups may be LDAP distinguished names like "cn=admins,dc=corp"
+func ParseRoleMapping(value string) (RoleMapping, error) {
+	var mapping RoleMapping
+
+	for _, pair := range strings.Split(value, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(pair, "=")
+		if idx <= 0 || idx == len(pair)-1 {
+			return nil, fmt.Errorf("invalid role mapping entry %q, expected group=Role", pair)
+		}
+
+		mapping = append(mapping, GroupRole{
+			Group: strings.TrimSpace(pair[:idx]),
+			Role:  strings.TrimSpace(pair[idx+1:]),
+		})
+	}
+
+	return mapping, nil
+}
+
+// Match returns the role for the first group of the mapping which the user belongs to
+func (m RoleMapping) Match(groups []string) (string, bool) {
+	fallback, hasFallback := "", false
+
+	for _, entry := range m {
+		if entry.Group == RoleMappingFallback {
+			if !hasFallback {
+				fallback, hasFallback = entry.Role, true
+			}
+			continue
+		}
+
+		for _, group := range groups {
+			if strings.EqualFold(group, entry.Group) {
+				return entry.Role, true
+			}
+		}
+	}
+
+	return fallback, hasFallback
+}
@@ -0,0 +1,46 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoleMapping(t *testing.T) {
+	mapping, err := ParseRoleMapping(" admins = Admin ; cn=ops,dc=corp=User;*=User ")
+	require.NoError(t, err)
+	assert.Equal(t, RoleMapping{
+		{Group: "admins", Role: "Admin"},
+		{Group: "cn=ops,dc=corp", Role: "User"},
+		{Group: RoleMappingFallback, Role: "User"},
+	}, mapping)
+
+	mapping, err = ParseRoleMapping("")
+	require.NoError(t, err)
+	assert.Empty(t, mapping)
+
+	for _, value := range []string{"admins", "=Admin", "admins="} {
+		_, err = ParseRoleMapping(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestRoleMapping_Match(t *testing.T) {
+	mapping := RoleMapping{
+		{Group: RoleMappingFallback, Role: "User"},
+		{Group: "admins", Role: "Admin"},
+		{Group: "pentesters", Role: "User"},
+	}
+
+	role, ok := mapping.Match([]string{"pentesters", "Admins"})
+	assert.True(t, ok)
+	assert.Equal(t, "Admin", role)
+
+	role, ok = mapping.Match([]string{"unknown"})
+	assert.True(t, ok)
+	assert.Equal(t, "User", role)
+
+	_, ok = mapping[1:].Match([]string{"unknown"})
+	assert.False(t, ok)
+}
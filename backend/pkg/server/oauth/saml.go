@@ -0,0 +1,255 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+const SAMLProviderName = "saml"
+
+var (
+	samlEmailAttributes = []string{
+		"email",
+		"mail",
+		"urn:oid:0.9.2342.19200300.100.1.3",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+	}
+	samlNameAttributes = []string{
+		"displayName",
+		"name",
+		"urn:oid:2.16.840.1.113730.3.1.241",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name",
+	}
+	samlGroupsAttributes = []string{
+		"groups",
+		"memberOf",
+		"urn:oid:1.3.6.1.4.1.5923.1.5.1.1",
+		"http://schemas.microsoft.com/ws/2008/06/identity/claims/groups",
+	}
+)
+
+// SAMLConfig describes the SAML 2.0 service provider and its identity provider
+type SAMLConfig struct {
+	IDPMetadataURL  string
+	IDPMetadataFile string
+	EntityID        string
+	MetadataURL     string
+	AcsURL          string
+	// optional key pair to sign authentication requests and decrypt assertions
+	CertFile string
+	KeyFile  string
+	// attribute names to read the user from, common names are used when empty
+	EmailAttribute  string
+	NameAttribute   string
+	GroupsAttribute string
+}
+
+type SAMLClient interface {
+	ProviderName() string
+	// AuthURL returns the IdP redirect URL and the ID of the authentication request
+	AuthURL(relayState string) (string, string, error)
+	// ResolveUser validates the SAMLResponse posted to the ACS endpoint
+	ResolveUser(r *http.Request, requestID string) (OAuthUser, error)
+	Metadata() ([]byte, error)
+}
+
+type samlClient struct {
+	sp  *saml.ServiceProvider
+	cfg SAMLConfig
+}
+
+func NewSAMLClient(ctx context.Context, cfg SAMLConfig) (SAMLClient, error) {
+	acsURL, err := url.Parse(cfg.AcsURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAML ACS URL: %w", err)
+	}
+
+	metadataURL, err := url.Parse(cfg.MetadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAML metadata URL: %w", err)
+	}
+
+	idpMetadata, err := loadIDPMetadata(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &saml.ServiceProvider{
+		EntityID:          cfg.EntityID,
+		AcsURL:            *acsURL,
+		MetadataURL:       *metadataURL,
+		IDPMetadata:       idpMetadata,
+		AuthnNameIDFormat: saml.UnspecifiedNameIDFormat,
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		keyPair, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load SAML SP key pair: %w", err)
+		}
+
+		signer, ok := keyPair.PrivateKey.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("SAML SP private key is not a signer")
+		}
+
+		cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SAML SP certificate: %w", err)
+		}
+
+		sp.Key = signer
+		sp.Certificate = cert
+		sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+		if _, ok := signer.(*ecdsa.PrivateKey); ok {
+			sp.SignatureMethod = dsig.ECDSASHA256SignatureMethod
+		}
+	}
+
+	if sp.GetSSOBindingLocation(saml.HTTPRedirectBinding) == "" {
+		return nil, fmt.Errorf("SAML IdP metadata has no HTTP-Redirect single sign-on service")
+	}
+
+	return &samlClient{sp: sp, cfg: cfg}, nil
+}
+
+func loadIDPMetadata(ctx context.Context, cfg SAMLConfig) (*saml.EntityDescriptor, error) {
+	switch {
+	case cfg.IDPMetadataFile != "":
+		data, err := os.ReadFile(cfg.IDPMetadataFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SAML IdP metadata: %w", err)
+		}
+		metadata, err := samlsp.ParseMetadata(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SAML IdP metadata: %w", err)
+		}
+		return metadata, nil
+	case cfg.IDPMetadataURL != "":
+		metadataURL, err := url.Parse(cfg.IDPMetadataURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SAML IdP metadata URL: %w", err)
+		}
+		metadata, err := samlsp.FetchMetadata(ctx, http.DefaultClient, *metadataURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch SAML IdP metadata: %w", err)
+		}
+		return metadata, nil
+	default:
+		return nil, fmt.Errorf("SAML IdP metadata URL or file is required")
+	}
+}
+
+func (s *samlClient) ProviderName() string {
+	return SAMLProviderName
+}
+
+func (s *samlClient) AuthURL(relayState string) (string, string, error) {
+	req, err := s.sp.MakeAuthenticationRequest(
+		s.sp.GetSSOBindingLocation(saml.HTTPRedirectBinding),
+		saml.HTTPRedirectBinding,
+		saml.HTTPPostBinding,
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to make SAML authentication request: %w", err)
+	}
+
+	// relay state is appended to the query as is
+	redirectURL, err := req.Redirect(url.QueryEscape(relayState), s.sp)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to make SAML redirect URL: %w", err)
+	}
+
+	return redirectURL.String(), req.ID, nil
+}
+
+func (s *samlClient) ResolveUser(r *http.Request, requestID string) (OAuthUser, error) {
+	if err := r.ParseForm(); err != nil {
+		return OAuthUser{}, fmt.Errorf("failed to parse SAML response form: %w", err)
+	}
+
+	assertion, err := s.sp.ParseResponse(r, []string{requestID})
+	if err != nil {
+		if ire, ok := err.(*saml.InvalidResponseError); ok && ire.PrivateErr != nil {
+			err = ire.PrivateErr
+		}
+		return OAuthUser{}, fmt.Errorf("invalid SAML response: %w", err)
+	}
+
+	return mapSAMLAssertion(assertion, s.cfg), nil
+}
+
+func (s *samlClient) Metadata() ([]byte, error) {
+	data, err := xml.MarshalIndent(s.sp.Metadata(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SAML SP metadata: %w", err)
+	}
+
+	return data, nil
+}
+
+func mapSAMLAssertion(assertion *saml.Assertion, cfg SAMLConfig) OAuthUser {
+	attributes := map[string][]string{}
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			values := make([]string, 0, len(attr.Values))
+			for _, value := range attr.Values {
+				if value.Value != "" {
+					values = append(values, value.Value)
+				}
+			}
+			attributes[attr.Name] = append(attributes[attr.Name], values...)
+			if attr.FriendlyName != "" && attr.FriendlyName != attr.Name {
+				attributes[attr.FriendlyName] = append(attributes[attr.FriendlyName], values...)
+			}
+		}
+	}
+
+	lookup := func(name string, fallbacks []string) []string {
+		if name != "" {
+			return attributes[name]
+		}
+		for _, fallback := range fallbacks {
+			if values := attributes[fallback]; len(values) != 0 {
+				return values
+			}
+		}
+		return nil
+	}
+
+	// the IdP signed the assertion, so the asserted email is trusted
+	user := OAuthUser{Verified: true}
+	if values := lookup(cfg.EmailAttribute, samlEmailAttributes); len(values) != 0 {
+		user.Email = values[0]
+	} else if nameID := assertionNameID(assertion); nameID != nil &&
+		(nameID.Format == string(saml.EmailAddressNameIDFormat) || strings.Contains(nameID.Value, "@")) {
+		user.Email = nameID.Value
+	}
+	if values := lookup(cfg.NameAttribute, samlNameAttributes); len(values) != 0 {
+		user.Name = values[0]
+	}
+	user.Groups = lookup(cfg.GroupsAttribute, samlGroupsAttributes)
+
+	return user
+}
+
+func assertionNameID(assertion *saml.Assertion) *saml.NameID {
+	if assertion.Subject == nil {
+		return nil
+	}
+
+	return assertion.Subject.NameID
+}
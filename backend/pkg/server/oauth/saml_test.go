@@ -0,0 +1,196 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/xml"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func samlAttribute(name, friendlyName string, values ...string) saml.Attribute {
+	attr := saml.Attribute{Name: name, FriendlyName: friendlyName}
+	for _, value := range values {
+		attr.Values = append(attr.Values, saml.AttributeValue{Value: value})
+	}
+	return attr
+}
+
+func TestMapSAMLAssertion(t *testing.T) {
+	assertion := &saml.Assertion{
+		AttributeStatements: []saml.AttributeStatement{{
+			Attributes: []saml.Attribute{
+				samlAttribute("urn:oid:0.9.2342.19200300.100.1.3", "mail", "alice@example.com"),
+				samlAttribute("displayName", "", "Alice"),
+				samlAttribute("memberOf", "", "admins", "", "pentesters"),
+				samlAttribute("department", "", "red team"),
+			},
+		}},
+	}
+
+	user := mapSAMLAssertion(assertion, SAMLConfig{})
+	assert.Equal(t, OAuthUser{
+		Email:    "alice@example.com",
+		Verified: true,
+		Name:     "Alice",
+		Groups:   []string{"admins", "pentesters"},
+	}, user)
+
+	user = mapSAMLAssertion(assertion, SAMLConfig{GroupsAttribute: "department"})
+	assert.Equal(t, []string{"red team"}, user.Groups)
+}
+
+func TestMapSAMLAssertion_NameID(t *testing.T) {
+	assertion := &saml.Assertion{
+		Subject: &saml.Subject{NameID: &saml.NameID{
+			Format: string(saml.EmailAddressNameIDFormat),
+			Value:  "bob@example.com",
+		}},
+	}
+
+	user := mapSAMLAssertion(assertion, SAMLConfig{})
+	assert.Equal(t, "bob@example.com", user.Email)
+	assert.Empty(t, user.Groups)
+
+	assertion.Subject.NameID = &saml.NameID{Format: string(saml.PersistentNameIDFormat), Value: "opaque-id"}
+	assert.Empty(t, mapSAMLAssertion(assertion, SAMLConfig{}).Email)
+}
+
+const (
+	testSAMLEntityID = "https://pentagi.example.com/api/v1/auth/saml/metadata"
+	testSAMLAcsURL   = "https://pentagi.example.com/api/v1/auth/saml/acs"
+)
+
+// newTestSAMLIdP returns an identity provider signing assertions with a fresh key and
+// the service provider client trusting it through the IdP metadata file
+func newTestSAMLIdP(t *testing.T) (*saml.IdentityProvider, *samlClient) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	idp := &saml.IdentityProvider{
+		Key:         key,
+		Certificate: cert,
+		MetadataURL: url.URL{Scheme: "https", Host: "idp.example.com", Path: "/metadata"},
+		SSOURL:      url.URL{Scheme: "https", Host: "idp.example.com", Path: "/sso"},
+	}
+
+	metadata, err := xml.Marshal(idp.Metadata())
+	require.NoError(t, err)
+	metadataFile := filepath.Join(t.TempDir(), "idp.xml")
+	require.NoError(t, os.WriteFile(metadataFile, metadata, 0o600))
+
+	client, err := NewSAMLClient(t.Context(), SAMLConfig{
+		IDPMetadataFile: metadataFile,
+		EntityID:        testSAMLEntityID,
+		MetadataURL:     testSAMLEntityID,
+		AcsURL:          testSAMLAcsURL,
+		GroupsAttribute: "eduPersonAffiliation",
+	})
+	require.NoError(t, err)
+
+	return idp, client.(*samlClient)
+}
+
+// postSAMLResponse makes the IdP answer the authentication request and posts the
+// signed response to the ACS endpoint of the client
+func postSAMLResponse(
+	t *testing.T,
+	idp *saml.IdentityProvider,
+	client *samlClient,
+	requestID string,
+	modify func(*saml.IdpAuthnRequest),
+) (OAuthUser, error) {
+	t.Helper()
+
+	spMetadata := client.sp.Metadata()
+	req := &saml.IdpAuthnRequest{
+		IDP:         idp,
+		HTTPRequest: httptest.NewRequest(http.MethodGet, "https://idp.example.com/sso", nil),
+		Request: saml.AuthnRequest{
+			ID:                          requestID,
+			AssertionConsumerServiceURL: testSAMLAcsURL,
+		},
+		ServiceProviderMetadata: spMetadata,
+		SPSSODescriptor:         &spMetadata.SPSSODescriptors[0],
+		ACSEndpoint:             &saml.IndexedEndpoint{Binding: saml.HTTPPostBinding, Location: testSAMLAcsURL},
+		Now:                     saml.TimeNow(),
+	}
+	if modify != nil {
+		modify(req)
+	}
+
+	session := &saml.Session{
+		ID:        "session",
+		NameID:    "alice@example.com",
+		UserEmail: "alice@example.com",
+		Groups:    []string{"pentesters"},
+	}
+	require.NoError(t, saml.DefaultAssertionMaker{}.MakeAssertion(req, session))
+	form, err := req.PostBinding()
+	require.NoError(t, err)
+
+	body := url.Values{"SAMLResponse": {form.SAMLResponse}}.Encode()
+	acs := httptest.NewRequest(http.MethodPost, testSAMLAcsURL, strings.NewReader(body))
+	acs.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return client.ResolveUser(acs, requestID)
+}
+
+func TestSAMLClientResolveUser(t *testing.T) {
+	idp, client := newTestSAMLIdP(t)
+
+	authURL, requestID, err := client.AuthURL("state")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(authURL, "https://idp.example.com/sso?"))
+
+	user, err := postSAMLResponse(t, idp, client, requestID, nil)
+	require.NoError(t, err, "a signed assertion for the request is accepted")
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, []string{"pentesters"}, user.Groups)
+
+	_, err = postSAMLResponse(t, idp, client, requestID, func(req *saml.IdpAuthnRequest) {
+		metadata := *req.ServiceProviderMetadata
+		metadata.EntityID = "https://other.example.com/metadata"
+		req.ServiceProviderMetadata = &metadata
+	})
+	require.ErrorContains(t, err, "AudienceRestriction", "an assertion for another service provider is rejected")
+
+	_, err = postSAMLResponse(t, idp, client, requestID, func(req *saml.IdpAuthnRequest) {
+		req.Now = saml.TimeNow().Add(-time.Hour)
+	})
+	require.Error(t, err, "an expired assertion is rejected")
+
+	_, err = postSAMLResponse(t, idp, client, requestID, func(req *saml.IdpAuthnRequest) {
+		req.Request.ID = "id-other"
+	})
+	require.Error(t, err, "a response to another request is rejected")
+
+	other, _ := newTestSAMLIdP(t)
+	_, err = postSAMLResponse(t, other, client, requestID, nil)
+	require.Error(t, err, "an assertion signed by an untrusted key is rejected")
+}
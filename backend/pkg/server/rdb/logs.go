@@ -0,0 +1,98 @@
+package rdb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// LogColumns is auxiliary struct to describe the columns of a log table used by LogQuery
+type LogColumns struct {
+	// Kind is the SQL expression matched by the types filter
+	Kind string
+	// Document is the full-text document, it must match the GIN index of the table
+	Document string
+	// Tasks is false for the logs which belong to no task
+	Tasks bool
+}
+
+// LogQuery is TableQuery of the log tables extended by keyset pagination and
+// the filters of the flow logs
+//
+//nolint:lll
+type LogQuery struct {
+	TableQuery
+	// Logs with a greater id in ascending order, the cursor is the newest log of the previous page
+	After int64 `form:"after" json:"after,omitempty" binding:"min=0" minimum:"0"`
+	// Logs with a smaller id in the default descending order, the cursor is the oldest log of the previous page
+	Before int64 `form:"before" json:"before,omitempty" binding:"min=0" minimum:"0"`
+	// Logs created at or after the time in RFC 3339 format
+	Since *time.Time `form:"since" json:"since,omitempty" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Logs created before the time in RFC 3339 format
+	Until *time.Time `form:"until" json:"until,omitempty" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Logs of the task, ignored by the logs which belong to no task
+	TaskID uint64 `form:"task_id" json:"task_id,omitempty"`
+	// Logs of the subtask, ignored by the logs which belong to no task
+	SubtaskID uint64 `form:"subtask_id" json:"subtask_id,omitempty"`
+	// Log types, executor agents or tool names depending on the endpoint
+	Types []string `form:"types[]" json:"types[],omitempty" binding:"omitempty,dive,required"`
+	// Full-text search with the web search syntax: "quoted phrases", or, -excluded
+	Search string `form:"search" json:"search,omitempty" binding:"omitempty,max=1000"`
+}
+
+// Init is function to set table name, sql mapping to data columns and the log filters
+func (q *LogQuery) Init(table string, sqlMappers map[string]any, columns LogColumns) error {
+	if q.After != 0 && q.Before != 0 {
+		return errors.New("after and before can't be used together")
+	}
+
+	if err := q.TableQuery.Init(table, sqlMappers); err != nil {
+		return err
+	}
+
+	filters := []func(*gorm.DB) *gorm.DB{}
+	where := func(cond string, args ...any) {
+		filters = append(filters, func(db *gorm.DB) *gorm.DB {
+			return db.Where(q.DoConditionFormat(cond), args...)
+		})
+	}
+
+	if q.After != 0 || q.Before != 0 {
+		// the cursor replaces the offset, the page size is still used as the limit
+		q.Page = 1
+	}
+	if q.After != 0 {
+		where("{{table}}.id > ?", q.After)
+		q.sqlOrders = []func(*gorm.DB) *gorm.DB{
+			func(db *gorm.DB) *gorm.DB {
+				return db.Order("id ASC")
+			},
+		}
+	}
+	if q.Before != 0 {
+		where("{{table}}.id < ?", q.Before)
+	}
+	if q.Since != nil {
+		where("{{table}}.created_at >= ?", *q.Since)
+	}
+	if q.Until != nil {
+		where("{{table}}.created_at < ?", *q.Until)
+	}
+	if columns.Tasks && q.TaskID != 0 {
+		where("{{table}}.task_id = ?", q.TaskID)
+	}
+	if columns.Tasks && q.SubtaskID != 0 {
+		where("{{table}}.subtask_id = ?", q.SubtaskID)
+	}
+	if len(q.Types) != 0 {
+		where(columns.Kind+" IN (?)", q.Types)
+	}
+	if q.Search != "" {
+		where(columns.Document+" @@ websearch_to_tsquery('english', ?)", q.Search)
+	}
+
+	q.sqlFilters = append(q.sqlFilters, filters...)
+
+	return nil
+}
@@ -58,6 +58,15 @@ var ErrDeleteUserModelsNotFound = NewHttpError(404, "Users.DeleteUser.ModelsNotF
 var ErrRolesInvalidRequest = NewHttpError(400, "Roles.InvalidRequest", "invalid role request data")
 var ErrRolesInvalidData = NewHttpError(500, "Roles.InvalidData", "invalid role data")
 var ErrRolesNotFound = NewHttpError(404, "Roles.NotFound", "role not found")
+var ErrRolesInvalidPrivileges = NewHttpError(400, "Roles.InvalidPrivileges", "unknown privileges in role")
+var ErrRolesNameExists = NewHttpError(409, "Roles.NameExists", "role name already exists")
+var ErrRolesBuiltIn = NewHttpError(403, "Roles.BuiltIn", "built-in role can't be changed")
+var ErrRolesInUse = NewHttpError(409, "Roles.InUse", "role is assigned to users or tokens")
+
+// audit
+
+var ErrAuditInvalidRequest = NewHttpError(400, "Audit.InvalidRequest", "invalid audit request data")
+var ErrAuditInvalidData = NewHttpError(500, "Audit.InvalidData", "invalid audit event data")
 
 // prompts
 
@@ -105,6 +105,14 @@ func TestPredefinedErrors(t *testing.T) {
 		{"ErrRolesInvalidRequest", ErrRolesInvalidRequest, 400, "Roles.InvalidRequest"},
 		{"ErrRolesInvalidData", ErrRolesInvalidData, 500, "Roles.InvalidData"},
 		{"ErrRolesNotFound", ErrRolesNotFound, 404, "Roles.NotFound"},
+		{"ErrRolesInvalidPrivileges", ErrRolesInvalidPrivileges, 400, "Roles.InvalidPrivileges"},
+		{"ErrRolesNameExists", ErrRolesNameExists, 409, "Roles.NameExists"},
+		{"ErrRolesBuiltIn", ErrRolesBuiltIn, 403, "Roles.BuiltIn"},
+		{"ErrRolesInUse", ErrRolesInUse, 409, "Roles.InUse"},
+
+		// Audit errors
+		{"ErrAuditInvalidRequest", ErrAuditInvalidRequest, 400, "Audit.InvalidRequest"},
+		{"ErrAuditInvalidData", ErrAuditInvalidData, 500, "Audit.InvalidData"},
 
 		// Prompts errors
 		{"ErrPromptsInvalidRequest", ErrPromptsInvalidRequest, 400, "Prompts.InvalidRequest"},
@@ -14,17 +14,21 @@ import (
 	"strings"
 	"time"
 
+	"pentagi/pkg/attackgraph"
 	"pentagi/pkg/config"
 	"pentagi/pkg/controller"
 	"pentagi/pkg/database"
 	"pentagi/pkg/database/knowledge"
+	"pentagi/pkg/database/knowledge/ingest"
 	"pentagi/pkg/docker"
 	"pentagi/pkg/graph/subscriptions"
 	"pentagi/pkg/providers"
+	"pentagi/pkg/server/audit"
 	"pentagi/pkg/server/auth"
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/server/oauth"
 	"pentagi/pkg/server/services"
+	"pentagi/pkg/webhooks"
 
 	_ "pentagi/pkg/server/docs" // swagger docs
 
@@ -88,6 +92,7 @@ func NewRouter(
 	controller controller.FlowController,
 	subscriptions subscriptions.SubscriptionsController,
 	dockerClient docker.DockerClient,
+	dispatcher *webhooks.Dispatcher,
 ) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	if cfg.Debug {
@@ -125,6 +130,64 @@ func NewRouter(
 		oauthClients[githubClient.ProviderName()] = githubClient
 	}
 
+	roleMappings := make(map[string]oauth.RoleMapping)
+	providerCtx, providerCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer providerCancel()
+
+	if publicURL != nil && cfg.OAuthOIDCIssuerURL != "" && cfg.OAuthOIDCClientID != "" {
+		oidcClient, err := oauth.NewOIDCOAuthClient(providerCtx, oauth.OIDCConfig{
+			Name:         cfg.OAuthOIDCName,
+			IssuerURL:    cfg.OAuthOIDCIssuerURL,
+			ClientID:     cfg.OAuthOIDCClientID,
+			ClientSecret: cfg.OAuthOIDCClientSecret,
+			RedirectURL:  publicURL.String(),
+			Scopes:       cfg.OAuthOIDCScopes,
+			EmailClaim:   cfg.OAuthOIDCEmailClaim,
+			NameClaim:    cfg.OAuthOIDCNameClaim,
+			GroupsClaim:  cfg.OAuthOIDCGroupsClaim,
+			TrustEmail:   cfg.OAuthOIDCTrustEmail,
+		})
+		if err != nil {
+			logrus.WithError(err).Error("failed to initialize OpenID Connect provider; it will be unavailable")
+		} else if mapping, err := oauth.ParseRoleMapping(cfg.OAuthOIDCRoleMapping); err != nil {
+			logrus.WithError(err).Error("invalid OpenID Connect role mapping; the provider will be unavailable")
+		} else {
+			oauthClients[oidcClient.ProviderName()] = oidcClient
+			roleMappings[oidcClient.ProviderName()] = mapping
+		}
+	}
+
+	var samlClient oauth.SAMLClient
+	if publicURL != nil && (cfg.SAMLIDPMetadataURL != "" || cfg.SAMLIDPMetadataFile != "") {
+		metadataURL := *publicURL
+		metadataURL.Path = path.Join(baseURL, "/auth/saml/metadata")
+		entityID := cfg.SAMLSPEntityID
+		if entityID == "" {
+			entityID = metadataURL.String()
+		}
+
+		client, err := oauth.NewSAMLClient(providerCtx, oauth.SAMLConfig{
+			IDPMetadataURL:  cfg.SAMLIDPMetadataURL,
+			IDPMetadataFile: cfg.SAMLIDPMetadataFile,
+			EntityID:        entityID,
+			MetadataURL:     metadataURL.String(),
+			AcsURL:          publicURL.String(),
+			CertFile:        cfg.SAMLSPCertFile,
+			KeyFile:         cfg.SAMLSPKeyFile,
+			EmailAttribute:  cfg.SAMLEmailAttribute,
+			NameAttribute:   cfg.SAMLNameAttribute,
+			GroupsAttribute: cfg.SAMLGroupsAttribute,
+		})
+		if err != nil {
+			logrus.WithError(err).Error("failed to initialize SAML service provider; it will be unavailable")
+		} else if mapping, err := oauth.ParseRoleMapping(cfg.SAMLRoleMapping); err != nil {
+			logrus.WithError(err).Error("invalid SAML role mapping; the provider will be unavailable")
+		} else {
+			samlClient = client
+			roleMappings[client.ProviderName()] = mapping
+		}
+	}
+
 	// ---- Knowledge (pgvector) store -----------------------------------------
 	// Shared by both the GraphQL and REST layers.
 	// Store and embedder are nil when no embedding provider is configured;
@@ -148,7 +211,15 @@ func NewRouter(
 		}
 	}
 	var knowledgeStore knowledge.KnowledgeStore
-	knowledgeStore = knowledge.NewKnowledgeStore(db, pgStore, embedder, subscriptions.NewKnowledgePublisher, cfg.EmbeddingMaxTextBytes)
+	knowledgeStore = knowledge.NewKnowledgeStore(db, pgStore, embedder, subscriptions.NewKnowledgePublisher, cfg.EmbeddingMaxTextBytes, database.NewHybridSearchConfig(cfg), knowledge.NewReembedConfig(cfg))
+	if err := knowledgeStore.FailInterruptedImports(context.Background()); err != nil {
+		logrus.WithError(err).Warn("failed to mark interrupted knowledge imports as failed")
+	}
+	if embedder.IsAvailable() {
+		if _, err := knowledgeStore.CheckEmbeddingModel(context.Background()); err != nil {
+			logrus.WithError(err).Warn("failed to check the embedding model of stored knowledge")
+		}
+	}
 
 	// ---- Anonymizer replacer ------------------------------------------------
 	// Shared singleton used by the GraphQL anonymizeText mutation.
@@ -166,6 +237,22 @@ func NewRouter(
 		}
 	}
 
+	// ---- Audit log -----------------------------------------------------------
+	// Every state changing REST request and GraphQL mutation is appended to the hash chain;
+	// the recorder is nil when the audit log is disabled and then records nothing.
+	var auditRecorder *audit.Recorder
+	if cfg.AuditEnabled {
+		var forwarder *audit.SyslogForwarder
+		if cfg.AuditSyslogURL != "" {
+			if f, err := audit.NewSyslogForwarder(cfg.AuditSyslogURL); err != nil {
+				logrus.WithError(err).Error("failed to create syslog forwarder; audit events will be stored only")
+			} else {
+				forwarder = f
+			}
+		}
+		auditRecorder = audit.NewRecorder(orm, cfg.AuditMaxDiffBytes, forwarder)
+	}
+
 	// services
 	authService := services.NewAuthService(
 		services.AuthServiceConfig{
@@ -173,15 +260,19 @@ func NewRouter(
 			LoginCallbackURL: oauthLoginCallbackURL,
 			SessionTimeout:   4 * 60 * 60, // 4 hours
 			CookiePrefix:     cfg.TenantPrefix(),
+			RoleMappings:     roleMappings,
 		},
 		orm,
 		oauthClients,
+		samlClient,
 	)
 	userService := services.NewUserService(orm, userCache)
-	roleService := services.NewRoleService(orm)
+	roleService := services.NewRoleService(orm, userCache, tokenCache)
 	providerService := services.NewProviderService(providers)
 	settingsService := services.NewSettingsService(cfg)
-	flowService := services.NewFlowService(orm, providers, controller, subscriptions)
+	flowService := services.NewFlowService(
+		orm, attackgraph.NewLoader(db, cfg, providers.GraphitiClient()), providers, controller, subscriptions,
+	)
 	flowFileService := services.NewFlowFileService(orm, cfg.DataDir, cfg.TenantPrefix(), dockerClient, subscriptions)
 	resourceService := services.NewResourceService(orm, cfg.DataDir, subscriptions)
 	taskService := services.NewTaskService(orm)
@@ -199,10 +290,18 @@ func NewRouter(
 	promptService := services.NewPromptService(orm)
 	analyticsService := services.NewAnalyticsService(orm)
 	tokenService := services.NewTokenService(orm, cfg.AuthSalt(), tokenCache, subscriptions)
-	knowledgeService := services.NewKnowledgeService(orm, knowledgeStore)
+	knowledgeService := services.NewKnowledgeService(orm, knowledgeStore, knowledge.Instance{
+		InstallationID: cfg.InstallationID,
+		TenantID:       cfg.TenantID,
+	}, cfg.KnowledgeImportMaxBytes, ingest.Options{
+		ChunkSize:    cfg.KnowledgeImportChunkSize,
+		ChunkOverlap: cfg.KnowledgeImportChunkOverlap,
+	})
 	anonymizerService := services.NewAnonymizerService(textReplacer)
+	auditService := services.NewAuditService(orm)
 	graphqlService := services.NewGraphqlService(
-		db, cfg, baseURL, cfg.CorsOrigins, tokenCache, providers, controller, subscriptions, knowledgeStore, textReplacer,
+		db, database.NewTxRunner(orm.DB()), cfg, baseURL, cfg.CorsOrigins, tokenCache, userCache, providers, controller, subscriptions, knowledgeStore, textReplacer,
+		auditRecorder, dispatcher,
 	)
 
 	router := gin.Default()
@@ -249,6 +348,8 @@ func NewRouter(
 
 	api := router.Group(baseURL)
 	api.Use(noCacheMiddleware())
+	// GraphQL mutations are recorded by the GraphQL extension with their own names
+	api.Use(auditRecorder.Middleware(baseURL, baseURL+"/graphql"))
 
 	// Special case for local user own password change
 	changePasswordGroup := api.Group("/user")
@@ -281,6 +382,7 @@ func NewRouter(
 			authGroup.GET("/login-callback", authService.AuthLoginGetCallback)
 			authGroup.POST("/login-callback", authService.AuthLoginPostCallback)
 			authGroup.POST("/logout-callback", authService.AuthLogoutCallback)
+			authGroup.GET("/saml/metadata", authService.AuthSAMLMetadata)
 		}
 	}
 
@@ -318,6 +420,7 @@ func NewRouter(
 		setRolesGroup(privateGroup, roleService)
 		setUsersGroup(privateGroup, userService)
 		setTokensGroup(privateGroup, tokenService)
+		setAuditGroup(privateGroup, auditService)
 	}
 
 	if cfg.StaticURL != nil && cfg.StaticURL.Scheme != "" && cfg.StaticURL.Host != "" {
@@ -404,8 +507,13 @@ func setKnowledgeGroup(parent *gin.RouterGroup, svc *services.KnowledgeService)
 	kg := parent.Group("/knowledge")
 	{
 		kg.GET("/", svc.ListDocuments)
+		kg.GET("/export", svc.ExportDocuments)
+		kg.GET("/import/", svc.ListImportJobs)
+		kg.GET("/import/:id", svc.GetImportJob)
 		kg.GET("/:id", svc.GetDocument)
 		kg.POST("/", svc.CreateDocument)
+		kg.POST("/import", svc.ImportDocuments)
+		kg.POST("/import/archive", svc.ImportArchive)
 		kg.POST("/search", svc.SearchDocuments)
 		kg.PUT("/:id", svc.UpdateDocument)
 		kg.DELETE("/:id", svc.DeleteDocument)
@@ -476,6 +584,7 @@ func setFlowsGroup(parent *gin.RouterGroup, svc *services.FlowService) {
 		flowsViewGroup.GET("/", svc.GetFlows)
 		flowsViewGroup.GET("/:flowID", svc.GetFlow)
 		flowsViewGroup.GET("/:flowID/graph", svc.GetFlowGraph)
+		flowsViewGroup.GET("/:flowID/attack-graph", svc.GetFlowAttackGraph)
 	}
 }
 
@@ -667,8 +776,25 @@ func setRolesGroup(parent *gin.RouterGroup, svc *services.RoleService) {
 	rolesViewGroup := parent.Group("/roles")
 	{
 		rolesViewGroup.GET("/", svc.GetRoles)
+		rolesViewGroup.GET("/privileges", svc.GetPrivileges)
 		rolesViewGroup.GET("/:roleID", svc.GetRole)
 	}
+
+	rolesEditGroup := parent.Group("/roles")
+	{
+		rolesEditGroup.POST("/", svc.CreateRole)
+		rolesEditGroup.PUT("/:roleID", svc.UpdateRole)
+		rolesEditGroup.DELETE("/:roleID", svc.DeleteRole)
+	}
+}
+
+func setAuditGroup(parent *gin.RouterGroup, svc *services.AuditService) {
+	auditGroup := parent.Group("/audit")
+	{
+		auditGroup.GET("/", svc.GetAuditEvents)
+		auditGroup.GET("/export", svc.ExportAuditEvents)
+		auditGroup.GET("/verify", svc.VerifyAuditLog)
+	}
 }
 
 func setUsersGroup(parent *gin.RouterGroup, svc *services.UserService) {
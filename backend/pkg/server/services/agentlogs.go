@@ -38,6 +38,12 @@ var agentlogsSQLMappers = map[string]any{
 	"data":       "({{table}}.task || ' ' || {{table}}.result)",
 }
 
+var agentlogsColumns = rdb.LogColumns{
+	Kind:     "agentlogs.executor::text",
+	Document: "search_document(agentlogs.task, agentlogs.result)",
+	Tasks:    true,
+}
+
 type AgentlogService struct {
 	db *gorm.DB
 }
@@ -53,7 +59,7 @@ func NewAgentlogService(db *gorm.DB) *AgentlogService {
 // @Tags Agentlogs
 // @Produce json
 // @Security BearerAuth
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=agentlogs} "agentlogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting agentlogs not permitted"
@@ -62,7 +68,7 @@ func NewAgentlogService(db *gorm.DB) *AgentlogService {
 func (s *AgentlogService) GetAgentlogs(c *gin.Context) {
 	var (
 		err   error
-		query rdb.TableQuery
+		query rdb.LogQuery
 		resp  agentlogs
 	)
 
@@ -84,7 +90,7 @@ func (s *AgentlogService) GetAgentlogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "agentlogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -92,7 +98,11 @@ func (s *AgentlogService) GetAgentlogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("agentlogs", agentlogsSQLMappers)
+	if err = query.Init("agentlogs", agentlogsSQLMappers, agentlogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrAgentlogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := agentlogsSQLMappers[query.Group]; !ok {
@@ -135,7 +145,7 @@ func (s *AgentlogService) GetAgentlogs(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param flowID path int true "flow id" minimum(0)
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=agentlogs} "agentlogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting agentlogs not permitted"
@@ -145,7 +155,7 @@ func (s *AgentlogService) GetFlowAgentlogs(c *gin.Context) {
 	var (
 		err    error
 		flowID uint64
-		query  rdb.TableQuery
+		query  rdb.LogQuery
 		resp   agentlogs
 	)
 
@@ -174,7 +184,7 @@ func (s *AgentlogService) GetFlowAgentlogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "agentlogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -182,7 +192,11 @@ func (s *AgentlogService) GetFlowAgentlogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("agentlogs", agentlogsSQLMappers)
+	if err = query.Init("agentlogs", agentlogsSQLMappers, agentlogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrAgentlogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := agentlogsSQLMappers[query.Group]; !ok {
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"pentagi/pkg/providers/pconfig"
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/server/models"
 	"pentagi/pkg/server/response"
@@ -86,6 +87,7 @@ func (s *AnalyticsService) GetSystemUsage(c *gin.Context) {
 		TotalUsageCacheOut: int(usageStats.TotalUsageCacheOut),
 		TotalUsageCostIn:   usageStats.TotalUsageCostIn,
 		TotalUsageCostOut:  usageStats.TotalUsageCostOut,
+		CacheHitRatio:      pconfig.CacheHitRatio(usageStats.TotalUsageIn, usageStats.TotalUsageCacheIn),
 	}
 
 	// 2. Get total toolcalls stats
@@ -198,6 +200,7 @@ func (s *AnalyticsService) GetSystemUsage(c *gin.Context) {
 				TotalUsageCacheOut: int(stat.TotalUsageCacheOut),
 				TotalUsageCostIn:   stat.TotalUsageCostIn,
 				TotalUsageCostOut:  stat.TotalUsageCostOut,
+				CacheHitRatio:      pconfig.CacheHitRatio(stat.TotalUsageIn, stat.TotalUsageCacheIn),
 			},
 		})
 	}
@@ -251,6 +254,7 @@ func (s *AnalyticsService) GetSystemUsage(c *gin.Context) {
 				TotalUsageCacheOut: int(stat.TotalUsageCacheOut),
 				TotalUsageCostIn:   stat.TotalUsageCostIn,
 				TotalUsageCostOut:  stat.TotalUsageCostOut,
+				CacheHitRatio:      pconfig.CacheHitRatio(stat.TotalUsageIn, stat.TotalUsageCacheIn),
 			},
 		})
 	}
@@ -301,6 +305,7 @@ func (s *AnalyticsService) GetSystemUsage(c *gin.Context) {
 				TotalUsageCacheOut: int(stat.TotalUsageCacheOut),
 				TotalUsageCostIn:   stat.TotalUsageCostIn,
 				TotalUsageCostOut:  stat.TotalUsageCostOut,
+				CacheHitRatio:      pconfig.CacheHitRatio(stat.TotalUsageIn, stat.TotalUsageCacheIn),
 			},
 		})
 	}
@@ -448,6 +453,7 @@ func (s *AnalyticsService) GetPeriodUsage(c *gin.Context) {
 				TotalUsageCacheOut: int(stat.TotalUsageCacheOut),
 				TotalUsageCostIn:   stat.TotalUsageCostIn,
 				TotalUsageCostOut:  stat.TotalUsageCostOut,
+				CacheHitRatio:      pconfig.CacheHitRatio(stat.TotalUsageIn, stat.TotalUsageCacheIn),
 			},
 		})
 	}
@@ -807,13 +813,14 @@ func (s *AnalyticsService) GetFlowUsage(c *gin.Context) {
 		return
 	}
 
-	// Check flow ownership without loading the full object (to avoid JSON field scanning issues)
+	// Check flow access without loading the full object (to avoid JSON field scanning issues)
 	var count int64
 	var checkQuery *gorm.DB
 	if slices.Contains(privs, "usage.admin") {
 		checkQuery = s.db.Model(&models.Flow{}).Where("id = ? AND deleted_at IS NULL", flowID)
 	} else {
-		checkQuery = s.db.Model(&models.Flow{}).Where("id = ? AND user_id = ? AND deleted_at IS NULL", flowID, uid)
+		checkQuery = s.db.Model(&models.Flow{}).Where("id = ? AND deleted_at IS NULL", flowID).
+			Scopes(userFlowScope("flows", uid, "usage.view"))
 	}
 
 	if err := checkQuery.Count(&count).Error; err != nil {
@@ -874,6 +881,7 @@ func (s *AnalyticsService) GetFlowUsage(c *gin.Context) {
 		TotalUsageCacheOut: int(usageStats.TotalUsageCacheOut),
 		TotalUsageCostIn:   usageStats.TotalUsageCostIn,
 		TotalUsageCostOut:  usageStats.TotalUsageCostOut,
+		CacheHitRatio:      pconfig.CacheHitRatio(usageStats.TotalUsageIn, usageStats.TotalUsageCacheIn),
 	}
 
 	// 2. Get usage stats by agent type for this flow
@@ -922,6 +930,7 @@ func (s *AnalyticsService) GetFlowUsage(c *gin.Context) {
 				TotalUsageCacheOut: int(stat.TotalUsageCacheOut),
 				TotalUsageCostIn:   stat.TotalUsageCostIn,
 				TotalUsageCostOut:  stat.TotalUsageCostOut,
+				CacheHitRatio:      pconfig.CacheHitRatio(stat.TotalUsageIn, stat.TotalUsageCacheIn),
 			},
 		})
 	}
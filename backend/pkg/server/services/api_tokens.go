@@ -2,7 +2,10 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"pentagi/pkg/database"
@@ -78,6 +81,11 @@ func (s *TokenService) CreateToken(c *gin.Context) {
 		return
 	}
 
+	privileges, scope, err := s.bindTokenScope(c, req)
+	if err != nil {
+		return
+	}
+
 	if req.Name != nil && *req.Name != "" {
 		var existing models.APIToken
 		err := s.db.
@@ -108,12 +116,16 @@ func (s *TokenService) CreateToken(c *gin.Context) {
 	}
 
 	apiToken := models.APIToken{
-		TokenID: tokenID,
-		UserID:  uid,
-		RoleID:  rid,
-		Name:    req.Name,
-		TTL:     req.TTL,
-		Status:  models.TokenStatusActive,
+		TokenID:     tokenID,
+		UserID:      uid,
+		RoleID:      rid,
+		Name:        req.Name,
+		TTL:         req.TTL,
+		Status:      models.TokenStatusActive,
+		Privileges:  privileges,
+		FlowIDs:     scope.FlowIDs,
+		TemplateIDs: scope.TemplateIDs,
+		AllowedIPs:  scope.IPs(),
 	}
 
 	if err := s.db.Create(&apiToken).Error; err != nil {
@@ -392,17 +404,127 @@ func (s *TokenService) DeleteToken(c *gin.Context) {
 	response.Success(c, http.StatusOK, gin.H{"message": "token deleted successfully"})
 }
 
+// bindTokenScope checks the privileges and the scope requested for a new token:
+// the privileges must be held by the caller, the flows and the templates must be
+// visible to it, and a token minted by a scoped token stays within that scope
+func (s *TokenService) bindTokenScope(
+	c *gin.Context,
+	req models.CreateAPITokenRequest,
+) ([]string, auth.TokenScope, error) {
+	privileges, err := auth.ValidatePrivileges(req.Privileges)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating token privileges")
+		response.Error(c, response.ErrTokenInvalidRequest, err)
+		return nil, auth.TokenScope{}, err
+	}
+	if len(privileges) == 0 {
+		privileges = nil
+	}
+
+	prms := c.GetStringSlice("prm")
+	for _, priv := range privileges {
+		if !auth.LookupPerm(prms, priv) {
+			err = fmt.Errorf("privilege '%s' is not granted to the current user", priv)
+			logger.FromContext(c).WithError(err).Errorf("error checking token privileges")
+			response.Error(c, response.ErrNotPermitted, err)
+			return nil, auth.TokenScope{}, err
+		}
+	}
+
+	scope, err := auth.NewTokenScope(req.FlowIDs, req.TemplateIDs, req.AllowedIPs)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing token scope")
+		response.Error(c, response.ErrTokenInvalidRequest, err)
+		return nil, auth.TokenScope{}, err
+	}
+	scope.FlowIDs = slices.Compact(slices.Sorted(slices.Values(scope.FlowIDs)))
+	scope.TemplateIDs = slices.Compact(slices.Sorted(slices.Values(scope.TemplateIDs)))
+
+	if scope, err = auth.GetTokenScope(c).Narrow(scope); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error narrowing token scope")
+		response.Error(c, response.ErrNotPermitted, err)
+		return nil, auth.TokenScope{}, err
+	}
+
+	uid := c.GetUint64("uid")
+	checks := []struct {
+		table string
+		admin string
+		ids   []int64
+	}{
+		{table: "flows", admin: "flows.admin", ids: scope.FlowIDs},
+		{table: "flow_templates", admin: "templates.admin", ids: scope.TemplateIDs},
+	}
+	for _, check := range checks {
+		if len(check.ids) == 0 {
+			continue
+		}
+
+		query := s.db.Table(check.table).Where("id IN (?)", check.ids)
+		if check.table == "flows" {
+			query = query.Where("deleted_at IS NULL")
+		}
+		if !auth.LookupPerm(prms, check.admin) {
+			query = query.Where("user_id = ? OR project_id IN "+
+				"(SELECT m.project_id FROM project_members m WHERE m.user_id = ?)", uid, uid)
+		}
+
+		var count int
+		if err = query.Count(&count).Error; err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error checking token scope in %s", check.table)
+			response.Error(c, response.ErrInternal, err)
+			return nil, auth.TokenScope{}, err
+		}
+		if count != len(check.ids) {
+			err = fmt.Errorf("some of %s of the token scope are not found", check.table)
+			logger.FromContext(c).WithError(err).Errorf("error checking token scope")
+			response.Error(c, response.ErrTokenInvalidRequest, err)
+			return nil, auth.TokenScope{}, err
+		}
+	}
+
+	return privileges, scope, nil
+}
+
+// checkTemplateScope checks that a token restricted to some templates creates
+// the flow from one of them: the flow input must be the text of the template
+func checkTemplateScope(db *gorm.DB, scope auth.TokenScope, input string) error {
+	if !scope.HasTemplates() {
+		return nil
+	}
+
+	var count int
+	err := db.Table("flow_templates").
+		Where("id IN (?) AND TRIM(text) = ?", scope.TemplateIDs, strings.TrimSpace(input)).
+		Count(&count).
+		Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("flow input doesn't match any template of the token scope")
+	}
+
+	return nil
+}
+
 func convertAPITokenToDatabase(apiToken models.APIToken) database.ApiToken {
 	return database.ApiToken{
-		ID:        int64(apiToken.ID),
-		TokenID:   apiToken.TokenID,
-		UserID:    int64(apiToken.UserID),
-		RoleID:    int64(apiToken.RoleID),
-		Name:      database.StringToNullString(*apiToken.Name),
-		Ttl:       int64(apiToken.TTL),
-		Status:    database.TokenStatus(apiToken.Status),
-		CreatedAt: database.TimeToNullTime(apiToken.CreatedAt),
-		UpdatedAt: database.TimeToNullTime(apiToken.UpdatedAt),
-		DeletedAt: database.PtrTimeToNullTime(apiToken.DeletedAt),
+		ID:          int64(apiToken.ID),
+		TokenID:     apiToken.TokenID,
+		UserID:      int64(apiToken.UserID),
+		RoleID:      int64(apiToken.RoleID),
+		Name:        database.StringToNullString(*apiToken.Name),
+		Ttl:         int64(apiToken.TTL),
+		Status:      database.TokenStatus(apiToken.Status),
+		CreatedAt:   database.TimeToNullTime(apiToken.CreatedAt),
+		UpdatedAt:   database.TimeToNullTime(apiToken.UpdatedAt),
+		DeletedAt:   database.PtrTimeToNullTime(apiToken.DeletedAt),
+		Privileges:  apiToken.Privileges,
+		FlowIds:     apiToken.FlowIDs,
+		TemplateIds: apiToken.TemplateIDs,
+		AllowedIps:  apiToken.AllowedIPs,
+		LastUsedAt:  database.PtrTimeToNullTime(apiToken.LastUsedAt),
+		LastUsedIp:  database.PtrStringToNullString(apiToken.LastUsedIP),
 	}
 }
@@ -53,6 +53,12 @@ func setupTestDB(t *testing.T) *gorm.DB {
 			name TEXT,
 			ttl INTEGER NOT NULL,
 			status TEXT NOT NULL DEFAULT 'active',
+			privileges TEXT,
+			flow_ids TEXT,
+			template_ids TEXT,
+			allowed_ips TEXT,
+			last_used_at DATETIME,
+			last_used_ip TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			deleted_at DATETIME
@@ -299,6 +305,78 @@ func TestTokenService_CreateToken_NameUniqueness(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, w3.Code)
 }
 
+func TestTokenService_CreateToken_Scope(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	db.Exec(`CREATE TABLE flows (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL, project_id INTEGER, deleted_at DATETIME)`)
+	db.Exec(`CREATE TABLE project_members (project_id INTEGER NOT NULL, user_id INTEGER NOT NULL)`)
+	db.Exec("INSERT INTO flows (id, user_id) VALUES (1, 1), (2, 2)")
+
+	tokenCache := auth.NewTokenCache(db)
+	service := NewTokenService(db, "custom_salt", tokenCache, nil)
+
+	testCases := []struct {
+		name         string
+		requestBody  string
+		tokenScope   *auth.TokenScope
+		expectedCode int
+	}{
+		{
+			name:         "scoped token",
+			requestBody:  `{"ttl": 3600, "privileges": ["flows.view"], "flow_ids": [1], "allowed_ips": ["10.0.0.0/8"]}`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "privilege not held by creator",
+			requestBody:  `{"ttl": 3600, "privileges": ["flows.admin"]}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "unknown privilege",
+			requestBody:  `{"ttl": 3600, "privileges": ["flows.unknown"]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "foreign flow",
+			requestBody:  `{"ttl": 3600, "flow_ids": [2]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid address",
+			requestBody:  `{"ttl": 3600, "allowed_ips": ["localhost"]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "flow out of creator token scope",
+			requestBody:  `{"ttl": 3600, "flow_ids": [2]}`,
+			tokenScope:   &auth.TokenScope{FlowIDs: []int64{1}},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, w := setupTestContext(1, 2, "hash1", []string{"settings.tokens.create", "flows.view"})
+			if tc.tokenScope != nil {
+				auth.SetTokenScope(c, *tc.tokenScope)
+			}
+			c.Request = httptest.NewRequest(http.MethodPost, "/tokens", bytes.NewBufferString(tc.requestBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			service.CreateToken(c)
+			assert.Equal(t, tc.expectedCode, w.Code, w.Body.String())
+		})
+	}
+
+	var token models.APIToken
+	require.NoError(t, db.Where("user_id = ?", 1).Take(&token).Error)
+	assert.Equal(t, []string{"flows.view"}, []string(token.Privileges))
+	assert.Equal(t, []int64{1}, []int64(token.FlowIDs))
+	assert.Empty(t, token.TemplateIDs)
+	assert.Equal(t, []string{"10.0.0.0/8"}, []string(token.AllowedIPs))
+}
+
 func TestTokenService_ListTokens(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
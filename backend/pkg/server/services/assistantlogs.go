@@ -37,6 +37,12 @@ var assistantlogsSQLMappers = map[string]any{
 	"data":          "({{table}}.type || ' ' || {{table}}.message || ' ' || {{table}}.result)",
 }
 
+var assistantlogsColumns = rdb.LogColumns{
+	Kind:     "assistantlogs.type::text",
+	Document: "search_document(assistantlogs.message, assistantlogs.thinking, assistantlogs.result)",
+	Tasks:    false,
+}
+
 type AssistantlogService struct {
 	db *gorm.DB
 }
@@ -52,7 +58,7 @@ func NewAssistantlogService(db *gorm.DB) *AssistantlogService {
 // @Tags Assistantlogs
 // @Produce json
 // @Security BearerAuth
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=assistantlogs} "assistantlogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting assistantlogs not permitted"
@@ -61,7 +67,7 @@ func NewAssistantlogService(db *gorm.DB) *AssistantlogService {
 func (s *AssistantlogService) GetAssistantlogs(c *gin.Context) {
 	var (
 		err   error
-		query rdb.TableQuery
+		query rdb.LogQuery
 		resp  assistantlogs
 	)
 
@@ -83,7 +89,7 @@ func (s *AssistantlogService) GetAssistantlogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "assistantlogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -91,7 +97,11 @@ func (s *AssistantlogService) GetAssistantlogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("assistantlogs", assistantlogsSQLMappers)
+	if err = query.Init("assistantlogs", assistantlogsSQLMappers, assistantlogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrAssistantlogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := assistantlogsSQLMappers[query.Group]; !ok {
@@ -134,7 +144,7 @@ func (s *AssistantlogService) GetAssistantlogs(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param flowID path int true "flow id" minimum(0)
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=assistantlogs} "assistantlogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting assistantlogs not permitted"
@@ -144,7 +154,7 @@ func (s *AssistantlogService) GetFlowAssistantlogs(c *gin.Context) {
 	var (
 		err    error
 		flowID uint64
-		query  rdb.TableQuery
+		query  rdb.LogQuery
 		resp   assistantlogs
 	)
 
@@ -173,7 +183,7 @@ func (s *AssistantlogService) GetFlowAssistantlogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "assistantlogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -181,7 +191,11 @@ func (s *AssistantlogService) GetFlowAssistantlogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("assistantlogs", assistantlogsSQLMappers)
+	if err = query.Init("assistantlogs", assistantlogsSQLMappers, assistantlogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrAssistantlogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := assistantlogsSQLMappers[query.Group]; !ok {
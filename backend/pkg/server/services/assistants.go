@@ -13,6 +13,7 @@ import (
 	"pentagi/pkg/graph/subscriptions"
 	"pentagi/pkg/providers"
 	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/server/auth"
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/server/models"
 	"pentagi/pkg/server/rdb"
@@ -114,7 +115,7 @@ func (s *AssistantService) GetFlowAssistants(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = assistants.flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "assistants.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -204,7 +205,7 @@ func (s *AssistantService) GetFlowAssistant(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = assistants.flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "assistants.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -278,6 +279,11 @@ func (s *AssistantService) CreateFlowAssistant(c *gin.Context) {
 			response.Error(c, response.ErrNotPermitted, nil)
 			return
 		}
+		if err := checkTemplateScope(s.db, auth.GetTokenScope(c), createAssistant.Input); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error checking token templates")
+			response.Error(c, response.ErrNotPermitted, err)
+			return
+		}
 	} else {
 		if !slices.Contains(privs, "assistants.create") {
 			logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -285,13 +291,15 @@ func (s *AssistantService) CreateFlowAssistant(c *gin.Context) {
 			return
 		}
 
-		// Verify the flow exists and belongs to the user (unless admin).
+		// Verify the flow exists and the user owns it or edits it in a project (unless admin).
 		var flow models.Flow
 		var flowScope func(db *gorm.DB) *gorm.DB
 		if slices.Contains(privs, "flows.admin") {
 			flowScope = func(db *gorm.DB) *gorm.DB { return db.Where("id = ?", flowID) }
 		} else {
-			flowScope = func(db *gorm.DB) *gorm.DB { return db.Where("id = ? AND user_id = ?", flowID, uid) }
+			flowScope = func(db *gorm.DB) *gorm.DB {
+				return db.Where("id = ?", flowID).Scopes(userFlowScope("flows", uid, "assistants.create"))
+			}
 		}
 		if err = s.db.Model(&flow).Scopes(flowScope).Take(&flow).Error; err != nil {
 			logger.FromContext(c).WithError(err).Errorf("error getting flow by id")
@@ -413,7 +421,7 @@ func (s *AssistantService) PatchAssistant(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.Where("assistants.id = ? AND assistants.flow_id = ?", assistantID, flowID).
 				Joins("INNER JOIN flows f ON f.id = assistants.flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "assistants.edit"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -550,7 +558,7 @@ func (s *AssistantService) DeleteAssistant(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.Where("assistants.id = ? AND assistants.flow_id = ?", assistantID, flowID).
 				Joins("INNER JOIN flows f ON f.id = assistants.flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "assistants.delete"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
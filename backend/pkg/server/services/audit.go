@@ -0,0 +1,247 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"pentagi/pkg/server/audit"
+	"pentagi/pkg/server/auth"
+	"pentagi/pkg/server/logger"
+	"pentagi/pkg/server/models"
+	"pentagi/pkg/server/rdb"
+	"pentagi/pkg/server/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+const auditExportBatchSize = 500
+
+type auditEvents struct {
+	Events []models.AuditEvent `json:"events"`
+	Total  uint64              `json:"total"`
+}
+
+type auditEventsGrouped struct {
+	Grouped []string `json:"grouped"`
+	Total   uint64   `json:"total"`
+}
+
+var auditEventsSQLMappers = map[string]any{
+	"id":          "{{table}}.id",
+	"user_id":     "{{table}}.user_id",
+	"user_type":   "{{table}}.user_type",
+	"token_id":    "{{table}}.token_id",
+	"action":      "{{table}}.action",
+	"method":      "{{table}}.method",
+	"target_type": "{{table}}.target_type",
+	"target_id":   "{{table}}.target_id",
+	"status":      "{{table}}.status",
+	"error":       "{{table}}.error",
+	"ip":          "{{table}}.ip",
+	"user_agent":  "{{table}}.user_agent",
+	"created_at":  "{{table}}.created_at",
+	"data":        "({{table}}.action || ' ' || {{table}}.method || ' ' || {{table}}.target_id || ' ' || {{table}}.ip)",
+}
+
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{
+		db: db,
+	}
+}
+
+func hasAuditView(c *gin.Context) bool {
+	return slices.Contains(c.GetStringSlice("prm"), auth.PrivilegeAuditView)
+}
+
+// GetAuditEvents is a function to return audit events list
+// @Summary Retrieve audit events list
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Param request query rdb.TableQuery true "query table params"
+// @Success 200 {object} response.successResp{data=auditEvents} "audit events list received successful"
+// @Failure 400 {object} response.errorResp "invalid query request data"
+// @Failure 403 {object} response.errorResp "getting audit events not permitted"
+// @Failure 500 {object} response.errorResp "internal error on getting audit events"
+// @Router /audit/ [get]
+func (s *AuditService) GetAuditEvents(c *gin.Context) {
+	var (
+		err   error
+		query rdb.TableQuery
+		resp  auditEvents
+	)
+
+	if err = c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrAuditInvalidRequest, err)
+		return
+	}
+
+	if !hasAuditView(c) {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	query.Init("audit_events", auditEventsSQLMappers)
+
+	if query.Group != "" {
+		if _, ok := auditEventsSQLMappers[query.Group]; !ok {
+			logger.FromContext(c).Errorf("error finding audit events grouped: group field not found")
+			response.Error(c, response.ErrAuditInvalidRequest, errors.New("group field not found"))
+			return
+		}
+
+		var respGrouped auditEventsGrouped
+		if respGrouped.Total, err = query.QueryGrouped(s.db, &respGrouped.Grouped); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error finding audit events grouped")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+
+		response.Success(c, http.StatusOK, respGrouped)
+		return
+	}
+
+	if resp.Total, err = query.Query(s.db, &resp.Events); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding audit events")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	for i := 0; i < len(resp.Events); i++ {
+		if err = resp.Events[i].Valid(); err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error validating audit event data '%d'", resp.Events[i].ID)
+			response.Error(c, response.ErrAuditInvalidData, err)
+			return
+		}
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// ExportAuditEvents is a function to stream audit events for a SIEM
+// @Summary Export audit events as JSON Lines or RFC 5424 syslog messages
+// @Tags Audit
+// @Produce plain
+// @Security BearerAuth
+// @Param request query models.AuditExportQuery false "export filter"
+// @Success 200 {file} file "audit events, one per line in ascending order"
+// @Failure 400 {object} response.errorResp "invalid query request data"
+// @Failure 403 {object} response.errorResp "exporting audit events not permitted"
+// @Failure 500 {object} response.errorResp "internal error on exporting audit events"
+// @Router /audit/export [get]
+func (s *AuditService) ExportAuditEvents(c *gin.Context) {
+	var query models.AuditExportQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding query")
+		response.Error(c, response.ErrAuditInvalidRequest, err)
+		return
+	}
+	if err := query.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating query")
+		response.Error(c, response.ErrAuditInvalidRequest, err)
+		return
+	}
+
+	if !hasAuditView(c) {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	format, contentType, extension := audit.FormatJSONL, "application/x-ndjson", "jsonl"
+	if query.Format == models.AuditExportFormatSyslog {
+		format, contentType, extension = audit.FormatSyslog, "text/plain; charset=utf-8", "log"
+	}
+
+	// the first batch is read before the headers so that failures still produce a JSON error
+	var events []models.AuditEvent
+	if err := s.exportBatch(query, 0, &events); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding audit events")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	filename := fmt.Sprintf("pentagi-audit-%s.%s", time.Now().UTC().Format("20060102-150405"), extension)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+
+	for len(events) != 0 {
+		for _, event := range events {
+			line, err := format(event)
+			if err != nil {
+				logger.FromContext(c).WithError(err).Errorf("error formatting audit event '%d'", event.ID)
+				return
+			}
+			if query.Format == models.AuditExportFormatSyslog {
+				line = append(line, '\n')
+			}
+			if _, err := c.Writer.Write(line); err != nil {
+				logger.FromContext(c).WithError(err).Errorf("error writing audit events")
+				return
+			}
+		}
+		c.Writer.Flush()
+
+		if len(events) < auditExportBatchSize {
+			break
+		}
+
+		lastID := events[len(events)-1].ID
+		events = events[:0]
+		if err := s.exportBatch(query, lastID, &events); err != nil {
+			// the status is already sent, the collector sees the truncated stream
+			logger.FromContext(c).WithError(err).Errorf("error finding audit events after '%d'", lastID)
+			return
+		}
+	}
+}
+
+func (s *AuditService) exportBatch(query models.AuditExportQuery, afterID uint64, events *[]models.AuditEvent) error {
+	return s.db.Scopes(query.Scope).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(auditExportBatchSize).
+		Find(events).Error
+}
+
+// VerifyAuditLog is a function to check the hash chain of the audit log
+// @Summary Verify that audit events were not changed or removed
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.successResp{data=models.AuditVerification} "audit log verified, see valid field for the result"
+// @Failure 403 {object} response.errorResp "verifying audit log not permitted"
+// @Failure 500 {object} response.errorResp "internal error on verifying audit log"
+// @Router /audit/verify [get]
+func (s *AuditService) VerifyAuditLog(c *gin.Context) {
+	if !hasAuditView(c) {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	result, err := audit.Verify(s.db)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error verifying audit log")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if !result.Valid {
+		logger.FromContext(c).Warnf("audit log chain is broken at event '%d': %s", *result.BrokenID, result.Reason)
+	}
+
+	response.Success(c, http.StatusOK, result)
+}
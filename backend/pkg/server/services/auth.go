@@ -48,6 +48,10 @@ type AuthServiceConfig struct {
 	// otherwise clobber each other's in-flight OAuth handshakes. Empty in
 	// single-instance mode, which keeps the cookie names exactly "state"/"nonce".
 	CookiePrefix string
+
+	// RoleMappings maps identity provider groups to local roles per provider name,
+	// users of a provider with a mapping are denied when none of their groups match
+	RoleMappings map[string]oauth.RoleMapping
 }
 
 // stateCookieName returns the tenant-scoped name of the OAuth CSRF state cookie.
@@ -60,17 +64,31 @@ func (s *AuthService) nonceCookieName() string {
 	return s.cfg.CookiePrefix + authNonceCookieName
 }
 
+// callbackSameSite returns SameSite mode of the callback cookies for the provider.
+// Google OAuth and SAML use POST callback which requires SameSite=None for cross-site requests,
+// GitHub and other providers use GET callback which works with SameSite=Lax
+func callbackSameSite(provider string) http.SameSite {
+	switch provider {
+	case "google", oauth.SAMLProviderName:
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
 type AuthService struct {
 	cfg   AuthServiceConfig
 	db    *gorm.DB
 	key   []byte
 	oauth map[string]oauth.OAuthClient
+	saml  oauth.SAMLClient
 }
 
 func NewAuthService(
 	cfg AuthServiceConfig,
 	db *gorm.DB,
 	oauth map[string]oauth.OAuthClient,
+	saml oauth.SAMLClient,
 ) *AuthService {
 	var count int
 	err := db.Model(&models.User{}).Where("type = 'local'").Count(&count).Error
@@ -88,6 +106,7 @@ func NewAuthService(
 		db:    db,
 		key:   key,
 		oauth: oauth,
+		saml:  saml,
 	}
 }
 
@@ -243,7 +262,7 @@ func (s *AuthService) refreshCookie(c *gin.Context, resp *info, privs []string)
 // @Tags Public
 // @Produce json
 // @Param return_uri query string false "URI to redirect user there after login" default(/)
-// @Param provider query string false "OAuth provider name (google, github, etc.)" default(google) enums:"google,github"
+// @Param provider query string false "OAuth provider name (google, github, oidc, saml, etc.)" default(google)
 // @Success 307 "redirect to SSO login page"
 // @Failure 400 {object} response.errorResp "invalid autorizarion query"
 // @Failure 403 {object} response.errorResp "authorize not permitted"
@@ -268,7 +287,7 @@ func (s *AuthService) AuthAuthorize(c *gin.Context) {
 
 	provider := c.Query("provider")
 	oauthClient, ok := s.oauth[provider]
-	if !ok {
+	if !ok && (provider != oauth.SAMLProviderName || s.saml == nil) {
 		logger.FromContext(c).Errorf("external OAuth2 provider '%s' is not initialized", provider)
 		err := fmt.Errorf("provider not initialized")
 		response.Error(c, response.ErrNotPermitted, err)
@@ -304,21 +323,36 @@ func (s *AuthService) AuthAuthorize(c *gin.Context) {
 	signedStateJSON := append(signature, stateJSON...)
 	state := base64.RawURLEncoding.EncodeToString(signedStateJSON)
 
-	// Google OAuth uses POST callback which requires SameSite=None for cross-site requests
-	// GitHub and other providers use GET callback which works with SameSite=Lax
-	sameSiteMode := http.SameSiteLaxMode
-	if provider == "google" {
-		sameSiteMode = http.SameSiteNoneMode
+	sameSiteMode := callbackSameSite(provider)
+	maxAge := int(authStateRequestTTL / time.Second)
+
+	// SAML binds the response to the authentication request ID instead of the nonce
+	if provider == oauth.SAMLProviderName {
+		redirectURL, requestID, err := s.saml.AuthURL(state)
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("failed to make SAML authentication request")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+
+		s.setCallbackCookie(c.Writer, c.Request, s.stateCookieName(), state, maxAge, sameSiteMode)
+		s.setCallbackCookie(c.Writer, c.Request, s.nonceCookieName(), requestID, maxAge, sameSiteMode)
+		http.Redirect(c.Writer, c.Request, redirectURL, http.StatusTemporaryRedirect)
+		return
 	}
 
-	maxAge := int(authStateRequestTTL / time.Second)
 	s.setCallbackCookie(c.Writer, c.Request, s.stateCookieName(), state, maxAge, sameSiteMode)
 	s.setCallbackCookie(c.Writer, c.Request, s.nonceCookieName(), nonce, maxAge, sameSiteMode)
 
 	authOpts := []oauth2.AuthCodeOption{
 		oauth2.SetAuthURLParam("nonce", nonce),
-		oauth2.SetAuthURLParam("response_mode", "form_post"),
-		oauth2.SetAuthURLParam("response_type", "code id_token"),
+	}
+	// generic OpenID providers use the plain code flow with GET callback
+	if provider == "google" || provider == "github" {
+		authOpts = append(authOpts,
+			oauth2.SetAuthURLParam("response_mode", "form_post"),
+			oauth2.SetAuthURLParam("response_type", "code id_token"),
+		)
 	}
 	http.Redirect(c.Writer, c.Request,
 		oauthClient.AuthCodeURL(state, authOpts...),
@@ -372,12 +406,12 @@ func (s *AuthService) AuthLoginGetCallback(c *gin.Context) {
 	s.authLoginCallback(c, stateData, code)
 }
 
-// AuthLoginPostCallback is function to catch login callback from OAuth application
-// @Summary Login user from external OAuth application
+// AuthLoginPostCallback is function to catch login callback from OAuth application or SAML IdP
+// @Summary Login user from external OAuth application or SAML IdP
 // @Tags Public
 // @Accept json
 // @Produce json
-// @Param json body models.AuthCallback true "Auth form JSON data"
+// @Param json body models.AuthCallback true "Auth form JSON data, or SAMLResponse and RelayState form fields"
 // @Success 303 "redirect to registered return_uri path in the state"
 // @Failure 400 {object} response.errorResp "invalid login data"
 // @Failure 401 {object} response.errorResp "invalid login or password"
@@ -390,6 +424,11 @@ func (s *AuthService) AuthLoginPostCallback(c *gin.Context) {
 		err  error
 	)
 
+	if c.PostForm("SAMLResponse") != "" {
+		s.samlLoginCallback(c)
+		return
+	}
+
 	if err = c.ShouldBind(&data); err != nil || data.Valid() != nil {
 		if err == nil {
 			err = data.Valid()
@@ -420,6 +459,78 @@ func (s *AuthService) AuthLoginPostCallback(c *gin.Context) {
 	s.authLoginCallback(c, stateData, data.Code)
 }
 
+func (s *AuthService) samlLoginCallback(c *gin.Context) {
+	if s.saml == nil {
+		logger.FromContext(c).Errorf("external SAML provider is not initialized")
+		response.Error(c, response.ErrNotPermitted, fmt.Errorf("provider not initialized"))
+		return
+	}
+
+	state, err := c.Request.Cookie(s.stateCookieName())
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting state from cookie")
+		response.Error(c, response.ErrAuthInvalidAuthorizationState, err)
+		return
+	}
+
+	if c.PostForm("RelayState") != state.Value {
+		logger.FromContext(c).Errorf("error matching received relay state to stored one")
+		response.Error(c, response.ErrAuthInvalidAuthorizationState, nil)
+		return
+	}
+
+	stateData, err := s.parseState(c, state.Value)
+	if err != nil {
+		return
+	}
+
+	if stateData["provider"] != oauth.SAMLProviderName {
+		logger.FromContext(c).Errorf("error matching SAML response to provider '%s'", stateData["provider"])
+		response.Error(c, response.ErrAuthInvalidAuthorizationState, fmt.Errorf("provider mismatch"))
+		return
+	}
+
+	requestID, err := c.Request.Cookie(s.nonceCookieName())
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting request id from cookie")
+		response.Error(c, response.ErrAuthInvalidAuthorizationNonce, err)
+		return
+	}
+
+	user, err := s.saml.ResolveUser(c.Request, requestID.Value)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("failed to validate SAML response")
+		response.Error(c, response.ErrAuthVerificationTokenFail, err)
+		return
+	}
+
+	s.loginExternalUser(c, stateData, user)
+}
+
+// AuthSAMLMetadata is function to return SAML service provider metadata
+// @Summary Retrieve SAML 2.0 service provider metadata to register it in the IdP
+// @Tags Public
+// @Produce xml
+// @Success 200 "SAML SP metadata XML"
+// @Failure 403 {object} response.errorResp "SAML is not configured"
+// @Failure 500 {object} response.errorResp "internal error on making metadata"
+// @Router /auth/saml/metadata [get]
+func (s *AuthService) AuthSAMLMetadata(c *gin.Context) {
+	if s.saml == nil {
+		response.Error(c, response.ErrNotPermitted, fmt.Errorf("provider not initialized"))
+		return
+	}
+
+	metadata, err := s.saml.Metadata()
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("failed to make SAML metadata")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
 // AuthLogoutCallback is function to catch logout callback from OAuth application
 // @Summary Logout current user from external OAuth application
 // @Tags Public
@@ -464,11 +575,6 @@ func (s *AuthService) AuthLogout(c *gin.Context) {
 }
 
 func (s *AuthService) authLoginCallback(c *gin.Context, stateData map[string]string, code string) {
-	var (
-		privs []string
-		user  models.User
-	)
-
 	provider := stateData["provider"]
 	oauthClient, ok := s.oauth[provider]
 	if !ok {
@@ -499,13 +605,25 @@ func (s *AuthService) authLoginCallback(c *gin.Context, stateData map[string]str
 		return
 	}
 
-	email, verified, err := oauthClient.ResolveEmail(ctx, nonce.Value, oauth2Token)
+	externalUser, err := oauthClient.ResolveUser(ctx, nonce.Value, oauth2Token)
 	if err != nil {
-		logger.FromContext(c).WithError(err).Errorf("failed to resolve email")
+		logger.FromContext(c).WithError(err).Errorf("failed to resolve user")
 		response.Error(c, response.ErrAuthInvalidUserData, err)
 		return
 	}
 
+	s.loginExternalUser(c, stateData, externalUser)
+}
+
+// loginExternalUser finds or creates the user asserted by the external provider and starts the session
+func (s *AuthService) loginExternalUser(c *gin.Context, stateData map[string]string, externalUser oauth.OAuthUser) {
+	var (
+		privs []string
+		user  models.User
+	)
+
+	provider := stateData["provider"]
+	email, verified := externalUser.Email, externalUser.Verified
 	if !verified {
 		logger.FromContext(c).Errorf("provider returned an unverified email '%s'", email)
 		response.Error(c, response.ErrAuthInvalidUserData, fmt.Errorf("email not verified by provider"))
@@ -524,14 +642,33 @@ func (s *AuthService) authLoginCallback(c *gin.Context, stateData map[string]str
 		response.Error(c, response.ErrAuthInvalidUserData, fmt.Errorf("empty username"))
 		return
 	}
+	if externalUser.Name != "" {
+		username = externalUser.Name
+	}
+
+	roleID, mapped, err := s.mapExternalRole(provider, externalUser.Groups)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error mapping groups of '%s' to role", email)
+		if errors.Is(err, errRoleNotMapped) {
+			response.Error(c, response.ErrNotPermitted, err)
+		} else {
+			response.Error(c, response.ErrAuthInvalidServiceData, err)
+		}
+		return
+	}
+	if !mapped {
+		roleID = models.RoleUser
+	}
 
+	// the role is synced to the groups only for the external users of this provider
+	syncRole := true
 	if err = s.db.Take(&user, "mail = ?", email).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			user = models.User{
 				Hash:     rdb.MakeUserHash(email),
 				Mail:     email,
 				Name:     username,
-				RoleID:   models.RoleUser,
+				RoleID:   roleID,
 				Status:   "active",
 				Type:     models.UserTypeOAuth,
 				Provider: &provider,
@@ -556,6 +693,12 @@ func (s *AuthService) authLoginCallback(c *gin.Context, stateData map[string]str
 					response.Error(c, response.ErrInternal, err)
 					return
 				}
+				if !canLinkExternalUser(user, provider) {
+					logger.FromContext(c).Errorf("can't link %s login to %s user '%s'", provider, user.Type, user.Hash)
+					response.Error(c, response.ErrAuthInvalidUserData, fmt.Errorf("user is not external"))
+					return
+				}
+				syncRole = ownsExternalUser(user, provider)
 			} else {
 				preferences := models.NewUserPreferences(user.ID)
 				if err = tx.Create(preferences).Error; err != nil {
@@ -580,10 +723,29 @@ func (s *AuthService) authLoginCallback(c *gin.Context, stateData map[string]str
 		logger.FromContext(c).WithError(err).Errorf("error validating user data '%s'", user.Hash)
 		response.Error(c, response.ErrAuthInvalidUserData, err)
 		return
-	} else if user.Provider == nil || *user.Provider != provider {
-		if err = s.db.Model(&user).Update("provider", provider).Error; err != nil {
-			logger.FromContext(c).WithError(err).Errorf("error updating user provider '%s'", user.Hash)
+	} else if !canLinkExternalUser(user, provider) {
+		logger.FromContext(c).Errorf("can't link %s login to %s user '%s'", provider, user.Type, user.Hash)
+		response.Error(c, response.ErrAuthInvalidUserData, fmt.Errorf("user is not external"))
+		return
+	} else {
+		syncRole = ownsExternalUser(user, provider)
+		// a provider mapping groups to roles doesn't adopt the users of another one
+		adopt := user.Type != models.UserTypeOAuth || !mapped
+		if adopt && (user.Provider == nil || *user.Provider != provider) {
+			if err = s.db.Model(&user).Update("provider", provider).Error; err != nil {
+				logger.FromContext(c).WithError(err).Errorf("error updating user provider '%s'", user.Hash)
+			}
+		}
+	}
+
+	// groups of the identity provider are the source of truth for the role
+	if mapped && syncRole && user.RoleID != roleID {
+		if err = s.db.Model(&user).Update("role_id", roleID).Error; err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error updating user role '%s'", user.Hash)
+			response.Error(c, response.ErrInternal, err)
+			return
 		}
+		user.RoleID = roleID
 	}
 
 	if user.Status != "active" {
@@ -628,12 +790,7 @@ func (s *AuthService) authLoginCallback(c *gin.Context, stateData map[string]str
 	}
 
 	// delete temporary cookies
-	// Google OAuth uses POST callback which requires SameSite=None for cross-site requests
-	// GitHub and other providers use GET callback which works with SameSite=Lax
-	sameSiteMode := http.SameSiteLaxMode
-	if stateData["provider"] == "google" {
-		sameSiteMode = http.SameSiteNoneMode
-	}
+	sameSiteMode := callbackSameSite(provider)
 	s.setCallbackCookie(c.Writer, c.Request, s.stateCookieName(), "", 0, sameSiteMode)
 	s.setCallbackCookie(c.Writer, c.Request, s.nonceCookieName(), "", 0, sameSiteMode)
 
@@ -665,6 +822,29 @@ func (s *AuthService) authLoginCallback(c *gin.Context, stateData map[string]str
 	}
 }
 
+var errRoleNotMapped = errors.New("user groups are not mapped to any role")
+
+// mapExternalRole resolves the local role by the provider groups, mapped is false
+// when there is no role mapping for the provider
+func (s *AuthService) mapExternalRole(provider string, groups []string) (uint64, bool, error) {
+	mapping := s.cfg.RoleMappings[provider]
+	if len(mapping) == 0 {
+		return 0, false, nil
+	}
+
+	roleName, ok := mapping.Match(groups)
+	if !ok {
+		return 0, false, errRoleNotMapped
+	}
+
+	var role models.Role
+	if err := s.db.Take(&role, "name = ?", roleName).Error; err != nil {
+		return 0, false, fmt.Errorf("error getting mapped role '%s': %w", roleName, err)
+	}
+
+	return role.ID, true, nil
+}
+
 func (s *AuthService) parseState(c *gin.Context, state string) (map[string]string, error) {
 	var stateData map[string]string
 
@@ -833,6 +1013,9 @@ func (s *AuthService) Info(c *gin.Context) {
 	for name := range s.oauth {
 		resp.Providers = append(resp.Providers, name)
 	}
+	if s.saml != nil {
+		resp.Providers = append(resp.Providers, s.saml.ProviderName())
+	}
 
 	logger.FromContext(c).WithFields(logrus.Fields(
 		map[string]any{
@@ -912,3 +1095,19 @@ func (s *AuthService) Info(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, resp)
 }
+
+// localLinkProviders may log in to the local account with the same email, they
+// verify the email themselves while OpenID Connect and SAML providers assert any
+var localLinkProviders = []string{"github", "google"}
+
+// canLinkExternalUser reports whether the existing user may log in with the provider,
+// an identity provider must not take over local accounts like the built-in admin
+func canLinkExternalUser(user models.User, provider string) bool {
+	return user.Type == models.UserTypeOAuth || slices.Contains(localLinkProviders, provider)
+}
+
+// ownsExternalUser reports whether the groups of the provider manage the user role,
+// the role of a local account or of a user of another provider is left as is
+func ownsExternalUser(user models.User, provider string) bool {
+	return user.Type == models.UserTypeOAuth && user.Provider != nil && *user.Provider == provider
+}
@@ -24,6 +24,7 @@ type fakeOAuthClient struct {
 	name     string
 	email    string
 	verified bool
+	groups   []string
 }
 
 func (f *fakeOAuthClient) ProviderName() string { return f.name }
@@ -32,6 +33,10 @@ func (f *fakeOAuthClient) ResolveEmail(context.Context, string, *oauth2.Token) (
 	return f.email, f.verified, nil
 }
 
+func (f *fakeOAuthClient) ResolveUser(context.Context, string, *oauth2.Token) (oauth.OAuthUser, error) {
+	return oauth.OAuthUser{Email: f.email, Verified: f.verified, Groups: f.groups}, nil
+}
+
 func (f *fakeOAuthClient) TokenSource(context.Context, *oauth2.Token) oauth2.TokenSource { return nil }
 
 func (f *fakeOAuthClient) Exchange(context.Context, string, ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
@@ -235,3 +240,118 @@ func TestAuthLoginCallback_LinksOnConcurrentCreateConflict(t *testing.T) {
 	assert.Equal(t, before, countUsers(t, db), "no duplicate is created on the conflict")
 	assert.Equal(t, uint64(20), sessions.Default(c).Get("uid"), "session is issued for the existing row")
 }
+
+func newGroupsOAuthService(db *gorm.DB, email string, groups []string, mapping oauth.RoleMapping) *AuthService {
+	svc := newOAuthService(db, email)
+	svc.cfg.RoleMappings = map[string]oauth.RoleMapping{"github": mapping}
+	svc.oauth["github"].(*fakeOAuthClient).groups = groups
+	return svc
+}
+
+func TestAuthLoginCallback_MapsGroupsToRole(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	mapping := oauth.RoleMapping{{Group: "pentagi-admins", Role: "Admin"}, {Group: "*", Role: "User"}}
+
+	svc := newGroupsOAuthService(db, "sso-admin@corp.com", []string{"staff", "pentagi-admins"}, mapping)
+	c, w := newCallbackContext(t)
+	svc.authLoginCallback(c, map[string]string{"provider": "github"}, "test-code")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var created models.User
+	require.NoError(t, db.Where("mail = ?", "sso-admin@corp.com").First(&created).Error)
+	assert.Equal(t, uint64(models.RoleAdmin), created.RoleID, "new user gets the mapped role")
+
+	// the role follows the groups of the identity provider at the next login
+	svc = newGroupsOAuthService(db, "sso-admin@corp.com", []string{"staff"}, mapping)
+	c, w = newCallbackContext(t)
+	svc.authLoginCallback(c, map[string]string{"provider": "github"}, "test-code")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var updated models.User
+	require.NoError(t, db.Where("mail = ?", "sso-admin@corp.com").First(&updated).Error)
+	assert.Equal(t, uint64(models.RoleUser), updated.RoleID, "existing user is downgraded by the fallback entry")
+	assert.Equal(t, uint64(models.RoleUser), sessions.Default(c).Get("rid"))
+}
+
+func TestAuthLoginCallback_RejectsUnmappedGroups(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	before := countUsers(t, db)
+
+	svc := newGroupsOAuthService(db, "outsider@corp.com", []string{"staff"},
+		oauth.RoleMapping{{Group: "pentagi-users", Role: "User"}})
+	c, w := newCallbackContext(t)
+	svc.authLoginCallback(c, map[string]string{"provider": "github"}, "test-code")
+
+	assert.Equal(t, http.StatusForbidden, w.Code, "users without a mapped group are denied")
+	assert.Equal(t, before, countUsers(t, db), "no account is created")
+	assert.Nil(t, sessions.Default(c).Get("uid"))
+}
+
+func newIdPOAuthService(db *gorm.DB, email string, groups []string, mapping oauth.RoleMapping) *AuthService {
+	svc := newOAuthService(db, email)
+	svc.oauth["corp"] = &fakeOAuthClient{name: "corp", email: email, verified: true, groups: groups}
+	svc.cfg.RoleMappings = map[string]oauth.RoleMapping{"corp": mapping, "github": mapping}
+	return svc
+}
+
+// TestAuthLoginCallback_RejectsIdPLinkToLocalAccount guards the built-in admin: an OpenID Connect
+// or SAML provider asserts any email, so it must neither log in to nor change the role of a local account.
+func TestAuthLoginCallback_RejectsIdPLinkToLocalAccount(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	require.NoError(t, db.Exec(
+		"INSERT INTO users (id, hash, type, mail, name, status, role_id) VALUES (12, ?, 'local', 'admin2@corp.com', 'Admin Two', 'active', 1)",
+		"1234567890abcdef1234567890abcdef",
+	).Error)
+
+	svc := newIdPOAuthService(db, "admin2@corp.com", []string{"staff"}, oauth.RoleMapping{{Group: "*", Role: "User"}})
+	c, w := newCallbackContext(t)
+	svc.authLoginCallback(c, map[string]string{"provider": "corp"}, "test-code")
+
+	assert.NotEqual(t, http.StatusOK, w.Code)
+	assert.Nil(t, sessions.Default(c).Get("uid"), "no session is issued for the local account")
+
+	var admin models.User
+	require.NoError(t, db.Where("id = ?", 12).First(&admin).Error)
+	assert.Equal(t, uint64(models.RoleAdmin), admin.RoleID, "the role of the local account is kept")
+	assert.Nil(t, admin.Provider)
+}
+
+func TestAuthLoginCallback_GroupsDontChangeRoleOfOtherUsers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	require.NoError(t, db.Exec(
+		"INSERT INTO users (id, hash, type, mail, name, status, role_id) VALUES (12, ?, 'local', 'admin2@corp.com', 'Admin Two', 'active', 1)",
+		"1234567890abcdef1234567890abcdef",
+	).Error)
+	require.NoError(t, db.Exec(
+		"INSERT INTO users (id, hash, type, mail, name, status, role_id, provider) VALUES (20, ?, 'oauth', 'lead@corp.com', 'Lead', 'active', 1, 'github')",
+		"abcdef1234567890abcdef1234567890",
+	).Error)
+	mapping := oauth.RoleMapping{{Group: "*", Role: "User"}}
+
+	// GitHub still links the local account with the same email, its role is managed locally
+	svc := newIdPOAuthService(db, "admin2@corp.com", []string{"staff"}, mapping)
+	c, w := newCallbackContext(t)
+	svc.authLoginCallback(c, map[string]string{"provider": "github"}, "test-code")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint64(models.RoleAdmin), sessions.Default(c).Get("rid"), "a local role is not synced")
+
+	// the user of another provider logs in without being adopted by this one
+	svc = newIdPOAuthService(db, "lead@corp.com", []string{"staff"}, mapping)
+	c, w = newCallbackContext(t)
+	svc.authLoginCallback(c, map[string]string{"provider": "corp"}, "test-code")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var lead models.User
+	require.NoError(t, db.Where("id = ?", 20).First(&lead).Error)
+	assert.Equal(t, uint64(models.RoleAdmin), lead.RoleID, "the role belongs to the provider of the user")
+	require.NotNil(t, lead.Provider)
+	assert.Equal(t, "github", *lead.Provider)
+}
@@ -85,7 +85,7 @@ func (s *ContainerService) GetContainers(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = containers.flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "containers.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -175,7 +175,7 @@ func (s *ContainerService) GetFlowContainers(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = containers.flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "containers.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -260,7 +260,7 @@ func (s *ContainerService) GetFlowContainer(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "containers.view") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("f.id = ? AND f.user_id = ?", flowID, uid)
+			return db.Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "containers.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
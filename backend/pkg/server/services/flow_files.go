@@ -1403,13 +1403,13 @@ func flowScopeForFiles(
 
 	if writeAccess && slices.Contains(privs, "flow_files.upload") {
 		return func(db *gorm.DB) *gorm.DB {
-			return db.Where("id = ? AND user_id = ?", flowID, uid)
+			return db.Where("id = ?", flowID).Scopes(userFlowScope("flows", uid, "flow_files.upload"))
 		}
 	}
 
 	if !writeAccess && slices.Contains(privs, "flow_files.view") {
 		return func(db *gorm.DB) *gorm.DB {
-			return db.Where("id = ? AND user_id = ?", flowID, uid)
+			return db.Where("id = ?", flowID).Scopes(userFlowScope("flows", uid, "flow_files.view"))
 		}
 	}
 
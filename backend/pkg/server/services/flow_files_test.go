@@ -611,15 +611,15 @@ func TestFlowScopeForFiles(t *testing.T) {
 			wantSQL:     "id = ?",
 		},
 		{
-			name:        "upload privilege grants write to own flow",
+			name:        "upload privilege grants write to own or edited project flow",
 			privs:       []string{"flow_files.upload"},
 			writeAccess: true,
-			wantSQL:     "id = ? AND user_id = ?",
+			wantSQL:     "id = ? AND (flows.user_id = ? OR flows.project_id IN (...))",
 		},
 		{
-			name:    "view privilege grants read to own flow",
+			name:    "view privilege grants read to own or project flow",
 			privs:   []string{"flow_files.view"},
-			wantSQL: "id = ? AND user_id = ?",
+			wantSQL: "id = ? AND (flows.user_id = ? OR flows.project_id IN (...))",
 		},
 		{
 			name:        "view privilege does not grant write",
@@ -676,12 +676,22 @@ func setupFlowFileServiceTestDB(t *testing.T) *gorm.DB {
 			functions TEXT NOT NULL DEFAULT '{}',
 			tool_call_id_template TEXT NOT NULL DEFAULT '',
 			trace_id TEXT,
+			project_id INTEGER,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			deleted_at DATETIME
 		)
 	`).Error)
 
+	require.NoError(t, db.Exec(`
+		CREATE TABLE project_members (
+			project_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			role TEXT NOT NULL DEFAULT 'viewer',
+			PRIMARY KEY (project_id, user_id)
+		)
+	`).Error)
+
 	require.NoError(t, db.Exec(`
 		CREATE TABLE user_resources (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -897,6 +907,8 @@ func (p *captureFlowPublisher) FlowDeleted(_ context.Context, _ database.Flow, _
 }
 func (p *captureFlowPublisher) FlowUpdated(_ context.Context, _ database.Flow, _ []database.Container) {
 }
+func (p *captureFlowPublisher) FlowUnshared(_ context.Context, _ database.Flow, _ []database.Container, _ int64) {
+}
 func (p *captureFlowPublisher) TaskCreated(_ context.Context, _ database.Task, _ []database.Subtask) {
 }
 func (p *captureFlowPublisher) TaskUpdated(_ context.Context, _ database.Task, _ []database.Subtask) {
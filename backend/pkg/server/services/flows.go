@@ -3,15 +3,19 @@ package services
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"slices"
 	"strconv"
 
+	"pentagi/pkg/attackgraph"
 	"pentagi/pkg/controller"
 	"pentagi/pkg/database"
+	"pentagi/pkg/graph"
 	"pentagi/pkg/graph/subscriptions"
 	"pentagi/pkg/providers"
 	"pentagi/pkg/providers/provider"
+	"pentagi/pkg/server/auth"
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/server/models"
 	"pentagi/pkg/server/rdb"
@@ -45,8 +49,31 @@ var flowsSQLMappers = map[string]any{
 	"data":                "({{table}}.status || ' ' || {{table}}.title || ' ' || {{table}}.model || ' ' || {{table}}.model_provider || ' ' || {{table}}.language)",
 }
 
+// userFlowScope limits a query to the flows (named table in it) the user owns
+// or shares through a project whose member role allows perm, the same rows
+// as GetUserFlows serves over GraphQL.
+func userFlowScope(table string, uid uint64, perm string) func(db *gorm.DB) *gorm.DB {
+	roles := make([]string, 0, 3)
+	for _, role := range []database.ProjectRole{
+		database.ProjectRoleOwner,
+		database.ProjectRoleEditor,
+		database.ProjectRoleViewer,
+	} {
+		if graph.ProjectRoleAllows(role, perm) {
+			roles = append(roles, string(role))
+		}
+	}
+
+	cond := fmt.Sprintf("(%[1]s.user_id = ? OR %[1]s.project_id IN "+
+		"(SELECT m.project_id FROM project_members m WHERE m.user_id = ? AND m.role IN (?)))", table)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(cond, uid, uid, roles)
+	}
+}
+
 type FlowService struct {
 	db *gorm.DB
+	ag *attackgraph.Loader
 	pc providers.ProviderController
 	fc controller.FlowController
 	ss subscriptions.SubscriptionsController
@@ -54,12 +81,14 @@ type FlowService struct {
 
 func NewFlowService(
 	db *gorm.DB,
+	ag *attackgraph.Loader,
 	pc providers.ProviderController,
 	fc controller.FlowController,
 	ss subscriptions.SubscriptionsController,
 ) *FlowService {
 	return &FlowService{
 		db: db,
+		ag: ag,
 		pc: pc,
 		fc: fc,
 		ss: ss,
@@ -99,7 +128,7 @@ func (s *FlowService) GetFlows(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "flows.view") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("user_id = ?", uid)
+			return db.Scopes(userFlowScope("flows", uid, "flows.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -107,6 +136,13 @@ func (s *FlowService) GetFlows(c *gin.Context) {
 		return
 	}
 
+	if tokenScope := auth.GetTokenScope(c); tokenScope.HasFlows() {
+		roleScope := scope
+		scope = func(db *gorm.DB) *gorm.DB {
+			return roleScope(db).Where("id IN (?)", tokenScope.FlowIDs)
+		}
+	}
+
 	query.Init("flows", flowsSQLMappers)
 
 	if query.Group != "" {
@@ -177,7 +213,7 @@ func (s *FlowService) GetFlow(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "flows.view") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("id = ? AND user_id = ?", flowID, uid)
+			return db.Where("id = ?", flowID).Scopes(userFlowScope("flows", uid, "flows.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -232,7 +268,7 @@ func (s *FlowService) GetFlowGraph(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "flows.view") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("id = ? AND user_id = ?", flowID, uid)
+			return db.Where("id = ?", flowID).Scopes(userFlowScope("flows", uid, "flows.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -253,14 +289,30 @@ func (s *FlowService) GetFlowGraph(c *gin.Context) {
 		return
 	}
 
+	// project members see the tasks of a shared flow the same way its owner does
+	isFlowUser := resp.UserID == uid
+	if !isFlowUser {
+		var count int
+		err = s.db.Model(&models.Flow{}).
+			Where("id = ?", flowID).
+			Scopes(userFlowScope("flows", uid, "tasks.view")).
+			Count(&count).Error
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error on checking flow project access")
+			response.Error(c, response.ErrInternal, err)
+			return
+		}
+		isFlowUser = count > 0
+	}
+
 	isTasksAdmin := slices.Contains(privs, "tasks.admin")
 	isTasksView := slices.Contains(privs, "tasks.view")
-	if !(resp.UserID == uid && isTasksView) && !(resp.UserID != uid && isTasksAdmin) {
+	if !(isFlowUser && isTasksView) && !(!isFlowUser && isTasksAdmin) {
 		response.Success(c, http.StatusOK, resp)
 		return
 	}
 
-	if resp.UserID != uid && !slices.Contains(privs, "tasks.admin") {
+	if !isFlowUser && !slices.Contains(privs, "tasks.admin") {
 		response.Success(c, http.StatusOK, resp)
 		return
 	}
@@ -274,7 +326,7 @@ func (s *FlowService) GetFlowGraph(c *gin.Context) {
 
 	isSubtasksAdmin := slices.Contains(privs, "subtasks.admin")
 	isSubtasksView := slices.Contains(privs, "subtasks.view")
-	if !(resp.UserID == uid && isSubtasksView) && !(resp.UserID != uid && isSubtasksAdmin) {
+	if !(isFlowUser && isSubtasksView) && !(!isFlowUser && isSubtasksAdmin) {
 		response.Success(c, http.StatusOK, resp)
 		return
 	}
@@ -309,6 +361,89 @@ func (s *FlowService) GetFlowGraph(c *gin.Context) {
 	response.Success(c, http.StatusOK, resp)
 }
 
+// GetFlowAttackGraph is a function to export the attack graph of a flow
+// @Summary Export flow attack graph as JSON or Graphviz DOT
+// @Tags Flows
+// @Produce json,text/vnd.graphviz
+// @Security BearerAuth
+// @Param flowID path int true "flow id" minimum(0)
+// @Param format query string false "export format" Enums(json, dot) default(json)
+// @Success 200 {file} file "flow attack graph"
+// @Failure 400 {object} response.errorResp "invalid export format"
+// @Failure 403 {object} response.errorResp "getting flow attack graph not permitted"
+// @Failure 404 {object} response.errorResp "flow not found"
+// @Failure 500 {object} response.errorResp "internal error on building flow attack graph"
+// @Router /flows/{flowID}/attack-graph [get]
+func (s *FlowService) GetFlowAttackGraph(c *gin.Context) {
+	var (
+		err    error
+		flowID uint64
+		flow   models.Flow
+	)
+
+	if flowID, err = strconv.ParseUint(c.Param("flowID"), 10, 64); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing flow id")
+		response.Error(c, response.ErrFlowsInvalidRequest, err)
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "dot" {
+		logger.FromContext(c).Errorf("error parsing attack graph format: %s", format)
+		response.Error(c, response.ErrFlowsInvalidRequest, fmt.Errorf("unknown format %q", format))
+		return
+	}
+
+	uid := c.GetUint64("uid")
+	privs := c.GetStringSlice("prm")
+	var scope func(db *gorm.DB) *gorm.DB
+	if slices.Contains(privs, "toolcalls.admin") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID)
+		}
+	} else if slices.Contains(privs, "toolcalls.view") {
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("id = ?", flowID).Scopes(userFlowScope("flows", uid, "toolcalls.view"))
+		}
+	} else {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if err = s.db.Model(&flow).Scopes(scope).Take(&flow).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on getting flow by id")
+		if gorm.IsRecordNotFoundError(err) {
+			response.Error(c, response.ErrFlowsNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return
+	}
+
+	graph, err := s.ag.Load(c.Request.Context(), int64(flowID))
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on building flow attack graph")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	filename := fmt.Sprintf("flow-%d-attack-graph.%s", flowID, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "dot" {
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(graph.DOT()))
+		return
+	}
+
+	data, err := graph.JSON()
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error on encoding flow attack graph")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
 // CreateFlow is a function to create new flow with custom functions
 // @Summary Create new flow with custom functions
 // @Tags Flows
@@ -347,6 +482,12 @@ func (s *FlowService) CreateFlow(c *gin.Context) {
 		return
 	}
 
+	if err := checkTemplateScope(s.db, auth.GetTokenScope(c), createFlow.Input); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error checking token templates")
+		response.Error(c, response.ErrNotPermitted, err)
+		return
+	}
+
 	uid := c.GetUint64("uid")
 	prvname := provider.ProviderName(createFlow.Provider)
 
@@ -431,7 +572,7 @@ func (s *FlowService) PatchFlow(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "flows.edit") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("id = ? AND user_id = ?", flowID, uid)
+			return db.Where("id = ?", flowID).Scopes(userFlowScope("flows", uid, "flows.edit"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -565,7 +706,7 @@ func (s *FlowService) DeleteFlow(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "flows.delete") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("id = ? AND user_id = ?", flowID, uid)
+			return db.Where("id = ?", flowID).Scopes(userFlowScope("flows", uid, "flows.delete"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
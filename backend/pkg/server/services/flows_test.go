@@ -1,12 +1,17 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
 
+	"pentagi/pkg/attackgraph"
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
 	"pentagi/pkg/server/models"
 
+	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/require"
 )
 
@@ -28,7 +33,7 @@ func TestGetFlowsAllowsPendingTraceID(t *testing.T) {
 		0,
 	)
 
-	NewFlowService(db, nil, nil, nil).GetFlows(c)
+	NewFlowService(db, nil, nil, nil, nil).GetFlows(c)
 
 	require.Equal(t, http.StatusOK, w.Code)
 	var resp struct {
@@ -44,3 +49,147 @@ func TestGetFlowsAllowsPendingTraceID(t *testing.T) {
 	require.Len(t, resp.Data.Flows, 1)
 	require.Nil(t, resp.Data.Flows[0].TraceID)
 }
+
+// attackGraphQuerier serves the toolcalls of the attack graph loader
+type attackGraphQuerier struct {
+	database.Querier
+	toolcalls []database.Toolcall
+}
+
+func (q attackGraphQuerier) GetFlowToolcalls(_ context.Context, _ int64) ([]database.Toolcall, error) {
+	return q.toolcalls, nil
+}
+
+func TestGetFlowAttackGraph(t *testing.T) {
+	db := setupFlowFileServiceTestDB(t)
+	require.NoError(t, db.Exec(`
+		INSERT INTO flows (
+			id, user_id, model, model_provider_name, model_provider_type,
+			tool_call_id_template
+		) VALUES (1, 42, 'gpt', 'openai', 'openai', 'tcid')
+	`).Error)
+
+	loader := attackgraph.NewLoader(attackGraphQuerier{toolcalls: []database.Toolcall{{
+		ID:     7,
+		Name:   "terminal",
+		Status: database.ToolcallStatusFinished,
+		Args:   json.RawMessage(`{"input":"nmap 10.0.0.5"}`),
+		Result: "Nmap scan report for 10.0.0.5\n80/tcp open http",
+		FlowID: 1,
+	}}}, &config.Config{}, nil)
+	svc := NewFlowService(db, loader, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		target   string
+		privs    []string
+		uid      uint64
+		wantCode int
+		wantType string
+		wantBody string
+	}{
+		{"json by default", "/flows/1/attack-graph", []string{"toolcalls.view"}, 42,
+			http.StatusOK, "application/json", `"id": "host:10.0.0.5"`},
+		{"dot", "/flows/1/attack-graph?format=dot", []string{"toolcalls.view"}, 42,
+			http.StatusOK, "text/vnd.graphviz", `"start" -> "host:10.0.0.5"`},
+		{"admin sees other users flows", "/flows/1/attack-graph", []string{"toolcalls.admin"}, 7,
+			http.StatusOK, "application/json", `"flow_id": 1`},
+		{"unknown format", "/flows/1/attack-graph?format=svg", []string{"toolcalls.view"}, 42,
+			http.StatusBadRequest, "", ""},
+		{"flow of another user", "/flows/1/attack-graph", []string{"toolcalls.view"}, 7,
+			http.StatusNotFound, "", ""},
+		{"no toolcalls privilege", "/flows/1/attack-graph", []string{"flows.view"}, 42,
+			http.StatusForbidden, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newFlowFileTestContext(http.MethodGet, tt.target, nil, tt.privs, tt.uid, 1)
+			svc.GetFlowAttackGraph(c)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			require.Contains(t, w.Header().Get("Content-Type"), tt.wantType)
+			require.Contains(t, w.Header().Get("Content-Disposition"), "flow-1-attack-graph.")
+			require.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func seedProjectFlow(t *testing.T, db *gorm.DB, id, userID, projectID uint64, members map[uint64]string) {
+	t.Helper()
+
+	require.NoError(t, db.Exec(`
+		INSERT INTO flows (
+			id, user_id, model, model_provider_name, model_provider_type,
+			tool_call_id_template, project_id
+		) VALUES (?, ?, 'gpt', 'openai', 'openai', 'tcid', ?)
+	`, id, userID, projectID).Error)
+	for uid, role := range members {
+		require.NoError(t, db.Exec(
+			`INSERT INTO project_members (project_id, user_id, role) VALUES (?, ?, ?)`,
+			projectID, uid, role,
+		).Error)
+	}
+}
+
+func TestUserFlowScope(t *testing.T) {
+	db := setupFlowFileServiceTestDB(t)
+	seedFlow(t, db, 1, 42)
+	seedProjectFlow(t, db, 2, 42, 5, map[uint64]string{7: "viewer", 8: "editor", 9: "owner"})
+
+	tests := []struct {
+		name string
+		uid  uint64
+		perm string
+		want []uint64
+	}{
+		{"owner sees own flows", 42, "flows.view", []uint64{1, 2}},
+		{"owner deletes own flows", 42, "flows.delete", []uint64{1, 2}},
+		{"viewer sees project flow", 7, "flows.view", []uint64{2}},
+		{"viewer can not edit", 7, "flows.edit", nil},
+		{"editor edits project flow", 8, "flows.edit", []uint64{2}},
+		{"editor can not delete", 8, "flows.delete", nil},
+		{"project owner deletes project flow", 9, "flows.delete", []uint64{2}},
+		{"outsider sees nothing", 3, "flows.view", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []uint64
+			err := db.Model(&models.Flow{}).
+				Scopes(userFlowScope("flows", tt.uid, tt.perm)).
+				Order("id").
+				Pluck("id", &ids).Error
+			require.NoError(t, err)
+			require.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestGetFlowSharedThroughProject(t *testing.T) {
+	db := setupFlowFileServiceTestDB(t)
+	seedProjectFlow(t, db, 1, 42, 5, map[uint64]string{7: "viewer"})
+	svc := NewFlowService(db, nil, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		uid      uint64
+		wantCode int
+	}{
+		{"owner", 42, http.StatusOK},
+		{"project member", 7, http.StatusOK},
+		{"outsider", 3, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newFlowFileTestContext(http.MethodGet, "/flows/1", nil, []string{"flows.view"}, tt.uid, 1)
+			svc.GetFlow(c)
+
+			require.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
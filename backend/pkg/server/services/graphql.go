@@ -15,9 +15,11 @@ import (
 	"pentagi/pkg/graph"
 	"pentagi/pkg/graph/subscriptions"
 	"pentagi/pkg/providers"
+	"pentagi/pkg/server/audit"
 	"pentagi/pkg/server/auth"
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/templates"
+	"pentagi/pkg/webhooks"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -51,27 +53,34 @@ type originValidator struct {
 
 func NewGraphqlService(
 	db *database.Queries,
+	inTx database.TxRunner,
 	cfg *config.Config,
 	baseURL string,
 	origins []string,
 	tokenCache *auth.TokenCache,
+	userCache *auth.UserCache,
 	providers providers.ProviderController,
 	controller controller.FlowController,
 	subscriptions subscriptions.SubscriptionsController,
 	knowledgeStore knowledge.KnowledgeStore,
 	replacer anonymizer.Replacer,
+	recorder *audit.Recorder,
+	dispatcher *webhooks.Dispatcher,
 ) *GraphqlService {
 	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{
 		DB:              db,
+		InTx:            inTx,
 		Config:          cfg,
 		Logger:          logrus.StandardLogger().WithField("component", "pentagi-gql-bl"),
 		TokenCache:      tokenCache,
+		UserCache:       userCache,
 		DefaultPrompter: templates.NewDefaultPrompter(),
 		ProvidersCtrl:   providers,
 		Controller:      controller,
 		Subscriptions:   subscriptions,
 		Knowledge:       knowledgeStore,
 		Replacer:        replacer,
+		Webhooks:        dispatcher,
 	}}))
 
 	component := "pentagi-gql"
@@ -92,6 +101,9 @@ func NewGraphqlService(
 		Cache: lru.New[string](100),
 	})
 	srv.Use(extension.FixedComplexityLimit(20000))
+	if recorder != nil {
+		srv.Use(recorder.GraphqlExtension())
+	}
 
 	ov := newOriginValidator(origins)
 
@@ -147,6 +159,8 @@ func (s *GraphqlService) ServeGraphql(c *gin.Context) {
 	ctx = graph.SetUserID(ctx, uid)
 	ctx = graph.SetUserType(ctx, tid)
 	ctx = graph.SetUserPermissions(ctx, privs)
+	ctx = graph.SetTokenScope(ctx, auth.GetTokenScope(c))
+	ctx = audit.WithActor(ctx, audit.ActorFromGin(c))
 	c.Request = c.Request.WithContext(ctx)
 
 	s.srv.ServeHTTP(c.Writer, c.Request)
@@ -1,11 +1,18 @@
 package services
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	knowledgepkg "pentagi/pkg/database/knowledge"
+	"pentagi/pkg/database/knowledge/ingest"
 	gqlmodel "pentagi/pkg/graph/model"
 	"pentagi/pkg/server/auth"
 	"pentagi/pkg/server/logger"
@@ -99,17 +106,38 @@ type knowledgeGrouped struct {
 //   - knowledge.edit   → update own document (admin: any)
 //   - knowledge.delete → delete own document (admin: any)
 //   - knowledge.search → semantic search (own docs for regular users)
+//   - knowledge.create → bulk import of files and archives (own jobs for regular users)
+//   - knowledge.view   → export own documents to a knowledge archive (admin: any)
+//   - knowledge.create → import a knowledge archive exported by another instance
 type KnowledgeService struct {
-	db    *gorm.DB
-	store knowledgepkg.KnowledgeStore
+	db             *gorm.DB
+	store          knowledgepkg.KnowledgeStore
+	instance       knowledgepkg.Instance
+	importMaxBytes int64
+	importDefaults ingest.Options
 }
 
 // NewKnowledgeService creates a KnowledgeService.
 // db is the gorm connection used for paginated list queries via rdb.TableQuery.
 // store may be nil when the embedding provider is not configured; in that
 // case embedding-dependent endpoints return 503.
-func NewKnowledgeService(db *gorm.DB, store knowledgepkg.KnowledgeStore) *KnowledgeService {
-	return &KnowledgeService{db: db, store: store}
+// importMaxBytes limits both the upload and the uncompressed size of an import;
+// importDefaults holds the server-wide chunking options of imports;
+// instance identifies this installation in knowledge archives.
+func NewKnowledgeService(
+	db *gorm.DB,
+	store knowledgepkg.KnowledgeStore,
+	instance knowledgepkg.Instance,
+	importMaxBytes int64,
+	importDefaults ingest.Options,
+) *KnowledgeService {
+	return &KnowledgeService{
+		db:             db,
+		store:          store,
+		instance:       instance,
+		importMaxBytes: importMaxBytes,
+		importDefaults: importDefaults,
+	}
 }
 
 func isKnowledgeAdmin(c *gin.Context) bool {
@@ -503,6 +531,331 @@ func (s *KnowledgeService) DeleteDocument(c *gin.Context) {
 	response.Success(c, http.StatusOK, gin.H{"message": "knowledge document deleted successfully"})
 }
 
+// ---- Bulk import -------------------------------------------------------------
+
+// importFormOverhead is added to the upload limit for multipart boundaries and form fields.
+const importFormOverhead = 1 << 20
+
+// ImportDocuments starts a background import of uploaded Markdown, HTML, plain
+// text, JSONL, source files and zip archives into the knowledge store.
+// Progress is reported by the knowledgeImportJobUpdated subscription and the
+// import job endpoints.
+//
+// @Summary Import knowledge documents
+// @Tags Knowledge
+// @Accept mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param files formData file true "Files or zip archives to import"
+// @Param splitter formData string false "Chunking strategy" Enums(auto, markdown, recursive, none)
+// @Param chunk_size formData int false "Maximum chunk size in characters"
+// @Param chunk_overlap formData int false "Overlap between chunks in characters"
+// @Param doc_type formData string false "Document type for all chunks" Enums(answer, guide, code)
+// @Param guide_type formData string false "Guide type for all guide chunks"
+// @Param answer_type formData string false "Answer type for all answer chunks"
+// @Param code_lang formData string false "Code language for all code chunks"
+// @Success 202 {object} response.successResp{data=models.KnowledgeImportJobEntry}
+// @Failure 400 {object} response.errorResp "invalid request or upload too large"
+// @Failure 403 {object} response.errorResp "not permitted"
+// @Failure 503 {object} response.errorResp "embedding provider not configured"
+// @Failure 500 {object} response.errorResp "internal error"
+// @Router /knowledge/import [post]
+func (s *KnowledgeService) ImportDocuments(c *gin.Context) {
+	uid := int64(c.GetUint64("uid"))
+
+	if s.importMaxBytes > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, s.importMaxBytes+importFormOverhead)
+	}
+	multipartForm, err := c.MultipartForm()
+	if err != nil {
+		logger.FromContext(c).WithError(err).Error("error reading import multipart form")
+		response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+		return
+	}
+
+	var req models.KnowledgeImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		logger.FromContext(c).WithError(err).Error("error binding import request")
+		response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+		return
+	}
+	if err := req.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Error("invalid import request")
+		response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+		return
+	}
+
+	fileHeaders := multipartForm.File["files"]
+	if len(fileHeaders) == 0 {
+		fileHeaders = multipartForm.File["file"]
+	}
+	if len(fileHeaders) == 0 {
+		response.Error(c, response.ErrKnowledgeInvalidRequest, errors.New("at least one uploaded file is required"))
+		return
+	}
+
+	files := make([]ingest.File, 0, len(fileHeaders))
+	names := make([]string, 0, len(fileHeaders))
+	for _, fileHeader := range fileHeaders {
+		data, err := readUploadedFile(fileHeader)
+		if err != nil {
+			logger.FromContext(c).WithError(err).Errorf("error reading uploaded file %s", fileHeader.Filename)
+			response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+			return
+		}
+		files = append(files, ingest.File{Path: fileHeader.Filename, Data: data})
+		names = append(names, fileHeader.Filename)
+	}
+
+	files, skipped, err := ingest.ExpandArchives(files, s.importMaxBytes)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Error("error expanding import archives")
+		response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+		return
+	}
+
+	job, err := s.store.StartImport(c.Request.Context(), uid, knowledgepkg.ImportRequest{
+		Source:       strings.Join(names, ", "),
+		Files:        files,
+		SkippedFiles: skipped,
+		Options:      req.ToOptions(s.importDefaults),
+	})
+	if err != nil {
+		if isKnowledgeStoreUnavailable(err) {
+			response.Error(c, response.ErrKnowledgeStoreUnavail, err)
+			return
+		}
+		if strings.Contains(err.Error(), "knowledge: invalid import options") ||
+			strings.Contains(err.Error(), "knowledge: nothing to import") {
+			response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+			return
+		}
+		logger.FromContext(c).WithError(err).Error("error starting knowledge import")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, models.KnowledgeImportJobFromGQL(job))
+}
+
+// ListImportJobs returns knowledge import jobs, newest first.
+// Admin sees all jobs; regular users see only their own.
+//
+// @Summary List knowledge import jobs
+// @Tags Knowledge
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.successResp{data=models.KnowledgeImportJobList}
+// @Failure 403 {object} response.errorResp "not permitted"
+// @Failure 500 {object} response.errorResp "internal error"
+// @Router /knowledge/import/ [get]
+func (s *KnowledgeService) ListImportJobs(c *gin.Context) {
+	uid := int64(c.GetUint64("uid"))
+	ctx := c.Request.Context()
+
+	var (
+		jobs []*gqlmodel.KnowledgeImportJob
+		err  error
+	)
+	if isKnowledgeAdmin(c) {
+		jobs, err = s.store.ListImportJobs(ctx)
+	} else {
+		jobs, err = s.store.ListUserImportJobs(ctx, uid)
+	}
+	if err != nil {
+		logger.FromContext(c).WithError(err).Error("error listing knowledge import jobs")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, models.KnowledgeImportJobListFromGQL(jobs))
+}
+
+// GetImportJob returns a single knowledge import job.
+//
+// @Summary Get knowledge import job
+// @Tags Knowledge
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Import job ID" minimum(0)
+// @Success 200 {object} response.successResp{data=models.KnowledgeImportJobEntry}
+// @Failure 400 {object} response.errorResp "invalid job id"
+// @Failure 403 {object} response.errorResp "not permitted or wrong owner"
+// @Failure 404 {object} response.errorResp "import job not found"
+// @Router /knowledge/import/{id} [get]
+func (s *KnowledgeService) GetImportJob(c *gin.Context) {
+	uid := int64(c.GetUint64("uid"))
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var job *gqlmodel.KnowledgeImportJob
+	if isKnowledgeAdmin(c) {
+		job, err = s.store.GetImportJob(ctx, id)
+	} else {
+		job, err = s.store.GetUserImportJob(ctx, uid, id)
+	}
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error getting knowledge import job %d", id)
+		response.Error(c, response.ErrKnowledgeNotFound, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, models.KnowledgeImportJobFromGQL(job))
+}
+
+// ---- Archives ----------------------------------------------------------------
+
+// ExportDocuments streams a versioned zip archive of the filtered documents,
+// optionally with their embeddings, for import on another instance.
+// Admin exports documents of all users; regular users export their own.
+//
+// @Summary Export knowledge archive
+// @Tags Knowledge
+// @Produce application/zip
+// @Security BearerAuth
+// @Param request query models.KnowledgeExportQuery false "export filter"
+// @Success 200 {file} file "knowledge archive"
+// @Failure 400 {object} response.errorResp "invalid query parameters"
+// @Failure 403 {object} response.errorResp "not permitted"
+// @Failure 500 {object} response.errorResp "internal error"
+// @Router /knowledge/export [get]
+func (s *KnowledgeService) ExportDocuments(c *gin.Context) {
+	uid := int64(c.GetUint64("uid"))
+
+	var query models.KnowledgeExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		logger.FromContext(c).WithError(err).Error("error binding export query")
+		response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+		return
+	}
+	if err := query.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Error("invalid export query")
+		response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+		return
+	}
+
+	req := knowledgepkg.ExportRequest{
+		Filter:         query.ToGQLFilter(),
+		WithEmbeddings: query.WithEmbeddings,
+		Instance:       s.instance,
+	}
+
+	// the archive is buffered so that failures still produce a JSON error
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	ctx := c.Request.Context()
+	if isKnowledgeAdmin(c) {
+		_, err = s.store.ExportDocuments(ctx, &buf, req)
+	} else {
+		_, err = s.store.ExportUserDocuments(ctx, uid, &buf, req)
+	}
+	if err != nil {
+		if isKnowledgeStoreUnavailable(err) {
+			response.Error(c, response.ErrKnowledgeStoreUnavail, err)
+			return
+		}
+		logger.FromContext(c).WithError(err).Error("error exporting knowledge archive")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	filename := fmt.Sprintf("pentagi-knowledge-%s.zip", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// ImportArchive starts a background import of a knowledge archive exported by
+// another instance. Documents are re-embedded when the archive carries no
+// embeddings or was produced by a different embedding model; collisions with
+// existing documents are resolved by the conflict policy.
+//
+// @Summary Import knowledge archive
+// @Tags Knowledge
+// @Accept mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Knowledge archive"
+// @Param conflict formData string false "Conflict policy" Enums(skip, overwrite, duplicate)
+// @Success 202 {object} response.successResp{data=models.KnowledgeImportJobEntry}
+// @Failure 400 {object} response.errorResp "invalid archive or upload too large"
+// @Failure 403 {object} response.errorResp "not permitted"
+// @Failure 503 {object} response.errorResp "embedding provider not configured"
+// @Failure 500 {object} response.errorResp "internal error"
+// @Router /knowledge/import/archive [post]
+func (s *KnowledgeService) ImportArchive(c *gin.Context) {
+	uid := int64(c.GetUint64("uid"))
+
+	if s.importMaxBytes > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, s.importMaxBytes+importFormOverhead)
+	}
+
+	var req models.KnowledgeArchiveImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		logger.FromContext(c).WithError(err).Error("error binding archive import request")
+		response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+		return
+	}
+	if err := req.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Error("invalid archive import request")
+		response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, response.ErrKnowledgeInvalidRequest, errors.New("an uploaded archive is required"))
+		return
+	}
+	data, err := readUploadedFile(fileHeader)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error reading uploaded archive %s", fileHeader.Filename)
+		response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+		return
+	}
+
+	job, err := s.store.StartArchiveImport(c.Request.Context(), uid, knowledgepkg.ArchiveImportRequest{
+		Source:   fileHeader.Filename,
+		Data:     data,
+		Conflict: knowledgepkg.ConflictPolicy(req.Conflict),
+		Instance: s.instance,
+		MaxBytes: s.importMaxBytes,
+	})
+	if err != nil {
+		if isKnowledgeStoreUnavailable(err) {
+			response.Error(c, response.ErrKnowledgeStoreUnavail, err)
+			return
+		}
+		if errors.Is(err, knowledgepkg.ErrInvalidArchive) ||
+			strings.Contains(err.Error(), "knowledge: invalid import options") {
+			response.Error(c, response.ErrKnowledgeInvalidRequest, err)
+			return
+		}
+		logger.FromContext(c).WithError(err).Error("error starting knowledge archive import")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, models.KnowledgeImportJobFromGQL(job))
+}
+
+func readUploadedFile(fileHeader *multipart.FileHeader) ([]byte, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
 // ---- error classification ---------------------------------------------------
 
 // isKnowledgeStoreUnavailable returns true when the error originates from a
@@ -39,6 +39,12 @@ var msglogsSQLMappers = map[string]any{
 	"data":          "({{table}}.type || ' ' || {{table}}.message || ' ' || {{table}}.thinking || ' ' || {{table}}.result)",
 }
 
+var msglogsColumns = rdb.LogColumns{
+	Kind:     "msglogs.type::text",
+	Document: "search_document(msglogs.message, msglogs.thinking, msglogs.result)",
+	Tasks:    true,
+}
+
 type MsglogService struct {
 	db *gorm.DB
 }
@@ -54,7 +60,7 @@ func NewMsglogService(db *gorm.DB) *MsglogService {
 // @Tags Msglogs
 // @Produce json
 // @Security BearerAuth
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=msglogs} "msglogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting msglogs not permitted"
@@ -63,7 +69,7 @@ func NewMsglogService(db *gorm.DB) *MsglogService {
 func (s *MsglogService) GetMsglogs(c *gin.Context) {
 	var (
 		err   error
-		query rdb.TableQuery
+		query rdb.LogQuery
 		resp  msglogs
 	)
 
@@ -85,7 +91,7 @@ func (s *MsglogService) GetMsglogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = msglogs.flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "msglogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -93,7 +99,11 @@ func (s *MsglogService) GetMsglogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("msglogs", msglogsSQLMappers)
+	if err = query.Init("msglogs", msglogsSQLMappers, msglogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrMsglogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := msglogsSQLMappers[query.Group]; !ok {
@@ -136,7 +146,7 @@ func (s *MsglogService) GetMsglogs(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param flowID path int true "flow id" minimum(0)
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=msglogs} "msglogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting msglogs not permitted"
@@ -146,7 +156,7 @@ func (s *MsglogService) GetFlowMsglogs(c *gin.Context) {
 	var (
 		err    error
 		flowID uint64
-		query  rdb.TableQuery
+		query  rdb.LogQuery
 		resp   msglogs
 	)
 
@@ -175,7 +185,7 @@ func (s *MsglogService) GetFlowMsglogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = msglogs.flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "msglogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -183,7 +193,11 @@ func (s *MsglogService) GetFlowMsglogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("msglogs", msglogsSQLMappers)
+	if err = query.Init("msglogs", msglogsSQLMappers, msglogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrMsglogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := msglogsSQLMappers[query.Group]; !ok {
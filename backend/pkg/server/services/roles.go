@@ -2,10 +2,12 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"slices"
 	"strconv"
 
+	"pentagi/pkg/server/auth"
 	"pentagi/pkg/server/logger"
 	"pentagi/pkg/server/models"
 	"pentagi/pkg/server/rdb"
@@ -27,12 +29,16 @@ var rolesSQLMappers = map[string]any{
 }
 
 type RoleService struct {
-	db *gorm.DB
+	db         *gorm.DB
+	userCache  *auth.UserCache
+	tokenCache *auth.TokenCache
 }
 
-func NewRoleService(db *gorm.DB) *RoleService {
+func NewRoleService(db *gorm.DB, userCache *auth.UserCache, tokenCache *auth.TokenCache) *RoleService {
 	return &RoleService{
-		db: db,
+		db:         db,
+		userCache:  userCache,
+		tokenCache: tokenCache,
 	}
 }
 
@@ -162,3 +168,398 @@ func (s *RoleService) GetRole(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, resp)
 }
+
+// CreateRole is a function to create new role with privileges
+// @Summary Create new role
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Param json body models.RoleInput true "role model to create"
+// @Success 201 {object} response.successResp{data=models.RolePrivileges} "role created successful"
+// @Failure 400 {object} response.errorResp "invalid role request data"
+// @Failure 403 {object} response.errorResp "creating role not permitted"
+// @Failure 409 {object} response.errorResp "role name already exists"
+// @Failure 500 {object} response.errorResp "internal error on creating role"
+// @Router /roles/ [post]
+func (s *RoleService) CreateRole(c *gin.Context) {
+	var (
+		err   error
+		input models.RoleInput
+		resp  models.RolePrivileges
+	)
+
+	if !slices.Contains(c.GetStringSlice("prm"), auth.PrivilegeRolesEdit) {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if input, err = s.bindRoleInput(c); err != nil {
+		return
+	}
+
+	if err = s.checkRoleName(c, 0, input.Name); err != nil {
+		return
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		logger.FromContext(c).WithError(tx.Error).Errorf("error starting transaction")
+		response.Error(c, response.ErrInternal, tx.Error)
+		return
+	}
+
+	role := models.Role{Name: input.Name}
+	if err = tx.Create(&role).Error; err != nil {
+		tx.Rollback()
+		logger.FromContext(c).WithError(err).Errorf("error creating role")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if err = setRolePrivileges(tx, role.ID, input.Privileges); err != nil {
+		tx.Rollback()
+		logger.FromContext(c).WithError(err).Errorf("error setting role privileges")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error committing transaction")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if resp, err = s.getRolePrivileges(role.ID); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding created role '%d'", role.ID)
+		response.Error(c, response.ErrRolesInvalidData, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, resp)
+}
+
+// UpdateRole is a function to update role name and replace its privileges
+// @Summary Update role
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Param roleID path uint64 true "role id"
+// @Param json body models.RoleInput true "role model to update"
+// @Success 200 {object} response.successResp{data=models.RolePrivileges} "role updated successful"
+// @Failure 400 {object} response.errorResp "invalid role request data"
+// @Failure 403 {object} response.errorResp "updating role not permitted"
+// @Failure 404 {object} response.errorResp "role not found"
+// @Failure 409 {object} response.errorResp "role name already exists"
+// @Failure 500 {object} response.errorResp "internal error on updating role"
+// @Router /roles/{roleID} [put]
+func (s *RoleService) UpdateRole(c *gin.Context) {
+	var (
+		err   error
+		input models.RoleInput
+		resp  models.RolePrivileges
+		role  models.Role
+	)
+
+	if !slices.Contains(c.GetStringSlice("prm"), auth.PrivilegeRolesEdit) {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if role, err = s.findRole(c); err != nil {
+		return
+	}
+
+	// the admin role keeps every privilege, new ones are granted to it by migrations
+	if role.ID == models.RoleAdmin {
+		logger.FromContext(c).Errorf("error updating built-in role '%d'", role.ID)
+		response.Error(c, response.ErrRolesBuiltIn, nil)
+		return
+	}
+
+	if input, err = s.bindRoleInput(c); err != nil {
+		return
+	}
+
+	if role.ID == models.RoleUser && input.Name != role.Name {
+		logger.FromContext(c).Errorf("error renaming built-in role '%d'", role.ID)
+		response.Error(c, response.ErrRolesBuiltIn, nil)
+		return
+	}
+
+	if err = s.checkRoleName(c, role.ID, input.Name); err != nil {
+		return
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		logger.FromContext(c).WithError(tx.Error).Errorf("error starting transaction")
+		response.Error(c, response.ErrInternal, tx.Error)
+		return
+	}
+
+	if err = tx.Model(&role).Update("name", input.Name).Error; err != nil {
+		tx.Rollback()
+		logger.FromContext(c).WithError(err).Errorf("error updating role '%d'", role.ID)
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if err = setRolePrivileges(tx, role.ID, input.Privileges); err != nil {
+		tx.Rollback()
+		logger.FromContext(c).WithError(err).Errorf("error setting role privileges")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error committing transaction")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	s.invalidateRole(c, role.ID)
+
+	if resp, err = s.getRolePrivileges(role.ID); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding updated role '%d'", role.ID)
+		response.Error(c, response.ErrRolesInvalidData, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, resp)
+}
+
+// DeleteRole is a function to delete role which is not assigned to anyone
+// @Summary Delete role
+// @Tags Roles
+// @Produce json
+// @Param roleID path uint64 true "role id"
+// @Success 200 {object} response.successResp "role deleted successful"
+// @Failure 400 {object} response.errorResp "invalid role request data"
+// @Failure 403 {object} response.errorResp "deleting role not permitted"
+// @Failure 404 {object} response.errorResp "role not found"
+// @Failure 409 {object} response.errorResp "role is assigned to users or tokens"
+// @Failure 500 {object} response.errorResp "internal error on deleting role"
+// @Router /roles/{roleID} [delete]
+func (s *RoleService) DeleteRole(c *gin.Context) {
+	var (
+		err    error
+		role   models.Role
+		users  uint64
+		tokens uint64
+	)
+
+	if !slices.Contains(c.GetStringSlice("prm"), auth.PrivilegeRolesEdit) {
+		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
+		response.Error(c, response.ErrNotPermitted, nil)
+		return
+	}
+
+	if role, err = s.findRole(c); err != nil {
+		return
+	}
+
+	if role.ID == models.RoleAdmin || role.ID == models.RoleUser {
+		logger.FromContext(c).Errorf("error deleting built-in role '%d'", role.ID)
+		response.Error(c, response.ErrRolesBuiltIn, nil)
+		return
+	}
+
+	// revoked and deleted tokens still reference the role
+	if err = s.db.Model(&models.User{}).Where("role_id = ?", role.ID).Count(&users).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error counting role users")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	if err = s.db.Table("api_tokens").Where("role_id = ?", role.ID).Count(&tokens).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error counting role tokens")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+	if users != 0 || tokens != 0 {
+		logger.FromContext(c).Errorf("error deleting role '%d' assigned to %d users and %d tokens", role.ID, users, tokens)
+		response.Error(c, response.ErrRolesInUse, nil)
+		return
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		logger.FromContext(c).WithError(tx.Error).Errorf("error starting transaction")
+		response.Error(c, response.ErrInternal, tx.Error)
+		return
+	}
+
+	if err = tx.Where("role_id = ?", role.ID).Delete(&models.Privilege{}).Error; err != nil {
+		tx.Rollback()
+		logger.FromContext(c).WithError(err).Errorf("error deleting role privileges")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if err = tx.Delete(&role).Error; err != nil {
+		tx.Rollback()
+		logger.FromContext(c).WithError(err).Errorf("error deleting role '%d'", role.ID)
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error committing transaction")
+		response.Error(c, response.ErrInternal, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, struct{}{})
+}
+
+// GetPrivileges is a function to return the catalog of privileges
+// @Summary Retrieve privileges which can be granted to roles
+// @Tags Roles
+// @Produce json
+// @Success 200 {object} response.successResp{data=[]string} "privileges list received successful"
+// @Failure 403 {object} response.errorResp "getting privileges not permitted"
+// @Router /roles/privileges [get]
+func (s *RoleService) GetPrivileges(c *gin.Context) {
+	response.Success(c, http.StatusOK, auth.KnownPrivileges())
+}
+
+// bindRoleInput reads the role from the request body and checks its privileges
+// against the catalog and against the privileges of the current user, so that
+// nobody can grant what they don't have
+func (s *RoleService) bindRoleInput(c *gin.Context) (models.RoleInput, error) {
+	var input models.RoleInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error binding JSON")
+		response.Error(c, response.ErrRolesInvalidRequest, err)
+		return input, err
+	}
+	if err := input.Valid(); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating role")
+		response.Error(c, response.ErrRolesInvalidRequest, err)
+		return input, err
+	}
+
+	privs, err := auth.ValidatePrivileges(input.Privileges)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error validating role privileges")
+		response.Error(c, response.ErrRolesInvalidPrivileges, err)
+		return input, err
+	}
+	input.Privileges = privs
+
+	prms := c.GetStringSlice("prm")
+	for _, priv := range privs {
+		if !slices.Contains(prms, priv) {
+			err = fmt.Errorf("privilege '%s' is not granted to the current user", priv)
+			logger.FromContext(c).WithError(err).Errorf("error checking role privileges")
+			response.Error(c, response.ErrNotPermitted, err)
+			return input, err
+		}
+	}
+
+	return input, nil
+}
+
+func (s *RoleService) findRole(c *gin.Context) (models.Role, error) {
+	var role models.Role
+
+	roleID, err := strconv.ParseUint(c.Param("roleID"), 10, 64)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error parsing role id")
+		response.Error(c, response.ErrRolesInvalidRequest, err)
+		return role, err
+	}
+
+	if err = s.db.Take(&role, "id = ?", roleID).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding role by id")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, response.ErrRolesNotFound, err)
+		} else {
+			response.Error(c, response.ErrInternal, err)
+		}
+		return role, err
+	}
+
+	return role, nil
+}
+
+func (s *RoleService) checkRoleName(c *gin.Context, roleID uint64, name string) error {
+	var count uint64
+
+	if err := s.db.Model(&models.Role{}).Where("name = ? AND id <> ?", name, roleID).Count(&count).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error checking role name")
+		response.Error(c, response.ErrInternal, err)
+		return err
+	}
+	if count != 0 {
+		err := fmt.Errorf("role '%s' already exists", name)
+		logger.FromContext(c).WithError(err).Errorf("error checking role name")
+		response.Error(c, response.ErrRolesNameExists, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *RoleService) getRolePrivileges(roleID uint64) (models.RolePrivileges, error) {
+	var resp models.RolePrivileges
+
+	if err := s.db.Take(&resp, "id = ?", roleID).Error; err != nil {
+		return resp, err
+	}
+	if err := s.db.Model(&resp).Association("privileges").Find(&resp.Privileges).Error; err != nil {
+		return resp, err
+	}
+
+	return resp, resp.Valid()
+}
+
+// invalidateRole drops the cached users and tokens so that the new privileges
+// apply to API tokens at once, session cookies pick them up on the next refresh
+func (s *RoleService) invalidateRole(c *gin.Context, roleID uint64) {
+	var uids []uint64
+
+	if err := s.db.Model(&models.User{}).Where("role_id = ?", roleID).Pluck("id", &uids).Error; err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error finding role users, invalidating all of them")
+		s.userCache.InvalidateAll()
+	} else {
+		for _, uid := range uids {
+			s.userCache.Invalidate(uid)
+		}
+	}
+
+	// token cache entries are keyed by token id and carry the role privileges
+	s.tokenCache.InvalidateAll()
+}
+
+// setRolePrivileges replaces the privileges of the role keeping the rows
+// which are still granted
+func setRolePrivileges(tx *gorm.DB, roleID uint64, privs []string) error {
+	var current []string
+
+	if err := tx.Model(&models.Privilege{}).Where("role_id = ?", roleID).Pluck("name", &current).Error; err != nil {
+		return err
+	}
+
+	for _, name := range current {
+		if slices.Contains(privs, name) {
+			continue
+		}
+		if err := tx.Where("role_id = ? AND name = ?", roleID, name).Delete(&models.Privilege{}).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, name := range privs {
+		if slices.Contains(current, name) {
+			continue
+		}
+		if err := tx.Create(&models.Privilege{RoleID: roleID, Name: name}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"pentagi/pkg/server/auth"
+	"pentagi/pkg/server/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var roleEditorPrivileges = []string{"roles.edit", "roles.view", "flows.create", "flows.view", "flows.edit"}
+
+func newTestRoleService(db *gorm.DB) *RoleService {
+	return NewRoleService(db, auth.NewUserCache(db), auth.NewTokenCache(db))
+}
+
+func callRoleHandler(t *testing.T, handler gin.HandlerFunc, prms []string, roleID string, body any) (int, []byte) {
+	t.Helper()
+
+	c, w := setupTestContext(1, 1, "testhash1", prms)
+	if roleID != "" {
+		c.Params = gin.Params{{Key: "roleID", Value: roleID}}
+	}
+
+	var reader *bytes.Buffer
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewBuffer(data)
+	} else {
+		reader = bytes.NewBuffer(nil)
+	}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/roles/", reader)
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler(c)
+
+	return w.Code, w.Body.Bytes()
+}
+
+func rolePrivilegeNames(t *testing.T, db *gorm.DB, roleID uint64) []string {
+	t.Helper()
+
+	var names []string
+	require.NoError(t, db.Model(&models.Privilege{}).Where("role_id = ?", roleID).Order("name").Pluck("name", &names).Error)
+	return names
+}
+
+func TestRoleService_CreateRole(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service := newTestRoleService(db)
+
+	testCases := []struct {
+		name         string
+		prms         []string
+		input        models.RoleInput
+		expectedCode int
+	}{
+		{
+			name:         "valid role",
+			prms:         roleEditorPrivileges,
+			input:        models.RoleInput{Name: "Auditor", Privileges: []string{"flows.view", "flows.view", "roles.view"}},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "missing roles.edit",
+			prms:         []string{"roles.view", "flows.view"},
+			input:        models.RoleInput{Name: "Viewer", Privileges: []string{"flows.view"}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "unknown privilege",
+			prms:         roleEditorPrivileges,
+			input:        models.RoleInput{Name: "Broken", Privileges: []string{"flows.destroy"}},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "privilege not held by the editor",
+			prms:         roleEditorPrivileges,
+			input:        models.RoleInput{Name: "Operator", Privileges: []string{"flows.view", "flows.delete"}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "duplicate name",
+			prms:         roleEditorPrivileges,
+			input:        models.RoleInput{Name: "User", Privileges: []string{"flows.view"}},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "empty name",
+			prms:         roleEditorPrivileges,
+			input:        models.RoleInput{Privileges: []string{"flows.view"}},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, body := callRoleHandler(t, service.CreateRole, tc.prms, "", tc.input)
+			assert.Equal(t, tc.expectedCode, code, string(body))
+		})
+	}
+
+	var role models.Role
+	require.NoError(t, db.Take(&role, "name = ?", "Auditor").Error)
+	assert.Equal(t, []string{"flows.view", "roles.view"}, rolePrivilegeNames(t, db, role.ID))
+
+	var count int
+	require.NoError(t, db.Model(&models.Role{}).Count(&count).Error)
+	assert.Equal(t, 3, count, "only the valid role is created")
+}
+
+func TestRoleService_UpdateRole(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	db.Exec("INSERT INTO roles (id, name) VALUES (3, 'Operator')")
+	db.Exec("INSERT INTO privileges (role_id, name) VALUES (3, 'flows.view'), (3, 'flows.delete')")
+	db.Exec("INSERT INTO api_tokens (token_id, user_id, role_id, ttl, status) VALUES ('optoken001', 1, 3, 3600, 'active')")
+
+	service := newTestRoleService(db)
+
+	// warm up the cache to check it is dropped on update
+	_, privs, err := service.tokenCache.GetStatus("optoken001")
+	require.NoError(t, err)
+	assert.Contains(t, privs, "flows.delete")
+
+	input := models.RoleInput{Name: "Flow Operator", Privileges: []string{"flows.view", "flows.edit"}}
+	code, body := callRoleHandler(t, service.UpdateRole, roleEditorPrivileges, "3", input)
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	var role models.Role
+	require.NoError(t, db.Take(&role, "id = ?", 3).Error)
+	assert.Equal(t, "Flow Operator", role.Name)
+	assert.Equal(t, []string{"flows.edit", "flows.view"}, rolePrivilegeNames(t, db, 3))
+
+	_, privs, err = service.tokenCache.GetStatus("optoken001")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"flows.edit", "flows.view", auth.PrivilegeAutomation}, privs)
+
+	t.Run("admin role is read-only", func(t *testing.T) {
+		input := models.RoleInput{Name: "Admin", Privileges: []string{"flows.view"}}
+		code, _ := callRoleHandler(t, service.UpdateRole, roleEditorPrivileges, "1", input)
+		assert.Equal(t, http.StatusForbidden, code)
+		assert.Contains(t, rolePrivilegeNames(t, db, 1), "users.create")
+	})
+
+	t.Run("user role can't be renamed", func(t *testing.T) {
+		input := models.RoleInput{Name: "Member", Privileges: []string{"flows.view"}}
+		code, _ := callRoleHandler(t, service.UpdateRole, roleEditorPrivileges, "2", input)
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("user role privileges can be changed", func(t *testing.T) {
+		input := models.RoleInput{Name: "User", Privileges: []string{"flows.view", "roles.view"}}
+		code, body := callRoleHandler(t, service.UpdateRole, roleEditorPrivileges, "2", input)
+		assert.Equal(t, http.StatusOK, code, string(body))
+		assert.Equal(t, []string{"flows.view", "roles.view"}, rolePrivilegeNames(t, db, 2))
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		code, _ := callRoleHandler(t, service.UpdateRole, roleEditorPrivileges, "42", input)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
+
+func TestRoleService_DeleteRole(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	db.Exec("INSERT INTO roles (id, name) VALUES (3, 'Auditor'), (4, 'Operator')")
+	db.Exec("INSERT INTO privileges (role_id, name) VALUES (3, 'flows.view'), (4, 'flows.view')")
+	db.Exec("INSERT INTO api_tokens (token_id, user_id, role_id, ttl, status) VALUES ('optoken001', 1, 4, 3600, 'revoked')")
+
+	service := newTestRoleService(db)
+
+	testCases := []struct {
+		name         string
+		prms         []string
+		roleID       string
+		expectedCode int
+	}{
+		{"missing roles.edit", []string{"roles.view"}, "3", http.StatusForbidden},
+		{"built-in admin", roleEditorPrivileges, "1", http.StatusForbidden},
+		{"built-in user", roleEditorPrivileges, "2", http.StatusForbidden},
+		{"referenced by a token", roleEditorPrivileges, "4", http.StatusConflict},
+		{"unknown role", roleEditorPrivileges, "42", http.StatusNotFound},
+		{"unused role", roleEditorPrivileges, "3", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, body := callRoleHandler(t, service.DeleteRole, tc.prms, tc.roleID, nil)
+			assert.Equal(t, tc.expectedCode, code, string(body))
+		})
+	}
+
+	assert.True(t, db.Take(&models.Role{}, "id = ?", 3).RecordNotFound())
+	assert.Empty(t, rolePrivilegeNames(t, db, 3))
+	assert.Equal(t, []string{"flows.view"}, rolePrivilegeNames(t, db, 4))
+}
@@ -86,7 +86,7 @@ func (s *ScreenshotService) GetScreenshots(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = screenshots.flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "screenshots.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -176,7 +176,7 @@ func (s *ScreenshotService) GetFlowScreenshots(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = screenshots.flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "screenshots.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -261,7 +261,7 @@ func (s *ScreenshotService) GetFlowScreenshot(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "screenshots.view") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("f.id = ? AND f.user_id = ?", flowID, uid)
+			return db.Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "screenshots.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -326,7 +326,8 @@ func (s *ScreenshotService) GetFlowScreenshotFile(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "screenshots.download") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("f.id = ? AND f.user_id = ?", flowID, uid)
+			// a download only reads what project viewers already see
+			return db.Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "screenshots.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -39,6 +39,12 @@ var searchlogsSQLMappers = map[string]any{
 	"data":       "({{table}}.query || ' ' || {{table}}.result)",
 }
 
+var searchlogsColumns = rdb.LogColumns{
+	Kind:     "searchlogs.executor::text",
+	Document: "search_document(searchlogs.query, searchlogs.result)",
+	Tasks:    true,
+}
+
 type SearchlogService struct {
 	db *gorm.DB
 }
@@ -54,7 +60,7 @@ func NewSearchlogService(db *gorm.DB) *SearchlogService {
 // @Tags Searchlogs
 // @Produce json
 // @Security BearerAuth
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=searchlogs} "searchlogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting searchlogs not permitted"
@@ -63,7 +69,7 @@ func NewSearchlogService(db *gorm.DB) *SearchlogService {
 func (s *SearchlogService) GetSearchlogs(c *gin.Context) {
 	var (
 		err   error
-		query rdb.TableQuery
+		query rdb.LogQuery
 		resp  searchlogs
 	)
 
@@ -85,7 +91,7 @@ func (s *SearchlogService) GetSearchlogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "searchlogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -93,7 +99,11 @@ func (s *SearchlogService) GetSearchlogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("searchlogs", searchlogsSQLMappers)
+	if err = query.Init("searchlogs", searchlogsSQLMappers, searchlogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrSearchlogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := searchlogsSQLMappers[query.Group]; !ok {
@@ -136,7 +146,7 @@ func (s *SearchlogService) GetSearchlogs(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param flowID path int true "flow id" minimum(0)
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=searchlogs} "searchlogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting searchlogs not permitted"
@@ -146,7 +156,7 @@ func (s *SearchlogService) GetFlowSearchlogs(c *gin.Context) {
 	var (
 		err    error
 		flowID uint64
-		query  rdb.TableQuery
+		query  rdb.LogQuery
 		resp   searchlogs
 	)
 
@@ -175,7 +185,7 @@ func (s *SearchlogService) GetFlowSearchlogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "searchlogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -183,7 +193,11 @@ func (s *SearchlogService) GetFlowSearchlogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("searchlogs", searchlogsSQLMappers)
+	if err = query.Init("searchlogs", searchlogsSQLMappers, searchlogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrSearchlogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := searchlogsSQLMappers[query.Group]; !ok {
@@ -95,7 +95,7 @@ func (s *SubtaskService) GetFlowSubtasks(c *gin.Context) {
 			return db.
 				Joins("INNER JOIN tasks t ON t.id = subtasks.task_id").
 				Joins("INNER JOIN flows f ON f.id = t.flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "subtasks.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -195,7 +195,7 @@ func (s *SubtaskService) GetFlowTaskSubtasks(c *gin.Context) {
 			return db.
 				Joins("INNER JOIN tasks t ON t.id = subtasks.task_id").
 				Joins("INNER JOIN flows f ON f.id = t.flow_id").
-				Where("f.id = ? AND f.user_id = ? AND t.id = ?", flowID, uid, taskID)
+				Where("f.id = ? AND t.id = ?", flowID, taskID).Scopes(userFlowScope("f", uid, "subtasks.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -295,7 +295,7 @@ func (s *SubtaskService) GetFlowTaskSubtask(c *gin.Context) {
 			return db.
 				Joins("INNER JOIN tasks t ON t.id = subtasks.task_id").
 				Joins("INNER JOIN flows f ON f.id = t.flow_id").
-				Where("f.id = ? AND f.user_id = ? AND t.id = ?", flowID, uid, taskID)
+				Where("f.id = ? AND t.id = ?", flowID, taskID).Scopes(userFlowScope("f", uid, "subtasks.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -92,7 +92,7 @@ func (s *TaskService) GetFlowTasks(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = tasks.flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "tasks.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -182,7 +182,7 @@ func (s *TaskService) GetFlowTask(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = tasks.flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "tasks.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -249,7 +249,7 @@ func (s *TaskService) GetFlowTaskGraph(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "tasks.view") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("f.id = ? AND f.user_id = ?", flowID, uid)
+			return db.Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "tasks.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
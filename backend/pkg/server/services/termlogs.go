@@ -37,6 +37,12 @@ var termlogsSQLMappers = map[string]any{
 	"data":         "({{table}}.type || ' ' || {{table}}.text)",
 }
 
+var termlogsColumns = rdb.LogColumns{
+	Kind:     "termlogs.type::text",
+	Document: "search_document(termlogs.text)",
+	Tasks:    true,
+}
+
 type TermlogService struct {
 	db *gorm.DB
 }
@@ -52,7 +58,7 @@ func NewTermlogService(db *gorm.DB) *TermlogService {
 // @Tags Termlogs
 // @Produce json
 // @Security BearerAuth
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=termlogs} "termlogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting termlogs not permitted"
@@ -61,7 +67,7 @@ func NewTermlogService(db *gorm.DB) *TermlogService {
 func (s *TermlogService) GetTermlogs(c *gin.Context) {
 	var (
 		err   error
-		query rdb.TableQuery
+		query rdb.LogQuery
 		resp  termlogs
 	)
 
@@ -83,7 +89,7 @@ func (s *TermlogService) GetTermlogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "termlogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -91,7 +97,11 @@ func (s *TermlogService) GetTermlogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("termlogs", termlogsSQLMappers)
+	if err = query.Init("termlogs", termlogsSQLMappers, termlogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrTermlogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := termlogsSQLMappers[query.Group]; !ok {
@@ -134,7 +144,7 @@ func (s *TermlogService) GetTermlogs(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param flowID path int true "flow id" minimum(0)
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=termlogs} "termlogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting termlogs not permitted"
@@ -144,7 +154,7 @@ func (s *TermlogService) GetFlowTermlogs(c *gin.Context) {
 	var (
 		err    error
 		flowID uint64
-		query  rdb.TableQuery
+		query  rdb.LogQuery
 		resp   termlogs
 	)
 
@@ -173,7 +183,7 @@ func (s *TermlogService) GetFlowTermlogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "termlogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -181,7 +191,11 @@ func (s *TermlogService) GetFlowTermlogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("termlogs", termlogsSQLMappers)
+	if err = query.Init("termlogs", termlogsSQLMappers, termlogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrTermlogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := termlogsSQLMappers[query.Group]; !ok {
@@ -41,6 +41,12 @@ var toolcallsSQLMappers = map[string]any{
 	"data":             "({{table}}.name || ' ' || {{table}}.call_id || ' ' || {{table}}.args::text || ' ' || {{table}}.result)",
 }
 
+var toolcallsColumns = rdb.LogColumns{
+	Kind:     "toolcalls.name",
+	Document: "search_document(toolcalls.name, toolcalls.args::text, toolcalls.result)",
+	Tasks:    true,
+}
+
 type ToolcallService struct {
 	db *gorm.DB
 }
@@ -56,7 +62,7 @@ func NewToolcallService(db *gorm.DB) *ToolcallService {
 // @Tags Toolcalls
 // @Produce json
 // @Security BearerAuth
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=toolcalls} "toolcalls list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting toolcalls not permitted"
@@ -65,7 +71,7 @@ func NewToolcallService(db *gorm.DB) *ToolcallService {
 func (s *ToolcallService) GetToolcalls(c *gin.Context) {
 	var (
 		err   error
-		query rdb.TableQuery
+		query rdb.LogQuery
 		resp  toolcalls
 	)
 
@@ -87,7 +93,7 @@ func (s *ToolcallService) GetToolcalls(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = toolcalls.flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "toolcalls.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -95,7 +101,11 @@ func (s *ToolcallService) GetToolcalls(c *gin.Context) {
 		return
 	}
 
-	query.Init("toolcalls", toolcallsSQLMappers)
+	if err = query.Init("toolcalls", toolcallsSQLMappers, toolcallsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrToolcallsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := toolcallsSQLMappers[query.Group]; !ok {
@@ -138,7 +148,7 @@ func (s *ToolcallService) GetToolcalls(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param flowID path int true "flow id" minimum(0)
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=toolcalls} "toolcalls list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting toolcalls not permitted"
@@ -148,7 +158,7 @@ func (s *ToolcallService) GetFlowToolcalls(c *gin.Context) {
 	var (
 		err    error
 		flowID uint64
-		query  rdb.TableQuery
+		query  rdb.LogQuery
 		resp   toolcalls
 	)
 
@@ -177,7 +187,7 @@ func (s *ToolcallService) GetFlowToolcalls(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = toolcalls.flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "toolcalls.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -185,7 +195,11 @@ func (s *ToolcallService) GetFlowToolcalls(c *gin.Context) {
 		return
 	}
 
-	query.Init("toolcalls", toolcallsSQLMappers)
+	if err = query.Init("toolcalls", toolcallsSQLMappers, toolcallsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrToolcallsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := toolcallsSQLMappers[query.Group]; !ok {
@@ -263,7 +277,7 @@ func (s *ToolcallService) GetFlowToolcall(c *gin.Context) {
 		}
 	} else if slices.Contains(privs, "toolcalls.view") {
 		scope = func(db *gorm.DB) *gorm.DB {
-			return db.Where("f.id = ? AND f.user_id = ?", flowID, uid)
+			return db.Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "toolcalls.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -40,6 +40,12 @@ var vecstorelogsSQLMappers = map[string]any{
 	"data":       "({{table}}.filter || ' ' || {{table}}.query || ' ' || {{table}}.result)",
 }
 
+var vecstorelogsColumns = rdb.LogColumns{
+	Kind:     "vecstorelogs.executor::text",
+	Document: "search_document(vecstorelogs.query, vecstorelogs.result)",
+	Tasks:    true,
+}
+
 type VecstorelogService struct {
 	db *gorm.DB
 }
@@ -55,7 +61,7 @@ func NewVecstorelogService(db *gorm.DB) *VecstorelogService {
 // @Tags Vecstorelogs
 // @Produce json
 // @Security BearerAuth
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=vecstorelogs} "vecstorelogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting vecstorelogs not permitted"
@@ -64,7 +70,7 @@ func NewVecstorelogService(db *gorm.DB) *VecstorelogService {
 func (s *VecstorelogService) GetVecstorelogs(c *gin.Context) {
 	var (
 		err   error
-		query rdb.TableQuery
+		query rdb.LogQuery
 		resp  vecstorelogs
 	)
 
@@ -86,7 +92,7 @@ func (s *VecstorelogService) GetVecstorelogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = flow_id").
-				Where("f.user_id = ?", uid)
+				Scopes(userFlowScope("f", uid, "vecstorelogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -94,7 +100,11 @@ func (s *VecstorelogService) GetVecstorelogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("vecstorelogs", vecstorelogsSQLMappers)
+	if err = query.Init("vecstorelogs", vecstorelogsSQLMappers, vecstorelogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrVecstorelogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := vecstorelogsSQLMappers[query.Group]; !ok {
@@ -137,7 +147,7 @@ func (s *VecstorelogService) GetVecstorelogs(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param flowID path int true "flow id" minimum(0)
-// @Param request query rdb.TableQuery true "query table params"
+// @Param request query rdb.LogQuery true "query table params"
 // @Success 200 {object} response.successResp{data=vecstorelogs} "vecstorelogs list received successful"
 // @Failure 400 {object} response.errorResp "invalid query request data"
 // @Failure 403 {object} response.errorResp "getting vecstorelogs not permitted"
@@ -147,7 +157,7 @@ func (s *VecstorelogService) GetFlowVecstorelogs(c *gin.Context) {
 	var (
 		err    error
 		flowID uint64
-		query  rdb.TableQuery
+		query  rdb.LogQuery
 		resp   vecstorelogs
 	)
 
@@ -176,7 +186,7 @@ func (s *VecstorelogService) GetFlowVecstorelogs(c *gin.Context) {
 		scope = func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN flows f ON f.id = flow_id").
-				Where("f.id = ? AND f.user_id = ?", flowID, uid)
+				Where("f.id = ?", flowID).Scopes(userFlowScope("f", uid, "vecstorelogs.view"))
 		}
 	} else {
 		logger.FromContext(c).Errorf("error filtering user role permissions: permission not found")
@@ -184,7 +194,11 @@ func (s *VecstorelogService) GetFlowVecstorelogs(c *gin.Context) {
 		return
 	}
 
-	query.Init("vecstorelogs", vecstorelogsSQLMappers)
+	if err = query.Init("vecstorelogs", vecstorelogsSQLMappers, vecstorelogsColumns); err != nil {
+		logger.FromContext(c).WithError(err).Errorf("error initializing query")
+		response.Error(c, response.ErrVecstorelogsInvalidRequest, err)
+		return
+	}
 
 	if query.Group != "" {
 		if _, ok := vecstorelogsSQLMappers[query.Group]; !ok {
@@ -39,14 +39,15 @@ type FileAction struct {
 type BrowserAction = String
 
 const (
-	Markdown BrowserAction = "markdown"
-	HTML     BrowserAction = "html"
-	Links    BrowserAction = "links"
+	Markdown   BrowserAction = "markdown"
+	HTML       BrowserAction = "html"
+	Links      BrowserAction = "links"
+	Screenshot BrowserAction = "screenshot"
 )
 
 type Browser struct {
 	Url     string        `json:"url" jsonschema:"required" jsonschema_description:"URL to open in the browser"`
-	Action  BrowserAction `json:"action" jsonschema:"required,type=string,enum=markdown,enum=html,enum=links" jsonschema_description:"Action to perform in the browser. 'markdown' - Returns the content of the page in markdown format. 'html' - Returns the content of the page in html format. 'links' - Get the list of all URLs on the page to be used in later calls (e.g., open search results after the initial search lookup)."`
+	Action  BrowserAction `json:"action" jsonschema:"required,type=string,enum=markdown,enum=html,enum=links,enum=screenshot" jsonschema_description:"Action to perform in the browser. 'markdown' - Returns the content of the page in markdown format. 'html' - Returns the content of the page in html format. 'links' - Get the list of all URLs on the page to be used in later calls (e.g., open search results after the initial search lookup). 'screenshot' - Shows the rendered page as an image to inspect its visual layout (login forms, CAPTCHAs, dashboards, rendered error pages); models without image input receive a text-only view of the page instead."`
 	Message string        `json:"message" jsonschema:"required,title=Browser action message" jsonschema_description:"Engagement-log entry — a 1-2 short sentence running commentary describing what content you are fetching, in which format, and why. Written in the engagement language declared by your system prompt."`
 }
 
@@ -202,6 +203,8 @@ type SearchGuideAction struct {
 	Questions Strings `json:"questions" jsonschema:"required,type=array,minItems=1,maxItems=5" jsonschema_description:"Technical-channel payload — 1 to 5 detailed, context-rich semantic queries for the team's guide vector store. Must be a real JSON array of strings, e.g. [\"query 1\",\"query 2\"] - NOT a JSON-encoded string containing an array. ALWAYS written in English regardless of the engagement language: the store is indexed in English and shared across all engagements, so non-English queries will fail to retrieve relevant guides. Each query should include scenario context, objectives, and specific intent. Note: The 'Type' field acts as a strict filter."`
 	Type      String  `json:"type" jsonschema:"required,type=string,enum=install,enum=configure,enum=use,enum=pentest,enum=development,enum=other" jsonschema_description:"The specific type of guide you need. This required field acts as a strict filter to enhance the relevance of search results by narrowing down the scope to the specified guide type."`
 	Message   string  `json:"message" jsonschema:"required,title=Guide search message" jsonschema_description:"Engagement-log entry — a 1-2 short sentence running commentary summarizing the queries and the type of guide needed. Written in the engagement language declared by your system prompt."`
+	Useful    Strings `json:"useful,omitempty" jsonschema:"type=array" jsonschema_description:"Optional feedback — IDs of documents returned by your earlier searches that turned out to be correct and helpful. Improves future rankings; leave empty when you have nothing to report."`
+	NotUseful Strings `json:"not_useful,omitempty" jsonschema:"type=array" jsonschema_description:"Optional feedback — IDs of documents returned by your earlier searches that were wrong, outdated or irrelevant. Lowers their future rankings; leave empty when you have nothing to report."`
 }
 
 type StoreGuideAction struct {
@@ -215,6 +218,8 @@ type SearchAnswerAction struct {
 	Questions Strings `json:"questions" jsonschema:"required,type=array,minItems=1,maxItems=5" jsonschema_description:"Technical-channel payload — 1 to 5 detailed, context-rich semantic queries for the team's answer vector store. Must be a real JSON array of strings, e.g. [\"query 1\",\"query 2\"] - NOT a JSON-encoded string containing an array. ALWAYS written in English regardless of the engagement language: the store is indexed in English and shared across all engagements, so non-English queries will fail to retrieve relevant answers. Each query should include the context, what you want to find, what you intend to do with the information, and why you need it. Note: The 'Type' field acts as a strict filter."`
 	Type      String  `json:"type" jsonschema:"required,type=string,enum=guide,enum=vulnerability,enum=code,enum=tool,enum=other" jsonschema_description:"The specific type of information or answer you are seeking. This required field acts as a strict filter to enhance the relevance of search results by narrowing down the scope to the specified type."`
 	Message   string  `json:"message" jsonschema:"required,title=Answer search message" jsonschema_description:"Engagement-log entry — a 1-2 short sentence running commentary summarizing the queries and the type of answer needed. Written in the engagement language declared by your system prompt."`
+	Useful    Strings `json:"useful,omitempty" jsonschema:"type=array" jsonschema_description:"Optional feedback — IDs of documents returned by your earlier searches that turned out to be correct and helpful. Improves future rankings; leave empty when you have nothing to report."`
+	NotUseful Strings `json:"not_useful,omitempty" jsonschema:"type=array" jsonschema_description:"Optional feedback — IDs of documents returned by your earlier searches that were wrong, outdated or irrelevant. Lowers their future rankings; leave empty when you have nothing to report."`
 }
 
 type StoreAnswerAction struct {
@@ -228,6 +233,8 @@ type SearchCodeAction struct {
 	Questions Strings `json:"questions" jsonschema:"required,type=array,minItems=1,maxItems=5" jsonschema_description:"Technical-channel payload — 1 to 5 detailed, context-rich semantic queries for the team's code vector store. Must be a real JSON array of strings, e.g. [\"query 1\",\"query 2\"] - NOT a JSON-encoded string containing an array. ALWAYS written in English regardless of the engagement language: the store is indexed in English and shared across all engagements, so non-English queries will fail to retrieve relevant code samples. Each query should include the context, what you intend to achieve with the code, and the functionality or content that should be included."`
 	Lang      string  `json:"lang" jsonschema:"required" jsonschema_description:"The programming language of the code samples you need. Use the standard markdown code block language name (e.g., 'python', 'bash', 'golang'). This required field narrows down the search to code samples in the desired language."`
 	Message   string  `json:"message" jsonschema:"required,title=Code search message" jsonschema_description:"Engagement-log entry — a 1-2 short sentence running commentary summarizing the queries and the programming language of the code samples. Written in the engagement language declared by your system prompt."`
+	Useful    Strings `json:"useful,omitempty" jsonschema:"type=array" jsonschema_description:"Optional feedback — IDs of documents returned by your earlier searches that turned out to be correct and helpful. Improves future rankings; leave empty when you have nothing to report."`
+	NotUseful Strings `json:"not_useful,omitempty" jsonschema:"type=array" jsonschema_description:"Optional feedback — IDs of documents returned by your earlier searches that were wrong, outdated or irrelevant. Lowers their future rankings; leave empty when you have nothing to report."`
 }
 
 type StoreCodeAction struct {
@@ -269,6 +276,19 @@ type HackResult struct {
 	Message string `json:"message" jsonschema:"required,title=Hack result message" jsonschema_description:"Engagement-log entry — a 1-2 short sentence running commentary with the result and the path to reach the goal. Written in the engagement language declared by your system prompt."`
 }
 
+type StoreCredentialAction struct {
+	Type       String `json:"type" jsonschema:"required,type=string,enum=password,enum=hash,enum=token,enum=key,enum=other" jsonschema_description:"Kind of the secret: 'password' for a plaintext password, 'hash' for a password hash, 'token' for API keys, session cookies and bearer tokens, 'key' for private keys, 'other' for anything else"`
+	Username   string `json:"username" jsonschema_description:"Login the secret belongs to, empty when it has none (e.g. an API token)"`
+	Secret     string `json:"secret" jsonschema:"required" jsonschema_description:"The secret exactly as found or given, verbatim; it is encrypted at rest and replaced with its placeholder in every log"`
+	SourceHost string `json:"source_host" jsonschema_description:"Host, service or URL the credential is valid for, e.g. '10.0.0.5', 'ssh://10.0.0.5:22' or 'https://app.corp.local'"`
+	Message    string `json:"message" jsonschema:"required,title=Store credential message" jsonschema_description:"Engagement-log entry — a 1-2 short sentence running commentary on which credential you are storing and where it comes from, never the secret itself. Written in the engagement language declared by your system prompt."`
+}
+
+type ListCredentialsAction struct {
+	Reveal  Bool   `json:"reveal" jsonschema:"type=boolean" jsonschema_description:"Set to true ONLY when the raw secrets are needed outside terminal commands, e.g. to reason about a password pattern; terminal commands take the placeholders as they are"`
+	Message string `json:"message" jsonschema:"required,title=List credentials message" jsonschema_description:"Engagement-log entry — a 1-2 short sentence running commentary on why you need the stored credentials. Written in the engagement language declared by your system prompt."`
+}
+
 // FlowStatusDetail controls the level of detail returned by get_flow_status.
 // It is a type alias for String - see the FileOp comment above.
 type FlowStatusDetail = String
@@ -19,6 +19,8 @@ import (
 	"pentagi/pkg/observability/langfuse"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 )
 
 const (
@@ -26,6 +28,12 @@ const (
 	minHtmlContentSize       = 300
 	minImgContentSize        = 2048
 	maxScraperErrorBodyBytes = 512
+
+	// maxVisionImageSize keeps a screenshot below the strictest provider limit
+	// for inline images (5 MB after base64 encoding); larger images fall back
+	// to the text-only view
+	maxVisionImageSize = 3 << 20
+	maxTextViewSize    = 16 * 1024
 )
 
 // nonHTMLExtensions lists URL path suffixes that point to resources the scraper
@@ -178,6 +186,9 @@ func (b *browser) Handle(ctx context.Context, name string, args json.RawMessage)
 	case Links:
 		result, screen, err := b.Links(ctx, action.Url)
 		return b.wrapCommandResult(ctx, name, result, action.Url, screen, err)
+	case Screenshot:
+		result, screen, err := b.Screenshot(ctx, action.Url)
+		return b.wrapCommandResult(ctx, name, result, action.Url, screen, err)
 	default:
 		logger.Error("unknown browser action")
 		return "", fmt.Errorf("unknown browser action: %s", action.Action)
@@ -298,6 +309,65 @@ func (b *browser) Links(ctx context.Context, url string) (string, string, error)
 	return links, screenshotName, nil
 }
 
+// Screenshot captures the visible part of the page for the agent itself. With a
+// vision-capable model the image is queued as an attachment of the next model
+// call; otherwise, or when the image cannot be attached, the page is described
+// by its text and image alt texts.
+func (b *browser) Screenshot(ctx context.Context, url string) (string, string, error) {
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(b.flowID, b.taskID, b.subtaskID, logrus.Fields{
+		"tool":   "browser",
+		"action": "screenshot",
+		"url":    url,
+	}))
+	logger.Debug("trying to get screenshot for the agent")
+
+	if isBinaryURL(url) {
+		return "", "", fmt.Errorf(
+			"the URL appears to point to a binary/non-HTML resource (e.g. PDF, image, archive) " +
+				"that cannot be rendered as a page. Use the terminal tool with curl/wget to download it instead",
+		)
+	}
+
+	screenshot, err := b.captureScreenshot(url, false)
+	if err != nil {
+		return "", "", err
+	}
+
+	screenshotName, err := b.saveScreenshotData(screenshot)
+	if err != nil {
+		logger.WithError(err).Warn("failed to save screenshot, continuing without it")
+		screenshotName = ""
+	}
+
+	if GetVisionSupport(ctx) && len(screenshot) <= maxVisionImageSize {
+		attached := AddAttachment(ctx, Attachment{
+			Name:        screenshotName,
+			MimeType:    http.DetectContentType(screenshot),
+			Data:        screenshot,
+			Description: fmt.Sprintf("Screenshot of '%s'", url),
+		})
+		if attached {
+			return fmt.Sprintf(
+				"Screenshot of the visible part of '%s' is attached as an image right after the tool results. "+
+					"It is shown only once, so note down everything you need from it.", url,
+			), screenshotName, nil
+		}
+	}
+
+	logger.Debug("model has no vision support, falling back to text-only view")
+
+	content, err := b.getHTML(url)
+	if err != nil {
+		return "", "", err
+	}
+
+	return fmt.Sprintf(
+		"Text-only view of '%s' (the screenshot cannot be shown to the current model, "+
+			"visible text and image alt texts are listed instead):\n\n%s",
+		url, htmlTextView(content),
+	), screenshotName, nil
+}
+
 func (b *browser) resolveUrl(targetURL string) (*url.URL, error) {
 	u, err := url.Parse(targetURL)
 	if err != nil {
@@ -480,26 +550,37 @@ func (b *browser) getLinks(targetURL string) (string, error) {
 }
 
 func (b *browser) getScreenshot(targetURL string) (string, error) {
+	content, err := b.captureScreenshot(targetURL, true)
+	if err != nil {
+		return "", err
+	}
+
+	return b.saveScreenshotData(content)
+}
+
+func (b *browser) captureScreenshot(targetURL string, fullPage bool) ([]byte, error) {
 	scraperURL, err := b.resolveUrl(targetURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve url: %w", err)
+		return nil, fmt.Errorf("failed to resolve url: %w", err)
 	}
 
 	query := scraperURL.Query()
-	query.Add("fullPage", "true")
+	if fullPage {
+		query.Add("fullPage", "true")
+	}
 	query.Add("url", targetURL)
 	scraperURL.Path = "/screenshot"
 	scraperURL.RawQuery = query.Encode()
 
 	content, err := b.callScraper(scraperURL.String())
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch screenshot by url '%s': %w", targetURL, err)
+		return nil, fmt.Errorf("failed to fetch screenshot by url '%s': %w", targetURL, err)
 	}
 	if len(content) < minImgContentSize {
-		return "", fmt.Errorf("image size is less than minimum: %d bytes", minImgContentSize)
+		return nil, fmt.Errorf("image size is less than minimum: %d bytes", minImgContentSize)
 	}
 
-	return b.saveScreenshotData(content)
+	return content, nil
 }
 
 func (b *browser) callScraper(url string) ([]byte, error) {
@@ -543,3 +624,96 @@ func (b *browser) callScraper(url string) ([]byte, error) {
 func (b *browser) IsAvailable() bool {
 	return b.scPrvURL != "" || b.scPubURL != ""
 }
+
+// htmlTextView renders the visible text of a page for models that cannot see
+// screenshots: the title, text blocks one per line, image alt texts and form
+// controls, truncated to maxTextViewSize.
+func htmlTextView(content string) string {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return content[:min(len(content), maxTextViewSize)]
+	}
+
+	var (
+		buffer strings.Builder
+		title  string
+		line   []string
+	)
+	flush := func() {
+		if len(line) > 0 {
+			buffer.WriteString(strings.Join(line, " "))
+			buffer.WriteString("\n")
+			line = line[:0]
+		}
+	}
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if buffer.Len() > maxTextViewSize {
+			return
+		}
+
+		switch node.Type {
+		case html.TextNode:
+			if text := strings.Join(strings.Fields(node.Data), " "); text != "" {
+				line = append(line, text)
+			}
+			return
+		case html.ElementNode:
+			switch node.DataAtom {
+			case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg:
+				return
+			case atom.Title:
+				if node.FirstChild != nil {
+					title = strings.TrimSpace(node.FirstChild.Data)
+				}
+				return
+			case atom.Img:
+				if alt := strings.TrimSpace(htmlAttr(node, "alt")); alt != "" {
+					line = append(line, fmt.Sprintf("[image: %s]", alt))
+				}
+				return
+			case atom.Input, atom.Select, atom.Textarea:
+				if htmlAttr(node, "type") == "hidden" {
+					return
+				}
+				control := []string{node.Data}
+				for _, attr := range []string{"type", "name", "placeholder", "value"} {
+					if value := strings.TrimSpace(htmlAttr(node, attr)); value != "" {
+						control = append(control, fmt.Sprintf("%s=%q", attr, value))
+					}
+				}
+				line = append(line, fmt.Sprintf("[%s]", strings.Join(control, " ")))
+			case atom.Br, atom.P, atom.Div, atom.Li, atom.Tr, atom.H1, atom.H2, atom.H3,
+				atom.H4, atom.H5, atom.H6, atom.Form, atom.Section, atom.Article, atom.Table:
+				flush()
+				defer flush()
+			}
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+	flush()
+
+	view := buffer.String()
+	if len(view) > maxTextViewSize {
+		view = view[:maxTextViewSize] + "\n... [truncated]"
+	}
+	if title != "" {
+		view = fmt.Sprintf("Title: %s\n\n%s", title, view)
+	}
+
+	return view
+}
+
+func htmlAttr(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -702,3 +702,85 @@ func TestWrapCommandResult_ErrorIsSwallowed(t *testing.T) {
 		t.Fatalf("PutScreenshot() should not be called on error branch, got %d calls", scp.calls)
 	}
 }
+
+func TestBrowserHandle_Screenshot(t *testing.T) {
+	ts := newTestScraper(t, "ok")
+	defer ts.Close()
+
+	newBrowser := func() (*browser, *screenshotProviderMock) {
+		scp := &screenshotProviderMock{}
+		return &browser{flowID: 1, dataDir: t.TempDir(), scPubURL: ts.URL, scp: scp}, scp
+	}
+	args := json.RawMessage(`{"url":"https://example.com/page","action":"screenshot","message":"m"}`)
+
+	t.Run("vision model gets image attachment", func(t *testing.T) {
+		b, scp := newBrowser()
+		ctx := PutAttachments(PutVisionSupport(t.Context(), true))
+
+		result, err := b.Handle(ctx, "browser", args)
+		if err != nil {
+			t.Fatalf("Handle() returned unexpected error: %v", err)
+		}
+		if !strings.Contains(result, "attached as an image") {
+			t.Errorf("Handle() = %q, want attachment notice", result)
+		}
+
+		attachments := PopAttachments(ctx)
+		if len(attachments) != 1 {
+			t.Fatalf("expected 1 attachment, got %d", len(attachments))
+		}
+		if len(attachments[0].Data) < minImgContentSize || attachments[0].MimeType == "" {
+			t.Errorf("unexpected attachment %q (%d bytes)", attachments[0].MimeType, len(attachments[0].Data))
+		}
+		if scp.calls != 1 {
+			t.Errorf("PutScreenshot() calls = %d, want 1", scp.calls)
+		}
+	})
+
+	t.Run("text-only model gets page text", func(t *testing.T) {
+		b, scp := newBrowser()
+		ctx := PutAttachments(t.Context())
+
+		result, err := b.Handle(ctx, "browser", args)
+		if err != nil {
+			t.Fatalf("Handle() returned unexpected error: %v", err)
+		}
+		if !strings.Contains(result, "Text-only view") {
+			t.Errorf("Handle() = %q, want text-only view", result)
+		}
+		if attachments := PopAttachments(ctx); len(attachments) != 0 {
+			t.Errorf("expected no attachments, got %d", len(attachments))
+		}
+		if scp.calls != 1 {
+			t.Errorf("PutScreenshot() calls = %d, want 1", scp.calls)
+		}
+	})
+}
+
+func TestHTMLTextView(t *testing.T) {
+	view := htmlTextView(`<html><head><title> Admin login </title><style>body{}</style></head>
+<body><script>var secret = 1;</script>
+<h1>Welcome</h1><p>Sign   in to
+continue</p>
+<img src="logo.png" alt="ACME logo"><img src="spacer.gif">
+<form><input type="text" name="user" placeholder="Username"><input type="hidden" name="csrf" value="x">
+<button>Log in</button></form></body></html>`)
+
+	for _, want := range []string{
+		"Title: Admin login",
+		"Welcome\n",
+		"Sign in to continue",
+		"[image: ACME logo]",
+		`[input type="text" name="user" placeholder="Username"]`,
+		"Log in",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("htmlTextView() missing %q in:\n%s", want, view)
+		}
+	}
+	for _, unwanted := range []string{"secret", "body{}", "csrf"} {
+		if strings.Contains(view, unwanted) {
+			t.Errorf("htmlTextView() should not contain %q:\n%s", unwanted, view)
+		}
+	}
+}
@@ -17,7 +17,6 @@ import (
 	"github.com/vxcontrol/cloud/anonymizer"
 	"github.com/vxcontrol/langchaingo/documentloaders"
 	"github.com/vxcontrol/langchaingo/schema"
-	"github.com/vxcontrol/langchaingo/vectorstores"
 	"github.com/vxcontrol/langchaingo/vectorstores/pgvector"
 )
 
@@ -38,6 +37,7 @@ type code struct {
 	embedder          embeddings.Embedder
 	db                database.Querier
 	maxEmbeddingBytes int
+	hybrid            database.HybridSearchConfig
 	vslp              VectorStoreLogProvider
 	knp               KnowledgeProvider
 }
@@ -51,6 +51,7 @@ func NewCodeTool(
 	embedder embeddings.Embedder,
 	db database.Querier,
 	maxEmbeddingBytes int,
+	hybrid database.HybridSearchConfig,
 	vslp VectorStoreLogProvider,
 	knp KnowledgeProvider,
 ) Tool {
@@ -64,6 +65,7 @@ func NewCodeTool(
 		embedder:          embedder,
 		db:                db,
 		maxEmbeddingBytes: maxEmbeddingBytes,
+		hybrid:            hybrid,
 		vslp:              vslp,
 		knp:               knp,
 	}
@@ -93,6 +95,8 @@ func (c *code) Handle(ctx context.Context, name string, args json.RawMessage) (s
 			return "", fmt.Errorf("failed to unmarshal %s search code action arguments: %w", name, err)
 		}
 
+		recordKnowledgeFeedback(ctx, c.db, action.Useful, action.NotUseful)
+
 		filters := map[string]any{
 			"doc_type":  codeVectorStoreDefaultType,
 			"code_lang": action.Lang,
@@ -133,12 +137,15 @@ func (c *code) Handle(ctx context.Context, name string, args json.RawMessage) (s
 				"query":       query[:min(len(query), 1000)],
 			})
 
-			docs, err := c.store.SimilaritySearch(
+			docs, err := hybridSimilaritySearch(
 				ctx,
+				c.store,
+				c.db,
+				c.hybrid,
 				query,
-				codeVectorStoreResultLimit,
-				vectorstores.WithScoreThreshold(codeVectorStoreThreshold),
-				vectorstores.WithFilters(filters),
+				c.hybrid.Candidates(codeVectorStoreResultLimit),
+				codeVectorStoreThreshold,
+				filters,
 			)
 			if err != nil {
 				queryLogger.WithError(err).Error("failed to search code samples for query")
@@ -153,7 +160,7 @@ func (c *code) Handle(ctx context.Context, name string, args json.RawMessage) (s
 			"total_docs_before_dedup": len(allDocs),
 		}).Debug("all queries completed")
 
-		docs := MergeAndDeduplicateDocs(allDocs, codeVectorStoreResultLimit)
+		docs := rankKnowledgeDocs(ctx, c.db, c.hybrid, allDocs, codeVectorStoreResultLimit)
 
 		logger.WithFields(logrus.Fields{
 			"docs_after_dedup": len(docs),
@@ -187,12 +194,14 @@ func (c *code) Handle(ctx context.Context, name string, args json.RawMessage) (s
 				langfuse.WithScoreFloatValue(float64(doc.Score)),
 			)
 			buffer.WriteString(fmt.Sprintf("# Document %d Match score: %f\n\n", i+1, doc.Score))
+			writeKnowledgeDocID(&buffer, doc)
 			buffer.WriteString(fmt.Sprintf("## Original Code Question\n\n%s\n\n", doc.Metadata["question"]))
 			buffer.WriteString(fmt.Sprintf("## Original Code Description\n\n%s\n\n", doc.Metadata["description"]))
 			buffer.WriteString("## Content\n\n")
 			buffer.WriteString(doc.PageContent)
 			buffer.WriteString("\n\n")
 		}
+		buffer.WriteString(knowledgeFeedbackHint)
 
 		if agentCtx, ok := GetAgentContext(ctx); ok {
 			filtersData, err := json.Marshal(filters)
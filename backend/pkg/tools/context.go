@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"sync"
 
 	"pentagi/pkg/database"
 )
@@ -32,3 +33,90 @@ func PutAgentContext(ctx context.Context, agent database.MsgchainType) context.C
 
 	return context.WithValue(ctx, agentContextKey, agentCtx)
 }
+
+type VisionContextKey int
+
+var visionContextKey VisionContextKey
+
+// PutVisionSupport records whether the model behind the current agent accepts
+// image parts, so tools can choose between an image and a text-only result.
+func PutVisionSupport(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, visionContextKey, enabled)
+}
+
+func GetVisionSupport(ctx context.Context) bool {
+	enabled, _ := ctx.Value(visionContextKey).(bool)
+	return enabled
+}
+
+type AttachmentsContextKey int
+
+var attachmentsContextKey AttachmentsContextKey
+
+// Attachment is binary content produced by a tool call for the model to see
+// next to the textual tool response, e.g. a browser screenshot.
+type Attachment struct {
+	Name        string
+	MimeType    string
+	Data        []byte
+	Description string
+}
+
+type attachments struct {
+	mx    sync.Mutex
+	items []Attachment
+}
+
+// PutAttachments installs an attachments collector for the tool calls of one
+// agent chain; nested agent chains install their own.
+func PutAttachments(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attachmentsContextKey, &attachments{})
+}
+
+// AddAttachment queues an attachment for the next model call of the current
+// agent chain. It returns false when the caller runs outside an agent chain.
+func AddAttachment(ctx context.Context, attachment Attachment) bool {
+	collector, ok := ctx.Value(attachmentsContextKey).(*attachments)
+	if !ok {
+		return false
+	}
+
+	collector.mx.Lock()
+	defer collector.mx.Unlock()
+
+	collector.items = append(collector.items, attachment)
+	return true
+}
+
+// PopAttachments returns and clears the attachments queued by tool calls.
+func PopAttachments(ctx context.Context) []Attachment {
+	collector, ok := ctx.Value(attachmentsContextKey).(*attachments)
+	if !ok {
+		return nil
+	}
+
+	collector.mx.Lock()
+	defer collector.mx.Unlock()
+
+	items := collector.items
+	collector.items = nil
+	return items
+}
+
+type ToolcallContextKey int
+
+var toolcallContextKey ToolcallContextKey
+
+// PutToolcallID records the toolcall a handler runs for, so the tool can link
+// what it stores to the call which produced it.
+func PutToolcallID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, toolcallContextKey, id)
+}
+
+func GetToolcallID(ctx context.Context) *int64 {
+	id, ok := ctx.Value(toolcallContextKey).(int64)
+	if !ok || id == 0 {
+		return nil
+	}
+	return &id
+}
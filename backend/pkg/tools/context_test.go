@@ -144,3 +144,45 @@ func TestGetAgentContextIgnoresOtherContextValues(t *testing.T) {
 		t.Error("GetAgentContext() should ignore unrelated context values")
 	}
 }
+
+func TestVisionSupport(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	if GetVisionSupport(ctx) {
+		t.Error("GetVisionSupport() on empty context should return false")
+	}
+	if !GetVisionSupport(PutVisionSupport(ctx, true)) {
+		t.Error("GetVisionSupport() should return true after PutVisionSupport(true)")
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	t.Parallel()
+
+	if AddAttachment(t.Context(), Attachment{Name: "a.png"}) {
+		t.Error("AddAttachment() without collector should return false")
+	}
+	if items := PopAttachments(t.Context()); items != nil {
+		t.Errorf("PopAttachments() without collector = %v, want nil", items)
+	}
+
+	ctx := PutAttachments(t.Context())
+	AddAttachment(ctx, Attachment{Name: "a.png"})
+	AddAttachment(ctx, Attachment{Name: "b.png"})
+
+	items := PopAttachments(ctx)
+	if len(items) != 2 || items[0].Name != "a.png" || items[1].Name != "b.png" {
+		t.Fatalf("PopAttachments() = %v, want a.png and b.png", items)
+	}
+	if items := PopAttachments(ctx); len(items) != 0 {
+		t.Errorf("PopAttachments() after pop = %v, want empty", items)
+	}
+
+	// nested agent chains collect their own attachments
+	nested := PutAttachments(ctx)
+	AddAttachment(nested, Attachment{Name: "c.png"})
+	if items := PopAttachments(ctx); len(items) != 0 {
+		t.Errorf("parent collector received nested attachments: %v", items)
+	}
+}
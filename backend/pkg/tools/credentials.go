@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"pentagi/pkg/database"
+	"pentagi/pkg/vault"
+
+	"github.com/sirupsen/logrus"
+)
+
+var credentialTypes = map[string]database.CredentialType{
+	string(database.CredentialTypePassword): database.CredentialTypePassword,
+	string(database.CredentialTypeHash):     database.CredentialTypeHash,
+	string(database.CredentialTypeToken):    database.CredentialTypeToken,
+	string(database.CredentialTypeKey):      database.CredentialTypeKey,
+	string(database.CredentialTypeOther):    database.CredentialTypeOther,
+}
+
+type credentials struct {
+	flowID    int64
+	taskID    *int64
+	subtaskID *int64
+	vault     *vault.Vault
+}
+
+func NewCredentialsTool(flowID int64, taskID, subtaskID *int64, credentialVault *vault.Vault) Tool {
+	return &credentials{
+		flowID:    flowID,
+		taskID:    taskID,
+		subtaskID: subtaskID,
+		vault:     credentialVault,
+	}
+}
+
+func (c *credentials) Handle(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if !c.IsAvailable() {
+		return "", vault.ErrNotAvailable
+	}
+
+	logger := logrus.WithContext(ctx).WithFields(enrichLogrusFields(c.flowID, c.taskID, c.subtaskID, logrus.Fields{
+		"tool": name,
+	}))
+
+	switch name {
+	case StoreCredentialToolName:
+		var action StoreCredentialAction
+		if err := json.Unmarshal(args, &action); err != nil {
+			logger.WithError(err).Error("failed to unmarshal store credential action")
+			return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+		}
+		return c.store(ctx, action)
+
+	case ListCredentialsToolName:
+		var action ListCredentialsAction
+		if err := json.Unmarshal(args, &action); err != nil {
+			logger.WithError(err).Error("failed to unmarshal list credentials action")
+			return "", fmt.Errorf("failed to unmarshal %s action arguments: %w", name, err)
+		}
+		return c.list(ctx, action.Reveal.Bool())
+
+	default:
+		logger.Error("unknown tool")
+		return "", fmt.Errorf("unknown tool: %s", name)
+	}
+}
+
+func (c *credentials) store(ctx context.Context, action StoreCredentialAction) (string, error) {
+	kind, ok := credentialTypes[strings.ToLower(action.Type.String())]
+	if !ok {
+		return "", fmt.Errorf("unknown credential type '%s': use password, hash, token, key or other", action.Type)
+	}
+	if action.Secret == "" {
+		return "", fmt.Errorf("secret must not be empty")
+	}
+
+	cred, err := c.vault.Store(ctx, c.flowID, vault.Credential{
+		Type:       kind,
+		Username:   strings.TrimSpace(action.Username),
+		Secret:     action.Secret,
+		SourceHost: strings.TrimSpace(action.SourceHost),
+		ToolcallID: GetToolcallID(ctx),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("credential stored as %s (%s); use the placeholder instead of the secret from now on",
+		vault.Placeholder(cred.ID), describeCredential(cred)), nil
+}
+
+func (c *credentials) list(ctx context.Context, reveal bool) (string, error) {
+	creds, err := c.vault.List(ctx, c.flowID)
+	if err != nil {
+		return "", err
+	}
+	if len(creds) == 0 {
+		return "no credentials are stored in this flow yet", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Stored credentials of this flow:\n")
+	for _, cred := range creds {
+		fmt.Fprintf(&sb, "- %s: %s", vault.Placeholder(cred.ID), describeCredential(cred))
+		if reveal {
+			fmt.Fprintf(&sb, ", secret: %s", cred.Secret)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
+
+func describeCredential(cred vault.Credential) string {
+	parts := []string{string(cred.Type)}
+	if cred.Username != "" {
+		parts = append(parts, "username "+cred.Username)
+	}
+	if cred.SourceHost != "" {
+		parts = append(parts, "for "+cred.SourceHost)
+	}
+	if cred.ToolcallID != nil {
+		parts = append(parts, fmt.Sprintf("found by toolcall %d", *cred.ToolcallID))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (c *credentials) IsAvailable() bool {
+	return c.vault.IsAvailable()
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/vault"
+)
+
+// credentialsQuerier keeps flow_credentials rows in memory
+type credentialsQuerier struct {
+	database.Querier
+	rows []database.FlowCredential
+}
+
+func (q *credentialsQuerier) UpsertFlowCredential(
+	_ context.Context, arg database.UpsertFlowCredentialParams,
+) (database.FlowCredential, error) {
+	row := database.FlowCredential{
+		ID:         int64(len(q.rows) + 1),
+		FlowID:     arg.FlowID,
+		Type:       arg.Type,
+		Username:   arg.Username,
+		Secret:     arg.Secret,
+		SourceHost: arg.SourceHost,
+		ToolcallID: arg.ToolcallID,
+	}
+	q.rows = append(q.rows, row)
+	return row, nil
+}
+
+func (q *credentialsQuerier) GetFlowCredentials(_ context.Context, flowID int64) ([]database.FlowCredential, error) {
+	var rows []database.FlowCredential
+	for _, row := range q.rows {
+		if row.FlowID == flowID {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func TestCredentialsTool(t *testing.T) {
+	t.Parallel()
+
+	credentialVault := vault.New(&credentialsQuerier{}, &config.Config{CredentialVaultKey: "tools-test-key"})
+	tool := NewCredentialsTool(7, nil, nil, credentialVault)
+	ctx := PutToolcallID(t.Context(), 42)
+
+	result, err := tool.Handle(ctx, StoreCredentialToolName, json.RawMessage(
+		`{"type":"password","username":"admin","secret":"Tools-S3cret","source_host":"10.0.0.5","message":"found"}`))
+	if err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	if !strings.Contains(result, vault.Placeholder(1)) || strings.Contains(result, "Tools-S3cret") {
+		t.Errorf("unexpected store result: %s", result)
+	}
+
+	result, err = tool.Handle(ctx, ListCredentialsToolName, json.RawMessage(`{"reveal":false,"message":"list"}`))
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if strings.Contains(result, "Tools-S3cret") {
+		t.Errorf("list must not reveal the secret: %s", result)
+	}
+	if !strings.Contains(result, "username admin, for 10.0.0.5, found by toolcall 42") {
+		t.Errorf("unexpected list result: %s", result)
+	}
+
+	result, err = tool.Handle(ctx, ListCredentialsToolName, json.RawMessage(`{"reveal":true,"message":"list"}`))
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(result, "secret: Tools-S3cret") {
+		t.Errorf("list with reveal must show the secret: %s", result)
+	}
+
+	_, err = tool.Handle(ctx, StoreCredentialToolName, json.RawMessage(
+		`{"type":"cookie","secret":"value","message":"found"}`))
+	if err == nil {
+		t.Error("expected an error for an unknown credential type")
+	}
+}
+
+func TestCredentialsToolNotAvailable(t *testing.T) {
+	t.Parallel()
+
+	tool := NewCredentialsTool(7, nil, nil, vault.New(&credentialsQuerier{}, &config.Config{}))
+	if tool.IsAvailable() {
+		t.Fatal("tool must not be available without a vault key")
+	}
+	if _, err := tool.Handle(t.Context(), ListCredentialsToolName, json.RawMessage(`{}`)); err == nil {
+		t.Error("expected an error when the vault is not available")
+	}
+}
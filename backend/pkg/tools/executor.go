@@ -14,6 +14,7 @@ import (
 	obs "pentagi/pkg/observability"
 	"pentagi/pkg/observability/langfuse"
 	"pentagi/pkg/schema"
+	"pentagi/pkg/vault"
 
 	"github.com/vxcontrol/langchaingo/documentloaders"
 	"github.com/vxcontrol/langchaingo/llms"
@@ -257,16 +258,19 @@ func (ce *customExecutor) Execute(
 		return fmt.Sprintf("failed to unmarshal '%s' tool call arguments: %v: fix it", name, err), nil
 	}
 
+	// only the handler sees secrets passed in the arguments
+	logArgs := json.RawMessage(vault.RedactFlow(ce.flowID, string(maskSecretArgs(name, args))))
+
 	toolType := GetToolType(name)
 	var obsWrapper observationWrapper
 
 	switch toolType {
 	case EnvironmentToolType, SearchNetworkToolType, StoreAgentResultToolType, StoreVectorDbToolType:
-		obsWrapper = ce.createToolObservation(ctx, obsName, args)
+		obsWrapper = ce.createToolObservation(ctx, obsName, logArgs)
 	case AgentToolType:
-		obsWrapper = ce.createAgentObservation(ctx, obsName, args)
+		obsWrapper = ce.createAgentObservation(ctx, obsName, logArgs)
 	case BarrierToolType:
-		obsWrapper = ce.createSpanObservation(ctx, obsName, args)
+		obsWrapper = ce.createSpanObservation(ctx, obsName, logArgs)
 	case SearchVectorDbToolType:
 		// Skip - handlers create RETRIEVER internally
 		obsWrapper = &noopObservationWrapper{context: ctx}
@@ -286,7 +290,7 @@ func (ce *customExecutor) Execute(
 		}
 	}
 
-	tcID, err := ce.tclp.PutLog(ctx, id, name, args, ce.taskID, ce.subtaskID)
+	tcID, err := ce.tclp.PutLog(ctx, id, name, logArgs, ce.taskID, ce.subtaskID)
 	if err != nil {
 		obsWrapper.end("", err, time.Since(startTime).Seconds())
 		return "", fmt.Errorf("failed to create toolcall: %w", err)
@@ -294,7 +298,7 @@ func (ce *customExecutor) Execute(
 
 	wrapHandler := func(ctx context.Context, name string, args json.RawMessage) (string, database.MsglogResultFormat, error) {
 		resultFormat := getMessageResultFormat(name)
-		result, err := handler(ctx, name, args)
+		result, err := handler(PutToolcallID(ctx, tcID), name, args)
 		persistCtx := context.WithoutCancel(ctx)
 
 		if err != nil {
@@ -330,7 +334,7 @@ func (ce *customExecutor) Execute(
 		}
 
 		durationDelta := time.Since(startTime).Seconds()
-		err = ce.tclp.UpdateLogSuccess(persistCtx, tcID, result, durationDelta)
+		err = ce.tclp.UpdateLogSuccess(persistCtx, tcID, vault.RedactFlow(ce.flowID, result), durationDelta)
 		if err != nil {
 			return "", resultFormat, fmt.Errorf("failed to update toolcall result: %w", err)
 		}
@@ -350,7 +354,7 @@ func (ce *customExecutor) Execute(
 		return "", err
 	}
 
-	if err := ce.storeToolResult(ctx, name, result, args); err != nil {
+	if err := ce.storeToolResult(ctx, name, result, logArgs); err != nil {
 		obsWrapper.end(result, err, time.Since(startTime).Seconds())
 		return "", fmt.Errorf("failed to store tool result in long-term memory: %w", err)
 	}
@@ -528,7 +532,8 @@ func (ce *customExecutor) storeToolResult(ctx context.Context, name, result stri
 	var buffer strings.Builder
 	buffer.WriteString(fmt.Sprintf("### Incoming arguments\n\n```json\n%s\n```\n\n", args))
 	buffer.WriteString(fmt.Sprintf("#### Tool result\n\n%s\n\n", result))
-	text := buffer.String()
+	// long-term memory is shared across flows, stored secrets stay out of it
+	text := vault.Redact(buffer.String())
 
 	split := textsplitter.NewRecursiveCharacter(
 		textsplitter.WithChunkSize(2000),
@@ -17,7 +17,6 @@ import (
 	"github.com/vxcontrol/cloud/anonymizer"
 	"github.com/vxcontrol/langchaingo/documentloaders"
 	"github.com/vxcontrol/langchaingo/schema"
-	"github.com/vxcontrol/langchaingo/vectorstores"
 	"github.com/vxcontrol/langchaingo/vectorstores/pgvector"
 )
 
@@ -38,6 +37,7 @@ type guide struct {
 	embedder          embeddings.Embedder
 	db                database.Querier
 	maxEmbeddingBytes int
+	hybrid            database.HybridSearchConfig
 	vslp              VectorStoreLogProvider
 	knp               KnowledgeProvider
 }
@@ -49,6 +49,7 @@ func NewGuideTool(
 	embedder embeddings.Embedder,
 	db database.Querier,
 	maxEmbeddingBytes int,
+	hybrid database.HybridSearchConfig,
 	vslp VectorStoreLogProvider,
 	knp KnowledgeProvider,
 ) Tool {
@@ -62,6 +63,7 @@ func NewGuideTool(
 		embedder:          embedder,
 		db:                db,
 		maxEmbeddingBytes: maxEmbeddingBytes,
+		hybrid:            hybrid,
 		vslp:              vslp,
 		knp:               knp,
 	}
@@ -91,6 +93,8 @@ func (g *guide) Handle(ctx context.Context, name string, args json.RawMessage) (
 			return "", fmt.Errorf("failed to unmarshal %s search guide action arguments: %w", name, err)
 		}
 
+		recordKnowledgeFeedback(ctx, g.db, action.Useful, action.NotUseful)
+
 		filters := map[string]any{
 			"doc_type":   guideVectorStoreDefaultType,
 			"guide_type": action.Type,
@@ -130,12 +134,15 @@ func (g *guide) Handle(ctx context.Context, name string, args json.RawMessage) (
 				"query":       query[:min(len(query), 1000)],
 			})
 
-			docs, err := g.store.SimilaritySearch(
+			docs, err := hybridSimilaritySearch(
 				ctx,
+				g.store,
+				g.db,
+				g.hybrid,
 				query,
-				guideVectorStoreResultLimit,
-				vectorstores.WithScoreThreshold(guideVectorStoreThreshold),
-				vectorstores.WithFilters(filters),
+				g.hybrid.Candidates(guideVectorStoreResultLimit),
+				guideVectorStoreThreshold,
+				filters,
 			)
 			if err != nil {
 				obs.LogErrorOrCancel(queryLogger, err, "failed to search for similar documents")
@@ -150,7 +157,7 @@ func (g *guide) Handle(ctx context.Context, name string, args json.RawMessage) (
 			"total_docs_before_dedup": len(allDocs),
 		}).Debug("all queries completed")
 
-		docs := MergeAndDeduplicateDocs(allDocs, guideVectorStoreResultLimit)
+		docs := rankKnowledgeDocs(ctx, g.db, g.hybrid, allDocs, guideVectorStoreResultLimit)
 
 		logger.WithFields(logrus.Fields{
 			"docs_after_dedup": len(docs),
@@ -184,12 +191,14 @@ func (g *guide) Handle(ctx context.Context, name string, args json.RawMessage) (
 				langfuse.WithScoreFloatValue(float64(doc.Score)),
 			)
 			buffer.WriteString(fmt.Sprintf("# Document %d Match score: %f\n\n", i+1, doc.Score))
+			writeKnowledgeDocID(&buffer, doc)
 			buffer.WriteString(fmt.Sprintf("## Original Guide Type: %s\n\n", doc.Metadata["guide_type"]))
 			buffer.WriteString(fmt.Sprintf("## Original Guide Question\n\n%s\n\n", doc.Metadata["question"]))
 			buffer.WriteString("## Content\n\n")
 			buffer.WriteString(doc.PageContent)
 			buffer.WriteString("\n\n")
 		}
+		buffer.WriteString(knowledgeFeedbackHint)
 
 		if agentCtx, ok := GetAgentContext(ctx); ok {
 			filtersData, err := json.Marshal(filters)
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"pentagi/pkg/database"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vxcontrol/langchaingo/schema"
+	"github.com/vxcontrol/langchaingo/vectorstores"
+	"github.com/vxcontrol/langchaingo/vectorstores/pgvector"
+)
+
+// hybridSimilaritySearch returns up to limit documents matching the query and
+// metadata filters. The vector similarity ranking from the store is fused with
+// a full-text ranking over the same documents when hybrid search is enabled,
+// so exact tokens like CVE IDs, tool flags and hostnames are found even when
+// their embeddings are not close enough. Document scores are fused ones then.
+//
+// A failed full-text search degrades to vector-only results instead of
+// failing the tool call.
+func hybridSimilaritySearch(
+	ctx context.Context,
+	store *pgvector.Store,
+	db database.Querier,
+	hybrid database.HybridSearchConfig,
+	query string,
+	limit int,
+	threshold float32,
+	filters map[string]any,
+) ([]schema.Document, error) {
+	if !hybrid.Enabled || db == nil {
+		return store.SimilaritySearch(
+			ctx,
+			query,
+			limit,
+			vectorstores.WithScoreThreshold(threshold),
+			vectorstores.WithFilters(filters),
+		)
+	}
+
+	candidates := hybrid.Candidates(limit)
+	vectorDocs, err := store.SimilaritySearch(
+		ctx,
+		query,
+		candidates,
+		vectorstores.WithScoreThreshold(threshold),
+		vectorstores.WithFilters(filters),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	textDocs, err := textSearchDocs(ctx, db, query, candidates, filters)
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).Warn("full-text knowledge search failed, using vector results only")
+		return vectorDocs[:min(len(vectorDocs), limit)], nil
+	}
+
+	docs := make(map[string]schema.Document, len(vectorDocs)+len(textDocs))
+	vectorKeys := make([]string, 0, len(vectorDocs))
+	for _, doc := range vectorDocs {
+		key := hashContent(doc.PageContent)
+		docs[key] = doc
+		vectorKeys = append(vectorKeys, key)
+	}
+	textKeys := make([]string, 0, len(textDocs))
+	for _, doc := range textDocs {
+		key := hashContent(doc.PageContent)
+		if _, ok := docs[key]; !ok {
+			docs[key] = doc
+		}
+		textKeys = append(textKeys, key)
+	}
+
+	docType, _ := filters["doc_type"].(string)
+	textWeight := hybrid.TextWeightFor(docType)
+	fused := database.FuseRankings(vectorKeys, textKeys, hybrid.K, func(string) float64 {
+		return textWeight
+	})
+
+	result := make([]schema.Document, 0, min(len(fused), limit))
+	for _, rank := range fused[:min(len(fused), limit)] {
+		doc := docs[rank.Key]
+		doc.Score = float32(rank.Score)
+		result = append(result, doc)
+	}
+
+	return result, nil
+}
+
+// textSearchDocs runs the full-text half of hybrid search with the same
+// metadata filters the vector store gets, compared as text like pgvector does.
+func textSearchDocs(
+	ctx context.Context,
+	db database.Querier,
+	query string,
+	limit int,
+	filters map[string]any,
+) ([]schema.Document, error) {
+	filtersData, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.SearchKnowledgeDocumentsText(ctx, database.SearchKnowledgeDocumentsTextParams{
+		Query:      query,
+		WithMemory: true,
+		Filters:    filtersData,
+		Lim:        int32(limit), //nolint:gosec
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]schema.Document, 0, len(rows))
+	for _, row := range rows {
+		metadata := map[string]any{}
+		if row.Cmetadata.Valid {
+			if err := json.Unmarshal([]byte(row.Cmetadata.String), &metadata); err != nil {
+				continue
+			}
+		}
+		docs = append(docs, schema.Document{
+			PageContent: row.Document,
+			Metadata:    metadata,
+			Score:       float32(row.Score),
+		})
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"pentagi/pkg/database"
+)
+
+type textSearchQuerier struct {
+	database.Querier
+
+	got  database.SearchKnowledgeDocumentsTextParams
+	rows []database.SearchKnowledgeDocumentsTextRow
+}
+
+func (q *textSearchQuerier) SearchKnowledgeDocumentsText(
+	_ context.Context,
+	arg database.SearchKnowledgeDocumentsTextParams,
+) ([]database.SearchKnowledgeDocumentsTextRow, error) {
+	q.got = arg
+	return q.rows, nil
+}
+
+func TestTextSearchDocs(t *testing.T) {
+	db := &textSearchQuerier{rows: []database.SearchKnowledgeDocumentsTextRow{
+		{
+			ID:        "1",
+			Document:  "nmap -sV -p- 10.0.0.5",
+			Cmetadata: sql.NullString{String: `{"doc_type":"memory","flow_id":"7"}`, Valid: true},
+			Score:     0.8,
+		},
+		{ID: "2", Document: "broken metadata", Cmetadata: sql.NullString{String: `{`, Valid: true}},
+		{ID: "3", Document: "no metadata"},
+	}}
+
+	filters := map[string]any{"doc_type": "memory", "flow_id": "7"}
+	docs, err := textSearchDocs(t.Context(), db, "-sV", 12, filters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if db.got.Query != "-sV" || db.got.Lim != 12 || !db.got.WithMemory || db.got.UserID != "" {
+		t.Errorf("unexpected params: %+v", db.got)
+	}
+	if string(db.got.Filters) != `{"doc_type":"memory","flow_id":"7"}` {
+		t.Errorf("filters must be passed as JSON, got %s", db.got.Filters)
+	}
+
+	if len(docs) != 2 {
+		t.Fatalf("rows with invalid metadata must be skipped, got %d docs", len(docs))
+	}
+	if docs[0].PageContent != "nmap -sV -p- 10.0.0.5" || docs[0].Metadata["flow_id"] != "7" || docs[0].Score != 0.8 {
+		t.Errorf("unexpected document: %+v", docs[0])
+	}
+	if docs[1].Metadata == nil {
+		t.Error("documents without metadata must get an empty map")
+	}
+}
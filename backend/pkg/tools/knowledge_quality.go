@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"pentagi/pkg/database"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vxcontrol/langchaingo/schema"
+)
+
+// knowledgeDocIDKey carries the document UUID in the metadata of ranked
+// results so the tool output can reference it for feedback.
+const knowledgeDocIDKey = "id"
+
+const knowledgeFeedbackHint = "If a document above helped or misled you, pass its ID in `useful` or `not_useful` of your next search to improve future rankings.\n"
+
+// rankKnowledgeDocs merges results of several queries, drops archived and
+// expired documents and scales scores by the documents' quality signals
+// before keeping the best limit ones. A retrieval is recorded for every
+// returned document. Signal lookups are best effort: on failure documents
+// keep their similarity scores.
+func rankKnowledgeDocs(
+	ctx context.Context,
+	db database.Querier,
+	hybrid database.HybridSearchConfig,
+	docs []schema.Document,
+	limit int,
+) []schema.Document {
+	docs = MergeAndDeduplicateDocs(docs, len(docs))
+
+	now := time.Now()
+	active := make([]schema.Document, 0, len(docs))
+	for _, doc := range docs {
+		archived, expiresAt := knowledgeDocState(doc.Metadata)
+		if !database.KnowledgeRetired(archived, expiresAt, now) {
+			active = append(active, doc)
+		}
+	}
+	if db == nil || len(active) == 0 {
+		return active[:min(len(active), limit)]
+	}
+
+	hashes := make([]string, len(active))
+	for i, doc := range active {
+		hashes[i] = md5Hex(doc.PageContent)
+	}
+
+	rows, err := db.GetKnowledgeDocumentSignals(ctx, database.GetKnowledgeDocumentSignalsParams{
+		Ids:    []string{},
+		Hashes: hashes,
+	})
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).Warn("failed to load knowledge quality signals")
+		return active[:min(len(active), limit)]
+	}
+	signals := make(map[string]database.GetKnowledgeDocumentSignalsRow, len(rows))
+	for _, row := range rows {
+		signals[row.Hash] = row
+	}
+
+	for i := range active {
+		row, ok := signals[hashes[i]]
+		if !ok {
+			continue
+		}
+		metadata := make(map[string]any, len(active[i].Metadata)+1)
+		maps.Copy(metadata, active[i].Metadata)
+		metadata[knowledgeDocIDKey] = row.ID
+		active[i].Metadata = metadata
+		active[i].Score *= float32(hybrid.QualityFactor(database.SignalsFromRow(row)))
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].Score > active[j].Score
+	})
+	active = active[:min(len(active), limit)]
+
+	ids := make([]string, 0, len(active))
+	for _, doc := range active {
+		if id := knowledgeDocID(doc); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		if err := db.RecordKnowledgeRetrievals(ctx, ids); err != nil {
+			logrus.WithContext(ctx).WithError(err).Warn("failed to record knowledge retrievals")
+		}
+	}
+
+	return active
+}
+
+// recordKnowledgeFeedback counts the agent's report on documents returned by
+// earlier searches. Failures are logged only, they must not fail the search.
+func recordKnowledgeFeedback(ctx context.Context, db database.Querier, useful, notUseful []string) {
+	if db == nil {
+		return
+	}
+	for _, report := range []struct {
+		ids    []string
+		useful bool
+	}{{useful, true}, {notUseful, false}} {
+		ids := cleanDocIDs(report.ids)
+		if len(ids) == 0 {
+			continue
+		}
+		counted, err := db.AddKnowledgeDocumentFeedback(ctx, database.AddKnowledgeDocumentFeedbackParams{
+			Useful: report.useful,
+			Ids:    ids,
+		})
+		logger := logrus.WithContext(ctx).WithFields(logrus.Fields{
+			"ids":    ids,
+			"useful": report.useful,
+		})
+		if err != nil {
+			logger.WithError(err).Warn("failed to record knowledge feedback")
+			continue
+		}
+		logger.WithField("counted", counted).Debug("knowledge feedback recorded")
+	}
+}
+
+// writeKnowledgeDocID writes the reference agents use to report feedback.
+func writeKnowledgeDocID(buffer *strings.Builder, doc schema.Document) {
+	if id := knowledgeDocID(doc); id != "" {
+		buffer.WriteString(fmt.Sprintf("## Document ID: %s\n\n", id))
+	}
+}
+
+func knowledgeDocID(doc schema.Document) string {
+	id, _ := doc.Metadata[knowledgeDocIDKey].(string)
+	return id
+}
+
+// knowledgeDocState reads the archived flag and expiry from cmetadata as
+// decoded from JSON by the vector store.
+func knowledgeDocState(metadata map[string]any) (bool, *time.Time) {
+	archived, _ := metadata["archived"].(bool)
+	raw, _ := metadata["expires_at"].(string)
+	if raw == "" {
+		return archived, nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return archived, nil
+	}
+	return archived, &expiresAt
+}
+
+// cleanDocIDs trims and deduplicates document IDs given by an agent.
+func cleanDocIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if _, ok := seen[id]; ok || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
+
+// md5Hex matches md5(document) in SQL, used to find documents by content.
+func md5Hex(content string) string {
+	sum := md5.Sum([]byte(content)) //nolint:gosec
+	return hex.EncodeToString(sum[:])
+}
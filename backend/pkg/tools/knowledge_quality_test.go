@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pentagi/pkg/database"
+
+	"github.com/vxcontrol/langchaingo/schema"
+)
+
+type signalsQuerier struct {
+	database.Querier
+
+	rows      []database.GetKnowledgeDocumentSignalsRow
+	err       error
+	retrieved []string
+	feedback  []database.AddKnowledgeDocumentFeedbackParams
+}
+
+func (q *signalsQuerier) GetKnowledgeDocumentSignals(
+	_ context.Context,
+	_ database.GetKnowledgeDocumentSignalsParams,
+) ([]database.GetKnowledgeDocumentSignalsRow, error) {
+	return q.rows, q.err
+}
+
+func (q *signalsQuerier) RecordKnowledgeRetrievals(_ context.Context, ids []string) error {
+	q.retrieved = append(q.retrieved, ids...)
+	return nil
+}
+
+func (q *signalsQuerier) AddKnowledgeDocumentFeedback(
+	_ context.Context,
+	arg database.AddKnowledgeDocumentFeedbackParams,
+) (int64, error) {
+	q.feedback = append(q.feedback, arg)
+	return int64(len(arg.Ids)), nil
+}
+
+func TestRankKnowledgeDocs(t *testing.T) {
+	hybrid := database.HybridSearchConfig{FeedbackWeight: 0.5}
+	expired := time.Now().Add(-time.Hour).Format(time.RFC3339Nano)
+	docs := []schema.Document{
+		{PageContent: "misleading", Score: 0.9, Metadata: map[string]any{}},
+		{PageContent: "helpful", Score: 0.8, Metadata: map[string]any{}},
+		{PageContent: "helpful", Score: 0.7, Metadata: map[string]any{}},
+		{PageContent: "archived", Score: 0.95, Metadata: map[string]any{"archived": true}},
+		{PageContent: "expired", Score: 0.95, Metadata: map[string]any{"expires_at": expired}},
+		{PageContent: "unknown", Score: 0.5, Metadata: map[string]any{}},
+	}
+
+	t.Run("signals reorder results", func(t *testing.T) {
+		db := &signalsQuerier{rows: []database.GetKnowledgeDocumentSignalsRow{
+			{ID: "id-misleading", Hash: md5Hex("misleading"), NotUseful: 5},
+			{ID: "id-helpful", Hash: md5Hex("helpful"), Useful: 3, Upvotes: 1},
+		}}
+
+		ranked := rankKnowledgeDocs(t.Context(), db, hybrid, docs, 2)
+		if len(ranked) != 2 {
+			t.Fatalf("expected 2 documents, got %d", len(ranked))
+		}
+		if ranked[0].PageContent != "helpful" || ranked[1].PageContent != "misleading" {
+			t.Errorf("unexpected order: %q, %q", ranked[0].PageContent, ranked[1].PageContent)
+		}
+		if knowledgeDocID(ranked[0]) != "id-helpful" {
+			t.Errorf("ranked documents must carry their ID, got %v", ranked[0].Metadata)
+		}
+		if len(db.retrieved) != 2 || db.retrieved[0] != "id-helpful" || db.retrieved[1] != "id-misleading" {
+			t.Errorf("unexpected retrievals: %v", db.retrieved)
+		}
+		if _, ok := docs[1].Metadata[knowledgeDocIDKey]; ok {
+			t.Error("metadata of the input documents must not be modified")
+		}
+	})
+
+	t.Run("signal failure keeps similarity order", func(t *testing.T) {
+		db := &signalsQuerier{err: errors.New("db down")}
+		ranked := rankKnowledgeDocs(t.Context(), db, hybrid, docs, 10)
+		if len(ranked) != 3 {
+			t.Fatalf("archived and expired documents must be dropped, got %d", len(ranked))
+		}
+		if ranked[0].PageContent != "misleading" || len(db.retrieved) != 0 {
+			t.Errorf("unexpected ranking without signals: %+v", ranked)
+		}
+	})
+}
+
+func TestRecordKnowledgeFeedback(t *testing.T) {
+	db := &signalsQuerier{}
+	recordKnowledgeFeedback(t.Context(), db, []string{" a ", "a", ""}, nil)
+
+	if len(db.feedback) != 1 {
+		t.Fatalf("expected one report, got %+v", db.feedback)
+	}
+	if !db.feedback[0].Useful || len(db.feedback[0].Ids) != 1 || db.feedback[0].Ids[0] != "a" {
+		t.Errorf("unexpected report: %+v", db.feedback[0])
+	}
+}
@@ -14,7 +14,6 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/vxcontrol/langchaingo/schema"
-	"github.com/vxcontrol/langchaingo/vectorstores"
 	"github.com/vxcontrol/langchaingo/vectorstores/pgvector"
 )
 
@@ -28,13 +27,23 @@ const (
 type memory struct {
 	flowID int64
 	store  *pgvector.Store
+	db     database.Querier
+	hybrid database.HybridSearchConfig
 	vslp   VectorStoreLogProvider
 }
 
-func NewMemoryTool(flowID int64, store *pgvector.Store, vslp VectorStoreLogProvider) Tool {
+func NewMemoryTool(
+	flowID int64,
+	store *pgvector.Store,
+	db database.Querier,
+	hybrid database.HybridSearchConfig,
+	vslp VectorStoreLogProvider,
+) Tool {
 	return &memory{
 		flowID: flowID,
 		store:  store,
+		db:     db,
+		hybrid: hybrid,
 		vslp:   vslp,
 	}
 }
@@ -118,12 +127,15 @@ func (m *memory) Handle(ctx context.Context, name string, args json.RawMessage)
 				"query":       query[:min(len(query), 1000)],
 			})
 
-			docs, err := m.store.SimilaritySearch(
+			docs, err := hybridSimilaritySearch(
 				ctx,
+				m.store,
+				m.db,
+				m.hybrid,
 				query,
 				memoryVectorStoreResultLimit,
-				vectorstores.WithScoreThreshold(memoryVectorStoreThreshold),
-				vectorstores.WithFilters(filters),
+				memoryVectorStoreThreshold,
+				filters,
 			)
 			if err != nil {
 				obs.LogErrorOrCancel(queryLogger, err, "failed to search for similar documents")
@@ -132,12 +144,15 @@ func (m *memory) Handle(ctx context.Context, name string, args json.RawMessage)
 
 			// Fallback to global filters if specific filters yielded no results
 			if isSpecificFilters && len(docs) == 0 {
-				docs, err = m.store.SimilaritySearch(
+				docs, err = hybridSimilaritySearch(
 					ctx,
+					m.store,
+					m.db,
+					m.hybrid,
 					query,
 					memoryVectorStoreResultLimit,
-					vectorstores.WithScoreThreshold(memoryVectorStoreThreshold),
-					vectorstores.WithFilters(globalFilters),
+					memoryVectorStoreThreshold,
+					globalFilters,
 				)
 				if err != nil {
 					obs.LogErrorOrCancel(queryLogger, err, "failed to search with global filters")
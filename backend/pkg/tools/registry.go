@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"encoding/json"
 	"maps"
 	"pentagi/pkg/database"
 
@@ -41,6 +42,8 @@ const (
 	SearchCodeToolName         = "search_code"
 	StoreCodeToolName          = "store_code"
 	GraphitiSearchToolName     = "graphiti_search"
+	StoreCredentialToolName    = "store_credential"
+	ListCredentialsToolName    = "list_credentials"
 	ReportResultToolName       = "report_result"
 	SubtaskListToolName        = "subtask_list"
 	SubtaskPatchToolName       = "subtask_patch"
@@ -128,6 +131,8 @@ var toolsTypeMapping = map[string]ToolType{
 	SearchCodeToolName:         SearchVectorDbToolType,
 	StoreCodeToolName:          StoreVectorDbToolType,
 	GraphitiSearchToolName:     SearchVectorDbToolType,
+	StoreCredentialToolName:    EnvironmentToolType,
+	ListCredentialsToolName:    EnvironmentToolType,
 	ReportResultToolName:       StoreAgentResultToolType,
 	SubtaskListToolName:        StoreAgentResultToolType,
 	SubtaskPatchToolName:       StoreAgentResultToolType,
@@ -353,6 +358,20 @@ var registryDefinitions = map[string]llms.FunctionDefinition{
 			"and build on previous findings within the same penetration testing engagement.",
 		Parameters: reflector.Reflect(&GraphitiSearchAction{}),
 	},
+	StoreCredentialToolName: {
+		Name: StoreCredentialToolName,
+		Description: "Store a credential found during the engagement or given by the user in the encrypted vault of this flow. " +
+			"Returns the placeholder {{cred:ID}} which stands for the secret from then on: put it into terminal commands instead of the secret, " +
+			"it is replaced with the secret only when the command runs, and the secret itself is masked with it in every log. " +
+			"Storing the same type, username and host again replaces the secret and keeps the placeholder.",
+		Parameters: reflector.Reflect(&StoreCredentialAction{}),
+	},
+	ListCredentialsToolName: {
+		Name: ListCredentialsToolName,
+		Description: "List the credentials stored in the vault of this flow with their placeholders, type, username and source host. " +
+			"Secrets are not shown unless reveal is set: use the {{cred:ID}} placeholders in terminal commands instead, e.g. sshpass -p '{{cred:3}}' ssh admin@10.0.0.5",
+		Parameters: reflector.Reflect(&ListCredentialsAction{}),
+	},
 	MemoristToolName: {
 		Name:        MemoristToolName,
 		Description: "Call to Archivist team member who remember all the information about the past work and made tasks and can answer your question about it",
@@ -455,6 +474,38 @@ var registryDefinitions = map[string]llms.FunctionDefinition{
 	},
 }
 
+// secretToolArgs are the argument fields which carry secrets: only the tool
+// handler sees them, toolcall logs and traces get them masked.
+var secretToolArgs = map[string][]string{
+	StoreCredentialToolName: {"secret"},
+}
+
+const maskedSecretArg = "[secret]"
+
+// maskSecretArgs returns args with the secret fields of the tool masked
+func maskSecretArgs(name string, args json.RawMessage) json.RawMessage {
+	fields, ok := secretToolArgs[name]
+	if !ok {
+		return args
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(args, &values); err != nil {
+		return args
+	}
+	for _, field := range fields {
+		if _, ok := values[field]; ok {
+			values[field] = maskedSecretArg
+		}
+	}
+
+	masked, err := json.Marshal(values)
+	if err != nil {
+		return args
+	}
+	return masked
+}
+
 func getMessageType(name string) database.MsglogType {
 	switch name {
 	case TerminalToolName:
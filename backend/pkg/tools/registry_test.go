@@ -1,7 +1,9 @@
 package tools
 
 import (
+	"encoding/json"
 	"slices"
+	"strings"
 	"testing"
 
 	"pentagi/pkg/database"
@@ -287,3 +289,21 @@ func TestAllowedToolListsContainKnownUniqueTools(t *testing.T) {
 		t.Errorf("allowedStoringInMemoryTools must contain %q", SearchToolName)
 	}
 }
+
+func TestMaskSecretArgs(t *testing.T) {
+	t.Parallel()
+
+	args := json.RawMessage(`{"type":"password","username":"admin","secret":"S3cret!","message":"found"}`)
+	masked := maskSecretArgs(StoreCredentialToolName, args)
+	if strings.Contains(string(masked), "S3cret!") {
+		t.Errorf("masked args still contain the secret: %s", masked)
+	}
+	if !strings.Contains(string(masked), `"username":"admin"`) {
+		t.Errorf("masked args lost other fields: %s", masked)
+	}
+
+	other := json.RawMessage(`{"input":"echo S3cret!"}`)
+	if got := maskSecretArgs(TerminalToolName, other); string(got) != string(other) {
+		t.Errorf("args of %s must not change, got %s", TerminalToolName, got)
+	}
+}
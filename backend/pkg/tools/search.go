@@ -17,7 +17,6 @@ import (
 	"github.com/vxcontrol/cloud/anonymizer"
 	"github.com/vxcontrol/langchaingo/documentloaders"
 	"github.com/vxcontrol/langchaingo/schema"
-	"github.com/vxcontrol/langchaingo/vectorstores"
 	"github.com/vxcontrol/langchaingo/vectorstores/pgvector"
 )
 
@@ -38,6 +37,7 @@ type search struct {
 	embedder          embeddings.Embedder
 	db                database.Querier
 	maxEmbeddingBytes int
+	hybrid            database.HybridSearchConfig
 	vslp              VectorStoreLogProvider
 	knp               KnowledgeProvider
 }
@@ -51,6 +51,7 @@ func NewSearchTool(
 	embedder embeddings.Embedder,
 	db database.Querier,
 	maxEmbeddingBytes int,
+	hybrid database.HybridSearchConfig,
 	vslp VectorStoreLogProvider,
 	knp KnowledgeProvider,
 ) Tool {
@@ -64,6 +65,7 @@ func NewSearchTool(
 		embedder:          embedder,
 		db:                db,
 		maxEmbeddingBytes: maxEmbeddingBytes,
+		hybrid:            hybrid,
 		vslp:              vslp,
 		knp:               knp,
 	}
@@ -89,6 +91,8 @@ func (s *search) Handle(ctx context.Context, name string, args json.RawMessage)
 			return "", fmt.Errorf("failed to unmarshal %s search answer action arguments: %w", name, err)
 		}
 
+		recordKnowledgeFeedback(ctx, s.db, action.Useful, action.NotUseful)
+
 		filters := map[string]any{
 			"doc_type":    searchVectorStoreDefaultType,
 			"answer_type": action.Type,
@@ -128,12 +132,15 @@ func (s *search) Handle(ctx context.Context, name string, args json.RawMessage)
 				"query":       query[:min(len(query), 1000)],
 			})
 
-			docs, err := s.store.SimilaritySearch(
+			docs, err := hybridSimilaritySearch(
 				ctx,
+				s.store,
+				s.db,
+				s.hybrid,
 				query,
-				searchVectorStoreResultLimit,
-				vectorstores.WithScoreThreshold(searchVectorStoreThreshold),
-				vectorstores.WithFilters(filters),
+				s.hybrid.Candidates(searchVectorStoreResultLimit),
+				searchVectorStoreThreshold,
+				filters,
 			)
 			if err != nil {
 				obs.LogErrorOrCancel(queryLogger, err, "failed to search answer for query")
@@ -148,7 +155,7 @@ func (s *search) Handle(ctx context.Context, name string, args json.RawMessage)
 			"total_docs_before_dedup": len(allDocs),
 		}).Debug("all queries completed")
 
-		docs := MergeAndDeduplicateDocs(allDocs, searchVectorStoreResultLimit)
+		docs := rankKnowledgeDocs(ctx, s.db, s.hybrid, allDocs, searchVectorStoreResultLimit)
 
 		logger.WithFields(logrus.Fields{
 			"docs_after_dedup": len(docs),
@@ -182,12 +189,14 @@ func (s *search) Handle(ctx context.Context, name string, args json.RawMessage)
 				langfuse.WithScoreFloatValue(float64(doc.Score)),
 			)
 			buffer.WriteString(fmt.Sprintf("# Document %d Search Score: %f\n\n", i+1, doc.Score))
+			writeKnowledgeDocID(&buffer, doc)
 			buffer.WriteString(fmt.Sprintf("## Original Answer Type: %s\n\n", doc.Metadata["answer_type"]))
 			buffer.WriteString(fmt.Sprintf("## Original Search Question\n\n%s\n\n", doc.Metadata["question"]))
 			buffer.WriteString("## Content\n\n")
 			buffer.WriteString(doc.PageContent)
 			buffer.WriteString("\n\n")
 		}
+		buffer.WriteString(knowledgeFeedbackHint)
 
 		if agentCtx, ok := GetAgentContext(ctx); ok {
 			filtersData, err := json.Marshal(filters)
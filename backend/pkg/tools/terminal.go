@@ -16,6 +16,7 @@ import (
 	"pentagi/pkg/docker"
 	obs "pentagi/pkg/observability"
 	"pentagi/pkg/observability/langfuse"
+	"pentagi/pkg/vault"
 
 	"github.com/moby/moby/client"
 	"github.com/sirupsen/logrus"
@@ -50,6 +51,7 @@ type terminal struct {
 	tenantPrefix       string
 	dockerClient       docker.DockerClient
 	tlp                TermLogProvider
+	vault              *vault.Vault
 	defaultExecTimeout time.Duration
 }
 
@@ -60,6 +62,7 @@ func NewTerminalTool(
 	tenantPrefix string,
 	dockerClient docker.DockerClient,
 	tlp TermLogProvider,
+	credentialVault *vault.Vault,
 	defaultExecTimeout time.Duration,
 ) Tool {
 	return &terminal{
@@ -71,6 +74,7 @@ func NewTerminalTool(
 		tenantPrefix:       tenantPrefix,
 		dockerClient:       dockerClient,
 		tlp:                tlp,
+		vault:              credentialVault,
 		defaultExecTimeout: defaultExecTimeout,
 	}
 }
@@ -195,12 +199,6 @@ func (t *terminal) ExecCommand(
 ) (string, error) {
 	containerName := PrimaryTerminalName(t.tenantPrefix, t.flowID)
 
-	cmd := []string{
-		"sh",
-		"-c",
-		command,
-	}
-
 	isRunning, err := t.dockerClient.IsContainerRunning(ctx, t.containerLID)
 	if err != nil {
 		return "", fmt.Errorf("runtime verification failed: %w", err)
@@ -220,6 +218,18 @@ func (t *terminal) ExecCommand(
 		return "", fmt.Errorf("failed to put terminal log (stdin): %w", err)
 	}
 
+	// the log above keeps the {{cred:ID}} placeholders, only the shell sees the secrets
+	command, err = t.vault.Substitute(ctx, t.flowID, command)
+	if err != nil {
+		return "", fmt.Errorf("failed to substitute credentials: %w", err)
+	}
+
+	cmd := []string{
+		"sh",
+		"-c",
+		command,
+	}
+
 	timeout = t.normalizeExecTimeout(timeout)
 
 	createResp, err := t.dockerClient.ContainerExecCreate(ctx, containerName, client.ExecCreateOptions{
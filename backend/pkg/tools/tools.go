@@ -22,6 +22,7 @@ import (
 	"pentagi/pkg/graphiti"
 	"pentagi/pkg/providers/embeddings"
 	"pentagi/pkg/schema"
+	"pentagi/pkg/vault"
 
 	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/client"
@@ -176,6 +177,7 @@ type flowToolsExecutor struct {
 	cfg            *config.Config
 	embedder       embeddings.Embedder
 	store          *pgvector.Store
+	hybrid         database.HybridSearchConfig
 	graphitiClient *graphiti.Client
 	image          string
 	docker         docker.DockerClient
@@ -183,6 +185,7 @@ type flowToolsExecutor struct {
 	primaryLID     string
 	functions      *Functions
 	replacer       anonymizer.Replacer
+	vault          *vault.Vault
 
 	definitions map[string]llms.FunctionDefinition
 	handlers    map[string]ExecutorHandler
@@ -388,7 +391,9 @@ func NewFlowToolsExecutor(
 		docker:      docker,
 		functions:   functions,
 		replacer:    sharedReplacer,
+		vault:       vault.New(db, cfg),
 		cfg:         cfg,
+		hybrid:      database.NewHybridSearchConfig(cfg),
 		flowID:      flowID,
 		userID:      userID,
 		definitions: make(map[string]llms.FunctionDefinition),
@@ -481,6 +486,12 @@ func (fte *flowToolsExecutor) SetGraphitiClient(client *graphiti.Client) {
 }
 
 func (fte *flowToolsExecutor) Prepare(ctx context.Context) error {
+	// secrets stored before a restart must be redacted from the logs as well
+	if err := fte.vault.Load(ctx, fte.flowID); err != nil {
+		logrus.WithContext(ctx).WithError(err).WithFields(enrichLogrusFields(fte.flowID, nil, nil, nil)).
+			Warn("failed to load flow credentials for redaction")
+	}
+
 	if cnt, err := fte.db.GetFlowPrimaryContainer(ctx, fte.flowID); err == nil {
 		containerName := PrimaryTerminalName(fte.cfg.TenantPrefix(), fte.flowID)
 		// the stored status goes stale when the container is removed outside pentagi
@@ -850,6 +861,7 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 		fte.cfg.TenantPrefix(),
 		fte.docker,
 		fte.tlp,
+		fte.vault,
 		time.Duration(fte.cfg.TerminalToolTimeout)*time.Second,
 	)
 
@@ -874,6 +886,16 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 		handlers[BrowserToolName] = browser.Handle
 	}
 
+	creds := NewCredentialsTool(fte.flowID, nil, nil, fte.vault)
+	if creds.IsAvailable() {
+		definitions = append(definitions,
+			registryDefinitions[StoreCredentialToolName],
+			registryDefinitions[ListCredentialsToolName],
+		)
+		handlers[StoreCredentialToolName] = creds.Handle
+		handlers[ListCredentialsToolName] = creds.Handle
+	}
+
 	if cfg.UseAgents {
 		definitions = append(definitions,
 			registryDefinitions[AdviceToolName],
@@ -893,6 +915,8 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 		memory := NewMemoryTool(
 			fte.flowID,
 			fte.store,
+			fte.db,
+			fte.hybrid,
 			fte.vslp,
 		)
 		if memory.IsAvailable() {
@@ -907,6 +931,7 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 			fte.embedder,
 			fte.db,
 			fte.cfg.EmbeddingMaxTextBytes,
+			fte.hybrid,
 			fte.vslp,
 			fte.knp,
 		)
@@ -922,6 +947,7 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 			fte.embedder,
 			fte.db,
 			fte.cfg.EmbeddingMaxTextBytes,
+			fte.hybrid,
 			fte.vslp,
 			fte.knp,
 		)
@@ -937,6 +963,7 @@ func (fte *flowToolsExecutor) GetAssistantExecutor(cfg AssistantExecutorConfig)
 			fte.embedder,
 			fte.db,
 			fte.cfg.EmbeddingMaxTextBytes,
+			fte.hybrid,
 			fte.vslp,
 			fte.knp,
 		)
@@ -1100,6 +1127,7 @@ func (fte *flowToolsExecutor) GetInstallerExecutor(cfg InstallerExecutorConfig)
 		fte.cfg.TenantPrefix(),
 		fte.docker,
 		fte.tlp,
+		fte.vault,
 		time.Duration(fte.cfg.TerminalToolTimeout)*time.Second,
 	)
 
@@ -1159,6 +1187,7 @@ func (fte *flowToolsExecutor) GetInstallerExecutor(cfg InstallerExecutorConfig)
 		fte.embedder,
 		fte.db,
 		fte.cfg.EmbeddingMaxTextBytes,
+		fte.hybrid,
 		fte.vslp,
 		fte.knp,
 	)
@@ -1207,6 +1236,7 @@ func (fte *flowToolsExecutor) GetCoderExecutor(cfg CoderExecutorConfig) (Context
 		fte.cfg.TenantPrefix(),
 		fte.docker,
 		fte.tlp,
+		fte.vault,
 		time.Duration(fte.cfg.TerminalToolTimeout)*time.Second,
 	)
 
@@ -1258,6 +1288,13 @@ func (fte *flowToolsExecutor) GetCoderExecutor(cfg CoderExecutorConfig) (Context
 		ce.handlers[BrowserToolName] = browser.Handle
 	}
 
+	// the coder uses credentials the pentester stored, it does not find any
+	creds := NewCredentialsTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.vault)
+	if creds.IsAvailable() {
+		ce.definitions = append(ce.definitions, registryDefinitions[ListCredentialsToolName])
+		ce.handlers[ListCredentialsToolName] = creds.Handle
+	}
+
 	code := NewCodeTool(
 		fte.userID,
 		fte.flowID,
@@ -1268,6 +1305,7 @@ func (fte *flowToolsExecutor) GetCoderExecutor(cfg CoderExecutorConfig) (Context
 		fte.embedder,
 		fte.db,
 		fte.cfg.EmbeddingMaxTextBytes,
+		fte.hybrid,
 		fte.vslp,
 		fte.knp,
 	)
@@ -1332,6 +1370,7 @@ func (fte *flowToolsExecutor) GetPentesterExecutor(cfg PentesterExecutorConfig)
 		fte.cfg.TenantPrefix(),
 		fte.docker,
 		fte.tlp,
+		fte.vault,
 		time.Duration(fte.cfg.TerminalToolTimeout)*time.Second,
 	)
 
@@ -1385,6 +1424,16 @@ func (fte *flowToolsExecutor) GetPentesterExecutor(cfg PentesterExecutorConfig)
 		ce.handlers[BrowserToolName] = browser.Handle
 	}
 
+	creds := NewCredentialsTool(fte.flowID, cfg.TaskID, cfg.SubtaskID, fte.vault)
+	if creds.IsAvailable() {
+		ce.definitions = append(ce.definitions,
+			registryDefinitions[StoreCredentialToolName],
+			registryDefinitions[ListCredentialsToolName],
+		)
+		ce.handlers[StoreCredentialToolName] = creds.Handle
+		ce.handlers[ListCredentialsToolName] = creds.Handle
+	}
+
 	guide := NewGuideTool(
 		fte.userID,
 		fte.flowID,
@@ -1395,6 +1444,7 @@ func (fte *flowToolsExecutor) GetPentesterExecutor(cfg PentesterExecutorConfig)
 		fte.embedder,
 		fte.db,
 		fte.cfg.EmbeddingMaxTextBytes,
+		fte.hybrid,
 		fte.vslp,
 		fte.knp,
 	)
@@ -1489,6 +1539,7 @@ func (fte *flowToolsExecutor) GetSearcherExecutor(cfg SearcherExecutorConfig) (C
 		fte.embedder,
 		fte.db,
 		fte.cfg.EmbeddingMaxTextBytes,
+		fte.hybrid,
 		fte.vslp,
 		fte.knp,
 	)
@@ -1525,6 +1576,7 @@ func (fte *flowToolsExecutor) GetGeneratorExecutor(cfg GeneratorExecutorConfig)
 		fte.cfg.TenantPrefix(),
 		fte.docker,
 		fte.tlp,
+		fte.vault,
 		time.Duration(fte.cfg.TerminalToolTimeout)*time.Second,
 	)
 
@@ -1594,6 +1646,7 @@ func (fte *flowToolsExecutor) GetRefinerExecutor(cfg RefinerExecutorConfig) (Con
 		fte.cfg.TenantPrefix(),
 		fte.docker,
 		fte.tlp,
+		fte.vault,
 		time.Duration(fte.cfg.TerminalToolTimeout)*time.Second,
 	)
 
@@ -1659,6 +1712,7 @@ func (fte *flowToolsExecutor) GetMemoristExecutor(cfg MemoristExecutorConfig) (C
 		fte.cfg.TenantPrefix(),
 		fte.docker,
 		fte.tlp,
+		fte.vault,
 		time.Duration(fte.cfg.TerminalToolTimeout)*time.Second,
 	)
 
@@ -1691,6 +1745,8 @@ func (fte *flowToolsExecutor) GetMemoristExecutor(cfg MemoristExecutorConfig) (C
 	memory := NewMemoryTool(
 		fte.flowID,
 		fte.store,
+		fte.db,
+		fte.hybrid,
 		fte.vslp,
 	)
 	if memory.IsAvailable() {
@@ -1732,6 +1788,7 @@ func (fte *flowToolsExecutor) GetEnricherExecutor(cfg EnricherExecutorConfig) (C
 		fte.cfg.TenantPrefix(),
 		fte.docker,
 		fte.tlp,
+		fte.vault,
 		time.Duration(fte.cfg.TerminalToolTimeout)*time.Second,
 	)
 
@@ -1764,6 +1821,8 @@ func (fte *flowToolsExecutor) GetEnricherExecutor(cfg EnricherExecutorConfig) (C
 	memory := NewMemoryTool(
 		fte.flowID,
 		fte.store,
+		fte.db,
+		fte.hybrid,
 		fte.vslp,
 	)
 	if memory.IsAvailable() {
@@ -0,0 +1,152 @@
+package vault
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// minRedactLength keeps very short secrets from being redacted: replacing
+// every occurrence of two or three characters would garble the logs.
+const minRedactLength = 4
+
+// known holds the secrets of the flows loaded by this process until Forget
+// drops them. Traces and long-term memory are written by shared workers which
+// do not know the flow of the text, Redact replaces the secrets of every
+// loaded flow there; RedactFlow keeps the logs of a flow to its own secrets.
+var known = &registry{
+	flows:     make(map[int64]map[string]string),
+	replacers: make(map[int64]*strings.Replacer),
+}
+
+type registry struct {
+	mx        sync.RWMutex
+	flows     map[int64]map[string]string
+	replacers map[int64]*strings.Replacer
+	replacer  *strings.Replacer
+}
+
+// Redact replaces every secret of the loaded flows in text with its
+// {{cred:ID}} placeholder. Secrets are matched as they are and JSON-escaped,
+// so it applies to serialized documents as well.
+func Redact(text string) string {
+	known.mx.RLock()
+	replacer := known.replacer
+	known.mx.RUnlock()
+
+	return replace(replacer, text)
+}
+
+// RedactFlow replaces the secrets of the flow in text with their placeholders
+func RedactFlow(flowID int64, text string) string {
+	known.mx.RLock()
+	replacer := known.replacers[flowID]
+	known.mx.RUnlock()
+
+	return replace(replacer, text)
+}
+
+// Forget drops the secrets of the flow, it is called when the flow worker
+// stops. The secrets become known again when the flow is loaded next time.
+func Forget(flowID int64) {
+	known.forget(flowID)
+}
+
+func replace(replacer *strings.Replacer, text string) string {
+	if replacer == nil || text == "" {
+		return text
+	}
+	return replacer.Replace(text)
+}
+
+func (r *registry) add(flowID int64, secret, placeholder string) {
+	if len(secret) < minRedactLength {
+		return
+	}
+
+	variants := []string{secret}
+	if escaped, err := json.Marshal(secret); err == nil {
+		if s := string(escaped[1 : len(escaped)-1]); s != secret {
+			variants = append(variants, s)
+		}
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	placeholders, ok := r.flows[flowID]
+	if !ok {
+		placeholders = make(map[string]string)
+		r.flows[flowID] = placeholders
+	}
+
+	changed := false
+	for _, v := range variants {
+		if placeholders[v] != placeholder {
+			placeholders[v] = placeholder
+			changed = true
+		}
+	}
+	if changed {
+		r.replacers[flowID] = newReplacer(placeholders)
+		r.rebuild()
+	}
+}
+
+func (r *registry) forget(flowID int64) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if _, ok := r.flows[flowID]; !ok {
+		return
+	}
+
+	delete(r.flows, flowID)
+	delete(r.replacers, flowID)
+	r.rebuild()
+}
+
+// rebuild merges the secrets of all flows into the replacer of Redact, a
+// secret stored in several flows takes the placeholder of the earliest one.
+func (r *registry) rebuild() {
+	if len(r.flows) == 0 {
+		r.replacer = nil
+		return
+	}
+
+	flowIDs := make([]int64, 0, len(r.flows))
+	for flowID := range r.flows {
+		flowIDs = append(flowIDs, flowID)
+	}
+	slices.Sort(flowIDs)
+
+	placeholders := make(map[string]string)
+	for _, flowID := range flowIDs {
+		for secret, placeholder := range r.flows[flowID] {
+			if _, ok := placeholders[secret]; !ok {
+				placeholders[secret] = placeholder
+			}
+		}
+	}
+	r.replacer = newReplacer(placeholders)
+}
+
+// newReplacer orders secrets longest first: a secret which contains another
+// is replaced whole rather than around the shorter one.
+func newReplacer(placeholders map[string]string) *strings.Replacer {
+	secrets := make([]string, 0, len(placeholders))
+	for secret := range placeholders {
+		secrets = append(secrets, secret)
+	}
+	slices.SortFunc(secrets, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+
+	oldnew := make([]string, 0, 2*len(secrets))
+	for _, secret := range secrets {
+		oldnew = append(oldnew, secret, placeholders[secret])
+	}
+	return strings.NewReplacer(oldnew...)
+}
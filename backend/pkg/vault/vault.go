@@ -0,0 +1,244 @@
+// Package vault keeps the credentials of a flow encrypted at rest. Agents
+// refer to a stored secret by its {{cred:ID}} placeholder, which is replaced
+// with the secret only when a command runs, and every stored secret is
+// redacted from logs and traces.
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+)
+
+var placeholderRegex = regexp.MustCompile(`\{\{cred:(\d+)\}\}`)
+
+var ErrNotAvailable = errors.New("credential vault is not available")
+
+// Placeholder is the reference an agent uses instead of the secret
+func Placeholder(id int64) string {
+	return fmt.Sprintf("{{cred:%d}}", id)
+}
+
+// HasPlaceholders reports whether text refers to any stored credential
+func HasPlaceholders(text string) bool {
+	return placeholderRegex.MatchString(text)
+}
+
+type Credential struct {
+	ID         int64
+	Type       database.CredentialType
+	Username   string
+	Secret     string
+	SourceHost string
+	ToolcallID *int64
+	CreatedAt  time.Time
+}
+
+type Vault struct {
+	db     database.Querier
+	master []byte
+
+	mx     sync.Mutex
+	loaded map[int64]struct{}
+}
+
+// New creates the vault. The master key is derived with HKDF from
+// CREDENTIAL_VAULT_KEY or, when it is not set, from the cookie signing salt of
+// the instance under a label of its own, so the vault never shares key material
+// with the session cookies; without either the vault is not available.
+func New(db database.Querier, cfg *config.Config) *Vault {
+	v := &Vault{db: db, loaded: make(map[int64]struct{})}
+
+	secret, info := cfg.CredentialVaultKey, masterKeyInfo
+	if secret == "" {
+		secret, info = cfg.AuthSalt(), saltMasterKeyInfo
+	}
+	if secret != "" {
+		v.master = masterKey(secret, info)
+	}
+
+	return v
+}
+
+func (v *Vault) IsAvailable() bool {
+	return v != nil && v.db != nil && len(v.master) != 0
+}
+
+// Store seals and saves a credential of the flow. A credential with the same
+// type, username and host is replaced, keeping its ID and placeholder.
+func (v *Vault) Store(ctx context.Context, flowID int64, cred Credential) (Credential, error) {
+	if !v.IsAvailable() {
+		return Credential{}, ErrNotAvailable
+	}
+
+	secret, err := seal(flowKey(v.master, flowID), []byte(cred.Secret),
+		additionalData(cred.Type, cred.Username, cred.SourceHost))
+	if err != nil {
+		return Credential{}, err
+	}
+
+	row, err := v.db.UpsertFlowCredential(ctx, database.UpsertFlowCredentialParams{
+		FlowID:     flowID,
+		Type:       cred.Type,
+		Username:   cred.Username,
+		Secret:     secret,
+		SourceHost: cred.SourceHost,
+		ToolcallID: database.Int64ToNullInt64(cred.ToolcallID),
+	})
+	if err != nil {
+		return Credential{}, fmt.Errorf("failed to store credential: %w", err)
+	}
+
+	stored := toCredential(row, cred.Secret)
+	known.add(flowID, stored.Secret, Placeholder(stored.ID))
+
+	return stored, nil
+}
+
+// List opens every credential of the flow in the order they were stored
+func (v *Vault) List(ctx context.Context, flowID int64) ([]Credential, error) {
+	if !v.IsAvailable() {
+		return nil, ErrNotAvailable
+	}
+
+	rows, err := v.db.GetFlowCredentials(ctx, flowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow credentials: %w", err)
+	}
+
+	creds := make([]Credential, 0, len(rows))
+	for _, row := range rows {
+		cred, err := v.open(row)
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, cred)
+	}
+
+	return creds, nil
+}
+
+// Load makes the secrets of a flow known to Redact until Forget drops them. A
+// flow is loaded once, later credentials become known as they are stored.
+func (v *Vault) Load(ctx context.Context, flowID int64) error {
+	if !v.IsAvailable() {
+		return nil
+	}
+
+	v.mx.Lock()
+	_, ok := v.loaded[flowID]
+	v.mx.Unlock()
+	if ok {
+		return nil
+	}
+
+	if _, err := v.List(ctx, flowID); err != nil {
+		return err
+	}
+
+	v.mx.Lock()
+	v.loaded[flowID] = struct{}{}
+	v.mx.Unlock()
+
+	return nil
+}
+
+// Substitute replaces the {{cred:ID}} placeholders in text with the secrets
+// of the flow. A placeholder of a credential the flow does not have is an
+// error, the command must not run with the placeholder in it.
+func (v *Vault) Substitute(ctx context.Context, flowID int64, text string) (string, error) {
+	matches := placeholderRegex.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return text, nil
+	}
+	if !v.IsAvailable() {
+		return "", ErrNotAvailable
+	}
+
+	secrets := make(map[string]string, len(matches))
+	for _, m := range matches {
+		if _, ok := secrets[m[0]]; ok {
+			continue
+		}
+
+		id, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid credential placeholder %s: %w", m[0], err)
+		}
+		row, err := v.db.GetFlowCredential(ctx, database.GetFlowCredentialParams{ID: id, FlowID: flowID})
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("credential %d is not stored in this flow", id)
+		} else if err != nil {
+			return "", fmt.Errorf("failed to get credential %d: %w", id, err)
+		}
+
+		cred, err := v.open(row)
+		if err != nil {
+			return "", err
+		}
+		secrets[m[0]] = cred.Secret
+	}
+
+	return placeholderRegex.ReplaceAllStringFunc(text, func(placeholder string) string {
+		return secrets[placeholder]
+	}), nil
+}
+
+// SealFlowData seals data of another per-flow store under the key of the
+// flow. The purpose names the store: data sealed for one purpose does not open
+// for another.
+func (v *Vault) SealFlowData(flowID int64, purpose string, data []byte) ([]byte, error) {
+	if !v.IsAvailable() {
+		return nil, ErrNotAvailable
+	}
+	return seal(flowKey(v.master, flowID), data, []byte(purpose))
+}
+
+func (v *Vault) OpenFlowData(flowID int64, purpose string, sealed []byte) ([]byte, error) {
+	if !v.IsAvailable() {
+		return nil, ErrNotAvailable
+	}
+	return open(flowKey(v.master, flowID), sealed, []byte(purpose))
+}
+
+// FlowDigest is a keyed digest of data to look a sealed value up by, it tells
+// nothing about the value without the key of the flow.
+func (v *Vault) FlowDigest(flowID int64, purpose string, data []byte) ([]byte, error) {
+	if !v.IsAvailable() {
+		return nil, ErrNotAvailable
+	}
+	return digest(flowKey(v.master, flowID), []byte(purpose), data), nil
+}
+
+func (v *Vault) open(row database.FlowCredential) (Credential, error) {
+	secret, err := open(flowKey(v.master, row.FlowID), row.Secret,
+		additionalData(row.Type, row.Username, row.SourceHost))
+	if err != nil {
+		return Credential{}, fmt.Errorf("failed to open credential %d: %w", row.ID, err)
+	}
+
+	cred := toCredential(row, string(secret))
+	known.add(row.FlowID, cred.Secret, Placeholder(cred.ID))
+
+	return cred, nil
+}
+
+func toCredential(row database.FlowCredential, secret string) Credential {
+	return Credential{
+		ID:         row.ID,
+		Type:       row.Type,
+		Username:   row.Username,
+		Secret:     secret,
+		SourceHost: row.SourceHost,
+		ToolcallID: database.NullInt64ToInt64(row.ToolcallID),
+		CreatedAt:  row.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,246 @@
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCredentials keeps flow_credentials rows the way the upsert does
+type memoryCredentials struct {
+	database.Querier
+	rows []database.FlowCredential
+}
+
+func (m *memoryCredentials) UpsertFlowCredential(
+	_ context.Context, arg database.UpsertFlowCredentialParams,
+) (database.FlowCredential, error) {
+	for i, row := range m.rows {
+		if row.FlowID == arg.FlowID && row.Type == arg.Type &&
+			row.Username == arg.Username && row.SourceHost == arg.SourceHost {
+			m.rows[i].Secret = arg.Secret
+			if !row.ToolcallID.Valid {
+				m.rows[i].ToolcallID = arg.ToolcallID
+			}
+			return m.rows[i], nil
+		}
+	}
+
+	row := database.FlowCredential{
+		ID:         int64(len(m.rows) + 1),
+		FlowID:     arg.FlowID,
+		Type:       arg.Type,
+		Username:   arg.Username,
+		Secret:     arg.Secret,
+		SourceHost: arg.SourceHost,
+		ToolcallID: arg.ToolcallID,
+	}
+	m.rows = append(m.rows, row)
+	return row, nil
+}
+
+func (m *memoryCredentials) GetFlowCredentials(_ context.Context, flowID int64) ([]database.FlowCredential, error) {
+	var rows []database.FlowCredential
+	for _, row := range m.rows {
+		if row.FlowID == flowID {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (m *memoryCredentials) GetFlowCredential(
+	_ context.Context, arg database.GetFlowCredentialParams,
+) (database.FlowCredential, error) {
+	for _, row := range m.rows {
+		if row.ID == arg.ID && row.FlowID == arg.FlowID {
+			return row, nil
+		}
+	}
+	return database.FlowCredential{}, sql.ErrNoRows
+}
+
+func TestSealOpen(t *testing.T) {
+	t.Parallel()
+
+	master := []byte("0123456789abcdef0123456789abcdef")
+	ad := additionalData(database.CredentialTypePassword, "admin", "10.0.0.5")
+
+	sealed, err := seal(flowKey(master, 1), []byte("S3cret!"), ad)
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "S3cret!")
+
+	again, err := seal(flowKey(master, 1), []byte("S3cret!"), ad)
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every seal uses a new nonce")
+
+	plaintext, err := open(flowKey(master, 1), sealed, ad)
+	require.NoError(t, err)
+	assert.Equal(t, "S3cret!", string(plaintext))
+
+	_, err = open(flowKey(master, 2), sealed, ad)
+	assert.Error(t, err, "the key of another flow must not open it")
+
+	_, err = open(flowKey(master, 1), sealed, additionalData(database.CredentialTypePassword, "root", "10.0.0.5"))
+	assert.Error(t, err, "the secret is bound to its credential")
+
+	sealed[len(sealed)-1] ^= 0xff
+	_, err = open(flowKey(master, 1), sealed, ad)
+	assert.Error(t, err)
+
+	_, err = open(flowKey(master, 1), []byte("short"), ad)
+	assert.ErrorIs(t, err, errSecretTooShort)
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	db := &memoryCredentials{}
+	assert.False(t, New(db, &config.Config{}).IsAvailable(), "no key material")
+	assert.True(t, New(db, &config.Config{CookieSigningSalt: "salt"}).IsAvailable())
+	assert.True(t, New(db, &config.Config{CredentialVaultKey: "key"}).IsAvailable())
+
+	var v *Vault
+	assert.False(t, v.IsAvailable())
+
+	fromSalt := New(db, &config.Config{CookieSigningSalt: "salt"})
+	fromKey := New(db, &config.Config{CookieSigningSalt: "salt", CredentialVaultKey: "key"})
+	assert.NotEqual(t, fromSalt.master, fromKey.master, "the vault key takes precedence")
+
+	sameSecret := New(db, &config.Config{CredentialVaultKey: "salt"})
+	assert.NotEqual(t, fromSalt.master, sameSecret.master, "the salt fallback is derived under its own label")
+	assert.NotEqual(t, []byte("salt"), fromSalt.master)
+	assert.Len(t, fromKey.master, 32)
+}
+
+func TestVault(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := &memoryCredentials{}
+	v := New(db, &config.Config{CredentialVaultKey: "test-vault-key"})
+
+	toolcallID := int64(42)
+	cred, err := v.Store(ctx, 1, Credential{
+		Type:       database.CredentialTypePassword,
+		Username:   "admin",
+		Secret:     "Vault-Test-Pass#1",
+		SourceHost: "10.0.0.5",
+		ToolcallID: &toolcallID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cred.ID)
+	assert.NotContains(t, string(db.rows[0].Secret), "Vault-Test-Pass#1")
+
+	// storing the same credential again replaces the secret only
+	cred, err = v.Store(ctx, 1, Credential{
+		Type:       database.CredentialTypePassword,
+		Username:   "admin",
+		Secret:     "Vault-Test-Pass#2",
+		SourceHost: "10.0.0.5",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cred.ID)
+	require.NotNil(t, cred.ToolcallID)
+	assert.Equal(t, toolcallID, *cred.ToolcallID)
+
+	_, err = v.Store(ctx, 2, Credential{Type: database.CredentialTypeToken, Secret: "other-flow-token"})
+	require.NoError(t, err)
+
+	creds, err := v.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, creds, 1)
+	assert.Equal(t, "Vault-Test-Pass#2", creds[0].Secret)
+
+	command, err := v.Substitute(ctx, 1, "sshpass -p '{{cred:1}}' ssh admin@10.0.0.5 && echo {{cred:1}}")
+	require.NoError(t, err)
+	assert.Equal(t, "sshpass -p 'Vault-Test-Pass#2' ssh admin@10.0.0.5 && echo Vault-Test-Pass#2", command)
+
+	_, err = v.Substitute(ctx, 1, "curl -H 'Authorization: {{cred:2}}' http://10.0.0.5")
+	assert.ErrorContains(t, err, "credential 2 is not stored in this flow")
+
+	plain, err := v.Substitute(ctx, 1, "id")
+	require.NoError(t, err)
+	assert.Equal(t, "id", plain)
+
+	var unavailable *Vault
+	_, err = unavailable.Substitute(ctx, 1, "echo {{cred:1}}")
+	assert.ErrorIs(t, err, ErrNotAvailable)
+
+	require.NoError(t, v.Load(ctx, 1))
+	assert.Equal(t, "password is {{cred:1}}", Redact("password is Vault-Test-Pass#2"))
+}
+
+func TestFlowData(t *testing.T) {
+	t.Parallel()
+
+	v := New(&memoryCredentials{}, &config.Config{CredentialVaultKey: "test-vault-key"})
+
+	sealed, err := v.SealFlowData(1, "purpose-a", []byte("10.0.0.5"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "10.0.0.5")
+
+	data, err := v.OpenFlowData(1, "purpose-a", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.5", string(data))
+
+	_, err = v.OpenFlowData(1, "purpose-b", sealed)
+	assert.Error(t, err, "data sealed for one purpose must not open for another")
+	_, err = v.OpenFlowData(2, "purpose-a", sealed)
+	assert.Error(t, err, "the key of another flow must not open it")
+
+	digest, err := v.FlowDigest(1, "purpose-a", []byte("10.0.0.5"))
+	require.NoError(t, err)
+	again, err := v.FlowDigest(1, "purpose-a", []byte("10.0.0.5"))
+	require.NoError(t, err)
+	assert.Equal(t, digest, again, "the digest is stable to look values up by")
+	other, err := v.FlowDigest(2, "purpose-a", []byte("10.0.0.5"))
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, other)
+
+	var unavailable *Vault
+	_, err = unavailable.SealFlowData(1, "purpose-a", []byte("10.0.0.5"))
+	assert.ErrorIs(t, err, ErrNotAvailable)
+}
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	known.add(101, "redact-test-abc", Placeholder(101))
+	known.add(101, "redact-test-abcdef", Placeholder(102))
+	known.add(101, `redact"test\quote`, Placeholder(103))
+	known.add(101, "xyz", Placeholder(104))
+
+	assert.Equal(t, "{{cred:102}} and {{cred:101}}", Redact("redact-test-abcdef and redact-test-abc"),
+		"the longest secret wins")
+	assert.Equal(t, `{"input":"echo {{cred:103}}"}`, Redact(`{"input":"echo redact\"test\\quote"}`),
+		"JSON-escaped secrets are redacted as well")
+	assert.Equal(t, "xyz", Redact("xyz"), "short secrets are left alone")
+	assert.Empty(t, Redact(""))
+}
+
+func TestRedactFlow(t *testing.T) {
+	t.Parallel()
+
+	known.add(201, "flow-201-secret", Placeholder(201))
+	known.add(202, "flow-202-secret", Placeholder(202))
+
+	text := "flow-201-secret flow-202-secret"
+	assert.Equal(t, "{{cred:201}} flow-202-secret", RedactFlow(201, text), "a flow's logs know its own secrets only")
+	assert.Equal(t, "{{cred:201}} {{cred:202}}", Redact(text), "shared workers redact every loaded flow")
+	assert.Equal(t, text, RedactFlow(203, text))
+
+	Forget(201)
+	assert.Equal(t, text, RedactFlow(201, text))
+	assert.Equal(t, "flow-201-secret {{cred:202}}", Redact(text), "the secrets of a stopped flow are dropped")
+
+	Forget(202)
+	assert.Equal(t, text, Redact(text))
+	Forget(204)
+}
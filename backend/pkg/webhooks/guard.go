@@ -0,0 +1,167 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"pentagi/pkg/config"
+)
+
+var ErrForbiddenTarget = errors.New("webhook target is not allowed")
+
+// internalPrefixes are the ranges the net/netip predicates don't cover: the
+// "this network" block, carrier-grade NAT (cloud metadata of some providers)
+// and the benchmarking block
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// targetGuard keeps the deliveries away from the internal network of the
+// instance: loopback, private, link-local (cloud metadata), multicast and
+// unspecified addresses are refused unless WEBHOOK_ALLOW_PRIVATE is set or
+// the host is listed in WEBHOOK_ALLOWED_HOSTS
+type targetGuard struct {
+	allowPrivate bool
+	hosts        map[string]struct{}
+	prefixes     []netip.Prefix
+}
+
+func newTargetGuard(cfg *config.Config) *targetGuard {
+	g := &targetGuard{hosts: make(map[string]struct{})}
+	if cfg == nil {
+		return g
+	}
+
+	g.allowPrivate = cfg.WebhookAllowPrivate
+	for _, host := range cfg.WebhookAllowedHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(host); err == nil {
+			g.prefixes = append(g.prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(host); err == nil {
+			g.prefixes = append(g.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			g.hosts[host] = struct{}{}
+		}
+	}
+
+	return g
+}
+
+// CheckTargetURL refuses a webhook URL whose host is a literal internal IP or
+// a localhost name, hostnames are checked again on every connection
+func CheckTargetURL(cfg *config.Config, target *url.URL) error {
+	return newTargetGuard(cfg).checkHost(target.Hostname())
+}
+
+func (g *targetGuard) checkHost(host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if g.allowsHost(host) {
+		return nil
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return g.checkAddr(addr)
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s is a loopback host", ErrForbiddenTarget, host)
+	}
+
+	return nil
+}
+
+func (g *targetGuard) allowsHost(host string) bool {
+	if g.allowPrivate {
+		return true
+	}
+	_, ok := g.hosts[host]
+	return ok
+}
+
+func (g *targetGuard) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if g.allowPrivate || isPublicAddr(addr) {
+		return nil
+	}
+	for _, prefix := range g.prefixes {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s is an internal address", ErrForbiddenTarget, addr)
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// control runs after DNS resolution for every address the dialer connects to,
+// so a name which resolves to an internal address later (DNS rebinding) is
+// refused as well
+func (g *targetGuard) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: invalid address %s", ErrForbiddenTarget, address)
+	}
+	return g.checkAddr(addrPort.Addr())
+}
+
+// dialContext connects to the listed hosts as they are and to any other host
+// through the guarded dialer
+func (g *targetGuard) dialContext(timeout time.Duration) func(context.Context, string, string) (net.Conn, error) {
+	open := &net.Dialer{Timeout: timeout}
+	guarded := &net.Dialer{Timeout: timeout, Control: g.control}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if host, _, err := net.SplitHostPort(address); err == nil && g.allowsHost(strings.ToLower(host)) {
+			return open.DialContext(ctx, network, address)
+		}
+		return guarded.DialContext(ctx, network, address)
+	}
+}
+
+// checkResolved resolves the host of the target before a request goes through
+// a proxy, the proxy connects to it instead of the guarded dialer
+func (g *targetGuard) checkResolved(ctx context.Context, host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if err := g.checkHost(host); err != nil || g.allowsHost(host) {
+		return err
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if err := g.checkAddr(addr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,518 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/subscriptions"
+	"pentagi/pkg/system"
+	"pentagi/pkg/version"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventPing is sent by the test endpoint to check the receiver
+const EventPing = "ping"
+
+const (
+	HeaderEvent     = "X-PentAGI-Event"
+	HeaderDelivery  = "X-PentAGI-Delivery"
+	HeaderTimestamp = "X-PentAGI-Timestamp"
+	HeaderSignature = "X-PentAGI-Signature"
+)
+
+const (
+	queueSize         = 1024
+	workersCount      = 4
+	claimBatchSize    = 20
+	pollInterval      = 5 * time.Second
+	hooksCacheTTL     = 30 * time.Second
+	baseBackoff       = 10 * time.Second
+	maxBackoff        = time.Hour
+	maxResponseBytes  = 4 << 10 // 4KB
+	defaultMaxAttempt = 8
+	defaultTimeout    = 10 * time.Second
+)
+
+// Payload is the JSON body of every delivery, data is the same GraphQL model
+// which the flow subscriptions send for the event
+type Payload struct {
+	Event     string    `json:"event"`
+	WebhookID int64     `json:"webhook_id"`
+	UserID    int64     `json:"user_id"`
+	FlowID    *int64    `json:"flow_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Dispatcher turns flow events into webhook deliveries and sends them with
+// retries; deliveries are stored before they are sent, so pending ones
+// survive restarts and are shared between replicas without double sending.
+type Dispatcher struct {
+	db          database.Querier
+	client      *http.Client
+	guard       *targetGuard
+	proxied     bool
+	timeout     time.Duration
+	maxAttempts int
+	queue       chan subscriptions.FlowEvent
+	wake        chan struct{}
+	logger      *logrus.Entry
+
+	mx      sync.Mutex
+	hooks   []database.Webhook
+	hooksAt time.Time
+}
+
+func NewDispatcher(db database.Querier, cfg *config.Config) (*Dispatcher, error) {
+	client, err := system.GetHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhooks HTTP client: %w", err)
+	}
+
+	timeout := defaultTimeout
+	maxAttempts := defaultMaxAttempt
+	if cfg != nil {
+		if cfg.WebhookTimeout > 0 {
+			timeout = time.Duration(cfg.WebhookTimeout) * time.Second
+		}
+		if cfg.WebhookMaxAttempts > 0 {
+			maxAttempts = cfg.WebhookMaxAttempts
+		}
+	}
+	client.Timeout = timeout
+	// receivers answer themselves, a redirect to another host would leak the payload
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		client.Transport = transport
+	}
+
+	// through a proxy the transport connects to the proxy only, the target
+	// is checked when the request is sent
+	guard := newTargetGuard(cfg)
+	proxied := cfg != nil && cfg.ProxyURL != ""
+	if !proxied {
+		transport.Proxy = nil
+		transport.DialContext = guard.dialContext(timeout)
+	}
+
+	return &Dispatcher{
+		db:          db,
+		client:      client,
+		guard:       guard,
+		proxied:     proxied,
+		timeout:     timeout,
+		maxAttempts: maxAttempts,
+		queue:       make(chan subscriptions.FlowEvent, queueSize),
+		wake:        make(chan struct{}, 1),
+		logger:      logrus.WithField("component", "pentagi-webhooks"),
+	}, nil
+}
+
+// Start runs the event and the delivery loops until the context is done
+func (d *Dispatcher) Start(ctx context.Context) {
+	go d.runEvents(ctx)
+	go d.runDeliveries(ctx)
+}
+
+// HandleFlowEvent queues the event for the webhooks without blocking the publisher,
+// the event is dropped when the queue is full
+func (d *Dispatcher) HandleFlowEvent(_ context.Context, event subscriptions.FlowEvent) {
+	select {
+	case d.queue <- event:
+	default:
+		d.logger.WithFields(logrus.Fields{
+			"event":   event.Type,
+			"flow_id": event.FlowID,
+		}).Warn("webhooks queue is full, flow event is dropped")
+	}
+}
+
+// Invalidate drops the cached webhooks after they were changed
+func (d *Dispatcher) Invalidate() {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	d.hooks, d.hooksAt = nil, time.Time{}
+}
+
+// Ping sends a ping event to the webhook right away, it's never retried
+func (d *Dispatcher) Ping(ctx context.Context, hook database.Webhook) (database.WebhookDelivery, error) {
+	payload, err := json.Marshal(Payload{
+		Event:     EventPing,
+		WebhookID: hook.ID,
+		UserID:    hook.UserID,
+		CreatedAt: time.Now().UTC(),
+		Data:      map[string]any{"name": hook.Name},
+	})
+	if err != nil {
+		return database.WebhookDelivery{}, fmt.Errorf("failed to marshal ping payload: %w", err)
+	}
+
+	delivery, err := d.db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		WebhookID: hook.ID,
+		Event:     EventPing,
+		Payload:   payload,
+	})
+	if err != nil {
+		return database.WebhookDelivery{}, fmt.Errorf("failed to create ping delivery: %w", err)
+	}
+
+	return d.attempt(ctx, hook, delivery, 1)
+}
+
+// Replay queues a copy of the delivery, the original one stays in the log as it is
+func (d *Dispatcher) Replay(ctx context.Context, delivery database.WebhookDelivery) (database.WebhookDelivery, error) {
+	replay, err := d.db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		WebhookID: delivery.WebhookID,
+		Event:     delivery.Event,
+		FlowID:    delivery.FlowID,
+		Payload:   delivery.Payload,
+	})
+	if err != nil {
+		return database.WebhookDelivery{}, fmt.Errorf("failed to create replay delivery: %w", err)
+	}
+
+	d.notify()
+
+	return replay, nil
+}
+
+// Sign returns the signature of the X-PentAGI-Signature header: the hex HMAC-SHA256
+// of the timestamp header, a dot and the body, keyed with the webhook secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Matches tells whether the webhook wants the flow event, empty filters match everything
+// and an event filter like "task.*" matches all events of the kind
+func Matches(hook database.Webhook, event subscriptions.FlowEvent) bool {
+	if !hook.Enabled || hook.UserID != event.UserID {
+		return false
+	}
+
+	if len(hook.FlowIds) != 0 && !slices.Contains(hook.FlowIds, event.FlowID) {
+		return false
+	}
+
+	if len(hook.Events) == 0 {
+		return true
+	}
+
+	eventType := string(event.Type)
+	for _, filter := range hook.Events {
+		if filter == eventType || filter == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(filter, ".*"); ok && strings.HasPrefix(eventType, prefix+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidEventFilter checks an event filter of a webhook
+func ValidEventFilter(filter string) error {
+	if filter == "*" {
+		return nil
+	}
+
+	for _, eventType := range subscriptions.FlowEventTypes {
+		if filter == string(eventType) {
+			return nil
+		}
+		if prefix, ok := strings.CutSuffix(filter, ".*"); ok && strings.HasPrefix(string(eventType), prefix+".") {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown webhook event '%s'", filter)
+}
+
+// Backoff returns the pause after the given number of failed attempts
+func Backoff(attempts int) time.Duration {
+	backoff := baseBackoff
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) enabledHooks(ctx context.Context) ([]database.Webhook, error) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	// the cache expires to pick up the changes made on the other replicas
+	if !d.hooksAt.IsZero() && time.Since(d.hooksAt) < hooksCacheTTL {
+		return d.hooks, nil
+	}
+
+	hooks, err := d.db.GetEnabledWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d.hooks, d.hooksAt = hooks, time.Now()
+	return hooks, nil
+}
+
+func (d *Dispatcher) runEvents(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.queue:
+			if d.enqueue(ctx, event) {
+				d.notify()
+			}
+		}
+	}
+}
+
+// enqueue stores a delivery for every webhook which wants the event
+func (d *Dispatcher) enqueue(ctx context.Context, event subscriptions.FlowEvent) bool {
+	hooks, err := d.enabledHooks(ctx)
+	if err != nil {
+		d.logger.WithError(err).Error("failed to get webhooks")
+		return false
+	}
+
+	var queued bool
+	for _, hook := range hooks {
+		if !Matches(hook, event) {
+			continue
+		}
+
+		flowID := event.FlowID
+		payload, err := json.Marshal(Payload{
+			Event:     string(event.Type),
+			WebhookID: hook.ID,
+			UserID:    event.UserID,
+			FlowID:    &flowID,
+			CreatedAt: time.Now().UTC(),
+			Data:      event.Data,
+		})
+		if err != nil {
+			d.logger.WithError(err).WithField("event", event.Type).Error("failed to marshal webhook payload")
+			return queued
+		}
+
+		_, err = d.db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+			WebhookID: hook.ID,
+			Event:     string(event.Type),
+			FlowID:    sql.NullInt64{Int64: event.FlowID, Valid: true},
+			Payload:   payload,
+		})
+		if err != nil {
+			d.logger.WithError(err).WithField("webhook_id", hook.ID).Error("failed to create webhook delivery")
+			continue
+		}
+		queued = true
+	}
+
+	return queued
+}
+
+func (d *Dispatcher) runDeliveries(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+
+		// a full batch means there may be more due deliveries
+		for ctx.Err() == nil {
+			if d.deliverDue(ctx) < claimBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// deliverDue claims the due deliveries and sends them, it returns the number of claimed ones
+func (d *Dispatcher) deliverDue(ctx context.Context) int {
+	// the claim moves the next attempt past the request timeout, so another
+	// replica doesn't take the same delivery while it's being sent
+	deliveries, err := d.db.ClaimWebhookDeliveries(ctx, database.ClaimWebhookDeliveriesParams{
+		NextAttemptAt: time.Now().Add(2*d.timeout + pollInterval),
+		Limit:         claimBatchSize,
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.WithError(err).Error("failed to claim webhook deliveries")
+		}
+		return 0
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, workersCount)
+	)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(delivery database.WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			d.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery database.WebhookDelivery) {
+	logger := d.logger.WithFields(logrus.Fields{
+		"webhook_id":  delivery.WebhookID,
+		"delivery_id": delivery.ID,
+	})
+
+	hook, err := d.db.GetWebhook(ctx, delivery.WebhookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return // the webhook was deleted with its deliveries
+	} else if err != nil {
+		logger.WithError(err).Error("failed to get webhook")
+		return
+	}
+
+	if !hook.Enabled {
+		_, err = d.db.UpdateWebhookDeliveryResult(ctx, database.UpdateWebhookDeliveryResultParams{
+			Status:        database.WebhookDeliveryStatusFailed,
+			Attempts:      delivery.Attempts,
+			NextAttemptAt: delivery.NextAttemptAt,
+			Error:         "webhook is disabled",
+			ID:            delivery.ID,
+		})
+		if err != nil {
+			logger.WithError(err).Error("failed to update webhook delivery")
+		}
+		return
+	}
+
+	if _, err := d.attempt(ctx, hook, delivery, d.maxAttempts); err != nil {
+		logger.WithError(err).Error("failed to update webhook delivery")
+	}
+}
+
+// attempt sends the delivery once and stores the result, the delivery stays
+// pending for the next attempt until maxAttempts is reached
+func (d *Dispatcher) attempt(
+	ctx context.Context,
+	hook database.Webhook,
+	delivery database.WebhookDelivery,
+	maxAttempts int,
+) (database.WebhookDelivery, error) {
+	now := time.Now()
+	params := database.UpdateWebhookDeliveryResultParams{
+		Status:   database.WebhookDeliveryStatusPending,
+		Attempts: delivery.Attempts + 1,
+		ID:       delivery.ID,
+	}
+
+	status, body, err := d.send(ctx, hook, delivery)
+	if status != 0 {
+		params.ResponseStatus = sql.NullInt32{Int32: int32(status), Valid: true}
+		params.ResponseBody = body
+	}
+
+	switch {
+	case err == nil && status >= http.StatusOK && status < http.StatusMultipleChoices:
+		params.Status = database.WebhookDeliveryStatusSuccess
+		params.DeliveredAt = sql.NullTime{Time: now, Valid: true}
+	case err != nil:
+		params.Error = err.Error()
+	default:
+		params.Error = fmt.Sprintf("unexpected response status %d", status)
+	}
+
+	if params.Status == database.WebhookDeliveryStatusPending {
+		if int(params.Attempts) >= maxAttempts {
+			params.Status = database.WebhookDeliveryStatusFailed
+		} else {
+			params.NextAttemptAt = now.Add(Backoff(int(params.Attempts)))
+		}
+	}
+	if params.NextAttemptAt.IsZero() {
+		params.NextAttemptAt = now
+	}
+
+	// the result is stored even if the request context is gone
+	return d.db.UpdateWebhookDeliveryResult(context.WithoutCancel(ctx), params)
+}
+
+func (d *Dispatcher) send(ctx context.Context, hook database.Webhook, delivery database.WebhookDelivery) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid webhook request: %w", err)
+	}
+
+	if d.proxied {
+		if err := d.guard.checkResolved(ctx, req.URL.Hostname()); err != nil {
+			return 0, "", err
+		}
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PentAGI-Webhooks/"+version.GetBinaryVersion())
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if hook.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, delivery.Payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return resp.StatusCode, "", fmt.Errorf("failed to read webhook response: %w", err)
+	}
+
+	return resp.StatusCode, strings.ToValidUTF8(string(body), ""), nil
+}
@@ -0,0 +1,324 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"pentagi/pkg/config"
+	"pentagi/pkg/database"
+	"pentagi/pkg/graph/subscriptions"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQuerier struct {
+	database.Querier
+
+	mx         sync.Mutex
+	hooks      []database.Webhook
+	deliveries map[int64]database.WebhookDelivery
+	nextID     int64
+}
+
+func newFakeQuerier(hooks ...database.Webhook) *fakeQuerier {
+	return &fakeQuerier{hooks: hooks, deliveries: map[int64]database.WebhookDelivery{}}
+}
+
+func (q *fakeQuerier) GetEnabledWebhooks(context.Context) ([]database.Webhook, error) {
+	return q.hooks, nil
+}
+
+func (q *fakeQuerier) GetWebhook(_ context.Context, id int64) (database.Webhook, error) {
+	for _, hook := range q.hooks {
+		if hook.ID == id {
+			return hook, nil
+		}
+	}
+	return database.Webhook{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) CreateWebhookDelivery(
+	_ context.Context, arg database.CreateWebhookDeliveryParams,
+) (database.WebhookDelivery, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	q.nextID++
+	delivery := database.WebhookDelivery{
+		ID:            q.nextID,
+		WebhookID:     arg.WebhookID,
+		Event:         arg.Event,
+		FlowID:        arg.FlowID,
+		Payload:       arg.Payload,
+		Status:        database.WebhookDeliveryStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	q.deliveries[delivery.ID] = delivery
+	return delivery, nil
+}
+
+func (q *fakeQuerier) UpdateWebhookDeliveryResult(
+	_ context.Context, arg database.UpdateWebhookDeliveryResultParams,
+) (database.WebhookDelivery, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	delivery := q.deliveries[arg.ID]
+	delivery.Status = arg.Status
+	delivery.Attempts = arg.Attempts
+	delivery.NextAttemptAt = arg.NextAttemptAt
+	delivery.ResponseStatus = arg.ResponseStatus
+	delivery.ResponseBody = arg.ResponseBody
+	delivery.Error = arg.Error
+	delivery.DeliveredAt = arg.DeliveredAt
+	q.deliveries[arg.ID] = delivery
+	return delivery, nil
+}
+
+func newTestDispatcher(t *testing.T, db database.Querier) *Dispatcher {
+	t.Helper()
+
+	d, err := NewDispatcher(db, &config.Config{
+		WebhookMaxAttempts:  3,
+		WebhookTimeout:      5,
+		WebhookAllowedHosts: []string{"127.0.0.1"},
+	})
+	require.NoError(t, err)
+	return d
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		Sign("secret", "1700000000", []byte("{}")),
+	)
+	assert.NotEqual(t, Sign("secret", "1", []byte("body")), Sign("secret", "2", []byte("body")))
+	assert.NotEqual(t, Sign("secret", "1", []byte("body")), Sign("other", "1", []byte("body")))
+}
+
+func TestMatches(t *testing.T) {
+	event := subscriptions.FlowEvent{Type: subscriptions.FlowEventTaskCreated, UserID: 1, FlowID: 10}
+
+	tests := []struct {
+		name string
+		hook database.Webhook
+		want bool
+	}{
+		{"all events", database.Webhook{UserID: 1, Enabled: true}, true},
+		{"disabled", database.Webhook{UserID: 1}, false},
+		{"other user", database.Webhook{UserID: 2, Enabled: true}, false},
+		{"exact event", database.Webhook{UserID: 1, Enabled: true, Events: []string{"task.created"}}, true},
+		{"wildcard event", database.Webhook{UserID: 1, Enabled: true, Events: []string{"task.*"}}, true},
+		{"other event", database.Webhook{UserID: 1, Enabled: true, Events: []string{"flow.*"}}, false},
+		{"flow filter", database.Webhook{UserID: 1, Enabled: true, FlowIds: []int64{10}}, true},
+		{"other flow", database.Webhook{UserID: 1, Enabled: true, FlowIds: []int64{11}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Matches(tt.hook, event))
+		})
+	}
+}
+
+func TestValidEventFilter(t *testing.T) {
+	assert.NoError(t, ValidEventFilter("*"))
+	assert.NoError(t, ValidEventFilter("flow.updated"))
+	assert.NoError(t, ValidEventFilter("task.*"))
+	assert.Error(t, ValidEventFilter("flow.exploded"))
+	assert.Error(t, ValidEventFilter("nothing.*"))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, Backoff(1))
+	assert.Equal(t, 20*time.Second, Backoff(2))
+	assert.Equal(t, 80*time.Second, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
+
+func TestDeliverySignedAndStored(t *testing.T) {
+	var (
+		gotHeaders http.Header
+		gotBody    []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	hook := database.Webhook{ID: 7, UserID: 1, Name: "ci", Url: srv.URL, Secret: "s3cr3t", Enabled: true}
+	db := newFakeQuerier(hook)
+	d := newTestDispatcher(t, db)
+
+	ctx := context.Background()
+	require.True(t, d.enqueue(ctx, subscriptions.FlowEvent{
+		Type:   subscriptions.FlowEventFlowUpdated,
+		UserID: 1,
+		FlowID: 10,
+		Data:   map[string]any{"id": "10"},
+	}))
+	assert.Equal(t, 1, d.deliverDueFrom(ctx, db))
+
+	delivery := db.deliveries[1]
+	assert.Equal(t, database.WebhookDeliveryStatusSuccess, delivery.Status)
+	assert.Equal(t, int32(1), delivery.Attempts)
+	assert.Equal(t, sql.NullInt32{Int32: http.StatusAccepted, Valid: true}, delivery.ResponseStatus)
+	assert.Equal(t, "ok", delivery.ResponseBody)
+	assert.True(t, delivery.DeliveredAt.Valid)
+
+	assert.Equal(t, "flow.updated", gotHeaders.Get(HeaderEvent))
+	assert.Equal(t, "1", gotHeaders.Get(HeaderDelivery))
+	assert.Equal(t, Sign("s3cr3t", gotHeaders.Get(HeaderTimestamp), gotBody), gotHeaders.Get(HeaderSignature))
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(gotBody, &payload))
+	assert.Equal(t, "flow.updated", payload.Event)
+	assert.Equal(t, int64(7), payload.WebhookID)
+	require.NotNil(t, payload.FlowID)
+	assert.Equal(t, int64(10), *payload.FlowID)
+}
+
+func TestDeliveryRetriesUntilFailed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	hook := database.Webhook{ID: 1, UserID: 1, Url: srv.URL, Enabled: true}
+	db := newFakeQuerier(hook)
+	d := newTestDispatcher(t, db)
+
+	ctx := context.Background()
+	delivery, err := db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		WebhookID: hook.ID,
+		Event:     "flow.updated",
+		Payload:   json.RawMessage(`{}`),
+	})
+	require.NoError(t, err)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		delivery, err = d.attempt(ctx, hook, delivery, d.maxAttempts)
+		require.NoError(t, err)
+		assert.Equal(t, int32(attempt), delivery.Attempts)
+		assert.Contains(t, delivery.Error, "500")
+
+		if attempt < 3 {
+			assert.Equal(t, database.WebhookDeliveryStatusPending, delivery.Status)
+			assert.True(t, delivery.NextAttemptAt.After(time.Now()))
+		}
+	}
+	assert.Equal(t, database.WebhookDeliveryStatusFailed, delivery.Status)
+}
+
+func TestPingIsNotRetried(t *testing.T) {
+	hook := database.Webhook{ID: 1, UserID: 1, Url: "http://127.0.0.1:1", Enabled: true}
+	db := newFakeQuerier(hook)
+	d := newTestDispatcher(t, db)
+
+	delivery, err := d.Ping(context.Background(), hook)
+	require.NoError(t, err)
+	assert.Equal(t, EventPing, delivery.Event)
+	assert.Equal(t, database.WebhookDeliveryStatusFailed, delivery.Status)
+	assert.NotEmpty(t, delivery.Error)
+}
+
+// deliverDueFrom sends all pending deliveries of the fake querier, it stands in
+// for ClaimWebhookDeliveries which relies on the postgres row locking
+func (d *Dispatcher) deliverDueFrom(ctx context.Context, db *fakeQuerier) int {
+	var pending []database.WebhookDelivery
+	for _, delivery := range db.deliveries {
+		if delivery.Status == database.WebhookDeliveryStatusPending {
+			pending = append(pending, delivery)
+		}
+	}
+	for _, delivery := range pending {
+		d.deliver(ctx, delivery)
+	}
+	return len(pending)
+}
+
+func TestTargetGuard(t *testing.T) {
+	guard := newTargetGuard(&config.Config{})
+	for _, addr := range []string{
+		"127.0.0.1", "::1", "::ffff:127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "fd00::1",
+		"169.254.169.254", "fe80::1", "224.0.0.1", "ff02::1", "0.0.0.0", "::", "100.100.100.200",
+	} {
+		assert.ErrorIs(t, guard.checkAddr(netip.MustParseAddr(addr)), ErrForbiddenTarget, addr)
+	}
+	for _, addr := range []string{"8.8.8.8", "203.0.113.7", "2001:4860:4860::8888"} {
+		assert.NoError(t, guard.checkAddr(netip.MustParseAddr(addr)), addr)
+	}
+
+	allowed := newTargetGuard(&config.Config{WebhookAllowedHosts: []string{"10.0.0.0/8", "192.168.1.5", " CI.Internal "}})
+	assert.NoError(t, allowed.checkAddr(netip.MustParseAddr("10.1.2.3")))
+	assert.NoError(t, allowed.checkAddr(netip.MustParseAddr("192.168.1.5")))
+	assert.ErrorIs(t, allowed.checkAddr(netip.MustParseAddr("192.168.1.6")), ErrForbiddenTarget)
+	assert.True(t, allowed.allowsHost("ci.internal"))
+
+	anyTarget := newTargetGuard(&config.Config{WebhookAllowPrivate: true})
+	assert.NoError(t, anyTarget.checkAddr(netip.MustParseAddr("169.254.169.254")))
+}
+
+func TestCheckTargetURL(t *testing.T) {
+	for rawURL, allowed := range map[string]bool{
+		"https://hooks.example.com/ci":       true,
+		"https://203.0.113.7/ci":             true,
+		"http://169.254.169.254/latest/meta": false,
+		"http://[::1]:8080/":                 false,
+		"http://localhost:8080/":             false,
+		"http://api.localhost/":              false,
+		"http://10.0.0.5/":                   false,
+	} {
+		target, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		if allowed {
+			assert.NoError(t, CheckTargetURL(&config.Config{}, target), rawURL)
+		} else {
+			assert.ErrorIs(t, CheckTargetURL(&config.Config{}, target), ErrForbiddenTarget, rawURL)
+		}
+	}
+
+	target, _ := url.Parse("http://localhost:8080/")
+	assert.NoError(t, CheckTargetURL(&config.Config{WebhookAllowedHosts: []string{"localhost"}}, target))
+	assert.NoError(t, CheckTargetURL(&config.Config{WebhookAllowPrivate: true}, target))
+}
+
+func TestDeliveryRefusesInternalTarget(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = w.Write([]byte("internal secret"))
+	}))
+	defer srv.Close()
+
+	target, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	// the name resolves to the loopback address, it's refused after the resolution
+	for _, hookURL := range []string{srv.URL, "http://localhost:" + target.Port()} {
+		hook := database.Webhook{ID: 1, UserID: 1, Url: hookURL, Enabled: true}
+		d, err := NewDispatcher(newFakeQuerier(hook), &config.Config{WebhookTimeout: 5})
+		require.NoError(t, err)
+
+		delivery, err := d.Ping(context.Background(), hook)
+		require.NoError(t, err)
+		assert.Equal(t, database.WebhookDeliveryStatusFailed, delivery.Status, hookURL)
+		assert.Contains(t, delivery.Error, ErrForbiddenTarget.Error(), hookURL)
+		assert.Empty(t, delivery.ResponseBody, hookURL)
+	}
+	assert.Zero(t, hits, "internal services are never reached")
+}
@@ -6,6 +6,25 @@ INNER JOIN flows f ON al.flow_id = f.id
 WHERE al.flow_id = $1 AND f.deleted_at IS NULL
 ORDER BY al.created_at ASC;
 
+-- name: GetFlowAgentLogsPage :many
+SELECT
+  al.*
+FROM agentlogs al
+INNER JOIN flows f ON al.flow_id = f.id
+WHERE al.flow_id = sqlc.arg(flow_id) AND f.deleted_at IS NULL
+  AND (sqlc.narg(after_id)::bigint IS NULL OR al.id > sqlc.narg(after_id)::bigint)
+  AND (sqlc.narg(before_id)::bigint IS NULL OR al.id < sqlc.narg(before_id)::bigint)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR al.created_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(until)::timestamptz IS NULL OR al.created_at < sqlc.narg(until)::timestamptz)
+  AND (sqlc.narg(task_id)::bigint IS NULL OR al.task_id = sqlc.narg(task_id)::bigint)
+  AND (sqlc.narg(subtask_id)::bigint IS NULL OR al.subtask_id = sqlc.narg(subtask_id)::bigint)
+  AND (cardinality(sqlc.arg(types)::text[]) = 0 OR al.executor::text = ANY(sqlc.arg(types)::text[]))
+  AND (sqlc.arg(search)::text = '' OR search_document(al.task, al.result) @@ websearch_to_tsquery('english', sqlc.arg(search)::text))
+ORDER BY
+  CASE WHEN sqlc.arg(reverse)::bool THEN al.id END DESC,
+  al.id ASC
+LIMIT sqlc.narg(page_size)::int;
+
 -- name: GetUserFlowAgentLogs :many
 SELECT
   al.*
@@ -0,0 +1,64 @@
+-- name: GetUserAnonymizationPatterns :many
+SELECT
+  p.*
+FROM anonymization_patterns p
+INNER JOIN users u ON p.user_id = u.id
+WHERE p.user_id = $1
+ORDER BY p.name ASC;
+
+-- name: GetUserAnonymizationPattern :one
+SELECT
+  p.*
+FROM anonymization_patterns p
+INNER JOIN users u ON p.user_id = u.id
+WHERE p.id = $1 AND p.user_id = $2;
+
+-- name: CreateUserAnonymizationPattern :one
+INSERT INTO anonymization_patterns (
+  user_id,
+  name,
+  regex,
+  enabled
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: UpdateUserAnonymizationPattern :one
+UPDATE anonymization_patterns
+SET name = $1, regex = $2, enabled = $3
+WHERE id = $4 AND user_id = $5
+RETURNING *;
+
+-- name: DeleteUserAnonymizationPattern :exec
+DELETE FROM anonymization_patterns
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetFlowAnonymizationEntries :many
+SELECT
+  e.*
+FROM flow_anonymization_entries e
+WHERE e.flow_id = $1
+ORDER BY e.id ASC;
+
+-- name: GetFlowAnonymizationEntry :one
+SELECT
+  e.*
+FROM flow_anonymization_entries e
+WHERE e.id = $1 AND e.flow_id = $2;
+
+-- name: UpsertFlowAnonymizationEntry :one
+-- A value seen again keeps its first entry, so its placeholder never changes
+-- within a flow.
+INSERT INTO flow_anonymization_entries (
+  flow_id,
+  kind,
+  value,
+  value_digest
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT ON CONSTRAINT flow_anonymization_entries_flow_digest_key
+DO UPDATE SET
+  flow_id = flow_anonymization_entries.flow_id
+RETURNING *;
@@ -46,9 +46,13 @@ INSERT INTO api_tokens (
   role_id,
   name,
   ttl,
-  status
+  status,
+  privileges,
+  flow_ids,
+  template_ids,
+  allowed_ips
 ) VALUES (
-  $1, $2, $3, $4, $5, $6
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
 RETURNING *;
 
@@ -7,6 +7,24 @@ INNER JOIN flows f ON al.flow_id = f.id
 WHERE al.flow_id = $1 AND al.assistant_id = $2 AND f.deleted_at IS NULL AND a.deleted_at IS NULL
 ORDER BY al.created_at ASC;
 
+-- name: GetFlowAssistantLogsPage :many
+SELECT
+  al.*
+FROM assistantlogs al
+INNER JOIN assistants a ON al.assistant_id = a.id
+INNER JOIN flows f ON al.flow_id = f.id
+WHERE al.flow_id = sqlc.arg(flow_id) AND al.assistant_id = sqlc.arg(assistant_id) AND f.deleted_at IS NULL AND a.deleted_at IS NULL
+  AND (sqlc.narg(after_id)::bigint IS NULL OR al.id > sqlc.narg(after_id)::bigint)
+  AND (sqlc.narg(before_id)::bigint IS NULL OR al.id < sqlc.narg(before_id)::bigint)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR al.created_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(until)::timestamptz IS NULL OR al.created_at < sqlc.narg(until)::timestamptz)
+  AND (cardinality(sqlc.arg(types)::text[]) = 0 OR al.type::text = ANY(sqlc.arg(types)::text[]))
+  AND (sqlc.arg(search)::text = '' OR search_document(al.message, al.thinking, al.result) @@ websearch_to_tsquery('english', sqlc.arg(search)::text))
+ORDER BY
+  CASE WHEN sqlc.arg(reverse)::bool THEN al.id END DESC,
+  al.id ASC
+LIMIT sqlc.narg(page_size)::int;
+
 -- name: GetUserFlowAssistantLogs :many
 SELECT
   al.*
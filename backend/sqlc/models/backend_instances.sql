@@ -0,0 +1,72 @@
+-- name: RegisterBackendInstance :one
+INSERT INTO backend_instances (
+  name,
+  expires_at
+) VALUES (
+  sqlc.arg(name),
+  CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(ttl_seconds)::int)
+)
+ON CONFLICT (name) DO UPDATE
+SET
+  started_at = CURRENT_TIMESTAMP,
+  heartbeat_at = CURRENT_TIMESTAMP,
+  expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: RenewBackendInstance :one
+INSERT INTO backend_instances (
+  name,
+  expires_at
+) VALUES (
+  sqlc.arg(name),
+  CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(ttl_seconds)::int)
+)
+ON CONFLICT (name) DO UPDATE
+SET
+  heartbeat_at = CURRENT_TIMESTAMP,
+  expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: GetBackendInstances :many
+SELECT
+  i.*,
+  (i.expires_at > CURRENT_TIMESTAMP)::bool AS alive,
+  COUNT(l.flow_id) AS flows_count
+FROM backend_instances i
+LEFT JOIN flow_leases l ON l.owner = i.name AND l.expires_at > CURRENT_TIMESTAMP
+GROUP BY i.name
+ORDER BY i.started_at ASC, i.name ASC;
+
+-- name: DeleteBackendInstance :exec
+DELETE FROM backend_instances
+WHERE name = $1;
+
+-- name: DeleteExpiredBackendInstances :exec
+DELETE FROM backend_instances
+WHERE expires_at < $1;
+
+-- name: AcquireBackendLeader :one
+INSERT INTO backend_leader (
+  owner,
+  expires_at
+) VALUES (
+  sqlc.arg(owner),
+  CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(ttl_seconds)::int)
+)
+ON CONFLICT (id) DO UPDATE
+SET
+  owner = EXCLUDED.owner,
+  acquired_at = CASE WHEN backend_leader.owner = EXCLUDED.owner THEN backend_leader.acquired_at ELSE CURRENT_TIMESTAMP END,
+  expires_at = EXCLUDED.expires_at
+WHERE backend_leader.owner = EXCLUDED.owner OR backend_leader.expires_at < CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetBackendLeader :one
+SELECT
+  b.*
+FROM backend_leader b
+WHERE b.expires_at > CURRENT_TIMESTAMP;
+
+-- name: ReleaseBackendLeader :exec
+DELETE FROM backend_leader
+WHERE owner = $1;
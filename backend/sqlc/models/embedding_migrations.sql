@@ -0,0 +1,160 @@
+-- name: GetEmbeddingCollection :one
+SELECT
+  ec.*
+FROM embedding_collections ec
+WHERE ec.name = $1;
+
+-- name: UpsertEmbeddingCollection :one
+INSERT INTO embedding_collections (
+  name,
+  provider,
+  model,
+  dimensions
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (name) DO UPDATE
+SET
+  provider = EXCLUDED.provider,
+  model = EXCLUDED.model,
+  dimensions = EXCLUDED.dimensions
+RETURNING *;
+
+-- name: GetEmbeddingCollectionStats :one
+-- Counts the vectors of a collection and those still waiting for a vector of
+-- the migration target; dimensions is taken from any stored vector, 0 when empty.
+SELECT
+  COUNT(e.uuid)::bigint                                         AS total_docs,
+  (COUNT(e.uuid) FILTER (
+    WHERE e.embedding IS NOT NULL AND e.embedding_next IS NULL
+  ))::bigint                                                    AS pending_docs,
+  COALESCE((
+    SELECT vector_dims(e2.embedding)
+    FROM langchain_pg_embedding e2
+    WHERE e2.collection_id = c.uuid AND e2.embedding IS NOT NULL
+    LIMIT 1
+  ), 0)::int                                                    AS dimensions
+FROM langchain_pg_collection c
+LEFT JOIN langchain_pg_embedding e ON e.collection_id = c.uuid
+WHERE c.name = sqlc.arg(collection)::text
+GROUP BY c.uuid;
+
+-- name: GetEmbeddingMigration :one
+SELECT
+  m.*
+FROM embedding_migrations m
+WHERE m.id = $1;
+
+-- name: GetLatestEmbeddingMigration :one
+SELECT
+  m.*
+FROM embedding_migrations m
+WHERE m.collection = $1
+ORDER BY m.id DESC
+LIMIT 1;
+
+-- name: GetRunningEmbeddingMigrations :many
+SELECT
+  m.*
+FROM embedding_migrations m
+WHERE m.status = 'running'
+ORDER BY m.id;
+
+-- name: CreateEmbeddingMigration :one
+INSERT INTO embedding_migrations (
+  collection,
+  source_provider,
+  source_model,
+  source_dimensions,
+  target_provider,
+  target_model,
+  total_docs
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: UpdateEmbeddingMigration :one
+UPDATE embedding_migrations
+SET
+  status = $1,
+  target_dimensions = $2,
+  total_docs = $3,
+  processed_docs = $4,
+  failed_docs = $5,
+  error = $6,
+  finished_at = CASE WHEN $1 = 'running' THEN NULL ELSE CURRENT_TIMESTAMP END
+WHERE id = $7
+RETURNING *;
+
+-- name: CompleteEmbeddingMigration :one
+-- Moves the target vectors into place, records the target model for the
+-- collection and marks the migration completed in a single statement.
+WITH m AS (
+  SELECT collection, target_provider, target_model, target_dimensions
+  FROM embedding_migrations
+  WHERE id = sqlc.arg(id)
+), swapped AS (
+  UPDATE langchain_pg_embedding e
+  SET
+    embedding = e.embedding_next,
+    embedding_next = NULL
+  FROM langchain_pg_collection c, m
+  WHERE e.collection_id = c.uuid
+    AND c.name = m.collection
+    AND e.embedding_next IS NOT NULL
+  RETURNING e.uuid
+), recorded AS (
+  INSERT INTO embedding_collections (name, provider, model, dimensions)
+  SELECT m.collection, m.target_provider, m.target_model, m.target_dimensions
+  FROM m
+  ON CONFLICT (name) DO UPDATE
+  SET
+    provider = EXCLUDED.provider,
+    model = EXCLUDED.model,
+    dimensions = EXCLUDED.dimensions
+  RETURNING name
+)
+UPDATE embedding_migrations j
+SET
+  status = 'completed',
+  error = NULL,
+  finished_at = CURRENT_TIMESTAMP
+WHERE j.id = sqlc.arg(id)
+RETURNING j.*;
+
+-- name: GetPendingReembedDocuments :many
+-- Returns documents of a collection that have no vector of the migration
+-- target yet, including documents added while the migration is running.
+SELECT
+  e.uuid::text             AS id,
+  COALESCE(e.document, '') AS document
+FROM langchain_pg_embedding e
+INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+WHERE c.name = sqlc.arg(collection)::text
+  AND e.embedding IS NOT NULL
+  AND e.embedding_next IS NULL
+ORDER BY e.uuid
+LIMIT sqlc.arg(lim)::int;
+
+-- name: SetReembedDocumentVector :exec
+-- embedding must be formatted as a PostgreSQL vector literal: '[f1,f2,...]'
+UPDATE langchain_pg_embedding
+SET embedding_next = sqlc.arg(embedding)::vector
+WHERE uuid::text = sqlc.arg(uuid);
+
+-- name: KeepReembedDocumentVector :exec
+-- Marks a document the target model failed to embed as processed; it keeps
+-- its old vector and stays reachable through full-text search only.
+UPDATE langchain_pg_embedding
+SET embedding_next = embedding
+WHERE uuid::text = sqlc.arg(uuid);
+
+-- name: ResetReembedVectors :exec
+-- Drops partial target vectors left by an abandoned migration.
+UPDATE langchain_pg_embedding e
+SET embedding_next = NULL
+FROM langchain_pg_collection c
+WHERE e.collection_id = c.uuid
+  AND c.name = sqlc.arg(collection)::text
+  AND e.embedding_next IS NOT NULL;
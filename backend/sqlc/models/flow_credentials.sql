@@ -0,0 +1,31 @@
+-- name: GetFlowCredential :one
+SELECT
+  c.*
+FROM flow_credentials c
+WHERE c.id = $1 AND c.flow_id = $2;
+
+-- name: GetFlowCredentials :many
+SELECT
+  c.*
+FROM flow_credentials c
+WHERE c.flow_id = $1
+ORDER BY c.id ASC;
+
+-- name: UpsertFlowCredential :one
+-- A credential is unique by type, username and host within a flow: storing
+-- it again replaces the secret and keeps the toolcall which found it first.
+INSERT INTO flow_credentials (
+  flow_id,
+  type,
+  username,
+  secret,
+  source_host,
+  toolcall_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT ON CONSTRAINT flow_credentials_flow_type_username_host_key
+DO UPDATE SET
+  secret = EXCLUDED.secret,
+  toolcall_id = COALESCE(flow_credentials.toolcall_id, EXCLUDED.toolcall_id)
+RETURNING *;
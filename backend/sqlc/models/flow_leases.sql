@@ -0,0 +1,85 @@
+-- name: AcquireFlowLease :one
+INSERT INTO flow_leases (
+  flow_id,
+  owner,
+  expires_at
+) VALUES (
+  sqlc.arg(flow_id),
+  sqlc.arg(owner),
+  CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(ttl_seconds)::int)
+)
+ON CONFLICT (flow_id) DO UPDATE
+SET
+  owner = EXCLUDED.owner,
+  acquired_at = CASE WHEN flow_leases.owner = EXCLUDED.owner THEN flow_leases.acquired_at ELSE CURRENT_TIMESTAMP END,
+  heartbeat_at = CURRENT_TIMESTAMP,
+  expires_at = EXCLUDED.expires_at
+WHERE flow_leases.owner = EXCLUDED.owner OR flow_leases.expires_at < CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: RenewFlowLeases :many
+UPDATE flow_leases
+SET
+  heartbeat_at = CURRENT_TIMESTAMP,
+  expires_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg(ttl_seconds)::int)
+WHERE owner = sqlc.arg(owner)
+RETURNING *;
+
+-- name: GetFlowLease :one
+SELECT
+  l.*
+FROM flow_leases l
+WHERE l.flow_id = $1;
+
+-- name: GetFlowLeases :many
+SELECT
+  l.*
+FROM flow_leases l
+ORDER BY l.owner ASC, l.flow_id ASC;
+
+-- name: GetOwnerFlowLeases :many
+SELECT
+  l.*
+FROM flow_leases l
+WHERE l.owner = $1 AND l.expires_at > CURRENT_TIMESTAMP
+ORDER BY l.flow_id ASC;
+
+-- name: GetOrphanedFlows :many
+-- Running flows nobody drives, the flows changed lately are skipped because
+-- a replica which is creating or loading them takes the lease right after
+SELECT
+  f.*
+FROM flows f
+LEFT JOIN flow_leases l ON l.flow_id = f.id AND l.expires_at > CURRENT_TIMESTAMP
+WHERE f.deleted_at IS NULL AND f.status IN ('running', 'waiting') AND l.flow_id IS NULL
+  AND f.updated_at < CURRENT_TIMESTAMP - make_interval(secs => sqlc.arg(grace_seconds)::int)
+ORDER BY f.id ASC;
+
+-- name: ReleaseFlowLease :exec
+DELETE FROM flow_leases
+WHERE flow_id = $1 AND owner = $2;
+
+-- name: ReleaseOwnerFlowLeases :exec
+DELETE FROM flow_leases
+WHERE owner = $1;
+
+-- name: CreateSubscriptionPayload :one
+INSERT INTO subscription_payloads (
+  payload
+) VALUES (
+  $1
+)
+RETURNING id;
+
+-- name: GetSubscriptionPayload :one
+SELECT
+  p.payload
+FROM subscription_payloads p
+WHERE p.id = $1;
+
+-- name: DeleteSubscriptionPayloads :exec
+DELETE FROM subscription_payloads
+WHERE created_at < $1;
+
+-- name: NotifySubscriptions :exec
+SELECT pg_notify(sqlc.arg(channel)::text, sqlc.arg(payload)::text);
@@ -2,9 +2,16 @@
 SELECT * FROM flow_templates
 WHERE id = $1 AND user_id = $2 LIMIT 1;
 
+-- name: GetFlowTemplatesByIDs :many
+SELECT * FROM flow_templates
+WHERE id = ANY(@ids::BIGINT[])
+ORDER BY id;
+
 -- name: GetFlowTemplatesByUserID :many
 SELECT * FROM flow_templates
-WHERE user_id = $1
+WHERE user_id = $1 OR project_id IN (
+  SELECT m.project_id FROM project_members m WHERE m.user_id = $1
+)
 ORDER BY created_at DESC;
 
 -- name: CreateFlowTemplate :one
@@ -27,6 +34,12 @@ SET
 WHERE id = $1 AND user_id = $2
 RETURNING *;
 
+-- name: UpdateFlowTemplateProject :one
+UPDATE flow_templates
+SET project_id = $1
+WHERE id = $2
+RETURNING *;
+
 -- name: DeleteFlowTemplate :exec
 DELETE FROM flow_templates
 WHERE id = $1 AND user_id = $2;
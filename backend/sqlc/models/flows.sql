@@ -10,7 +10,9 @@ SELECT
   f.*
 FROM flows f
 INNER JOIN users u ON f.user_id = u.id
-WHERE f.user_id = $1 AND f.deleted_at IS NULL
+WHERE (f.user_id = $1 OR f.project_id IN (
+    SELECT m.project_id FROM project_members m WHERE m.user_id = $1
+  )) AND f.deleted_at IS NULL
 ORDER BY f.created_at DESC;
 
 -- name: GetFlow :one
@@ -82,6 +84,12 @@ SET model_provider_name = sqlc.arg(new_name)
 WHERE user_id = sqlc.arg(user_id) AND model_provider_name = sqlc.arg(old_name) AND deleted_at IS NULL
 RETURNING *;
 
+-- name: UpdateFlowProject :one
+UPDATE flows
+SET project_id = $1
+WHERE id = $2
+RETURNING *;
+
 -- name: DeleteFlow :one
 UPDATE flows
 SET deleted_at = CURRENT_TIMESTAMP
@@ -0,0 +1,184 @@
+-- name: CreateGraphEpisode :one
+INSERT INTO graph_episodes (
+  group_id,
+  name,
+  author,
+  source_description,
+  content,
+  success,
+  valid_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetGraphEntitiesByIDs :many
+SELECT
+  n.*
+FROM graph_entities n
+WHERE n.id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: GetGraphEntity :one
+SELECT
+  n.*
+FROM graph_entities n
+WHERE n.id = sqlc.arg(id)::uuid;
+
+-- name: GetGraphEntityRelations :many
+-- Relations touching any of the given entities in either direction, the
+-- frontier step of a graph traversal. An empty edge_types list keeps all names.
+SELECT
+  r.*
+FROM graph_relations r
+WHERE (r.source_id = ANY(sqlc.arg(ids)::uuid[]) OR r.target_id = ANY(sqlc.arg(ids)::uuid[]))
+  AND (cardinality(sqlc.arg(edge_types)::text[]) = 0 OR r.name = ANY(sqlc.arg(edge_types)::text[]))
+ORDER BY r.last_seen_at DESC
+LIMIT sqlc.arg(lim)::int;
+
+-- name: GetGraphEpisodeEntities :many
+-- Entities mentioned by any of the given episodes, most mentioned first.
+SELECT
+  n.*
+FROM graph_entities n
+WHERE n.id IN (
+  SELECT ee.entity_id FROM graph_entity_episodes ee
+  WHERE ee.episode_id = ANY(sqlc.arg(ids)::uuid[])
+)
+ORDER BY n.mentions DESC, n.last_seen_at DESC
+LIMIT sqlc.arg(lim)::int;
+
+-- name: GetGraphGroupEpisodes :many
+-- The last lim episodes of a group, oldest first.
+SELECT
+  e.*
+FROM (
+  SELECT * FROM graph_episodes
+  WHERE group_id = sqlc.arg(group_id)::text
+  ORDER BY valid_at DESC, created_at DESC
+  LIMIT sqlc.arg(lim)::int
+) e
+ORDER BY e.valid_at ASC, e.created_at ASC;
+
+-- name: LinkGraphEntityEpisode :exec
+INSERT INTO graph_entity_episodes (entity_id, episode_id)
+VALUES (sqlc.arg(entity_id)::uuid, sqlc.arg(episode_id)::uuid)
+ON CONFLICT DO NOTHING;
+
+-- name: SearchGraphEntities :many
+-- Entities of a group seen within [time_start, time_end], matched by full-text
+-- search or by their name appearing verbatim in the query (IP addresses and
+-- CVE IDs are not split into useful lexemes). An empty query matches all,
+-- an empty labels list keeps all labels. Ordered by score, then recency.
+WITH q AS (
+  SELECT replace(plainto_tsquery('english', sqlc.arg(query)::text)::text, '&', '|')::tsquery AS tsq
+)
+SELECT
+  n.*,
+  (ts_rank(to_tsvector('english', n.label || ' ' || n.name || ' ' || n.summary), q.tsq)
+    + CASE WHEN strpos(lower(sqlc.arg(query)::text), lower(n.name)) > 0 THEN 1 ELSE 0 END)::float8 AS score
+FROM graph_entities n, q
+WHERE (sqlc.arg(group_id)::text = '' OR n.group_id = sqlc.arg(group_id)::text)
+  AND (cardinality(sqlc.arg(labels)::text[]) = 0 OR n.label = ANY(sqlc.arg(labels)::text[]))
+  AND n.last_seen_at >= sqlc.arg(time_start)::timestamptz
+  AND n.first_seen_at <= sqlc.arg(time_end)::timestamptz
+  AND (
+    sqlc.arg(query)::text = ''
+    OR to_tsvector('english', n.label || ' ' || n.name || ' ' || n.summary) @@ q.tsq
+    OR strpos(lower(sqlc.arg(query)::text), lower(n.name)) > 0
+  )
+ORDER BY score DESC, n.last_seen_at DESC
+LIMIT sqlc.arg(lim)::int;
+
+-- name: SearchGraphEpisodes :many
+-- Episodes of a group valid within [time_start, time_end] matching the query
+-- by full-text search; an empty query matches all. successful_only keeps tool
+-- executions which reported success. Ordered by score, then recency.
+WITH q AS (
+  SELECT replace(plainto_tsquery('english', sqlc.arg(query)::text)::text, '&', '|')::tsquery AS tsq
+)
+SELECT
+  e.*,
+  ts_rank(to_tsvector('english', e.content), q.tsq)::float8 AS score
+FROM graph_episodes e, q
+WHERE (sqlc.arg(group_id)::text = '' OR e.group_id = sqlc.arg(group_id)::text)
+  AND e.valid_at BETWEEN sqlc.arg(time_start)::timestamptz AND sqlc.arg(time_end)::timestamptz
+  AND (NOT sqlc.arg(successful_only)::boolean OR e.success IS TRUE)
+  AND (sqlc.arg(query)::text = '' OR to_tsvector('english', e.content) @@ q.tsq)
+ORDER BY score DESC, e.valid_at DESC
+LIMIT sqlc.arg(lim)::int;
+
+-- name: SearchGraphRelations :many
+-- Relations of a group seen within [time_start, time_end] whose fact matches
+-- the query by full-text search; an empty query matches all, an empty
+-- edge_types list keeps all names. Ordered by score, then recency.
+WITH q AS (
+  SELECT replace(plainto_tsquery('english', sqlc.arg(query)::text)::text, '&', '|')::tsquery AS tsq
+)
+SELECT
+  r.*,
+  ts_rank(to_tsvector('english', r.fact), q.tsq)::float8 AS score
+FROM graph_relations r, q
+WHERE (sqlc.arg(group_id)::text = '' OR r.group_id = sqlc.arg(group_id)::text)
+  AND (cardinality(sqlc.arg(edge_types)::text[]) = 0 OR r.name = ANY(sqlc.arg(edge_types)::text[]))
+  AND r.last_seen_at >= sqlc.arg(time_start)::timestamptz
+  AND r.valid_at <= sqlc.arg(time_end)::timestamptz
+  AND (sqlc.arg(query)::text = '' OR to_tsvector('english', r.fact) @@ q.tsq)
+ORDER BY score DESC, r.last_seen_at DESC
+LIMIT sqlc.arg(lim)::int;
+
+-- name: UpsertGraphEntity :one
+-- Record a mention of an entity: a new entity is created, a known one gets
+-- its seen window widened and its summary replaced when a new one is given.
+INSERT INTO graph_entities (
+  group_id,
+  label,
+  name,
+  summary,
+  first_seen_at,
+  last_seen_at
+) VALUES (
+  sqlc.arg(group_id),
+  sqlc.arg(label),
+  sqlc.arg(name),
+  sqlc.arg(summary),
+  sqlc.arg(seen_at)::timestamptz,
+  sqlc.arg(seen_at)::timestamptz
+)
+ON CONFLICT (group_id, label, name) DO UPDATE
+SET
+  summary       = CASE WHEN EXCLUDED.summary <> '' THEN EXCLUDED.summary ELSE graph_entities.summary END,
+  mentions      = graph_entities.mentions + 1,
+  first_seen_at = LEAST(graph_entities.first_seen_at, EXCLUDED.first_seen_at),
+  last_seen_at  = GREATEST(graph_entities.last_seen_at, EXCLUDED.last_seen_at)
+RETURNING *;
+
+-- name: UpsertGraphRelation :one
+-- Record a mention of a fact between two entities, keeping the first time it
+-- was valid and pointing it to the latest source episode.
+INSERT INTO graph_relations (
+  group_id,
+  source_id,
+  target_id,
+  name,
+  fact,
+  episode_id,
+  valid_at,
+  last_seen_at
+) VALUES (
+  sqlc.arg(group_id),
+  sqlc.arg(source_id),
+  sqlc.arg(target_id),
+  sqlc.arg(name),
+  sqlc.arg(fact),
+  sqlc.arg(episode_id),
+  sqlc.arg(seen_at)::timestamptz,
+  sqlc.arg(seen_at)::timestamptz
+)
+ON CONFLICT (source_id, target_id, name) DO UPDATE
+SET
+  fact         = EXCLUDED.fact,
+  mentions     = graph_relations.mentions + 1,
+  episode_id   = EXCLUDED.episode_id,
+  valid_at     = LEAST(graph_relations.valid_at, EXCLUDED.valid_at),
+  last_seen_at = GREATEST(graph_relations.last_seen_at, EXCLUDED.last_seen_at)
+RETURNING *;
@@ -104,6 +104,7 @@ WHERE uuid::text = sqlc.arg(uuid)
 
 -- name: SearchKnowledgeDocuments :many
 -- Vector similarity search over all knowledge documents (admin view, no user filter).
+-- Archived and expired documents are skipped.
 -- Returns rows ordered by cosine similarity descending (highest score first).
 -- embedding    query vector as a PostgreSQL vector literal, e.g. '[0.1,0.2,...]'
 -- max_distance cosine-distance upper bound (exclusive); equals (1 - score_threshold),
@@ -118,12 +119,16 @@ FROM langchain_pg_embedding e
 INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
 WHERE c.name = 'langchain'
   AND COALESCE(e.cmetadata ->> 'doc_type', '') NOT IN ('memory')
+  AND COALESCE(e.cmetadata ->> 'archived', '') <> 'true'
+  AND COALESCE((e.cmetadata ->> 'expires_at')::timestamptz > CURRENT_TIMESTAMP, true)
+  AND vector_dims(e.embedding) = vector_dims(sqlc.arg(embedding)::vector)
   AND (e.embedding <=> sqlc.arg(embedding)::vector)::float8 < sqlc.arg(max_distance)::float8
 ORDER BY e.embedding <=> sqlc.arg(embedding)::vector
 LIMIT sqlc.arg(lim)::int;
 
 -- name: SearchUserKnowledgeDocuments :many
 -- Vector similarity search scoped to a specific user (by cmetadata user_id).
+-- Archived and expired documents are skipped.
 -- Returns rows ordered by cosine similarity descending (highest score first).
 -- embedding    query vector as a PostgreSQL vector literal, e.g. '[0.1,0.2,...]'
 -- max_distance cosine-distance upper bound (exclusive); equals (1 - score_threshold)
@@ -139,6 +144,9 @@ INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
 WHERE c.name = 'langchain'
   AND COALESCE(e.cmetadata ->> 'doc_type', '') NOT IN ('memory')
   AND (e.cmetadata ->> 'user_id') = sqlc.arg(user_id)
+  AND COALESCE(e.cmetadata ->> 'archived', '') <> 'true'
+  AND COALESCE((e.cmetadata ->> 'expires_at')::timestamptz > CURRENT_TIMESTAMP, true)
+  AND vector_dims(e.embedding) = vector_dims(sqlc.arg(embedding)::vector)
   AND (e.embedding <=> sqlc.arg(embedding)::vector)::float8 < sqlc.arg(max_distance)::float8
 ORDER BY e.embedding <=> sqlc.arg(embedding)::vector
 LIMIT sqlc.arg(lim)::int;
@@ -167,3 +175,114 @@ SELECT
 FROM langchain_pg_collection c
 WHERE c.name = 'langchain'
 RETURNING uuid::text AS id;
+
+-- name: SearchKnowledgeDocumentsText :many
+-- Full-text and trigram search over knowledge documents, the lexical half of
+-- hybrid retrieval. Catches exact tokens (CVE IDs, tool flags, hostnames)
+-- which embeddings tend to blur. Returns rows ordered by lexical score descending.
+-- Archived and expired documents are skipped.
+-- query       raw search text; its terms are OR-ed into a tsquery
+-- filters     JSON object of cmetadata key/value pairs compared as text,
+--             e.g. '{"doc_type":"guide","guide_type":"pentest"}'; '{}' for none
+-- user_id     owner filter as a decimal text string; empty for all users
+-- with_memory include memory documents (excluded from the knowledge UI)
+-- lim         maximum number of rows to return
+WITH q AS (
+  SELECT replace(plainto_tsquery('english', sqlc.arg(query)::text)::text, '&', '|')::tsquery AS tsq
+)
+SELECT
+  e.uuid::text                                                         AS id,
+  COALESCE(e.document, '')                                             AS document,
+  COALESCE(e.cmetadata::text, '{}')                                   AS cmetadata,
+  (ts_rank_cd(to_tsvector('english', COALESCE(e.document, '')), q.tsq, 32)
+    + word_similarity(sqlc.arg(query)::text, COALESCE(e.document, '')))::float8 AS score
+FROM langchain_pg_embedding e
+INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+CROSS JOIN q
+WHERE c.name = 'langchain'
+  AND (sqlc.arg(with_memory)::boolean OR COALESCE(e.cmetadata ->> 'doc_type', '') NOT IN ('memory'))
+  AND (sqlc.arg(user_id)::text = '' OR (e.cmetadata ->> 'user_id') = sqlc.arg(user_id)::text)
+  AND COALESCE(e.cmetadata ->> 'archived', '') <> 'true'
+  AND COALESCE((e.cmetadata ->> 'expires_at')::timestamptz > CURRENT_TIMESTAMP, true)
+  AND NOT EXISTS (
+    SELECT 1 FROM json_each_text(sqlc.arg(filters)::json) f
+    WHERE (e.cmetadata ->> f.key) IS DISTINCT FROM f.value
+  )
+  AND (
+    to_tsvector('english', COALESCE(e.document, '')) @@ q.tsq
+    OR sqlc.arg(query)::text <% COALESCE(e.document, '')
+  )
+ORDER BY score DESC
+LIMIT sqlc.arg(lim)::int;
+
+-- name: ExistsUserKnowledgeDocumentHash :one
+-- Reports whether the user already owns a document with exactly this content.
+-- hash    md5 hex digest of the stored (trimmed) document text
+-- user_id owner filter as a decimal text string (e.g. "42")
+SELECT EXISTS (
+  SELECT 1
+  FROM langchain_pg_embedding e
+  INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+  WHERE c.name = 'langchain'
+    AND md5(COALESCE(e.document, '')) = sqlc.arg(hash)::text
+    AND (e.cmetadata ->> 'user_id') = sqlc.arg(user_id)::text
+) AS exists;
+
+-- name: ExportKnowledgeDocuments :many
+-- List non-memory knowledge documents for export to a portable archive.
+-- user_id         owner filter as a decimal text string; empty for all users
+-- with_embeddings return the embedding vector as text ('[f1,f2,...]'), empty otherwise
+SELECT
+  e.uuid::text                              AS id,
+  COALESCE(e.document, '')                  AS document,
+  COALESCE(e.cmetadata::text, '{}')         AS cmetadata,
+  (CASE WHEN sqlc.arg(with_embeddings)::boolean
+    THEN COALESCE(e.embedding::text, '')
+    ELSE ''
+  END)::text                                AS embedding
+FROM langchain_pg_embedding e
+INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+WHERE c.name = 'langchain'
+  AND COALESCE(e.cmetadata ->> 'doc_type', '') NOT IN ('memory')
+  AND (sqlc.arg(user_id)::text = '' OR (e.cmetadata ->> 'user_id') = sqlc.arg(user_id)::text)
+ORDER BY e.uuid;
+
+-- name: FindUserKnowledgeDocumentConflict :one
+-- Find a document of the user that an archived document would collide with:
+-- the same document of this instance, a copy imported earlier from the same
+-- origin, or a document with identical content. Provenance matches win over
+-- content matches. Returns sql.ErrNoRows when there is no conflict.
+-- user_id         owner filter as a decimal text string (e.g. "42")
+-- local_id        UUID of the archived document if this instance exported it, empty otherwise
+-- origin_instance installation ID the archived document originates from
+-- origin_id       UUID of the archived document on its origin instance
+-- hash            md5 hex digest of the archived document text
+SELECT
+  e.uuid::text                              AS id
+FROM langchain_pg_embedding e
+INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+WHERE c.name = 'langchain'
+  AND (e.cmetadata ->> 'user_id') = sqlc.arg(user_id)::text
+  AND (
+    e.uuid::text = sqlc.arg(local_id)::text
+    OR (
+      (e.cmetadata ->> 'origin_instance') = sqlc.arg(origin_instance)::text
+      AND (e.cmetadata ->> 'origin_id') = sqlc.arg(origin_id)::text
+    )
+    OR md5(COALESCE(e.document, '')) = sqlc.arg(hash)::text
+  )
+ORDER BY md5(COALESCE(e.document, '')) = sqlc.arg(hash)::text
+LIMIT 1;
+
+-- name: ListProjectKnowledgeDocuments :many
+-- List all non-memory knowledge documents shared with a project.
+SELECT
+  e.uuid::text                              AS id,
+  COALESCE(e.document, '')                  AS document,
+  COALESCE(e.cmetadata::text, '{}')         AS cmetadata
+FROM langchain_pg_embedding e
+INNER JOIN langchain_pg_collection c ON e.collection_id = c.uuid
+WHERE c.name = 'langchain'
+  AND COALESCE(e.cmetadata ->> 'doc_type', '') NOT IN ('memory')
+  AND (e.cmetadata ->> 'project_id') = sqlc.arg(project_id)
+ORDER BY e.uuid;
@@ -0,0 +1,56 @@
+-- name: AddKnowledgeDocumentFeedback :execrows
+-- Count an agent report on documents returned by an earlier search.
+-- Unknown document IDs are ignored; returns the number of documents counted.
+INSERT INTO knowledge_document_stats (document_id, useful, not_useful)
+SELECT
+  e.uuid,
+  CASE WHEN sqlc.arg(useful)::boolean THEN 1 ELSE 0 END,
+  CASE WHEN sqlc.arg(useful)::boolean THEN 0 ELSE 1 END
+FROM langchain_pg_embedding e
+WHERE e.uuid::text = ANY(sqlc.arg(ids)::text[])
+ON CONFLICT (document_id) DO UPDATE
+SET
+  useful     = knowledge_document_stats.useful + EXCLUDED.useful,
+  not_useful = knowledge_document_stats.not_useful + EXCLUDED.not_useful;
+
+-- name: DeleteKnowledgeDocumentVote :exec
+DELETE FROM knowledge_document_votes
+WHERE document_id::text = sqlc.arg(document_id)::text
+  AND user_id = sqlc.arg(user_id);
+
+-- name: GetKnowledgeDocumentSignals :many
+-- Quality signals of documents given by UUID or by md5 hex digest of their
+-- text; search tools only know the text of vector store results.
+SELECT
+  e.uuid::text                                        AS id,
+  md5(COALESCE(e.document, ''))::text                 AS hash,
+  COALESCE(s.retrievals, 0)::bigint                   AS retrievals,
+  COALESCE(s.useful, 0)::bigint                       AS useful,
+  COALESCE(s.not_useful, 0)::bigint                   AS not_useful,
+  (SELECT COUNT(*) FROM knowledge_document_votes v
+    WHERE v.document_id = e.uuid AND v.vote > 0)::bigint AS upvotes,
+  (SELECT COUNT(*) FROM knowledge_document_votes v
+    WHERE v.document_id = e.uuid AND v.vote < 0)::bigint AS downvotes,
+  s.last_retrieved_at
+FROM langchain_pg_embedding e
+LEFT JOIN knowledge_document_stats s ON s.document_id = e.uuid
+WHERE e.uuid::text = ANY(sqlc.arg(ids)::text[])
+  OR md5(COALESCE(e.document, '')) = ANY(sqlc.arg(hashes)::text[]);
+
+-- name: RecordKnowledgeRetrievals :exec
+-- Count one retrieval for each document returned to an agent.
+INSERT INTO knowledge_document_stats (document_id, retrievals, last_retrieved_at)
+SELECT e.uuid, 1, CURRENT_TIMESTAMP
+FROM langchain_pg_embedding e
+WHERE e.uuid::text = ANY(sqlc.arg(ids)::text[])
+ON CONFLICT (document_id) DO UPDATE
+SET
+  retrievals        = knowledge_document_stats.retrievals + 1,
+  last_retrieved_at = EXCLUDED.last_retrieved_at;
+
+-- name: UpsertKnowledgeDocumentVote :exec
+-- Set the vote of an operator on a document, replacing an earlier one.
+INSERT INTO knowledge_document_votes (document_id, user_id, vote)
+VALUES (sqlc.arg(document_id)::uuid, sqlc.arg(user_id), sqlc.arg(vote))
+ON CONFLICT (document_id, user_id) DO UPDATE
+SET vote = EXCLUDED.vote;
@@ -0,0 +1,57 @@
+-- name: GetKnowledgeImportJobs :many
+SELECT
+  j.*
+FROM knowledge_import_jobs j
+ORDER BY j.created_at DESC;
+
+-- name: GetUserKnowledgeImportJobs :many
+SELECT
+  j.*
+FROM knowledge_import_jobs j
+WHERE j.user_id = $1
+ORDER BY j.created_at DESC;
+
+-- name: GetKnowledgeImportJob :one
+SELECT
+  j.*
+FROM knowledge_import_jobs j
+WHERE j.id = $1;
+
+-- name: GetUserKnowledgeImportJob :one
+SELECT
+  j.*
+FROM knowledge_import_jobs j
+WHERE j.id = $1 AND j.user_id = $2;
+
+-- name: CreateKnowledgeImportJob :one
+INSERT INTO knowledge_import_jobs (
+  user_id,
+  source,
+  options,
+  total_files
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: UpdateKnowledgeImportJob :one
+UPDATE knowledge_import_jobs
+SET
+  status = $1,
+  skipped_files = $2,
+  total_chunks = $3,
+  processed_chunks = $4,
+  created_docs = $5,
+  updated_docs = $6,
+  duplicate_docs = $7,
+  failed_docs = $8,
+  error = $9
+WHERE id = $10
+RETURNING *;
+
+-- name: FailInterruptedKnowledgeImportJobs :exec
+UPDATE knowledge_import_jobs
+SET
+  status = 'failed',
+  error = $1
+WHERE status IN ('pending', 'running');
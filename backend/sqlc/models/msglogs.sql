@@ -6,6 +6,25 @@ INNER JOIN flows f ON ml.flow_id = f.id
 WHERE ml.flow_id = $1 AND f.deleted_at IS NULL
 ORDER BY ml.created_at ASC;
 
+-- name: GetFlowMsgLogsPage :many
+SELECT
+  ml.*
+FROM msglogs ml
+INNER JOIN flows f ON ml.flow_id = f.id
+WHERE ml.flow_id = sqlc.arg(flow_id) AND f.deleted_at IS NULL
+  AND (sqlc.narg(after_id)::bigint IS NULL OR ml.id > sqlc.narg(after_id)::bigint)
+  AND (sqlc.narg(before_id)::bigint IS NULL OR ml.id < sqlc.narg(before_id)::bigint)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR ml.created_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(until)::timestamptz IS NULL OR ml.created_at < sqlc.narg(until)::timestamptz)
+  AND (sqlc.narg(task_id)::bigint IS NULL OR ml.task_id = sqlc.narg(task_id)::bigint)
+  AND (sqlc.narg(subtask_id)::bigint IS NULL OR ml.subtask_id = sqlc.narg(subtask_id)::bigint)
+  AND (cardinality(sqlc.arg(types)::text[]) = 0 OR ml.type::text = ANY(sqlc.arg(types)::text[]))
+  AND (sqlc.arg(search)::text = '' OR search_document(ml.message, ml.thinking, ml.result) @@ websearch_to_tsquery('english', sqlc.arg(search)::text))
+ORDER BY
+  CASE WHEN sqlc.arg(reverse)::bool THEN ml.id END DESC,
+  ml.id ASC
+LIMIT sqlc.narg(page_size)::int;
+
 -- name: GetUserFlowMsgLogs :many
 SELECT
   ml.*
@@ -0,0 +1,116 @@
+-- name: GetProject :one
+SELECT
+  p.*
+FROM projects p
+WHERE p.id = $1;
+
+-- name: GetUserProject :one
+SELECT
+  p.*,
+  m.role AS member_role
+FROM projects p
+INNER JOIN project_members m ON m.project_id = p.id
+WHERE p.id = $1 AND m.user_id = $2;
+
+-- name: GetUserProjects :many
+SELECT
+  p.*,
+  m.role AS member_role
+FROM projects p
+INNER JOIN project_members m ON m.project_id = p.id
+WHERE m.user_id = $1
+ORDER BY p.name ASC;
+
+-- name: GetProjects :many
+SELECT
+  p.*
+FROM projects p
+ORDER BY p.name ASC;
+
+-- name: CreateProject :one
+INSERT INTO projects (
+  name,
+  description,
+  user_id
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: UpdateProject :one
+UPDATE projects
+SET name = $1, description = $2
+WHERE id = $3
+RETURNING *;
+
+-- name: DeleteProject :one
+DELETE FROM projects
+WHERE id = $1
+RETURNING *;
+
+-- name: GetProjectMembers :many
+SELECT
+  m.*,
+  u.name AS user_name,
+  u.mail AS user_mail
+FROM project_members m
+INNER JOIN users u ON m.user_id = u.id
+WHERE m.project_id = $1
+ORDER BY m.created_at ASC;
+
+-- name: GetProjectMember :one
+SELECT
+  m.*
+FROM project_members m
+WHERE m.project_id = $1 AND m.user_id = $2;
+
+-- name: GetProjectMemberUserIDs :many
+SELECT
+  m.user_id
+FROM project_members m
+WHERE m.project_id = $1
+ORDER BY m.user_id ASC;
+
+-- name: UpsertProjectMember :one
+INSERT INTO project_members (
+  project_id,
+  user_id,
+  role
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (project_id, user_id)
+DO UPDATE SET
+  role = EXCLUDED.role
+RETURNING *;
+
+-- name: DeleteProjectMember :exec
+DELETE FROM project_members
+WHERE project_id = $1 AND user_id = $2;
+
+-- name: GetProjectFlows :many
+SELECT
+  f.*
+FROM flows f
+WHERE f.project_id = $1 AND f.deleted_at IS NULL
+ORDER BY f.created_at DESC;
+
+-- name: GetProjectFlowTemplates :many
+SELECT
+  t.*
+FROM flow_templates t
+WHERE t.project_id = $1
+ORDER BY t.created_at DESC;
+
+-- name: GetProjectResources :many
+SELECT id, user_id, hash, name, path, size, is_dir, created_at, updated_at, project_id
+FROM user_resources
+WHERE project_id = $1
+ORDER BY updated_at DESC, name ASC;
+
+-- name: GetProjectProviders :many
+SELECT
+  p.*
+FROM providers p
+WHERE p.project_id = $1 AND p.deleted_at IS NULL
+ORDER BY p.created_at ASC;
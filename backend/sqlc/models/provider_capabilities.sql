@@ -0,0 +1,54 @@
+-- name: GetUserProviderCapabilities :many
+SELECT
+  pc.*
+FROM provider_capabilities pc
+INNER JOIN providers p ON pc.provider_id = p.id
+WHERE pc.user_id = $1 AND p.deleted_at IS NULL
+ORDER BY pc.type ASC, pc.provider_id ASC, pc.model ASC;
+
+-- name: GetUserProviderCapabilitiesByType :many
+SELECT
+  pc.*
+FROM provider_capabilities pc
+INNER JOIN providers p ON pc.provider_id = p.id
+WHERE pc.user_id = $1 AND pc.type = $2 AND p.deleted_at IS NULL
+ORDER BY pc.provider_id ASC, pc.model ASC;
+
+-- name: GetProviderCapabilities :many
+SELECT
+  pc.*
+FROM provider_capabilities pc
+WHERE pc.provider_id = $1
+ORDER BY pc.model ASC;
+
+-- name: UpsertProviderCapability :one
+INSERT INTO provider_capabilities (
+  user_id,
+  provider_id,
+  type,
+  model,
+  agent_types,
+  tool_calling,
+  json_mode,
+  reasoning,
+  streaming,
+  max_context,
+  tests_total,
+  tests_passed
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (provider_id, model) DO UPDATE SET
+  agent_types = EXCLUDED.agent_types,
+  tool_calling = EXCLUDED.tool_calling,
+  json_mode = EXCLUDED.json_mode,
+  reasoning = EXCLUDED.reasoning,
+  streaming = EXCLUDED.streaming,
+  max_context = EXCLUDED.max_context,
+  tests_total = EXCLUDED.tests_total,
+  tests_passed = EXCLUDED.tests_passed
+RETURNING *;
+
+-- name: DeleteUserProviderCapabilities :exec
+DELETE FROM provider_capabilities
+WHERE user_id = $1 AND type = $2;
@@ -48,6 +48,25 @@ FROM providers p
 INNER JOIN users u ON p.user_id = u.id
 WHERE p.name = $1 AND p.user_id = $2 AND p.deleted_at IS NULL;
 
+-- name: GetUserProjectProviderByName :one
+-- A provider another member shares through a project of the user. When
+-- several projects share one with that name, the oldest provider wins.
+SELECT
+  p.*
+FROM providers p
+INNER JOIN project_members m ON m.project_id = p.project_id
+WHERE p.name = $1 AND m.user_id = $2 AND p.user_id <> $2 AND p.deleted_at IS NULL
+ORDER BY p.created_at ASC
+LIMIT 1;
+
+-- name: GetUserProjectProviders :many
+SELECT
+  p.*
+FROM providers p
+INNER JOIN project_members m ON m.project_id = p.project_id
+WHERE m.user_id = $1 AND p.user_id <> $1 AND p.deleted_at IS NULL
+ORDER BY p.created_at ASC;
+
 -- name: CreateProvider :one
 INSERT INTO providers (
   user_id,
@@ -71,6 +90,12 @@ SET config = $3, name = $4
 WHERE id = $1 AND user_id = $2
 RETURNING *;
 
+-- name: UpdateProviderProject :one
+UPDATE providers
+SET project_id = $1
+WHERE id = $2 AND deleted_at IS NULL
+RETURNING *;
+
 -- name: DeleteProvider :one
 UPDATE providers
 SET deleted_at = CURRENT_TIMESTAMP
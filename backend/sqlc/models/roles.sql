@@ -33,3 +33,50 @@ SELECT
   ) AS privileges
 FROM roles r
 WHERE r.name = $1;
+
+-- name: GetRoleUserIDs :many
+SELECT id
+FROM users
+WHERE role_id = $1
+ORDER BY id ASC;
+
+-- name: GetRoleUsage :one
+-- Number of users and API tokens, revoked and deleted ones included, which
+-- reference the role and keep it from being deleted.
+SELECT
+  (SELECT COUNT(*) FROM users u WHERE u.role_id = $1)::bigint +
+  (SELECT COUNT(*) FROM api_tokens t WHERE t.role_id = $1)::bigint AS usage;
+
+-- name: CreateRole :one
+INSERT INTO roles (
+  name
+) VALUES (
+  $1
+)
+RETURNING *;
+
+-- name: UpdateRoleName :one
+UPDATE roles
+SET name = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: SetRolePrivileges :exec
+-- Replaces the privileges of the role in one statement, the rows which are
+-- still granted are kept as they are.
+WITH removed AS (
+  DELETE FROM privileges
+  WHERE role_id = @role_id::bigint AND NOT (name = ANY(@privileges::text[]))
+)
+INSERT INTO privileges (role_id, name)
+SELECT @role_id::bigint, UNNEST(@privileges::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteRole :one
+WITH removed AS (
+  DELETE FROM privileges
+  WHERE role_id = $1
+)
+DELETE FROM roles r
+WHERE r.id = $1
+RETURNING r.*;
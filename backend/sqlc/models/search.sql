@@ -0,0 +1,130 @@
+-- name: SearchFlows :many
+-- Ranked full-text hits of flows, tasks, subtasks and logs, the kinds of all_kinds
+-- are searched in every flow and the kinds of own_kinds in the flows of the user and
+-- of the projects the user is a member of; a non-empty flow_ids limits both
+WITH visible AS (
+  SELECT
+    f.id,
+    f.user_id = sqlc.arg(user_id)::bigint OR f.project_id IN (
+      SELECT m.project_id FROM project_members m WHERE m.user_id = sqlc.arg(user_id)::bigint
+    ) AS own
+  FROM flows f
+  WHERE f.deleted_at IS NULL
+    AND (cardinality(sqlc.arg(flow_ids)::bigint[]) = 0 OR f.id = ANY(sqlc.arg(flow_ids)::bigint[]))
+),
+hits AS (
+  SELECT
+    'flow'::text AS kind,
+    f.id AS flow_id,
+    NULL::bigint AS task_id,
+    NULL::bigint AS subtask_id,
+    f.id AS object_id,
+    ts_rank(search_document(f.title), websearch_to_tsquery('english', sqlc.arg(search)::text)) AS rank,
+    f.created_at
+  FROM flows f
+  INNER JOIN visible v ON f.id = v.id
+  WHERE f.deleted_at IS NULL
+    AND search_document(f.title) @@ websearch_to_tsquery('english', sqlc.arg(search)::text)
+    AND ('flow' = ANY(sqlc.arg(all_kinds)::text[]) OR ('flow' = ANY(sqlc.arg(own_kinds)::text[]) AND v.own))
+  UNION ALL
+  SELECT
+    'task',
+    t.flow_id,
+    t.id,
+    NULL,
+    t.id,
+    ts_rank(search_document(t.title, t.input, t.result), websearch_to_tsquery('english', sqlc.arg(search)::text)),
+    t.created_at
+  FROM tasks t
+  INNER JOIN visible v ON t.flow_id = v.id
+  WHERE search_document(t.title, t.input, t.result) @@ websearch_to_tsquery('english', sqlc.arg(search)::text)
+    AND ('task' = ANY(sqlc.arg(all_kinds)::text[]) OR ('task' = ANY(sqlc.arg(own_kinds)::text[]) AND v.own))
+  UNION ALL
+  SELECT
+    'subtask',
+    t.flow_id,
+    t.id,
+    s.id,
+    s.id,
+    ts_rank(search_document(s.title, s.description, s.result), websearch_to_tsquery('english', sqlc.arg(search)::text)),
+    s.created_at
+  FROM subtasks s
+  INNER JOIN tasks t ON s.task_id = t.id
+  INNER JOIN visible v ON t.flow_id = v.id
+  WHERE search_document(s.title, s.description, s.result) @@ websearch_to_tsquery('english', sqlc.arg(search)::text)
+    AND ('subtask' = ANY(sqlc.arg(all_kinds)::text[]) OR ('subtask' = ANY(sqlc.arg(own_kinds)::text[]) AND v.own))
+  UNION ALL
+  SELECT
+    'message',
+    ml.flow_id,
+    ml.task_id,
+    ml.subtask_id,
+    ml.id,
+    ts_rank(search_document(ml.message, ml.thinking, ml.result), websearch_to_tsquery('english', sqlc.arg(search)::text)),
+    ml.created_at
+  FROM msglogs ml
+  INNER JOIN visible v ON ml.flow_id = v.id
+  WHERE search_document(ml.message, ml.thinking, ml.result) @@ websearch_to_tsquery('english', sqlc.arg(search)::text)
+    AND ('message' = ANY(sqlc.arg(all_kinds)::text[]) OR ('message' = ANY(sqlc.arg(own_kinds)::text[]) AND v.own))
+  UNION ALL
+  SELECT
+    'terminal',
+    tl.flow_id,
+    tl.task_id,
+    tl.subtask_id,
+    tl.id,
+    ts_rank(search_document(tl.text), websearch_to_tsquery('english', sqlc.arg(search)::text)),
+    tl.created_at
+  FROM termlogs tl
+  INNER JOIN visible v ON tl.flow_id = v.id
+  WHERE search_document(tl.text) @@ websearch_to_tsquery('english', sqlc.arg(search)::text)
+    AND ('terminal' = ANY(sqlc.arg(all_kinds)::text[]) OR ('terminal' = ANY(sqlc.arg(own_kinds)::text[]) AND v.own))
+  UNION ALL
+  SELECT
+    'toolcall',
+    tc.flow_id,
+    tc.task_id,
+    tc.subtask_id,
+    tc.id,
+    ts_rank(search_document(tc.name, tc.args::text, tc.result), websearch_to_tsquery('english', sqlc.arg(search)::text)),
+    tc.created_at
+  FROM toolcalls tc
+  INNER JOIN visible v ON tc.flow_id = v.id
+  WHERE search_document(tc.name, tc.args::text, tc.result) @@ websearch_to_tsquery('english', sqlc.arg(search)::text)
+    AND ('toolcall' = ANY(sqlc.arg(all_kinds)::text[]) OR ('toolcall' = ANY(sqlc.arg(own_kinds)::text[]) AND v.own))
+),
+page AS (
+  SELECT *
+  FROM hits
+  ORDER BY rank DESC, created_at DESC, kind, object_id
+  LIMIT sqlc.arg(page_size)::int
+  OFFSET sqlc.arg(page_offset)::int
+)
+-- the snippets are highlighted for the page only, ts_headline parses the whole document
+SELECT
+  p.kind,
+  p.flow_id,
+  f.title AS flow_title,
+  p.task_id,
+  p.subtask_id,
+  p.object_id,
+  p.rank,
+  p.created_at,
+  ts_headline('english', left(d.document, 262144), websearch_to_tsquery('english', sqlc.arg(search)::text),
+    'StartSel=<mark>, StopSel=</mark>, MinWords=10, MaxWords=30, MaxFragments=3, FragmentDelimiter=" ... "')::text AS snippet
+FROM page p
+INNER JOIN flows f ON p.flow_id = f.id
+CROSS JOIN LATERAL (
+  SELECT f.title WHERE p.kind = 'flow'
+  UNION ALL
+  SELECT concat_ws(' ', t.title, t.input, t.result) FROM tasks t WHERE p.kind = 'task' AND t.id = p.object_id
+  UNION ALL
+  SELECT concat_ws(' ', s.title, s.description, s.result) FROM subtasks s WHERE p.kind = 'subtask' AND s.id = p.object_id
+  UNION ALL
+  SELECT concat_ws(' ', ml.message, ml.thinking, ml.result) FROM msglogs ml WHERE p.kind = 'message' AND ml.id = p.object_id
+  UNION ALL
+  SELECT tl.text FROM termlogs tl WHERE p.kind = 'terminal' AND tl.id = p.object_id
+  UNION ALL
+  SELECT concat_ws(' ', tc.name, tc.args::text, tc.result) FROM toolcalls tc WHERE p.kind = 'toolcall' AND tc.id = p.object_id
+) d(document)
+ORDER BY p.rank DESC, p.created_at DESC, p.kind, p.object_id;
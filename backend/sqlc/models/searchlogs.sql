@@ -6,6 +6,25 @@ INNER JOIN flows f ON sl.flow_id = f.id
 WHERE sl.flow_id = $1 AND f.deleted_at IS NULL
 ORDER BY sl.created_at ASC;
 
+-- name: GetFlowSearchLogsPage :many
+SELECT
+  sl.*
+FROM searchlogs sl
+INNER JOIN flows f ON sl.flow_id = f.id
+WHERE sl.flow_id = sqlc.arg(flow_id) AND f.deleted_at IS NULL
+  AND (sqlc.narg(after_id)::bigint IS NULL OR sl.id > sqlc.narg(after_id)::bigint)
+  AND (sqlc.narg(before_id)::bigint IS NULL OR sl.id < sqlc.narg(before_id)::bigint)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR sl.created_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(until)::timestamptz IS NULL OR sl.created_at < sqlc.narg(until)::timestamptz)
+  AND (sqlc.narg(task_id)::bigint IS NULL OR sl.task_id = sqlc.narg(task_id)::bigint)
+  AND (sqlc.narg(subtask_id)::bigint IS NULL OR sl.subtask_id = sqlc.narg(subtask_id)::bigint)
+  AND (cardinality(sqlc.arg(types)::text[]) = 0 OR sl.executor::text = ANY(sqlc.arg(types)::text[]))
+  AND (sqlc.arg(search)::text = '' OR search_document(sl.query, sl.result) @@ websearch_to_tsquery('english', sqlc.arg(search)::text))
+ORDER BY
+  CASE WHEN sqlc.arg(reverse)::bool THEN sl.id END DESC,
+  sl.id ASC
+LIMIT sqlc.narg(page_size)::int;
+
 -- name: GetUserFlowSearchLogs :many
 SELECT
   sl.*
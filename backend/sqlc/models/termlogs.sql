@@ -6,6 +6,25 @@ INNER JOIN flows f ON tl.flow_id = f.id
 WHERE tl.flow_id = $1 AND f.deleted_at IS NULL
 ORDER BY tl.created_at ASC;
 
+-- name: GetFlowTermLogsPage :many
+SELECT
+  tl.*
+FROM termlogs tl
+INNER JOIN flows f ON tl.flow_id = f.id
+WHERE tl.flow_id = sqlc.arg(flow_id) AND f.deleted_at IS NULL
+  AND (sqlc.narg(after_id)::bigint IS NULL OR tl.id > sqlc.narg(after_id)::bigint)
+  AND (sqlc.narg(before_id)::bigint IS NULL OR tl.id < sqlc.narg(before_id)::bigint)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR tl.created_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(until)::timestamptz IS NULL OR tl.created_at < sqlc.narg(until)::timestamptz)
+  AND (sqlc.narg(task_id)::bigint IS NULL OR tl.task_id = sqlc.narg(task_id)::bigint)
+  AND (sqlc.narg(subtask_id)::bigint IS NULL OR tl.subtask_id = sqlc.narg(subtask_id)::bigint)
+  AND (cardinality(sqlc.arg(types)::text[]) = 0 OR tl.type::text = ANY(sqlc.arg(types)::text[]))
+  AND (sqlc.arg(search)::text = '' OR search_document(tl.text) @@ websearch_to_tsquery('english', sqlc.arg(search)::text))
+ORDER BY
+  CASE WHEN sqlc.arg(reverse)::bool THEN tl.id END DESC,
+  tl.id ASC
+LIMIT sqlc.narg(page_size)::int;
+
 -- name: GetUserFlowTermLogs :many
 SELECT
   tl.*
@@ -6,6 +6,25 @@ INNER JOIN flows f ON tc.flow_id = f.id
 WHERE tc.flow_id = $1 AND f.deleted_at IS NULL
 ORDER BY tc.created_at ASC;
 
+-- name: GetFlowToolcallsPage :many
+SELECT
+  tc.*
+FROM toolcalls tc
+INNER JOIN flows f ON tc.flow_id = f.id
+WHERE tc.flow_id = sqlc.arg(flow_id) AND f.deleted_at IS NULL
+  AND (sqlc.narg(after_id)::bigint IS NULL OR tc.id > sqlc.narg(after_id)::bigint)
+  AND (sqlc.narg(before_id)::bigint IS NULL OR tc.id < sqlc.narg(before_id)::bigint)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR tc.created_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(until)::timestamptz IS NULL OR tc.created_at < sqlc.narg(until)::timestamptz)
+  AND (sqlc.narg(task_id)::bigint IS NULL OR tc.task_id = sqlc.narg(task_id)::bigint)
+  AND (sqlc.narg(subtask_id)::bigint IS NULL OR tc.subtask_id = sqlc.narg(subtask_id)::bigint)
+  AND (cardinality(sqlc.arg(types)::text[]) = 0 OR tc.name = ANY(sqlc.arg(types)::text[]))
+  AND (sqlc.arg(search)::text = '' OR search_document(tc.name, tc.args::text, tc.result) @@ websearch_to_tsquery('english', sqlc.arg(search)::text))
+ORDER BY
+  CASE WHEN sqlc.arg(reverse)::bool THEN tc.id END DESC,
+  tc.id ASC
+LIMIT sqlc.narg(page_size)::int;
+
 -- name: GetFlowToolcall :one
 SELECT
   tc.*
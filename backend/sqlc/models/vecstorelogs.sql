@@ -6,6 +6,25 @@ INNER JOIN flows f ON vl.flow_id = f.id
 WHERE vl.flow_id = $1 AND f.deleted_at IS NULL
 ORDER BY vl.created_at ASC;
 
+-- name: GetFlowVectorStoreLogsPage :many
+SELECT
+  vl.*
+FROM vecstorelogs vl
+INNER JOIN flows f ON vl.flow_id = f.id
+WHERE vl.flow_id = sqlc.arg(flow_id) AND f.deleted_at IS NULL
+  AND (sqlc.narg(after_id)::bigint IS NULL OR vl.id > sqlc.narg(after_id)::bigint)
+  AND (sqlc.narg(before_id)::bigint IS NULL OR vl.id < sqlc.narg(before_id)::bigint)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR vl.created_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(until)::timestamptz IS NULL OR vl.created_at < sqlc.narg(until)::timestamptz)
+  AND (sqlc.narg(task_id)::bigint IS NULL OR vl.task_id = sqlc.narg(task_id)::bigint)
+  AND (sqlc.narg(subtask_id)::bigint IS NULL OR vl.subtask_id = sqlc.narg(subtask_id)::bigint)
+  AND (cardinality(sqlc.arg(types)::text[]) = 0 OR vl.executor::text = ANY(sqlc.arg(types)::text[]))
+  AND (sqlc.arg(search)::text = '' OR search_document(vl.query, vl.result) @@ websearch_to_tsquery('english', sqlc.arg(search)::text))
+ORDER BY
+  CASE WHEN sqlc.arg(reverse)::bool THEN vl.id END DESC,
+  vl.id ASC
+LIMIT sqlc.narg(page_size)::int;
+
 -- name: GetUserFlowVectorStoreLogs :many
 SELECT
   vl.*
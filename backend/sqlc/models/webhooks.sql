@@ -0,0 +1,107 @@
+-- name: GetWebhooks :many
+SELECT
+  w.*
+FROM webhooks w
+ORDER BY w.created_at DESC;
+
+-- name: GetUserWebhooks :many
+SELECT
+  w.*
+FROM webhooks w
+WHERE w.user_id = $1
+ORDER BY w.created_at DESC;
+
+-- name: GetWebhook :one
+SELECT
+  w.*
+FROM webhooks w
+WHERE w.id = $1;
+
+-- name: GetUserWebhook :one
+SELECT
+  w.*
+FROM webhooks w
+WHERE w.id = $1 AND w.user_id = $2;
+
+-- name: GetEnabledWebhooks :many
+SELECT
+  w.*
+FROM webhooks w
+WHERE w.enabled = TRUE
+ORDER BY w.id ASC;
+
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+  user_id,
+  name,
+  url,
+  secret,
+  events,
+  flow_ids,
+  enabled
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: UpdateWebhook :one
+UPDATE webhooks
+SET name = $1, url = $2, secret = $3, events = $4, flow_ids = $5, enabled = $6
+WHERE id = $7
+RETURNING *;
+
+-- name: DeleteWebhook :one
+DELETE FROM webhooks
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+  webhook_id,
+  event,
+  flow_id,
+  payload
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT
+  d.*
+FROM webhook_deliveries d
+WHERE d.id = $1;
+
+-- name: GetWebhookDeliveries :many
+SELECT
+  d.*
+FROM webhook_deliveries d
+WHERE d.webhook_id = $1
+ORDER BY d.id DESC
+LIMIT $2;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = $1
+WHERE id IN (
+  SELECT d.id
+  FROM webhook_deliveries d
+  WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP
+  ORDER BY d.next_attempt_at ASC
+  LIMIT $2
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateWebhookDeliveryResult :one
+UPDATE webhook_deliveries
+SET
+  status = $1,
+  attempts = $2,
+  next_attempt_at = $3,
+  response_status = $4,
+  response_body = $5,
+  error = $6,
+  delivered_at = $7
+WHERE id = $8
+RETURNING *;